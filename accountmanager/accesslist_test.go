@@ -0,0 +1,56 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_AssetAccessList(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+
+	ban := &UpdateAssetAccessList{AssetID: assetID, Banned: []common.Name{recipient}}
+	if err := processAssetAction(am, types.UpdateAssetAccessList, recipient, 0, big.NewInt(0), ban); err == nil {
+		t.Fatal("update access list by non owner should fail")
+	}
+	if err := processAssetAction(am, types.UpdateAssetAccessList, sender, 0, big.NewInt(0), ban); err != nil {
+		t.Fatal(err)
+	}
+	list, err := am.GetAssetAccessList(assetID)
+	if err != nil || len(list.Banned) != 1 || list.Banned[0] != recipient {
+		t.Fatalf("GetAssetAccessList = %v, %v", list, err)
+	}
+	if err := am.TransferAsset(sender, recipient, assetID, big.NewInt(10)); err == nil {
+		t.Fatal("transfer to banned account should fail")
+	}
+
+	allow := &UpdateAssetAccessList{AssetID: assetID, Allowlist: true, Allowed: []common.Name{recipient}}
+	if err := processAssetAction(am, types.UpdateAssetAccessList, sender, 0, big.NewInt(0), allow); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(sender, recipient, assetID, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(recipient, common.Name("fractal"), assetID, big.NewInt(5)); err == nil {
+		t.Fatal("transfer to account not allowed should fail")
+	}
+}
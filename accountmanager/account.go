@@ -34,6 +34,8 @@ type AssetBalance struct {
 
 type recoverActionResult struct {
 	acctAuthors map[common.Name]*accountAuthor
+	actionType  types.ActionType
+	subTypes    []types.ActionType // the sub-action types of a batch action
 }
 
 type accountAuthor struct {
@@ -43,6 +45,15 @@ type accountAuthor struct {
 	indexWeight           map[uint64]uint64
 }
 
+// addAuthorWeight counts the weight of the author at index of the account.
+func (r *recoverActionResult) addAuthorWeight(acct *Account, index uint64) {
+	if r.acctAuthors[acct.GetName()] == nil {
+		r.acctAuthors[acct.GetName()] = &accountAuthor{version: acct.AuthorVersion, threshold: acct.Threshold, updateAuthorThreshold: acct.UpdateAuthorThreshold, indexWeight: map[uint64]uint64{index: acct.Authors[index].GetWeight()}}
+		return
+	}
+	r.acctAuthors[acct.GetName()].indexWeight[index] = acct.Authors[index].GetWeight()
+}
+
 func newAssetBalance(assetID uint64, amount *big.Int) *AssetBalance {
 	ab := AssetBalance{
 		AssetID: assetID,
@@ -104,6 +115,21 @@ func NewAccount(accountName common.Name, founderName common.Name, pubkey common.
 	return &acctObject, nil
 }
 
+// Copy returns a copy of the account, balances and authors are not shared.
+func (a *Account) Copy() *Account {
+	acct := *a
+	acct.Balances = make([]*AssetBalance, len(a.Balances))
+	for i, ab := range a.Balances {
+		acct.Balances[i] = newAssetBalance(ab.AssetID, new(big.Int).Set(ab.Balance))
+	}
+	acct.Authors = make([]*common.Author, len(a.Authors))
+	for i, author := range a.Authors {
+		auth := *author
+		acct.Authors[i] = &auth
+	}
+	return &acct
+}
+
 //HaveCode check account have code
 func (a *Account) HaveCode() bool {
 	return a.GetCodeSize() != 0
@@ -243,6 +269,17 @@ func (a *Account) UpdateAuthor(author *common.Author) error {
 	return nil
 }
 
+// UpdateAuthorScope replace the scope of the author, an empty scope lifts the restriction.
+func (a *Account) UpdateAuthorScope(author *common.Author) error {
+	for _, auth := range a.Authors {
+		if author.Owner.String() == auth.Owner.String() {
+			auth.Scope = author.Scope
+			break
+		}
+	}
+	return nil
+}
+
 func (a *Account) DeleteAuthor(author *common.Author) error {
 	for i, auth := range a.Authors {
 		if author.Owner.String() == auth.Owner.String() {
@@ -253,6 +290,28 @@ func (a *Account) DeleteAuthor(author *common.Author) error {
 	return nil
 }
 
+// CheckAuthors check the author set can still reach both thresholds.
+func (a *Account) CheckAuthors() error {
+	if len(a.Authors) == 0 {
+		return ErrAuthorsIsEmpty
+	}
+	var total uint64
+	for _, auth := range a.Authors {
+		if total+auth.GetWeight() < total {
+			total = ^uint64(0)
+			break
+		}
+		total += auth.GetWeight()
+	}
+	if total < a.GetThreshold() {
+		return fmt.Errorf("%v threshold %d, total weight %d", ErrThresholdUnreachable, a.GetThreshold(), total)
+	}
+	if total < a.GetUpdateAuthorThreshold() {
+		return fmt.Errorf("%v update author threshold %d, total weight %d", ErrThresholdUnreachable, a.GetUpdateAuthorThreshold(), total)
+	}
+	return nil
+}
+
 // GetCodeHash get code hash
 func (a *Account) GetCodeHash() (common.Hash, error) {
 	if len(a.CodeHash) == 0 {
@@ -0,0 +1,56 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+// MaxAccountsLimit max accounts returned by a query
+const MaxAccountsLimit = 1000
+
+// AccountList a page of the accounts in the id order, next is the account id cursor of the next page, 0 if no more
+type AccountList struct {
+	Accounts []*Account `json:"accounts"`
+	Next     uint64     `json:"next"`
+}
+
+//GetAccounts get the accounts from the account id cursor without their balances,
+//the ids of the acctInfo keys are the cursors so the pages are stable while accounts are created
+func (am *AccountManager) GetAccounts(cursor uint64, limit uint64) (*AccountList, error) {
+	if limit == 0 || limit > MaxAccountsLimit {
+		limit = MaxAccountsLimit
+	}
+	lastAccountID, err := am.getAccountCounter()
+	if err != nil {
+		return nil, err
+	}
+	list := &AccountList{Accounts: []*Account{}}
+	id := cursor
+	if id <= counterID {
+		id = counterID + 1
+	}
+	for ; id <= lastAccountID && uint64(len(list.Accounts)) < limit; id++ {
+		acct, err := am.getAccountMetaById(id)
+		if err != nil {
+			return nil, err
+		}
+		if acct != nil {
+			list.Accounts = append(list.Accounts, acct)
+		}
+	}
+	if id <= lastAccountID {
+		list.Next = id
+	}
+	return list, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAccountManager_GetAccounts(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	want := []common.Name{"fractal", "fractal.account", "escrowsender", "escrowrecipient"}
+
+	var got []common.Name
+	cursor := uint64(0)
+	for pages := 0; ; pages++ {
+		list, err := am.GetAccounts(cursor, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Accounts) > 3 || pages > 1 {
+			t.Fatalf("page %d returns %d accounts", pages, len(list.Accounts))
+		}
+		for _, acct := range list.Accounts {
+			got = append(got, acct.GetName())
+		}
+		if list.Next == 0 {
+			break
+		}
+		cursor = list.Next
+	}
+	if len(got) != len(want) {
+		t.Fatalf("accounts %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("accounts %v, want %v", got, want)
+		}
+	}
+}
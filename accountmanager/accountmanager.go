@@ -51,6 +51,8 @@ const (
 	AddAuthor AuthorActionType = iota
 	UpdateAuthor
 	DeleteAuthor
+	// UpdateAuthorScope replace the scope of the author, the weight is kept
+	UpdateAuthorScope
 )
 
 type CreateAccountAction struct {
@@ -85,6 +87,8 @@ type IssueAsset struct {
 	UpperLimit  *big.Int    `json:"upperLimit"`
 	Contract    common.Name `json:"contract"`
 	Description string      `json:"description"`
+	// the approvers of raising the upper limit, none for a limit that can only be lowered
+	UpperLimitApprovers []common.Name `json:"upperLimitApprovers,omitempty" rlp:"tail"`
 }
 
 type IncAsset struct {
@@ -103,15 +107,131 @@ type UpdateAssetOwner struct {
 	Owner   common.Name `json:"owner"`
 }
 
+type AcceptAssetOwner struct {
+	AssetID uint64 `json:"assetId,omitempty"`
+}
+
 type UpdateAssetContract struct {
 	AssetID  uint64      `json:"assetId,omitempty"`
 	Contract common.Name `json:"contract"`
 }
 
+type UpdateTokenCurator struct {
+	Curator common.Name `json:"curator"`
+	Remove  bool        `json:"remove"`
+}
+
+type SetAssetConversion struct {
+	ParentID uint64 `json:"parentId"`
+	SubID    uint64 `json:"subId"`
+	Ratio    uint64 `json:"ratio"`
+	Remove   bool   `json:"remove"`
+}
+
+type WrapAsset struct {
+	SubID uint64 `json:"subId"`
+}
+
+type BurnAsset struct {
+	AssetID uint64   `json:"assetId,omitempty"`
+	Amount  *big.Int `json:"amount"`
+}
+
+type SetAssetBurnable struct {
+	AssetID  uint64 `json:"assetId,omitempty"`
+	Burnable bool   `json:"burnable"`
+}
+
+type AssetRoleAction struct {
+	AssetID uint64          `json:"assetId,omitempty"`
+	Role    asset.AssetRole `json:"role"`
+	Account common.Name     `json:"account"`
+}
+
+type PauseAsset struct {
+	AssetID uint64 `json:"assetId,omitempty"`
+	Paused  bool   `json:"paused"`
+}
+
+type SetAssetBanned struct {
+	AssetID uint64      `json:"assetId,omitempty"`
+	Account common.Name `json:"account"`
+	Banned  bool        `json:"banned"`
+}
+
+type RegisterWrappedAsset struct {
+	AssetID        uint64      `json:"assetId,omitempty"`
+	OriginChain    string      `json:"originChain"`
+	OriginContract string      `json:"originContract"`
+	Bridge         common.Name `json:"bridge"`
+}
+
+type BridgeMint struct {
+	AssetID uint64      `json:"assetId,omitempty"`
+	To      common.Name `json:"to"`
+	Amount  *big.Int    `json:"amount"`
+	Proof   []byte      `json:"proof"`
+}
+
+type BridgeBurn struct {
+	AssetID uint64   `json:"assetId,omitempty"`
+	Amount  *big.Int `json:"amount"`
+	Proof   []byte   `json:"proof"`
+}
+
+type UpdateAssetUpperLimit struct {
+	AssetID    uint64   `json:"assetId,omitempty"`
+	UpperLimit *big.Int `json:"upperLimit"`
+}
+
+type ApproveAssetUpperLimit struct {
+	AssetID    uint64   `json:"assetId,omitempty"`
+	UpperLimit *big.Int `json:"upperLimit"`
+}
+
+type SetAssetVerifier struct {
+	AssetID  uint64      `json:"assetId,omitempty"`
+	Verifier common.Name `json:"verifier"`
+}
+
+type UpdateAssetMetadata struct {
+	AssetID        uint64      `json:"assetId,omitempty"`
+	IconURL        string      `json:"iconURL"`
+	Website        string      `json:"website"`
+	WhitepaperHash common.Hash `json:"whitepaperHash"`
+	Extended       string      `json:"extended"`
+}
+
+type SetSubAssetDelegate struct {
+	AssetID  uint64      `json:"assetId,omitempty"`
+	Delegate common.Name `json:"delegate"`
+	Quota    uint64      `json:"quota"`
+}
+
+type UpdateAssetAccessList struct {
+	AssetID   uint64        `json:"assetId,omitempty"`
+	Allowlist bool          `json:"allowlist"`
+	Allowed   []common.Name `json:"allowed"`
+	Banned    []common.Name `json:"banned"`
+}
+
+type UpdateTokenList struct {
+	AssetID     uint64 `json:"assetId,omitempty"`
+	DisplayName string `json:"displayName"`
+	Symbol      string `json:"symbol"`
+	LogoURL     string `json:"logoURL"`
+	Website     string `json:"website"`
+	Remove      bool   `json:"remove"`
+}
+
 //AccountManager represents account management model.
 type AccountManager struct {
-	sdb *state.StateDB
-	ast *asset.Asset
+	sdb         *state.StateDB
+	ast         *asset.Asset
+	cache       map[uint64]*accountCacheEntry
+	authorCache map[common.Name]*Account
+	storageBase map[uint64]uint64
+	readOnly    bool
 }
 
 func SetAccountNameConfig(config *Config) bool {
@@ -134,6 +254,19 @@ func SetAccountNameConfig(config *Config) bool {
 	}
 	acctRegExp = regexp
 	accountNameLength = config.AccountNameMaxLength
+	acctNameReclaimBlocks = config.AccountNameReclaimBlocks
+	acctNameReuse = config.AccountNameReuse
+	blsAuthors = config.AllowBLSAuthor
+	chainAuthorLimits = defaultAuthorLimits
+	if config.MaxSignDepth != 0 {
+		chainAuthorLimits.signDepth = config.MaxSignDepth
+	}
+	if config.MaxSignLength != 0 {
+		chainAuthorLimits.signLength = config.MaxSignLength
+	}
+	if config.MaxAuthorNum != 0 {
+		chainAuthorLimits.authorNum = config.MaxAuthorNum
+	}
 	return true
 }
 func GetAcountNameRegExp() *regexp.Regexp {
@@ -163,8 +296,9 @@ func NewAccountManager(db *state.StateDB) (*AccountManager, error) {
 		return nil, ErrAccountManagerNotExist
 	}
 	am := &AccountManager{
-		sdb: db,
-		ast: asset.NewAsset(db),
+		sdb:   db,
+		ast:   asset.NewAsset(db),
+		cache: make(map[uint64]*accountCacheEntry),
 	}
 
 	am.initAccountCounter()
@@ -303,6 +437,9 @@ func (am *AccountManager) checkAccountNameValid(fromName common.Name, accountNam
 
 //CreateAccount create account
 func (am *AccountManager) CreateAccount(fromName common.Name, accountName common.Name, founderName common.Name, number uint64, curForkID uint64, pubkey common.PubKey, detail string) error {
+	if am.readOnly {
+		return ErrAccountManagerReadOnly
+	}
 	if curForkID >= params.ForkID1 {
 		if err := am.checkAccountNameValid(fromName, accountName); err != nil {
 			return err
@@ -325,7 +462,20 @@ func (am *AccountManager) CreateAccount(fromName common.Name, accountName common
 		return err
 	}
 	if accountID > 0 {
-		return ErrAccountIsExist
+		if !acctNameReuse {
+			return ErrAccountIsExist
+		}
+		if err := am.ReclaimAccountName(accountName, number); err != nil {
+			return ErrAccountIsExist
+		}
+	} else if !acctNameReuse {
+		archived, err := am.GetArchivedAccounts(accountName)
+		if err != nil {
+			return err
+		}
+		if len(archived) > 0 {
+			return ErrNameIsExist
+		}
 	}
 
 	// asset and account name diff
@@ -416,6 +566,7 @@ func (am *AccountManager) UpdateAccount(accountName common.Name, accountAction *
 	return am.SetAccount(acct)
 }
 
+//UpdateAccountAuthor update the authors and thresholds of the account
 func (am *AccountManager) UpdateAccountAuthor(accountName common.Name, acctAuth *AccountAuthorAction) error {
 	acct, err := am.GetAccountByName(accountName)
 	if acct == nil {
@@ -424,6 +575,66 @@ func (am *AccountManager) UpdateAccountAuthor(accountName common.Name, acctAuth
 	if err != nil {
 		return err
 	}
+	// the result authors are checked satisfiable from ForkID4
+	check, err := am.isForked(params.ForkID4)
+	if err != nil {
+		return err
+	}
+	if err := checkAuthorScopeFork(acctAuth, check); err != nil {
+		return err
+	}
+	limit, err := am.authorLimit()
+	if err != nil {
+		return err
+	}
+	if err := applyAccountAuthor(acct, acctAuth, check, limit.authorNum); err != nil {
+		return err
+	}
+	acct.SetAuthorVersion()
+	return am.SetAccount(acct)
+}
+
+//CheckAccountAuthor simulate the author action and check the result authors is still satisfiable
+func (am *AccountManager) CheckAccountAuthor(accountName common.Name, acctAuth *AccountAuthorAction) error {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	forked, err := am.isForked(params.ForkID4)
+	if err != nil {
+		return err
+	}
+	if err := checkAuthorScopeFork(acctAuth, forked); err != nil {
+		return err
+	}
+	limit, err := am.authorLimit()
+	if err != nil {
+		return err
+	}
+	return applyAccountAuthor(acct, acctAuth, true, limit.authorNum)
+}
+
+//checkAuthorScopeFork reject the author scopes before ForkID4, the earlier blocks decode no scope
+func checkAuthorScopeFork(acctAuth *AccountAuthorAction, forked bool) error {
+	if forked {
+		return nil
+	}
+	for _, authorAct := range acctAuth.AuthorActions {
+		if authorAct.ActionType == UpdateAuthorScope {
+			return fmt.Errorf("invalid account author operation type %d", authorAct.ActionType)
+		}
+		if authorAct.Author != nil && len(authorAct.Author.Scope) != 0 {
+			return types.ErrNotForked
+		}
+	}
+	return nil
+}
+
+//applyAccountAuthor apply the author action to the account, check whether the result authors are satisfiable
+func applyAccountAuthor(acct *Account, acctAuth *AccountAuthorAction, check bool, maxAuthorNum uint64) error {
 	if acctAuth.Threshold != 0 {
 		acct.SetThreshold(acctAuth.Threshold)
 	}
@@ -434,20 +645,30 @@ func (am *AccountManager) UpdateAccountAuthor(accountName common.Name, acctAuth
 		actionTy := authorAct.ActionType
 		switch actionTy {
 		case AddAuthor:
+			if err := checkBLSAuthor(authorAct.Author); err != nil {
+				return err
+			}
 			acct.AddAuthor(authorAct.Author)
 		case UpdateAuthor:
+			if err := checkBLSAuthor(authorAct.Author); err != nil {
+				return err
+			}
 			acct.UpdateAuthor(authorAct.Author)
+		case UpdateAuthorScope:
+			acct.UpdateAuthorScope(authorAct.Author)
 		case DeleteAuthor:
 			acct.DeleteAuthor(authorAct.Author)
 		default:
 			return fmt.Errorf("invalid account author operation type %d", actionTy)
 		}
 	}
-	if uint64(len(acct.Authors)) > params.MaxAuthorNum {
-		return fmt.Errorf("account author lenght can not exceed %d", params.MaxAuthorNum)
+	if uint64(len(acct.Authors)) > maxAuthorNum {
+		return fmt.Errorf("account author lenght can not exceed %d", maxAuthorNum)
 	}
-	acct.SetAuthorVersion()
-	return am.SetAccount(acct)
+	if !check {
+		return nil
+	}
+	return acct.CheckAuthors()
 }
 
 //GetAccountByTime get account by name and time
@@ -458,7 +679,16 @@ func (am *AccountManager) GetAccountByTime(accountName common.Name, time uint64)
 	}
 
 	snapshotManager := snapshot.NewSnapshotManager(am.sdb)
-	b, err := snapshotManager.GetSnapshotMsg(acctManagerName, acctInfoPrefix+strconv.FormatUint(accountID, 10), time)
+	snapshotState, err := snapshotManager.GetSnapshotState(time)
+	if err != nil {
+		return nil, err
+	}
+	return snapshotAccount(snapshotState, accountID)
+}
+
+//snapshotAccount get the account with its balances from the snapshot state
+func snapshotAccount(snapshotState *state.StateDB, accountID uint64) (*Account, error) {
+	b, err := snapshotState.Get(acctManagerName, acctInfoPrefix+strconv.FormatUint(accountID, 10))
 	if err != nil {
 		return nil, err
 	}
@@ -470,7 +700,12 @@ func (am *AccountManager) GetAccountByTime(accountName common.Name, time uint64)
 	if err := rlp.DecodeBytes(b, &acct); err != nil {
 		return nil, err
 	}
-
+	snapshotGet := func(key string) ([]byte, error) {
+		return snapshotState.Get(acctManagerName, key)
+	}
+	if err := loadBalances(snapshotGet, &acct); err != nil {
+		return nil, err
+	}
 	return &acct, nil
 }
 
@@ -502,8 +737,35 @@ func (am *AccountManager) GetAccountIDByName(accountName common.Name) (uint64, e
 	return accountID, nil
 }
 
+//GetAccountCounter get the id of the last created account
+func (am *AccountManager) GetAccountCounter() (uint64, error) {
+	return am.getAccountCounter()
+}
+
 //GetAccountById get account by account id
 func (am *AccountManager) GetAccountById(id uint64) (*Account, error) {
+	acct, err := am.getAccountMetaById(id)
+	if err != nil || acct == nil {
+		return acct, err
+	}
+	if err := loadBalances(am.stateGet, acct); err != nil {
+		return nil, err
+	}
+	return acct, nil
+}
+
+//getAccountMetaByName get account by name without loading the balances
+func (am *AccountManager) getAccountMetaByName(accountName common.Name) (*Account, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	return am.getAccountMetaById(accountID)
+}
+
+//getAccountMetaById get account by account id without loading the balances,
+//the account not migrated yet still carries its balances
+func (am *AccountManager) getAccountMetaById(id uint64) (*Account, error) {
 	if id == 0 {
 		return nil, nil
 	}
@@ -517,10 +779,14 @@ func (am *AccountManager) GetAccountById(id uint64) (*Account, error) {
 		log.Debug("account not exist", "id", ErrAccountNotExist, id)
 		return nil, nil
 	}
+	if acct := am.getCachedAccount(id, b); acct != nil {
+		return acct, nil
+	}
 	var acct Account
 	if err := rlp.DecodeBytes(b, &acct); err != nil {
 		return nil, err
 	}
+	am.setCachedAccount(id, b, &acct)
 	return &acct, nil
 }
 
@@ -532,18 +798,44 @@ func (am *AccountManager) SetAccount(acct *Account) error {
 	if acct.IsDestroyed() {
 		return ErrAccountIsDestroy
 	}
-	b, err := rlp.EncodeToBytes(acct)
+	return am.putAccount(acct)
+}
+
+//putAccount store the account object, from ForkID4 the balances are stored to the
+//per-asset keys and the account object without balances
+func (am *AccountManager) putAccount(acct *Account) error {
+	if am.readOnly {
+		return ErrAccountManagerReadOnly
+	}
+	split, err := am.splitBalances()
+	if err != nil {
+		return err
+	}
+	if err := am.touchStorage(acct.GetAccountID()); err != nil {
+		return err
+	}
+	meta := *acct
+	if split {
+		if err := am.storeBalances(acct); err != nil {
+			return err
+		}
+		meta.Balances = make([]*AssetBalance, 0)
+	}
+	b, err := rlp.EncodeToBytes(&meta)
 	if err != nil {
 		return err
 	}
 
 	//am.sdb.Put(acctManagerName, acctInfoPrefix+acct.GetName().String(), b)
-	am.sdb.Put(acctManagerName, acctInfoPrefix+strconv.FormatUint(acct.GetAccountID(), 10), b)
+	key := acctInfoPrefix + strconv.FormatUint(acct.GetAccountID(), 10)
+	am.sdb.Put(acctManagerName, key, b)
+	am.setCachedAccount(acct.GetAccountID(), b, &meta)
+	am.dropAuthorAccount(acct)
 	return nil
 }
 
 //DeleteAccountByName delete account
-func (am *AccountManager) DeleteAccountByName(accountName common.Name) error {
+func (am *AccountManager) DeleteAccountByName(accountName common.Name, number uint64) error {
 	acct, err := am.GetAccountByName(accountName)
 	if err != nil {
 		return ErrAccountNotExist
@@ -557,13 +849,18 @@ func (am *AccountManager) DeleteAccountByName(accountName common.Name) error {
 	if err != nil {
 		return err
 	}
+	dn, err := rlp.EncodeToBytes(&number)
+	if err != nil {
+		return err
+	}
 	am.sdb.Put(acct.GetName().String(), acctInfoPrefix, b)
+	am.sdb.Put(acctManagerName, acctDestroyPrefix+strconv.FormatUint(acct.GetAccountID(), 10), dn)
 	return nil
 }
 
 // GetNonce get nonce
 func (am *AccountManager) GetNonce(accountName common.Name) (uint64, error) {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getAccountMetaByName(accountName)
 	if err != nil {
 		return 0, err
 	}
@@ -575,7 +872,7 @@ func (am *AccountManager) GetNonce(accountName common.Name) (uint64, error) {
 
 // SetNonce set nonce
 func (am *AccountManager) SetNonce(accountName common.Name, nonce uint64) error {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getAccountMetaByName(accountName)
 	if err != nil {
 		return err
 	}
@@ -588,7 +885,7 @@ func (am *AccountManager) SetNonce(accountName common.Name, nonce uint64) error
 
 // GetAuthorVersion returns the account author version
 func (am *AccountManager) GetAuthorVersion(accountName common.Name) (common.Hash, error) {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getAccountMetaByName(accountName)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -619,14 +916,29 @@ func (am *AccountManager) getParentAccount(accountName common.Name, parentIndex
 
 // RecoverTx Make sure the transaction is signed properly and validate account authorization.
 func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction) error {
+	limit, err := am.authorLimit()
+	if err != nil {
+		return err
+	}
+	var payerVersion map[common.Name]common.Hash
+	if tx.Payer() != nil {
+		if payerVersion, err = am.recoverPayer(signer, tx, limit); err != nil {
+			return err
+		}
+	}
 	for _, action := range tx.GetActions() {
 		pubs, err := types.RecoverMultiKey(signer, action, tx)
 		if err != nil {
 			return err
 		}
 
-		if uint64(len(pubs)) > params.MaxSignLength {
-			return fmt.Errorf("exceed max sign length, want most %d, actual is %d", params.MaxSignLength, len(pubs))
+		agg := action.GetAggregateSign()
+		signLen := len(pubs)
+		if agg != nil {
+			signLen += len(agg.Indexes)
+		}
+		if uint64(signLen) > limit.signLength {
+			return fmt.Errorf("exceed max sign length, want most %d, actual is %d", limit.signLength, signLen)
 		}
 
 		parentIndex := action.GetSignParent()
@@ -634,17 +946,41 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 		if err != nil {
 			return err
 		}
-		recoverRes := &recoverActionResult{make(map[common.Name]*accountAuthor)}
+		recoverRes := &recoverActionResult{acctAuthors: make(map[common.Name]*accountAuthor), actionType: action.Type()}
+		if action.Type() == types.BatchAction {
+			subs, err := types.DecodeBatch(action.Data())
+			if err != nil {
+				return err
+			}
+			for _, sub := range subs {
+				recoverRes.subTypes = append(recoverRes.subTypes, sub.Type)
+			}
+		}
+		if action.Type() == types.ScheduleAction {
+			var schedule ScheduleAction
+			if err := rlp.DecodeBytes(action.Data(), &schedule); err != nil {
+				return err
+			}
+			if schedule.Action == nil {
+				return ErrScheduleInvalid
+			}
+			recoverRes.subTypes = append(recoverRes.subTypes, schedule.Action.Type)
+		}
 		for i, pub := range pubs {
 			index := action.GetSignIndex(uint64(i))
-			if uint64(len(index)) > params.MaxSignDepth {
-				return fmt.Errorf("exceed max sign depth, want most %d, actual is %d", params.MaxSignDepth, len(index))
+			if uint64(len(index)) > limit.signDepth {
+				return fmt.Errorf("exceed max sign depth, want most %d, actual is %d", limit.signDepth, len(index))
 			}
 
 			if err := am.ValidSign(signSender, pub, index, recoverRes); err != nil {
 				return err
 			}
 		}
+		if agg != nil {
+			if err := am.ValidAggregateSign(signSender, signer.Hash(tx), agg, recoverRes); err != nil {
+				return err
+			}
+		}
 
 		authorVersion := make(map[common.Name]common.Hash)
 		for name, acctAuthor := range recoverRes.acctAuthors {
@@ -653,7 +989,7 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 				count += weight
 			}
 			threshold := acctAuthor.threshold
-			if name.String() == signSender.String() && (action.Type() == types.UpdateAccountAuthor || signSender != action.Sender()) {
+			if name.String() == signSender.String() && (action.Type() == types.UpdateAccountAuthor || action.Type() == types.SetAuthorDelay || action.Type() == types.SetGuardians || action.Type() == types.SetInheritance || signSender != action.Sender()) {
 				threshold = acctAuthor.updateAuthorThreshold
 			}
 			if count < threshold {
@@ -661,12 +997,64 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 			}
 			authorVersion[name] = acctAuthor.version
 		}
+		// the changes of the payer authors invalidate the cached verification too
+		for name, version := range payerVersion {
+			authorVersion[name] = version
+		}
 
 		types.StoreAuthorCache(action, authorVersion)
 	}
 	return nil
 }
 
+// recoverPayer validates the signatures of the fee payer of the transaction,
+// the payer authors are checked against the transfer scope as the gas is paid
+// by the payer assets. It returns the author versions of the signing accounts.
+func (am *AccountManager) recoverPayer(signer types.Signer, tx *types.Transaction, limit authorLimits) (map[common.Name]common.Hash, error) {
+	payer := tx.Payer()
+	if payer.Sign != nil && len(payer.Sign.Aggregate) != 0 {
+		return nil, ErrAggregateSignInvalid
+	}
+	pubs, err := signer.PayerPubKeys(tx)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(pubs)) > limit.signLength {
+		return nil, fmt.Errorf("exceed max sign length, want most %d, actual is %d", limit.signLength, len(pubs))
+	}
+	signPayer, err := am.getParentAccount(payer.Name, payer.Sign.ParentIndex)
+	if err != nil {
+		return nil, err
+	}
+	recoverRes := &recoverActionResult{acctAuthors: make(map[common.Name]*accountAuthor), actionType: types.Transfer}
+	for i, pub := range pubs {
+		index := payer.Sign.SignData[i].Index
+		if uint64(len(index)) > limit.signDepth {
+			return nil, fmt.Errorf("exceed max sign depth, want most %d, actual is %d", limit.signDepth, len(index))
+		}
+		if err := am.ValidSign(signPayer, pub, index, recoverRes); err != nil {
+			return nil, err
+		}
+	}
+
+	authorVersion := make(map[common.Name]common.Hash)
+	for name, acctAuthor := range recoverRes.acctAuthors {
+		var count uint64
+		for _, weight := range acctAuthor.indexWeight {
+			count += weight
+		}
+		threshold := acctAuthor.threshold
+		if name.String() == signPayer.String() && signPayer != payer.Name {
+			threshold = acctAuthor.updateAuthorThreshold
+		}
+		if count < threshold {
+			return nil, fmt.Errorf("fee payer %s want threshold %d, but actual is %d", name, threshold, count)
+		}
+		authorVersion[name] = acctAuthor.version
+	}
+	return authorVersion, nil
+}
+
 // IsValidSign
 func (am *AccountManager) IsValidSign(accountName common.Name, pub common.PubKey) error {
 	acct, err := am.GetAccountByName(accountName)
@@ -691,37 +1079,44 @@ func (am *AccountManager) IsValidSign(accountName common.Name, pub common.PubKey
 
 //ValidSign check the sign
 func (am *AccountManager) ValidSign(accountName common.Name, pub common.PubKey, index []uint64, recoverRes *recoverActionResult) error {
-	acct, err := am.GetAccountByName(accountName)
+	acct, idx, err := am.signAuthor(accountName, index, recoverRes)
 	if err != nil {
 		return err
 	}
-	if acct == nil {
-		return ErrAccountNotExist
-	}
-	if acct.IsDestroyed() {
-		return ErrAccountIsDestroy
+	return am.ValidOneSign(acct, idx, pub, recoverRes)
+}
+
+// signAuthor follows the sign index through the account authors, it returns
+// the account of the signing author and its index in the authors.
+func (am *AccountManager) signAuthor(accountName common.Name, index []uint64, recoverRes *recoverActionResult) (*Account, uint64, error) {
+	acct, err := am.getAuthorAccount(accountName)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	var i int
 	var idx uint64
 	for i, idx = range index {
 		if idx >= uint64(len(acct.Authors)) {
-			return fmt.Errorf("acct authors modified")
+			return nil, 0, fmt.Errorf("acct authors modified")
+		}
+		if !types.ScopeAllows(acct.Authors[idx].Scope, recoverRes.actionType) {
+			return nil, 0, fmt.Errorf("%v %v author %v action type %d", acct.AcctName, ErrAuthorScope, acct.Authors[idx].Owner.String(), recoverRes.actionType)
+		}
+		// the author of a batch signs its sub-actions too
+		for _, subType := range recoverRes.subTypes {
+			if !types.ScopeAllows(acct.Authors[idx].Scope, subType) {
+				return nil, 0, fmt.Errorf("%v %v author %v action type %d", acct.AcctName, ErrAuthorScope, acct.Authors[idx].Owner.String(), subType)
+			}
 		}
 		if i == len(index)-1 {
 			break
 		}
 		switch ownerTy := acct.Authors[idx].Owner.(type) {
 		case common.Name:
-			nextacct, err := am.GetAccountByName(ownerTy)
+			nextacct, err := am.getAuthorAccount(ownerTy)
 			if err != nil {
-				return err
-			}
-			if nextacct == nil {
-				return ErrAccountNotExist
-			}
-			if nextacct.IsDestroyed() {
-				return ErrAccountIsDestroy
+				return nil, 0, err
 			}
 			if recoverRes.acctAuthors[acct.GetName()] == nil {
 				a := &accountAuthor{version: acct.AuthorVersion, threshold: acct.Threshold, updateAuthorThreshold: acct.UpdateAuthorThreshold, indexWeight: map[uint64]uint64{idx: acct.Authors[idx].GetWeight()}}
@@ -731,10 +1126,10 @@ func (am *AccountManager) ValidSign(accountName common.Name, pub common.PubKey,
 			}
 			acct = nextacct
 		default:
-			return ErrAccountNotExist
+			return nil, 0, ErrAccountNotExist
 		}
 	}
-	return am.ValidOneSign(acct, idx, pub, recoverRes)
+	return acct, idx, nil
 }
 
 func (am *AccountManager) ValidOneSign(acct *Account, index uint64, pub common.PubKey, recoverRes *recoverActionResult) error {
@@ -751,12 +1146,7 @@ func (am *AccountManager) ValidOneSign(acct *Account, index uint64, pub common.P
 	default:
 		return fmt.Errorf("wrong sign type")
 	}
-	if recoverRes.acctAuthors[acct.GetName()] == nil {
-		a := &accountAuthor{version: acct.AuthorVersion, threshold: acct.Threshold, updateAuthorThreshold: acct.UpdateAuthorThreshold, indexWeight: map[uint64]uint64{index: acct.Authors[index].GetWeight()}}
-		recoverRes.acctAuthors[acct.GetName()] = a
-		return nil
-	}
-	recoverRes.acctAuthors[acct.GetName()].indexWeight[index] = acct.Authors[index].GetWeight()
+	recoverRes.addAuthorWeight(acct, index)
 	return nil
 }
 
@@ -769,11 +1159,100 @@ func (am *AccountManager) GetAssetInfoByName(assetName string) (*asset.AssetObje
 	return am.ast.GetAssetObjectById(assetID)
 }
 
+//GetAssetIdBySymbol get the asset id by the symbol in the namespace, empty namespace for the main assets
+func (am *AccountManager) GetAssetIdBySymbol(namespace string, symbol string) (uint64, error) {
+	return am.ast.GetAssetIdBySymbol(namespace, symbol)
+}
+
+//GetAssetCount get the count of the issued assets
+func (am *AccountManager) GetAssetCount() (uint64, error) {
+	return am.ast.GetAssetCount()
+}
+
 //GetAssetInfoByID get asset info by assetID
 func (am *AccountManager) GetAssetInfoByID(assetID uint64) (*asset.AssetObject, error) {
 	return am.ast.GetAssetObjectById(assetID)
 }
 
+//GetTokenList get the token info of all listed assets
+func (am *AccountManager) GetTokenList() ([]*asset.TokenInfo, error) {
+	ids, err := am.ast.GetTokenList()
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]*asset.TokenInfo, 0, len(ids))
+	for _, id := range ids {
+		info, err := am.ast.GetTokenInfo(id)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, info)
+	}
+	return tokens, nil
+}
+
+//GetTokenInfo get the token info of the listed asset
+func (am *AccountManager) GetTokenInfo(assetID uint64) (*asset.TokenInfo, error) {
+	return am.ast.GetTokenInfo(assetID)
+}
+
+//GetAssetAccessList get the access list of the asset
+func (am *AccountManager) GetAssetAccessList(assetID uint64) (*asset.AssetAccessList, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	return am.ast.GetAssetAccessList(assetID)
+}
+
+//GetAssetRoles get the accounts granted the roles of the asset
+func (am *AccountManager) GetAssetRoles(assetID uint64) (*asset.AssetRoles, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	return am.ast.GetAssetRoles(assetID)
+}
+
+//GetAssetsByOwner get the assets owned by the account from the cursor, at most limit
+func (am *AccountManager) GetAssetsByOwner(owner common.Name, cursor uint64, limit uint64) (*asset.AccountAssets, error) {
+	return am.ast.GetAssetsByOwner(owner, cursor, limit)
+}
+
+//GetAssetsByFounder get the assets founded by the account from the cursor, at most limit
+func (am *AccountManager) GetAssetsByFounder(founder common.Name, cursor uint64, limit uint64) (*asset.AccountAssets, error) {
+	return am.ast.GetAssetsByFounder(founder, cursor, limit)
+}
+
+//GetWrappedAsset get the origin of the wrapped asset
+func (am *AccountManager) GetWrappedAsset(assetID uint64) (*asset.WrappedAsset, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	wrapped, err := am.ast.GetWrappedAsset(assetID)
+	if err != nil {
+		return nil, err
+	}
+	if wrapped == nil {
+		return nil, asset.ErrWrappedAssetNotExist
+	}
+	return wrapped, nil
+}
+
+//IsBridgeProofUsed check the proof has been consumed by the wrapped asset
+func (am *AccountManager) IsBridgeProofUsed(assetID uint64, proof []byte) (bool, error) {
+	if _, err := am.GetWrappedAsset(assetID); err != nil {
+		return false, err
+	}
+	return am.ast.IsBridgeProofUsed(assetID, proof)
+}
+
+//IsAssetPaused check the transfers of the asset are paused
+func (am *AccountManager) IsAssetPaused(assetID uint64) (bool, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return false, err
+	}
+	return am.ast.IsAssetPaused(assetID)
+}
+
 // GetAllAssetbyAssetId get accout asset and subAsset Info
 func (am *AccountManager) GetAllAssetbyAssetId(acct *Account, assetId uint64) (map[uint64]*big.Int, error) {
 	var ba = make(map[uint64]*big.Int)
@@ -863,7 +1342,7 @@ func (am *AccountManager) GetBalanceByTime(accountName common.Name, assetID uint
 
 //GetAccountBalanceByID get account balance by ID
 func (am *AccountManager) GetAccountBalanceByID(accountName common.Name, assetID uint64, typeID uint64) (*big.Int, error) {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getAccountMetaByName(accountName)
 	if err != nil {
 		return big.NewInt(0), err
 	}
@@ -871,23 +1350,49 @@ func (am *AccountManager) GetAccountBalanceByID(accountName common.Name, assetID
 		return big.NewInt(0), ErrAccountNotExist
 	}
 	if typeID == 0 {
-		return acct.GetBalanceByID(assetID)
+		return am.accountBalance(acct, assetID)
 	} else if typeID == 1 {
+		if err := loadBalances(am.stateGet, acct); err != nil {
+			return big.NewInt(0), err
+		}
 		return am.GetAllBalancebyAssetID(acct, assetID)
 	} else {
 		return big.NewInt(0), fmt.Errorf("type ID %d invalid", typeID)
 	}
 }
 
-//GetAssetAmountByTime get asset amount by time
-func (am *AccountManager) GetAssetAmountByTime(assetID uint64, time uint64) (*big.Int, error) {
-	return am.ast.GetAssetAmountByTime(assetID, time)
-}
-
-//GetAccountLastChange account balance last change time
-func (am *AccountManager) GetAccountLastChange(accountName common.Name) (uint64, error) {
-	//TODO
-	return 0, nil
+//GetBalancesBatch get the balances of every account for every asset, each account loaded only once
+func (am *AccountManager) GetBalancesBatch(accounts []common.Name, assetIDs []uint64) ([][]*big.Int, error) {
+	balances := make([][]*big.Int, len(accounts))
+	for i, accountName := range accounts {
+		acct, err := am.getAccountMetaByName(accountName)
+		if err != nil {
+			return nil, err
+		}
+		if acct == nil {
+			return nil, fmt.Errorf("%v %v", ErrAccountNotExist, accountName)
+		}
+		balances[i] = make([]*big.Int, len(assetIDs))
+		for j, assetID := range assetIDs {
+			balance, err := am.accountBalance(acct, assetID)
+			if err != nil && err != ErrAccountAssetNotExist {
+				return nil, err
+			}
+			balances[i][j] = new(big.Int).Set(balance)
+		}
+	}
+	return balances, nil
+}
+
+//GetAssetAmountByTime get asset amount by time
+func (am *AccountManager) GetAssetAmountByTime(assetID uint64, time uint64) (*big.Int, error) {
+	return am.ast.GetAssetAmountByTime(assetID, time)
+}
+
+//GetAccountLastChange account balance last change time
+func (am *AccountManager) GetAccountLastChange(accountName common.Name) (uint64, error) {
+	//TODO
+	return 0, nil
 }
 
 //GetSnapshotTime get snapshot time
@@ -921,6 +1426,11 @@ func (am *AccountManager) GetSnapshotTime(num uint64, time uint64) (uint64, erro
 	return 0, ErrTimeTypeInvalid
 }
 
+//GetSnapshotTimes get the times of the last max snapshots, the latest first
+func (am *AccountManager) GetSnapshotTimes(max int) ([]uint64, error) {
+	return snapshot.NewSnapshotManager(am.sdb).GetSnapshotTimes(max)
+}
+
 //GetFounder Get Account Founder
 func (am *AccountManager) GetFounder(accountName common.Name) (common.Name, error) {
 	acct, err := am.GetAccountByName(accountName)
@@ -938,9 +1448,82 @@ func (am *AccountManager) GetAssetFounder(assetID uint64) (common.Name, error) {
 	return am.ast.GetAssetFounderById(assetID)
 }
 
+//IsAssetBurnable check the holders can burn their balances of the asset
+func (am *AccountManager) IsAssetBurnable(assetID uint64) (bool, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return false, err
+	}
+	return am.ast.IsAssetBurnable(assetID)
+}
+
+//GetAssetMetadata get the metadata of the asset
+func (am *AccountManager) GetAssetMetadata(assetID uint64) (*asset.AssetMetadata, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	return am.ast.GetAssetMetadata(assetID)
+}
+
+//GetSubAssetDelegation get the sub-assets the delegate may issue under the namespace of the asset
+func (am *AccountManager) GetSubAssetDelegation(assetID uint64, delegate common.Name) (*asset.SubAssetDelegation, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	return am.ast.GetSubAssetDelegation(assetID, delegate)
+}
+
+//GetUpperLimitApprovers get the approvers of raising the upper limit of the asset
+func (am *AccountManager) GetUpperLimitApprovers(assetID uint64) ([]common.Name, error) {
+	return am.ast.GetUpperLimitApprovers(assetID)
+}
+
+//GetAssetVerifier get the contract verifying the issuance and ownership changes of the asset
+func (am *AccountManager) GetAssetVerifier(assetID uint64) (common.Name, error) {
+	return am.ast.GetAssetVerifier(assetID)
+}
+
+//verifyAsset consult the verification contract of the asset, if any, before the action
+//issues the amount to the target or changes the owner to the target
+func (am *AccountManager) verifyAsset(accountManagerContext *types.AccountManagerContext, assetID uint64, target common.Name, amount *big.Int) error {
+	verifier, err := am.ast.GetAssetVerifier(assetID)
+	if err != nil || verifier == "" {
+		return err
+	}
+	if accountManagerContext.VerifyAsset == nil {
+		return ErrAssetVerifierUnusable
+	}
+	return accountManagerContext.VerifyAsset(verifier, accountManagerContext.Action, assetID, target, amount)
+}
+
+//GetPendingUpperLimit get the proposed raise of the upper limit of the asset
+func (am *AccountManager) GetPendingUpperLimit(assetID uint64) (*asset.PendingUpperLimit, error) {
+	return am.ast.GetPendingUpperLimit(assetID)
+}
+
+//GetPendingAssetOwner get the proposed owner waiting to accept the asset
+func (am *AccountManager) GetPendingAssetOwner(assetID uint64) (*asset.PendingAssetOwner, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	return am.ast.GetPendingAssetOwner(assetID)
+}
+
+//GetAssetSupplyHistory get the supply changes of the asset between the blocks
+func (am *AccountManager) GetAssetSupplyHistory(assetID uint64, fromNumber uint64, toNumber uint64) ([]*asset.SupplyChange, error) {
+	return am.ast.GetSupplyHistory(assetID, fromNumber, toNumber)
+}
+
+//BurnAsset destroy the amount of the asset held by the account
+func (am *AccountManager) BurnAsset(accountName common.Name, assetID uint64, value *big.Int) error {
+	if err := am.SubAccountBalanceByID(accountName, assetID, value); err != nil {
+		return err
+	}
+	return am.ast.DestroyAsset(accountName, assetID, value)
+}
+
 //SubAccountBalanceByID sub balance by assetID
 func (am *AccountManager) SubAccountBalanceByID(accountName common.Name, assetID uint64, value *big.Int) error {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getBalanceAccountByName(accountName)
 	if err != nil {
 		return err
 	}
@@ -952,17 +1535,32 @@ func (am *AccountManager) SubAccountBalanceByID(accountName common.Name, assetID
 		return ErrAmountValueInvalid
 	}
 
-	err = acct.SubBalanceByID(assetID, value)
+	val, err := am.accountBalance(acct, assetID)
 	if err != nil {
 		return err
 	}
+	if val.Cmp(big.NewInt(0)) < 0 || val.Cmp(value) < 0 {
+		return ErrInsufficientBalance
+	}
+	_, err = am.setAccountBalance(acct.GetAccountID(), assetID, new(big.Int).Sub(val, value))
+	return err
+}
 
-	return am.SetAccount(acct)
+//addAccountBalance add balance to the account loaded without balances
+func (am *AccountManager) addAccountBalance(acct *Account, assetID uint64, value *big.Int) (bool, error) {
+	if value.Cmp(big.NewInt(0)) < 0 {
+		return false, ErrAmountValueInvalid
+	}
+	val, err := am.accountBalance(acct, assetID)
+	if err != nil && err != ErrAccountAssetNotExist {
+		return false, err
+	}
+	return am.setAccountBalance(acct.GetAccountID(), assetID, new(big.Int).Add(val, value))
 }
 
 //AddAccountBalanceByID add balance by assetID
 func (am *AccountManager) AddAccountBalanceByID(accountName common.Name, assetID uint64, value *big.Int) error {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getBalanceAccountByName(accountName)
 	if err != nil {
 		return err
 	}
@@ -970,21 +1568,13 @@ func (am *AccountManager) AddAccountBalanceByID(accountName common.Name, assetID
 		return ErrAccountNotExist
 	}
 
-	if value.Cmp(big.NewInt(0)) < 0 {
-		return ErrAmountValueInvalid
-	}
-
-	_, err = acct.AddBalanceByID(assetID, value)
-	if err != nil {
-		return err
-	}
-
-	return am.SetAccount(acct)
+	_, err = am.addAccountBalance(acct, assetID, value)
+	return err
 }
 
 //AddAccountBalanceByName  add balance by name
 func (am *AccountManager) AddAccountBalanceByName(accountName common.Name, assetName string, value *big.Int) error {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getBalanceAccountByName(accountName)
 	if err != nil {
 		return err
 	}
@@ -997,21 +1587,13 @@ func (am *AccountManager) AddAccountBalanceByName(accountName common.Name, asset
 		return err
 	}
 
-	if value.Cmp(big.NewInt(0)) < 0 {
-		return ErrAmountValueInvalid
-	}
-
-	_, err = acct.AddBalanceByID(assetID, value)
-	if err != nil {
-		return err
-	}
-
-	return am.SetAccount(acct)
+	_, err = am.addAccountBalance(acct, assetID, value)
+	return err
 }
 
 //
 func (am *AccountManager) EnoughAccountBalance(accountName common.Name, assetID uint64, value *big.Int) error {
-	acct, err := am.GetAccountByName(accountName)
+	acct, err := am.getAccountMetaByName(accountName)
 	if err != nil {
 		return err
 	}
@@ -1021,7 +1603,14 @@ func (am *AccountManager) EnoughAccountBalance(accountName common.Name, assetID
 	if value.Cmp(big.NewInt(0)) < 0 {
 		return ErrAmountValueInvalid
 	}
-	return acct.EnoughAccountBalance(assetID, value)
+	val, err := am.accountBalance(acct, assetID)
+	if err != nil {
+		return err
+	}
+	if val.Cmp(value) < 0 {
+		return ErrInsufficientBalance
+	}
+	return nil
 }
 
 //
@@ -1111,8 +1700,13 @@ func (am *AccountManager) CanTransfer(accountName common.Name, assetID uint64, v
 	return false, err
 }
 
-//TransferAsset transfer asset
+//TransferAsset transfer asset and pay the asset transfer fee, the non-fungible asset can only be transferred by token
 func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int, fromAccountExtra ...common.Name) error {
+	_, _, err := am.transferAssetWithFee(fromAccount, toAccount, assetID, value, fromAccountExtra...)
+	return err
+}
+
+func (am *AccountManager) transferAsset(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int, fromAccountExtra ...common.Name) error {
 	if sign := value.Sign(); sign == 0 {
 		return nil
 	} else if sign == -1 {
@@ -1124,12 +1718,17 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 	if !am.ast.HasAccess(assetID, fromAccountExtra...) {
 		return fmt.Errorf("no permissions of asset %v", assetID)
 	}
+	if paused, err := am.ast.IsAssetPaused(assetID); err != nil {
+		return err
+	} else if paused {
+		return ErrAssetPaused
+	}
 	// if !am.ast.HasAccess(assetID, fromAccount, toAccount) {
 	// 	return fmt.Errorf("no permissions of asset %v", assetID)
 	// }
 
 	//check from account
-	fromAcct, err := am.GetAccountByName(fromAccount)
+	fromAcct, err := am.getBalanceAccountByName(fromAccount)
 	if err != nil {
 		return err
 	}
@@ -1138,7 +1737,7 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 	}
 
 	//check from account balance
-	val, err := fromAcct.GetBalanceByID(assetID)
+	val, err := am.accountBalance(fromAcct, assetID)
 	if err != nil {
 		return err
 	}
@@ -1147,13 +1746,20 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 		return ErrInsufficientBalance
 	}
 
+	//check the balance locked by the asset owner
+	locked, err := am.lockedBalance(fromAcct.GetAccountID(), assetID)
+	if err != nil {
+		return err
+	}
+	if locked.Sign() > 0 && new(big.Int).Sub(val, locked).Cmp(value) < 0 {
+		return ErrBalanceLocked
+	}
+
 	if fromAccount == toAccount || value.Cmp(big.NewInt(0)) == 0 {
 		return nil
 	}
-	//sub from account balance
-	fromAcct.SetBalance(assetID, new(big.Int).Sub(val, value))
 	//check to account
-	toAcct, err := am.GetAccountByName(toAccount)
+	toAcct, err := am.getBalanceAccountByName(toAccount)
 	if err != nil {
 		return err
 	}
@@ -1163,8 +1769,12 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 	if toAcct.IsDestroyed() {
 		return ErrAccountIsDestroy
 	}
+	//sub from account balance
+	if _, err := am.setAccountBalance(fromAcct.GetAccountID(), assetID, new(big.Int).Sub(val, value)); err != nil {
+		return err
+	}
 	//add to account balance
-	bNew, err := toAcct.AddBalanceByID(assetID, value)
+	bNew, err := am.addAccountBalance(toAcct, assetID, value)
 	if err != nil {
 		return err
 	}
@@ -1174,10 +1784,7 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 			return err
 		}
 	}
-	if err = am.SetAccount(fromAcct); err != nil {
-		return err
-	}
-	return am.SetAccount(toAcct)
+	return nil
 }
 
 func (am *AccountManager) CheckAssetContract(contract common.Name, owner common.Name, from ...common.Name) bool {
@@ -1222,7 +1829,19 @@ func (am *AccountManager) checkAssetNameAndOwner(fromName common.Name, assetInfo
 	//check sub asset owner
 	parentAassetID, isValid := am.ast.IsValidAssetOwner(fromName, assetPrex, assetNames)
 	if !isValid {
-		return fmt.Errorf("asset owner is invalid, name: %v", assetInfo.AssetName)
+		// the delegates of the parent asset issue within their quota
+		parentName := assetPrex + strings.Join(assetNames[:len(assetNames)-1], ".")
+		parentID, err := am.ast.GetAssetIdByName(parentName)
+		if err != nil {
+			return fmt.Errorf("asset owner is invalid, name: %v", assetInfo.AssetName)
+		}
+		if delegation, err := am.ast.GetSubAssetDelegation(parentID, fromName); err != nil || delegation.Quota == 0 {
+			return fmt.Errorf("asset owner is invalid, name: %v", assetInfo.AssetName)
+		}
+		if err := am.ast.UseSubAssetDelegation(parentID, fromName); err != nil {
+			return err
+		}
+		parentAassetID = parentID
 	}
 	assetObj, _ := am.ast.GetAssetObjectById(parentAassetID)
 	assetInfo.Decimals = assetObj.GetDecimals()
@@ -1263,6 +1882,13 @@ func (am *AccountManager) checkAssetInfoValid(fromName common.Name, assetInfo *I
 
 //IssueAsset issue asset
 func (am *AccountManager) IssueAsset(fromName common.Name, asset IssueAsset, number uint64, curForkID uint64) (uint64, error) {
+	if am.readOnly {
+		return 0, ErrAccountManagerReadOnly
+	}
+	// the issue payloads carry no upper limit approvers before ForkID4
+	if curForkID < params.ForkID4 && len(asset.UpperLimitApprovers) != 0 {
+		return 0, types.ErrNotForked
+	}
 	//check owner valid
 	if curForkID >= params.ForkID1 {
 		err := am.checkAssetInfoValid(fromName, &asset)
@@ -1325,12 +1951,26 @@ func (am *AccountManager) IssueAsset(fromName common.Name, asset IssueAsset, num
 		return 0, ErrNameIsExist
 	}
 
+	// check upper limit approvers
+	for _, approver := range asset.UpperLimitApprovers {
+		a, err := am.GetAccountByName(approver)
+		if err != nil {
+			return 0, err
+		}
+		if a == nil {
+			return 0, ErrAccountNotExist
+		}
+	}
+
 	assetID, err := am.ast.IssueAsset(asset.AssetName, number, curForkID, asset.Symbol,
 		asset.Amount, asset.Decimals, asset.Founder, asset.Owner,
 		asset.UpperLimit, asset.Contract, asset.Description)
 	if err != nil {
 		return 0, err
 	}
+	if err := am.ast.SetUpperLimitApprovers(assetID, asset.UpperLimitApprovers); err != nil {
+		return 0, err
+	}
 
 	//add the asset to owner
 	return assetID, nil
@@ -1338,9 +1978,14 @@ func (am *AccountManager) IssueAsset(fromName common.Name, asset IssueAsset, num
 
 //IncAsset2Acct increase asset and add amount to accout balance
 func (am *AccountManager) IncAsset2Acct(fromName common.Name, toName common.Name, assetID uint64, amount *big.Int) error {
-	if err := am.ast.CheckOwner(fromName, assetID); err != nil {
+	if err := am.ast.CheckAssetRole(fromName, assetID, asset.RoleMinter); err != nil {
 		return err
 	}
+	if wrapped, err := am.ast.IsWrappedAsset(assetID); err != nil {
+		return err
+	} else if wrapped {
+		return ErrWrappedAssetSupply
+	}
 
 	if err := am.ast.IncreaseAsset(fromName, assetID, amount); err != nil {
 		return err
@@ -1350,10 +1995,14 @@ func (am *AccountManager) IncAsset2Acct(fromName common.Name, toName common.Name
 
 //Process account action
 func (am *AccountManager) Process(accountManagerContext *types.AccountManagerContext) ([]*types.InternalAction, error) {
+	if am.readOnly {
+		return nil, ErrAccountManagerReadOnly
+	}
 	snap := am.sdb.Snapshot()
 	internalActions, err := am.process(accountManagerContext)
 	if err != nil {
 		am.sdb.RevertToSnapshot(snap)
+		am.resetCache()
 	}
 	return internalActions, err
 }
@@ -1368,12 +2017,26 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 	if err := action.Check(accountManagerContext.ChainConfig); err != nil {
 		return nil, err
 	}
+	forkID, err := am.curForkID()
+	if err != nil {
+		return nil, err
+	}
+	if !action.Type().IsForked(forkID) {
+		return nil, types.ErrActionUndefined
+	}
+	if err := action.CheckForkFields(forkID); err != nil {
+		return nil, err
+	}
 
 	var internalActions []*types.InternalAction
-	//transfer
-	if err := am.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), fromAccountExtra...); err != nil {
+	//transfer, the fee taken by the asset founder is an internal action
+	_, feeAction, err := am.transferAssetWithFee(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), fromAccountExtra...)
+	if err != nil {
 		return nil, err
 	}
+	if feeAction != nil {
+		internalActions = append(internalActions, feeAction)
+	}
 
 	//transaction
 	switch action.Type() {
@@ -1384,10 +2047,27 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 			return nil, err
 		}
 
+		if err := am.checkReservedName(action.Sender(), acct.AccountName, accountManagerContext.ChainConfig); err != nil {
+			return nil, err
+		}
+		priceCfg := accountManagerContext.ChainConfig.NamePriceCfg
+		if IsPremiumAccountName(priceCfg, acct.AccountName) {
+			return nil, ErrAccountNamePremium
+		}
 		if err := am.CreateAccount(action.Sender(), acct.AccountName, acct.Founder, number, curForkID, acct.PublicKey, acct.Description); err != nil {
 			return nil, err
 		}
 
+		if level, _ := GetAccountNameLevel(acct.AccountName); level == mainAccount && priceCfg != nil && priceCfg.CreateFee != nil && priceCfg.CreateFee.Sign() > 0 {
+			to := common.Name(accountManagerContext.ChainConfig.AccountName)
+			if err := am.TransferAsset(action.Sender(), to, accountManagerContext.ChainConfig.SysTokenID, priceCfg.CreateFee, fromAccountExtra...); err != nil {
+				return nil, err
+			}
+			actionX := types.NewAction(types.Transfer, action.Sender(), to, 0, accountManagerContext.ChainConfig.SysTokenID, 0, priceCfg.CreateFee, nil, nil)
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
+		}
+
 		if action.Value().Cmp(big.NewInt(0)) > 0 {
 			if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), acct.AccountName, action.AssetID(), action.Value(), fromAccountExtra...); err != nil {
 				return nil, err
@@ -1406,136 +2086,797 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.UpdateAccount(action.Sender(), &acct); err != nil {
 			return nil, err
 		}
+	case types.DeleteAccount:
+		// not supported before ForkID4
+		if forkID < params.ForkID4 {
+			return nil, ErrUnkownTxType
+		}
+		if err := am.DestroyAccount(action.Sender(), number); err != nil {
+			return nil, err
+		}
 	case types.UpdateAccountAuthor:
 		var acctAuth AccountAuthorAction
 		err := rlp.DecodeBytes(action.Data(), &acctAuth)
 		if err != nil {
 			return nil, err
 		}
-		if err := am.UpdateAccountAuthor(action.Sender(), &acctAuth); err != nil {
+		delayed, err := am.DelayAccountAuthor(action.Sender(), number, &acctAuth)
+		if err != nil {
 			return nil, err
 		}
-	case types.IssueAsset:
-		var issueAsset IssueAsset
-		err := rlp.DecodeBytes(action.Data(), &issueAsset)
+		if !delayed {
+			if err := am.UpdateAccountAuthor(action.Sender(), &acctAuth); err != nil {
+				return nil, err
+			}
+		}
+	case types.TransferEscrow:
+		var escrow TransferEscrowAction
+		err := rlp.DecodeBytes(action.Data(), &escrow)
 		if err != nil {
 			return nil, err
 		}
-		fromAccountExtra = append(fromAccountExtra, action.Sender())
-
-		if len(issueAsset.Contract) != 0 {
-			if !am.CheckAssetContract(issueAsset.Contract, issueAsset.Owner, fromAccountExtra...) && issueAsset.Amount.Sign() != 0 {
-				return nil, ErrAmountMustBeZero
-			}
+		if _, err := am.TransferEscrow(action.Sender(), action.AssetID(), action.Value(), number, &escrow); err != nil {
+			return nil, err
 		}
-
-		assetID, err := am.IssueAsset(action.Sender(), issueAsset, number, curForkID)
+	case types.ClaimEscrow:
+		var claim ClaimEscrowAction
+		err := rlp.DecodeBytes(action.Data(), &claim)
 		if err != nil {
 			return nil, err
 		}
-
-		if err := am.AddAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), assetID, issueAsset.Amount); err != nil {
+		escrow, err := am.ClaimEscrow(action.Sender(), number, &claim)
+		if err != nil {
 			return nil, err
 		}
-		actionX := types.NewAction(types.Transfer, common.Name(""), common.Name(accountManagerContext.ChainConfig.AssetName), 0, assetID, 0, issueAsset.Amount, nil, nil)
-		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
-		internalActions = append(internalActions, internalAction)
-
-		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), issueAsset.Owner, assetID, issueAsset.Amount, fromAccountExtra...); err != nil {
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), escrow.Recipient, escrow.AssetID, escrow.Amount, fromAccountExtra...); err != nil {
 			return nil, err
 		}
-		actionX = types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), issueAsset.Owner, 0, assetID, 0, issueAsset.Amount, nil, nil)
-		internalAction = &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AccountName), escrow.Recipient, 0, escrow.AssetID, 0, escrow.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
 		internalActions = append(internalActions, internalAction)
-	case types.IncreaseAsset:
-		var inc IncAsset
-		err := rlp.DecodeBytes(action.Data(), &inc)
+	case types.AtomicSwap:
+		var swap AtomicSwapAction
+		err := rlp.DecodeBytes(action.Data(), &swap)
 		if err != nil {
 			return nil, err
 		}
-
-		if inc.Amount.Cmp(big.NewInt(0)) < 0 {
-			return nil, ErrNegativeAmount
-		}
-
-		if err := am.IncAsset2Acct(action.Sender(), inc.To, inc.AssetId, inc.Amount); err != nil {
+		if err := am.AtomicSwap(action.Sender(), number, accountManagerContext.ChainConfig, &swap); err != nil {
 			return nil, err
 		}
-
-		if err := am.AddAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), inc.AssetId, inc.Amount); err != nil {
+		for _, leg := range []SwapLeg{swap.LegA, swap.LegB} {
+			to := swap.LegB.From
+			if leg.From == swap.LegB.From {
+				to = swap.LegA.From
+			}
+			if err := am.TransferAsset(leg.From, to, leg.AssetID, leg.Amount, fromAccountExtra...); err != nil {
+				return nil, err
+			}
+			actionX := types.NewAction(types.Transfer, leg.From, to, 0, leg.AssetID, 0, leg.Amount, nil, nil)
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
+		}
+	case types.PermitTransfer:
+		var permit PermitTransferAction
+		err := rlp.DecodeBytes(action.Data(), &permit)
+		if err != nil {
 			return nil, err
 		}
-		actionX := types.NewAction(types.Transfer, common.Name(""), common.Name(accountManagerContext.ChainConfig.AssetName), 0, inc.AssetId, 0, inc.Amount, nil, nil)
-		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
-		internalActions = append(internalActions, internalAction)
-
-		fromAccountExtra = append(fromAccountExtra, action.Sender())
-		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), inc.To, inc.AssetId, inc.Amount, fromAccountExtra...); err != nil {
+		if err := am.PermitTransfer(action.Sender(), number, accountManagerContext.ChainConfig, &permit); err != nil {
 			return nil, err
 		}
-		actionX = types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), inc.To, 0, inc.AssetId, 0, inc.Amount, nil, nil)
-		internalAction = &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
-		internalActions = append(internalActions, internalAction)
-	case types.DestroyAsset:
-		if err := am.SubAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), action.AssetID(), action.Value()); err != nil {
+		transfers := []struct {
+			to     common.Name
+			amount *big.Int
+		}{{permit.Recipient, permit.Amount}, {action.Sender(), permit.Fee}}
+		for _, transfer := range transfers {
+			if transfer.amount.Sign() == 0 {
+				continue
+			}
+			if err := am.TransferAsset(permit.Payer, transfer.to, permit.AssetID, transfer.amount, fromAccountExtra...); err != nil {
+				return nil, err
+			}
+			actionX := types.NewAction(types.Transfer, permit.Payer, transfer.to, 0, permit.AssetID, 0, transfer.amount, nil, nil)
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
+		}
+	case types.BatchAction:
+		batchActions, err := am.processBatch(accountManagerContext)
+		if err != nil {
 			return nil, err
 		}
-
-		if err := am.ast.DestroyAsset(common.Name(accountManagerContext.ChainConfig.AssetName), action.AssetID(), action.Value()); err != nil {
+		internalActions = append(internalActions, batchActions...)
+	case types.ScheduleAction:
+		var schedule ScheduleAction
+		err := rlp.DecodeBytes(action.Data(), &schedule)
+		if err != nil {
 			return nil, err
 		}
-		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), common.Name(""), 0, action.AssetID(), 0, action.Value(), nil, nil)
-		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
-		internalActions = append(internalActions, internalAction)
-	case types.UpdateAsset:
-		var asset UpdateAsset
-		err := rlp.DecodeBytes(action.Data(), &asset)
+		scheduled, err := am.Schedule(action.Sender(), number, accountManagerContext.ChainConfig, &schedule)
 		if err != nil {
 			return nil, err
 		}
-
-		if len(asset.Founder.String()) > 0 {
-			acct, err := am.GetAccountByName(asset.Founder)
-			if err != nil {
+		if scheduled.Fee.Sign() > 0 {
+			to := common.Name(accountManagerContext.ChainConfig.AccountName)
+			if err := am.TransferAsset(action.Sender(), to, accountManagerContext.ChainConfig.SysTokenID, scheduled.Fee, fromAccountExtra...); err != nil {
 				return nil, err
 			}
-			if acct == nil {
-				return nil, ErrAccountNotExist
-			}
+			actionX := types.NewAction(types.Transfer, action.Sender(), to, 0, accountManagerContext.ChainConfig.SysTokenID, 0, scheduled.Fee, nil, nil)
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
 		}
-
-		if err := am.ast.CheckOwner(action.Sender(), asset.AssetID); err != nil {
+	case types.CancelScheduledAction:
+		var cancel CancelScheduledAction
+		err := rlp.DecodeBytes(action.Data(), &cancel)
+		if err != nil {
 			return nil, err
 		}
-
-		if err := am.ast.UpdateAsset(action.Sender(), asset.AssetID, asset.Founder); err != nil {
+		scheduled, err := am.CancelScheduled(action.Sender(), &cancel)
+		if err != nil {
 			return nil, err
 		}
-	case types.SetAssetOwner:
-		var asset UpdateAssetOwner
-		err := rlp.DecodeBytes(action.Data(), &asset)
+		if scheduled.Fee.Sign() > 0 {
+			from := common.Name(accountManagerContext.ChainConfig.AccountName)
+			if err := am.TransferAsset(from, scheduled.Sender, accountManagerContext.ChainConfig.SysTokenID, scheduled.Fee); err != nil {
+				return nil, err
+			}
+			actionX := types.NewAction(types.Transfer, from, scheduled.Sender, 0, accountManagerContext.ChainConfig.SysTokenID, 0, scheduled.Fee, nil, nil)
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
+		}
+	case types.RefundEscrow:
+		var refund RefundEscrowAction
+		err := rlp.DecodeBytes(action.Data(), &refund)
 		if err != nil {
 			return nil, err
 		}
-		acct, err := am.GetAccountByName(asset.Owner)
+		escrow, err := am.RefundEscrow(number, &refund)
 		if err != nil {
 			return nil, err
 		}
-		if acct == nil {
-			return nil, ErrAccountNotExist
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), escrow.Sender, escrow.AssetID, escrow.Amount, fromAccountExtra...); err != nil {
+			return nil, err
 		}
-
-		// check owner
-		if err := am.ast.CheckOwner(action.Sender(), asset.AssetID); err != nil {
+		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AccountName), escrow.Sender, 0, escrow.AssetID, 0, escrow.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.TimeLockTransfer:
+		var lock TimeLockTransferAction
+		err := rlp.DecodeBytes(action.Data(), &lock)
+		if err != nil {
 			return nil, err
 		}
-
-		if err := am.ast.SetAssetNewOwner(action.Sender(), asset.AssetID, asset.Owner); err != nil {
+		if _, err := am.TimeLockTransfer(action.Sender(), action.AssetID(), action.Value(), number, accountManagerContext.Time, &lock); err != nil {
 			return nil, err
 		}
-	case types.UpdateAssetContract:
-		var assetContract UpdateAssetContract
-		err := rlp.DecodeBytes(action.Data(), &assetContract)
+	case types.ClaimTimeLock:
+		var claim TimeLockAction
+		err := rlp.DecodeBytes(action.Data(), &claim)
+		if err != nil {
+			return nil, err
+		}
+		lock, err := am.ClaimTimeLock(action.Sender(), number, accountManagerContext.Time, &claim)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), lock.Recipient, lock.AssetID, lock.Amount, fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AccountName), lock.Recipient, 0, lock.AssetID, 0, lock.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.CancelTimeLock:
+		var cancel TimeLockAction
+		err := rlp.DecodeBytes(action.Data(), &cancel)
+		if err != nil {
+			return nil, err
+		}
+		lock, err := am.CancelTimeLock(action.Sender(), number, accountManagerContext.Time, &cancel)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), lock.Sender, lock.AssetID, lock.Amount, fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AccountName), lock.Sender, 0, lock.AssetID, 0, lock.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.SetInheritance:
+		var inheritance SetInheritanceAction
+		err := rlp.DecodeBytes(action.Data(), &inheritance)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.SetInheritance(action.Sender(), number, &inheritance); err != nil {
+			return nil, err
+		}
+	case types.ClaimInheritance:
+		var claim ClaimInheritanceAction
+		err := rlp.DecodeBytes(action.Data(), &claim)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ClaimInheritance(action.Sender(), number, &claim); err != nil {
+			return nil, err
+		}
+	case types.SetAuthorDelay:
+		var delay SetAuthorDelayAction
+		err := rlp.DecodeBytes(action.Data(), &delay)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.SetAuthorDelay(action.Sender(), number, &delay); err != nil {
+			return nil, err
+		}
+	case types.CancelAuthorUpdate:
+		if err := am.CancelAuthorUpdate(action.Sender()); err != nil {
+			return nil, err
+		}
+	case types.ApplyAuthorUpdate:
+		var apply ApplyAuthorUpdateAction
+		err := rlp.DecodeBytes(action.Data(), &apply)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ApplyAuthorUpdate(number, &apply); err != nil {
+			return nil, err
+		}
+	case types.SetGuardians:
+		var guardians SetGuardiansAction
+		err := rlp.DecodeBytes(action.Data(), &guardians)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.SetGuardians(action.Sender(), &guardians); err != nil {
+			return nil, err
+		}
+	case types.ProposeRecovery:
+		var proposal ProposeRecoveryAction
+		err := rlp.DecodeBytes(action.Data(), &proposal)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ProposeRecovery(action.Sender(), number, &proposal); err != nil {
+			return nil, err
+		}
+	case types.ApproveRecovery:
+		var approve RecoveryAction
+		err := rlp.DecodeBytes(action.Data(), &approve)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ApproveRecovery(action.Sender(), number, &approve); err != nil {
+			return nil, err
+		}
+	case types.CancelRecovery:
+		if err := am.CancelRecovery(action.Sender()); err != nil {
+			return nil, err
+		}
+	case types.ExecuteRecovery:
+		var execute RecoveryAction
+		err := rlp.DecodeBytes(action.Data(), &execute)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ExecuteRecovery(number, &execute); err != nil {
+			return nil, err
+		}
+	case types.UpdateReservedNames:
+		var reserved ReservedNames
+		err := rlp.DecodeBytes(action.Data(), &reserved)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.UpdateReservedNames(action.Sender(), accountManagerContext.ChainConfig, &reserved); err != nil {
+			return nil, err
+		}
+	case types.ApproveAsset:
+		var approve ApproveAssetAction
+		err := rlp.DecodeBytes(action.Data(), &approve)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ApproveAsset(action.Sender(), &approve); err != nil {
+			return nil, err
+		}
+	case types.TransferFromAsset:
+		var transfer TransferFromAssetAction
+		err := rlp.DecodeBytes(action.Data(), &transfer)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.spendAllowance(transfer.Owner, action.Sender(), transfer.AssetID, transfer.Amount); err != nil {
+			return nil, err
+		}
+		fromAccountExtra = append(fromAccountExtra, action.Sender())
+		fee, feeAction, err := am.transferAssetWithFee(transfer.Owner, transfer.To, transfer.AssetID, transfer.Amount, fromAccountExtra...)
+		if err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, transfer.Owner, transfer.To, 0, transfer.AssetID, 0, new(big.Int).Sub(transfer.Amount, fee), nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+		if feeAction != nil {
+			internalActions = append(internalActions, feeAction)
+		}
+	case types.DistributeAsset:
+		var dist DistributeAssetAction
+		err := rlp.DecodeBytes(action.Data(), &dist)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := am.DistributeAsset(action.Sender(), action.AssetID(), action.Value(), number, &dist); err != nil {
+			return nil, err
+		}
+	case types.CreateInvitations:
+		var invitations CreateInvitationsAction
+		err := rlp.DecodeBytes(action.Data(), &invitations)
+		if err != nil {
+			return nil, err
+		}
+		fee := big.NewInt(0)
+		if priceCfg := accountManagerContext.ChainConfig.NamePriceCfg; priceCfg != nil && priceCfg.CreateFee != nil && priceCfg.CreateFee.Sign() > 0 {
+			fee.Set(priceCfg.CreateFee)
+		}
+		if err := am.CreateInvitations(action.Sender(), action.AssetID(), action.Value(), number, &invitations, accountManagerContext.ChainConfig.SysTokenID, fee); err != nil {
+			return nil, err
+		}
+		if fee.Sign() > 0 {
+			to := common.Name(accountManagerContext.ChainConfig.AccountName)
+			total := new(big.Int).Mul(fee, big.NewInt(int64(len(invitations.CodeHashes))))
+			if err := am.TransferAsset(action.Sender(), to, accountManagerContext.ChainConfig.SysTokenID, total, fromAccountExtra...); err != nil {
+				return nil, err
+			}
+			actionX := types.NewAction(types.Transfer, action.Sender(), to, 0, accountManagerContext.ChainConfig.SysTokenID, 0, total, nil, nil)
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
+		}
+	case types.RedeemInvitation:
+		var redeem RedeemInvitationAction
+		err := rlp.DecodeBytes(action.Data(), &redeem)
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.redeemInvitation(accountManagerContext, &redeem, fromAccountExtra...)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.RevokeInvitation:
+		var revoke RevokeInvitationAction
+		err := rlp.DecodeBytes(action.Data(), &revoke)
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.revokeInvitation(accountManagerContext, action.Sender(), &revoke, fromAccountExtra...)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.ReleaseHolderBalance:
+		var release ReleaseHolderBalanceAction
+		err := rlp.DecodeBytes(action.Data(), &release)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ReleaseHolderBalance(number, &release); err != nil {
+			return nil, err
+		}
+	case types.BidAccountName:
+		var bid BidAccountNameAction
+		err := rlp.DecodeBytes(action.Data(), &bid)
+		if err != nil {
+			return nil, err
+		}
+		if action.AssetID() != accountManagerContext.ChainConfig.SysTokenID {
+			return nil, ErrAssetIDInvalid
+		}
+		if err := am.checkReservedName(action.Sender(), bid.AccountName, accountManagerContext.ChainConfig); err != nil {
+			return nil, err
+		}
+		outbid, err := am.BidAccountName(action.Sender(), action.Value(), number, accountManagerContext.ChainConfig.NamePriceCfg, &bid)
+		if err != nil {
+			return nil, err
+		}
+		if outbid != nil {
+			internalAction, err := am.refundNameBid(common.Name(accountManagerContext.ChainConfig.AccountName), action.AssetID(), outbid, fromAccountExtra...)
+			if err != nil {
+				return nil, err
+			}
+			internalActions = append(internalActions, internalAction)
+		}
+	case types.ClaimAccountName:
+		var claim ClaimAccountNameAction
+		err := rlp.DecodeBytes(action.Data(), &claim)
+		if err != nil {
+			return nil, err
+		}
+		lapsed, err := am.ClaimAccountName(action.Sender(), number, curForkID, &claim)
+		if err != nil {
+			return nil, err
+		}
+		if lapsed != nil {
+			internalAction, err := am.refundNameBid(common.Name(accountManagerContext.ChainConfig.AccountName), accountManagerContext.ChainConfig.SysTokenID, lapsed, fromAccountExtra...)
+			if err != nil {
+				return nil, err
+			}
+			internalActions = append(internalActions, internalAction)
+		}
+	case types.IssueAsset:
+		var issueAsset IssueAsset
+		err := rlp.DecodeBytes(action.Data(), &issueAsset)
+		if err != nil {
+			return nil, err
+		}
+		fromAccountExtra = append(fromAccountExtra, action.Sender())
+
+		if len(issueAsset.Contract) != 0 {
+			if !am.CheckAssetContract(issueAsset.Contract, issueAsset.Owner, fromAccountExtra...) && issueAsset.Amount.Sign() != 0 {
+				return nil, ErrAmountMustBeZero
+			}
+		}
+
+		assetID, err := am.IssueAsset(action.Sender(), issueAsset, number, curForkID)
+		if err != nil {
+			return nil, err
+		}
+		accountManagerContext.Output = &types.ActionOutput{AssetID: assetID}
+
+		if err := am.AddAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), assetID, issueAsset.Amount); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, common.Name(""), common.Name(accountManagerContext.ChainConfig.AssetName), 0, assetID, 0, issueAsset.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), issueAsset.Owner, assetID, issueAsset.Amount, fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		actionX = types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), issueAsset.Owner, 0, assetID, 0, issueAsset.Amount, nil, nil)
+		internalAction = &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.IncreaseAsset:
+		var inc IncAsset
+		err := rlp.DecodeBytes(action.Data(), &inc)
+		if err != nil {
+			return nil, err
+		}
+
+		if inc.Amount.Cmp(big.NewInt(0)) < 0 {
+			return nil, ErrNegativeAmount
+		}
+
+		if err := am.verifyAsset(accountManagerContext, inc.AssetId, inc.To, inc.Amount); err != nil {
+			return nil, err
+		}
+
+		if err := am.IncAsset2Acct(action.Sender(), inc.To, inc.AssetId, inc.Amount); err != nil {
+			return nil, err
+		}
+
+		if err := am.AddAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), inc.AssetId, inc.Amount); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, common.Name(""), common.Name(accountManagerContext.ChainConfig.AssetName), 0, inc.AssetId, 0, inc.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+
+		fromAccountExtra = append(fromAccountExtra, action.Sender())
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), inc.To, inc.AssetId, inc.Amount, fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		actionX = types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), inc.To, 0, inc.AssetId, 0, inc.Amount, nil, nil)
+		internalAction = &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.DestroyAsset:
+		if wrapped, err := am.ast.IsWrappedAsset(action.AssetID()); err != nil {
+			return nil, err
+		} else if wrapped {
+			return nil, ErrWrappedAssetSupply
+		}
+		// once burners are granted only the owner and the burners destroy the asset
+		roles, err := am.ast.GetAssetRoles(action.AssetID())
+		if err != nil {
+			return nil, err
+		}
+		if len(roles.Burners) != 0 {
+			if err := am.ast.CheckAssetRole(action.Sender(), action.AssetID(), asset.RoleBurner); err != nil {
+				return nil, err
+			}
+		}
+		if err := am.SubAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), action.AssetID(), action.Value()); err != nil {
+			return nil, err
+		}
+
+		if err := am.ast.DestroyAsset(common.Name(accountManagerContext.ChainConfig.AssetName), action.AssetID(), action.Value()); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), common.Name(""), 0, action.AssetID(), 0, action.Value(), nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.UpdateAsset:
+		var asset UpdateAsset
+		err := rlp.DecodeBytes(action.Data(), &asset)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(asset.Founder.String()) > 0 {
+			acct, err := am.GetAccountByName(asset.Founder)
+			if err != nil {
+				return nil, err
+			}
+			if acct == nil {
+				return nil, ErrAccountNotExist
+			}
+		}
+
+		if err := am.ast.CheckOwner(action.Sender(), asset.AssetID); err != nil {
+			return nil, err
+		}
+
+		if err := am.ast.UpdateAsset(action.Sender(), asset.AssetID, asset.Founder); err != nil {
+			return nil, err
+		}
+	case types.SetAssetOwner:
+		var asset UpdateAssetOwner
+		err := rlp.DecodeBytes(action.Data(), &asset)
+		if err != nil {
+			return nil, err
+		}
+		acct, err := am.GetAccountByName(asset.Owner)
+		if err != nil {
+			return nil, err
+		}
+		if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+
+		// check owner
+		if err := am.ast.CheckOwner(action.Sender(), asset.AssetID); err != nil {
+			return nil, err
+		}
+
+		if err := am.verifyAsset(accountManagerContext, asset.AssetID, asset.Owner, big.NewInt(0)); err != nil {
+			return nil, err
+		}
+
+		// the owner changes at once before ForkID4, from the fork the new owner has to accept it
+		forked, err := am.isForked(params.ForkID4)
+		if err != nil {
+			return nil, err
+		}
+		if !forked {
+			if err := am.ast.SetAssetNewOwner(action.Sender(), asset.AssetID, asset.Owner); err != nil {
+				return nil, err
+			}
+		} else if err := am.ast.ProposeAssetOwner(asset.AssetID, asset.Owner, number); err != nil {
+			return nil, err
+		}
+	case types.AcceptAssetOwner:
+		var accept AcceptAssetOwner
+		err := rlp.DecodeBytes(action.Data(), &accept)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.verifyAsset(accountManagerContext, accept.AssetID, action.Sender(), big.NewInt(0)); err != nil {
+			return nil, err
+		}
+		if err := am.ast.AcceptAssetOwner(action.Sender(), accept.AssetID, number); err != nil {
+			return nil, err
+		}
+	case types.RedenominateAsset:
+		var redenominate RedenominateAssetAction
+		err := rlp.DecodeBytes(action.Data(), &redenominate)
+		if err != nil {
+			return nil, err
+		}
+		if redenominate.AssetID == accountManagerContext.ChainConfig.SysTokenID {
+			return nil, ErrRedenominateSysAsset
+		}
+		if err := am.ast.CheckOwner(action.Sender(), redenominate.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.RedenominateAsset(number, &redenominate, common.Name(accountManagerContext.ChainConfig.AccountName)); err != nil {
+			return nil, err
+		}
+	case types.LockHolderBalance:
+		var lock LockHolderBalanceAction
+		err := rlp.DecodeBytes(action.Data(), &lock)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), lock.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.LockHolderBalance(number, &lock); err != nil {
+			return nil, err
+		}
+	case types.GrantAssetRole:
+		var grant AssetRoleAction
+		err := rlp.DecodeBytes(action.Data(), &grant)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), grant.AssetID); err != nil {
+			return nil, err
+		}
+		if acct, err := am.GetAccountByName(grant.Account); err != nil {
+			return nil, err
+		} else if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		if err := am.ast.GrantAssetRole(grant.AssetID, grant.Role, grant.Account); err != nil {
+			return nil, err
+		}
+	case types.RevokeAssetRole:
+		var revoke AssetRoleAction
+		err := rlp.DecodeBytes(action.Data(), &revoke)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), revoke.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.RevokeAssetRole(revoke.AssetID, revoke.Role, revoke.Account); err != nil {
+			return nil, err
+		}
+	case types.PauseAsset:
+		var pause PauseAsset
+		err := rlp.DecodeBytes(action.Data(), &pause)
+		if err != nil {
+			return nil, err
+		}
+		if pause.AssetID == accountManagerContext.ChainConfig.SysTokenID {
+			return nil, ErrPauseSysAsset
+		}
+		if err := am.ast.CheckAssetRole(action.Sender(), pause.AssetID, asset.RolePauser); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetPaused(pause.AssetID, pause.Paused); err != nil {
+			return nil, err
+		}
+	case types.SetAssetBanned:
+		var ban SetAssetBanned
+		err := rlp.DecodeBytes(action.Data(), &ban)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckAssetRole(action.Sender(), ban.AssetID, asset.RoleBlacklister); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetBanned(ban.AssetID, ban.Account, ban.Banned); err != nil {
+			return nil, err
+		}
+	case types.RegisterWrappedAsset:
+		var register RegisterWrappedAsset
+		err := rlp.DecodeBytes(action.Data(), &register)
+		if err != nil {
+			return nil, err
+		}
+		if register.AssetID == accountManagerContext.ChainConfig.SysTokenID {
+			return nil, ErrWrapSysAsset
+		}
+		if err := am.ast.CheckOwner(action.Sender(), register.AssetID); err != nil {
+			return nil, err
+		}
+		// the supply of the wrapped asset must be fully backed by the bridge
+		if ao, err := am.ast.GetAssetObjectById(register.AssetID); err != nil {
+			return nil, err
+		} else if ao.GetAssetAmount().Sign() != 0 {
+			return nil, ErrWrappedAssetSupply
+		}
+		if acct, err := am.GetAccountByName(register.Bridge); err != nil {
+			return nil, err
+		} else if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		if err := am.ast.SetWrappedAsset(&asset.WrappedAsset{
+			AssetID:        register.AssetID,
+			OriginChain:    register.OriginChain,
+			OriginContract: register.OriginContract,
+			Bridge:         register.Bridge,
+		}); err != nil {
+			return nil, err
+		}
+	case types.BridgeMint:
+		var mint BridgeMint
+		err := rlp.DecodeBytes(action.Data(), &mint)
+		if err != nil {
+			return nil, err
+		}
+		if mint.Amount == nil || mint.Amount.Sign() <= 0 {
+			return nil, ErrAmountValueInvalid
+		}
+		if _, err := am.ast.CheckBridge(action.Sender(), mint.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.UseBridgeProof(mint.AssetID, mint.Proof); err != nil {
+			return nil, err
+		}
+		if err := am.ast.IncreaseAsset(action.Sender(), mint.AssetID, mint.Amount); err != nil {
+			return nil, err
+		}
+		if err := am.AddAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), mint.AssetID, mint.Amount); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, common.Name(""), common.Name(accountManagerContext.ChainConfig.AssetName), 0, mint.AssetID, 0, mint.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+
+		fromAccountExtra = append(fromAccountExtra, action.Sender())
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), mint.To, mint.AssetID, mint.Amount, fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		// the proof of the deposit travels with the credit of the recipient
+		actionX = types.NewAction(types.BridgeMint, common.Name(accountManagerContext.ChainConfig.AssetName), mint.To, 0, mint.AssetID, 0, mint.Amount, mint.Proof, nil)
+		internalAction = &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.BridgeBurn:
+		var burn BridgeBurn
+		err := rlp.DecodeBytes(action.Data(), &burn)
+		if err != nil {
+			return nil, err
+		}
+		if burn.Amount == nil || burn.Amount.Sign() <= 0 {
+			return nil, ErrAmountValueInvalid
+		}
+		if _, err := am.ast.CheckBridge(action.Sender(), burn.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.UseBridgeProof(burn.AssetID, burn.Proof); err != nil {
+			return nil, err
+		}
+		if err := am.BurnAsset(action.Sender(), burn.AssetID, burn.Amount); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.BridgeBurn, action.Sender(), common.Name(""), 0, burn.AssetID, 0, burn.Amount, burn.Proof, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.UpdateAssetUpperLimit:
+		var limit UpdateAssetUpperLimit
+		err := rlp.DecodeBytes(action.Data(), &limit)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), limit.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.UpdateAssetUpperLimit(limit.AssetID, limit.UpperLimit, number); err != nil {
+			return nil, err
+		}
+	case types.ApproveAssetUpperLimit:
+		var approve ApproveAssetUpperLimit
+		err := rlp.DecodeBytes(action.Data(), &approve)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.ApproveAssetUpperLimit(action.Sender(), approve.AssetID, approve.UpperLimit, number); err != nil {
+			return nil, err
+		}
+	case types.SetAssetVerifier:
+		var verifier SetAssetVerifier
+		err := rlp.DecodeBytes(action.Data(), &verifier)
+		if err != nil {
+			return nil, err
+		}
+		if len(verifier.Verifier) != 0 {
+			acct, err := am.GetAccountByName(verifier.Verifier)
+			if err != nil {
+				return nil, err
+			}
+			if acct == nil {
+				return nil, ErrAccountNotExist
+			}
+			if acct.GetCodeSize() == 0 {
+				return nil, ErrAssetVerifierInvalid
+			}
+		}
+		if err := am.ast.SetAssetVerifier(action.Sender(), verifier.AssetID, verifier.Verifier); err != nil {
+			return nil, err
+		}
+	case types.UpdateAssetContract:
+		var assetContract UpdateAssetContract
+		err := rlp.DecodeBytes(action.Data(), &assetContract)
 		if err != nil {
 			return nil, err
 		}
@@ -1557,7 +2898,243 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.ast.SetAssetNewContract(assetContract.AssetID, assetContract.Contract); err != nil {
 			return nil, err
 		}
+	case types.UpdateTokenCurator:
+		var curator UpdateTokenCurator
+		err := rlp.DecodeBytes(action.Data(), &curator)
+		if err != nil {
+			return nil, err
+		}
 
+		if action.Sender().String() != accountManagerContext.ChainConfig.SysName {
+			return nil, asset.ErrNotTokenCurator
+		}
+
+		if !curator.Remove {
+			acct, err := am.GetAccountByName(curator.Curator)
+			if err != nil {
+				return nil, err
+			}
+			if acct == nil {
+				return nil, ErrAccountNotExist
+			}
+		}
+		am.ast.SetTokenCurator(curator.Curator, curator.Remove)
+	case types.UpdateTokenList:
+		var token UpdateTokenList
+		err := rlp.DecodeBytes(action.Data(), &token)
+		if err != nil {
+			return nil, err
+		}
+
+		isCurator, err := am.ast.IsTokenCurator(action.Sender())
+		if err != nil {
+			return nil, err
+		}
+		if !isCurator && action.Sender().String() != accountManagerContext.ChainConfig.SysName {
+			return nil, asset.ErrNotTokenCurator
+		}
+
+		if token.Remove {
+			if err := am.ast.RemoveTokenInfo(token.AssetID); err != nil {
+				return nil, err
+			}
+			break
+		}
+		info := &asset.TokenInfo{
+			AssetID:     token.AssetID,
+			DisplayName: token.DisplayName,
+			Symbol:      token.Symbol,
+			LogoURL:     token.LogoURL,
+			Website:     token.Website,
+			Curator:     action.Sender(),
+			Number:      number,
+		}
+		if err := am.ast.SetTokenInfo(info); err != nil {
+			return nil, err
+		}
+
+	case types.SetAssetConversion:
+		var conv SetAssetConversion
+		err := rlp.DecodeBytes(action.Data(), &conv)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), conv.SubID); err != nil {
+			return nil, err
+		}
+		if conv.Remove {
+			if err := am.ast.RemoveAssetConversion(conv.SubID); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if err := am.ast.CheckOwner(action.Sender(), conv.ParentID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetConversion(&asset.AssetConversion{ParentID: conv.ParentID, SubID: conv.SubID, Ratio: conv.Ratio}); err != nil {
+			return nil, err
+		}
+	case types.WrapAsset:
+		var wrap WrapAsset
+		err := rlp.DecodeBytes(action.Data(), &wrap)
+		if err != nil {
+			return nil, err
+		}
+		conv, err := am.ast.GetAssetConversion(wrap.SubID)
+		if err != nil {
+			return nil, err
+		}
+		if conv.ParentID != action.AssetID() {
+			return nil, ErrAssetIDInvalid
+		}
+		actions, err := am.convertAsset(accountManagerContext, action.Sender(), conv.ParentID, action.Value(), conv.SubID, conv.WrapAmount(action.Value()))
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.UnwrapAsset:
+		conv, err := am.ast.GetAssetConversion(action.AssetID())
+		if err != nil {
+			return nil, err
+		}
+		amount, err := conv.UnwrapAmount(action.Value())
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.convertAsset(accountManagerContext, action.Sender(), conv.SubID, action.Value(), conv.ParentID, amount)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.UpdateAssetAccessList:
+		var list UpdateAssetAccessList
+		err := rlp.DecodeBytes(action.Data(), &list)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), list.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetAccessList(&asset.AssetAccessList{AssetID: list.AssetID, Allowlist: list.Allowlist, Allowed: list.Allowed, Banned: list.Banned}); err != nil {
+			return nil, err
+		}
+	case types.BurnAsset:
+		var burn BurnAsset
+		err := rlp.DecodeBytes(action.Data(), &burn)
+		if err != nil {
+			return nil, err
+		}
+		if burn.Amount == nil || burn.Amount.Sign() <= 0 {
+			return nil, ErrAmountValueInvalid
+		}
+		if isCollection, err := am.ast.IsNFTCollection(burn.AssetID); err != nil {
+			return nil, err
+		} else if isCollection {
+			return nil, ErrNFTBurn
+		}
+		if wrapped, err := am.ast.IsWrappedAsset(burn.AssetID); err != nil {
+			return nil, err
+		} else if wrapped {
+			return nil, ErrWrappedAssetSupply
+		}
+		if burnable, err := am.ast.IsAssetBurnable(burn.AssetID); err != nil {
+			return nil, err
+		} else if !burnable {
+			return nil, ErrAssetNotBurnable
+		}
+		if err := am.BurnAsset(action.Sender(), burn.AssetID, burn.Amount); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, action.Sender(), common.Name(""), 0, burn.AssetID, 0, burn.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.SetAssetBurnable:
+		var flag SetAssetBurnable
+		err := rlp.DecodeBytes(action.Data(), &flag)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), flag.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetBurnable(flag.AssetID, flag.Burnable); err != nil {
+			return nil, err
+		}
+	case types.UpdateAssetMetadata:
+		var update UpdateAssetMetadata
+		err := rlp.DecodeBytes(action.Data(), &update)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), update.AssetID); err != nil {
+			return nil, err
+		}
+		metadata := &asset.AssetMetadata{IconURL: update.IconURL, Website: update.Website, WhitepaperHash: update.WhitepaperHash, Extended: update.Extended}
+		if err := am.ast.SetAssetMetadata(update.AssetID, metadata); err != nil {
+			return nil, err
+		}
+	case types.SetSubAssetDelegate:
+		var delegate SetSubAssetDelegate
+		err := rlp.DecodeBytes(action.Data(), &delegate)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), delegate.AssetID); err != nil {
+			return nil, err
+		}
+		if acct, err := am.GetAccountByName(delegate.Delegate); err != nil {
+			return nil, err
+		} else if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		if err := am.ast.SetSubAssetDelegate(delegate.AssetID, delegate.Delegate, delegate.Quota); err != nil {
+			return nil, err
+		}
+	case types.SetAssetTransferFee:
+		var fee SetAssetTransferFee
+		err := rlp.DecodeBytes(action.Data(), &fee)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), fee.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetTransferFee(fee.AssetID, fee.Rate); err != nil {
+			return nil, err
+		}
+	case types.IssueNFT:
+		var issue IssueNFTAction
+		err := rlp.DecodeBytes(action.Data(), &issue)
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.issueNFT(accountManagerContext, action.Sender(), &issue, fromAccountExtra...)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.TransferNFT:
+		var transfer TransferNFTAction
+		err := rlp.DecodeBytes(action.Data(), &transfer)
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.transferNFT(action.Sender(), &transfer, fromAccountExtra...)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.BurnNFT:
+		var burn BurnNFTAction
+		err := rlp.DecodeBytes(action.Data(), &burn)
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.burnNFT(action.Sender(), &burn)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
 	case types.Transfer:
 	default:
 		return nil, ErrUnkownTxType
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/fractalplatform/fractal/asset"
@@ -47,9 +48,19 @@ func getStateDB() *state.StateDB {
 		fmt.Printf("test getStateDB() failure %v", err)
 		return nil
 	}
-
+	setForkID(statedb, params.ForkID4)
 	return statedb
 }
+
+//setForkID store the fork information of the fork controller at the fork id
+func setForkID(statedb *state.StateDB, id uint64) {
+	SetChainName(common.Name("systestchain"))
+	b, err := rlp.EncodeToBytes(&forkInfo{CurForkID: id})
+	if err != nil {
+		panic(err)
+	}
+	statedb.Put(chainName, forkInfoKey, b)
+}
 func getAsset() *asset.Asset {
 	return asset.NewAsset(sdb)
 }
@@ -123,12 +134,36 @@ func TestNewAccountManager(t *testing.T) {
 			t.Errorf("%q. NewAccountManager() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 			continue
 		}
-		if !reflect.DeepEqual(got, tt.want) {
+		if (got == nil) != (tt.want == nil) || got != nil && (got.sdb != tt.want.sdb || !reflect.DeepEqual(got.ast, tt.want.ast)) {
 			t.Errorf("%q. NewAccountManager() = %v, want %v", tt.name, got, tt.want)
 		}
 	}
 }
 
+func TestAccountManager_AccountCache(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	acct, err := am.GetAccountByName(common.Name("escrowsender"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	acct.SetNonce(10)
+	if nonce, _ := am.GetNonce(common.Name("escrowsender")); nonce != 0 {
+		t.Errorf("cached account modified without SetAccount, nonce %v", nonce)
+	}
+
+	snap := am.sdb.Snapshot()
+	if err := am.SetNonce(common.Name("escrowsender"), 5); err != nil {
+		t.Fatal(err)
+	}
+	if nonce, _ := am.GetNonce(common.Name("escrowsender")); nonce != 5 {
+		t.Errorf("account cache not write through, nonce %v", nonce)
+	}
+	am.sdb.RevertToSnapshot(snap)
+	if nonce, _ := am.GetNonce(common.Name("escrowsender")); nonce != 0 {
+		t.Errorf("account cache stale after revert, nonce %v", nonce)
+	}
+}
+
 func TestAccountManager_InitAccountCounter(t *testing.T) {
 	//TODO
 }
@@ -436,7 +471,7 @@ func TestAccountManager_DeleteAccountByName(t *testing.T) {
 			sdb: tt.fields.sdb,
 			ast: tt.fields.ast,
 		}
-		if err := am.DeleteAccountByName(tt.args.accountName); (err != nil) != tt.wantErr {
+		if err := am.DeleteAccountByName(tt.args.accountName, 0); (err != nil) != tt.wantErr {
 			t.Errorf("%q. AccountManager.DeleteAccountByName() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 		}
 	}
@@ -630,6 +665,21 @@ func TestAccountManager_GetAccountBalanceByID(t *testing.T) {
 	}
 }
 
+func TestAccountManager_GetBalancesBatch(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	balances, err := am.GetBalancesBatch([]common.Name{"escrowsender", "escrowrecipient"}, []uint64{assetID, assetID + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]*big.Int{{big.NewInt(100), big.NewInt(0)}, {big.NewInt(0), big.NewInt(0)}}
+	if !reflect.DeepEqual(balances, want) {
+		t.Errorf("AccountManager.GetBalancesBatch() = %v, want %v", balances, want)
+	}
+	if _, err := am.GetBalancesBatch([]common.Name{"notexistacct"}, []uint64{assetID}); err == nil {
+		t.Error("AccountManager.GetBalancesBatch() want account not exist err")
+	}
+}
+
 func TestAccountManager_GetAssetInfoByName(t *testing.T) {
 	type fields struct {
 		sdb *state.StateDB
@@ -1582,7 +1632,7 @@ func TestAccountManager_Process(t *testing.T) {
 	ca2 := common.NewAuthor(common.Name("a123456789aeee"), 2)
 	autha2 := &AuthorAction{ActionType: 1, Author: ca2}
 
-	aaa1 := &AccountAuthorAction{Threshold: 10, AuthorActions: []*AuthorAction{autha1, autha2}}
+	aaa1 := &AccountAuthorAction{Threshold: 3, AuthorActions: []*AuthorAction{autha1, autha2}}
 	payload5, err := rlp.EncodeToBytes(aaa1)
 	if err != nil {
 		panic("rlp payload err")
@@ -1618,20 +1668,28 @@ func TestAccountManager_Process(t *testing.T) {
 		//{"transfer", fields{sdb, ast}, args{action7}, false},
 	}
 
+	var issued *types.ActionOutput
 	for _, tt := range tests {
 		am := &AccountManager{
 			sdb: tt.fields.sdb,
 			ast: tt.fields.ast,
 		}
-		if _, err := am.Process(&types.AccountManagerContext{Action: tt.args.action, ChainConfig: params.DefaultChainconfig, Number: blockNumber}); (err != nil) != tt.wantErr {
+		ctx := &types.AccountManagerContext{Action: tt.args.action, ChainConfig: params.DefaultChainconfig, Number: blockNumber}
+		if _, err := am.Process(ctx); (err != nil) != tt.wantErr {
 			t.Errorf("%q. AccountManager.Process() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 		}
+		if tt.args.action.Type() == types.IssueAsset {
+			issued = ctx.Output
+		}
 	}
 
 	asset2, err := acctm.GetAssetInfoByName("abced99")
 	if asset2 == nil {
 		t.Error("Process issue asset failure")
 	}
+	if issued == nil || issued.AssetID != asset2.GetAssetId() {
+		t.Errorf("Process issue asset output = %v, want id %v", issued, asset2.GetAssetId())
+	}
 	//t.Logf("issue ok id=%v", asset2.AssetId)
 	if asset2.Amount.Cmp(big.NewInt(100000000)) != 0 {
 		t.Errorf("Process increase asset failure amount=%v", asset2.Amount)
@@ -1669,7 +1727,7 @@ func TestAccountManager_Process(t *testing.T) {
 	}
 	ca3 := common.NewAuthor(common.Name("a123456789aeee"), 1)
 	autha3 := &AuthorAction{ActionType: 2, Author: ca3}
-	aaa2 := &AccountAuthorAction{AuthorActions: []*AuthorAction{autha3}}
+	aaa2 := &AccountAuthorAction{Threshold: 1, AuthorActions: []*AuthorAction{autha3}}
 
 	payload6, err := rlp.EncodeToBytes(aaa2)
 	if err != nil {
@@ -1707,6 +1765,57 @@ func TestAccountManager_Process(t *testing.T) {
 
 }
 
+func TestAccountManager_CheckAccountAuthor(t *testing.T) {
+	pubkey, _ := GeneragePubKey()
+	if err := acctm.CreateAccount(common.Name("fractal.founder"), common.Name("a123456authchk"), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatalf("create account err %v", err)
+	}
+	ca := common.NewAuthor(common.Name("a123456789aeee"), 2)
+	tests := []struct {
+		name     string
+		acctAuth *AccountAuthorAction
+		wantErr  bool
+	}{
+		{"reachable", &AccountAuthorAction{Threshold: 3, AuthorActions: []*AuthorAction{{ActionType: AddAuthor, Author: ca}}}, false},
+		{"threshold", &AccountAuthorAction{Threshold: 4}, true},
+		{"updatethreshold", &AccountAuthorAction{UpdateAuthorThreshold: 4}, true},
+		{"deleteauthor", &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: DeleteAuthor, Author: ca}}}, true},
+		{"empty", &AccountAuthorAction{Threshold: 1, AuthorActions: []*AuthorAction{{ActionType: DeleteAuthor, Author: ca}, {ActionType: DeleteAuthor, Author: common.NewAuthor(pubkey, 1)}}}, true},
+	}
+	for _, tt := range tests {
+		if err := acctm.CheckAccountAuthor(common.Name("a123456authchk"), tt.acctAuth); (err != nil) != tt.wantErr {
+			t.Errorf("%q. AccountManager.CheckAccountAuthor() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err := acctm.UpdateAccountAuthor(common.Name("a123456authchk"), tt.acctAuth); err == nil && tt.wantErr {
+			t.Errorf("%q. AccountManager.UpdateAccountAuthor() want err", tt.name)
+		}
+		acct, _ := acctm.GetAccountByName(common.Name("a123456authchk"))
+		if err := acct.CheckAuthors(); err != nil {
+			t.Errorf("%q. account authors unsatisfiable %v", tt.name, err)
+		}
+	}
+}
+
+func TestAccountManager_UpdateAccountAuthorBeforeFork(t *testing.T) {
+	statedb := getStateDB()
+	setForkID(statedb, params.ForkID3)
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal.founder"), common.Name("a123456authold"), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatalf("create account err %v", err)
+	}
+	// the historical blocks may leave the authors unsatisfiable
+	if err := am.UpdateAccountAuthor(common.Name("a123456authold"), &AccountAuthorAction{Threshold: 4}); err != nil {
+		t.Fatalf("update author before the fork err %v", err)
+	}
+	if err := am.CheckAccountAuthor(common.Name("a123456authold"), &AccountAuthorAction{Threshold: 4}); err == nil {
+		t.Fatal("check author want err")
+	}
+}
+
 func TestAccountManager_SubAccount(t *testing.T) {
 
 	type fields struct {
@@ -2159,3 +2268,47 @@ func Test_IssueAssetForkID1(t *testing.T) {
 		}
 	}
 }
+
+func TestSetAccountNameConfig_AuthorLimit(t *testing.T) {
+	defer func(re *regexp.Regexp, length uint64) {
+		acctRegExp, accountNameLength = re, length
+		chainAuthorLimits = defaultAuthorLimits
+	}(acctRegExp, accountNameLength)
+	SetAccountNameConfig(&Config{
+		AccountNameLevel:         1,
+		AccountNameMaxLength:     31,
+		MainAccountNameMinLength: 7,
+		MainAccountNameMaxLength: 16,
+		MaxAuthorNum:             2,
+	})
+	want := authorLimits{params.MaxSignDepth, params.MaxSignLength, 2}
+	if chainAuthorLimits != want {
+		t.Fatalf("author limit %v, want %v", chainAuthorLimits, want)
+	}
+
+	statedb := getStateDB()
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit, err := am.authorLimit(); err != nil || limit != want {
+		t.Fatalf("forked author limit %v, %v, want %v", limit, err, want)
+	}
+	// the blocks before the fork keep the default limits
+	setForkID(statedb, params.ForkID3)
+	if limit, err := am.authorLimit(); err != nil || limit != defaultAuthorLimits {
+		t.Fatalf("author limit before the fork %v, %v, want %v", limit, err, defaultAuthorLimits)
+	}
+
+	pubkey, _ := GeneragePubKey()
+	acct, _ := NewAccount(common.Name("authorlimit"), common.Name(""), pubkey, "")
+	pubkey2, _ := GeneragePubKey()
+	pubkey3, _ := GeneragePubKey()
+	acctAuth := &AccountAuthorAction{AuthorActions: []*AuthorAction{
+		{ActionType: AddAuthor, Author: common.NewAuthor(pubkey2, 1)},
+		{ActionType: AddAuthor, Author: common.NewAuthor(pubkey3, 1)},
+	}}
+	if err := applyAccountAuthor(acct, acctAuth, true, want.authorNum); err == nil {
+		t.Error("applyAccountAuthor want author limit err")
+	}
+}
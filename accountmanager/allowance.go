@@ -0,0 +1,135 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// An allowance is stored with the decimals of the asset at the approval, it is
+// converted to the current decimals when read so a redenomination of the asset
+// rescales the allowances without visiting them.
+var acctAllowancePrefix = "acctAllowance"
+
+// allowance the amount the spender can transfer in the decimals of the approval
+type allowance struct {
+	Amount   *big.Int
+	Decimals uint64
+}
+
+// ApproveAssetAction set the amount of the asset the spender can transfer from the sender.
+// A zero amount revokes the allowance.
+type ApproveAssetAction struct {
+	Spender common.Name `json:"spender"`
+	AssetID uint64      `json:"assetID"`
+	Amount  *big.Int    `json:"amount"`
+}
+
+// TransferFromAssetAction transfer the asset from the owner to the recipient within the
+// allowance of the sender.
+type TransferFromAssetAction struct {
+	Owner   common.Name `json:"owner"`
+	To      common.Name `json:"to"`
+	AssetID uint64      `json:"assetID"`
+	Amount  *big.Int    `json:"amount"`
+}
+
+func allowanceKey(owner common.Name, spender common.Name, assetID uint64) string {
+	return acctAllowancePrefix + owner.String() + ":" + spender.String() + ":" + strconv.FormatUint(assetID, 10)
+}
+
+//GetAllowance get the amount of the asset the spender can transfer from the owner
+func (am *AccountManager) GetAllowance(owner common.Name, spender common.Name, assetID uint64) (*big.Int, error) {
+	b, err := am.sdb.Get(acctManagerName, allowanceKey(owner, spender, assetID))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return new(big.Int), nil
+	}
+	var stored allowance
+	if err := rlp.DecodeBytes(b, &stored); err != nil {
+		return nil, err
+	}
+	assetObj, err := am.ast.GetAssetObjectById(assetID)
+	if err != nil {
+		return nil, err
+	}
+	amount, _ := ConvertAssetAmount(stored.Amount, stored.Decimals, assetObj.GetDecimals())
+	return amount, nil
+}
+
+func (am *AccountManager) setAllowance(owner common.Name, spender common.Name, assetID uint64, amount *big.Int) error {
+	if am.readOnly {
+		return ErrAccountManagerReadOnly
+	}
+	key := allowanceKey(owner, spender, assetID)
+	if amount.Sign() == 0 {
+		am.sdb.Delete(acctManagerName, key)
+		return nil
+	}
+	assetObj, err := am.ast.GetAssetObjectById(assetID)
+	if err != nil {
+		return err
+	}
+	b, err := rlp.EncodeToBytes(&allowance{Amount: amount, Decimals: assetObj.GetDecimals()})
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, key, b)
+	return nil
+}
+
+//ApproveAsset set the allowance of the spender on the asset of the owner
+func (am *AccountManager) ApproveAsset(owner common.Name, action *ApproveAssetAction) error {
+	if action.Amount == nil || action.Amount.Sign() < 0 {
+		return ErrAmountValueInvalid
+	}
+	if action.Spender == owner {
+		return ErrSpenderInvalid
+	}
+	if action.Amount.Sign() > 0 {
+		if acct, err := am.GetAccountByName(action.Spender); err != nil {
+			return err
+		} else if acct == nil {
+			return ErrAccountNotExist
+		}
+		if _, err := am.ast.GetAssetObjectById(action.AssetID); err != nil {
+			return err
+		}
+	}
+	return am.setAllowance(owner, action.Spender, action.AssetID, action.Amount)
+}
+
+//spendAllowance deduct the amount from the allowance of the spender
+func (am *AccountManager) spendAllowance(owner common.Name, spender common.Name, assetID uint64, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return ErrAmountValueInvalid
+	}
+	allowance, err := am.GetAllowance(owner, spender, assetID)
+	if err != nil {
+		return err
+	}
+	if allowance.Cmp(amount) < 0 {
+		return ErrAllowanceInsufficient
+	}
+	return am.setAllowance(owner, spender, assetID, allowance.Sub(allowance, amount))
+}
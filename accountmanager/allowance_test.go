@@ -0,0 +1,70 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_Allowance(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	owner, spender, to := common.Name("escrowsender"), common.Name("escrowrecipient"), common.Name("fractal")
+
+	approve := &ApproveAssetAction{Spender: spender, AssetID: assetID, Amount: big.NewInt(30)}
+	if err := processEscrowAction(am, types.ApproveAsset, owner, 0, big.NewInt(0), 1, approve); err != nil {
+		t.Fatal(err)
+	}
+	if allowance, err := am.GetAllowance(owner, spender, assetID); err != nil || allowance.Int64() != 30 {
+		t.Fatalf("GetAllowance = %v, %v", allowance, err)
+	}
+
+	transfer := &TransferFromAssetAction{Owner: owner, To: to, AssetID: assetID, Amount: big.NewInt(20)}
+	if err := processEscrowAction(am, types.TransferFromAsset, to, 0, big.NewInt(0), 2, transfer); err != ErrAllowanceInsufficient {
+		t.Fatalf("transfer without allowance err %v", err)
+	}
+	if err := processEscrowAction(am, types.TransferFromAsset, spender, 0, big.NewInt(0), 2, transfer); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(to, assetID, 0); balance.Int64() != 20 {
+		t.Fatalf("recipient balance %v", balance)
+	}
+	if balance, _ := am.GetAccountBalanceByID(owner, assetID, 0); balance.Int64() != 80 {
+		t.Fatalf("owner balance %v", balance)
+	}
+	if err := processEscrowAction(am, types.TransferFromAsset, spender, 0, big.NewInt(0), 3, transfer); err != ErrAllowanceInsufficient {
+		t.Fatalf("transfer over allowance err %v", err)
+	}
+	if allowance, _ := am.GetAllowance(owner, spender, assetID); allowance.Int64() != 10 {
+		t.Fatalf("allowance after transfer %v", allowance)
+	}
+
+	revoke := &ApproveAssetAction{Spender: spender, AssetID: assetID, Amount: big.NewInt(0)}
+	if err := processEscrowAction(am, types.ApproveAsset, owner, 0, big.NewInt(0), 4, revoke); err != nil {
+		t.Fatal(err)
+	}
+	if allowance, _ := am.GetAllowance(owner, spender, assetID); allowance.Sign() != 0 {
+		t.Fatalf("allowance after revoke %v", allowance)
+	}
+	self := &ApproveAssetAction{Spender: owner, AssetID: assetID, Amount: big.NewInt(1)}
+	if err := processEscrowAction(am, types.ApproveAsset, owner, 0, big.NewInt(0), 5, self); err != ErrSpenderInvalid {
+		t.Fatalf("approve self err %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_AcceptAssetOwner(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	owner, newOwner := common.Name("escrowsender"), common.Name("escrowrecipient")
+	process := func(aType types.ActionType, from common.Name, number uint64, data interface{}) error {
+		payload, err := rlp.EncodeToBytes(data)
+		if err != nil {
+			return err
+		}
+		action := types.NewAction(aType, from, common.Name(params.DefaultChainconfig.AssetName), 0, assetID, 0, big.NewInt(0), payload, nil)
+		_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: number})
+		return err
+	}
+	currentOwner := func() common.Name {
+		assetObj, err := am.GetAssetInfoByID(assetID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return assetObj.GetAssetOwner()
+	}
+
+	if err := process(types.SetAssetOwner, owner, 1, &UpdateAssetOwner{AssetID: assetID, Owner: newOwner}); err != nil {
+		t.Fatal(err)
+	}
+	if currentOwner() != owner {
+		t.Fatal("asset owner changed before accepted")
+	}
+	pending, err := am.GetPendingAssetOwner(assetID)
+	if err != nil || pending.Owner != newOwner || pending.Expiry != 1+asset.AssetOwnerAcceptBlocks {
+		t.Fatalf("pending owner %v, %v", pending, err)
+	}
+	if err := process(types.AcceptAssetOwner, common.Name("fractal"), 2, &AcceptAssetOwner{AssetID: assetID}); err != asset.ErrNotPendingOwner {
+		t.Fatalf("accept by other err %v", err)
+	}
+	if err := process(types.AcceptAssetOwner, newOwner, 2+asset.AssetOwnerAcceptBlocks, &AcceptAssetOwner{AssetID: assetID}); err != asset.ErrPendingOwnerExpired {
+		t.Fatalf("accept after expired err %v", err)
+	}
+	if err := process(types.AcceptAssetOwner, newOwner, 1+asset.AssetOwnerAcceptBlocks, &AcceptAssetOwner{AssetID: assetID}); err != nil {
+		t.Fatal(err)
+	}
+	if currentOwner() != newOwner {
+		t.Fatal("asset owner not changed after accepted")
+	}
+	if _, err := am.GetPendingAssetOwner(assetID); err != asset.ErrPendingOwnerNotExist {
+		t.Fatalf("pending owner after accepted err %v", err)
+	}
+
+	// proposing the current owner cancels the proposal
+	if err := process(types.SetAssetOwner, newOwner, 3, &UpdateAssetOwner{AssetID: assetID, Owner: owner}); err != nil {
+		t.Fatal(err)
+	}
+	if err := process(types.SetAssetOwner, newOwner, 4, &UpdateAssetOwner{AssetID: assetID, Owner: newOwner}); err != nil {
+		t.Fatal(err)
+	}
+	if err := process(types.AcceptAssetOwner, owner, 5, &AcceptAssetOwner{AssetID: assetID}); err != asset.ErrPendingOwnerNotExist {
+		t.Fatalf("accept cancelled proposal err %v", err)
+	}
+}
+
+func TestAccountManager_SetAssetOwnerBeforeFork(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	setForkID(am.sdb, params.ForkID3)
+	owner, newOwner := common.Name("escrowsender"), common.Name("escrowrecipient")
+	payload, err := rlp.EncodeToBytes(&UpdateAssetOwner{AssetID: assetID, Owner: newOwner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	action := types.NewAction(types.SetAssetOwner, owner, common.Name(params.DefaultChainconfig.AssetName), 0, assetID, 0, big.NewInt(0), payload, nil)
+	if _, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// the owner changes at once before the fork
+	assetObj, err := am.GetAssetInfoByID(assetID)
+	if err != nil || assetObj.GetAssetOwner() != newOwner {
+		t.Fatalf("asset owner %v, %v", assetObj, err)
+	}
+	if _, err := am.GetPendingAssetOwner(assetID); err != asset.ErrPendingOwnerNotExist {
+		t.Fatalf("pending owner before the fork err %v", err)
+	}
+}
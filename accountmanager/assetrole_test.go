@@ -0,0 +1,123 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_AssetRoles(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	pubkey, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name(params.DefaultChainconfig.AssetName), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	owner, operator, other := common.Name("escrowsender"), common.Name("escrowrecipient"), common.Name("fractal")
+	issue := IssueAsset{AssetName: "escrowcoin.stable", Symbol: "usd", Amount: big.NewInt(0), Owner: owner, UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(owner, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mint := func(from common.Name) error {
+		return processAssetAction(am, types.IncreaseAsset, from, 0, big.NewInt(0), &IncAsset{AssetId: assetID, Amount: big.NewInt(10), To: from})
+	}
+	grant := func(role asset.AssetRole) {
+		if err := processAssetAction(am, types.GrantAssetRole, owner, 0, big.NewInt(0), &AssetRoleAction{AssetID: assetID, Role: role, Account: operator}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// minter
+	if err := processAssetAction(am, types.GrantAssetRole, operator, 0, big.NewInt(0), &AssetRoleAction{AssetID: assetID, Role: asset.RoleMinter, Account: operator}); err != asset.ErrOwnerMismatch {
+		t.Fatalf("grant by non owner err %v", err)
+	}
+	if err := mint(operator); err != asset.ErrAssetRoleMismatch {
+		t.Fatalf("mint without role err %v", err)
+	}
+	grant(asset.RoleMinter)
+	if err := mint(operator); err != nil {
+		t.Fatal(err)
+	}
+	if err := mint(other); err != asset.ErrAssetRoleMismatch {
+		t.Fatalf("mint by other err %v", err)
+	}
+	if roles, err := am.GetAssetRoles(assetID); err != nil || !roles.HasRole(asset.RoleMinter, operator) || roles.HasRole(asset.RolePauser, operator) {
+		t.Fatalf("roles %v, %v", roles, err)
+	}
+
+	// pauser
+	pause := func(paused bool) error {
+		return processAssetAction(am, types.PauseAsset, operator, 0, big.NewInt(0), &PauseAsset{AssetID: assetID, Paused: paused})
+	}
+	if err := pause(true); err != asset.ErrAssetRoleMismatch {
+		t.Fatalf("pause without role err %v", err)
+	}
+	grant(asset.RolePauser)
+	if err := pause(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(operator, owner, assetID, big.NewInt(1)); err != ErrAssetPaused {
+		t.Fatalf("transfer of paused asset err %v", err)
+	}
+	if err := pause(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(operator, owner, assetID, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// blacklister
+	grant(asset.RoleBlacklister)
+	if err := processAssetAction(am, types.SetAssetBanned, operator, 0, big.NewInt(0), &SetAssetBanned{AssetID: assetID, Account: other, Banned: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(owner, other, assetID, big.NewInt(1)); err == nil {
+		t.Fatal("transfer to banned account should fail")
+	}
+
+	// burner, anyone destroys the asset until burners are granted
+	holder := common.Name("fractal.account")
+	if err := am.AddAccountBalanceByID(holder, assetID, big.NewInt(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.DestroyAsset, holder, assetID, big.NewInt(1), nil); err != nil {
+		t.Fatal(err)
+	}
+	grant(asset.RoleBurner)
+	if err := processAssetAction(am, types.DestroyAsset, holder, assetID, big.NewInt(1), nil); err != asset.ErrAssetRoleMismatch {
+		t.Fatalf("destroy without role err %v", err)
+	}
+	if err := processAssetAction(am, types.DestroyAsset, operator, assetID, big.NewInt(1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := processAssetAction(am, types.RevokeAssetRole, owner, 0, big.NewInt(0), &AssetRoleAction{AssetID: assetID, Role: asset.RoleMinter, Account: operator}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mint(operator); err != asset.ErrAssetRoleMismatch {
+		t.Fatalf("mint after revoked err %v", err)
+	}
+	if err := processAssetAction(am, types.RevokeAssetRole, owner, 0, big.NewInt(0), &AssetRoleAction{AssetID: assetID, Role: asset.RoleMinter, Account: operator}); err != asset.ErrAssetRoleNotExist {
+		t.Fatalf("revoke again err %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var acctNameAuctionPrefix = "acctNameAuction"
+
+// BidAccountNameAction bid the value for the premium account name.
+type BidAccountNameAction struct {
+	AccountName common.Name `json:"accountName,omitempty"`
+}
+
+// ClaimAccountNameAction create the account won by auction.
+type ClaimAccountNameAction struct {
+	AccountName common.Name   `json:"accountName,omitempty"`
+	Founder     common.Name   `json:"founder,omitempty"`
+	PublicKey   common.PubKey `json:"publicKey,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
+// NameAuction the highest bid of the premium account name, the bid is held by
+// the account manager until the winner claims the name. The name is released and
+// the bid refunded if the winner does not claim it before the claim number.
+type NameAuction struct {
+	AccountName common.Name `json:"accountName"`
+	Bidder      common.Name `json:"bidder"`
+	Bid         *big.Int    `json:"bid"`
+	StartNumber uint64      `json:"startNumber"`
+	EndNumber   uint64      `json:"endNumber"`
+	ClaimNumber uint64      `json:"claimNumber"`
+}
+
+//lapsed check the winner missed the claim deadline
+func (a *NameAuction) lapsed(number uint64) bool {
+	return a.ClaimNumber != 0 && number >= a.ClaimNumber
+}
+
+//IsPremiumAccountName check the main account name must be won by auction
+func IsPremiumAccountName(cfg *params.PriceConfig, accountName common.Name) bool {
+	if cfg == nil || cfg.PremiumLength == 0 {
+		return false
+	}
+	level, err := GetAccountNameLevel(accountName)
+	if err != nil || level != mainAccount {
+		return false
+	}
+	return uint64(len(accountName.String())) < cfg.PremiumLength
+}
+
+//GetNameAuction get the auction of the account name
+func (am *AccountManager) GetNameAuction(accountName common.Name) (*NameAuction, error) {
+	b, err := am.sdb.Get(acctManagerName, acctNameAuctionPrefix+accountName.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrNameAuctionNotExist
+	}
+	var auction NameAuction
+	if err := rlp.DecodeBytes(b, &auction); err != nil {
+		return nil, err
+	}
+	return &auction, nil
+}
+
+func (am *AccountManager) setNameAuction(auction *NameAuction) error {
+	b, err := rlp.EncodeToBytes(auction)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctNameAuctionPrefix+auction.AccountName.String(), b)
+	return nil
+}
+
+//BidAccountName bid for the premium account name, returns the outbid auction to refund
+func (am *AccountManager) BidAccountName(bidder common.Name, value *big.Int, number uint64, cfg *params.PriceConfig, action *BidAccountNameAction) (*NameAuction, error) {
+	if !IsPremiumAccountName(cfg, action.AccountName) {
+		return nil, ErrAccountInvaid
+	}
+	accountID, err := am.GetAccountIDByName(action.AccountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID > 0 {
+		return nil, ErrAccountIsExist
+	}
+
+	auction, err := am.GetNameAuction(action.AccountName)
+	if err != nil && err != ErrNameAuctionNotExist {
+		return nil, err
+	}
+	// the first bid or the bid on the name released by the winner opens a new auction
+	if err == ErrNameAuctionNotExist || auction.lapsed(number) {
+		if value.Sign() <= 0 || (cfg.MinBid != nil && value.Cmp(cfg.MinBid) < 0) {
+			return nil, ErrNameBidTooLow
+		}
+		newAuction := &NameAuction{
+			AccountName: action.AccountName,
+			Bidder:      bidder,
+			Bid:         value,
+			StartNumber: number,
+			EndNumber:   number + cfg.AuctionBlocks,
+		}
+		if cfg.ClaimBlocks != 0 {
+			newAuction.ClaimNumber = newAuction.EndNumber + cfg.ClaimBlocks
+		}
+		return auction, am.setNameAuction(newAuction)
+	}
+	if number >= auction.EndNumber {
+		return nil, ErrNameAuctionEnded
+	}
+	if value.Cmp(auction.Bid) <= 0 {
+		return nil, ErrNameBidTooLow
+	}
+	outbid := *auction
+	auction.Bidder = bidder
+	auction.Bid = value
+	if err := am.setNameAuction(auction); err != nil {
+		return nil, err
+	}
+	return &outbid, nil
+}
+
+//ClaimAccountName create the account for the winner of the ended auction, the claim after
+//the deadline releases the name and returns the auction to refund instead
+func (am *AccountManager) ClaimAccountName(winner common.Name, number uint64, curForkID uint64, action *ClaimAccountNameAction) (*NameAuction, error) {
+	auction, err := am.GetNameAuction(action.AccountName)
+	if err != nil {
+		return nil, err
+	}
+	if number < auction.EndNumber {
+		return nil, ErrNameAuctionNotEnded
+	}
+	if auction.Bidder != winner {
+		return nil, ErrNameAuctionNotWinner
+	}
+	am.sdb.Delete(acctManagerName, acctNameAuctionPrefix+action.AccountName.String())
+	if auction.lapsed(number) {
+		return auction, nil
+	}
+	if err := am.CreateAccount(winner, action.AccountName, action.Founder, number, curForkID, action.PublicKey, action.Description); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+//refundNameBid refund the bid held by the account manager to the bidder
+func (am *AccountManager) refundNameBid(pool common.Name, assetID uint64, auction *NameAuction, fromAccountExtra ...common.Name) (*types.InternalAction, error) {
+	if err := am.TransferAsset(pool, auction.Bidder, assetID, auction.Bid, fromAccountExtra...); err != nil {
+		return nil, err
+	}
+	actionX := types.NewAction(types.Transfer, pool, auction.Bidder, 0, assetID, 0, auction.Bid, nil, nil)
+	return &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func processPriceAction(am *AccountManager, cfg *params.ChainConfig, aType types.ActionType, from common.Name, value *big.Int, number uint64, data interface{}) error {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	action := types.NewAction(aType, from, common.Name(cfg.AccountName), 0, cfg.SysTokenID, 0, value, payload, nil)
+	_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: cfg, Number: number})
+	return err
+}
+
+func TestAccountManager_NameAuction(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	if err := am.AddAccountBalanceByID(common.Name("escrowrecipient"), assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	cfg := params.DefaultChainconfig.Copy()
+	cfg.SysTokenID = assetID
+	cfg.NamePriceCfg = &params.PriceConfig{CreateFee: big.NewInt(5), PremiumLength: 10, MinBid: big.NewInt(10), AuctionBlocks: 5}
+	pubkey, _ := GeneragePubKey()
+	premium := common.Name("premium")
+
+	create := &CreateAccountAction{AccountName: premium, PublicKey: pubkey}
+	if err := processPriceAction(am, cfg, types.CreateAccount, common.Name("escrowsender"), big.NewInt(0), 1, create); err != ErrAccountNamePremium {
+		t.Fatalf("create premium name err %v", err)
+	}
+	create = &CreateAccountAction{AccountName: common.Name("regularname"), PublicKey: pubkey}
+	if err := processPriceAction(am, cfg, types.CreateAccount, common.Name("escrowsender"), big.NewInt(0), 1, create); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(common.Name("escrowsender"), assetID, 0); balance.Cmp(big.NewInt(95)) != 0 {
+		t.Fatalf("create fee not charged, balance %v", balance)
+	}
+
+	bid := &BidAccountNameAction{AccountName: premium}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowsender"), big.NewInt(5), 2, bid); err != ErrNameBidTooLow {
+		t.Fatalf("bid below min err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowsender"), big.NewInt(20), 2, bid); err != nil {
+		t.Fatal(err)
+	}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowrecipient"), big.NewInt(20), 3, bid); err != ErrNameBidTooLow {
+		t.Fatalf("equal bid err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowrecipient"), big.NewInt(30), 3, bid); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(common.Name("escrowsender"), assetID, 0); balance.Cmp(big.NewInt(95)) != 0 {
+		t.Fatalf("outbid not refunded, balance %v", balance)
+	}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowsender"), big.NewInt(40), 7, bid); err != ErrNameAuctionEnded {
+		t.Fatalf("bid after end err %v", err)
+	}
+
+	claim := &ClaimAccountNameAction{AccountName: premium, PublicKey: pubkey}
+	if err := processPriceAction(am, cfg, types.ClaimAccountName, common.Name("escrowrecipient"), big.NewInt(0), 6, claim); err != ErrNameAuctionNotEnded {
+		t.Fatalf("claim before end err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.ClaimAccountName, common.Name("escrowsender"), big.NewInt(0), 7, claim); err != ErrNameAuctionNotWinner {
+		t.Fatalf("claim by loser err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.ClaimAccountName, common.Name("escrowrecipient"), big.NewInt(0), 7, claim); err != nil {
+		t.Fatal(err)
+	}
+	if exist, _ := am.AccountIsExist(premium); !exist {
+		t.Fatal("premium account not created")
+	}
+	if _, err := am.GetNameAuction(premium); err != ErrNameAuctionNotExist {
+		t.Fatalf("auction not removed err %v", err)
+	}
+}
+
+func TestAccountManager_NameAuctionClaimDeadline(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	if err := am.AddAccountBalanceByID(common.Name("escrowrecipient"), assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	cfg := params.DefaultChainconfig.Copy()
+	cfg.SysTokenID = assetID
+	cfg.NamePriceCfg = &params.PriceConfig{CreateFee: big.NewInt(5), PremiumLength: 10, MinBid: big.NewInt(10), AuctionBlocks: 5, ClaimBlocks: 3}
+	pubkey, _ := GeneragePubKey()
+	premium := common.Name("premium")
+	balance := func(name string) int64 {
+		b, _ := am.GetAccountBalanceByID(common.Name(name), assetID, 0)
+		return b.Int64()
+	}
+
+	// the winner misses the deadline, the next bid opens a new auction and refunds the winner
+	bid := &BidAccountNameAction{AccountName: premium}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowsender"), big.NewInt(20), 1, bid); err != nil {
+		t.Fatal(err)
+	}
+	if auction, _ := am.GetNameAuction(premium); auction.ClaimNumber != 9 {
+		t.Fatalf("claim number %v, want 9", auction.ClaimNumber)
+	}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowrecipient"), big.NewInt(10), 8, bid); err != ErrNameAuctionEnded {
+		t.Fatalf("bid before the deadline err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.BidAccountName, common.Name("escrowrecipient"), big.NewInt(10), 9, bid); err != nil {
+		t.Fatal(err)
+	}
+	if balance("escrowsender") != 100 || balance("escrowrecipient") != 90 {
+		t.Fatalf("balances sender %v recipient %v", balance("escrowsender"), balance("escrowrecipient"))
+	}
+	auction, err := am.GetNameAuction(premium)
+	if err != nil || auction.Bidder != common.Name("escrowrecipient") || auction.EndNumber != 14 || auction.ClaimNumber != 17 {
+		t.Fatalf("new auction %v, %v", auction, err)
+	}
+
+	// the late claim of the winner releases the name and refunds the bid
+	claim := &ClaimAccountNameAction{AccountName: premium, PublicKey: pubkey}
+	if err := processPriceAction(am, cfg, types.ClaimAccountName, common.Name("escrowrecipient"), big.NewInt(0), 17, claim); err != nil {
+		t.Fatal(err)
+	}
+	if exist, _ := am.AccountIsExist(premium); exist {
+		t.Fatal("lapsed premium account created")
+	}
+	if _, err := am.GetNameAuction(premium); err != ErrNameAuctionNotExist {
+		t.Fatalf("lapsed auction not removed err %v", err)
+	}
+	if balance("escrowrecipient") != 100 {
+		t.Fatalf("lapsed bid not refunded, balance %v", balance("escrowrecipient"))
+	}
+}
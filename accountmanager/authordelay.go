@@ -0,0 +1,228 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	acctAuthorDelayPrefix   = "acctAuthorDelay"
+	acctPendingAuthorPrefix = "acctPendingAuthor"
+)
+
+// SetAuthorDelayAction set the blocks an author update of the sender waits
+// before it can be applied, 0 applies the author update immediately.
+type SetAuthorDelayAction struct {
+	Delay uint64 `json:"delay,omitempty"`
+}
+
+// ApplyAuthorUpdateAction apply the matured pending author update of the account.
+type ApplyAuthorUpdateAction struct {
+	Account common.Name `json:"account,omitempty"`
+}
+
+// PendingAuthorUpdate author update waiting for the delay of the account. The
+// current authors may cancel it before EffectiveNumber, after that anyone may
+// apply it. A decrease of the delay is delayed as well, so a stolen key can
+// not remove the protection first.
+type PendingAuthorUpdate struct {
+	Author          AccountAuthorAction `json:"author"`
+	SetDelay        bool                `json:"setDelay"`
+	Delay           uint64              `json:"delay"`
+	Number          uint64              `json:"number"`
+	EffectiveNumber uint64              `json:"effectiveNumber"`
+}
+
+//GetAuthorDelay get the author update delay of the account
+func (am *AccountManager) GetAuthorDelay(accountName common.Name) (uint64, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return 0, err
+	}
+	if accountID == 0 {
+		return 0, ErrAccountNotExist
+	}
+	return am.getAuthorDelay(accountID)
+}
+
+func (am *AccountManager) getAuthorDelay(accountID uint64) (uint64, error) {
+	b, err := am.sdb.Get(acctManagerName, acctAuthorDelayPrefix+strconv.FormatUint(accountID, 10))
+	if err != nil {
+		return 0, err
+	}
+	var delay uint64
+	if len(b) == 0 {
+		return delay, nil
+	}
+	if err := rlp.DecodeBytes(b, &delay); err != nil {
+		return 0, err
+	}
+	return delay, nil
+}
+
+func (am *AccountManager) setAuthorDelay(accountID uint64, delay uint64) error {
+	if delay == 0 {
+		am.sdb.Delete(acctManagerName, acctAuthorDelayPrefix+strconv.FormatUint(accountID, 10))
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(delay)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctAuthorDelayPrefix+strconv.FormatUint(accountID, 10), b)
+	return nil
+}
+
+//GetPendingAuthorUpdate get the pending author update of the account
+func (am *AccountManager) GetPendingAuthorUpdate(accountName common.Name) (*PendingAuthorUpdate, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.getPendingAuthorUpdate(accountID)
+}
+
+func (am *AccountManager) getPendingAuthorUpdate(accountID uint64) (*PendingAuthorUpdate, error) {
+	b, err := am.sdb.Get(acctManagerName, acctPendingAuthorPrefix+strconv.FormatUint(accountID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrAuthorUpdateNotExist
+	}
+	var pending PendingAuthorUpdate
+	if err := rlp.DecodeBytes(b, &pending); err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
+
+//queueAuthorUpdate store the pending author update of the account, only one update can be pending
+func (am *AccountManager) queueAuthorUpdate(accountID uint64, pending *PendingAuthorUpdate) error {
+	if _, err := am.getPendingAuthorUpdate(accountID); err == nil {
+		return ErrAuthorUpdatePending
+	} else if err != ErrAuthorUpdateNotExist {
+		return err
+	}
+	b, err := rlp.EncodeToBytes(pending)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctPendingAuthorPrefix+strconv.FormatUint(accountID, 10), b)
+	return nil
+}
+
+func (am *AccountManager) deletePendingAuthorUpdate(accountID uint64) {
+	am.sdb.Delete(acctManagerName, acctPendingAuthorPrefix+strconv.FormatUint(accountID, 10))
+}
+
+//DelayAccountAuthor queue the author update if the account has a delay, returns false if the update should be applied now
+func (am *AccountManager) DelayAccountAuthor(accountName common.Name, number uint64, acctAuth *AccountAuthorAction) (bool, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return false, err
+	}
+	if accountID == 0 {
+		return false, ErrAccountNotExist
+	}
+	delay, err := am.getAuthorDelay(accountID)
+	if err != nil || delay == 0 {
+		return false, err
+	}
+	if err := am.CheckAccountAuthor(accountName, acctAuth); err != nil {
+		return false, err
+	}
+	return true, am.queueAuthorUpdate(accountID, &PendingAuthorUpdate{
+		Author:          *acctAuth,
+		Number:          number,
+		EffectiveNumber: number + delay,
+	})
+}
+
+//SetAuthorDelay set the author update delay of the account, a decrease waits for the current delay
+func (am *AccountManager) SetAuthorDelay(accountName common.Name, number uint64, action *SetAuthorDelayAction) error {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	delay, err := am.getAuthorDelay(accountID)
+	if err != nil {
+		return err
+	}
+	if action.Delay >= delay {
+		return am.setAuthorDelay(accountID, action.Delay)
+	}
+	return am.queueAuthorUpdate(accountID, &PendingAuthorUpdate{
+		SetDelay:        true,
+		Delay:           action.Delay,
+		Number:          number,
+		EffectiveNumber: number + delay,
+	})
+}
+
+//CancelAuthorUpdate cancel the pending author update of the account
+func (am *AccountManager) CancelAuthorUpdate(accountName common.Name) error {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	if _, err := am.getPendingAuthorUpdate(accountID); err != nil {
+		return err
+	}
+	am.deletePendingAuthorUpdate(accountID)
+	return nil
+}
+
+//ApplyAuthorUpdate apply the pending author update of the account after the delay
+func (am *AccountManager) ApplyAuthorUpdate(number uint64, action *ApplyAuthorUpdateAction) error {
+	accountID, err := am.GetAccountIDByName(action.Account)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	pending, err := am.getPendingAuthorUpdate(accountID)
+	if err != nil {
+		return err
+	}
+	if number < pending.EffectiveNumber {
+		return ErrAuthorUpdateNotMatured
+	}
+	if pending.SetDelay {
+		if err := am.setAuthorDelay(accountID, pending.Delay); err != nil {
+			return err
+		}
+	} else if err := am.UpdateAccountAuthor(action.Account, &pending.Author); err != nil {
+		return err
+	}
+	am.deletePendingAuthorUpdate(accountID)
+	return nil
+}
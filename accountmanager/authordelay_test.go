@@ -0,0 +1,84 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_AuthorDelay(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	sender := common.Name("escrowsender")
+	update := &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: AddAuthor, Author: common.NewAuthor(common.Name("escrowrecipient"), 1)}}}
+	authorNum := func() int {
+		acct, err := am.GetAccountByName(sender)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(acct.Authors)
+	}
+
+	if err := processEscrowAction(am, types.SetAuthorDelay, sender, 0, big.NewInt(0), 1, &SetAuthorDelayAction{Delay: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.UpdateAccountAuthor, sender, 0, big.NewInt(0), 2, update); err != nil {
+		t.Fatal(err)
+	}
+	if authorNum() != 1 {
+		t.Fatal("author update applied before the delay")
+	}
+	if err := processEscrowAction(am, types.UpdateAccountAuthor, sender, 0, big.NewInt(0), 3, update); err != ErrAuthorUpdatePending {
+		t.Fatalf("second update err %v", err)
+	}
+	if err := processEscrowAction(am, types.ApplyAuthorUpdate, common.Name("escrowrecipient"), 0, big.NewInt(0), 11, &ApplyAuthorUpdateAction{Account: sender}); err != ErrAuthorUpdateNotMatured {
+		t.Fatalf("apply before maturity err %v", err)
+	}
+
+	if err := processEscrowAction(am, types.CancelAuthorUpdate, sender, 0, big.NewInt(0), 5, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.GetPendingAuthorUpdate(sender); err != ErrAuthorUpdateNotExist {
+		t.Fatalf("pending update not cancelled err %v", err)
+	}
+
+	if err := processEscrowAction(am, types.UpdateAccountAuthor, sender, 0, big.NewInt(0), 6, update); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.ApplyAuthorUpdate, common.Name("escrowrecipient"), 0, big.NewInt(0), 16, &ApplyAuthorUpdateAction{Account: sender}); err != nil {
+		t.Fatal(err)
+	}
+	if authorNum() != 2 {
+		t.Fatal("author update not applied after the delay")
+	}
+
+	if err := processEscrowAction(am, types.SetAuthorDelay, sender, 0, big.NewInt(0), 20, &SetAuthorDelayAction{Delay: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if delay, _ := am.GetAuthorDelay(sender); delay != 10 {
+		t.Fatalf("delay decreased immediately, got %d", delay)
+	}
+	if err := processEscrowAction(am, types.ApplyAuthorUpdate, sender, 0, big.NewInt(0), 30, &ApplyAuthorUpdateAction{Account: sender}); err != nil {
+		t.Fatal(err)
+	}
+	if delay, _ := am.GetAuthorDelay(sender); delay != 0 {
+		t.Fatalf("delay not decreased after the delay, got %d", delay)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"github.com/fractalplatform/fractal/params"
+)
+
+// authorLimits the max sign depth, sign length and authors of an account
+type authorLimits struct {
+	signDepth  uint64
+	signLength uint64
+	authorNum  uint64
+}
+
+var (
+	// defaultAuthorLimits the limits of the blocks before ForkID4
+	defaultAuthorLimits = authorLimits{params.MaxSignDepth, params.MaxSignLength, params.MaxAuthorNum}
+	// chainAuthorLimits the limits of the chain config, set by SetAccountNameConfig
+	chainAuthorLimits = defaultAuthorLimits
+)
+
+// authorLimit returns the author limits of the block of the state. The limits of
+// the chain config apply from ForkID4, the earlier blocks are checked by the
+// default limits they were produced with.
+func (am *AccountManager) authorLimit() (authorLimits, error) {
+	forked, err := am.isForked(params.ForkID4)
+	if err != nil {
+		return authorLimits{}, err
+	}
+	if !forked {
+		return defaultAuthorLimits, nil
+	}
+	return chainAuthorLimits, nil
+}
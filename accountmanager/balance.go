@@ -0,0 +1,314 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// From ForkID4 every (accountID, assetID) balance is stored under its own key,
+// the asset ids the account holds are kept in a sorted index. The account
+// object is stored without balances, so a transfer only rewrites the balances
+// it touches.
+//
+// Before the fork the balances are kept in the account object as they always
+// were. Accounts written before the fork are all migrated to the per-asset keys
+// by MigrateAllAccountBalances at the block activating it.
+var (
+	acctBalancePrefix       = "acctBalance"
+	acctAssetIndexPrefix    = "acctAssetIndex"
+	acctBalancesMigratedKey = "acctBalancesMigrated"
+)
+
+// stateGetter reads the value of the key in the account manager storage.
+type stateGetter func(key string) ([]byte, error)
+
+func balanceKey(accountID uint64, assetID uint64) string {
+	return acctBalancePrefix + strconv.FormatUint(accountID, 10) + "_" + strconv.FormatUint(assetID, 10)
+}
+
+func assetIndexKey(accountID uint64) string {
+	return acctAssetIndexPrefix + strconv.FormatUint(accountID, 10)
+}
+
+func (am *AccountManager) stateGet(key string) ([]byte, error) {
+	return am.sdb.Get(acctManagerName, key)
+}
+
+// isLegacyAccount check the balances of the account are still in the account object
+func isLegacyAccount(acct *Account) bool {
+	return len(acct.Balances) != 0
+}
+
+func getAssetIndex(get stateGetter, accountID uint64) ([]uint64, error) {
+	b, err := get(assetIndexKey(accountID))
+	if err != nil {
+		return nil, err
+	}
+	var index []uint64
+	if len(b) == 0 {
+		return index, nil
+	}
+	if err := rlp.DecodeBytes(b, &index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func getBalance(get stateGetter, accountID uint64, assetID uint64) (*big.Int, bool, error) {
+	b, err := get(balanceKey(accountID, assetID))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b) == 0 {
+		return big.NewInt(0), false, nil
+	}
+	balance := new(big.Int)
+	if err := rlp.DecodeBytes(b, balance); err != nil {
+		return nil, false, err
+	}
+	return balance, true, nil
+}
+
+// loadBalances fill the balances of the account from the per-asset keys
+func loadBalances(get stateGetter, acct *Account) error {
+	if isLegacyAccount(acct) {
+		return nil
+	}
+	index, err := getAssetIndex(get, acct.GetAccountID())
+	if err != nil {
+		return err
+	}
+	balances := make([]*AssetBalance, 0, len(index))
+	for _, assetID := range index {
+		balance, _, err := getBalance(get, acct.GetAccountID(), assetID)
+		if err != nil {
+			return err
+		}
+		balances = append(balances, newAssetBalance(assetID, balance))
+	}
+	acct.Balances = balances
+	return nil
+}
+
+// accountBalance get the balance of the account object loaded without balances
+func (am *AccountManager) accountBalance(acct *Account, assetID uint64) (*big.Int, error) {
+	if isLegacyAccount(acct) {
+		return acct.GetBalanceByID(assetID)
+	}
+	balance, exist, err := getBalance(am.stateGet, acct.GetAccountID(), assetID)
+	if err != nil {
+		return big.NewInt(0), err
+	}
+	if !exist {
+		return big.NewInt(0), ErrAccountAssetNotExist
+	}
+	return balance, nil
+}
+
+// setAccountBalance store the balance of the migrated account, returns true if the asset is new to the account
+func (am *AccountManager) setAccountBalance(accountID uint64, assetID uint64, amount *big.Int) (bool, error) {
+	if redenominating, err := am.isRedenominating(assetID); err != nil {
+		return false, err
+	} else if redenominating {
+		return false, ErrAssetRedenominating
+	}
+	return am.putAccountBalance(accountID, assetID, amount)
+}
+
+// splitBalances check the balances are stored under the per-asset keys
+func (am *AccountManager) splitBalances() (bool, error) {
+	return am.isForked(params.ForkID4)
+}
+
+// putAccountBalance store the balance without checking the asset is redenominating
+func (am *AccountManager) putAccountBalance(accountID uint64, assetID uint64, amount *big.Int) (bool, error) {
+	if am.readOnly {
+		return false, ErrAccountManagerReadOnly
+	}
+	if split, err := am.splitBalances(); err != nil {
+		return false, err
+	} else if !split {
+		return am.putLegacyBalance(accountID, assetID, amount)
+	}
+	if err := am.touchStorage(accountID); err != nil {
+		return false, err
+	}
+	old, err := am.stateGet(balanceKey(accountID, assetID))
+	if err != nil {
+		return false, err
+	}
+	exist := len(old) != 0
+	delta := -int64(len(old))
+	if !exist {
+		oldIndex, err := am.stateGet(assetIndexKey(accountID))
+		if err != nil {
+			return false, err
+		}
+		index, err := getAssetIndex(am.stateGet, accountID)
+		if err != nil {
+			return false, err
+		}
+		i := sort.Search(len(index), func(i int) bool { return index[i] >= assetID })
+		index = append(index, 0)
+		copy(index[i+1:], index[i:])
+		index[i] = assetID
+		b, err := rlp.EncodeToBytes(index)
+		if err != nil {
+			return false, err
+		}
+		am.sdb.Put(acctManagerName, assetIndexKey(accountID), b)
+		delta += int64(len(b)) - int64(len(oldIndex)) + int64(len(balanceKey(accountID, assetID)))
+		if len(oldIndex) == 0 {
+			delta += int64(len(assetIndexKey(accountID)))
+		}
+	}
+	oldAmount := new(big.Int)
+	if exist {
+		if err := rlp.DecodeBytes(old, oldAmount); err != nil {
+			return false, err
+		}
+	}
+	if err := am.updateAssetHolder(assetID, accountID, oldAmount, amount); err != nil {
+		return false, err
+	}
+	b, err := rlp.EncodeToBytes(amount)
+	if err != nil {
+		return false, err
+	}
+	am.sdb.Put(acctManagerName, balanceKey(accountID, assetID), b)
+	delta += int64(len(b))
+	if err := am.addBalancesSize(accountID, delta); err != nil {
+		return false, err
+	}
+	return !exist, nil
+}
+
+// putLegacyBalance store the balance inside the account object, the layout before ForkID4
+func (am *AccountManager) putLegacyBalance(accountID uint64, assetID uint64, amount *big.Int) (bool, error) {
+	acct, err := am.getAccountMetaById(accountID)
+	if err != nil {
+		return false, err
+	}
+	if acct == nil {
+		return false, ErrAccountNotExist
+	}
+	if acct.IsDestroyed() {
+		return false, ErrAccountIsDestroy
+	}
+	p, find := acct.binarySearch(assetID)
+	if find {
+		acct.Balances[p].Balance = amount
+	} else {
+		acct.AddNewAssetByAssetID(p, assetID, amount)
+	}
+	return !find, am.putAccount(acct)
+}
+
+// storeBalances store the changed balances of the account to the per-asset keys
+func (am *AccountManager) storeBalances(acct *Account) error {
+	for _, ab := range acct.Balances {
+		balance, exist, err := getBalance(am.stateGet, acct.GetAccountID(), ab.AssetID)
+		if err != nil {
+			return err
+		}
+		if exist && balance.Cmp(ab.Balance) == 0 {
+			continue
+		}
+		if _, err := am.setAccountBalance(acct.GetAccountID(), ab.AssetID, ab.Balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getBalanceAccountByName get the account object without balances for balance updates, migrate the legacy account first
+func (am *AccountManager) getBalanceAccountByName(accountName common.Name) (*Account, error) {
+	acct, err := am.getAccountMetaByName(accountName)
+	if err != nil || acct == nil {
+		return acct, err
+	}
+	split, err := am.splitBalances()
+	if err != nil {
+		return nil, err
+	}
+	if split && isLegacyAccount(acct) {
+		if err := am.SetAccount(acct); err != nil {
+			return nil, err
+		}
+		acct.Balances = make([]*AssetBalance, 0)
+	}
+	return acct, nil
+}
+
+// MigrateAccountBalances move the balances of the account object to the per-asset keys
+func (am *AccountManager) MigrateAccountBalances(accountName common.Name) (bool, error) {
+	acct, err := am.getAccountMetaByName(accountName)
+	if err != nil {
+		return false, err
+	}
+	if acct == nil {
+		return false, ErrAccountNotExist
+	}
+	if !isLegacyAccount(acct) || acct.IsDestroyed() {
+		return false, nil
+	}
+	if split, err := am.splitBalances(); err != nil || !split {
+		return false, err
+	}
+	if err := am.SetAccount(acct); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MigrateAllAccountBalances move the balances of every legacy account to the per-asset keys,
+// the accounts are indexed as the holders of their assets
+func (am *AccountManager) MigrateAllAccountBalances() error {
+	if split, err := am.splitBalances(); err != nil || !split {
+		return err
+	}
+	counter, err := am.getAccountCounter()
+	if err != nil {
+		return err
+	}
+	for id := counterID + 1; id <= counter; id++ {
+		acct, err := am.getAccountMetaById(id)
+		if err != nil {
+			return err
+		}
+		if acct == nil || !isLegacyAccount(acct) || acct.IsDestroyed() {
+			continue
+		}
+		if err := am.SetAccount(acct); err != nil {
+			return err
+		}
+	}
+	return am.putUint64(acctBalancesMigratedKey, counter)
+}
+
+// balancesMigrated check no account keeps its balances in the account object
+func (am *AccountManager) balancesMigrated() (bool, error) {
+	_, migrated, err := am.getUint64(acctBalancesMigratedKey)
+	return migrated, err
+}
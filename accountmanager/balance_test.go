@@ -0,0 +1,228 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_SplitBalances(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, _ := GeneragePubKey()
+	name := common.Name("splitbalance")
+	if err := am.CreateAccount(common.Name("fractal"), name, common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	acct, err := am.getAccountMetaByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// write the account object in the legacy layout, balances inside the object
+	acct.Balances = []*AssetBalance{newAssetBalance(1, big.NewInt(10)), newAssetBalance(3, big.NewInt(30))}
+	b, err := rlp.EncodeToBytes(acct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	am.sdb.Put(acctManagerName, acctInfoPrefix+strconv.FormatUint(acct.GetAccountID(), 10), b)
+	am.resetCache()
+
+	if balance, err := am.GetAccountBalanceByID(name, 3, 0); err != nil || balance.Cmp(big.NewInt(30)) != 0 {
+		t.Errorf("legacy balance = %v, %v", balance, err)
+	}
+	if err := am.AddAccountBalanceByID(name, 2, big.NewInt(20)); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := am.getAccountMetaByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isLegacyAccount(meta) {
+		t.Fatal("account not migrated")
+	}
+	index, err := getAssetIndex(am.stateGet, meta.GetAccountID())
+	if err != nil || len(index) != 3 || index[0] != 1 || index[1] != 2 || index[2] != 3 {
+		t.Errorf("asset index = %v, %v", index, err)
+	}
+
+	full, err := am.GetAccountByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{10, 20, 30}
+	if len(full.Balances) != len(want) {
+		t.Fatalf("balances = %v", full.Balances)
+	}
+	for i, ab := range full.Balances {
+		if ab.Balance.Cmp(big.NewInt(want[i])) != 0 {
+			t.Errorf("balance of asset %d = %v, want %d", ab.AssetID, ab.Balance, want[i])
+		}
+	}
+
+	if err := am.SubAccountBalanceByID(name, 1, big.NewInt(11)); err != ErrInsufficientBalance {
+		t.Errorf("SubAccountBalanceByID err = %v, want %v", err, ErrInsufficientBalance)
+	}
+	if err := am.SubAccountBalanceByID(name, 1, big.NewInt(4)); err != nil {
+		t.Fatal(err)
+	}
+	if balance, err := am.GetAccountBalanceByID(name, 1, 0); err != nil || balance.Cmp(big.NewInt(6)) != 0 {
+		t.Errorf("balance = %v, %v", balance, err)
+	}
+	if _, err := am.GetAccountBalanceByID(name, 4, 0); err != ErrAccountAssetNotExist {
+		t.Errorf("GetAccountBalanceByID err = %v, want %v", err, ErrAccountAssetNotExist)
+	}
+	if migrated, err := am.MigrateAccountBalances(name); err != nil || migrated {
+		t.Errorf("MigrateAccountBalances = %v, %v", migrated, err)
+	}
+}
+
+func TestAccountManager_BalancesBeforeFork(t *testing.T) {
+	statedb := getStateDB()
+	setForkID(statedb, params.ForkID3)
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, _ := GeneragePubKey()
+	from, to := common.Name("forkbalancefrom"), common.Name("forkbalanceto")
+	for _, name := range []common.Name{from, to} {
+		if err := am.CreateAccount(common.Name("fractal"), name, common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	issue := IssueAsset{AssetName: "forkcoin", Symbol: "fkc", Amount: big.NewInt(100), Owner: from, UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(from, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(from, assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(from, to, assetID, big.NewInt(40)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the balances stay inside the account objects and no per-asset key is written
+	for name, want := range map[common.Name]int64{from: 60, to: 40} {
+		acct, err := am.getAccountMetaByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance, err := acct.GetBalanceByID(assetID); err != nil || balance.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("%v object balance = %v, %v, want %d", name, balance, err, want)
+		}
+		if _, exist, err := getBalance(am.stateGet, acct.GetAccountID(), assetID); err != nil || exist {
+			t.Errorf("%v per-asset balance exist = %v, %v", name, exist, err)
+		}
+	}
+	if migrated, err := am.MigrateAccountBalances(from); err != nil || migrated {
+		t.Errorf("MigrateAccountBalances = %v, %v", migrated, err)
+	}
+
+	// the first balance change after the fork moves the balances out of the object
+	setForkID(statedb, params.ForkID4)
+	if err := am.TransferAsset(from, to, assetID, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[common.Name]int64{from: 50, to: 50} {
+		acct, err := am.getAccountMetaByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isLegacyAccount(acct) {
+			t.Errorf("%v not migrated", name)
+		}
+		if balance, err := am.GetAccountBalanceByID(name, assetID, 0); err != nil || balance.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("%v balance = %v, %v, want %d", name, balance, err, want)
+		}
+	}
+}
+
+func TestAccountManager_MigrateAllAccountBalances(t *testing.T) {
+	statedb := getStateDB()
+	setForkID(statedb, params.ForkID3)
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, _ := GeneragePubKey()
+	from, to := common.Name("migrateallfrom"), common.Name("migrateallto")
+	for _, name := range []common.Name{from, to} {
+		if err := am.CreateAccount(common.Name("fractal"), name, common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	issue := IssueAsset{AssetName: "migratecoin", Symbol: "mgc", Amount: big.NewInt(100), Owner: from, UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(from, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(from, assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(from, to, assetID, big.NewInt(40)); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is migrated before the fork
+	if err := am.MigrateAllAccountBalances(); err != nil {
+		t.Fatal(err)
+	}
+	if migrated, err := am.balancesMigrated(); err != nil || migrated {
+		t.Fatalf("balancesMigrated before fork = %v, %v", migrated, err)
+	}
+	if count, _ := am.GetAssetHolderCount(assetID); count != 0 {
+		t.Fatalf("holder count before fork %v, want 0", count)
+	}
+
+	setForkID(statedb, params.ForkID4)
+	if err := am.MigrateAllAccountBalances(); err != nil {
+		t.Fatal(err)
+	}
+	if migrated, err := am.balancesMigrated(); err != nil || !migrated {
+		t.Fatalf("balancesMigrated = %v, %v", migrated, err)
+	}
+	holders, err := am.GetAssetHolders(assetID, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[common.Name]int64{from: 60, to: 40}
+	if len(holders.Holders) != len(want) {
+		t.Fatalf("holders %d, want %d", len(holders.Holders), len(want))
+	}
+	for _, holder := range holders.Holders {
+		if holder.Balance.Cmp(big.NewInt(want[holder.Name])) != 0 {
+			t.Errorf("%v balance = %v, want %d", holder.Name, holder.Balance, want[holder.Name])
+		}
+		acct, err := am.getAccountMetaByName(holder.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isLegacyAccount(acct) {
+			t.Errorf("%v not migrated", holder.Name)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/snapshot"
+)
+
+// MaxBalanceHistoryPoints the maximum number of points of a balance history.
+const MaxBalanceHistoryPoints = 1024
+
+// BalancePoint the balance of a balance history at a time, read from the last
+// snapshot taken at or before the time.
+type BalancePoint struct {
+	Time         uint64   `json:"time"`
+	SnapshotTime uint64   `json:"snapshotTime"`
+	Balance      *big.Int `json:"balance"`
+}
+
+//GetBalanceHistory get the balances of the asset of the account from fromTime to toTime every step,
+//step 0 gets the balance at every snapshot in the range. The times before the first snapshot are skipped.
+func (am *AccountManager) GetBalanceHistory(accountName common.Name, assetID uint64, fromTime, toTime, step uint64) ([]*BalancePoint, error) {
+	if fromTime > toTime {
+		return nil, fmt.Errorf("from time %d after to time %d", fromTime, toTime)
+	}
+	if step != 0 && (toTime-fromTime)/step >= MaxBalanceHistoryPoints {
+		return nil, fmt.Errorf("more than %d points from %d to %d every %d", MaxBalanceHistoryPoints, fromTime, toTime, step)
+	}
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+
+	snapshotManager := snapshot.NewSnapshotManager(am.sdb)
+	times, err := snapshotManager.GetSnapshotTimes(math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	// the times are returned latest first
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	var samples []uint64
+	if step == 0 {
+		for _, time := range times {
+			if time >= fromTime && time <= toTime {
+				samples = append(samples, time)
+			}
+		}
+		if len(samples) > MaxBalanceHistoryPoints {
+			return nil, fmt.Errorf("more than %d snapshots from %d to %d", MaxBalanceHistoryPoints, fromTime, toTime)
+		}
+	} else {
+		for time := fromTime; time <= toTime; time += step {
+			samples = append(samples, time)
+			if time > math.MaxUint64-step {
+				break
+			}
+		}
+	}
+
+	balances := make(map[uint64]*big.Int)
+	points := make([]*BalancePoint, 0, len(samples))
+	for _, time := range samples {
+		// the last snapshot at or before the time
+		i := sort.Search(len(times), func(i int) bool { return times[i] > time })
+		if i == 0 {
+			continue
+		}
+		snapshotTime := times[i-1]
+		balance, ok := balances[snapshotTime]
+		if !ok {
+			if balance, err = snapshotBalance(snapshotManager, snapshotTime, accountID, assetID); err != nil {
+				return nil, err
+			}
+			balances[snapshotTime] = balance
+		}
+		points = append(points, &BalancePoint{Time: time, SnapshotTime: snapshotTime, Balance: new(big.Int).Set(balance)})
+	}
+	return points, nil
+}
+
+// snapshotBalance get the balance of the asset of the account at the snapshot,
+// 0 if the account did not exist or did not hold the asset.
+func snapshotBalance(snapshotManager *snapshot.SnapshotManager, time uint64, accountID, assetID uint64) (*big.Int, error) {
+	snapshotState, err := snapshotManager.GetSnapshotState(time)
+	if err != nil {
+		return nil, err
+	}
+	acct, err := snapshotAccount(snapshotState, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return big.NewInt(0), nil
+	}
+	balance, err := acct.GetBalanceByID(assetID)
+	if err == ErrAccountAssetNotExist {
+		return big.NewInt(0), nil
+	}
+	return balance, err
+}
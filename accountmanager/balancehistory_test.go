@@ -0,0 +1,107 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestAccountManager_BalanceHistory(t *testing.T) {
+	db := memdb.NewMemDatabase()
+	cachedb := state.NewDatabase(db)
+	pubkey, _ := GeneragePubKey()
+
+	// the account holds 0, 100 and 250 at the snapshots of 1000, 2000 and 3000
+	var roots []common.Hash
+	root := common.Hash{}
+	for i, amount := range []int64{0, 100, 150} {
+		statedb, _ := state.New(root, cachedb)
+		am, _ := NewAccountManager(statedb)
+		if i == 0 {
+			for _, name := range []string{"fractal", "historyaccount"} {
+				if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pubkey, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if amount > 0 {
+			if err := am.AddAccountBalanceByID(common.Name("historyaccount"), 1, big.NewInt(amount)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		batch := db.NewBatch()
+		var err error
+		if root, err = statedb.Commit(batch, common.Hash{}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := cachedb.TrieDB().Commit(root, false); err != nil {
+			t.Fatal(err)
+		}
+		batch.Write()
+		roots = append(roots, root)
+	}
+
+	statedb, _ := state.New(root, cachedb)
+	snapshotManager := snapshot.NewSnapshotManager(statedb)
+	for i, root := range roots {
+		block := types.SnapshotBlock{Number: uint64(i + 1), BlockHash: common.Hash{byte(i)}}
+		rawdb.WriteSnapshot(db, block, types.SnapshotInfo{Root: root})
+		if err := snapshotManager.SetSnapshot(uint64(i+1)*1000, snapshot.BlockInfo{Number: block.Number, BlockHash: block.BlockHash, Timestamp: uint64(i) * 1000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	am, _ := NewAccountManager(statedb)
+
+	check := func(points []*BalancePoint, want [][3]int64) {
+		t.Helper()
+		if len(points) != len(want) {
+			t.Fatalf("%d points, want %d", len(points), len(want))
+		}
+		for i, p := range points {
+			if int64(p.Time) != want[i][0] || int64(p.SnapshotTime) != want[i][1] || p.Balance.Int64() != want[i][2] {
+				t.Fatalf("point %d: %d %d %v, want %v", i, p.Time, p.SnapshotTime, p.Balance, want[i])
+			}
+		}
+	}
+
+	points, err := am.GetBalanceHistory(common.Name("historyaccount"), 1, 500, 3500, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(points, [][3]int64{{1000, 1000, 0}, {1500, 1000, 0}, {2000, 2000, 100}, {2500, 2000, 100}, {3000, 3000, 250}, {3500, 3000, 250}})
+
+	points, err = am.GetBalanceHistory(common.Name("historyaccount"), 1, 1500, 3000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(points, [][3]int64{{2000, 2000, 100}, {3000, 3000, 250}})
+
+	if _, err := am.GetBalanceHistory(common.Name("historyaccount"), 1, 0, MaxBalanceHistoryPoints, 1); err == nil {
+		t.Fatal("too many points accepted")
+	}
+	if _, err := am.GetBalanceHistory(common.Name("historyaccount"), 1, 2000, 1000, 1); err == nil {
+		t.Fatal("reversed range accepted")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+
+	"github.com/fractalplatform/fractal/types"
+)
+
+//processBatch execute the sub-actions of the batch in order, the first failing
+//sub-action fails the batch and Process reverts the changes of all of them
+func (am *AccountManager) processBatch(accountManagerContext *types.AccountManagerContext) ([]*types.InternalAction, error) {
+	action := accountManagerContext.Action
+	subs, err := types.DecodeBatch(action.Data())
+	if err != nil {
+		return nil, err
+	}
+	var internalActions []*types.InternalAction
+	for i, sub := range subs {
+		subAction := sub.Action(action.Sender())
+		subContext := *accountManagerContext
+		subContext.Action = subAction
+		subInternalActions, err := am.process(&subContext)
+		if err != nil {
+			return nil, fmt.Errorf("batch sub-action %d: %v", i, err)
+		}
+		// each sub-action is followed by its own internal actions
+		internalActions = append(internalActions, &types.InternalAction{Action: subAction.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""})
+		for _, internalAction := range subInternalActions {
+			internalAction.Depth++
+			internalActions = append(internalActions, internalAction)
+		}
+	}
+	return internalActions, nil
+}
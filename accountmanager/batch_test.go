@@ -0,0 +1,107 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func batchSubAction(t *testing.T, aType types.ActionType, to string, assetID uint64, amount int64, data interface{}) *types.SubAction {
+	var payload []byte
+	if data != nil {
+		var err error
+		if payload, err = rlp.EncodeToBytes(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &types.SubAction{Type: aType, To: common.Name(to), AssetID: assetID, Amount: big.NewInt(amount), Payload: payload}
+}
+
+func TestAccountManager_BatchAction(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	pubkey, _ := GeneragePubKey()
+	accountName, assetName := params.DefaultChainconfig.AccountName, params.DefaultChainconfig.AssetName
+	if err := am.CreateAccount(common.Name("fractal"), common.Name(assetName), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	subs := []*types.SubAction{
+		batchSubAction(t, types.CreateAccount, accountName, assetID, 10, &CreateAccountAction{AccountName: "batchaccount", PublicKey: pubkey}),
+		batchSubAction(t, types.IssueAsset, assetName, 0, 0, &IssueAsset{AssetName: "batchcoin", Symbol: "bch", Amount: big.NewInt(1000), Owner: "escrowsender", UpperLimit: big.NewInt(0)}),
+		batchSubAction(t, types.Transfer, "batchaccount", assetID, 20, nil),
+	}
+	internalActions, err := processBatchAction(am, "escrowsender", subs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID("batchaccount", assetID, 0); balance.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("batch account balance %v", balance)
+	}
+	if _, err := am.GetAssetInfoByName("batchcoin"); err != nil {
+		t.Fatal(err)
+	}
+	// every sub-action leads its own internal actions
+	var subActions int
+	for _, internalAction := range internalActions {
+		if internalAction.Depth == 0 {
+			subActions++
+		}
+	}
+	if subActions != len(subs) {
+		t.Fatalf("batch internal actions of %d sub-actions, want %d", subActions, len(subs))
+	}
+
+	// the failing last sub-action reverts the former ones
+	subs = []*types.SubAction{
+		batchSubAction(t, types.CreateAccount, accountName, assetID, 0, &CreateAccountAction{AccountName: "batchaccount2", PublicKey: pubkey}),
+		batchSubAction(t, types.Transfer, "batchaccount2", assetID, 1000, nil),
+	}
+	if _, err := processBatchAction(am, "escrowsender", subs); err == nil {
+		t.Fatal("batch with failing sub-action succeeded")
+	}
+	if exist, _ := am.AccountIsExist("batchaccount2"); exist {
+		t.Fatal("account of the failed batch is created")
+	}
+	if balance, _ := am.GetAccountBalanceByID("escrowsender", assetID, 0); balance.Cmp(big.NewInt(70)) != 0 {
+		t.Fatalf("sender balance after the failed batch %v", balance)
+	}
+
+	for _, subs := range [][]*types.SubAction{
+		nil,
+		{batchSubAction(t, types.CallContract, "escrowrecipient", assetID, 0, nil)},
+		{batchSubAction(t, types.UpdateAccountAuthor, accountName, assetID, 0, nil)},
+	} {
+		if _, err := processBatchAction(am, "escrowsender", subs); err == nil {
+			t.Fatalf("invalid batch %v succeeded", subs)
+		}
+	}
+}
+
+func processBatchAction(am *AccountManager, from common.Name, subs []*types.SubAction) ([]*types.InternalAction, error) {
+	payload, err := rlp.EncodeToBytes(subs)
+	if err != nil {
+		return nil, err
+	}
+	action := types.NewAction(types.BatchAction, from, common.Name(params.DefaultChainconfig.AccountName), 0, 0, 0, big.NewInt(0), payload, nil)
+	return am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: 1})
+}
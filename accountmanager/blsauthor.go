@@ -0,0 +1,85 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto/bls"
+	"github.com/fractalplatform/fractal/types"
+)
+
+var blsAuthors = false
+
+// checkBLSAuthor rejects the BLS key author if the BLS authors are disabled or
+// its proof of possession is invalid, the proof rules out the rogue keys
+// cancelling the other keys of an aggregated signature.
+func checkBLSAuthor(author *common.Author) error {
+	key, ok := author.Owner.(common.BLSPubKey)
+	if !ok {
+		return nil
+	}
+	if !blsAuthors {
+		return ErrBLSAuthorDisabled
+	}
+	if !bls.VerifyProof(key.Key[:], key.Proof[:]) {
+		return ErrBLSProofInvalid
+	}
+	return nil
+}
+
+// ValidAggregateSign checks the aggregated signature of the BLS authors at the
+// indexes, the authors sign the hash by one signature whatever their number.
+func (am *AccountManager) ValidAggregateSign(accountName common.Name, hash common.Hash, agg *types.AggregateSign, recoverRes *recoverActionResult) error {
+	if !blsAuthors {
+		return ErrBLSAuthorDisabled
+	}
+	if len(agg.Indexes) == 0 {
+		return ErrAggregateSignInvalid
+	}
+	limit, err := am.authorLimit()
+	if err != nil {
+		return err
+	}
+	keys := make([][]byte, 0, len(agg.Indexes))
+	seen := make(map[string]bool)
+	for _, index := range agg.Indexes {
+		if uint64(len(index)) > limit.signDepth {
+			return fmt.Errorf("exceed max sign depth, want most %d, actual is %d", limit.signDepth, len(index))
+		}
+		acct, idx, err := am.signAuthor(accountName, index, recoverRes)
+		if err != nil {
+			return err
+		}
+		key, ok := acct.Authors[idx].Owner.(common.BLSPubKey)
+		if !ok {
+			return fmt.Errorf("%v %v author %v is not a bls key", acct.AcctName, ErrAggregateSignInvalid, acct.Authors[idx].Owner.String())
+		}
+		// a key counted twice would be signed by a multiple of its signature
+		if seen[key.String()] {
+			return fmt.Errorf("%v %v author %v signs twice", acct.AcctName, ErrAggregateSignInvalid, key.String())
+		}
+		seen[key.String()] = true
+		keys = append(keys, key.Key[:])
+		recoverRes.addAuthorWeight(acct, idx)
+	}
+	if !bls.VerifyAggregate(keys, hash[:], agg.Sig) {
+		return ErrAggregateSignInvalid
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto/bls"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_RecoverTxBLSAuthor(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	userPub, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name("blsuser"), common.Name(""), 0, 0, userPub, ""); err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]*bls.SecretKey, 3)
+	authors := make([]*common.Author, 3)
+	for i := range keys {
+		sk, err := bls.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = sk
+		authors[i] = common.NewAuthor(common.NewBLSPubKey(sk.PublicKey(), bls.Prove(sk)), 1)
+	}
+	addAuthors := func(authors ...*common.Author) *AccountAuthorAction {
+		acts := make([]*AuthorAction, 0, len(authors))
+		for _, author := range authors {
+			acts = append(acts, &AuthorAction{ActionType: AddAuthor, Author: author})
+		}
+		return &AccountAuthorAction{Threshold: 2, AuthorActions: acts}
+	}
+
+	defer func(enabled bool) { blsAuthors = enabled }(blsAuthors)
+	blsAuthors = false
+	if err := am.UpdateAccountAuthor(common.Name("blsuser"), addAuthors(authors[0])); err != ErrBLSAuthorDisabled {
+		t.Fatalf("disabled bls author err %v", err)
+	}
+	blsAuthors = true
+	// the proof of another key does not prove the possession
+	forged := common.NewAuthor(common.NewBLSPubKey(keys[0].PublicKey(), bls.Prove(keys[1])), 1)
+	if err := am.UpdateAccountAuthor(common.Name("blsuser"), addAuthors(forged)); err != ErrBLSProofInvalid {
+		t.Fatalf("forged proof err %v", err)
+	}
+	if err := am.UpdateAccountAuthor(common.Name("blsuser"), addAuthors(authors...)); err != nil {
+		t.Fatal(err)
+	}
+	// the update of the author is checked too
+	update := &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: UpdateAuthor, Author: forged}}}
+	if err := am.UpdateAccountAuthor(common.Name("blsuser"), update); err != ErrBLSProofInvalid {
+		t.Fatalf("forged proof update err %v", err)
+	}
+
+	signer := types.NewSigner(big.NewInt(1))
+	newTx := func(signers ...int) *types.Transaction {
+		action := types.NewAction(types.Transfer, common.Name("blsuser"), common.Name("escrowrecipient"), 0, 0, 0, big.NewInt(0), nil, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		pairs := make([]*types.BLSKeyPair, 0, len(signers))
+		for _, i := range signers {
+			pairs = append(pairs, types.MakeBLSKeyPair(keys[i], []uint64{uint64(i + 1)}))
+		}
+		if err := types.SignActionWithBLSKeys(action, tx, signer, 0, pairs); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	if err := am.RecoverTx(signer, newTx(0, 2)); err != nil {
+		t.Fatalf("aggregate sign err %v", err)
+	}
+	if err := am.RecoverTx(signer, newTx(1)); err == nil {
+		t.Fatal("aggregate sign below threshold accepted")
+	}
+
+	// the signature does not match the claimed authors
+	tx := newTx(0, 1)
+	agg := tx.GetActions()[0].GetAggregateSign()
+	agg.Indexes[1] = []uint64{3}
+	if err := am.RecoverTx(signer, tx); err != ErrAggregateSignInvalid {
+		t.Fatalf("mismatched aggregate sign err %v", err)
+	}
+	// an author counted twice does not add weight
+	tx = newTx(0, 0)
+	if err := am.RecoverTx(signer, tx); err == nil {
+		t.Fatal("duplicate aggregate author accepted")
+	}
+
+	blsAuthors = false
+	if err := am.RecoverTx(signer, newTx(0, 2)); err != ErrBLSAuthorDisabled {
+		t.Fatalf("disabled aggregate sign err %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_WrappedAsset(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	pubkey, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name(params.DefaultChainconfig.AssetName), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	owner, bridge, holder := common.Name("escrowsender"), common.Name("escrowrecipient"), common.Name("fractal.account")
+	issue := IssueAsset{AssetName: "escrowcoin.weth", Symbol: "weth", Amount: big.NewInt(0), Owner: owner, UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(owner, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	register := &RegisterWrappedAsset{AssetID: assetID, OriginChain: "ethereum", OriginContract: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", Bridge: bridge}
+	if err := processAssetAction(am, types.RegisterWrappedAsset, bridge, 0, big.NewInt(0), register); err != asset.ErrOwnerMismatch {
+		t.Fatalf("register by non owner err %v", err)
+	}
+	if err := processAssetAction(am, types.RegisterWrappedAsset, owner, 0, big.NewInt(0), register); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.RegisterWrappedAsset, owner, 0, big.NewInt(0), register); err != asset.ErrWrappedAssetIsExist {
+		t.Fatalf("register again err %v", err)
+	}
+	if wrapped, err := am.GetWrappedAsset(assetID); err != nil || wrapped.Bridge != bridge || wrapped.OriginChain != "ethereum" {
+		t.Fatalf("wrapped %v, %v", wrapped, err)
+	}
+
+	// only the bridge mints, each proof once
+	mint := func(from common.Name, proof string) error {
+		return processAssetAction(am, types.BridgeMint, from, 0, big.NewInt(0), &BridgeMint{AssetID: assetID, To: holder, Amount: big.NewInt(10), Proof: []byte(proof)})
+	}
+	if err := mint(owner, "deposit1"); err != asset.ErrBridgeMismatch {
+		t.Fatalf("mint by non bridge err %v", err)
+	}
+	if err := mint(bridge, "deposit1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mint(bridge, "deposit1"); err != asset.ErrBridgeProofUsed {
+		t.Fatalf("mint with used proof err %v", err)
+	}
+	if used, err := am.IsBridgeProofUsed(assetID, []byte("deposit1")); err != nil || !used {
+		t.Fatalf("proof used %v, %v", used, err)
+	}
+	if balance, err := am.GetAccountBalanceByID(holder, assetID, 0); err != nil || balance.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("holder balance %v, %v", balance, err)
+	}
+	if err := processAssetAction(am, types.IncreaseAsset, owner, 0, big.NewInt(0), &IncAsset{AssetId: assetID, Amount: big.NewInt(10), To: owner}); err != ErrWrappedAssetSupply {
+		t.Fatalf("increase wrapped asset err %v", err)
+	}
+
+	// the holder returns the asset to the bridge which burns it
+	if err := am.TransferAsset(holder, bridge, assetID, big.NewInt(4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.BurnAsset, holder, 0, big.NewInt(0), &BurnAsset{AssetID: assetID, Amount: big.NewInt(1)}); err != ErrWrappedAssetSupply {
+		t.Fatalf("holder burn wrapped asset err %v", err)
+	}
+	if err := processAssetAction(am, types.BridgeBurn, bridge, 0, big.NewInt(0), &BridgeBurn{AssetID: assetID, Amount: big.NewInt(4), Proof: []byte("withdraw1")}); err != nil {
+		t.Fatal(err)
+	}
+	if ao, err := am.GetAssetInfoByID(assetID); err != nil || ao.GetAssetAmount().Cmp(big.NewInt(6)) != 0 {
+		t.Fatalf("asset supply %v, %v", ao, err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_BurnAsset(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	if err := am.ast.IncreaseAsset(sender, assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(sender, recipient, assetID, big.NewInt(40)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := processAssetAction(am, types.BurnAsset, recipient, 0, big.NewInt(0), &BurnAsset{AssetID: assetID, Amount: big.NewInt(50)}); err == nil {
+		t.Fatal("burn over the balance should fail")
+	}
+	if err := processAssetAction(am, types.BurnAsset, recipient, 0, big.NewInt(0), &BurnAsset{AssetID: assetID, Amount: big.NewInt(15)}); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, assetID, 0); balance.Int64() != 25 {
+		t.Fatalf("holder balance %v, want 25", balance)
+	}
+	if ast, _ := am.GetAssetInfoByID(assetID); ast.GetAssetAmount().Int64() != 85 {
+		t.Fatalf("asset amount %v, want 85", ast.GetAssetAmount())
+	}
+
+	disable := &SetAssetBurnable{AssetID: assetID, Burnable: false}
+	if err := processAssetAction(am, types.SetAssetBurnable, recipient, 0, big.NewInt(0), disable); err == nil {
+		t.Fatal("set burnable by non owner should fail")
+	}
+	if err := processAssetAction(am, types.SetAssetBurnable, sender, 0, big.NewInt(0), disable); err != nil {
+		t.Fatal(err)
+	}
+	if burnable, err := am.IsAssetBurnable(assetID); err != nil || burnable {
+		t.Fatalf("IsAssetBurnable = %v, %v", burnable, err)
+	}
+	if err := processAssetAction(am, types.BurnAsset, recipient, 0, big.NewInt(0), &BurnAsset{AssetID: assetID, Amount: big.NewInt(5)}); err != ErrAssetNotBurnable {
+		t.Fatalf("burn not burnable asset err %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/metrics"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+var (
+	sharedCacheHitMeter  = metrics.NewRegisteredMeter("accountmanager/cache/hit", nil)
+	sharedCacheMissMeter = metrics.NewRegisteredMeter("accountmanager/cache/miss", nil)
+
+	sharedCache atomic.Value // *lru.Cache of *accountCacheEntry by account id
+)
+
+// accountCacheEntry decoded account with the encoded bytes it decoded from,
+// the entry is never modified once cached.
+type accountCacheEntry struct {
+	raw  []byte
+	acct *Account
+}
+
+// SetSharedCacheSize keeps the last size decoded accounts for all the account
+// managers, the accounts touched by every block are then decoded once rather
+// than once per account manager. 0 disables the shared cache.
+func SetSharedCacheSize(size int) {
+	var cache *lru.Cache
+	if size > 0 {
+		cache, _ = lru.New(size)
+	}
+	sharedCache.Store(cache)
+}
+
+func getSharedCache() *lru.Cache {
+	cache, _ := sharedCache.Load().(*lru.Cache)
+	return cache
+}
+
+// getCachedAccount returns a copy of the cached account if the encoded bytes
+// still match the state, so state reverts never return a stale account.
+func (am *AccountManager) getCachedAccount(id uint64, raw []byte) *Account {
+	if entry, ok := am.cache[id]; ok && bytes.Equal(entry.raw, raw) {
+		return entry.acct.Copy()
+	}
+	cache := getSharedCache()
+	if cache == nil {
+		return nil
+	}
+	if v, ok := cache.Get(id); ok {
+		if entry := v.(*accountCacheEntry); bytes.Equal(entry.raw, raw) {
+			sharedCacheHitMeter.Mark(1)
+			am.setCacheEntry(id, entry)
+			return entry.acct.Copy()
+		}
+	}
+	sharedCacheMissMeter.Mark(1)
+	return nil
+}
+
+func (am *AccountManager) setCachedAccount(id uint64, raw []byte, acct *Account) {
+	entry := &accountCacheEntry{raw: raw, acct: acct.Copy()}
+	am.setCacheEntry(id, entry)
+	if cache := getSharedCache(); cache != nil {
+		cache.Add(id, entry)
+	}
+}
+
+func (am *AccountManager) setCacheEntry(id uint64, entry *accountCacheEntry) {
+	if am.cache == nil {
+		am.cache = make(map[uint64]*accountCacheEntry)
+	}
+	am.cache[id] = entry
+}
+
+func (am *AccountManager) deleteCachedAccount(id uint64) {
+	delete(am.cache, id)
+}
+
+func (am *AccountManager) resetCache() {
+	am.cache = make(map[uint64]*accountCacheEntry)
+	am.authorCache = nil
+}
+
+// getAuthorAccount returns the account the signatures are checked against, it
+// is read once per author version while the account manager is shared by the
+// block and must not be modified.
+func (am *AccountManager) getAuthorAccount(name common.Name) (*Account, error) {
+	if acct, ok := am.authorCache[name]; ok {
+		return acct, nil
+	}
+	acct, err := am.getAccountMetaByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, ErrAccountNotExist
+	}
+	if acct.IsDestroyed() {
+		return nil, ErrAccountIsDestroy
+	}
+	if am.authorCache == nil {
+		am.authorCache = make(map[common.Name]*Account)
+	}
+	am.authorCache[name] = acct
+	return acct, nil
+}
+
+// dropAuthorAccount drops the cached authors once the stored account changes
+// its author version or is destroyed.
+func (am *AccountManager) dropAuthorAccount(acct *Account) {
+	if cached, ok := am.authorCache[acct.GetName()]; ok && (cached.AuthorVersion != acct.AuthorVersion || acct.IsDestroyed()) {
+		delete(am.authorCache, acct.GetName())
+	}
+}
+
+// ResetAuthorCache drops the cached authors, it is called when the state
+// changes are reverted outside of the account manager.
+func (am *AccountManager) ResetAuthorCache() {
+	am.authorCache = nil
+}
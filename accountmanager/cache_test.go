@@ -0,0 +1,147 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_AuthorCache(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	oldPub, oldPriv := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name("cacheuser"), common.Name(""), 0, 0, oldPub, ""); err != nil {
+		t.Fatal(err)
+	}
+	newPub, newPriv := GeneragePubKey()
+
+	signer := types.NewSigner(big.NewInt(1))
+	newTx := func(keys ...*types.KeyPair) *types.Transaction {
+		action := types.NewAction(types.Transfer, common.Name("cacheuser"), common.Name("escrowrecipient"), 0, 0, 0, big.NewInt(0), nil, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		if err := types.SignActionWithMultiKey(action, tx, signer, 0, keys); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	if err := am.RecoverTx(signer, newTx(types.MakeKeyPair(oldPriv, []uint64{0}))); err != nil {
+		t.Fatal(err)
+	}
+	cached := am.authorCache[common.Name("cacheuser")]
+	if cached == nil {
+		t.Fatal("authors not cached")
+	}
+	if err := am.RecoverTx(signer, newTx(types.MakeKeyPair(oldPriv, []uint64{0}))); err != nil {
+		t.Fatal(err)
+	}
+	if am.authorCache[common.Name("cacheuser")] != cached {
+		t.Fatal("cached authors read again")
+	}
+
+	// the account changes of other fields keep the cached authors
+	if err := am.AddAccountBalanceByID(common.Name("cacheuser"), 0, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if am.authorCache[common.Name("cacheuser")] != cached {
+		t.Fatal("cached authors dropped by the balance change")
+	}
+
+	replace := &AccountAuthorAction{AuthorActions: []*AuthorAction{
+		{ActionType: AddAuthor, Author: common.NewAuthor(newPub, 1)},
+		{ActionType: DeleteAuthor, Author: common.NewAuthor(oldPub, 1)},
+	}}
+	if err := am.UpdateAccountAuthor(common.Name("cacheuser"), replace); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := am.authorCache[common.Name("cacheuser")]; ok {
+		t.Fatal("cached authors kept after the author update")
+	}
+	if err := am.RecoverTx(signer, newTx(types.MakeKeyPair(oldPriv, []uint64{0}))); err == nil {
+		t.Fatal("removed author signature accepted")
+	}
+	if err := am.RecoverTx(signer, newTx(types.MakeKeyPair(newPriv, []uint64{0}))); err != nil {
+		t.Fatal(err)
+	}
+
+	am.ResetAuthorCache()
+	if len(am.authorCache) != 0 {
+		t.Fatal("author cache not reset")
+	}
+}
+
+func TestAccountManager_SharedCache(t *testing.T) {
+	SetSharedCacheSize(16)
+	defer SetSharedCacheSize(0)
+
+	am, assetID := newEscrowTestManager(t)
+	acct, err := am.GetAccountByName(common.Name("escrowsender"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := acct.GetAccountID()
+
+	// another account manager of the block reuses the decoded account
+	other, err := NewAccountManager(am.sdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.GetAccountByName(common.Name("escrowsender")); err != nil {
+		t.Fatal(err)
+	}
+	shared, _ := getSharedCache().Get(id)
+	if other.cache[id] != shared {
+		t.Fatal("decoded account not shared")
+	}
+
+	// the accounts returned are copies, a change is seen once stored only
+	acct.SetNonce(7)
+	if got, _ := other.GetAccountByName(common.Name("escrowsender")); got.GetNonce() == 7 {
+		t.Fatal("cached account modified by the caller")
+	}
+	if err := other.SetAccount(acct); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := NewAccountManager(am.sdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fresh.GetAccountByName(common.Name("escrowsender"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetNonce() != 7 {
+		t.Fatalf("nonce %d, want 7", got.GetNonce())
+	}
+	if balance, _ := got.GetBalanceByID(assetID); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("balance %v, want 100", balance)
+	}
+
+	// an entry is not used once the state holds other bytes
+	snap := am.sdb.Snapshot()
+	acct.SetNonce(8)
+	if err := other.SetAccount(acct); err != nil {
+		t.Fatal(err)
+	}
+	am.sdb.RevertToSnapshot(snap)
+	if got, _ := fresh.GetAccountByName(common.Name("escrowsender")); got.GetNonce() != 7 {
+		t.Fatalf("nonce %d after revert, want 7", got.GetNonce())
+	}
+}
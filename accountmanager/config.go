@@ -24,6 +24,12 @@ type Config struct {
 	MainAccountNameMaxLength uint64 `json:"mainAccountNameMaxLength"`
 	SubAccountNameMinLength  uint64 `json:"subAccountNameMinLength"`
 	SubAccountNameMaxLength  uint64 `json:"subAccountNameMaxLength"`
+	AccountNameReclaimBlocks uint64 `json:"accountNameReclaimBlocks"`
+	AccountNameReuse         bool   `json:"accountNameReuse"`
+	MaxSignDepth             uint64 `json:"maxSignDepth"`
+	MaxSignLength            uint64 `json:"maxSignLength"`
+	MaxAuthorNum             uint64 `json:"maxAuthorNum"`
+	AllowBLSAuthor           bool   `json:"allowBLSAuthor"`
 }
 
 const MaxDescriptionLength uint64 = 255
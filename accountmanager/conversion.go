@@ -0,0 +1,61 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+//GetAssetConversion get the conversion of the wrapped sub-asset
+func (am *AccountManager) GetAssetConversion(subID uint64) (*asset.AssetConversion, error) {
+	return am.ast.GetAssetConversion(subID)
+}
+
+//convertAsset burn the amount of the asset received by the asset manager, and issue the converted asset to the account
+func (am *AccountManager) convertAsset(accountManagerContext *types.AccountManagerContext, accountName common.Name, fromID uint64, fromAmount *big.Int, toID uint64, toAmount *big.Int) ([]*types.InternalAction, error) {
+	if fromAmount.Sign() <= 0 || toAmount.Sign() <= 0 {
+		return nil, ErrAmountValueInvalid
+	}
+	assetName := common.Name(accountManagerContext.ChainConfig.AssetName)
+	if err := am.SubAccountBalanceByID(assetName, fromID, fromAmount); err != nil {
+		return nil, err
+	}
+	if err := am.ast.ConvertAsset(fromID, fromAmount, toID, toAmount); err != nil {
+		return nil, err
+	}
+	if err := am.AddAccountBalanceByID(assetName, toID, toAmount); err != nil {
+		return nil, err
+	}
+	if err := am.TransferAsset(assetName, accountName, toID, toAmount); err != nil {
+		return nil, err
+	}
+
+	var internalActions []*types.InternalAction
+	for _, actionX := range []*types.Action{
+		types.NewAction(types.Transfer, assetName, common.Name(""), 0, fromID, 0, fromAmount, nil, nil),
+		types.NewAction(types.Transfer, common.Name(""), assetName, 0, toID, 0, toAmount, nil, nil),
+		types.NewAction(types.Transfer, assetName, accountName, 0, toID, 0, toAmount, nil, nil),
+	} {
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	}
+	return internalActions, nil
+}
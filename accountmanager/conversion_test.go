@@ -0,0 +1,118 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func processAssetAction(am *AccountManager, aType types.ActionType, from common.Name, assetID uint64, value *big.Int, data interface{}) error {
+	var payload []byte
+	if data != nil {
+		var err error
+		if payload, err = rlp.EncodeToBytes(data); err != nil {
+			return err
+		}
+	}
+	action := types.NewAction(aType, from, common.Name(params.DefaultChainconfig.AssetName), 0, assetID, 0, value, payload, nil)
+	_, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig})
+	return err
+}
+
+func TestAccountManager_WrapAsset(t *testing.T) {
+	am, parentID := newEscrowTestManager(t)
+	pubkey, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name(params.DefaultChainconfig.AssetName), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	sender := common.Name("escrowsender")
+	if err := am.ast.IncreaseAsset(sender, parentID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	issue := IssueAsset{AssetName: "escrowcoin.wrap", Symbol: "wesc", Amount: big.NewInt(0), Owner: sender, UpperLimit: big.NewInt(0)}
+	subID, err := am.IssueAsset(sender, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := processAssetAction(am, types.SetAssetConversion, common.Name("escrowrecipient"), 0, big.NewInt(0), &SetAssetConversion{ParentID: parentID, SubID: subID, Ratio: 10}); err == nil {
+		t.Fatal("set conversion by non owner should fail")
+	}
+	if err := processAssetAction(am, types.SetAssetConversion, sender, 0, big.NewInt(0), &SetAssetConversion{ParentID: subID, SubID: parentID, Ratio: 10}); err != asset.ErrConversionInvalid {
+		t.Fatalf("set reversed conversion err %v", err)
+	}
+	if err := processAssetAction(am, types.SetAssetConversion, sender, 0, big.NewInt(0), &SetAssetConversion{ParentID: parentID, SubID: subID, Ratio: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := processAssetAction(am, types.WrapAsset, sender, parentID, big.NewInt(30), &WrapAsset{SubID: subID}); err != nil {
+		t.Fatal(err)
+	}
+	checkBalance := func(assetID uint64, want int64) {
+		if balance, _ := am.GetAccountBalanceByID(sender, assetID, 0); balance.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("asset %d balance mismatch, got %v want %d", assetID, balance, want)
+		}
+	}
+	checkSupply := func(assetID uint64, want int64) {
+		obj, err := am.GetAssetInfoByID(assetID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj.GetAssetAmount().Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("asset %d supply mismatch, got %v want %d", assetID, obj.GetAssetAmount(), want)
+		}
+	}
+	checkBalance(parentID, 70)
+	checkBalance(subID, 300)
+	checkSupply(parentID, 70)
+	checkSupply(subID, 300)
+
+	if err := processAssetAction(am, types.UnwrapAsset, sender, subID, big.NewInt(15), nil); err != asset.ErrConversionAmount {
+		t.Fatalf("unwrap partial unit err %v", err)
+	}
+	if err := processAssetAction(am, types.SetAssetConversion, sender, 0, big.NewInt(0), &SetAssetConversion{ParentID: parentID, SubID: subID, Ratio: 5}); err != asset.ErrConversionInUse {
+		t.Fatalf("change ratio with supply err %v", err)
+	}
+	if err := processAssetAction(am, types.UnwrapAsset, sender, subID, big.NewInt(100), nil); err != nil {
+		t.Fatal(err)
+	}
+	checkBalance(parentID, 80)
+	checkBalance(subID, 200)
+	checkSupply(parentID, 80)
+	checkSupply(subID, 200)
+
+	if err := processAssetAction(am, types.SetAssetConversion, sender, 0, big.NewInt(0), &SetAssetConversion{SubID: subID, Remove: true}); err != asset.ErrConversionSupply {
+		t.Fatalf("remove conversion with supply err %v", err)
+	}
+	if err := processAssetAction(am, types.UnwrapAsset, sender, subID, big.NewInt(200), nil); err != nil {
+		t.Fatal(err)
+	}
+	checkSupply(subID, 0)
+	if err := processAssetAction(am, types.SetAssetConversion, sender, 0, big.NewInt(0), &SetAssetConversion{SubID: subID, Remove: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.ast.GetAssetConversion(subID); err != asset.ErrConversionNotExist {
+		t.Fatalf("removed conversion err %v", err)
+	}
+}
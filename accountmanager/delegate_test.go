@@ -0,0 +1,73 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_SubAssetDelegate(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	owner, delegate := common.Name("escrowsender"), common.Name("escrowrecipient")
+	issue := func(name string) error {
+		child := IssueAsset{AssetName: name, Symbol: name[strings.Index(name, ".")+1:], Amount: big.NewInt(10), Owner: delegate, UpperLimit: big.NewInt(0)}
+		_, err := am.IssueAsset(delegate, child, 0, params.ForkID1)
+		return err
+	}
+
+	if err := issue("escrowcoin.child1"); err == nil {
+		t.Fatal("issue sub-asset without delegation should fail")
+	}
+	if err := processAssetAction(am, types.SetSubAssetDelegate, delegate, 0, big.NewInt(0), &SetSubAssetDelegate{AssetID: assetID, Delegate: delegate, Quota: 2}); err == nil {
+		t.Fatal("set delegate by non owner should fail")
+	}
+	if err := processAssetAction(am, types.SetSubAssetDelegate, owner, 0, big.NewInt(0), &SetSubAssetDelegate{AssetID: assetID, Delegate: delegate, Quota: 2}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"escrowcoin.child1", "escrowcoin.child2"} {
+		if err := issue(name); err != nil {
+			t.Fatalf("issue %s err %v", name, err)
+		}
+	}
+	if err := issue("escrowcoin.child3"); err != asset.ErrSubAssetQuota {
+		t.Fatalf("issue over quota err %v", err)
+	}
+	if delegation, err := am.GetSubAssetDelegation(assetID, delegate); err != nil || delegation.Quota != 2 || delegation.Issued != 2 {
+		t.Fatalf("delegation %v, %v", delegation, err)
+	}
+
+	// raising the quota keeps the issued count
+	if err := processAssetAction(am, types.SetSubAssetDelegate, owner, 0, big.NewInt(0), &SetSubAssetDelegate{AssetID: assetID, Delegate: delegate, Quota: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := issue("escrowcoin.child3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.SetSubAssetDelegate, owner, 0, big.NewInt(0), &SetSubAssetDelegate{AssetID: assetID, Delegate: delegate, Quota: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := issue("escrowcoin.child4"); err == nil {
+		t.Fatal("issue sub-asset after revoked should fail")
+	}
+}
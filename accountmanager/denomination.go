@@ -0,0 +1,313 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// A redenomination changes the decimals of an asset. The supply is rescaled when
+// it starts, the holders are rescaled in batches at the end of the blocks, from
+// the last holder down, so a holder emptied by the rescaling is swapped out by an
+// already rescaled one. The balances of the asset can not change until all the
+// holders are rescaled. Converting to less decimals truncates the balances, the
+// truncated dust is taken from the supply when the redenomination completes.
+//
+// The balance locks of the holders are rescaled with their balances and the
+// allowances are converted when read. The amounts of the asset held in escrow,
+// time locks, invitations or distributions by the account manager, and the
+// scheduled actions of the asset, must be released first. The pending raise of
+// the upper limit is dropped, the permits signed before are invalid. The asset
+// can't be redenominated until the legacy balances are migrated at ForkID4.
+var (
+	assetRedenominationPrefix = "assetRedenomination"
+	assetRedenominatingKey    = "assetRedenominating"
+)
+
+// MaxAssetDecimals max decimals an asset can be redenominated to
+const MaxAssetDecimals uint64 = 18
+
+// MaxRedenominateHolders max holders rescaled at the end of a block
+const MaxRedenominateHolders uint64 = 200
+
+// RedenominateAssetAction the asset owner change the decimals of the asset
+type RedenominateAssetAction struct {
+	AssetID  uint64 `json:"assetId,omitempty"`
+	Decimals uint64 `json:"decimals"`
+}
+
+// Redenomination the rescaling of the holders of the asset in progress
+type Redenomination struct {
+	AssetID  uint64   `json:"assetID"`
+	Decimals uint64   `json:"decimals"` // decimals before the redenomination
+	Number   uint64   `json:"number"`
+	Cursor   uint64   `json:"cursor"` // holders left to rescale
+	Dust     *big.Int `json:"dust"`   // truncated balances in the former decimals
+}
+
+func decimalsFactor(decimals uint64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(decimals), nil)
+}
+
+//ConvertAssetAmount convert the amount between the decimals, returns the converted amount and the remainder truncated
+func ConvertAssetAmount(amount *big.Int, fromDecimals uint64, toDecimals uint64) (*big.Int, *big.Int) {
+	if toDecimals >= fromDecimals {
+		return new(big.Int).Mul(amount, decimalsFactor(toDecimals-fromDecimals)), big.NewInt(0)
+	}
+	return new(big.Int).QuoRem(amount, decimalsFactor(fromDecimals-toDecimals), new(big.Int))
+}
+
+//ParseAssetAmount parse the decimal amount of the asset, such as "1.5", to the amount of the smallest unit
+func (am *AccountManager) ParseAssetAmount(assetID uint64, amount string) (*big.Int, error) {
+	assetObj, err := am.ast.GetAssetObjectById(assetID)
+	if err != nil {
+		return nil, err
+	}
+	decimals := assetObj.GetDecimals()
+	integer, fraction := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		integer, fraction = amount[:i], amount[i+1:]
+	}
+	if integer == "" && fraction == "" || uint64(len(fraction)) > decimals {
+		return nil, ErrAssetAmountFormat
+	}
+	digits := integer + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	if strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return nil, ErrAssetAmountFormat
+	}
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, ErrAssetAmountFormat
+	}
+	return value, nil
+}
+
+//FormatAssetAmount format the amount of the smallest unit of the asset to the decimal amount
+func (am *AccountManager) FormatAssetAmount(assetID uint64, amount *big.Int) (string, error) {
+	assetObj, err := am.ast.GetAssetObjectById(assetID)
+	if err != nil {
+		return "", err
+	}
+	if amount.Sign() < 0 {
+		return "", ErrNegativeAmount
+	}
+	integer, fraction := ConvertAssetAmount(amount, assetObj.GetDecimals(), 0)
+	if assetObj.GetDecimals() == 0 || fraction.Sign() == 0 {
+		return integer.String(), nil
+	}
+	digits := fraction.String()
+	digits = strings.Repeat("0", int(assetObj.GetDecimals())-len(digits)) + digits
+	return integer.String() + "." + strings.TrimRight(digits, "0"), nil
+}
+
+//GetRedenomination get the redenomination in progress of the asset
+func (am *AccountManager) GetRedenomination(assetID uint64) (*Redenomination, error) {
+	b, err := am.stateGet(assetRedenominationPrefix + strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrRedenominationNotExist
+	}
+	var redenomination Redenomination
+	if err := rlp.DecodeBytes(b, &redenomination); err != nil {
+		return nil, err
+	}
+	return &redenomination, nil
+}
+
+func (am *AccountManager) setRedenomination(redenomination *Redenomination) error {
+	b, err := rlp.EncodeToBytes(redenomination)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, assetRedenominationPrefix+strconv.FormatUint(redenomination.AssetID, 10), b)
+	return nil
+}
+
+//isRedenominating check the holders of the asset are being rescaled
+func (am *AccountManager) isRedenominating(assetID uint64) (bool, error) {
+	b, err := am.stateGet(assetRedenominationPrefix + strconv.FormatUint(assetID, 10))
+	return len(b) != 0, err
+}
+
+func (am *AccountManager) getRedenominating() ([]uint64, error) {
+	b, err := am.stateGet(assetRedenominatingKey)
+	if err != nil {
+		return nil, err
+	}
+	var assetIDs []uint64
+	if len(b) == 0 {
+		return assetIDs, nil
+	}
+	if err := rlp.DecodeBytes(b, &assetIDs); err != nil {
+		return nil, err
+	}
+	return assetIDs, nil
+}
+
+func (am *AccountManager) setRedenominating(assetIDs []uint64) error {
+	if len(assetIDs) == 0 {
+		am.sdb.Delete(acctManagerName, assetRedenominatingKey)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(assetIDs)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, assetRedenominatingKey, b)
+	return nil
+}
+
+//RedenominateAsset change the decimals of the asset and rescale the supply, the holders are rescaled by ProcessRedenominations,
+//the asset held in escrow by the escrow account and the scheduled actions of the asset must be released first
+func (am *AccountManager) RedenominateAsset(number uint64, action *RedenominateAssetAction, escrowAccount common.Name) error {
+	if migrated, err := am.balancesMigrated(); err != nil {
+		return err
+	} else if !migrated {
+		return ErrBalancesNotMigrated
+	}
+	assetObj, err := am.ast.GetAssetObjectById(action.AssetID)
+	if err != nil {
+		return err
+	}
+	if action.Decimals == assetObj.GetDecimals() || action.Decimals > MaxAssetDecimals {
+		return ErrRedenominationInvalid
+	}
+	if isCollection, err := am.ast.IsNFTCollection(action.AssetID); err != nil {
+		return err
+	} else if isCollection {
+		return ErrRedenominationInvalid
+	}
+	if redenominating, err := am.isRedenominating(action.AssetID); err != nil {
+		return err
+	} else if redenominating {
+		return ErrAssetRedenominating
+	}
+	if acct, err := am.getAccountMetaByName(escrowAccount); err != nil {
+		return err
+	} else if acct != nil {
+		balance, err := am.accountBalance(acct, action.AssetID)
+		if err != nil && err != ErrAccountAssetNotExist {
+			return err
+		}
+		if balance.Sign() > 0 {
+			return ErrRedenominateEscrow
+		}
+	}
+	if scheduled, err := am.isAssetScheduled(action.AssetID); err != nil {
+		return err
+	} else if scheduled {
+		return ErrRedenominateScheduled
+	}
+	if err := am.ast.DropPendingUpperLimit(action.AssetID); err != nil {
+		return err
+	}
+	count, err := am.GetAssetHolderCount(action.AssetID)
+	if err != nil {
+		return err
+	}
+
+	decimals := assetObj.GetDecimals()
+	amount, _ := ConvertAssetAmount(assetObj.GetAssetAmount(), decimals, action.Decimals)
+	addIssue, _ := ConvertAssetAmount(assetObj.GetAssetAddIssue(), decimals, action.Decimals)
+	upperLimit, _ := ConvertAssetAmount(assetObj.GetUpperLimit(), decimals, action.Decimals)
+	assetObj.SetDecimals(action.Decimals)
+	assetObj.SetAssetAmount(amount)
+	assetObj.SetAssetAddIssue(addIssue)
+	assetObj.UpperLimit = upperLimit
+	if err := am.ast.SetAssetObject(assetObj); err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	assetIDs, err := am.getRedenominating()
+	if err != nil {
+		return err
+	}
+	if err := am.setRedenominating(append(assetIDs, action.AssetID)); err != nil {
+		return err
+	}
+	return am.setRedenomination(&Redenomination{
+		AssetID:  action.AssetID,
+		Decimals: decimals,
+		Number:   number,
+		Cursor:   count,
+		Dust:     big.NewInt(0),
+	})
+}
+
+//ProcessRedenominations rescale at most limit holders of the redenominating assets
+func (am *AccountManager) ProcessRedenominations(limit uint64) error {
+	assetIDs, err := am.getRedenominating()
+	if err != nil || len(assetIDs) == 0 {
+		return err
+	}
+	for len(assetIDs) > 0 && limit > 0 {
+		redenomination, err := am.GetRedenomination(assetIDs[0])
+		if err != nil {
+			return err
+		}
+		assetObj, err := am.ast.GetAssetObjectById(redenomination.AssetID)
+		if err != nil {
+			return err
+		}
+		for ; redenomination.Cursor > 0 && limit > 0; limit-- {
+			redenomination.Cursor--
+			accountID, _, err := am.getUint64(assetHolderKey(redenomination.AssetID, redenomination.Cursor))
+			if err != nil {
+				return err
+			}
+			balance, _, err := getBalance(am.stateGet, accountID, redenomination.AssetID)
+			if err != nil {
+				return err
+			}
+			amount, dust := ConvertAssetAmount(balance, redenomination.Decimals, assetObj.GetDecimals())
+			redenomination.Dust.Add(redenomination.Dust, dust)
+			if _, err := am.putAccountBalance(accountID, redenomination.AssetID, amount); err != nil {
+				return err
+			}
+			if err := am.rescaleHolderLocks(accountID, redenomination.AssetID, redenomination.Decimals, assetObj.GetDecimals()); err != nil {
+				return err
+			}
+		}
+		if redenomination.Cursor > 0 {
+			if err := am.setRedenomination(redenomination); err != nil {
+				return err
+			}
+			break
+		}
+
+		// the supply was rescaled with the dust of all the holders together
+		if dust, _ := ConvertAssetAmount(redenomination.Dust, redenomination.Decimals, assetObj.GetDecimals()); dust.Sign() > 0 {
+			assetObj.SetAssetAmount(new(big.Int).Sub(assetObj.GetAssetAmount(), dust))
+			if err := am.ast.SetAssetObject(assetObj); err != nil {
+				return err
+			}
+		}
+		am.sdb.Delete(acctManagerName, assetRedenominationPrefix+strconv.FormatUint(redenomination.AssetID, 10))
+		assetIDs = assetIDs[1:]
+	}
+	return am.setRedenominating(assetIDs)
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_RedenominateAsset(t *testing.T) {
+	am, sysID := newEscrowTestManager(t)
+	owner, holder := common.Name("escrowsender"), common.Name("escrowrecipient")
+	issue := IssueAsset{AssetName: "redenomcoin", Symbol: "rdc", Amount: big.NewInt(0), Owner: owner, UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(owner, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.ast.IncreaseAsset(owner, assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(owner, assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(35)); err != nil {
+		t.Fatal(err)
+	}
+	balance := func(name common.Name) int64 {
+		b, err := am.GetAccountBalanceByID(name, assetID, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.Int64()
+	}
+	supply := func() (int64, uint64) {
+		assetObj, err := am.GetAssetInfoByID(assetID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return assetObj.GetAssetAmount().Int64(), assetObj.GetDecimals()
+	}
+
+	// the legacy balances are migrated at the fork activation first
+	if err := processAssetAction(am, types.RedenominateAsset, owner, 0, big.NewInt(0), &RedenominateAssetAction{AssetID: assetID, Decimals: 2}); err != ErrBalancesNotMigrated {
+		t.Fatalf("redenominate before migrated err %v", err)
+	}
+	if err := am.MigrateAllAccountBalances(); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.RedenominateAsset, owner, 0, big.NewInt(0), &RedenominateAssetAction{AssetID: sysID, Decimals: 2}); err != ErrRedenominateSysAsset {
+		t.Fatalf("redenominate system asset err %v", err)
+	}
+	if err := processAssetAction(am, types.RedenominateAsset, holder, 0, big.NewInt(0), &RedenominateAssetAction{AssetID: assetID, Decimals: 2}); err == nil {
+		t.Fatal("redenominate by non owner should fail")
+	}
+	if err := am.TransferAsset(owner, common.Name(params.DefaultChainconfig.AccountName), assetID, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.RedenominateAsset, owner, 0, big.NewInt(0), &RedenominateAssetAction{AssetID: assetID, Decimals: 2}); err != ErrRedenominateEscrow {
+		t.Fatalf("redenominate asset in escrow err %v", err)
+	}
+	if err := am.TransferAsset(common.Name(params.DefaultChainconfig.AccountName), owner, assetID, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the scheduled actions of the asset are released first
+	cfg := params.DefaultChainconfig.Copy()
+	cfg.ScheduleCfg = &params.Scheduler{BlockBudget: 1, MaxDelay: 100}
+	scheduled, err := am.Schedule(owner, 0, cfg, &ScheduleAction{Number: 5, Action: &types.SubAction{Type: types.Transfer, To: holder, AssetID: assetID, Amount: big.NewInt(1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.RedenominateAsset, owner, 0, big.NewInt(0), &RedenominateAssetAction{AssetID: assetID, Decimals: 2}); err != ErrRedenominateScheduled {
+		t.Fatalf("redenominate scheduled asset err %v", err)
+	}
+	if _, err := am.CancelScheduled(owner, &CancelScheduledAction{ScheduleID: scheduled.ScheduleID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the allowances and the balance locks are rescaled with the balances
+	if err := am.ApproveAsset(owner, &ApproveAssetAction{Spender: holder, AssetID: assetID, Amount: big.NewInt(10)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.LockHolderBalance(0, &LockHolderBalanceAction{AssetID: assetID, Holder: holder, Amount: big.NewInt(20), UnlockNumber: 10}); err != nil {
+		t.Fatal(err)
+	}
+	stored := func() (int64, int64) {
+		allowance, err := am.GetAllowance(owner, holder, assetID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locks, err := am.GetHolderLocks(holder)
+		if err != nil || len(locks) != 1 {
+			t.Fatalf("holder locks %v, %v", locks, err)
+		}
+		return allowance.Int64(), locks[0].Amount.Int64()
+	}
+
+	// more decimals multiply the balances
+	if err := processAssetAction(am, types.RedenominateAsset, owner, 0, big.NewInt(0), &RedenominateAssetAction{AssetID: assetID, Decimals: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if amount, decimals := supply(); amount != 10000 || decimals != 2 {
+		t.Fatalf("supply %v decimals %v after redenominated", amount, decimals)
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(1)); err != ErrAssetRedenominating {
+		t.Fatalf("transfer while redenominating err %v", err)
+	}
+	if err := am.ProcessRedenominations(1); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := am.GetRedenomination(assetID); err != nil || r.Cursor != 1 || r.Decimals != 0 {
+		t.Fatalf("redenomination %v, %v", r, err)
+	}
+	if err := am.ProcessRedenominations(MaxRedenominateHolders); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.GetRedenomination(assetID); err != ErrRedenominationNotExist {
+		t.Fatalf("redenomination after processed err %v", err)
+	}
+	if balance(owner) != 6500 || balance(holder) != 3500 {
+		t.Fatalf("balances %v, %v", balance(owner), balance(holder))
+	}
+	if allowance, locked := stored(); allowance != 1000 || locked != 2000 {
+		t.Fatalf("allowance %v, locked %v", allowance, locked)
+	}
+
+	// less decimals truncate the balances, the dust is taken from the supply
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(55)); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.RedenominateAsset, owner, 0, big.NewInt(0), &RedenominateAssetAction{AssetID: assetID, Decimals: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.ProcessRedenominations(MaxRedenominateHolders); err != nil {
+		t.Fatal(err)
+	}
+	if balance(owner) != 644 || balance(holder) != 355 {
+		t.Fatalf("balances %v, %v", balance(owner), balance(holder))
+	}
+	if amount, _ := supply(); amount != 999 {
+		t.Fatalf("supply %v, want 999", amount)
+	}
+	if allowance, locked := stored(); allowance != 100 || locked != 200 {
+		t.Fatalf("allowance %v, locked %v", allowance, locked)
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccountManager_AssetAmountFormat(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	if err := am.MigrateAllAccountBalances(); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.RedenominateAsset(0, &RedenominateAssetAction{AssetID: assetID, Decimals: 4}, common.Name("")); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		text   string
+		amount int64
+		format string
+	}{
+		{"1.5", 15000, "1.5"},
+		{"12", 120000, "12"},
+		{".0001", 1, "0.0001"},
+		{"3.1000", 31000, "3.1"},
+	} {
+		amount, err := am.ParseAssetAmount(assetID, tt.text)
+		if err != nil || amount.Int64() != tt.amount {
+			t.Errorf("parse %q got %v, %v", tt.text, amount, err)
+			continue
+		}
+		if format, err := am.FormatAssetAmount(assetID, amount); err != nil || format != tt.format {
+			t.Errorf("format %v got %q, %v", amount, format, err)
+		}
+	}
+	for _, text := range []string{"", ".", "1.00001", "-1", "1e3", "1.2.3"} {
+		if _, err := am.ParseAssetAmount(assetID, text); err != ErrAssetAmountFormat {
+			t.Errorf("parse %q err %v", text, err)
+		}
+	}
+}
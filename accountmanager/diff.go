@@ -0,0 +1,122 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// SnapshotDiff the changes of the state between two snapshots, the accounts
+// and the assets of the changed state entries are resolved by the later one.
+type SnapshotDiff struct {
+	Accounts      []common.Name           `json:"accounts"` // the account records modified or created
+	Balances      []*BalanceChange        `json:"balances"`
+	AssetsCreated []uint64                `json:"assetsCreated"`
+	Changes       []*snapshot.StateChange `json:"changes"`
+}
+
+// BalanceChange the balance of the asset of the account changed
+type BalanceChange struct {
+	Account common.Name `json:"account"`
+	AssetID uint64      `json:"assetID"`
+}
+
+// parseBalanceKey get the account id and the asset id of the balance key
+func parseBalanceKey(key string) (uint64, uint64, bool) {
+	ids := strings.Split(strings.TrimPrefix(key, acctBalancePrefix), "_")
+	if !strings.HasPrefix(key, acctBalancePrefix) || len(ids) != 2 {
+		return 0, 0, false
+	}
+	accountID, err1 := strconv.ParseUint(ids[0], 10, 64)
+	assetID, err2 := strconv.ParseUint(ids[1], 10, 64)
+	return accountID, assetID, err1 == nil && err2 == nil
+}
+
+//GetSnapshotDiff get the state changed from the snapshot of time1 to the snapshot of time2
+func (am *AccountManager) GetSnapshotDiff(time1, time2 uint64) (*SnapshotDiff, error) {
+	snapshotManager := snapshot.NewSnapshotManager(am.sdb)
+	changes, err := snapshotManager.Diff(time1, time2)
+	if err != nil {
+		return nil, err
+	}
+	state1, err := snapshotManager.GetSnapshotState(time1)
+	if err != nil {
+		return nil, err
+	}
+	state2, err := snapshotManager.GetSnapshotState(time2)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint64]common.Name)
+	accountName := func(accountID uint64) (common.Name, error) {
+		if name, ok := names[accountID]; ok {
+			return name, nil
+		}
+		b, err := state2.Get(acctManagerName, acctInfoPrefix+strconv.FormatUint(accountID, 10))
+		if err != nil {
+			return "", err
+		}
+		if len(b) == 0 {
+			return "", ErrAccountNotExist
+		}
+		var acct Account
+		if err := rlp.DecodeBytes(b, &acct); err != nil {
+			return "", err
+		}
+		names[accountID] = acct.GetName()
+		return acct.GetName(), nil
+	}
+
+	diff := &SnapshotDiff{Changes: changes}
+	for _, change := range changes {
+		if change.Storage {
+			continue
+		}
+		if change.Account == acctManagerName {
+			if strings.HasPrefix(change.Key, acctInfoPrefix) && !change.Deleted {
+				accountID, err := strconv.ParseUint(strings.TrimPrefix(change.Key, acctInfoPrefix), 10, 64)
+				if err != nil {
+					continue
+				}
+				name, err := accountName(accountID)
+				if err != nil {
+					return nil, err
+				}
+				diff.Accounts = append(diff.Accounts, name)
+			} else if accountID, assetID, ok := parseBalanceKey(change.Key); ok {
+				// a balance deleted is emptied
+				name, err := accountName(accountID)
+				if err != nil {
+					return nil, err
+				}
+				diff.Balances = append(diff.Balances, &BalanceChange{Account: name, AssetID: assetID})
+			}
+		} else if assetID, ok := asset.AssetObjectKeyID(change.Account, change.Key); ok && !change.Deleted {
+			if _, err := asset.NewAsset(state1).GetAssetObjectById(assetID); err == asset.ErrAssetNotExist {
+				diff.AssetsCreated = append(diff.AssetsCreated, assetID)
+			}
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestAccountManager_SnapshotDiff(t *testing.T) {
+	db := memdb.NewMemDatabase()
+	cachedb := state.NewDatabase(db)
+	commit := func(statedb *state.StateDB) common.Hash {
+		batch := db.NewBatch()
+		root, err := statedb.Commit(batch, common.Hash{}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cachedb.TrieDB().Commit(root, false); err != nil {
+			t.Fatal(err)
+		}
+		batch.Write()
+		return root
+	}
+	pubkey, _ := GeneragePubKey()
+
+	statedb, _ := state.New(common.Hash{}, cachedb)
+	setForkID(statedb, params.ForkID4)
+	am, _ := NewAccountManager(statedb)
+	for _, name := range []string{"fractal", "diffaccount"} {
+		if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root1 := commit(statedb)
+
+	statedb, _ = state.New(root1, cachedb)
+	am, _ = NewAccountManager(statedb)
+	if err := am.CreateAccount(common.Name("fractal"), common.Name("diffcreated"), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(common.Name("diffaccount"), 1, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	root2 := commit(statedb)
+
+	statedb, _ = state.New(root2, cachedb)
+	snapshotManager := snapshot.NewSnapshotManager(statedb)
+	for i, root := range []common.Hash{root1, root2} {
+		block := types.SnapshotBlock{Number: uint64(i + 1), BlockHash: common.Hash{byte(i)}}
+		rawdb.WriteSnapshot(db, block, types.SnapshotInfo{Root: root})
+		if err := snapshotManager.SetSnapshot(uint64(i+1)*1000, snapshot.BlockInfo{Number: block.Number, BlockHash: block.BlockHash}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	am, _ = NewAccountManager(statedb)
+	diff, err := am.GetSnapshotDiff(1000, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the balance is stored apart from the account record
+	if !reflect.DeepEqual(diff.Accounts, []common.Name{"diffcreated"}) {
+		t.Fatalf("accounts changed %v", diff.Accounts)
+	}
+	if len(diff.Balances) != 1 || diff.Balances[0].Account != "diffaccount" || diff.Balances[0].AssetID != 1 {
+		t.Fatalf("balances changed %v", diff.Balances)
+	}
+	if len(diff.AssetsCreated) != 0 {
+		t.Fatalf("assets created %v", diff.AssetsCreated)
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	distributionPrefix        = "distribution"
+	distributionCounterPrefix = "distributionCounter"
+	distributionQueuePrefix   = "distributionQueue"
+)
+
+// MaxDistributionAccounts max holders visited by the distributions of a block
+const MaxDistributionAccounts = 256
+
+// DistributeAssetAction distribute the action value to the holders of the asset pro rata
+// to their balances at the snapshot time.
+type DistributeAssetAction struct {
+	HolderAssetID uint64 `json:"holderAssetID"`
+	SnapshotTime  uint64 `json:"snapshotTime"`
+}
+
+// Distribution the pending distribution, the holders indexed at the snapshot are paid in
+// the index order across the blocks. The undistributed amount is refunded to the sender
+// when finished.
+type Distribution struct {
+	DistributionID uint64      `json:"distributionID"`
+	Sender         common.Name `json:"sender"`
+	HolderAssetID  uint64      `json:"holderAssetID"`
+	SnapshotTime   uint64      `json:"snapshotTime"`
+	Supply         *big.Int    `json:"supply"`
+	AssetID        uint64      `json:"assetID"`
+	Amount         *big.Int    `json:"amount"`
+	Distributed    *big.Int    `json:"distributed"`
+	NextHolder     uint64      `json:"nextHolder"`
+	Holders        uint64      `json:"holders"`
+	Number         uint64      `json:"number"`
+}
+
+//GetDistributionByID get the pending distribution
+func (am *AccountManager) GetDistributionByID(id uint64) (*Distribution, error) {
+	b, err := am.sdb.Get(acctManagerName, distributionPrefix+strconv.FormatUint(id, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrDistributionNotExist
+	}
+	var dist Distribution
+	if err := rlp.DecodeBytes(b, &dist); err != nil {
+		return nil, err
+	}
+	return &dist, nil
+}
+
+func (am *AccountManager) setDistribution(dist *Distribution) error {
+	b, err := rlp.EncodeToBytes(dist)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, distributionPrefix+strconv.FormatUint(dist.DistributionID, 10), b)
+	return nil
+}
+
+//getDistributionQueue get the ids of the pending distributions in the order they are paid next
+func (am *AccountManager) getDistributionQueue() ([]uint64, error) {
+	b, err := am.sdb.Get(acctManagerName, distributionQueuePrefix)
+	if err != nil {
+		return nil, err
+	}
+	var queue []uint64
+	if len(b) == 0 {
+		return queue, nil
+	}
+	if err := rlp.DecodeBytes(b, &queue); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+func (am *AccountManager) setDistributionQueue(queue []uint64) error {
+	if len(queue) == 0 {
+		am.sdb.Delete(acctManagerName, distributionQueuePrefix)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(queue)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, distributionQueuePrefix, b)
+	return nil
+}
+
+//DistributeAsset queue the distribution of the value already transferred to the account manager
+func (am *AccountManager) DistributeAsset(fromName common.Name, assetID uint64, amount *big.Int, number uint64, action *DistributeAssetAction) (uint64, error) {
+	if err := am.ast.CheckOwner(fromName, action.HolderAssetID); err != nil {
+		return 0, err
+	}
+	snapshotState, err := snapshot.NewSnapshotManager(am.sdb).GetSnapshotState(action.SnapshotTime)
+	if err != nil {
+		return 0, err
+	}
+	get := func(key string) ([]byte, error) {
+		return snapshotState.Get(acctManagerName, key)
+	}
+	// the holder index is complete once the legacy balances are migrated
+	if _, migrated, err := getUint64(get, acctBalancesMigratedKey); err != nil {
+		return 0, err
+	} else if !migrated {
+		return 0, ErrDistributionSnapshot
+	}
+	holders, _, err := getUint64(get, assetHolderCountPrefix+strconv.FormatUint(action.HolderAssetID, 10))
+	if err != nil {
+		return 0, err
+	}
+	supply, err := am.ast.GetAssetAmountByTime(action.HolderAssetID, action.SnapshotTime)
+	if err != nil {
+		return 0, err
+	}
+	return am.newDistribution(fromName, assetID, amount, number, action, supply, holders)
+}
+
+func (am *AccountManager) newDistribution(fromName common.Name, assetID uint64, amount *big.Int, number uint64, action *DistributeAssetAction, supply *big.Int, holders uint64) (uint64, error) {
+	if amount.Sign() <= 0 {
+		return 0, ErrAmountValueInvalid
+	}
+	if supply.Sign() <= 0 {
+		return 0, ErrDistributionSupply
+	}
+
+	var counter uint64
+	if b, err := am.sdb.Get(acctManagerName, distributionCounterPrefix); err != nil {
+		return 0, err
+	} else if len(b) != 0 {
+		if err := rlp.DecodeBytes(b, &counter); err != nil {
+			return 0, err
+		}
+	}
+	counter = counter + 1
+
+	dist := &Distribution{
+		DistributionID: counter,
+		Sender:         fromName,
+		HolderAssetID:  action.HolderAssetID,
+		SnapshotTime:   action.SnapshotTime,
+		Supply:         new(big.Int).Set(supply),
+		AssetID:        assetID,
+		Amount:         new(big.Int).Set(amount),
+		Distributed:    big.NewInt(0),
+		NextHolder:     0,
+		Holders:        holders,
+		Number:         number,
+	}
+	if err := am.setDistribution(dist); err != nil {
+		return 0, err
+	}
+	queue, err := am.getDistributionQueue()
+	if err != nil {
+		return 0, err
+	}
+	if err := am.setDistributionQueue(append(queue, counter)); err != nil {
+		return 0, err
+	}
+	b, err := rlp.EncodeToBytes(&counter)
+	if err != nil {
+		return 0, err
+	}
+	am.sdb.Put(acctManagerName, distributionCounterPrefix, b)
+	return counter, nil
+}
+
+//snapshotHolding get the balance of the account at the snapshot, nil if the account did not exist
+func snapshotHolding(get stateGetter, accountID uint64, assetID uint64) (*Account, *big.Int, error) {
+	b, err := get(acctInfoPrefix + strconv.FormatUint(accountID, 10))
+	if err != nil || len(b) == 0 {
+		return nil, nil, err
+	}
+	var acct Account
+	if err := rlp.DecodeBytes(b, &acct); err != nil {
+		return nil, nil, err
+	}
+	if isLegacyAccount(&acct) {
+		balance, err := acct.GetBalanceByID(assetID)
+		if err == ErrAccountAssetNotExist {
+			err = nil
+		}
+		return &acct, balance, err
+	}
+	balance, _, err := getBalance(get, accountID, assetID)
+	return &acct, balance, err
+}
+
+//scanSnapshotHolders visit the accounts from next to last at the snapshot, at most limit, and
+//call fn with those holding the asset until it returns false. It returns the next account to visit.
+func scanSnapshotHolders(get stateGetter, assetID uint64, next uint64, last uint64, limit uint64, fn func(acct *Account, balance *big.Int) bool) (uint64, error) {
+	for visited := uint64(0); visited < limit && next <= last; visited++ {
+		accountID := next
+		next++
+		acct, balance, err := snapshotHolding(get, accountID, assetID)
+		if err != nil {
+			return next, err
+		}
+		if acct == nil || balance.Sign() <= 0 {
+			continue
+		}
+		if !fn(acct, balance) {
+			break
+		}
+	}
+	return next, nil
+}
+
+//distributeChunk pay the next holders of the distribution, at most limit holders are
+//visited. It returns the holders visited and whether the distribution is finished.
+func (am *AccountManager) distributeChunk(pool common.Name, dist *Distribution, get stateGetter, limit uint64) (uint64, bool, error) {
+	var visited uint64
+	for ; visited < limit && dist.NextHolder < dist.Holders; visited++ {
+		accountID, _, err := getUint64(get, assetHolderKey(dist.HolderAssetID, dist.NextHolder))
+		if err != nil {
+			return visited, false, err
+		}
+		dist.NextHolder++
+		acct, balance, err := snapshotHolding(get, accountID, dist.HolderAssetID)
+		if err != nil {
+			return visited, false, err
+		}
+		if acct == nil || balance.Sign() <= 0 || acct.GetName() == pool {
+			continue
+		}
+		share := new(big.Int).Div(new(big.Int).Mul(dist.Amount, balance), dist.Supply)
+		if remain := new(big.Int).Sub(dist.Amount, dist.Distributed); share.Cmp(remain) > 0 {
+			share = remain
+		}
+		if share.Sign() == 0 {
+			continue
+		}
+		// a holder which can not receive the asset is skipped, its share is refunded
+		snap := am.sdb.Snapshot()
+		if err := am.TransferAsset(pool, acct.GetName(), dist.AssetID, share); err != nil {
+			am.sdb.RevertToSnapshot(snap)
+			am.resetCache()
+			log.Debug("Skip distribution holder", "id", dist.DistributionID, "holder", acct.GetName(), "err", err)
+			continue
+		}
+		dist.Distributed.Add(dist.Distributed, share)
+	}
+	if dist.NextHolder < dist.Holders {
+		return visited, false, am.setDistribution(dist)
+	}
+	if refund := new(big.Int).Sub(dist.Amount, dist.Distributed); refund.Sign() > 0 {
+		snap := am.sdb.Snapshot()
+		if err := am.TransferAsset(pool, dist.Sender, dist.AssetID, refund); err != nil {
+			am.sdb.RevertToSnapshot(snap)
+			am.resetCache()
+			log.Warn("Failed to refund distribution", "id", dist.DistributionID, "sender", dist.Sender, "err", err)
+		}
+	}
+	am.sdb.Delete(acctManagerName, distributionPrefix+strconv.FormatUint(dist.DistributionID, 10))
+	return visited, true, nil
+}
+
+//ProcessDistributions pay the pending distributions round robin, at most
+//MaxDistributionAccounts holders are visited by the block
+func (am *AccountManager) ProcessDistributions(config *params.ChainConfig, number uint64) error {
+	if am.readOnly {
+		return ErrAccountManagerReadOnly
+	}
+	snapshotManager := snapshot.NewSnapshotManager(am.sdb)
+	return am.processDistributions(common.Name(config.AccountName), MaxDistributionAccounts, func(dist *Distribution) (stateGetter, error) {
+		snapshotState, err := snapshotManager.GetSnapshotState(dist.SnapshotTime)
+		if err != nil {
+			return nil, err
+		}
+		return func(key string) ([]byte, error) {
+			return snapshotState.Get(acctManagerName, key)
+		}, nil
+	})
+}
+
+//processDistributions share the budget between the pending distributions, the unfinished
+//ones are moved behind those not reached by the block
+func (am *AccountManager) processDistributions(pool common.Name, budget uint64, snapshotGetter func(dist *Distribution) (stateGetter, error)) error {
+	queue, err := am.getDistributionQueue()
+	if err != nil || len(queue) == 0 {
+		return err
+	}
+	share := budget / uint64(len(queue))
+	if share == 0 {
+		share = 1
+	}
+	var processed []uint64
+	for len(queue) > 0 && budget > 0 {
+		dist, err := am.GetDistributionByID(queue[0])
+		if err != nil {
+			return err
+		}
+		get, err := snapshotGetter(dist)
+		if err != nil {
+			return err
+		}
+		limit := share
+		if limit > budget {
+			limit = budget
+		}
+		visited, finished, err := am.distributeChunk(pool, dist, get, limit)
+		if err != nil {
+			return err
+		}
+		budget -= visited
+		if !finished {
+			processed = append(processed, queue[0])
+		}
+		queue = queue[1:]
+	}
+	return am.setDistributionQueue(append(queue, processed...))
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAccountManager_DistributeChunk(t *testing.T) {
+	am, holderID := newEscrowTestManager(t)
+	pool := common.Name("fractal.account")
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	if err := am.TransferAsset(sender, recipient, holderID, big.NewInt(30)); err != nil {
+		t.Fatal(err)
+	}
+	issue := IssueAsset{AssetName: "escrowpay", Symbol: "epay", Amount: big.NewInt(0), Owner: sender, UpperLimit: big.NewInt(0)}
+	payID, err := am.IssueAsset(sender, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(pool, payID, big.NewInt(1001)); err != nil {
+		t.Fatal(err)
+	}
+
+	action := &DistributeAssetAction{HolderAssetID: holderID, SnapshotTime: 1}
+	holders, err := am.GetAssetHolderCount(holderID)
+	if err != nil || holders != 2 {
+		t.Fatalf("holder count %v, %v", holders, err)
+	}
+	if _, err := am.newDistribution(sender, payID, big.NewInt(1001), 1, action, big.NewInt(0), holders); err != ErrDistributionSupply {
+		t.Fatalf("distribution without supply err %v", err)
+	}
+	id, err := am.newDistribution(sender, payID, big.NewInt(1001), 1, action, big.NewInt(100), holders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queue, _ := am.getDistributionQueue(); len(queue) != 1 || queue[0] != id {
+		t.Fatalf("distribution queue %v", queue)
+	}
+
+	// the holders are escrowsender and escrowrecipient in the index order
+	dist, _ := am.GetDistributionByID(id)
+	visited, finished, err := am.distributeChunk(pool, dist, am.stateGet, 1)
+	if err != nil || visited != 1 || finished {
+		t.Fatalf("first chunk = %v, %v, %v", visited, finished, err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(sender, payID, 0); balance.Int64() != 700 {
+		t.Fatalf("sender share %v, want 700", balance)
+	}
+	dist, _ = am.GetDistributionByID(id)
+	if dist.Distributed.Int64() != 700 || dist.NextHolder != 1 {
+		t.Fatalf("distribution progress %v", dist)
+	}
+
+	visited, finished, err = am.distributeChunk(pool, dist, am.stateGet, 3)
+	if err != nil || visited != 1 || !finished {
+		t.Fatalf("last chunk = %v, %v, %v", visited, finished, err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, payID, 0); balance.Int64() != 300 {
+		t.Fatalf("recipient share %v, want 300", balance)
+	}
+	// the rounding remainder is refunded to the sender
+	if balance, _ := am.GetAccountBalanceByID(sender, payID, 0); balance.Int64() != 701 {
+		t.Fatalf("sender balance %v, want 701", balance)
+	}
+	if balance, _ := am.GetAccountBalanceByID(pool, payID, 0); balance.Sign() != 0 {
+		t.Fatalf("pool balance %v, want 0", balance)
+	}
+	if _, err := am.GetDistributionByID(id); err != ErrDistributionNotExist {
+		t.Fatalf("finished distribution err %v", err)
+	}
+}
+
+func TestAccountManager_ProcessDistributionsRoundRobin(t *testing.T) {
+	am, holderID := newEscrowTestManager(t)
+	pool := common.Name("fractal.account")
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	if err := am.TransferAsset(sender, recipient, holderID, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	issue := IssueAsset{AssetName: "escrowpay", Symbol: "epay", Amount: big.NewInt(0), Owner: sender, UpperLimit: big.NewInt(0)}
+	payID, err := am.IssueAsset(sender, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(pool, payID, big.NewInt(300)); err != nil {
+		t.Fatal(err)
+	}
+	action := &DistributeAssetAction{HolderAssetID: holderID, SnapshotTime: 1}
+	var ids []uint64
+	for i := 0; i < 3; i++ {
+		id, err := am.newDistribution(sender, payID, big.NewInt(100), 1, action, big.NewInt(100), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	current := func(dist *Distribution) (stateGetter, error) { return am.stateGet, nil }
+
+	// the budget of 2 pays the first holder of the first two distributions, the third goes first next
+	if err := am.processDistributions(pool, 2, current); err != nil {
+		t.Fatal(err)
+	}
+	if queue, _ := am.getDistributionQueue(); len(queue) != 3 || queue[0] != ids[2] || queue[1] != ids[0] || queue[2] != ids[1] {
+		t.Fatalf("distribution queue %v", queue)
+	}
+	for i, want := range []uint64{1, 1, 0} {
+		dist, err := am.GetDistributionByID(ids[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dist.NextHolder != want {
+			t.Fatalf("distribution %v next holder %v, want %v", ids[i], dist.NextHolder, want)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := am.processDistributions(pool, 2, current); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if queue, _ := am.getDistributionQueue(); len(queue) != 0 {
+		t.Fatalf("distribution queue %v", queue)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, payID, 0); balance.Int64() != 150 {
+		t.Fatalf("recipient balance %v, want 150", balance)
+	}
+	if balance, _ := am.GetAccountBalanceByID(pool, payID, 0); balance.Sign() != 0 {
+		t.Fatalf("pool balance %v, want 0", balance)
+	}
+}
@@ -52,4 +52,101 @@ var (
 	ErrNegativeAmount         = errors.New("negative amount")
 	ErrAmountMustBeZero       = errors.New("amount must be zero")
 	ErrAssetOwnerInvaild      = errors.New("asset owner invalid")
+	ErrAuthorsIsEmpty         = errors.New("account authors is empty")
+	ErrThresholdUnreachable   = errors.New("account threshold unreachable")
+	ErrEscrowNotExist         = errors.New("escrow not exist")
+	ErrEscrowExpiryInvalid    = errors.New("escrow expiry invalid")
+	ErrEscrowExpired          = errors.New("escrow is expired")
+	ErrEscrowNotExpired       = errors.New("escrow is not expired")
+	ErrEscrowClaimKeyInvalid  = errors.New("escrow claim key invalid")
+	ErrAccountReclaimDisabled = errors.New("account name reclaim disabled")
+	ErrAccountNotDestroy      = errors.New("account is not destroy")
+	ErrAccountInRetention     = errors.New("destroyed account in retention")
+	ErrAccountBalanceNotZero  = errors.New("account balance is not zero")
+	ErrTimeLockNotExist       = errors.New("time lock not exist")
+	ErrTimeLockUnlockInvalid  = errors.New("time lock unlock number or time invalid")
+	ErrTimeLockNotMatured     = errors.New("time lock is not matured")
+	ErrTimeLockMatured        = errors.New("time lock is matured")
+	ErrTimeLockNotRecipient   = errors.New("not the time lock recipient")
+	ErrTimeLockNotSender      = errors.New("not the time lock sender")
+	ErrStorageQuotaExceeded   = errors.New("account storage quota exceeded")
+	ErrInheritanceNotExist    = errors.New("inheritance policy not exist")
+	ErrInheritanceInvalid     = errors.New("inheritance policy invalid")
+	ErrInheritanceNotHeir     = errors.New("not the heir of the account")
+	ErrAccountStillActive     = errors.New("account is not inactive")
+	ErrInheritanceInChallenge = errors.New("inheritance claim in challenge window")
+	ErrAccountNamePremium     = errors.New("premium account name must be won by auction")
+	ErrNameAuctionNotExist    = errors.New("name auction not exist")
+	ErrNameAuctionEnded       = errors.New("name auction is ended")
+	ErrNameAuctionNotEnded    = errors.New("name auction is not ended")
+	ErrNameBidTooLow          = errors.New("name bid too low")
+	ErrNameAuctionNotWinner   = errors.New("not the winner of the name auction")
+	ErrAuthorUpdateNotExist   = errors.New("pending author update not exist")
+	ErrAuthorUpdatePending    = errors.New("author update is pending")
+	ErrAuthorUpdateNotMatured = errors.New("pending author update is not matured")
+	ErrAuthorScope            = errors.New("action type out of the author scope")
+	ErrGuardiansNotExist      = errors.New("recovery guardians not exist")
+	ErrGuardiansInvalid       = errors.New("recovery guardians invalid")
+	ErrNotGuardian            = errors.New("not the recovery guardian of the account")
+	ErrRecoveryNotExist       = errors.New("recovery proposal not exist")
+	ErrRecoveryApproved       = errors.New("recovery proposal already approved")
+	ErrRecoveryNotReady       = errors.New("recovery proposal not approved or in timelock")
+	ErrAccountManagerReadOnly = errors.New("account manager is read only")
+	ErrAccountNameReserved    = errors.New("account name is reserved")
+	ErrReservedNamesInvalid   = errors.New("reserved names invalid")
+	ErrNotSystemAccount       = errors.New("not the system account")
+	ErrAllowanceInsufficient  = errors.New("insufficient allowance")
+	ErrSpenderInvalid         = errors.New("allowance spender invalid")
+	ErrNFTransfer             = errors.New("non-fungible asset can only be transferred by token")
+	ErrNFTBurn                = errors.New("non-fungible asset can only be burned by token")
+	ErrAssetNotBurnable       = errors.New("asset is not burnable")
+	ErrDistributionNotExist   = errors.New("distribution not exist")
+	ErrDistributionSupply     = errors.New("distribution holder asset has no supply")
+	ErrDistributionSnapshot   = errors.New("distribution snapshot before the holder index")
+	ErrInvitationNotExist     = errors.New("invitation not exist")
+	ErrInvitationIsExist      = errors.New("invitation is exist")
+	ErrInvitationCodes        = errors.New("invitation code hashes invalid")
+	ErrInvitationExpiry       = errors.New("invitation expiry invalid")
+	ErrInvitationExpired      = errors.New("invitation is expired")
+	ErrInvitationSponsor      = errors.New("not the invitation sponsor")
+	ErrAssetAmountFormat      = errors.New("asset amount format invalid")
+	ErrAssetRedenominating    = errors.New("asset is redenominating")
+	ErrRedenominationInvalid  = errors.New("asset redenomination invalid")
+	ErrRedenominationNotExist = errors.New("asset redenomination not exist")
+	ErrRedenominateEscrow     = errors.New("asset held in escrow can not be redenominated")
+	ErrRedenominateSysAsset   = errors.New("system asset can not be redenominated")
+	ErrRedenominateScheduled  = errors.New("asset of pending scheduled actions can not be redenominated")
+	ErrBalancesNotMigrated    = errors.New("account balances are not migrated")
+	ErrTooManyProofAssets     = errors.New("too many assets of the account proof")
+	ErrAccountProofMismatch   = errors.New("account proof mismatch")
+	ErrHolderLockInvalid      = errors.New("holder balance lock invalid")
+	ErrHolderLocksFull        = errors.New("holder balance locks exceed maximum")
+	ErrHolderLockNotMatured   = errors.New("holder balance lock is not matured")
+	ErrBalanceLocked          = errors.New("balance is locked")
+	ErrAssetPaused            = errors.New("asset transfers are paused")
+	ErrPauseSysAsset          = errors.New("system asset can not be paused")
+	ErrSwapInvalid            = errors.New("atomic swap invalid")
+	ErrSwapExpired            = errors.New("atomic swap is expired")
+	ErrSwapExecuted           = errors.New("atomic swap is executed already")
+	ErrSwapSignature          = errors.New("atomic swap counterparty signature invalid")
+	ErrWrappedAssetSupply     = errors.New("wrapped asset supply only changes by the bridge")
+	ErrWrapSysAsset           = errors.New("system asset can not be wrapped")
+	ErrSchedulerDisabled      = errors.New("scheduled actions are disabled")
+	ErrScheduleInvalid        = errors.New("scheduled action invalid")
+	ErrScheduleNumber         = errors.New("scheduled block number invalid")
+	ErrScheduledNotExist      = errors.New("scheduled action not exist")
+	ErrScheduleSender         = errors.New("not the scheduled action sender")
+	ErrAssetVerifierInvalid   = errors.New("asset verifier is not a contract")
+	ErrAssetVerifierRejected  = errors.New("asset verifier rejected the action")
+	ErrAssetVerifierUnusable  = errors.New("asset verifier can not be called by the action")
+	ErrTypedDataChainID       = errors.New("typed data of another chain")
+	ErrTypedSignatureEmpty    = errors.New("typed data is not signed")
+	ErrPermitInvalid          = errors.New("permit transfer invalid")
+	ErrPermitExpired          = errors.New("permit transfer is expired")
+	ErrPermitExecuted         = errors.New("permit transfer is executed already")
+	ErrPermitRelayer          = errors.New("permit transfer sent by another relayer")
+	ErrPermitDecimals         = errors.New("permit transfer signed in other decimals of the asset")
+	ErrBLSAuthorDisabled      = errors.New("bls authors are disabled")
+	ErrBLSProofInvalid        = errors.New("bls author proof of possession invalid")
+	ErrAggregateSignInvalid   = errors.New("aggregated bls signature invalid")
 )
@@ -0,0 +1,223 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	escrowPrefix        = "escrow"
+	escrowCounterPrefix = "escrowCounter"
+)
+
+// TransferEscrowAction lock the action value until claimed or expired.
+// An empty recipient means whoever holds the claim key can claim it, the claim
+// hash of such an open escrow is the hash of the public key of the claim key.
+// The claimer signs its own name by the claim key, so a claim seen pending
+// can't be replayed by another account.
+type TransferEscrowAction struct {
+	Recipient common.Name `json:"recipient,omitempty"`
+	ClaimHash common.Hash `json:"claimHash,omitempty"`
+	Expiry    uint64      `json:"expiry,omitempty"`
+}
+
+// ClaimEscrowAction release the escrow by the claim key, or by the signature
+// of the claimer by the claim key for an open escrow.
+type ClaimEscrowAction struct {
+	EscrowID  uint64 `json:"escrowID,omitempty"`
+	ClaimKey  []byte `json:"claimKey,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// RefundEscrowAction refund the expired escrow to the sender.
+type RefundEscrowAction struct {
+	EscrowID uint64 `json:"escrowID,omitempty"`
+}
+
+// Escrow escrow object
+type Escrow struct {
+	EscrowID  uint64      `json:"escrowID"`
+	Sender    common.Name `json:"sender"`
+	Recipient common.Name `json:"recipient"`
+	AssetID   uint64      `json:"assetID"`
+	Amount    *big.Int    `json:"amount"`
+	ClaimHash common.Hash `json:"claimHash"`
+	Number    uint64      `json:"number"`
+	Expiry    uint64      `json:"expiry"`
+}
+
+//getEscrowCounter get escrow counter cur value
+func (am *AccountManager) getEscrowCounter() (uint64, error) {
+	b, err := am.sdb.Get(acctManagerName, escrowCounterPrefix)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	var escrowCounter uint64
+	if err := rlp.DecodeBytes(b, &escrowCounter); err != nil {
+		return 0, err
+	}
+	return escrowCounter, nil
+}
+
+//GetEscrowByID get escrow by escrow id
+func (am *AccountManager) GetEscrowByID(id uint64) (*Escrow, error) {
+	b, err := am.sdb.Get(acctManagerName, escrowPrefix+strconv.FormatUint(id, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrEscrowNotExist
+	}
+	var escrow Escrow
+	if err := rlp.DecodeBytes(b, &escrow); err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
+
+func (am *AccountManager) setEscrow(escrow *Escrow) error {
+	b, err := rlp.EncodeToBytes(escrow)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, escrowPrefix+strconv.FormatUint(escrow.EscrowID, 10), b)
+	return nil
+}
+
+func (am *AccountManager) deleteEscrow(id uint64) {
+	am.sdb.Delete(acctManagerName, escrowPrefix+strconv.FormatUint(id, 10))
+}
+
+//TransferEscrow record the escrow of the value already transferred to the account manager
+func (am *AccountManager) TransferEscrow(fromName common.Name, assetID uint64, amount *big.Int, number uint64, action *TransferEscrowAction) (uint64, error) {
+	if amount.Sign() <= 0 {
+		return 0, ErrAmountValueInvalid
+	}
+	if action.ClaimHash == (common.Hash{}) {
+		return 0, ErrHashIsEmpty
+	}
+	if action.Expiry <= number {
+		return 0, ErrEscrowExpiryInvalid
+	}
+	if len(action.Recipient.String()) > 0 && !action.Recipient.IsValid(acctRegExp, accountNameLength) {
+		return 0, ErrAccountNameInvalid
+	}
+
+	escrowCounter, err := am.getEscrowCounter()
+	if err != nil {
+		return 0, err
+	}
+	escrowCounter = escrowCounter + 1
+
+	escrow := &Escrow{
+		EscrowID:  escrowCounter,
+		Sender:    fromName,
+		Recipient: action.Recipient,
+		AssetID:   assetID,
+		Amount:    new(big.Int).Set(amount),
+		ClaimHash: action.ClaimHash,
+		Number:    number,
+		Expiry:    action.Expiry,
+	}
+	if err := am.setEscrow(escrow); err != nil {
+		return 0, err
+	}
+
+	b, err := rlp.EncodeToBytes(&escrowCounter)
+	if err != nil {
+		return 0, err
+	}
+	am.sdb.Put(acctManagerName, escrowCounterPrefix, b)
+	return escrowCounter, nil
+}
+
+//OpenEscrowClaimHash get the claim hash of the open escrow claimed by the key
+func OpenEscrowClaimHash(pub *ecdsa.PublicKey) common.Hash {
+	return crypto.Keccak256Hash(crypto.FromECDSAPub(pub))
+}
+
+//openEscrowClaimSignHash get the hash the claimer of the open escrow signs
+func openEscrowClaimSignHash(escrowID uint64, claimer common.Name) (common.Hash, error) {
+	b, err := rlp.EncodeToBytes([]interface{}{escrowID, claimer})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(b), nil
+}
+
+//SignOpenClaim sign the claim of the open escrow for the claimer by the claim key
+func (c *ClaimEscrowAction) SignOpenClaim(claimer common.Name, priv *ecdsa.PrivateKey) error {
+	hash, err := openEscrowClaimSignHash(c.EscrowID, claimer)
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(hash[:], priv)
+	if err != nil {
+		return err
+	}
+	c.Signature = sig
+	return nil
+}
+
+//ClaimEscrow check the claim key and remove the escrow, return the escrow to be released
+func (am *AccountManager) ClaimEscrow(fromName common.Name, number uint64, action *ClaimEscrowAction) (*Escrow, error) {
+	escrow, err := am.GetEscrowByID(action.EscrowID)
+	if err != nil {
+		return nil, err
+	}
+	if number > escrow.Expiry {
+		return nil, ErrEscrowExpired
+	}
+	if len(escrow.Recipient.String()) == 0 {
+		hash, err := openEscrowClaimSignHash(escrow.EscrowID, fromName)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := crypto.SigToPub(hash[:], action.Signature)
+		if err != nil || OpenEscrowClaimHash(pub) != escrow.ClaimHash {
+			return nil, ErrEscrowClaimKeyInvalid
+		}
+		escrow.Recipient = fromName
+	} else if crypto.Keccak256Hash(action.ClaimKey) != escrow.ClaimHash {
+		return nil, ErrEscrowClaimKeyInvalid
+	}
+	am.deleteEscrow(escrow.EscrowID)
+	return escrow, nil
+}
+
+//RefundEscrow remove the expired escrow, return the escrow to be refunded
+func (am *AccountManager) RefundEscrow(number uint64, action *RefundEscrowAction) (*Escrow, error) {
+	escrow, err := am.GetEscrowByID(action.EscrowID)
+	if err != nil {
+		return nil, err
+	}
+	if number <= escrow.Expiry {
+		return nil, ErrEscrowNotExpired
+	}
+	am.deleteEscrow(escrow.EscrowID)
+	return escrow, nil
+}
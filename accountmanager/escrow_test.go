@@ -0,0 +1,170 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func newEscrowTestManager(t *testing.T) (*AccountManager, uint64) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, _ := GeneragePubKey()
+	for _, name := range []string{"fractal", "fractal.account", "escrowsender", "escrowrecipient"} {
+		if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatalf("create account %s err %v", name, err)
+		}
+	}
+	issue := IssueAsset{AssetName: "escrowcoin", Symbol: "esc", Amount: big.NewInt(0), Owner: common.Name("escrowsender"), UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(common.Name("escrowsender"), issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(common.Name("escrowsender"), assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	return am, assetID
+}
+
+func processEscrowAction(am *AccountManager, aType types.ActionType, from common.Name, assetID uint64, value *big.Int, number uint64, data interface{}) error {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	action := types.NewAction(aType, from, common.Name(params.DefaultChainconfig.AccountName), 0, assetID, 0, value, payload, nil)
+	_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: number})
+	return err
+}
+
+func TestAccountManager_ClaimEscrow(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	key := []byte("escrow claim key")
+	escrow := &TransferEscrowAction{Recipient: common.Name("escrowrecipient"), ClaimHash: crypto.Keccak256Hash(key), Expiry: 10}
+	if err := processEscrowAction(am, types.TransferEscrow, common.Name("escrowsender"), assetID, big.NewInt(30), 1, escrow); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.GetEscrowByID(1); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		claim   *ClaimEscrowAction
+		number  uint64
+		wantErr bool
+	}{
+		{"invalidkey", &ClaimEscrowAction{EscrowID: 1, ClaimKey: []byte("wrong key")}, 5, true},
+		{"expired", &ClaimEscrowAction{EscrowID: 1, ClaimKey: key}, 11, true},
+		{"claim", &ClaimEscrowAction{EscrowID: 1, ClaimKey: key}, 10, false},
+		{"claimed", &ClaimEscrowAction{EscrowID: 1, ClaimKey: key}, 10, true},
+	}
+	for _, tt := range tests {
+		if err := processEscrowAction(am, types.ClaimEscrow, common.Name("escrowsender"), assetID, big.NewInt(0), tt.number, tt.claim); (err != nil) != tt.wantErr {
+			t.Errorf("%q. ClaimEscrow error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	balance, _ := am.GetAccountBalanceByID(common.Name("escrowrecipient"), assetID, 0)
+	if balance.Cmp(big.NewInt(30)) != 0 {
+		t.Errorf("recipient balance = %v, want 30", balance)
+	}
+	balance, _ = am.GetAccountBalanceByID(common.Name("escrowsender"), assetID, 0)
+	if balance.Cmp(big.NewInt(70)) != 0 {
+		t.Errorf("sender balance = %v, want 70", balance)
+	}
+}
+
+func TestAccountManager_ClaimOpenEscrow(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	key, _ := crypto.GenerateKey()
+	escrow := &TransferEscrowAction{ClaimHash: OpenEscrowClaimHash(&key.PublicKey), Expiry: 10}
+	if err := processEscrowAction(am, types.TransferEscrow, common.Name("escrowsender"), assetID, big.NewInt(30), 1, escrow); err != nil {
+		t.Fatal(err)
+	}
+
+	// the claim signed for the recipient can't be taken by another account
+	claim := &ClaimEscrowAction{EscrowID: 1}
+	if err := claim.SignOpenClaim(common.Name("escrowrecipient"), key); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.ClaimEscrow, common.Name("escrowsender"), assetID, big.NewInt(0), 5, claim); err != ErrEscrowClaimKeyInvalid {
+		t.Fatalf("claim by other account err %v", err)
+	}
+	other, _ := crypto.GenerateKey()
+	forged := &ClaimEscrowAction{EscrowID: 1}
+	if err := forged.SignOpenClaim(common.Name("escrowsender"), other); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.ClaimEscrow, common.Name("escrowsender"), assetID, big.NewInt(0), 5, forged); err != ErrEscrowClaimKeyInvalid {
+		t.Fatalf("claim by other key err %v", err)
+	}
+	if err := processEscrowAction(am, types.ClaimEscrow, common.Name("escrowrecipient"), assetID, big.NewInt(0), 5, claim); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(common.Name("escrowrecipient"), assetID, 0); balance.Cmp(big.NewInt(30)) != 0 {
+		t.Errorf("recipient balance = %v, want 30", balance)
+	}
+}
+
+func TestAccountManager_RefundEscrow(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	escrow := &TransferEscrowAction{ClaimHash: crypto.Keccak256Hash([]byte("key")), Expiry: 10}
+	if err := processEscrowAction(am, types.TransferEscrow, common.Name("escrowsender"), assetID, big.NewInt(30), 10, escrow); err == nil {
+		t.Error("TransferEscrow want expiry err")
+	}
+	if err := processEscrowAction(am, types.TransferEscrow, common.Name("escrowsender"), assetID, big.NewInt(30), 1, escrow); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		number  uint64
+		wantErr bool
+	}{
+		{"notexpired", 10, true},
+		{"refund", 11, false},
+		{"refunded", 12, true},
+	}
+	for _, tt := range tests {
+		if err := processEscrowAction(am, types.RefundEscrow, common.Name("escrowrecipient"), assetID, big.NewInt(0), tt.number, &RefundEscrowAction{EscrowID: 1}); (err != nil) != tt.wantErr {
+			t.Errorf("%q. RefundEscrow error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	balance, _ := am.GetAccountBalanceByID(common.Name("escrowsender"), assetID, 0)
+	if balance.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("sender balance = %v, want 100", balance)
+	}
+}
+
+func TestAccountManager_EscrowNotForked(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	setForkID(am.sdb, params.ForkID3)
+	escrow := &TransferEscrowAction{Recipient: common.Name("escrowrecipient"), Expiry: 5}
+	if err := processEscrowAction(am, types.TransferEscrow, common.Name("escrowsender"), assetID, big.NewInt(30), 1, escrow); err != types.ErrActionUndefined {
+		t.Errorf("TransferEscrow err = %v, want %v", err, types.ErrActionUndefined)
+	}
+}
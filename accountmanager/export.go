@@ -0,0 +1,165 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// ExportRecord a line of the account export, an asset or an account with its balances
+type ExportRecord struct {
+	Asset   *asset.AssetObject `json:"asset,omitempty"`
+	Account *Account           `json:"account,omitempty"`
+}
+
+//ExportAccounts write the assets and the accounts of the committed state at root to w, one json
+//record per line, the assets in asset id order followed by the accounts with their balances in
+//account id order. It returns the number of the accounts written.
+func (am *AccountManager) ExportAccounts(w io.Writer, root common.Hash) (uint64, error) {
+	sdb, err := state.New(root, am.sdb.Database())
+	if err != nil {
+		return 0, err
+	}
+	src, err := NewAccountManager(sdb)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	var count uint64
+	assetCount, err := src.ast.GetAssetCount()
+	if err != nil && err != asset.ErrAssetCountNotExist {
+		return count, err
+	}
+	for id := uint64(0); id < assetCount; id++ {
+		ao, err := src.ast.GetAssetObjectById(id)
+		if err == asset.ErrAssetNotExist {
+			continue
+		} else if err != nil {
+			return count, err
+		}
+		if err := enc.Encode(&ExportRecord{Asset: ao}); err != nil {
+			return count, err
+		}
+	}
+
+	accountCounter, err := src.getAccountCounter()
+	if err != nil {
+		return count, err
+	}
+	for id := counterID + 1; id <= accountCounter; id++ {
+		acct, err := src.GetAccountById(id)
+		if err != nil {
+			return count, err
+		}
+		if acct == nil {
+			continue
+		}
+		if err := enc.Encode(&ExportRecord{Account: acct}); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+//ImportAccounts read the records exported by ExportAccounts from r, the assets are issued with their
+//asset id and the accounts are stored with their account id and balances. It returns the number of
+//the accounts imported.
+func (am *AccountManager) ImportAccounts(r io.Reader) (uint64, error) {
+	accountCounter, err := am.getAccountCounter()
+	if err != nil {
+		return 0, err
+	}
+	am.ast.InitAssetCount()
+	dec := json.NewDecoder(r)
+	var count uint64
+	for {
+		var record ExportRecord
+		if err := dec.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return count, err
+		}
+		if record.Asset != nil {
+			if err := am.importAsset(record.Asset); err != nil {
+				return count, err
+			}
+		}
+		if record.Account == nil {
+			continue
+		}
+		acct := record.Account
+		if acct.GetAccountID() <= counterID {
+			return count, fmt.Errorf("%v %d", ErrAccountIdInvalid, acct.GetAccountID())
+		}
+		if exist, err := am.AccountIsExist(acct.GetName()); err != nil {
+			return count, err
+		} else if exist {
+			return count, fmt.Errorf("%v %s", ErrAccountIsExist, acct.GetName())
+		}
+		if old, err := am.getAccountMetaById(acct.GetAccountID()); err != nil {
+			return count, err
+		} else if old != nil {
+			return count, fmt.Errorf("%v %d", ErrAccountIdInvalid, acct.GetAccountID())
+		}
+		// the balances must be of the assets imported before
+		for _, balance := range acct.Balances {
+			if _, err := am.ast.GetAssetObjectById(balance.AssetID); err != nil {
+				return count, fmt.Errorf("%v %d of %s", err, balance.AssetID, acct.GetName())
+			}
+		}
+
+		if err := am.putAccount(acct); err != nil {
+			return count, err
+		}
+		aid, err := rlp.EncodeToBytes(acct.GetAccountID())
+		if err != nil {
+			return count, err
+		}
+		am.sdb.Put(acctManagerName, accountNameIDPrefix+acct.GetName().String(), aid)
+		if acct.GetAccountID() > accountCounter {
+			accountCounter = acct.GetAccountID()
+		}
+		count++
+	}
+	b, err := rlp.EncodeToBytes(&accountCounter)
+	if err != nil {
+		return count, err
+	}
+	am.sdb.Put(acctManagerName, counterPrefix, b)
+	return count, nil
+}
+
+// importAsset issue the exported asset, its id must be the next asset id
+func (am *AccountManager) importAsset(ao *asset.AssetObject) error {
+	assetCount, err := am.ast.GetAssetCount()
+	if err != nil {
+		return err
+	}
+	if ao.GetAssetId() != assetCount {
+		return fmt.Errorf("%v %d, want %d", asset.ErrAssetIdInvalid, ao.GetAssetId(), assetCount)
+	}
+	_, err = am.ast.IssueAssetObject(ao)
+	return err
+}
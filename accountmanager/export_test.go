@@ -0,0 +1,110 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestAccountManager_ExportImportAccounts(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	root, err := am.sdb.Commit(memdb.NewMemDatabase().NewBatch(), common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the export is of the state at the root, the later changes are left out
+	if err := am.AddAccountBalanceByID(common.Name("escrowsender"), assetID, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	var dump bytes.Buffer
+	count, err := am.ExportAccounts(&dump, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Fatalf("export count mismatch, got %d want 4", count)
+	}
+
+	var again bytes.Buffer
+	if _, err := am.ExportAccounts(&again, root); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dump.Bytes(), again.Bytes()) {
+		t.Fatal("export is not deterministic")
+	}
+
+	newAm, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count, err := newAm.ImportAccounts(bytes.NewReader(dump.Bytes())); err != nil {
+		t.Fatal(err)
+	} else if count != 4 {
+		t.Fatalf("import count mismatch, got %d want 4", count)
+	}
+
+	src, err := am.GetAccountByName(common.Name("escrowsender"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := newAm.GetAccountByName(common.Name("escrowsender"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst == nil || dst.GetAccountID() != src.GetAccountID() {
+		t.Fatalf("imported account mismatch, got %v want %v", dst, src)
+	}
+	if balance, err := newAm.GetAccountBalanceByID(common.Name("escrowsender"), assetID, 0); err != nil || balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("imported balance mismatch, got %v err %v", balance, err)
+	}
+	if ao, err := newAm.GetAssetInfoByID(assetID); err != nil || ao.GetAssetName() != "escrowcoin" {
+		t.Fatalf("imported asset mismatch, got %v err %v", ao, err)
+	}
+
+	newRoot, err := newAm.sdb.Commit(memdb.NewMemDatabase().NewBatch(), common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reexport bytes.Buffer
+	if _, err := newAm.ExportAccounts(&reexport, newRoot); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dump.Bytes(), reexport.Bytes()) {
+		t.Fatal("re-export of the imported accounts mismatch")
+	}
+
+	pubkey, _ := GeneragePubKey()
+	if err := newAm.CreateAccount(common.Name("fractal"), common.Name("exportnew"), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	acct, err := newAm.GetAccountByName(common.Name("exportnew"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.GetAccountID() <= src.GetAccountID() {
+		t.Fatalf("account id reused after import, got %d", acct.GetAccountID())
+	}
+
+	if _, err := newAm.ImportAccounts(bytes.NewReader(dump.Bytes())); err == nil {
+		t.Fatal("import existing accounts should fail")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_RecoverTxFeePayer(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	userPub, userPriv := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name("payeruser"), common.Name(""), 0, 0, userPub, ""); err != nil {
+		t.Fatal(err)
+	}
+	sponsorPub, sponsorPriv := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name("payersponsor"), common.Name(""), 0, 0, sponsorPub, ""); err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv := GeneragePubKey()
+
+	signer := types.NewSigner(big.NewInt(1))
+	newTx := func() *types.Transaction {
+		action := types.NewAction(types.Transfer, common.Name("payeruser"), common.Name("escrowrecipient"), 0, 0, 0, big.NewInt(0), nil, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		tx.WithPayer(common.Name("payersponsor"))
+		if err := types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(userPriv, []uint64{0})}); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	tx := newTx()
+	if err := am.RecoverTx(signer, tx); err != types.ErrSignEmpty {
+		t.Fatalf("unsigned payer err %v", err)
+	}
+	if err := types.SignPayerWithMultiKey(tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(sponsorPriv, []uint64{0})}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.RecoverTx(signer, tx); err != nil {
+		t.Fatalf("sponsored tx err %v", err)
+	}
+	if got := tx.GasPayer(tx.GetActions()[0]); got != common.Name("payersponsor") {
+		t.Fatalf("gas payer %v", got)
+	}
+
+	// the sender can not sign for the payer
+	tx = newTx()
+	if err := types.SignPayerWithMultiKey(tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(otherPriv, []uint64{0})}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.RecoverTx(signer, tx); err == nil {
+		t.Fatal("foreign payer signature accepted")
+	}
+
+	// the payer signature does not cover another payer
+	tx = newTx()
+	if err := types.SignPayerWithMultiKey(tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(sponsorPriv, []uint64{0})}); err != nil {
+		t.Fatal(err)
+	}
+	sign := tx.Payer().Sign
+	tx.WithPayer(common.Name("payeruser"))
+	tx.Payer().Sign = sign
+	if err := am.RecoverTx(signer, tx); err == nil {
+		t.Fatal("payer signature reused for another payer")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// The fork controller stores the fork information under the chain name. The
+// account manager reads the current fork id from it, so every caller sharing
+// the state, the block processing, the consensus and the rpc alike, switches
+// the state layout at the same block.
+var (
+	chainName   = ""
+	forkInfoKey = "forkInfo"
+)
+
+// forkInfo is the head of the fork information stored by the fork controller.
+type forkInfo struct {
+	CurForkID uint64
+	Rest      []rlp.RawValue `rlp:"tail"`
+}
+
+//SetChainName set the global chain name the fork information is stored under
+func SetChainName(name common.Name) {
+	chainName = name.String()
+	asset.SetChainName(name)
+}
+
+//curForkID get the current fork id of the chain, ForkID0 before the fork controller is initialized
+func (am *AccountManager) curForkID() (uint64, error) {
+	if len(chainName) == 0 {
+		return params.ForkID0, nil
+	}
+	b, err := am.sdb.Get(chainName, forkInfoKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return params.ForkID0, nil
+	}
+	var info forkInfo
+	if err := rlp.DecodeBytes(b, &info); err != nil {
+		return 0, err
+	}
+	return info.CurForkID, nil
+}
+
+//CurForkID get the current fork id of the chain stored in the state
+func (am *AccountManager) CurForkID() (uint64, error) {
+	return am.curForkID()
+}
+
+//isForked check the fork of the id is active
+func (am *AccountManager) isForked(id uint64) (bool, error) {
+	cur, err := am.curForkID()
+	if err != nil {
+		return false, err
+	}
+	return cur >= id, nil
+}
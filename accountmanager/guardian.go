@@ -0,0 +1,307 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	acctGuardianPrefix = "acctGuardian"
+	acctRecoveryPrefix = "acctRecovery"
+)
+
+// SetGuardiansAction set the recovery guardians of the sender account, empty
+// guardians removes them.
+type SetGuardiansAction struct {
+	Guardians []common.Name `json:"guardians,omitempty"`
+	Threshold uint64        `json:"threshold,omitempty"`
+	Timelock  uint64        `json:"timelock,omitempty"`
+}
+
+// ProposeRecoveryAction propose to replace the authors of the account.
+type ProposeRecoveryAction struct {
+	Account   common.Name      `json:"account,omitempty"`
+	Authors   []*common.Author `json:"authors,omitempty"`
+	Threshold uint64           `json:"threshold,omitempty"`
+}
+
+// RecoveryAction approve or execute the recovery proposal of the account.
+type RecoveryAction struct {
+	Account common.Name `json:"account,omitempty"`
+}
+
+// Guardians accounts able to rotate the keys of the account together.
+type Guardians struct {
+	Guardians []common.Name `json:"guardians"`
+	Threshold uint64        `json:"threshold"`
+	Timelock  uint64        `json:"timelock"`
+}
+
+// IsGuardian check the account is one of the guardians.
+func (g *Guardians) IsGuardian(name common.Name) bool {
+	for _, guardian := range g.Guardians {
+		if guardian == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RecoveryProposal pending key rotation of the account. It can be executed
+// Timelock blocks after Threshold guardians approved it, until then the
+// account can cancel it with its current keys.
+type RecoveryProposal struct {
+	Authors     []*common.Author `json:"authors"`
+	Threshold   uint64           `json:"threshold"`
+	Approvals   []common.Name    `json:"approvals"`
+	Number      uint64           `json:"number"`
+	ReadyNumber uint64           `json:"readyNumber"`
+}
+
+//GetGuardians get the recovery guardians of the account
+func (am *AccountManager) GetGuardians(accountName common.Name) (*Guardians, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.getGuardians(accountID)
+}
+
+func (am *AccountManager) getGuardians(accountID uint64) (*Guardians, error) {
+	b, err := am.sdb.Get(acctManagerName, acctGuardianPrefix+strconv.FormatUint(accountID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrGuardiansNotExist
+	}
+	var guardians Guardians
+	if err := rlp.DecodeBytes(b, &guardians); err != nil {
+		return nil, err
+	}
+	return &guardians, nil
+}
+
+//GetRecoveryProposal get the pending recovery proposal of the account
+func (am *AccountManager) GetRecoveryProposal(accountName common.Name) (*RecoveryProposal, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.getRecoveryProposal(accountID)
+}
+
+func (am *AccountManager) getRecoveryProposal(accountID uint64) (*RecoveryProposal, error) {
+	b, err := am.sdb.Get(acctManagerName, acctRecoveryPrefix+strconv.FormatUint(accountID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrRecoveryNotExist
+	}
+	var proposal RecoveryProposal
+	if err := rlp.DecodeBytes(b, &proposal); err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+func (am *AccountManager) setRecoveryProposal(accountID uint64, proposal *RecoveryProposal) error {
+	b, err := rlp.EncodeToBytes(proposal)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctRecoveryPrefix+strconv.FormatUint(accountID, 10), b)
+	return nil
+}
+
+//SetGuardians set or remove the recovery guardians of the account, the pending proposal is dropped
+func (am *AccountManager) SetGuardians(accountName common.Name, action *SetGuardiansAction) error {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	am.sdb.Delete(acctManagerName, acctRecoveryPrefix+strconv.FormatUint(accountID, 10))
+	if len(action.Guardians) == 0 {
+		am.sdb.Delete(acctManagerName, acctGuardianPrefix+strconv.FormatUint(accountID, 10))
+		return nil
+	}
+	limit, err := am.authorLimit()
+	if err != nil {
+		return err
+	}
+	if action.Threshold == 0 || action.Threshold > uint64(len(action.Guardians)) || uint64(len(action.Guardians)) > limit.authorNum {
+		return ErrGuardiansInvalid
+	}
+	seen := make(map[common.Name]bool)
+	for _, guardian := range action.Guardians {
+		if guardian == accountName || seen[guardian] {
+			return ErrGuardiansInvalid
+		}
+		seen[guardian] = true
+		acct, err := am.getAccountMetaByName(guardian)
+		if err != nil {
+			return err
+		}
+		if acct == nil {
+			return ErrAccountNotExist
+		}
+	}
+	b, err := rlp.EncodeToBytes(&Guardians{Guardians: action.Guardians, Threshold: action.Threshold, Timelock: action.Timelock})
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctGuardianPrefix+strconv.FormatUint(accountID, 10), b)
+	return nil
+}
+
+//ProposeRecovery the guardian propose the new authors of the account, it replaces the pending proposal
+func (am *AccountManager) ProposeRecovery(guardian common.Name, number uint64, action *ProposeRecoveryAction) error {
+	acct, err := am.getAccountMetaByName(action.Account)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	guardians, err := am.getGuardians(acct.GetAccountID())
+	if err != nil {
+		return err
+	}
+	if !guardians.IsGuardian(guardian) {
+		return ErrNotGuardian
+	}
+	limit, err := am.authorLimit()
+	if err != nil {
+		return err
+	}
+	if uint64(len(action.Authors)) > limit.authorNum {
+		return ErrGuardiansInvalid
+	}
+	for _, author := range action.Authors {
+		if err := checkBLSAuthor(author); err != nil {
+			return err
+		}
+	}
+	acct.Authors = action.Authors
+	acct.SetThreshold(action.Threshold)
+	acct.SetUpdateAuthorThreshold(action.Threshold)
+	if err := acct.CheckAuthors(); err != nil {
+		return err
+	}
+	return am.approveRecovery(acct.GetAccountID(), guardians, guardian, number, &RecoveryProposal{
+		Authors:   action.Authors,
+		Threshold: action.Threshold,
+		Number:    number,
+	})
+}
+
+//ApproveRecovery the guardian approve the pending recovery proposal of the account
+func (am *AccountManager) ApproveRecovery(guardian common.Name, number uint64, action *RecoveryAction) error {
+	accountID, err := am.GetAccountIDByName(action.Account)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	guardians, err := am.getGuardians(accountID)
+	if err != nil {
+		return err
+	}
+	if !guardians.IsGuardian(guardian) {
+		return ErrNotGuardian
+	}
+	proposal, err := am.getRecoveryProposal(accountID)
+	if err != nil {
+		return err
+	}
+	for _, approval := range proposal.Approvals {
+		if approval == guardian {
+			return ErrRecoveryApproved
+		}
+	}
+	return am.approveRecovery(accountID, guardians, guardian, number, proposal)
+}
+
+func (am *AccountManager) approveRecovery(accountID uint64, guardians *Guardians, guardian common.Name, number uint64, proposal *RecoveryProposal) error {
+	proposal.Approvals = append(proposal.Approvals, guardian)
+	if proposal.ReadyNumber == 0 && uint64(len(proposal.Approvals)) >= guardians.Threshold {
+		proposal.ReadyNumber = number + guardians.Timelock
+	}
+	return am.setRecoveryProposal(accountID, proposal)
+}
+
+//CancelRecovery the account cancel the pending recovery proposal with its current keys
+func (am *AccountManager) CancelRecovery(accountName common.Name) error {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	if _, err := am.getRecoveryProposal(accountID); err != nil {
+		return err
+	}
+	am.sdb.Delete(acctManagerName, acctRecoveryPrefix+strconv.FormatUint(accountID, 10))
+	return nil
+}
+
+//ExecuteRecovery replace the authors of the account with the approved proposal after the timelock
+func (am *AccountManager) ExecuteRecovery(number uint64, action *RecoveryAction) error {
+	acct, err := am.getAccountMetaByName(action.Account)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	if acct.IsDestroyed() {
+		return ErrAccountIsDestroy
+	}
+	proposal, err := am.getRecoveryProposal(acct.GetAccountID())
+	if err != nil {
+		return err
+	}
+	if proposal.ReadyNumber == 0 || number < proposal.ReadyNumber {
+		return ErrRecoveryNotReady
+	}
+	acct.Authors = proposal.Authors
+	acct.SetThreshold(proposal.Threshold)
+	acct.SetUpdateAuthorThreshold(proposal.Threshold)
+	acct.SetAuthorVersion()
+	if err := am.SetAccount(acct); err != nil {
+		return err
+	}
+	am.sdb.Delete(acctManagerName, acctRecoveryPrefix+strconv.FormatUint(acct.GetAccountID(), 10))
+	am.deletePendingAuthorUpdate(acct.GetAccountID())
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_GuardianRecovery(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	pubkey, _ := GeneragePubKey()
+	for _, name := range []string{"guardianone", "guardiantwo", "guardianthree"} {
+		if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	owner := common.Name("escrowsender")
+	setGuardians := &SetGuardiansAction{Guardians: []common.Name{"guardianone", "guardiantwo", "guardianthree"}, Threshold: 2, Timelock: 10}
+	if err := processEscrowAction(am, types.SetGuardians, owner, 0, big.NewInt(0), 1, setGuardians); err != nil {
+		t.Fatal(err)
+	}
+
+	newKey, _ := GeneragePubKey()
+	propose := &ProposeRecoveryAction{Account: owner, Authors: []*common.Author{common.NewAuthor(newKey, 1)}, Threshold: 1}
+	if err := processEscrowAction(am, types.ProposeRecovery, common.Name("escrowrecipient"), 0, big.NewInt(0), 2, propose); err != ErrNotGuardian {
+		t.Fatalf("propose by non guardian err %v", err)
+	}
+	if err := processEscrowAction(am, types.ProposeRecovery, common.Name("guardianone"), 0, big.NewInt(0), 2, propose); err != nil {
+		t.Fatal(err)
+	}
+	approve := &RecoveryAction{Account: owner}
+	if err := processEscrowAction(am, types.ExecuteRecovery, common.Name("escrowrecipient"), 0, big.NewInt(0), 20, approve); err != ErrRecoveryNotReady {
+		t.Fatalf("execute without approvals err %v", err)
+	}
+	if err := processEscrowAction(am, types.ApproveRecovery, common.Name("guardianone"), 0, big.NewInt(0), 3, approve); err != ErrRecoveryApproved {
+		t.Fatalf("approve twice err %v", err)
+	}
+
+	// the owner cancels the proposal with its current keys
+	if err := processEscrowAction(am, types.CancelRecovery, owner, 0, big.NewInt(0), 3, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.ApproveRecovery, common.Name("guardiantwo"), 0, big.NewInt(0), 4, approve); err != ErrRecoveryNotExist {
+		t.Fatalf("approve cancelled proposal err %v", err)
+	}
+
+	if err := processEscrowAction(am, types.ProposeRecovery, common.Name("guardianone"), 0, big.NewInt(0), 5, propose); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.ApproveRecovery, common.Name("guardiantwo"), 0, big.NewInt(0), 6, approve); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.ExecuteRecovery, common.Name("escrowrecipient"), 0, big.NewInt(0), 15, approve); err != ErrRecoveryNotReady {
+		t.Fatalf("execute in timelock err %v", err)
+	}
+	if err := processEscrowAction(am, types.ExecuteRecovery, common.Name("escrowrecipient"), 0, big.NewInt(0), 16, approve); err != nil {
+		t.Fatal(err)
+	}
+	acct, err := am.GetAccountByName(owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acct.Authors) != 1 || acct.Authors[0].Owner.String() != newKey.String() {
+		t.Fatalf("authors not rotated, got %v", acct.Authors)
+	}
+	if _, err := am.GetRecoveryProposal(owner); err != ErrRecoveryNotExist {
+		t.Fatalf("proposal not removed err %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// The holders of an asset are the accounts of a non zero balance, kept in a list
+// per asset. An account is appended when its balance leaves zero and swapped out
+// by the last holder when its balance returns to zero. The holder position is the
+// paging cursor, it is stable while the holders do not change.
+//
+// The balances still in the legacy account objects are indexed when they are all
+// migrated at the block activating ForkID4.
+var (
+	assetHolderCountPrefix = "assetHolderCount"
+	assetHolderPrefix      = "assetHolder"
+	assetHolderPosPrefix   = "assetHolderPos"
+)
+
+// MaxAssetHoldersLimit max holders returned by a query
+const MaxAssetHoldersLimit = 1000
+
+// MaxHoldersAtAccounts max accounts visited by a query of the holders at a snapshot
+const MaxHoldersAtAccounts = 100000
+
+// AssetHolder the account holding the asset
+type AssetHolder struct {
+	AccountID uint64      `json:"accountID"`
+	Name      common.Name `json:"name"`
+	Balance   *big.Int    `json:"balance"`
+}
+
+// AssetHolders a page of the holders of the asset, next is the cursor of the next page, 0 if no more
+type AssetHolders struct {
+	Holders []*AssetHolder `json:"holders"`
+	Next    uint64         `json:"next"`
+}
+
+func assetHolderKey(assetID uint64, pos uint64) string {
+	return assetHolderPrefix + strconv.FormatUint(assetID, 10) + "_" + strconv.FormatUint(pos, 10)
+}
+
+func assetHolderPosKey(assetID uint64, accountID uint64) string {
+	return assetHolderPosPrefix + strconv.FormatUint(assetID, 10) + "_" + strconv.FormatUint(accountID, 10)
+}
+
+func (am *AccountManager) getUint64(key string) (uint64, bool, error) {
+	return getUint64(am.stateGet, key)
+}
+
+func getUint64(get stateGetter, key string) (uint64, bool, error) {
+	b, err := get(key)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(b) == 0 {
+		return 0, false, nil
+	}
+	var v uint64
+	if err := rlp.DecodeBytes(b, &v); err != nil {
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
+func (am *AccountManager) putUint64(key string, v uint64) error {
+	b, err := rlp.EncodeToBytes(&v)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, key, b)
+	return nil
+}
+
+//GetAssetHolderCount get the number of the accounts holding the asset
+func (am *AccountManager) GetAssetHolderCount(assetID uint64) (uint64, error) {
+	count, _, err := am.getUint64(assetHolderCountPrefix + strconv.FormatUint(assetID, 10))
+	return count, err
+}
+
+//addAssetHolder append the account to the holders of the asset
+func (am *AccountManager) addAssetHolder(assetID uint64, accountID uint64) error {
+	if _, exist, err := am.getUint64(assetHolderPosKey(assetID, accountID)); err != nil || exist {
+		return err
+	}
+	count, err := am.GetAssetHolderCount(assetID)
+	if err != nil {
+		return err
+	}
+	if err := am.putUint64(assetHolderKey(assetID, count), accountID); err != nil {
+		return err
+	}
+	if err := am.putUint64(assetHolderPosKey(assetID, accountID), count); err != nil {
+		return err
+	}
+	return am.putUint64(assetHolderCountPrefix+strconv.FormatUint(assetID, 10), count+1)
+}
+
+//removeAssetHolder remove the account from the holders of the asset, the last holder takes its position
+func (am *AccountManager) removeAssetHolder(assetID uint64, accountID uint64) error {
+	pos, exist, err := am.getUint64(assetHolderPosKey(assetID, accountID))
+	if err != nil || !exist {
+		return err
+	}
+	count, err := am.GetAssetHolderCount(assetID)
+	if err != nil {
+		return err
+	}
+	last := count - 1
+	if pos != last {
+		lastID, _, err := am.getUint64(assetHolderKey(assetID, last))
+		if err != nil {
+			return err
+		}
+		if err := am.putUint64(assetHolderKey(assetID, pos), lastID); err != nil {
+			return err
+		}
+		if err := am.putUint64(assetHolderPosKey(assetID, lastID), pos); err != nil {
+			return err
+		}
+	}
+	am.sdb.Delete(acctManagerName, assetHolderKey(assetID, last))
+	am.sdb.Delete(acctManagerName, assetHolderPosKey(assetID, accountID))
+	if last == 0 {
+		am.sdb.Delete(acctManagerName, assetHolderCountPrefix+strconv.FormatUint(assetID, 10))
+		return nil
+	}
+	return am.putUint64(assetHolderCountPrefix+strconv.FormatUint(assetID, 10), last)
+}
+
+//updateAssetHolder index the account by the balance change of the asset
+func (am *AccountManager) updateAssetHolder(assetID uint64, accountID uint64, old *big.Int, amount *big.Int) error {
+	switch {
+	case old.Sign() == 0 && amount.Sign() > 0:
+		return am.addAssetHolder(assetID, accountID)
+	case old.Sign() > 0 && amount.Sign() == 0:
+		return am.removeAssetHolder(assetID, accountID)
+	}
+	return nil
+}
+
+//GetAssetHolders get the holders of the asset from the cursor, at most limit
+func (am *AccountManager) GetAssetHolders(assetID uint64, cursor uint64, limit uint64) (*AssetHolders, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > MaxAssetHoldersLimit {
+		limit = MaxAssetHoldersLimit
+	}
+	count, err := am.GetAssetHolderCount(assetID)
+	if err != nil {
+		return nil, err
+	}
+	holders := &AssetHolders{Holders: []*AssetHolder{}}
+	pos := cursor
+	for ; pos < count && uint64(len(holders.Holders)) < limit; pos++ {
+		accountID, _, err := am.getUint64(assetHolderKey(assetID, pos))
+		if err != nil {
+			return nil, err
+		}
+		acct, err := am.getAccountMetaById(accountID)
+		if err != nil {
+			return nil, err
+		}
+		if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		balance, _, err := getBalance(am.stateGet, accountID, assetID)
+		if err != nil {
+			return nil, err
+		}
+		holders.Holders = append(holders.Holders, &AssetHolder{AccountID: accountID, Name: acct.GetName(), Balance: balance})
+	}
+	if pos < count {
+		holders.Next = pos
+	}
+	return holders, nil
+}
+
+//HoldersAt get the holders of the asset with their balances at the snapshot of the time, the
+//accounts are visited in id order. The cursor is the account id
+//to start from, at most limit holders and MaxHoldersAtAccounts accounts are visited.
+func (am *AccountManager) HoldersAt(assetID uint64, time uint64, cursor uint64, limit uint64) (*AssetHolders, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > MaxAssetHoldersLimit {
+		limit = MaxAssetHoldersLimit
+	}
+	snapshotState, err := snapshot.NewSnapshotManager(am.sdb).GetSnapshotState(time)
+	if err != nil {
+		return nil, err
+	}
+	get := func(key string) ([]byte, error) {
+		return snapshotState.Get(acctManagerName, key)
+	}
+	b, err := get(counterPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrCounterNotExist
+	}
+	var lastAccountID uint64
+	if err := rlp.DecodeBytes(b, &lastAccountID); err != nil {
+		return nil, err
+	}
+	if cursor <= counterID {
+		cursor = counterID + 1
+	}
+	holders := &AssetHolders{Holders: []*AssetHolder{}}
+	next, err := scanSnapshotHolders(get, assetID, cursor, lastAccountID, MaxHoldersAtAccounts, func(acct *Account, balance *big.Int) bool {
+		holders.Holders = append(holders.Holders, &AssetHolder{AccountID: acct.GetAccountID(), Name: acct.GetName(), Balance: balance})
+		return uint64(len(holders.Holders)) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	if next <= lastAccountID {
+		holders.Next = next
+	}
+	return holders, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestAccountManager_AssetHolders(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient, other := common.Name("escrowsender"), common.Name("escrowrecipient"), common.Name("fractal")
+	holders := func(cursor, limit uint64) *AssetHolders {
+		h, err := am.GetAssetHolders(assetID, cursor, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	names := func(h *AssetHolders) []common.Name {
+		var names []common.Name
+		for _, holder := range h.Holders {
+			names = append(names, holder.Name)
+		}
+		return names
+	}
+
+	if h := holders(0, 0); len(h.Holders) != 1 || h.Holders[0].Name != sender || h.Holders[0].Balance.Int64() != 100 || h.Next != 0 {
+		t.Fatalf("holders after issue %v, next %v", names(h), h.Next)
+	}
+	for _, to := range []common.Name{recipient, other} {
+		if err := am.TransferAsset(sender, to, assetID, big.NewInt(30)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count, _ := am.GetAssetHolderCount(assetID); count != 3 {
+		t.Fatalf("holder count %v, want 3", count)
+	}
+	first := holders(0, 2)
+	if len(first.Holders) != 2 || first.Next != 2 {
+		t.Fatalf("first page %v, next %v", names(first), first.Next)
+	}
+	if second := holders(first.Next, 2); len(second.Holders) != 1 || second.Holders[0].Name != other || second.Next != 0 {
+		t.Fatalf("second page %v, next %v", names(second), second.Next)
+	}
+
+	// the sender empties its balance, the last holder takes its position
+	if err := am.TransferAsset(sender, recipient, assetID, big.NewInt(40)); err != nil {
+		t.Fatal(err)
+	}
+	h := holders(0, 0)
+	if got := names(h); len(got) != 2 || got[0] != other || got[1] != recipient {
+		t.Fatalf("holders after emptied %v", got)
+	}
+	if h.Holders[1].Balance.Int64() != 70 {
+		t.Fatalf("recipient balance %v, want 70", h.Holders[1].Balance)
+	}
+
+	// a holder returning is appended again
+	if err := am.TransferAsset(other, sender, assetID, big.NewInt(30)); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(holders(0, 0)); len(got) != 2 || got[0] != recipient || got[1] != sender {
+		t.Fatalf("holders after return %v", got)
+	}
+	if _, err := am.GetAssetHolders(assetID+1, 0, 0); err == nil {
+		t.Fatal("holders of missing asset should fail")
+	}
+}
+
+func TestAccountManager_HoldersAt(t *testing.T) {
+	db := memdb.NewMemDatabase()
+	cachedb := state.NewDatabase(db)
+	pubkey, _ := GeneragePubKey()
+	sender, recipient := common.Name("holdersender"), common.Name("holderrecipient")
+
+	// the sender holds 100 at the snapshot of 1000, then 70 with 30 held by the recipient at 2000
+	var roots []common.Hash
+	var assetID uint64
+	root := common.Hash{}
+	for i := 0; i < 2; i++ {
+		statedb, _ := state.New(root, cachedb)
+		am, _ := NewAccountManager(statedb)
+		if i == 0 {
+			for _, name := range []string{"fractal", "holdersender", "holderrecipient"} {
+				if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pubkey, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			issue := IssueAsset{AssetName: "holdercoin", Symbol: "hdc", Amount: big.NewInt(0), Owner: sender, UpperLimit: big.NewInt(0)}
+			var err error
+			if assetID, err = am.IssueAsset(sender, issue, 0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := am.AddAccountBalanceByID(sender, assetID, big.NewInt(100)); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := am.TransferAsset(sender, recipient, assetID, big.NewInt(30)); err != nil {
+			t.Fatal(err)
+		}
+		batch := db.NewBatch()
+		var err error
+		if root, err = statedb.Commit(batch, common.Hash{}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := cachedb.TrieDB().Commit(root, false); err != nil {
+			t.Fatal(err)
+		}
+		batch.Write()
+		roots = append(roots, root)
+	}
+
+	statedb, _ := state.New(root, cachedb)
+	snapshotManager := snapshot.NewSnapshotManager(statedb)
+	for i, root := range roots {
+		block := types.SnapshotBlock{Number: uint64(i + 1), BlockHash: common.Hash{byte(i)}}
+		rawdb.WriteSnapshot(db, block, types.SnapshotInfo{Root: root})
+		if err := snapshotManager.SetSnapshot(uint64(i+1)*1000, snapshot.BlockInfo{Number: block.Number, BlockHash: block.BlockHash, Timestamp: uint64(i) * 1000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	am, _ := NewAccountManager(statedb)
+
+	holders, err := am.HoldersAt(assetID, 1000, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders.Holders) != 1 || holders.Holders[0].Name != sender || holders.Holders[0].Balance.Int64() != 100 || holders.Next != 0 {
+		t.Fatalf("holders at 1000 %v", holders)
+	}
+
+	holders, err = am.HoldersAt(assetID, 2000, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders.Holders) != 1 || holders.Holders[0].Name != sender || holders.Holders[0].Balance.Int64() != 70 || holders.Next == 0 {
+		t.Fatalf("first page at 2000 %v", holders)
+	}
+	holders, err = am.HoldersAt(assetID, 2000, holders.Next, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders.Holders) != 1 || holders.Holders[0].Name != recipient || holders.Holders[0].Balance.Int64() != 30 || holders.Next != 0 {
+		t.Fatalf("last page at 2000 %v", holders)
+	}
+
+	if _, err := am.HoldersAt(assetID, 1500, 0, 0); err == nil {
+		t.Fatal("holders at a missing snapshot")
+	}
+}
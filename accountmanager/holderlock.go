@@ -0,0 +1,198 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// The asset owner may lock a part of the balance of a holder until a block, the
+// locked balance can not be transferred. The locks of an account are kept in one
+// list, a matured lock keeps the balance locked until it is released by
+// ReleaseHolderBalance, which anyone may send for the holder.
+var acctHolderLockPrefix = "acctHolderLock"
+
+// MaxHolderLocks max balance locks of an account
+const MaxHolderLocks = 16
+
+// LockHolderBalanceAction the asset owner lock the balance of the holder until the unlock number
+type LockHolderBalanceAction struct {
+	AssetID      uint64      `json:"assetId,omitempty"`
+	Holder       common.Name `json:"holder"`
+	Amount       *big.Int    `json:"amount"`
+	UnlockNumber uint64      `json:"unlockNumber"`
+}
+
+// ReleaseHolderBalanceAction release the matured balance locks of the holder
+type ReleaseHolderBalanceAction struct {
+	Holder common.Name `json:"holder"`
+}
+
+// HolderLock the balance of the asset locked by the asset owner
+type HolderLock struct {
+	AssetID      uint64   `json:"assetID"`
+	Amount       *big.Int `json:"amount"`
+	Number       uint64   `json:"number"`
+	UnlockNumber uint64   `json:"unlockNumber"`
+}
+
+func holderLockKey(accountID uint64) string {
+	return acctHolderLockPrefix + strconv.FormatUint(accountID, 10)
+}
+
+func (am *AccountManager) getHolderLocks(accountID uint64) ([]*HolderLock, error) {
+	b, err := am.stateGet(holderLockKey(accountID))
+	if err != nil {
+		return nil, err
+	}
+	locks := []*HolderLock{}
+	if len(b) == 0 {
+		return locks, nil
+	}
+	if err := rlp.DecodeBytes(b, &locks); err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+func (am *AccountManager) setHolderLocks(accountID uint64, locks []*HolderLock) error {
+	if len(locks) == 0 {
+		am.sdb.Delete(acctManagerName, holderLockKey(accountID))
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(locks)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, holderLockKey(accountID), b)
+	return nil
+}
+
+//lockedBalance get the balance of the asset locked of the account
+func (am *AccountManager) lockedBalance(accountID uint64, assetID uint64) (*big.Int, error) {
+	locks, err := am.getHolderLocks(accountID)
+	if err != nil {
+		return nil, err
+	}
+	locked := big.NewInt(0)
+	for _, lock := range locks {
+		if lock.AssetID == assetID {
+			locked.Add(locked, lock.Amount)
+		}
+	}
+	return locked, nil
+}
+
+//rescaleHolderLocks convert the balance locks of the asset of the account to the decimals,
+//the locked amounts are truncated as the balances are
+func (am *AccountManager) rescaleHolderLocks(accountID uint64, assetID uint64, fromDecimals uint64, toDecimals uint64) error {
+	locks, err := am.getHolderLocks(accountID)
+	if err != nil {
+		return err
+	}
+	rescaled := false
+	for _, lock := range locks {
+		if lock.AssetID == assetID {
+			lock.Amount, _ = ConvertAssetAmount(lock.Amount, fromDecimals, toDecimals)
+			rescaled = true
+		}
+	}
+	if !rescaled {
+		return nil
+	}
+	return am.setHolderLocks(accountID, locks)
+}
+
+//GetHolderLocks get the balance locks of the account
+func (am *AccountManager) GetHolderLocks(accountName common.Name) ([]*HolderLock, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.getHolderLocks(accountID)
+}
+
+//LockHolderBalance lock the balance of the holder, the balance locked of the asset can not exceed the balance of the holder
+func (am *AccountManager) LockHolderBalance(number uint64, action *LockHolderBalanceAction) error {
+	if action.Amount == nil || action.Amount.Sign() <= 0 || action.UnlockNumber <= number {
+		return ErrHolderLockInvalid
+	}
+	acct, err := am.getAccountMetaByName(action.Holder)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	locks, err := am.getHolderLocks(acct.GetAccountID())
+	if err != nil {
+		return err
+	}
+	if len(locks) >= MaxHolderLocks {
+		return ErrHolderLocksFull
+	}
+	balance, err := am.accountBalance(acct, action.AssetID)
+	if err != nil {
+		return err
+	}
+	locked, err := am.lockedBalance(acct.GetAccountID(), action.AssetID)
+	if err != nil {
+		return err
+	}
+	if locked.Add(locked, action.Amount).Cmp(balance) > 0 {
+		return ErrInsufficientBalance
+	}
+	locks = append(locks, &HolderLock{
+		AssetID:      action.AssetID,
+		Amount:       new(big.Int).Set(action.Amount),
+		Number:       number,
+		UnlockNumber: action.UnlockNumber,
+	})
+	return am.setHolderLocks(acct.GetAccountID(), locks)
+}
+
+//ReleaseHolderBalance release the matured balance locks of the holder
+func (am *AccountManager) ReleaseHolderBalance(number uint64, action *ReleaseHolderBalanceAction) error {
+	accountID, err := am.GetAccountIDByName(action.Holder)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	locks, err := am.getHolderLocks(accountID)
+	if err != nil {
+		return err
+	}
+	remain := make([]*HolderLock, 0, len(locks))
+	for _, lock := range locks {
+		if number < lock.UnlockNumber {
+			remain = append(remain, lock)
+		}
+	}
+	if len(remain) == len(locks) {
+		return ErrHolderLockNotMatured
+	}
+	return am.setHolderLocks(accountID, remain)
+}
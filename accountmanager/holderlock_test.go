@@ -0,0 +1,86 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_LockHolderBalance(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	owner, holder := common.Name("escrowsender"), common.Name("escrowrecipient")
+	lock := func(from common.Name, amount int64, unlock uint64) error {
+		payload, err := rlp.EncodeToBytes(&LockHolderBalanceAction{AssetID: assetID, Holder: holder, Amount: big.NewInt(amount), UnlockNumber: unlock})
+		if err != nil {
+			return err
+		}
+		action := types.NewAction(types.LockHolderBalance, from, common.Name(params.DefaultChainconfig.AssetName), 0, assetID, 0, big.NewInt(0), payload, nil)
+		_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: 1})
+		return err
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lock(holder, 30, 10); err == nil {
+		t.Fatal("lock by non owner should fail")
+	}
+	if err := lock(owner, 30, 1); err != ErrHolderLockInvalid {
+		t.Fatalf("lock unlocked already err %v", err)
+	}
+	if err := lock(owner, 51, 10); err != ErrInsufficientBalance {
+		t.Fatalf("lock over balance err %v", err)
+	}
+	if err := lock(owner, 30, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock(owner, 10, 20); err != nil {
+		t.Fatal(err)
+	}
+	if locks, err := am.GetHolderLocks(holder); err != nil || len(locks) != 2 || locks[0].Amount.Int64() != 30 || locks[1].UnlockNumber != 20 {
+		t.Fatalf("holder locks %v, %v", locks, err)
+	}
+
+	if err := am.TransferAsset(holder, owner, assetID, big.NewInt(11)); err != ErrBalanceLocked {
+		t.Fatalf("transfer locked balance err %v", err)
+	}
+	if err := am.TransferAsset(holder, owner, assetID, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := processEscrowAction(am, types.ReleaseHolderBalance, owner, assetID, big.NewInt(0), 9, &ReleaseHolderBalanceAction{Holder: holder}); err != ErrHolderLockNotMatured {
+		t.Fatalf("release before matured err %v", err)
+	}
+	if err := processEscrowAction(am, types.ReleaseHolderBalance, owner, assetID, big.NewInt(0), 10, &ReleaseHolderBalanceAction{Holder: holder}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(holder, owner, assetID, big.NewInt(30)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(holder, owner, assetID, big.NewInt(1)); err != ErrBalanceLocked {
+		t.Fatalf("transfer remaining locked balance err %v", err)
+	}
+	if locks, err := am.GetHolderLocks(holder); err != nil || len(locks) != 1 || locks[0].Amount.Int64() != 10 {
+		t.Fatalf("holder locks after released %v, %v", locks, err)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var acctInheritancePrefix = "acctInheritance"
+
+// SetInheritanceAction set the heir of the sender account, an empty heir
+// removes the policy.
+type SetInheritanceAction struct {
+	Heir             common.Name `json:"heir,omitempty"`
+	InactivityBlocks uint64      `json:"inactivityBlocks,omitempty"`
+	ChallengeBlocks  uint64      `json:"challengeBlocks,omitempty"`
+}
+
+// ClaimInheritanceAction start or finish the inheritance claim of the account.
+type ClaimInheritanceAction struct {
+	Account common.Name `json:"account,omitempty"`
+}
+
+// InheritancePolicy dead man's switch of the account. The heir may start a
+// claim after the account is inactive for InactivityBlocks, any action of the
+// account within the next ChallengeBlocks cancels the claim, otherwise the
+// heir takes the control of the account. The challenge lasts at least the
+// author update delay of the account, as the hand over is an author update.
+type InheritancePolicy struct {
+	Heir             common.Name `json:"heir"`
+	InactivityBlocks uint64      `json:"inactivityBlocks"`
+	ChallengeBlocks  uint64      `json:"challengeBlocks"`
+	LastActive       uint64      `json:"lastActive"`
+	ClaimNumber      uint64      `json:"claimNumber"`
+}
+
+//GetInheritancePolicy get the inheritance policy of the account
+func (am *AccountManager) GetInheritancePolicy(accountName common.Name) (*InheritancePolicy, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.getInheritancePolicy(accountID)
+}
+
+func (am *AccountManager) getInheritancePolicy(accountID uint64) (*InheritancePolicy, error) {
+	b, err := am.sdb.Get(acctManagerName, acctInheritancePrefix+strconv.FormatUint(accountID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrInheritanceNotExist
+	}
+	var policy InheritancePolicy
+	if err := rlp.DecodeBytes(b, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (am *AccountManager) setInheritancePolicy(accountID uint64, policy *InheritancePolicy) error {
+	b, err := rlp.EncodeToBytes(policy)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctInheritancePrefix+strconv.FormatUint(accountID, 10), b)
+	return nil
+}
+
+//SetInheritance set or remove the inheritance policy of the account
+func (am *AccountManager) SetInheritance(accountName common.Name, number uint64, action *SetInheritanceAction) error {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	if len(action.Heir.String()) == 0 {
+		am.sdb.Delete(acctManagerName, acctInheritancePrefix+strconv.FormatUint(accountID, 10))
+		return nil
+	}
+	if action.Heir == accountName || action.InactivityBlocks == 0 || action.ChallengeBlocks == 0 {
+		return ErrInheritanceInvalid
+	}
+	heir, err := am.getAccountMetaByName(action.Heir)
+	if err != nil {
+		return err
+	}
+	if heir == nil {
+		return ErrAccountNotExist
+	}
+	return am.setInheritancePolicy(accountID, &InheritancePolicy{
+		Heir:             action.Heir,
+		InactivityBlocks: action.InactivityBlocks,
+		ChallengeBlocks:  action.ChallengeBlocks,
+		LastActive:       number,
+	})
+}
+
+//TouchInheritance record the activity of the account, cancel the pending inheritance claim
+func (am *AccountManager) TouchInheritance(accountName common.Name, number uint64) error {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil || accountID == 0 {
+		return err
+	}
+	policy, err := am.getInheritancePolicy(accountID)
+	if err == ErrInheritanceNotExist {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if policy.LastActive == number && policy.ClaimNumber == 0 {
+		return nil
+	}
+	policy.LastActive = number
+	policy.ClaimNumber = 0
+	return am.setInheritancePolicy(accountID, policy)
+}
+
+//ClaimInheritance start the claim of the inactive account, or hand the account over to the heir after the challenge window
+func (am *AccountManager) ClaimInheritance(heirName common.Name, number uint64, action *ClaimInheritanceAction) error {
+	acct, err := am.getAccountMetaByName(action.Account)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	if acct.IsDestroyed() {
+		return ErrAccountIsDestroy
+	}
+	policy, err := am.getInheritancePolicy(acct.GetAccountID())
+	if err != nil {
+		return err
+	}
+	if policy.Heir != heirName {
+		return ErrInheritanceNotHeir
+	}
+
+	if policy.ClaimNumber == 0 {
+		if number < policy.LastActive+policy.InactivityBlocks {
+			return ErrAccountStillActive
+		}
+		policy.ClaimNumber = number
+		return am.setInheritancePolicy(acct.GetAccountID(), policy)
+	}
+	challenge, err := am.getAuthorDelay(acct.GetAccountID())
+	if err != nil {
+		return err
+	}
+	if challenge < policy.ChallengeBlocks {
+		challenge = policy.ChallengeBlocks
+	}
+	if number < policy.ClaimNumber+challenge {
+		return ErrInheritanceInChallenge
+	}
+
+	acct.Authors = []*common.Author{common.NewAuthor(heirName, 1)}
+	acct.SetThreshold(1)
+	acct.SetUpdateAuthorThreshold(1)
+	acct.SetAuthorVersion()
+	if err := am.SetAccount(acct); err != nil {
+		return err
+	}
+	am.sdb.Delete(acctManagerName, acctInheritancePrefix+strconv.FormatUint(acct.GetAccountID(), 10))
+	am.deletePendingAuthorUpdate(acct.GetAccountID())
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_ClaimInheritance(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	owner, heir := common.Name("escrowsender"), common.Name("escrowrecipient")
+	if err := processEscrowAction(am, types.SetInheritance, owner, 0, big.NewInt(0), 1, &SetInheritanceAction{Heir: owner, InactivityBlocks: 10, ChallengeBlocks: 5}); err == nil {
+		t.Error("SetInheritance want invalid heir err")
+	}
+	if err := processEscrowAction(am, types.SetInheritance, owner, 0, big.NewInt(0), 1, &SetInheritanceAction{Heir: heir, InactivityBlocks: 10, ChallengeBlocks: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	claim := func(number uint64) error {
+		return processEscrowAction(am, types.ClaimInheritance, heir, 0, big.NewInt(0), number, &ClaimInheritanceAction{Account: owner})
+	}
+	if err := processEscrowAction(am, types.ClaimInheritance, owner, 0, big.NewInt(0), 11, &ClaimInheritanceAction{Account: owner}); err != ErrInheritanceNotHeir {
+		t.Errorf("ClaimInheritance err = %v, want %v", err, ErrInheritanceNotHeir)
+	}
+	if err := claim(10); err != ErrAccountStillActive {
+		t.Errorf("ClaimInheritance err = %v, want %v", err, ErrAccountStillActive)
+	}
+	if err := claim(11); err != nil {
+		t.Fatal(err)
+	}
+	// the owner is alive, the claim is canceled
+	if err := am.TouchInheritance(owner, 12); err != nil {
+		t.Fatal(err)
+	}
+	if policy, _ := am.GetInheritancePolicy(owner); policy.ClaimNumber != 0 || policy.LastActive != 12 {
+		t.Errorf("claim not canceled %+v", policy)
+	}
+	if err := claim(21); err != ErrAccountStillActive {
+		t.Errorf("ClaimInheritance err = %v, want %v", err, ErrAccountStillActive)
+	}
+	if err := claim(22); err != nil {
+		t.Fatal(err)
+	}
+	if err := claim(26); err != ErrInheritanceInChallenge {
+		t.Errorf("ClaimInheritance err = %v, want %v", err, ErrInheritanceInChallenge)
+	}
+	// the author update delay of the account extends the challenge
+	acctID, _ := am.GetAccountIDByName(owner)
+	if err := am.setAuthorDelay(acctID, 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := claim(29); err != ErrInheritanceInChallenge {
+		t.Errorf("ClaimInheritance err = %v, want %v", err, ErrInheritanceInChallenge)
+	}
+	if err := claim(30); err != nil {
+		t.Fatal(err)
+	}
+
+	acct, _ := am.GetAccountByName(owner)
+	if len(acct.Authors) != 1 || acct.Authors[0].Owner.String() != heir.String() || acct.GetThreshold() != 1 {
+		t.Errorf("account authors not handed over %v", acct.Authors)
+	}
+	if _, err := am.GetInheritancePolicy(owner); err != ErrInheritanceNotExist {
+		t.Errorf("GetInheritancePolicy err = %v, want %v", err, ErrInheritanceNotExist)
+	}
+}
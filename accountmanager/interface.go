@@ -59,7 +59,7 @@ type IAccountManager interface {
 	AccountIsExist(accountName common.Name) (bool, error)
 	AccountIsEmpty(accountName common.Name) (bool, error)
 	CreateAccount(accountName common.Name, pubkey common.PubKey) error
-	DeleteAccountByName(accountName common.Name) error
+	DeleteAccountByName(accountName common.Name, number uint64) error
 	GetAccountByName(accountName common.Name) (*Account, error)
 	SetAccount(acct *Account) error
 	//sign
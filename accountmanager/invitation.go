@@ -0,0 +1,223 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var invitationPrefix = "invitation"
+
+// MaxInvitationCodes max invitations created by an action
+const MaxInvitationCodes = 64
+
+// CreateInvitationsAction create the invitations of the code hashes, each funded with
+// amount of the action asset. The action value is the amount of all the invitations.
+type CreateInvitationsAction struct {
+	CodeHashes []common.Hash `json:"codeHashes"`
+	Amount     *big.Int      `json:"amount"`
+	Expiry     uint64        `json:"expiry"`
+}
+
+// RedeemInvitationAction create the account by the invitation code, the account receives
+// the starter funds of the invitation.
+type RedeemInvitationAction struct {
+	Code        []byte        `json:"code"`
+	AccountName common.Name   `json:"accountName"`
+	PublicKey   common.PubKey `json:"publicKey"`
+	Description string        `json:"description"`
+}
+
+// RevokeInvitationAction refund the unused invitation to its sponsor.
+type RevokeInvitationAction struct {
+	CodeHash common.Hash `json:"codeHash"`
+}
+
+// Invitation a creation voucher pre-funded by the sponsor. The fee is the account create
+// fee reserved by the sponsor, it is refunded if the redeemed account pays no create fee.
+type Invitation struct {
+	CodeHash   common.Hash `json:"codeHash"`
+	Sponsor    common.Name `json:"sponsor"`
+	AssetID    uint64      `json:"assetID"`
+	Amount     *big.Int    `json:"amount"`
+	FeeAssetID uint64      `json:"feeAssetID"`
+	Fee        *big.Int    `json:"fee"`
+	Number     uint64      `json:"number"`
+	Expiry     uint64      `json:"expiry"`
+}
+
+//GetInvitation get the invitation by the hash of its code
+func (am *AccountManager) GetInvitation(codeHash common.Hash) (*Invitation, error) {
+	b, err := am.sdb.Get(acctManagerName, invitationPrefix+codeHash.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrInvitationNotExist
+	}
+	var invitation Invitation
+	if err := rlp.DecodeBytes(b, &invitation); err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (am *AccountManager) setInvitation(invitation *Invitation) error {
+	b, err := rlp.EncodeToBytes(invitation)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, invitationPrefix+invitation.CodeHash.Hex(), b)
+	return nil
+}
+
+func (am *AccountManager) deleteInvitation(codeHash common.Hash) {
+	am.sdb.Delete(acctManagerName, invitationPrefix+codeHash.Hex())
+}
+
+//CreateInvitations record the invitations of the value already transferred to the account manager,
+//the create fee of each invitation is paid by the sponsor separately
+func (am *AccountManager) CreateInvitations(fromName common.Name, assetID uint64, value *big.Int, number uint64, action *CreateInvitationsAction, feeAssetID uint64, fee *big.Int) error {
+	count := len(action.CodeHashes)
+	if count == 0 || count > MaxInvitationCodes {
+		return ErrInvitationCodes
+	}
+	amount := big.NewInt(0)
+	if action.Amount != nil {
+		amount.Set(action.Amount)
+	}
+	if amount.Sign() < 0 || new(big.Int).Mul(amount, big.NewInt(int64(count))).Cmp(value) != 0 {
+		return ErrAmountValueInvalid
+	}
+	if action.Expiry <= number {
+		return ErrInvitationExpiry
+	}
+	for _, codeHash := range action.CodeHashes {
+		if codeHash == (common.Hash{}) {
+			return ErrHashIsEmpty
+		}
+		if _, err := am.GetInvitation(codeHash); err == nil {
+			return ErrInvitationIsExist
+		} else if err != ErrInvitationNotExist {
+			return err
+		}
+		invitation := &Invitation{
+			CodeHash:   codeHash,
+			Sponsor:    fromName,
+			AssetID:    assetID,
+			Amount:     new(big.Int).Set(amount),
+			FeeAssetID: feeAssetID,
+			Fee:        new(big.Int).Set(fee),
+			Number:     number,
+			Expiry:     action.Expiry,
+		}
+		if err := am.setInvitation(invitation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//RedeemInvitation check the invitation code and remove the invitation, return the invitation to be redeemed
+func (am *AccountManager) RedeemInvitation(number uint64, action *RedeemInvitationAction) (*Invitation, error) {
+	invitation, err := am.GetInvitation(crypto.Keccak256Hash(action.Code))
+	if err != nil {
+		return nil, err
+	}
+	if number > invitation.Expiry {
+		return nil, ErrInvitationExpired
+	}
+	am.deleteInvitation(invitation.CodeHash)
+	return invitation, nil
+}
+
+//RevokeInvitation remove the invitation of the sponsor, return the invitation to be refunded
+func (am *AccountManager) RevokeInvitation(fromName common.Name, action *RevokeInvitationAction) (*Invitation, error) {
+	invitation, err := am.GetInvitation(action.CodeHash)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Sponsor != fromName {
+		return nil, ErrInvitationSponsor
+	}
+	am.deleteInvitation(invitation.CodeHash)
+	return invitation, nil
+}
+
+//redeemInvitation create the account of the invitation on behalf of the sponsor and pay the starter funds
+func (am *AccountManager) redeemInvitation(accountManagerContext *types.AccountManagerContext, redeem *RedeemInvitationAction, fromAccountExtra ...common.Name) ([]*types.InternalAction, error) {
+	config := accountManagerContext.ChainConfig
+	invitation, err := am.RedeemInvitation(accountManagerContext.Number, redeem)
+	if err != nil {
+		return nil, err
+	}
+	if err := am.checkReservedName(invitation.Sponsor, redeem.AccountName, config); err != nil {
+		return nil, err
+	}
+	if IsPremiumAccountName(config.NamePriceCfg, redeem.AccountName) {
+		return nil, ErrAccountNamePremium
+	}
+	if err := am.CreateAccount(invitation.Sponsor, redeem.AccountName, common.Name(""), accountManagerContext.Number, accountManagerContext.CurForkID, redeem.PublicKey, redeem.Description); err != nil {
+		return nil, err
+	}
+
+	// the reserved create fee is kept for a main account, the sub accounts pay no create fee
+	pool := common.Name(config.AccountName)
+	transfers := []*types.Action{}
+	if level, _ := GetAccountNameLevel(redeem.AccountName); level != mainAccount && invitation.Fee.Sign() > 0 {
+		transfers = append(transfers, types.NewAction(types.Transfer, pool, invitation.Sponsor, 0, invitation.FeeAssetID, 0, invitation.Fee, nil, nil))
+	}
+	if invitation.Amount.Sign() > 0 {
+		transfers = append(transfers, types.NewAction(types.Transfer, pool, redeem.AccountName, 0, invitation.AssetID, 0, invitation.Amount, nil, nil))
+	}
+	return am.payFromPool(transfers, fromAccountExtra...)
+}
+
+//revokeInvitation refund the starter funds and the reserved fee of the invitation to the sponsor
+func (am *AccountManager) revokeInvitation(accountManagerContext *types.AccountManagerContext, fromName common.Name, revoke *RevokeInvitationAction, fromAccountExtra ...common.Name) ([]*types.InternalAction, error) {
+	invitation, err := am.RevokeInvitation(fromName, revoke)
+	if err != nil {
+		return nil, err
+	}
+	pool := common.Name(accountManagerContext.ChainConfig.AccountName)
+	transfers := []*types.Action{}
+	if invitation.Fee.Sign() > 0 {
+		transfers = append(transfers, types.NewAction(types.Transfer, pool, invitation.Sponsor, 0, invitation.FeeAssetID, 0, invitation.Fee, nil, nil))
+	}
+	if invitation.Amount.Sign() > 0 {
+		transfers = append(transfers, types.NewAction(types.Transfer, pool, invitation.Sponsor, 0, invitation.AssetID, 0, invitation.Amount, nil, nil))
+	}
+	return am.payFromPool(transfers, fromAccountExtra...)
+}
+
+//payFromPool execute the transfers from the account manager, return their internal actions
+func (am *AccountManager) payFromPool(transfers []*types.Action, fromAccountExtra ...common.Name) ([]*types.InternalAction, error) {
+	var internalActions []*types.InternalAction
+	for _, actionX := range transfers {
+		if err := am.TransferAsset(actionX.Sender(), actionX.Recipient(), actionX.AssetID(), actionX.Value(), fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	}
+	return internalActions, nil
+}
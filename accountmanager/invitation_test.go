@@ -0,0 +1,103 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_Invitation(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	cfg := params.DefaultChainconfig.Copy()
+	cfg.SysTokenID = assetID
+	cfg.NamePriceCfg = &params.PriceConfig{CreateFee: big.NewInt(5)}
+	sponsor, relayer := common.Name("escrowsender"), common.Name("escrowrecipient")
+	balance := func(name common.Name) int64 {
+		b, _ := am.GetAccountBalanceByID(name, assetID, 0)
+		return b.Int64()
+	}
+
+	codes := [][]byte{[]byte("invite code 1"), []byte("invite code 2"), []byte("invite code 3")}
+	create := &CreateInvitationsAction{Amount: big.NewInt(10), Expiry: 10}
+	for _, code := range codes {
+		create.CodeHashes = append(create.CodeHashes, crypto.Keccak256Hash(code))
+	}
+	if err := processPriceAction(am, cfg, types.CreateInvitations, sponsor, big.NewInt(20), 1, create); err != ErrAmountValueInvalid {
+		t.Fatalf("create invitations with wrong value err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.CreateInvitations, sponsor, big.NewInt(30), 1, create); err != nil {
+		t.Fatal(err)
+	}
+	// the starter funds and the create fees are paid by the sponsor
+	if balance(sponsor) != 55 {
+		t.Fatalf("sponsor balance %v, want 55", balance(sponsor))
+	}
+	if err := processPriceAction(am, cfg, types.CreateInvitations, sponsor, big.NewInt(10), 1, &CreateInvitationsAction{CodeHashes: create.CodeHashes[:1], Amount: big.NewInt(10), Expiry: 10}); err != ErrInvitationIsExist {
+		t.Fatalf("create existing invitation err %v", err)
+	}
+
+	pubkey, _ := GeneragePubKey()
+	redeem := &RedeemInvitationAction{Code: codes[0], AccountName: common.Name("invitedplayer"), PublicKey: pubkey}
+	if err := processPriceAction(am, cfg, types.RedeemInvitation, relayer, big.NewInt(0), 2, redeem); err != nil {
+		t.Fatal(err)
+	}
+	if acct, err := am.GetAccountByName(redeem.AccountName); err != nil || acct == nil {
+		t.Fatalf("redeemed account %v, %v", acct, err)
+	}
+	if balance(redeem.AccountName) != 10 || balance(sponsor) != 55 {
+		t.Fatalf("starter funds %v, sponsor balance %v", balance(redeem.AccountName), balance(sponsor))
+	}
+	redeem.AccountName = common.Name("invitedother")
+	if err := processPriceAction(am, cfg, types.RedeemInvitation, relayer, big.NewInt(0), 2, redeem); err != ErrInvitationNotExist {
+		t.Fatalf("redeem used invitation err %v", err)
+	}
+
+	// a sub account of the sponsor pays no create fee, the reserved fee is refunded
+	redeem = &RedeemInvitationAction{Code: codes[1], AccountName: common.Name("escrowsender.bob"), PublicKey: pubkey}
+	if err := processPriceAction(am, cfg, types.RedeemInvitation, relayer, big.NewInt(0), 3, redeem); err != nil {
+		t.Fatal(err)
+	}
+	if balance(redeem.AccountName) != 10 || balance(sponsor) != 60 {
+		t.Fatalf("sub account funds %v, sponsor balance %v", balance(redeem.AccountName), balance(sponsor))
+	}
+
+	revoke := &RevokeInvitationAction{CodeHash: create.CodeHashes[2]}
+	if err := processPriceAction(am, cfg, types.RevokeInvitation, relayer, big.NewInt(0), 4, revoke); err != ErrInvitationSponsor {
+		t.Fatalf("revoke by non sponsor err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.RevokeInvitation, sponsor, big.NewInt(0), 4, revoke); err != nil {
+		t.Fatal(err)
+	}
+	if balance(sponsor) != 75 {
+		t.Fatalf("sponsor balance after revoke %v, want 75", balance(sponsor))
+	}
+
+	expiring := &CreateInvitationsAction{CodeHashes: []common.Hash{crypto.Keccak256Hash([]byte("expiring"))}, Expiry: 5}
+	if err := processPriceAction(am, cfg, types.CreateInvitations, sponsor, big.NewInt(0), 4, expiring); err != nil {
+		t.Fatal(err)
+	}
+	redeem = &RedeemInvitationAction{Code: []byte("expiring"), AccountName: common.Name("lateplayer"), PublicKey: pubkey}
+	if err := processPriceAction(am, cfg, types.RedeemInvitation, relayer, big.NewInt(0), 6, redeem); err != ErrInvitationExpired {
+		t.Fatalf("redeem expired invitation err %v", err)
+	}
+}
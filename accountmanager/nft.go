@@ -0,0 +1,111 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// IssueNFTAction mint the token of the non-fungible asset to the recipient.
+type IssueNFTAction struct {
+	AssetID uint64      `json:"assetId"`
+	TokenID uint64      `json:"tokenId"`
+	To      common.Name `json:"to"`
+	URI     string      `json:"uri"`
+}
+
+// TransferNFTAction transfer the token of the sender to the recipient.
+type TransferNFTAction struct {
+	AssetID uint64      `json:"assetId"`
+	TokenID uint64      `json:"tokenId"`
+	To      common.Name `json:"to"`
+}
+
+// BurnNFTAction burn the token of the sender.
+type BurnNFTAction struct {
+	AssetID uint64 `json:"assetId"`
+	TokenID uint64 `json:"tokenId"`
+}
+
+//GetNFToken get the non-fungible token of the asset
+func (am *AccountManager) GetNFToken(assetID uint64, tokenID uint64) (*asset.NFToken, error) {
+	return am.ast.GetNFToken(assetID, tokenID)
+}
+
+//issueNFT mint the token by the asset owner, the token is issued to the asset manager then transferred to the recipient
+func (am *AccountManager) issueNFT(accountManagerContext *types.AccountManagerContext, fromName common.Name, issue *IssueNFTAction, fromAccountExtra ...common.Name) ([]*types.InternalAction, error) {
+	if err := am.ast.CheckOwner(fromName, issue.AssetID); err != nil {
+		return nil, err
+	}
+	if _, err := am.GetAccountByName(issue.To); err != nil {
+		return nil, err
+	}
+	if err := am.ast.IssueNFToken(&asset.NFToken{AssetID: issue.AssetID, TokenID: issue.TokenID, Owner: issue.To, URI: issue.URI}); err != nil {
+		return nil, err
+	}
+	assetName := common.Name(accountManagerContext.ChainConfig.AssetName)
+	one := big.NewInt(1)
+	if err := am.AddAccountBalanceByID(assetName, issue.AssetID, one); err != nil {
+		return nil, err
+	}
+	fromAccountExtra = append(fromAccountExtra, fromName)
+	if err := am.transferAsset(assetName, issue.To, issue.AssetID, one, fromAccountExtra...); err != nil {
+		return nil, err
+	}
+
+	var internalActions []*types.InternalAction
+	for _, actionX := range []*types.Action{
+		types.NewAction(types.Transfer, common.Name(""), assetName, 0, issue.AssetID, 0, one, nil, nil),
+		types.NewAction(types.Transfer, assetName, issue.To, 0, issue.AssetID, 0, one, nil, nil),
+	} {
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	}
+	return internalActions, nil
+}
+
+//transferNFT transfer the token and one unit of the asset balance from its owner
+func (am *AccountManager) transferNFT(fromName common.Name, transfer *TransferNFTAction, fromAccountExtra ...common.Name) ([]*types.InternalAction, error) {
+	if err := am.ast.TransferNFToken(transfer.AssetID, transfer.TokenID, fromName, transfer.To); err != nil {
+		return nil, err
+	}
+	one := big.NewInt(1)
+	if err := am.transferAsset(fromName, transfer.To, transfer.AssetID, one, fromAccountExtra...); err != nil {
+		return nil, err
+	}
+	actionX := types.NewAction(types.Transfer, fromName, transfer.To, 0, transfer.AssetID, 0, one, nil, nil)
+	internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+	return []*types.InternalAction{internalAction}, nil
+}
+
+//burnNFT burn the token and one unit of the asset balance of its owner
+func (am *AccountManager) burnNFT(fromName common.Name, burn *BurnNFTAction) ([]*types.InternalAction, error) {
+	if err := am.ast.BurnNFToken(burn.AssetID, burn.TokenID, fromName); err != nil {
+		return nil, err
+	}
+	one := big.NewInt(1)
+	if err := am.SubAccountBalanceByID(fromName, burn.AssetID, one); err != nil {
+		return nil, err
+	}
+	actionX := types.NewAction(types.Transfer, fromName, common.Name(""), 0, burn.AssetID, 0, one, nil, nil)
+	internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+	return []*types.InternalAction{internalAction}, nil
+}
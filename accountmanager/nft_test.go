@@ -0,0 +1,86 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_NFT(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	pubkey, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name(params.DefaultChainconfig.AssetName), common.Name(""), 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	issue := IssueAsset{AssetName: "escrownft", Symbol: "enft", Amount: big.NewInt(0), Owner: sender, UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(sender, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mint := &IssueNFTAction{AssetID: assetID, TokenID: 7, To: sender, URI: "ipfs://escrownft/7"}
+	if err := processAssetAction(am, types.IssueNFT, recipient, 0, big.NewInt(0), mint); err == nil {
+		t.Fatal("issue nft by non owner should fail")
+	}
+	if err := processAssetAction(am, types.IssueNFT, sender, 0, big.NewInt(0), mint); err != nil {
+		t.Fatal(err)
+	}
+	if err := processAssetAction(am, types.IssueNFT, sender, 0, big.NewInt(0), mint); err == nil {
+		t.Fatal("issue existing nft should fail")
+	}
+	if balance, _ := am.GetAccountBalanceByID(sender, assetID, 0); balance.Int64() != 1 {
+		t.Fatalf("owner balance %v, want 1", balance)
+	}
+	if err := am.TransferAsset(sender, recipient, assetID, big.NewInt(1)); err != ErrNFTransfer {
+		t.Fatalf("fungible transfer of nft err %v", err)
+	}
+
+	transfer := &TransferNFTAction{AssetID: assetID, TokenID: 7, To: recipient}
+	if err := processAssetAction(am, types.TransferNFT, sender, 0, big.NewInt(0), transfer); err != nil {
+		t.Fatal(err)
+	}
+	token, err := am.GetNFToken(assetID, 7)
+	if err != nil || token.Owner != recipient || token.URI != mint.URI {
+		t.Fatalf("GetNFToken = %v, %v", token, err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, assetID, 0); balance.Int64() != 1 {
+		t.Fatalf("recipient balance %v, want 1", balance)
+	}
+	if err := processAssetAction(am, types.TransferNFT, sender, 0, big.NewInt(0), transfer); err == nil {
+		t.Fatal("transfer nft by non token owner should fail")
+	}
+
+	burn := &BurnNFTAction{AssetID: assetID, TokenID: 7}
+	if err := processAssetAction(am, types.BurnNFT, sender, 0, big.NewInt(0), burn); err == nil {
+		t.Fatal("burn nft by non token owner should fail")
+	}
+	if err := processAssetAction(am, types.BurnNFT, recipient, 0, big.NewInt(0), burn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.GetNFToken(assetID, 7); err == nil {
+		t.Fatal("burned nft still exists")
+	}
+	if ast, _ := am.GetAssetInfoByID(assetID); ast.GetAssetAmount().Sign() != 0 {
+		t.Fatalf("asset amount %v after burn, want 0", ast.GetAssetAmount())
+	}
+}
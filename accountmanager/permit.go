@@ -0,0 +1,129 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// A permit transfer moves the asset of the payer under the typed data signed
+// off-chain by its authors, the relayer sends the action and pays the gas so
+// the payer needs no fee asset. The payer may pay the relayer a fee in the
+// transferred asset. A permit is executed once, the hash of an executed permit
+// is recorded with its expiry. The permit signs the decimals of the asset, a
+// redenomination of the asset invalidates the permits signed before it.
+var permitPrefix = "permit"
+
+// PermitTransferAction the transfer authorized by the signatures of the payer
+type PermitTransferAction struct {
+	Payer      common.Name             `json:"payer"`
+	Recipient  common.Name             `json:"recipient"`
+	AssetID    uint64                  `json:"assetId"`
+	Amount     *big.Int                `json:"amount"`
+	Fee        *big.Int                `json:"fee"`      // paid by the payer to the relayer in the asset
+	Decimals   uint64                  `json:"decimals"` // decimals of the asset the amount and the fee are in
+	Relayer    common.Name             `json:"relayer"`  // empty allows any relayer
+	Salt       uint64                  `json:"salt"`     // distinguishes the permits of the same transfer
+	Expiry     uint64                  `json:"expiry"`   // last block number the permit can be executed
+	Signatures []*types.TypedSignature `json:"signatures"`
+}
+
+//TypedData get the typed data of the permit signed by the authors of the payer
+func (p *PermitTransferAction) TypedData(config *params.ChainConfig) *types.TypedData {
+	return &types.TypedData{
+		Types: map[string][]types.TypedField{
+			"PermitTransfer": {
+				{Name: "payer", Type: "name"},
+				{Name: "recipient", Type: "name"},
+				{Name: "assetId", Type: "uint64"},
+				{Name: "amount", Type: "uint256"},
+				{Name: "fee", Type: "uint256"},
+				{Name: "decimals", Type: "uint64"},
+				{Name: "relayer", Type: "name"},
+				{Name: "salt", Type: "uint64"},
+				{Name: "expiry", Type: "uint64"},
+			},
+		},
+		PrimaryType: "PermitTransfer",
+		Domain:      types.TypedDomain{Name: "PermitTransfer", Version: "1", ChainID: config.ChainID, Verifier: common.Name(config.AccountName)},
+		Message: map[string]interface{}{
+			"payer":     p.Payer.String(),
+			"recipient": p.Recipient.String(),
+			"assetId":   p.AssetID,
+			"amount":    p.Amount,
+			"fee":       p.Fee,
+			"decimals":  p.Decimals,
+			"relayer":   p.Relayer.String(),
+			"salt":      p.Salt,
+			"expiry":    p.Expiry,
+		},
+	}
+}
+
+//Sign sign the permit by the key of the payer author at the index
+func (p *PermitTransferAction) Sign(config *params.ChainConfig, priv *ecdsa.PrivateKey, index []uint64) error {
+	sig, err := types.SignTypedData(p.TypedData(config), priv)
+	if err != nil {
+		return err
+	}
+	p.Signatures = append(p.Signatures, &types.TypedSignature{Signature: sig, Index: index})
+	return nil
+}
+
+//PermitTransfer check the permit sent by the relayer and signed by the payer, the amount and the fee are transferred by the caller
+func (am *AccountManager) PermitTransfer(relayer common.Name, number uint64, config *params.ChainConfig, action *PermitTransferAction) error {
+	if action.Amount == nil || action.Amount.Sign() <= 0 || action.Fee == nil || action.Fee.Sign() < 0 {
+		return ErrPermitInvalid
+	}
+	if action.Payer == action.Recipient {
+		return ErrPermitInvalid
+	}
+	if number > action.Expiry {
+		return ErrPermitExpired
+	}
+	if action.Relayer != "" && action.Relayer != relayer {
+		return ErrPermitRelayer
+	}
+	assetObj, err := am.ast.GetAssetObjectById(action.AssetID)
+	if err != nil {
+		return err
+	}
+	if action.Decimals != assetObj.GetDecimals() {
+		return ErrPermitDecimals
+	}
+
+	data := action.TypedData(config)
+	hash, err := data.Hash()
+	if err != nil {
+		return err
+	}
+	key := permitPrefix + hash.String()
+	if b, err := am.stateGet(key); err != nil {
+		return err
+	} else if len(b) != 0 {
+		return ErrPermitExecuted
+	}
+	if err := am.VerifyTypedSignature(action.Payer, config.ChainID, data, types.Transfer, action.Signatures); err != nil {
+		return err
+	}
+	return am.putUint64(key, action.Expiry)
+}
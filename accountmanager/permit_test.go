@@ -0,0 +1,120 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_PermitTransfer(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, key := GeneragePubKey()
+	otherPubkey, otherKey := GeneragePubKey()
+	for _, name := range []string{"fractal", "fractal.account", "permitpayer", "permitrecipient"} {
+		if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatalf("create account %s err %v", name, err)
+		}
+	}
+	if err := am.CreateAccount(common.Name("fractal"), common.Name("permitrelayer"), common.Name(""), 0, 0, otherPubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	issue := IssueAsset{AssetName: "permitcoin", Symbol: "pmt", Amount: big.NewInt(0), Owner: common.Name("permitpayer"), UpperLimit: big.NewInt(0)}
+	assetID, err := am.IssueAsset(common.Name("permitpayer"), issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(common.Name("permitpayer"), assetID, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	config := params.DefaultChainconfig
+	newPermit := func(relayer common.Name, expiry uint64) *PermitTransferAction {
+		return &PermitTransferAction{
+			Payer:     common.Name("permitpayer"),
+			Recipient: common.Name("permitrecipient"),
+			AssetID:   assetID,
+			Amount:    big.NewInt(30),
+			Fee:       big.NewInt(2),
+			Relayer:   relayer,
+			Expiry:    expiry,
+		}
+	}
+
+	// the relayer pays no asset, the payer pays the amount and the fee
+	permit := newPermit(common.Name("permitrelayer"), 10)
+	if err := permit.Sign(config, key, []uint64{0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.PermitTransfer, common.Name("permitrelayer"), 0, big.NewInt(0), 5, permit); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int64{"permitpayer": 68, "permitrecipient": 30, "permitrelayer": 2} {
+		balance, err := am.GetAccountBalanceByID(common.Name(name), assetID, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("%s balance %v, want %v", name, balance, want)
+		}
+	}
+	if err := processEscrowAction(am, types.PermitTransfer, common.Name("permitrelayer"), 0, big.NewInt(0), 6, permit); err != ErrPermitExecuted {
+		t.Fatalf("replayed permit err %v", err)
+	}
+
+	expired := newPermit(common.Name(""), 4)
+	expired.Sign(config, key, []uint64{0})
+	unsigned := newPermit(common.Name(""), 10)
+	unsigned.Salt = 1
+	unsigned.Sign(config, otherKey, []uint64{0})
+	tampered := newPermit(common.Name(""), 10)
+	tampered.Salt = 2
+	tampered.Sign(config, key, []uint64{0})
+	tampered.Amount = big.NewInt(60)
+	other := newPermit(common.Name("permitrecipient"), 10)
+	other.Salt = 3
+	other.Sign(config, key, []uint64{0})
+	redenominated := newPermit(common.Name(""), 10)
+	redenominated.Salt = 4
+	redenominated.Decimals = 2
+	redenominated.Sign(config, key, []uint64{0})
+
+	tests := []struct {
+		name   string
+		permit *PermitTransferAction
+		err    error
+	}{
+		{"expired", expired, ErrPermitExpired},
+		{"otherrelayer", other, ErrPermitRelayer},
+		{"decimals", redenominated, ErrPermitDecimals},
+		{"otherkey", unsigned, nil},
+		{"tampered", tampered, nil},
+	}
+	for _, tt := range tests {
+		err := processEscrowAction(am, types.PermitTransfer, common.Name("permitrelayer"), 0, big.NewInt(0), 5, tt.permit)
+		if err == nil || (tt.err != nil && err != tt.err) {
+			t.Errorf("%s: err %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
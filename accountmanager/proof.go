@@ -0,0 +1,145 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// MaxProofAssets max asset balances proved by an account proof
+const MaxProofAssets = 256
+
+// AccountProof the merkle proofs of the account against the state root. The name
+// proof proves the account id of the name, the account proof proves the account
+// record holding the nonce and the code hash, the balance proofs prove the
+// balances of the assets. The proofs are checked by VerifyAccountProof.
+type AccountProof struct {
+	Name         common.Name     `json:"name"`
+	AccountID    uint64          `json:"accountID"`
+	Nonce        uint64          `json:"nonce"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	NameProof    []hexutil.Bytes `json:"nameProof"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	BalanceProof []*BalanceProof `json:"balanceProof"`
+}
+
+// BalanceProof the merkle proof of the balance of the asset, the proof of a zero
+// balance proves its absence
+type BalanceProof struct {
+	AssetID uint64          `json:"assetID"`
+	Balance *big.Int        `json:"balance"`
+	Proof   []hexutil.Bytes `json:"proof"`
+}
+
+//GetAccountProof get the proofs of the account and the balances of the assets, the state must be committed
+//when called please RLock cachedb
+func (am *AccountManager) GetAccountProof(accountName common.Name, assetIDs []uint64) (*AccountProof, error) {
+	if len(assetIDs) > MaxProofAssets {
+		return nil, ErrTooManyProofAssets
+	}
+	acct, err := am.getAccountMetaByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, ErrAccountNotExist
+	}
+	proof := &AccountProof{
+		Name:         accountName,
+		AccountID:    acct.GetAccountID(),
+		Nonce:        acct.GetNonce(),
+		CodeHash:     acct.CodeHash,
+		BalanceProof: make([]*BalanceProof, 0, len(assetIDs)),
+	}
+	if proof.NameProof, err = am.sdb.Prove(acctManagerName, accountNameIDPrefix+accountName.String()); err != nil {
+		return nil, err
+	}
+	if proof.AccountProof, err = am.sdb.Prove(acctManagerName, acctInfoPrefix+strconv.FormatUint(acct.GetAccountID(), 10)); err != nil {
+		return nil, err
+	}
+	for _, assetID := range assetIDs {
+		balance, err := am.accountBalance(acct, assetID)
+		if err != nil && err != ErrAccountAssetNotExist {
+			return nil, err
+		}
+		balanceProof, err := am.sdb.Prove(acctManagerName, balanceKey(acct.GetAccountID(), assetID))
+		if err != nil {
+			return nil, err
+		}
+		proof.BalanceProof = append(proof.BalanceProof, &BalanceProof{AssetID: assetID, Balance: balance, Proof: balanceProof})
+	}
+	return proof, nil
+}
+
+//VerifyAccountProof verify the proofs of the account against the state root, the balances of a legacy account are proved by its account record
+func VerifyAccountProof(root common.Hash, proof *AccountProof) error {
+	b, err := state.VerifyDataProof(root, acctManagerName, accountNameIDPrefix+proof.Name.String(), proof.NameProof)
+	if err != nil {
+		return err
+	}
+	var accountID uint64
+	if len(b) == 0 {
+		return ErrAccountNotExist
+	}
+	if err := rlp.DecodeBytes(b, &accountID); err != nil {
+		return err
+	}
+	if accountID != proof.AccountID {
+		return ErrAccountProofMismatch
+	}
+
+	if b, err = state.VerifyDataProof(root, acctManagerName, acctInfoPrefix+strconv.FormatUint(accountID, 10), proof.AccountProof); err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return ErrAccountNotExist
+	}
+	var acct Account
+	if err := rlp.DecodeBytes(b, &acct); err != nil {
+		return err
+	}
+	if acct.GetName() != proof.Name || acct.GetNonce() != proof.Nonce || acct.CodeHash != proof.CodeHash {
+		return ErrAccountProofMismatch
+	}
+
+	for _, balanceProof := range proof.BalanceProof {
+		balance := big.NewInt(0)
+		if isLegacyAccount(&acct) {
+			balance, _ = acct.GetBalanceByID(balanceProof.AssetID)
+		} else {
+			b, err := state.VerifyDataProof(root, acctManagerName, balanceKey(accountID, balanceProof.AssetID), balanceProof.Proof)
+			if err != nil {
+				return err
+			}
+			if len(b) != 0 {
+				if err := rlp.DecodeBytes(b, balance); err != nil {
+					return err
+				}
+			}
+		}
+		if balanceProof.Balance == nil || balance.Cmp(balanceProof.Balance) != 0 {
+			return ErrAccountProofMismatch
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestAccountManager_AccountProof(t *testing.T) {
+	db := memdb.NewMemDatabase()
+	cachedb := state.NewDatabase(db)
+	statedb, err := state.New(common.Hash{}, cachedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, _ := GeneragePubKey()
+	for _, name := range []string{"fractal", "proofaccount"} {
+		if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := am.AddAccountBalanceByID(common.Name("proofaccount"), 1, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch, common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cachedb.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	batch.Write()
+	if statedb, err = state.New(root, state.NewDatabase(db)); err != nil {
+		t.Fatal(err)
+	}
+	if am, err = NewAccountManager(statedb); err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := am.GetAccountProof(common.Name("proofaccount"), []uint64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof.BalanceProof) != 2 || proof.BalanceProof[0].Balance.Int64() != 100 || proof.BalanceProof[1].Balance.Sign() != 0 {
+		t.Fatalf("balance proofs %v", proof.BalanceProof)
+	}
+	if err := VerifyAccountProof(root, proof); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAccountProof(common.Hash{1}, proof); err == nil {
+		t.Fatal("verify proof against wrong root should fail")
+	}
+	proof.BalanceProof[1].Balance = big.NewInt(1)
+	if err := VerifyAccountProof(root, proof); err != ErrAccountProofMismatch {
+		t.Fatalf("verify forged balance err %v", err)
+	}
+	proof.BalanceProof[1].Balance = big.NewInt(0)
+	proof.Nonce++
+	if err := VerifyAccountProof(root, proof); err != ErrAccountProofMismatch {
+		t.Fatalf("verify forged nonce err %v", err)
+	}
+	if _, err := am.GetAccountProof(common.Name("proofmissing"), nil); err != ErrAccountNotExist {
+		t.Fatalf("proof of missing account err %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+)
+
+//NewReadOnlyAccountManager create an account manager on the state of root, which is opened from the database of db.
+//The getters resolve against root and the mutations return ErrAccountManagerReadOnly.
+func NewReadOnlyAccountManager(db *state.StateDB, root common.Hash) (*AccountManager, error) {
+	if db == nil {
+		return nil, ErrNewAccountErr
+	}
+	if len(acctManagerName) == 0 {
+		log.Error("NewReadOnlyAccountManager error", "name", ErrAccountManagerNotExist, acctManagerName)
+		return nil, ErrAccountManagerNotExist
+	}
+	sdb, err := state.New(root, db.Database())
+	if err != nil {
+		return nil, err
+	}
+	return &AccountManager{
+		sdb:      sdb,
+		ast:      asset.NewAsset(sdb),
+		cache:    make(map[uint64]*accountCacheEntry),
+		readOnly: true,
+	}, nil
+}
+
+//IsReadOnly return true if the account manager refuses mutations
+func (am *AccountManager) IsReadOnly() bool {
+	return am.readOnly
+}
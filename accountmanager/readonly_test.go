@@ -0,0 +1,66 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestReadOnlyAccountManager(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	root, err := am.sdb.Commit(memdb.NewMemDatabase().NewBatch(), common.Hash{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(common.Name("escrowsender"), assetID, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+
+	ro, err := NewReadOnlyAccountManager(am.sdb, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ro.IsReadOnly() {
+		t.Fatal("account manager not read only")
+	}
+	balance, err := ro.GetAccountBalanceByID(common.Name("escrowsender"), assetID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("historical balance mismatch, got %v", balance)
+	}
+	if balance, _ := am.GetAccountBalanceByID(common.Name("escrowsender"), assetID, 0); balance.Cmp(big.NewInt(150)) != 0 {
+		t.Fatalf("current balance mismatch, got %v", balance)
+	}
+
+	if err := ro.AddAccountBalanceByID(common.Name("escrowsender"), assetID, big.NewInt(1)); err != ErrAccountManagerReadOnly {
+		t.Fatalf("add balance err %v", err)
+	}
+	pubkey, _ := GeneragePubKey()
+	if err := ro.CreateAccount(common.Name("fractal"), common.Name("readonlyacct"), common.Name(""), 0, 0, pubkey, ""); err != ErrAccountManagerReadOnly {
+		t.Fatalf("create account err %v", err)
+	}
+	if err := processEscrowAction(ro, types.Transfer, common.Name("escrowsender"), assetID, big.NewInt(1), 2, []byte{}); err != ErrAccountManagerReadOnly {
+		t.Fatalf("process err %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	acctDestroyPrefix     = "acctDestroy"
+	acctArchivePrefix     = "acctArchive"
+	acctNameReclaimBlocks = uint64(0)
+	acctNameReuse         = false
+)
+
+// ArchivedAccount destroyed account which name mapping has been reclaimed.
+// The account object is removed from the current state, it can be read from
+// the snapshot of SnapshotTime.
+type ArchivedAccount struct {
+	AccountID     uint64 `json:"accountID"`
+	DestroyNumber uint64 `json:"destroyNumber"`
+	ReclaimNumber uint64 `json:"reclaimNumber"`
+	SnapshotTime  uint64 `json:"snapshotTime"`
+}
+
+//DestroyAccount destroy the account with empty balances, the name mapping of the
+//destroyed account can be reclaimed after the retention blocks
+func (am *AccountManager) DestroyAccount(accountName common.Name, number uint64) error {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	if acct.IsDestroyed() {
+		return ErrAccountIsDestroy
+	}
+	balances, err := acct.GetAllBalances()
+	if err != nil {
+		return err
+	}
+	for _, balance := range balances {
+		if balance.Sign() > 0 {
+			return ErrAccountBalanceNotZero
+		}
+	}
+	if err := am.DeleteAccountByName(accountName, number); err != nil {
+		return err
+	}
+	acct.SetDestroy()
+	return am.putAccount(acct)
+}
+
+//getAccountDestroyNumber get the block number the account destroyed
+func (am *AccountManager) getAccountDestroyNumber(accountID uint64) (uint64, error) {
+	b, err := am.sdb.Get(acctManagerName, acctDestroyPrefix+strconv.FormatUint(accountID, 10))
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	var number uint64
+	if err := rlp.DecodeBytes(b, &number); err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+//GetArchivedAccounts get all archived accounts of the name, in reclaim order
+func (am *AccountManager) GetArchivedAccounts(accountName common.Name) ([]*ArchivedAccount, error) {
+	b, err := am.sdb.Get(acctManagerName, acctArchivePrefix+accountName.String())
+	if err != nil {
+		return nil, err
+	}
+	var archived []*ArchivedAccount
+	if len(b) == 0 {
+		return archived, nil
+	}
+	if err := rlp.DecodeBytes(b, &archived); err != nil {
+		return nil, err
+	}
+	return archived, nil
+}
+
+//GetArchivedAccount get the archived account object from the snapshot
+func (am *AccountManager) GetArchivedAccount(archived *ArchivedAccount) (*Account, error) {
+	snapshotManager := snapshot.NewSnapshotManager(am.sdb)
+	snapshotState, err := snapshotManager.GetSnapshotState(archived.SnapshotTime)
+	if err != nil {
+		return nil, err
+	}
+	b, err := snapshotState.Get(acctManagerName, acctInfoPrefix+strconv.FormatUint(archived.AccountID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var acct Account
+	if err := rlp.DecodeBytes(b, &acct); err != nil {
+		return nil, err
+	}
+	snapshotGet := func(key string) ([]byte, error) {
+		return snapshotState.Get(acctManagerName, key)
+	}
+	if err := loadBalances(snapshotGet, &acct); err != nil {
+		return nil, err
+	}
+	return &acct, nil
+}
+
+//ReclaimAccountName reclaim the name mapping of the destroyed account after retention blocks
+func (am *AccountManager) ReclaimAccountName(accountName common.Name, number uint64) error {
+	if acctNameReclaimBlocks == 0 {
+		return ErrAccountReclaimDisabled
+	}
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	acct, err := am.GetAccountById(accountID)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	if !acct.IsDestroyed() {
+		return ErrAccountNotDestroy
+	}
+	destroyNumber, err := am.getAccountDestroyNumber(accountID)
+	if err != nil {
+		return err
+	}
+	if number < destroyNumber+acctNameReclaimBlocks {
+		return ErrAccountInRetention
+	}
+
+	archived, err := am.GetArchivedAccounts(accountName)
+	if err != nil {
+		return err
+	}
+	snapshotTime, _ := snapshot.NewSnapshotManager(am.sdb).GetLastSnapshotTime()
+	archived = append(archived, &ArchivedAccount{
+		AccountID:     accountID,
+		DestroyNumber: destroyNumber,
+		ReclaimNumber: number,
+		SnapshotTime:  snapshotTime,
+	})
+	b, err := rlp.EncodeToBytes(archived)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctArchivePrefix+accountName.String(), b)
+	am.sdb.Delete(acctManagerName, accountNameIDPrefix+accountName.String())
+	am.sdb.Delete(acctManagerName, acctInfoPrefix+strconv.FormatUint(accountID, 10))
+	am.deleteCachedAccount(accountID)
+	am.sdb.Delete(acctManagerName, acctDestroyPrefix+strconv.FormatUint(accountID, 10))
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_ReclaimAccountName(t *testing.T) {
+	defer func(blocks uint64, reuse bool) {
+		acctNameReclaimBlocks, acctNameReuse = blocks, reuse
+	}(acctNameReclaimBlocks, acctNameReuse)
+
+	am, assetID := newEscrowTestManager(t)
+	pubkey, _ := GeneragePubKey()
+	name := common.Name("escrowrecipient")
+
+	if err := am.ReclaimAccountName(name, 100); err != ErrAccountReclaimDisabled {
+		t.Errorf("ReclaimAccountName err = %v, want %v", err, ErrAccountReclaimDisabled)
+	}
+	acctNameReclaimBlocks = 10
+	if err := am.ReclaimAccountName(name, 100); err != ErrAccountNotDestroy {
+		t.Errorf("ReclaimAccountName err = %v, want %v", err, ErrAccountNotDestroy)
+	}
+	if err := processEscrowAction(am, types.DeleteAccount, common.Name("escrowsender"), assetID, big.NewInt(0), 5, []byte{}); err != ErrAccountBalanceNotZero {
+		t.Errorf("DeleteAccount err = %v, want %v", err, ErrAccountBalanceNotZero)
+	}
+	if err := processEscrowAction(am, types.DeleteAccount, name, assetID, big.NewInt(0), 5, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(common.Name("escrowsender"), name, assetID, big.NewInt(1)); err != ErrAccountIsDestroy {
+		t.Errorf("TransferAsset err = %v, want %v", err, ErrAccountIsDestroy)
+	}
+	if err := am.CreateAccount(common.Name("fractal"), name, common.Name(""), 20, 0, pubkey, ""); err != ErrAccountIsExist {
+		t.Errorf("CreateAccount err = %v, want %v", err, ErrAccountIsExist)
+	}
+	if err := am.ReclaimAccountName(name, 14); err != ErrAccountInRetention {
+		t.Errorf("ReclaimAccountName err = %v, want %v", err, ErrAccountInRetention)
+	}
+	if err := am.ReclaimAccountName(name, 15); err != nil {
+		t.Fatal(err)
+	}
+	if exist, _ := am.AccountIsExist(name); exist {
+		t.Error("reclaimed account name still exist")
+	}
+	archived, err := am.GetArchivedAccounts(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 1 || archived[0].DestroyNumber != 5 || archived[0].ReclaimNumber != 15 {
+		t.Errorf("archived account mismatch %v", archived)
+	}
+
+	if err := am.CreateAccount(common.Name("fractal"), name, common.Name(""), 20, 0, pubkey, ""); err != ErrNameIsExist {
+		t.Errorf("CreateAccount err = %v, want %v", err, ErrNameIsExist)
+	}
+	acctNameReuse = true
+	if err := am.CreateAccount(common.Name("fractal"), name, common.Name(""), 20, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	acct, err := am.GetAccountByName(name)
+	if err != nil || acct == nil || acct.IsDestroyed() {
+		t.Errorf("recreate account failed %v", err)
+	}
+}
+
+func TestAccountManager_DeleteAccountNotForked(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	setForkID(am.sdb, params.ForkID3)
+	if err := processEscrowAction(am, types.DeleteAccount, common.Name("escrowrecipient"), assetID, big.NewInt(0), 5, []byte{}); err != ErrUnkownTxType {
+		t.Errorf("DeleteAccount err = %v, want %v", err, ErrUnkownTxType)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"strings"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var acctReservedNamesKey = "acctReservedNames"
+
+// ReservedNames the account names and main account name prefixes only the system account can create,
+// the sub accounts of a reserved main account are created by the main account as usual.
+type ReservedNames struct {
+	Names    []string `json:"names"`
+	Prefixes []string `json:"prefixes"`
+}
+
+//IsReserved check the account name is reserved
+func (r *ReservedNames) IsReserved(accountName common.Name) bool {
+	name := accountName.String()
+	for _, reserved := range r.Names {
+		if name == reserved {
+			return true
+		}
+	}
+	if level, err := GetAccountNameLevel(accountName); err != nil || level != mainAccount {
+		return false
+	}
+	for _, prefix := range r.Prefixes {
+		if len(prefix) != 0 && strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+//GetReservedNames get the reserved names updated by the system account, or the chain config ones if never updated
+func (am *AccountManager) GetReservedNames(cfg *params.ChainConfig) (*ReservedNames, error) {
+	b, err := am.sdb.Get(acctManagerName, acctReservedNamesKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		reserved := &ReservedNames{}
+		if cfg.ReservedCfg != nil {
+			reserved.Names = cfg.ReservedCfg.Names
+			reserved.Prefixes = cfg.ReservedCfg.Prefixes
+		}
+		return reserved, nil
+	}
+	var reserved ReservedNames
+	if err := rlp.DecodeBytes(b, &reserved); err != nil {
+		return nil, err
+	}
+	return &reserved, nil
+}
+
+//UpdateReservedNames replace the reserved names, only the system account can update
+func (am *AccountManager) UpdateReservedNames(fromName common.Name, cfg *params.ChainConfig, reserved *ReservedNames) error {
+	if fromName.String() != cfg.SysName {
+		return ErrNotSystemAccount
+	}
+	for _, prefix := range reserved.Prefixes {
+		if len(prefix) == 0 {
+			return ErrReservedNamesInvalid
+		}
+	}
+	b, err := rlp.EncodeToBytes(reserved)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, acctReservedNamesKey, b)
+	return nil
+}
+
+//checkReservedName check the account name can be created by the account
+func (am *AccountManager) checkReservedName(fromName common.Name, accountName common.Name, cfg *params.ChainConfig) error {
+	if fromName.String() == cfg.SysName {
+		return nil
+	}
+	reserved, err := am.GetReservedNames(cfg)
+	if err != nil {
+		return err
+	}
+	if reserved.IsReserved(accountName) {
+		return ErrAccountNameReserved
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_ReservedNames(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	cfg := params.DefaultChainconfig.Copy()
+	cfg.SysTokenID = assetID
+	cfg.SysName = "fractal"
+	cfg.ReservedCfg = &params.ReservedName{Names: []string{"brandname"}, Prefixes: []string{"sysx"}}
+	pubkey, _ := GeneragePubKey()
+	create := func(from, name string) error {
+		return processPriceAction(am, cfg, types.CreateAccount, common.Name(from), big.NewInt(0), 1, &CreateAccountAction{AccountName: common.Name(name), PublicKey: pubkey})
+	}
+
+	if err := create("escrowsender", "brandname"); err != ErrAccountNameReserved {
+		t.Fatalf("create reserved name err %v", err)
+	}
+	if err := create("escrowsender", "sysxaccount"); err != ErrAccountNameReserved {
+		t.Fatalf("create reserved prefix err %v", err)
+	}
+	if err := create("fractal", "brandname"); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("brandname", "brandname.sub"); err != nil {
+		t.Fatal(err)
+	}
+
+	update := &ReservedNames{Names: []string{"othername"}}
+	if err := processPriceAction(am, cfg, types.UpdateReservedNames, common.Name("escrowsender"), big.NewInt(0), 2, update); err != ErrNotSystemAccount {
+		t.Fatalf("update by non system account err %v", err)
+	}
+	if err := processPriceAction(am, cfg, types.UpdateReservedNames, common.Name("fractal"), big.NewInt(0), 2, update); err != nil {
+		t.Fatal(err)
+	}
+	reserved, err := am.GetReservedNames(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reserved.Names) != 1 || reserved.Names[0] != "othername" || len(reserved.Prefixes) != 0 {
+		t.Fatalf("reserved names mismatch %v", reserved)
+	}
+	if err := create("escrowsender", "sysxaccount"); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("escrowsender", "othername"); err != ErrAccountNameReserved {
+		t.Fatalf("create updated reserved name err %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	schedulePrefix        = "schedule"
+	scheduleCounterPrefix = "scheduleCounter"
+	scheduleQueuePrefix   = "scheduleQueue"
+)
+
+// ScheduleAction register the action of the sender executed at the block number,
+// the fee is paid upfront to the account manager.
+type ScheduleAction struct {
+	Number uint64           `json:"number"`
+	Action *types.SubAction `json:"action"`
+}
+
+// CancelScheduledAction cancel the pending scheduled action, the fee is refunded.
+type CancelScheduledAction struct {
+	ScheduleID uint64 `json:"scheduleID"`
+}
+
+// Scheduled the pending scheduled action. It is executed by the first block at
+// or after the number with the execution budget left, the fee is paid to the
+// producer of the block whether the action succeeds or not.
+type Scheduled struct {
+	ScheduleID uint64           `json:"scheduleID"`
+	Sender     common.Name      `json:"sender"`
+	Number     uint64           `json:"number"`
+	Fee        *big.Int         `json:"fee"`
+	Action     *types.SubAction `json:"action"`
+}
+
+// scheduleEntry the queue entry of the scheduled action, ordered by number then id
+type scheduleEntry struct {
+	Number     uint64
+	ScheduleID uint64
+}
+
+//GetScheduledByID get the pending scheduled action
+func (am *AccountManager) GetScheduledByID(id uint64) (*Scheduled, error) {
+	b, err := am.sdb.Get(acctManagerName, schedulePrefix+strconv.FormatUint(id, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrScheduledNotExist
+	}
+	var scheduled Scheduled
+	if err := rlp.DecodeBytes(b, &scheduled); err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func (am *AccountManager) setScheduled(scheduled *Scheduled) error {
+	b, err := rlp.EncodeToBytes(scheduled)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, schedulePrefix+strconv.FormatUint(scheduled.ScheduleID, 10), b)
+	return nil
+}
+
+func (am *AccountManager) getScheduleQueue() ([]scheduleEntry, error) {
+	b, err := am.sdb.Get(acctManagerName, scheduleQueuePrefix)
+	if err != nil {
+		return nil, err
+	}
+	var queue []scheduleEntry
+	if len(b) == 0 {
+		return queue, nil
+	}
+	if err := rlp.DecodeBytes(b, &queue); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+func (am *AccountManager) setScheduleQueue(queue []scheduleEntry) error {
+	if len(queue) == 0 {
+		am.sdb.Delete(acctManagerName, scheduleQueuePrefix)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(queue)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, scheduleQueuePrefix, b)
+	return nil
+}
+
+//Schedule register the action of the sender, the caller charges the fee
+func (am *AccountManager) Schedule(fromName common.Name, number uint64, config *params.ChainConfig, action *ScheduleAction) (*Scheduled, error) {
+	if !config.IsScheduler() {
+		return nil, ErrSchedulerDisabled
+	}
+	if action.Action == nil || !types.ScheduleAllows(action.Action.Type) {
+		return nil, ErrScheduleInvalid
+	}
+	if action.Number <= number {
+		return nil, ErrScheduleNumber
+	}
+	if maxDelay := config.ScheduleCfg.MaxDelay; maxDelay != 0 && action.Number-number > maxDelay {
+		return nil, ErrScheduleNumber
+	}
+	if _, err := am.GetAccountByName(fromName); err != nil {
+		return nil, err
+	}
+
+	var counter uint64
+	if b, err := am.sdb.Get(acctManagerName, scheduleCounterPrefix); err != nil {
+		return nil, err
+	} else if len(b) != 0 {
+		if err := rlp.DecodeBytes(b, &counter); err != nil {
+			return nil, err
+		}
+	}
+	counter = counter + 1
+
+	fee := big.NewInt(0)
+	if config.ScheduleCfg.Fee != nil && config.ScheduleCfg.Fee.Sign() > 0 {
+		fee.Set(config.ScheduleCfg.Fee)
+	}
+	scheduled := &Scheduled{
+		ScheduleID: counter,
+		Sender:     fromName,
+		Number:     action.Number,
+		Fee:        fee,
+		Action:     action.Action,
+	}
+	if scheduled.Action.Amount == nil {
+		scheduled.Action.Amount = big.NewInt(0)
+	}
+	if err := am.setScheduled(scheduled); err != nil {
+		return nil, err
+	}
+	queue, err := am.getScheduleQueue()
+	if err != nil {
+		return nil, err
+	}
+	entry := scheduleEntry{Number: action.Number, ScheduleID: counter}
+	i := sort.Search(len(queue), func(i int) bool { return queue[i].Number > entry.Number })
+	queue = append(queue, scheduleEntry{})
+	copy(queue[i+1:], queue[i:])
+	queue[i] = entry
+	if err := am.setScheduleQueue(queue); err != nil {
+		return nil, err
+	}
+	b, err := rlp.EncodeToBytes(&counter)
+	if err != nil {
+		return nil, err
+	}
+	am.sdb.Put(acctManagerName, scheduleCounterPrefix, b)
+	return scheduled, nil
+}
+
+//CancelScheduled remove the pending scheduled action of the sender, the caller refunds the fee
+func (am *AccountManager) CancelScheduled(fromName common.Name, action *CancelScheduledAction) (*Scheduled, error) {
+	scheduled, err := am.GetScheduledByID(action.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled.Sender != fromName {
+		return nil, ErrScheduleSender
+	}
+	queue, err := am.getScheduleQueue()
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range queue {
+		if entry.ScheduleID == scheduled.ScheduleID {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if err := am.setScheduleQueue(queue); err != nil {
+		return nil, err
+	}
+	am.sdb.Delete(acctManagerName, schedulePrefix+strconv.FormatUint(scheduled.ScheduleID, 10))
+	return scheduled, nil
+}
+
+//isAssetScheduled check a pending scheduled action transfers the asset
+func (am *AccountManager) isAssetScheduled(assetID uint64) (bool, error) {
+	queue, err := am.getScheduleQueue()
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range queue {
+		scheduled, err := am.GetScheduledByID(entry.ScheduleID)
+		if err != nil {
+			return false, err
+		}
+		if scheduled.Action.AssetID == assetID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//ProcessScheduled execute the scheduled actions due at the block in the queue order,
+//at most the block budget of them. A failed action is dropped with its changes reverted.
+func (am *AccountManager) ProcessScheduled(config *params.ChainConfig, header *types.Header) error {
+	if am.readOnly {
+		return ErrAccountManagerReadOnly
+	}
+	if !config.IsScheduler() {
+		return nil
+	}
+	queue, err := am.getScheduleQueue()
+	if err != nil || len(queue) == 0 {
+		return err
+	}
+	number := header.Number.Uint64()
+	pool := common.Name(config.AccountName)
+	budget := config.ScheduleCfg.BlockBudget
+	for len(queue) > 0 && budget > 0 && queue[0].Number <= number {
+		scheduled, err := am.GetScheduledByID(queue[0].ScheduleID)
+		if err != nil {
+			return err
+		}
+		queue = queue[1:]
+		budget--
+		am.sdb.Delete(acctManagerName, schedulePrefix+strconv.FormatUint(scheduled.ScheduleID, 10))
+
+		context := &types.AccountManagerContext{
+			Action:      scheduled.Action.Action(scheduled.Sender),
+			ChainConfig: config,
+			Number:      number,
+			Time:        header.Time.Uint64(),
+			CurForkID:   header.CurForkID(),
+		}
+		if _, err := am.Process(context); err != nil {
+			log.Debug("Scheduled action failed", "id", scheduled.ScheduleID, "sender", scheduled.Sender, "err", err)
+		}
+		if scheduled.Fee.Sign() > 0 {
+			snap := am.sdb.Snapshot()
+			if err := am.TransferAsset(pool, header.Coinbase, config.SysTokenID, scheduled.Fee); err != nil {
+				am.sdb.RevertToSnapshot(snap)
+				am.resetCache()
+				log.Warn("Failed to pay scheduled action fee", "id", scheduled.ScheduleID, "coinbase", header.Coinbase, "err", err)
+			}
+		}
+	}
+	return am.setScheduleQueue(queue)
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func processScheduleAction(am *AccountManager, cfg *params.ChainConfig, aType types.ActionType, from common.Name, number uint64, data interface{}) error {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	action := types.NewAction(aType, from, common.Name(cfg.AccountName), 0, cfg.SysTokenID, 0, big.NewInt(0), payload, nil)
+	_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: cfg, Number: number})
+	return err
+}
+
+func TestAccountManager_ScheduleAction(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	pool, coinbase := common.Name("fractal.account"), common.Name("fractal")
+	cfg := params.DefaultChainconfig.Copy()
+	cfg.SysTokenID = assetID
+	transfer := func(amount int64) *types.SubAction {
+		return &types.SubAction{Type: types.Transfer, To: recipient, AssetID: assetID, Amount: big.NewInt(amount)}
+	}
+	balance := func(name common.Name) int64 {
+		b, err := am.GetAccountBalanceByID(name, assetID, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.Int64()
+	}
+
+	if err := processScheduleAction(am, cfg, types.ScheduleAction, sender, 1, &ScheduleAction{Number: 5, Action: transfer(10)}); err != ErrSchedulerDisabled {
+		t.Fatalf("scheduler disabled err %v", err)
+	}
+	cfg.ScheduleCfg = &params.Scheduler{Fee: big.NewInt(5), BlockBudget: 1, MaxDelay: 100}
+
+	invalids := []struct {
+		name     string
+		number   uint64
+		schedule *ScheduleAction
+		err      error
+	}{
+		{"past", 5, &ScheduleAction{Number: 5, Action: transfer(10)}, ErrScheduleNumber},
+		{"delay", 1, &ScheduleAction{Number: 102, Action: transfer(10)}, ErrScheduleNumber},
+		{"contract", 1, &ScheduleAction{Number: 5, Action: &types.SubAction{Type: types.CreateContract, To: sender}}, ErrScheduleInvalid},
+		{"cancel", 1, &ScheduleAction{Number: 5, Action: &types.SubAction{Type: types.CancelScheduledAction, To: pool}}, ErrScheduleInvalid},
+		{"inheritance", 1, &ScheduleAction{Number: 5, Action: &types.SubAction{Type: types.SetInheritance, To: pool}}, ErrScheduleInvalid},
+	}
+	for _, tt := range invalids {
+		if err := processScheduleAction(am, cfg, types.ScheduleAction, sender, tt.number, tt.schedule); err != tt.err {
+			t.Errorf("%s: err %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	// the second action fails at the execution, the third is cancelled
+	for _, schedule := range []*ScheduleAction{{Number: 5, Action: transfer(10)}, {Number: 5, Action: transfer(1000)}, {Number: 8, Action: transfer(10)}} {
+		if err := processScheduleAction(am, cfg, types.ScheduleAction, sender, 1, schedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if balance(sender) != 85 || balance(pool) != 15 {
+		t.Fatalf("fees not charged, sender %v pool %v", balance(sender), balance(pool))
+	}
+
+	if err := processScheduleAction(am, cfg, types.CancelScheduledAction, recipient, 2, &CancelScheduledAction{ScheduleID: 3}); err != ErrScheduleSender {
+		t.Fatalf("cancel by other err %v", err)
+	}
+	if err := processScheduleAction(am, cfg, types.CancelScheduledAction, sender, 2, &CancelScheduledAction{ScheduleID: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if balance(sender) != 90 || balance(pool) != 10 {
+		t.Fatalf("fee not refunded, sender %v pool %v", balance(sender), balance(pool))
+	}
+	if _, err := am.GetScheduledByID(3); err != ErrScheduledNotExist {
+		t.Fatalf("cancelled action err %v", err)
+	}
+
+	process := func(number int64) {
+		header := &types.Header{Number: big.NewInt(number), Time: big.NewInt(0), Coinbase: coinbase}
+		if err := am.ProcessScheduled(cfg, header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	process(4)
+	if queue, _ := am.getScheduleQueue(); len(queue) != 2 {
+		t.Fatalf("executed before the number, queue %v", queue)
+	}
+	// the block budget executes one action per block
+	process(5)
+	if balance(recipient) != 10 || balance(coinbase) != 5 {
+		t.Fatalf("first action, recipient %v coinbase %v", balance(recipient), balance(coinbase))
+	}
+	if _, err := am.GetScheduledByID(2); err != nil {
+		t.Fatalf("second action not pending, err %v", err)
+	}
+	process(6)
+	if balance(recipient) != 10 || balance(sender) != 80 || balance(coinbase) != 10 || balance(pool) != 0 {
+		t.Fatalf("failed action, recipient %v sender %v coinbase %v pool %v", balance(recipient), balance(sender), balance(coinbase), balance(pool))
+	}
+	if queue, _ := am.getScheduleQueue(); len(queue) != 0 {
+		t.Fatalf("queue not empty %v", queue)
+	}
+}
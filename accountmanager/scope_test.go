@@ -0,0 +1,168 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_RecoverTxAuthorScope(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	owner, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), common.Name("scopeowner"), common.Name(""), 0, 0, owner, ""); err != nil {
+		t.Fatal(err)
+	}
+	sessionPub, sessionPriv := GeneragePubKey()
+	session := common.NewAuthor(sessionPub, 1)
+	scope, err := types.TransferPermission.Scope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.Scope = scope
+	update := &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: AddAuthor, Author: session}}}
+	if err := am.UpdateAccountAuthor(common.Name("scopeowner"), update); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := types.NewSigner(big.NewInt(1))
+	tests := []struct {
+		aType   types.ActionType
+		wantErr bool
+	}{
+		{types.Transfer, false},
+		{types.CallContract, true},
+		{types.VoteCandidate, true},
+		{types.UpdateAccountAuthor, true},
+	}
+	for _, tt := range tests {
+		action := types.NewAction(tt.aType, common.Name("scopeowner"), common.Name("escrowrecipient"), 0, 0, 0, big.NewInt(0), nil, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		if err := types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(sessionPriv, []uint64{1})}); err != nil {
+			t.Fatal(err)
+		}
+		if err := am.RecoverTx(signer, tx); (err != nil) != tt.wantErr {
+			t.Errorf("action type %d RecoverTx err %v, wantErr %v", tt.aType, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAccountManager_UpdateAuthorScope(t *testing.T) {
+	statedb := getStateDB()
+	setForkID(statedb, params.ForkID4)
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal.founder"), common.Name("scopeupdate"), common.Name(""), 0, 0, owner, ""); err != nil {
+		t.Fatal(err)
+	}
+	sessionPub, _ := GeneragePubKey()
+	scope, err := types.TransferPermission.Scope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := common.NewAuthor(sessionPub, 1)
+	session.Scope = scope
+	add := &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: AddAuthor, Author: session}}}
+	if err := am.UpdateAccountAuthor(common.Name("scopeupdate"), add); err != nil {
+		t.Fatal(err)
+	}
+	sessionAuthor := func() *common.Author {
+		acct, err := am.GetAccountByName(common.Name("scopeupdate"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, auth := range acct.Authors {
+			if auth.Owner.String() == sessionPub.String() {
+				return auth
+			}
+		}
+		t.Fatal("session author not exist")
+		return nil
+	}
+
+	// a weight update keeps the scope of the session key
+	weight := &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: UpdateAuthor, Author: common.NewAuthor(sessionPub, 2)}}}
+	if err := am.UpdateAccountAuthor(common.Name("scopeupdate"), weight); err != nil {
+		t.Fatal(err)
+	}
+	if auth := sessionAuthor(); auth.Weight != 2 || !types.ScopeAllows(auth.Scope, types.Transfer) || types.ScopeAllows(auth.Scope, types.CallContract) {
+		t.Fatalf("weight update author %v, want weight 2 and the transfer scope", auth)
+	}
+
+	// the scope is only replaced by its own action
+	vote, err := types.VotePermission.Scope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rescoped := common.NewAuthor(sessionPub, 0)
+	rescoped.Scope = vote
+	update := &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: UpdateAuthorScope, Author: rescoped}}}
+	if err := am.UpdateAccountAuthor(common.Name("scopeupdate"), update); err != nil {
+		t.Fatal(err)
+	}
+	if auth := sessionAuthor(); auth.Weight != 2 || !types.ScopeAllows(auth.Scope, types.VoteCandidate) || types.ScopeAllows(auth.Scope, types.Transfer) {
+		t.Fatalf("scope update author %v, want weight 2 and the vote scope", auth)
+	}
+
+	// the blocks before the fork carry no scope
+	setForkID(statedb, params.ForkID3)
+	if err := am.UpdateAccountAuthor(common.Name("scopeupdate"), update); err == nil {
+		t.Error("scope update before the fork want err")
+	}
+	if err := am.UpdateAccountAuthor(common.Name("scopeupdate"), add); err != types.ErrNotForked {
+		t.Errorf("scoped author before the fork err %v, want %v", err, types.ErrNotForked)
+	}
+	if err := am.CheckAccountAuthor(common.Name("scopeupdate"), add); err != types.ErrNotForked {
+		t.Errorf("check scoped author before the fork err %v, want %v", err, types.ErrNotForked)
+	}
+}
+
+func TestPermissionScope(t *testing.T) {
+	tests := []struct {
+		permission types.Permission
+		allowed    types.ActionType
+		denied     []types.ActionType
+	}{
+		{types.TransferPermission, types.Transfer, []types.ActionType{types.CallContract, types.VoteCandidate, types.TransferNFT}},
+		{types.VotePermission, types.VoteCandidate, []types.ActionType{types.Transfer, types.RegCandidate}},
+		{types.CallContractPermission, types.CallContract, []types.ActionType{types.CreateContract, types.Transfer}},
+	}
+	for _, tt := range tests {
+		scope, err := tt.permission.Scope()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !types.ScopeAllows(scope, tt.allowed) {
+			t.Errorf("%s permission denies action type %d", tt.permission, tt.allowed)
+		}
+		for _, aType := range tt.denied {
+			if types.ScopeAllows(scope, aType) {
+				t.Errorf("%s permission allows action type %d", tt.permission, aType)
+			}
+		}
+	}
+	if _, err := types.Permission("owner").Scope(); err != types.ErrUnknownPermission {
+		t.Errorf("unknown permission err %v, want %v", err, types.ErrUnknownPermission)
+	}
+}
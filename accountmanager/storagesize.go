@@ -0,0 +1,189 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// The account object is measured when the size is read, only the size of the
+// per-asset balance keys is stored, and it is rewritten only when a balance key
+// is added or the encoded balance changes its length.
+var acctBalancesSizePrefix = "acctBalancesSize"
+
+// AccountStorageSize state bytes consumed by the account, keys included.
+type AccountStorageSize struct {
+	Authors  uint64 `json:"authors"`
+	Balances uint64 `json:"balances"`
+	Code     uint64 `json:"code"`
+	Metadata uint64 `json:"metadata"`
+}
+
+// Total returns the total bytes consumed by the account.
+func (s *AccountStorageSize) Total() uint64 {
+	return s.Authors + s.Balances + s.Code + s.Metadata
+}
+
+func balancesSizeKey(accountID uint64) string {
+	return acctBalancesSizePrefix + strconv.FormatUint(accountID, 10)
+}
+
+//getStorageSize measure the stored account object and read the size of its balance keys
+func (am *AccountManager) getStorageSize(accountID uint64) (*AccountStorageSize, error) {
+	key := acctInfoPrefix + strconv.FormatUint(accountID, 10)
+	b, err := am.stateGet(key)
+	if err != nil {
+		return nil, err
+	}
+	size := &AccountStorageSize{}
+	if len(b) != 0 {
+		var acct Account
+		if err := rlp.DecodeBytes(b, &acct); err != nil {
+			return nil, err
+		}
+		authors, err := rlp.EncodeToBytes(acct.Authors)
+		if err != nil {
+			return nil, err
+		}
+		size.Authors = uint64(len(authors))
+		size.Code = uint64(len(acct.Code))
+		if total := uint64(len(key) + len(b)); total > size.Authors+size.Code {
+			size.Metadata = total - size.Authors - size.Code
+		}
+	}
+	if size.Balances, _, err = am.getUint64(balancesSizeKey(accountID)); err != nil {
+		return nil, err
+	}
+	return size, nil
+}
+
+//touchStorage record the storage size of the account before its first write since TrackStorageGrowth
+func (am *AccountManager) touchStorage(accountID uint64) error {
+	if am.storageBase == nil {
+		return nil
+	}
+	if _, ok := am.storageBase[accountID]; ok {
+		return nil
+	}
+	size, err := am.getStorageSize(accountID)
+	if err != nil {
+		return err
+	}
+	am.storageBase[accountID] = size.Total()
+	return nil
+}
+
+//addBalancesSize add the delta to the balances size of the account
+func (am *AccountManager) addBalancesSize(accountID uint64, delta int64) error {
+	if delta == 0 {
+		return nil
+	}
+	size, _, err := am.getUint64(balancesSizeKey(accountID))
+	if err != nil {
+		return err
+	}
+	if delta < 0 && uint64(-delta) > size {
+		size = 0
+	} else {
+		size = uint64(int64(size) + delta)
+	}
+	return am.putUint64(balancesSizeKey(accountID), size)
+}
+
+//GetAccountStorageSize get the state bytes consumed by the account
+func (am *AccountManager) GetAccountStorageSize(accountName common.Name) (*AccountStorageSize, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.getStorageSize(accountID)
+}
+
+//TrackStorageGrowth start recording the storage size of the accounts written by the action
+func (am *AccountManager) TrackStorageGrowth() {
+	am.storageBase = make(map[uint64]uint64)
+}
+
+//ChargeStorageRent charge the sender of the action the rent of the bytes the accounts
+//written since TrackStorageGrowth grew beyond the storage quota, from ForkID4
+func (am *AccountManager) ChargeStorageRent(sender common.Name, config *params.ChainConfig) ([]*types.InternalAction, error) {
+	base := am.storageBase
+	am.storageBase = nil
+	rentCfg := config.RentCfg
+	if rentCfg == nil || rentCfg.StorageQuota == 0 || len(base) == 0 || am.isRentExempt(sender, config) {
+		return nil, nil
+	}
+	if forked, err := am.isForked(params.ForkID4); err != nil || !forked {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(base))
+	for id := range base {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var excess uint64
+	for _, id := range ids {
+		size, err := am.getStorageSize(id)
+		if err != nil {
+			return nil, err
+		}
+		total := size.Total()
+		if total <= rentCfg.StorageQuota || total <= base[id] {
+			continue
+		}
+		grown := total - base[id]
+		if over := total - rentCfg.StorageQuota; over < grown {
+			grown = over
+		}
+		excess += grown
+	}
+	if excess == 0 {
+		return nil, nil
+	}
+	if rentCfg.PricePerByte == nil || rentCfg.PricePerByte.Sign() == 0 {
+		return nil, ErrStorageQuotaExceeded
+	}
+	rent := new(big.Int).Mul(rentCfg.PricePerByte, new(big.Int).SetUint64(excess))
+	to := common.Name(config.AccountName)
+	if err := am.TransferAsset(sender, to, config.SysTokenID, rent); err != nil {
+		return nil, err
+	}
+	actionX := types.NewAction(types.Transfer, sender, to, 0, config.SysTokenID, 0, rent, nil, nil)
+	internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+	return []*types.InternalAction{internalAction}, nil
+}
+
+func (am *AccountManager) isRentExempt(name common.Name, cfg *params.ChainConfig) bool {
+	for _, sys := range []string{cfg.SysName, cfg.AccountName, cfg.AssetName, cfg.DposName, cfg.FeeName} {
+		if name.String() == sys {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_GetAccountStorageSize(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	name := common.Name("escrowrecipient")
+	size, err := am.GetAccountStorageSize(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size.Authors == 0 || size.Metadata == 0 || size.Balances != 0 || size.Code != 0 {
+		t.Errorf("new account storage size = %+v", size)
+	}
+	if err := am.AddAccountBalanceByID(name, assetID, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	grown, _ := am.GetAccountStorageSize(name)
+	if grown.Balances == 0 || grown.Total() <= size.Total() {
+		t.Errorf("storage size not grown %+v", grown)
+	}
+	if err := am.SubAccountBalanceByID(name, assetID, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.SetCode(name, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	coded, _ := am.GetAccountStorageSize(name)
+	if coded.Code != 3 || coded.Balances != grown.Balances {
+		t.Errorf("storage size = %+v", coded)
+	}
+	if _, err := am.GetAccountStorageSize(common.Name("notexistname")); err != ErrAccountNotExist {
+		t.Errorf("GetAccountStorageSize err = %v, want %v", err, ErrAccountNotExist)
+	}
+}
+
+func TestAccountManager_StorageRent(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	size, _ := am.GetAccountStorageSize(recipient)
+
+	config := params.DefaultChainconfig.Copy()
+	config.SysTokenID = assetID
+	config.RentCfg = &params.RentConfig{StorageQuota: size.Total()}
+	// transfer as the state transition does, reverted when the rent can not be charged
+	transfer := func(value *big.Int) ([]*types.InternalAction, error) {
+		snap := am.sdb.Snapshot()
+		am.TrackStorageGrowth()
+		action := types.NewAction(types.Transfer, sender, recipient, 0, assetID, 0, value, nil, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: config})
+		if err != nil {
+			return nil, err
+		}
+		internalActions, err := am.ChargeStorageRent(sender, config)
+		if err != nil {
+			am.sdb.RevertToSnapshot(snap)
+		}
+		return internalActions, err
+	}
+	if _, err := transfer(big.NewInt(10)); err != ErrStorageQuotaExceeded {
+		t.Fatalf("restricted transfer err = %v, want %v", err, ErrStorageQuotaExceeded)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, assetID, 0); balance.Sign() != 0 {
+		t.Errorf("restricted transfer not reverted, balance %v", balance)
+	}
+
+	// the sender pays the rent of the balance key the transfer added to the recipient
+	config.RentCfg.PricePerByte = big.NewInt(1)
+	internalActions, err := transfer(big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	grown, _ := am.GetAccountStorageSize(recipient)
+	rent := new(big.Int).SetUint64(grown.Total() - size.Total())
+	if len(internalActions) != 1 || internalActions[0].Action.From != sender || internalActions[0].Action.Amount.Cmp(rent) != 0 {
+		t.Fatalf("rent internal actions = %v, want %v paid by the sender", internalActions, rent)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, assetID, 0); balance.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("recipient balance = %v, want 10", balance)
+	}
+	if balance, _ := am.GetAccountBalanceByID(sender, assetID, 0); balance.Cmp(new(big.Int).Sub(big.NewInt(90), rent)) != 0 {
+		t.Errorf("sender balance = %v, want 90 - %v", balance, rent)
+	}
+
+	// no rent is charged before ForkID4
+	setForkID(am.sdb, params.ForkID3)
+	config.RentCfg.StorageQuota = 1
+	if internalActions, err := transfer(big.NewInt(1)); err != nil || len(internalActions) != 0 {
+		t.Errorf("rent before the fork = %v, %v", internalActions, err)
+	}
+}
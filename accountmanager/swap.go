@@ -0,0 +1,129 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// An atomic swap exchanges the assets of two accounts in one action. Either party
+// sends the action, the transaction signature authorizes its leg and the payload
+// carries the typed data signatures of the authors of the other party reaching
+// its threshold. A swap is executed once, the hash of an executed swap is
+// recorded with its expiry.
+var atomicSwapPrefix = "atomicSwap"
+
+// SwapLeg the account pays the amount of the asset to the other party
+type SwapLeg struct {
+	From    common.Name `json:"from"`
+	AssetID uint64      `json:"assetId"`
+	Amount  *big.Int    `json:"amount"`
+}
+
+// AtomicSwapAction the two legs of the swap and the counterparty signatures
+type AtomicSwapAction struct {
+	LegA       SwapLeg                 `json:"legA"`
+	LegB       SwapLeg                 `json:"legB"`
+	Expiry     uint64                  `json:"expiry"` // last block number the swap can be executed
+	Signatures []*types.TypedSignature `json:"signatures"`
+}
+
+//TypedData get the typed data of the swap signed by the authors of the counterparty
+func (s *AtomicSwapAction) TypedData(config *params.ChainConfig) *types.TypedData {
+	return &types.TypedData{
+		Types: map[string][]types.TypedField{
+			"AtomicSwap": {
+				{Name: "fromA", Type: "name"},
+				{Name: "assetIdA", Type: "uint64"},
+				{Name: "amountA", Type: "uint256"},
+				{Name: "fromB", Type: "name"},
+				{Name: "assetIdB", Type: "uint64"},
+				{Name: "amountB", Type: "uint256"},
+				{Name: "expiry", Type: "uint64"},
+			},
+		},
+		PrimaryType: "AtomicSwap",
+		Domain:      types.TypedDomain{Name: "AtomicSwap", Version: "1", ChainID: config.ChainID, Verifier: common.Name(config.AccountName)},
+		Message: map[string]interface{}{
+			"fromA":    s.LegA.From.String(),
+			"assetIdA": s.LegA.AssetID,
+			"amountA":  s.LegA.Amount,
+			"fromB":    s.LegB.From.String(),
+			"assetIdB": s.LegB.AssetID,
+			"amountB":  s.LegB.Amount,
+			"expiry":   s.Expiry,
+		},
+	}
+}
+
+//Sign sign the swap by the key of the counterparty author at the index
+func (s *AtomicSwapAction) Sign(config *params.ChainConfig, priv *ecdsa.PrivateKey, index []uint64) error {
+	sig, err := types.SignTypedData(s.TypedData(config), priv)
+	if err != nil {
+		return err
+	}
+	s.Signatures = append(s.Signatures, &types.TypedSignature{Signature: sig, Index: index})
+	return nil
+}
+
+//AtomicSwap check the swap sent by the sender and signed by the counterparty, the legs are transferred by the caller
+func (am *AccountManager) AtomicSwap(fromName common.Name, number uint64, config *params.ChainConfig, action *AtomicSwapAction) error {
+	if action.LegA.From == action.LegB.From {
+		return ErrSwapInvalid
+	}
+	for _, leg := range []SwapLeg{action.LegA, action.LegB} {
+		if leg.Amount == nil || leg.Amount.Sign() <= 0 {
+			return ErrSwapInvalid
+		}
+	}
+	if number > action.Expiry {
+		return ErrSwapExpired
+	}
+	var counterparty common.Name
+	switch fromName {
+	case action.LegA.From:
+		counterparty = action.LegB.From
+	case action.LegB.From:
+		counterparty = action.LegA.From
+	default:
+		return ErrSwapInvalid
+	}
+
+	data := action.TypedData(config)
+	hash, err := data.Hash()
+	if err != nil {
+		return err
+	}
+	key := atomicSwapPrefix + hash.String()
+	if b, err := am.stateGet(key); err != nil {
+		return err
+	} else if len(b) != 0 {
+		return ErrSwapExecuted
+	}
+	if len(action.Signatures) == 0 {
+		return ErrSwapSignature
+	}
+	if err := am.VerifyTypedSignature(counterparty, config.ChainID, data, types.AtomicSwap, action.Signatures); err != nil {
+		return err
+	}
+	return am.putUint64(key, action.Expiry)
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_AtomicSwap(t *testing.T) {
+	am, sellAssetID := newEscrowTestManager(t)
+	seller, buyer := common.Name("escrowsender"), common.Name("swapbuyer")
+	buyerPubKey, buyerKey := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("fractal"), buyer, common.Name(""), 0, 0, buyerPubKey, ""); err != nil {
+		t.Fatal(err)
+	}
+	// the buyer is a multisig account, two of its authors must sign
+	cosignerPubKey, cosignerKey := GeneragePubKey()
+	acct, _ := am.GetAccountByName(buyer)
+	if err := acct.AddAuthor(common.NewAuthor(cosignerPubKey, 1)); err != nil {
+		t.Fatal(err)
+	}
+	acct.SetThreshold(2)
+	if err := am.SetAccount(acct); err != nil {
+		t.Fatal(err)
+	}
+	issue := IssueAsset{AssetName: "escrowcoin.usd", Symbol: "usd", Amount: big.NewInt(0), Owner: seller, UpperLimit: big.NewInt(0)}
+	buyAssetID, err := am.IssueAsset(seller, issue, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(buyer, buyAssetID, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	config := params.DefaultChainconfig
+	newSwap := func(buyAmount int64) *AtomicSwapAction {
+		swap := &AtomicSwapAction{
+			LegA:   SwapLeg{From: seller, AssetID: sellAssetID, Amount: big.NewInt(30)},
+			LegB:   SwapLeg{From: buyer, AssetID: buyAssetID, Amount: big.NewInt(buyAmount)},
+			Expiry: 10,
+		}
+		if err := swap.Sign(config, buyerKey, []uint64{0}); err != nil {
+			t.Fatal(err)
+		}
+		if err := swap.Sign(config, cosignerKey, []uint64{1}); err != nil {
+			t.Fatal(err)
+		}
+		return swap
+	}
+	balance := func(name common.Name, assetID uint64) int64 {
+		b, err := am.GetAccountBalanceByID(name, assetID, 0)
+		if err == ErrAccountAssetNotExist {
+			return 0
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.Int64()
+	}
+
+	swap := newSwap(20)
+	if err := processEscrowAction(am, types.AtomicSwap, common.Name("escrowrecipient"), 0, big.NewInt(0), 1, swap); err != ErrSwapInvalid {
+		t.Fatalf("swap sent by other err %v", err)
+	}
+	if err := processEscrowAction(am, types.AtomicSwap, seller, 0, big.NewInt(0), 11, swap); err != ErrSwapExpired {
+		t.Fatalf("expired swap err %v", err)
+	}
+	unsigned := newSwap(20)
+	unsigned.Signatures = nil
+	if err := processEscrowAction(am, types.AtomicSwap, seller, 0, big.NewInt(0), 1, unsigned); err != ErrSwapSignature {
+		t.Fatalf("unsigned swap err %v", err)
+	}
+	belowThreshold := newSwap(20)
+	belowThreshold.Signatures = belowThreshold.Signatures[:1]
+	if err := processEscrowAction(am, types.AtomicSwap, seller, 0, big.NewInt(0), 1, belowThreshold); err == nil {
+		t.Fatal("swap signed below the counterparty threshold should fail")
+	}
+	_, otherKey := GeneragePubKey()
+	forged := newSwap(20)
+	forged.Signatures = forged.Signatures[:1]
+	if err := forged.Sign(config, otherKey, []uint64{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := processEscrowAction(am, types.AtomicSwap, seller, 0, big.NewInt(0), 1, forged); err == nil {
+		t.Fatal("forged swap should fail")
+	}
+
+	// the buyer leg fails, the seller leg is reverted
+	if err := processEscrowAction(am, types.AtomicSwap, seller, 0, big.NewInt(0), 1, newSwap(60)); err == nil {
+		t.Fatal("swap over the buyer balance should fail")
+	}
+	if balance(seller, sellAssetID) != 100 || balance(buyer, sellAssetID) != 0 {
+		t.Fatal("failed swap transferred the seller leg")
+	}
+
+	if err := processEscrowAction(am, types.AtomicSwap, seller, 0, big.NewInt(0), 1, swap); err != nil {
+		t.Fatal(err)
+	}
+	if balance(seller, sellAssetID) != 70 || balance(buyer, sellAssetID) != 30 || balance(seller, buyAssetID) != 20 || balance(buyer, buyAssetID) != 30 {
+		t.Fatal("swap balances mismatch")
+	}
+	if err := processEscrowAction(am, types.AtomicSwap, seller, 0, big.NewInt(0), 2, swap); err != ErrSwapExecuted {
+		t.Fatalf("replayed swap err %v", err)
+	}
+}
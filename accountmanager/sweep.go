@@ -0,0 +1,65 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+// SweepBalance the balance of the account to sweep. Amount is the snapshot
+// balance bounded by the current balance, the account can not transfer more
+// than it holds now.
+type SweepBalance struct {
+	Account  common.Name `json:"account"`
+	Nonce    uint64      `json:"nonce"`
+	Snapshot *big.Int    `json:"snapshot"`
+	Current  *big.Int    `json:"current"`
+	Amount   *big.Int    `json:"amount"`
+}
+
+//GetSweepBalances get the balances of the accounts at the snapshot time for the sweep
+func (am *AccountManager) GetSweepBalances(accounts []common.Name, assetID uint64, time uint64) ([]*SweepBalance, error) {
+	balances := make([]*SweepBalance, 0, len(accounts))
+	for _, name := range accounts {
+		snapshot, err := am.GetBalanceByTime(name, assetID, 0, time)
+		if err != nil && err != ErrAccountAssetNotExist {
+			return nil, err
+		}
+		current, err := am.GetAccountBalanceByID(name, assetID, 0)
+		if err != nil && err != ErrAccountAssetNotExist {
+			return nil, err
+		}
+		nonce, err := am.GetNonce(name)
+		if err != nil {
+			return nil, err
+		}
+		amount := new(big.Int).Set(snapshot)
+		if current.Cmp(amount) < 0 {
+			amount.Set(current)
+		}
+		balances = append(balances, &SweepBalance{
+			Account:  name,
+			Nonce:    nonce,
+			Snapshot: snapshot,
+			Current:  current,
+			Amount:   amount,
+		})
+	}
+	return balances, nil
+}
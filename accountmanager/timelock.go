@@ -0,0 +1,185 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	timeLockPrefix        = "timeLock"
+	timeLockCounterPrefix = "timeLockCounter"
+)
+
+// TimeLockTransferAction lock the action value for the recipient until the
+// unlock block number and the unlock time are both reached. A zero value
+// means no limit of that kind.
+type TimeLockTransferAction struct {
+	Recipient    common.Name `json:"recipient,omitempty"`
+	UnlockNumber uint64      `json:"unlockNumber,omitempty"`
+	UnlockTime   uint64      `json:"unlockTime,omitempty"`
+}
+
+// TimeLockAction claim or cancel the time lock.
+type TimeLockAction struct {
+	LockID uint64 `json:"lockID,omitempty"`
+}
+
+// TimeLock time lock object
+type TimeLock struct {
+	LockID       uint64      `json:"lockID"`
+	Sender       common.Name `json:"sender"`
+	Recipient    common.Name `json:"recipient"`
+	AssetID      uint64      `json:"assetID"`
+	Amount       *big.Int    `json:"amount"`
+	Number       uint64      `json:"number"`
+	UnlockNumber uint64      `json:"unlockNumber"`
+	UnlockTime   uint64      `json:"unlockTime"`
+}
+
+//IsMatured check the time lock can be claimed at the block
+func (l *TimeLock) IsMatured(number uint64, time uint64) bool {
+	return number >= l.UnlockNumber && time >= l.UnlockTime
+}
+
+//getTimeLockCounter get time lock counter cur value
+func (am *AccountManager) getTimeLockCounter() (uint64, error) {
+	b, err := am.sdb.Get(acctManagerName, timeLockCounterPrefix)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	var lockCounter uint64
+	if err := rlp.DecodeBytes(b, &lockCounter); err != nil {
+		return 0, err
+	}
+	return lockCounter, nil
+}
+
+//GetTimeLockByID get time lock by lock id
+func (am *AccountManager) GetTimeLockByID(id uint64) (*TimeLock, error) {
+	b, err := am.sdb.Get(acctManagerName, timeLockPrefix+strconv.FormatUint(id, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrTimeLockNotExist
+	}
+	var lock TimeLock
+	if err := rlp.DecodeBytes(b, &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+func (am *AccountManager) setTimeLock(lock *TimeLock) error {
+	b, err := rlp.EncodeToBytes(lock)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, timeLockPrefix+strconv.FormatUint(lock.LockID, 10), b)
+	return nil
+}
+
+func (am *AccountManager) deleteTimeLock(id uint64) {
+	am.sdb.Delete(acctManagerName, timeLockPrefix+strconv.FormatUint(id, 10))
+}
+
+//TimeLockTransfer record the time lock of the value already transferred to the account manager
+func (am *AccountManager) TimeLockTransfer(fromName common.Name, assetID uint64, amount *big.Int, number uint64, time uint64, action *TimeLockTransferAction) (uint64, error) {
+	if amount.Sign() <= 0 {
+		return 0, ErrAmountValueInvalid
+	}
+	if action.UnlockNumber <= number && action.UnlockTime <= time {
+		return 0, ErrTimeLockUnlockInvalid
+	}
+	acct, err := am.GetAccountByName(action.Recipient)
+	if err != nil {
+		return 0, err
+	}
+	if acct == nil {
+		return 0, ErrAccountNotExist
+	}
+	if acct.IsDestroyed() {
+		return 0, ErrAccountIsDestroy
+	}
+
+	lockCounter, err := am.getTimeLockCounter()
+	if err != nil {
+		return 0, err
+	}
+	lockCounter = lockCounter + 1
+
+	lock := &TimeLock{
+		LockID:       lockCounter,
+		Sender:       fromName,
+		Recipient:    action.Recipient,
+		AssetID:      assetID,
+		Amount:       new(big.Int).Set(amount),
+		Number:       number,
+		UnlockNumber: action.UnlockNumber,
+		UnlockTime:   action.UnlockTime,
+	}
+	if err := am.setTimeLock(lock); err != nil {
+		return 0, err
+	}
+
+	b, err := rlp.EncodeToBytes(&lockCounter)
+	if err != nil {
+		return 0, err
+	}
+	am.sdb.Put(acctManagerName, timeLockCounterPrefix, b)
+	return lockCounter, nil
+}
+
+//ClaimTimeLock remove the matured time lock of the recipient, return the time lock to be released
+func (am *AccountManager) ClaimTimeLock(fromName common.Name, number uint64, time uint64, action *TimeLockAction) (*TimeLock, error) {
+	lock, err := am.GetTimeLockByID(action.LockID)
+	if err != nil {
+		return nil, err
+	}
+	if lock.Recipient != fromName {
+		return nil, ErrTimeLockNotRecipient
+	}
+	if !lock.IsMatured(number, time) {
+		return nil, ErrTimeLockNotMatured
+	}
+	am.deleteTimeLock(lock.LockID)
+	return lock, nil
+}
+
+//CancelTimeLock remove the time lock of the sender before maturity, return the time lock to be refunded
+func (am *AccountManager) CancelTimeLock(fromName common.Name, number uint64, time uint64, action *TimeLockAction) (*TimeLock, error) {
+	lock, err := am.GetTimeLockByID(action.LockID)
+	if err != nil {
+		return nil, err
+	}
+	if lock.Sender != fromName {
+		return nil, ErrTimeLockNotSender
+	}
+	if lock.IsMatured(number, time) {
+		return nil, ErrTimeLockMatured
+	}
+	am.deleteTimeLock(lock.LockID)
+	return lock, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func processTimeLockAction(am *AccountManager, aType types.ActionType, from common.Name, assetID uint64, value *big.Int, number uint64, time uint64, data interface{}) error {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	action := types.NewAction(aType, from, common.Name(params.DefaultChainconfig.AccountName), 0, assetID, 0, value, payload, nil)
+	_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: number, Time: time})
+	return err
+}
+
+func TestAccountManager_ClaimTimeLock(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	if err := processTimeLockAction(am, types.TimeLockTransfer, sender, assetID, big.NewInt(30), 1, 100, &TimeLockTransferAction{Recipient: recipient, UnlockNumber: 1, UnlockTime: 100}); err == nil {
+		t.Error("TimeLockTransfer want unlock err")
+	}
+	lock := &TimeLockTransferAction{Recipient: recipient, UnlockNumber: 10, UnlockTime: 1000}
+	if err := processTimeLockAction(am, types.TimeLockTransfer, sender, assetID, big.NewInt(30), 1, 100, lock); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		from    common.Name
+		number  uint64
+		time    uint64
+		wantErr bool
+	}{
+		{"notrecipient", sender, 10, 1000, true},
+		{"numbernotmatured", recipient, 9, 1000, true},
+		{"timenotmatured", recipient, 10, 999, true},
+		{"claim", recipient, 10, 1000, false},
+		{"claimed", recipient, 10, 1000, true},
+	}
+	for _, tt := range tests {
+		if err := processTimeLockAction(am, types.ClaimTimeLock, tt.from, assetID, big.NewInt(0), tt.number, tt.time, &TimeLockAction{LockID: 1}); (err != nil) != tt.wantErr {
+			t.Errorf("%q. ClaimTimeLock error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	balance, _ := am.GetAccountBalanceByID(recipient, assetID, 0)
+	if balance.Cmp(big.NewInt(30)) != 0 {
+		t.Errorf("recipient balance = %v, want 30", balance)
+	}
+}
+
+func TestAccountManager_CancelTimeLock(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient := common.Name("escrowsender"), common.Name("escrowrecipient")
+	lock := &TimeLockTransferAction{Recipient: recipient, UnlockNumber: 10}
+	for i := 0; i < 2; i++ {
+		if err := processTimeLockAction(am, types.TimeLockTransfer, sender, assetID, big.NewInt(30), 1, 0, lock); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		from    common.Name
+		lockID  uint64
+		number  uint64
+		wantErr bool
+	}{
+		{"notsender", recipient, 1, 5, true},
+		{"cancel", sender, 1, 5, false},
+		{"canceled", sender, 1, 5, true},
+		{"matured", sender, 2, 10, true},
+	}
+	for _, tt := range tests {
+		if err := processTimeLockAction(am, types.CancelTimeLock, tt.from, assetID, big.NewInt(0), tt.number, 0, &TimeLockAction{LockID: tt.lockID}); (err != nil) != tt.wantErr {
+			t.Errorf("%q. CancelTimeLock error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	balance, _ := am.GetAccountBalanceByID(sender, assetID, 0)
+	if balance.Cmp(big.NewInt(70)) != 0 {
+		t.Errorf("sender balance = %v, want 70", balance)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// feeExemptNames the system accounts, the transfers from or to them keep the asset in
+// custody, issue it or pay the gas and are free of the transfer fee
+var feeExemptNames = make(map[common.Name]bool)
+
+//SetTransferFeeExemptNames set the system accounts exempt from the asset transfer fee
+func SetTransferFeeExemptNames(names ...common.Name) {
+	feeExemptNames = make(map[common.Name]bool)
+	for _, name := range names {
+		feeExemptNames[name] = true
+	}
+}
+
+func isTransferFeeExempt(name common.Name) bool {
+	return name.String() == acctManagerName || feeExemptNames[name]
+}
+
+// SetAssetTransferFee set the fee rate in basis points taken on the transfers of the asset.
+type SetAssetTransferFee struct {
+	AssetID uint64 `json:"assetId,omitempty"`
+	Rate    uint64 `json:"rate"`
+}
+
+//GetAssetTransferFee get the fee rate in basis points taken on the transfers of the asset
+func (am *AccountManager) GetAssetTransferFee(assetID uint64) (uint64, error) {
+	if _, err := am.ast.GetAssetObjectById(assetID); err != nil {
+		return 0, err
+	}
+	return am.ast.GetAssetTransferFee(assetID)
+}
+
+//assetTransferFee get the fee of the transfer and the founder it is credited to,
+//the transfers from or to the founder or the system accounts are free
+func (am *AccountManager) assetTransferFee(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int) (*big.Int, common.Name, error) {
+	fee := big.NewInt(0)
+	if value.Sign() <= 0 || isTransferFeeExempt(fromAccount) || isTransferFeeExempt(toAccount) {
+		return fee, "", nil
+	}
+	rate, err := am.ast.GetAssetTransferFee(assetID)
+	if err != nil || rate == 0 {
+		return fee, "", err
+	}
+	founder, err := am.ast.GetAssetFounderById(assetID)
+	if err != nil {
+		return fee, "", err
+	}
+	if founder == "" || founder == fromAccount || founder == toAccount {
+		return fee, "", nil
+	}
+	fee.Mul(value, new(big.Int).SetUint64(rate))
+	fee.Div(fee, new(big.Int).SetUint64(params.TransferFeeRateDenominator))
+	return fee, founder, nil
+}
+
+//transferAssetWithFee transfer the value less the transfer fee to the recipient and the fee to the asset founder.
+//It returns the fee and its internal action, nil if no fee is taken.
+func (am *AccountManager) transferAssetWithFee(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int, fromAccountExtra ...common.Name) (*big.Int, *types.InternalAction, error) {
+	if value.Sign() > 0 {
+		isCollection, err := am.ast.IsNFTCollection(assetID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isCollection {
+			return nil, nil, ErrNFTransfer
+		}
+	}
+	fee, founder, err := am.assetTransferFee(fromAccount, toAccount, assetID, value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := am.transferAsset(fromAccount, toAccount, assetID, new(big.Int).Sub(value, fee), fromAccountExtra...); err != nil {
+		return nil, nil, err
+	}
+	if fee.Sign() == 0 {
+		return fee, nil, nil
+	}
+	if err := am.transferAsset(fromAccount, founder, assetID, fee, fromAccountExtra...); err != nil {
+		return nil, nil, err
+	}
+	actionX := types.NewAction(types.Transfer, fromAccount, founder, 0, assetID, 0, fee, nil, nil)
+	internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+	return fee, internalAction, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_AssetTransferFee(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	founder, holder, payee := common.Name("escrowsender"), common.Name("escrowrecipient"), common.Name("fractal")
+
+	if err := processAssetAction(am, types.SetAssetTransferFee, holder, 0, big.NewInt(0), &SetAssetTransferFee{AssetID: assetID, Rate: 100}); err == nil {
+		t.Fatal("set transfer fee by non owner should fail")
+	}
+	overCap := &SetAssetTransferFee{AssetID: assetID, Rate: params.MaxAssetTransferFeeRate + 1}
+	if err := processAssetAction(am, types.SetAssetTransferFee, founder, 0, big.NewInt(0), overCap); err != asset.ErrTransferFeeRate {
+		t.Fatalf("set transfer fee over the cap err %v", err)
+	}
+	if err := processAssetAction(am, types.SetAssetTransferFee, founder, 0, big.NewInt(0), &SetAssetTransferFee{AssetID: assetID, Rate: 250}); err != nil {
+		t.Fatal(err)
+	}
+	if rate, err := am.GetAssetTransferFee(assetID); err != nil || rate != 250 {
+		t.Fatalf("GetAssetTransferFee = %v, %v", rate, err)
+	}
+
+	transfer := func(from, to common.Name, value int64) []*types.InternalAction {
+		action := types.NewAction(types.Transfer, from, to, 0, assetID, 0, big.NewInt(value), nil, nil)
+		internalActions, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return internalActions
+	}
+	balance := func(name common.Name) int64 {
+		b, _ := am.GetAccountBalanceByID(name, assetID, 0)
+		return b.Int64()
+	}
+
+	// the transfers of the founder are free
+	if actions := transfer(founder, holder, 60); len(actions) != 0 || balance(holder) != 60 {
+		t.Fatalf("founder transfer actions %v, holder balance %v", actions, balance(holder))
+	}
+	actions := transfer(holder, payee, 40)
+	if len(actions) != 1 || actions[0].Action.To != founder || actions[0].Action.Amount.Int64() != 1 {
+		t.Fatalf("fee internal actions %v", actions)
+	}
+	if balance(holder) != 20 || balance(payee) != 39 || balance(founder) != 41 {
+		t.Fatalf("balances holder %v payee %v founder %v", balance(holder), balance(payee), balance(founder))
+	}
+
+	// the transfers of the other paths pay the fee, those of the system accounts are free
+	defer SetTransferFeeExemptNames()
+	pool := common.Name("fractal.account")
+	SetTransferFeeExemptNames(pool)
+	if err := am.AddAccountBalanceByID(founder, assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(founder, holder, assetID, big.NewInt(400)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(holder, payee, assetID, big.NewInt(400)); err != nil {
+		t.Fatal(err)
+	}
+	if balance(holder) != 20 || balance(payee) != 429 || balance(founder) != 651 {
+		t.Fatalf("balances holder %v payee %v founder %v", balance(holder), balance(payee), balance(founder))
+	}
+	if err := am.TransferAsset(payee, pool, assetID, big.NewInt(400)); err != nil {
+		t.Fatal(err)
+	}
+	if balance(payee) != 29 || balance(pool) != 400 || balance(founder) != 651 {
+		t.Fatalf("balances payee %v pool %v founder %v", balance(payee), balance(pool), balance(founder))
+	}
+	if err := am.TransferAsset(pool, payee, assetID, big.NewInt(400)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := processAssetAction(am, types.SetAssetTransferFee, founder, 0, big.NewInt(0), &SetAssetTransferFee{AssetID: assetID, Rate: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if actions := transfer(holder, payee, 20); len(actions) != 0 || balance(payee) != 449 {
+		t.Fatalf("free transfer actions %v, payee balance %v", actions, balance(payee))
+	}
+}
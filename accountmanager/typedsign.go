@@ -0,0 +1,68 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+//VerifyTypedSignature check the typed data is signed by the authors of the account reaching the thresholds,
+//the authors limited by their scope must allow the action type the message authorizes
+func (am *AccountManager) VerifyTypedSignature(accountName common.Name, chainID *big.Int, data *types.TypedData, actionType types.ActionType, sigs []*types.TypedSignature) error {
+	if data.Domain.ChainID == nil || data.Domain.ChainID.Cmp(chainID) != 0 {
+		return ErrTypedDataChainID
+	}
+	if len(sigs) == 0 {
+		return ErrTypedSignatureEmpty
+	}
+	limit, err := am.authorLimit()
+	if err != nil {
+		return err
+	}
+	if uint64(len(sigs)) > limit.signLength {
+		return fmt.Errorf("exceed max sign length, want most %d, actual is %d", limit.signLength, len(sigs))
+	}
+
+	recoverRes := &recoverActionResult{acctAuthors: make(map[common.Name]*accountAuthor), actionType: actionType}
+	for _, sig := range sigs {
+		if uint64(len(sig.Index)) > limit.signDepth {
+			return fmt.Errorf("exceed max sign depth, want most %d, actual is %d", limit.signDepth, len(sig.Index))
+		}
+		pub, err := types.RecoverTypedData(data, sig.Signature)
+		if err != nil {
+			return err
+		}
+		if err := am.ValidSign(accountName, pub, sig.Index, recoverRes); err != nil {
+			return err
+		}
+	}
+
+	for name, acctAuthor := range recoverRes.acctAuthors {
+		var count uint64
+		for _, weight := range acctAuthor.indexWeight {
+			count += weight
+		}
+		if count < acctAuthor.threshold {
+			return fmt.Errorf("account %s want threshold %d, but actual is %d", name, acctAuthor.threshold, count)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_VerifyTypedSignature(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub1, key1 := GeneragePubKey()
+	pub2, key2 := GeneragePubKey()
+	for _, name := range []string{"fractal", "typedsigner"} {
+		if err := am.CreateAccount(common.Name("fractal"), common.Name(name), common.Name(""), 0, 0, pub1, ""); err != nil {
+			t.Fatalf("create account %s err %v", name, err)
+		}
+	}
+	// two of the authors must sign
+	acct, _ := am.GetAccountByName(common.Name("typedsigner"))
+	if err := acct.AddAuthor(common.NewAuthor(pub2, 1)); err != nil {
+		t.Fatal(err)
+	}
+	acct.SetThreshold(2)
+	if err := am.SetAccount(acct); err != nil {
+		t.Fatal(err)
+	}
+
+	chainID := big.NewInt(1)
+	data := &types.TypedData{
+		Types:       map[string][]types.TypedField{"Permit": {{Name: "spender", Type: "name"}, {Name: "amount", Type: "uint256"}}},
+		PrimaryType: "Permit",
+		Domain:      types.TypedDomain{Name: "permit", Version: "1", ChainID: chainID},
+		Message:     map[string]interface{}{"spender": "fractal", "amount": "100"},
+	}
+	sig1, err := types.SignTypedData(data, key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := types.SignTypedData(data, key2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		chainID *big.Int
+		sigs    []*types.TypedSignature
+		wantErr bool
+	}{
+		{"threshold", chainID, []*types.TypedSignature{{Signature: sig1, Index: []uint64{0}}, {Signature: sig2, Index: []uint64{1}}}, false},
+		{"belowthreshold", chainID, []*types.TypedSignature{{Signature: sig1, Index: []uint64{0}}}, true},
+		{"duplicate", chainID, []*types.TypedSignature{{Signature: sig1, Index: []uint64{0}}, {Signature: sig1, Index: []uint64{0}}}, true},
+		{"wrongindex", chainID, []*types.TypedSignature{{Signature: sig1, Index: []uint64{1}}, {Signature: sig2, Index: []uint64{0}}}, true},
+		{"otherchain", big.NewInt(2), []*types.TypedSignature{{Signature: sig1, Index: []uint64{0}}, {Signature: sig2, Index: []uint64{1}}}, true},
+		{"unsigned", chainID, nil, true},
+	}
+	for _, tt := range tests {
+		err := am.VerifyTypedSignature(common.Name("typedsigner"), tt.chainID, data, types.Transfer, tt.sigs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: VerifyTypedSignature() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
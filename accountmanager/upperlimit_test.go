@@ -0,0 +1,123 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func processUpperLimitAction(am *AccountManager, aType types.ActionType, from common.Name, number uint64, data interface{}) error {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	action := types.NewAction(aType, from, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), payload, nil)
+	_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: number})
+	return err
+}
+
+func TestAccountManager_LowerAssetUpperLimit(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+
+	// the asset issued without approvers can only be lowered
+	lower := &UpdateAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(500)}
+	if err := processUpperLimitAction(am, types.UpdateAssetUpperLimit, common.Name("escrowrecipient"), 1, lower); err != asset.ErrOwnerMismatch {
+		t.Fatalf("update upper limit by non owner err %v", err)
+	}
+	if err := processUpperLimitAction(am, types.UpdateAssetUpperLimit, common.Name("escrowsender"), 1, lower); err != nil {
+		t.Fatal(err)
+	}
+	if ao, _ := am.GetAssetInfoByID(assetID); ao.GetUpperLimit().Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("upper limit %v", ao.GetUpperLimit())
+	}
+	for _, limit := range []int64{600, 0} {
+		raise := &UpdateAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(limit)}
+		if err := processUpperLimitAction(am, types.UpdateAssetUpperLimit, common.Name("escrowsender"), 2, raise); err != asset.ErrUpperLimitRaise {
+			t.Fatalf("raise upper limit to %d err %v", limit, err)
+		}
+	}
+}
+
+func TestAccountManager_ApproveAssetUpperLimit(t *testing.T) {
+	am, _ := newEscrowTestManager(t)
+	issue := IssueAsset{AssetName: "escrowsender:limitcoin", Symbol: "lim", Amount: big.NewInt(100), Owner: common.Name("escrowsender"), UpperLimit: big.NewInt(1000),
+		UpperLimitApprovers: []common.Name{"fractal.account", "escrowrecipient"}}
+	if _, err := am.IssueAsset(common.Name("escrowsender"), issue, 0, params.ForkID3); err != types.ErrNotForked {
+		t.Fatalf("issue with approvers before the fork err %v, want %v", err, types.ErrNotForked)
+	}
+	assetID, err := am.IssueAsset(common.Name("escrowsender"), issue, 0, params.ForkID4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approvers, err := am.GetUpperLimitApprovers(assetID); err != nil || len(approvers) != 2 {
+		t.Fatalf("approvers %v err %v", approvers, err)
+	}
+
+	// not below the issued amount
+	below := &UpdateAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(50)}
+	if err := processUpperLimitAction(am, types.UpdateAssetUpperLimit, common.Name("escrowsender"), 1, below); err != asset.ErrUpperLimitInvalid {
+		t.Fatalf("lower upper limit below the issued amount err %v", err)
+	}
+
+	raise := &UpdateAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(2000)}
+	if err := processUpperLimitAction(am, types.UpdateAssetUpperLimit, common.Name("escrowsender"), 1, raise); err != nil {
+		t.Fatal(err)
+	}
+	approve := &ApproveAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(2000)}
+	if err := processUpperLimitAction(am, types.ApproveAssetUpperLimit, common.Name("escrowsender"), 2, approve); err != asset.ErrNotLimitApprover {
+		t.Fatalf("approve by non approver err %v", err)
+	}
+	mismatch := &ApproveAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(3000)}
+	if err := processUpperLimitAction(am, types.ApproveAssetUpperLimit, common.Name("fractal.account"), 2, mismatch); err != asset.ErrPendingLimitMismatch {
+		t.Fatalf("approve mismatched limit err %v", err)
+	}
+	if err := processUpperLimitAction(am, types.ApproveAssetUpperLimit, common.Name("fractal.account"), 2, approve); err != nil {
+		t.Fatal(err)
+	}
+	if err := processUpperLimitAction(am, types.ApproveAssetUpperLimit, common.Name("fractal.account"), 2, approve); err != asset.ErrLimitApproved {
+		t.Fatalf("approve twice err %v", err)
+	}
+	if ao, _ := am.GetAssetInfoByID(assetID); ao.GetUpperLimit().Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("upper limit raised before all the approvals %v", ao.GetUpperLimit())
+	}
+	if err := processUpperLimitAction(am, types.ApproveAssetUpperLimit, common.Name("escrowrecipient"), 3, approve); err != nil {
+		t.Fatal(err)
+	}
+	if ao, _ := am.GetAssetInfoByID(assetID); ao.GetUpperLimit().Cmp(big.NewInt(2000)) != 0 {
+		t.Fatalf("upper limit %v", ao.GetUpperLimit())
+	}
+	if _, err := am.GetPendingUpperLimit(assetID); err != asset.ErrPendingLimitNotExist {
+		t.Fatalf("pending upper limit after the raise err %v", err)
+	}
+
+	// the proposal expires
+	unlimited := &UpdateAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(0)}
+	if err := processUpperLimitAction(am, types.UpdateAssetUpperLimit, common.Name("escrowsender"), 4, unlimited); err != nil {
+		t.Fatal(err)
+	}
+	late := &ApproveAssetUpperLimit{AssetID: assetID, UpperLimit: big.NewInt(0)}
+	if err := processUpperLimitAction(am, types.ApproveAssetUpperLimit, common.Name("escrowrecipient"), 5+asset.UpperLimitApproveBlocks, late); err != asset.ErrPendingLimitExpired {
+		t.Fatalf("approve expired proposal err %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_AssetVerifier(t *testing.T) {
+	am, assetID := newEscrowTestManager(t)
+	sender, recipient, contract := common.Name("escrowsender"), common.Name("escrowrecipient"), common.Name("escrowverify")
+	pubkey, _ := GeneragePubKey()
+	for _, name := range []common.Name{contract, common.Name(params.DefaultChainconfig.AssetName)} {
+		if err := am.CreateAccount(common.Name("fractal"), name, common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := am.SetCode(contract, []byte{0x60, 0x01}); err != nil {
+		t.Fatal(err)
+	}
+
+	var verified []common.Name
+	reject := errors.New("rejected")
+	process := func(aType types.ActionType, from common.Name, data interface{}, verify types.AssetVerifier) error {
+		payload, err := rlp.EncodeToBytes(data)
+		if err != nil {
+			return err
+		}
+		action := types.NewAction(aType, from, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), payload, nil)
+		_, err = am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: 1, VerifyAsset: verify})
+		return err
+	}
+	accept := func(verifier common.Name, action *types.Action, id uint64, target common.Name, amount *big.Int) error {
+		if verifier != contract || id != assetID {
+			t.Fatalf("verifier %v asset %v", verifier, id)
+		}
+		verified = append(verified, target)
+		return nil
+	}
+	refuse := func(common.Name, *types.Action, uint64, common.Name, *big.Int) error { return reject }
+
+	tests := []struct {
+		name     string
+		from     common.Name
+		verifier common.Name
+		err      error
+	}{
+		{"notfounder", recipient, contract, asset.ErrNotAssetFounder},
+		{"notcontract", sender, recipient, ErrAssetVerifierInvalid},
+		{"set", sender, contract, nil},
+	}
+	for _, tt := range tests {
+		if err := process(types.SetAssetVerifier, tt.from, &SetAssetVerifier{AssetID: assetID, Verifier: tt.verifier}, nil); err != tt.err {
+			t.Fatalf("%s: err %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	if verifier, _ := am.GetAssetVerifier(assetID); verifier != contract {
+		t.Fatalf("asset verifier %v", verifier)
+	}
+
+	inc := &IncAsset{AssetId: assetID, Amount: big.NewInt(10), To: recipient}
+	if err := process(types.IncreaseAsset, sender, inc, nil); err != ErrAssetVerifierUnusable {
+		t.Fatalf("increase without verifier call err %v", err)
+	}
+	if err := process(types.IncreaseAsset, sender, inc, refuse); err != reject {
+		t.Fatalf("rejected increase err %v", err)
+	}
+	if err := process(types.IncreaseAsset, sender, inc, accept); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, assetID, 0); balance.Int64() != 10 {
+		t.Fatalf("recipient balance %v", balance)
+	}
+	if err := process(types.SetAssetOwner, sender, &UpdateAssetOwner{AssetID: assetID, Owner: recipient}, refuse); err != reject {
+		t.Fatalf("rejected owner change err %v", err)
+	}
+	if err := process(types.SetAssetOwner, sender, &UpdateAssetOwner{AssetID: assetID, Owner: recipient}, accept); err != nil {
+		t.Fatal(err)
+	}
+	if err := process(types.AcceptAssetOwner, recipient, &AcceptAssetOwner{AssetID: assetID}, accept); err != nil {
+		t.Fatal(err)
+	}
+	if len(verified) != 3 || verified[0] != recipient || verified[1] != recipient || verified[2] != recipient {
+		t.Fatalf("verified targets %v", verified)
+	}
+
+	// the founder, not the new owner, removes the verifier
+	if err := process(types.SetAssetVerifier, sender, &SetAssetVerifier{AssetID: assetID}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := process(types.IncreaseAsset, recipient, inc, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	assetAllowlistPrefix    = "assetAccessAllowlist"
+	assetAccessCountPrefix  = "assetAccessCount"
+	assetAccessMemberPrefix = "assetAccessMember"
+	assetAccessEntryPrefix  = "assetAccessEntry"
+)
+
+// MaxAccessListSize max accounts of each list of an asset access list update
+const MaxAccessListSize = 1024
+
+// AssetAccessList accounts permitted or banned from holding and transferring the
+// asset. If the allowlist is enabled only the allowed accounts have access, the
+// banned accounts never have access. The asset owner always has access.
+type AssetAccessList struct {
+	AssetID   uint64        `json:"assetId"`
+	Allowlist bool          `json:"allowlist"`
+	Allowed   []common.Name `json:"allowed"`
+	Banned    []common.Name `json:"banned"`
+}
+
+// accessEntry the access of one account in the access list, stored under its
+// own key so a transfer reads only the entries of its accounts. Index is the
+// position of the account in the member index used to list the entries.
+type accessEntry struct {
+	Banned bool
+	Index  uint64
+}
+
+//IsEmpty check the access list does not restrict any account
+func (l *AssetAccessList) IsEmpty() bool {
+	return !l.Allowlist && len(l.Allowed) == 0 && len(l.Banned) == 0
+}
+
+func accessEntryKey(assetID uint64, name common.Name) string {
+	return assetAccessEntryPrefix + strconv.FormatUint(assetID, 10) + "_" + name.String()
+}
+
+func accessMemberKey(assetID uint64, index uint64) string {
+	return assetAccessMemberPrefix + strconv.FormatUint(assetID, 10) + "_" + strconv.FormatUint(index, 10)
+}
+
+func (a *Asset) isAllowlist(assetID uint64) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, assetAllowlistPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+func (a *Asset) getAccessCount(assetID uint64) (uint64, error) {
+	b, err := a.sdb.Get(assetManagerName, assetAccessCountPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	var count uint64
+	if err := rlp.DecodeBytes(b, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (a *Asset) setAccessCount(assetID uint64, count uint64) error {
+	key := assetAccessCountPrefix + strconv.FormatUint(assetID, 10)
+	if count == 0 {
+		a.sdb.Delete(assetManagerName, key)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(count)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, key, b)
+	return nil
+}
+
+func (a *Asset) getAccessEntry(assetID uint64, name common.Name) (*accessEntry, error) {
+	b, err := a.sdb.Get(assetManagerName, accessEntryKey(assetID, name))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var entry accessEntry
+	if err := rlp.DecodeBytes(b, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (a *Asset) putAccessEntry(assetID uint64, name common.Name, entry *accessEntry) error {
+	b, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, accessEntryKey(assetID, name), b)
+	return nil
+}
+
+func (a *Asset) getAccessMember(assetID uint64, index uint64) (common.Name, error) {
+	b, err := a.sdb.Get(assetManagerName, accessMemberKey(assetID, index))
+	if err != nil {
+		return "", err
+	}
+	return common.Name(b), nil
+}
+
+//setAccess allow or ban the account in the access list of the asset
+func (a *Asset) setAccess(assetID uint64, name common.Name, banned bool) error {
+	entry, err := a.getAccessEntry(assetID, name)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		entry.Banned = banned
+		return a.putAccessEntry(assetID, name, entry)
+	}
+	count, err := a.getAccessCount(assetID)
+	if err != nil {
+		return err
+	}
+	if err := a.putAccessEntry(assetID, name, &accessEntry{Banned: banned, Index: count}); err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, accessMemberKey(assetID, count), []byte(name.String()))
+	return a.setAccessCount(assetID, count+1)
+}
+
+//removeAccess remove the account from the access list of the asset, the last
+//member takes its place in the member index
+func (a *Asset) removeAccess(assetID uint64, name common.Name, entry *accessEntry) error {
+	count, err := a.getAccessCount(assetID)
+	if err != nil {
+		return err
+	}
+	last := count - 1
+	if entry.Index != last {
+		lastName, err := a.getAccessMember(assetID, last)
+		if err != nil {
+			return err
+		}
+		lastEntry, err := a.getAccessEntry(assetID, lastName)
+		if err != nil {
+			return err
+		}
+		lastEntry.Index = entry.Index
+		if err := a.putAccessEntry(assetID, lastName, lastEntry); err != nil {
+			return err
+		}
+		a.sdb.Put(assetManagerName, accessMemberKey(assetID, entry.Index), []byte(lastName.String()))
+	}
+	a.sdb.Delete(assetManagerName, accessMemberKey(assetID, last))
+	a.sdb.Delete(assetManagerName, accessEntryKey(assetID, name))
+	return a.setAccessCount(assetID, last)
+}
+
+//GetAssetAccessList get the access list of the asset, it is empty if not set
+func (a *Asset) GetAssetAccessList(assetID uint64) (*AssetAccessList, error) {
+	allowlist, err := a.isAllowlist(assetID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := a.getAccessCount(assetID)
+	if err != nil {
+		return nil, err
+	}
+	list := &AssetAccessList{AssetID: assetID, Allowlist: allowlist}
+	for i := uint64(0); i < count; i++ {
+		name, err := a.getAccessMember(assetID, i)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := a.getAccessEntry(assetID, name)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Banned {
+			list.Banned = append(list.Banned, name)
+		} else {
+			list.Allowed = append(list.Allowed, name)
+		}
+	}
+	return list, nil
+}
+
+//SetAssetAccessList replace the access list of the asset, an empty list removes it.
+//An account both allowed and banned is banned.
+func (a *Asset) SetAssetAccessList(list *AssetAccessList) error {
+	if _, err := a.GetAssetObjectById(list.AssetID); err != nil {
+		return err
+	}
+	if len(list.Allowed) > MaxAccessListSize || len(list.Banned) > MaxAccessListSize {
+		return ErrAccessListTooLong
+	}
+	count, err := a.getAccessCount(list.AssetID)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < count; i++ {
+		name, err := a.getAccessMember(list.AssetID, i)
+		if err != nil {
+			return err
+		}
+		a.sdb.Delete(assetManagerName, accessMemberKey(list.AssetID, i))
+		a.sdb.Delete(assetManagerName, accessEntryKey(list.AssetID, name))
+	}
+	if err := a.setAccessCount(list.AssetID, 0); err != nil {
+		return err
+	}
+
+	key := assetAllowlistPrefix + strconv.FormatUint(list.AssetID, 10)
+	if list.Allowlist {
+		a.sdb.Put(assetManagerName, key, []byte{1})
+	} else {
+		a.sdb.Delete(assetManagerName, key)
+	}
+	for _, name := range list.Allowed {
+		if err := a.setAccess(list.AssetID, name, false); err != nil {
+			return err
+		}
+	}
+	for _, name := range list.Banned {
+		if err := a.setAccess(list.AssetID, name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//checkAccessList check all accounts have access to the asset, the asset owner and the asset manager are exempt
+func (a *Asset) checkAccessList(ast *AssetObject, names ...common.Name) bool {
+	assetID := ast.GetAssetId()
+	allowlist, err := a.isAllowlist(assetID)
+	if err != nil {
+		return false
+	}
+	if !allowlist {
+		count, err := a.getAccessCount(assetID)
+		if err != nil {
+			return false
+		}
+		if count == 0 {
+			return true
+		}
+	}
+	for _, name := range names {
+		if name == ast.GetAssetOwner() || name.String() == assetManagerName {
+			continue
+		}
+		entry, err := a.getAccessEntry(assetID, name)
+		if err != nil {
+			return false
+		}
+		if entry == nil {
+			if allowlist {
+				return false
+			}
+			continue
+		}
+		if entry.Banned {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAsset_AccessList(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("a123456789aeee")
+	id, err := a.IssueAsset("compliance", 0, 0, "cpl", big.NewInt(1), 0, common.Name(""), owner, big.NewInt(10), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := common.Name("alice"), common.Name("bob")
+	if !a.HasAccess(id, alice, bob) {
+		t.Fatal("asset without access list should be accessible")
+	}
+
+	if err := a.SetAssetAccessList(&AssetAccessList{AssetID: id, Banned: []common.Name{bob}}); err != nil {
+		t.Fatal(err)
+	}
+	if a.HasAccess(id, alice, bob) {
+		t.Fatal("banned account should have no access")
+	}
+	if !a.HasAccess(id, alice, owner) {
+		t.Fatal("account not banned should have access")
+	}
+
+	if err := a.SetAssetAccessList(&AssetAccessList{AssetID: id, Allowlist: true, Allowed: []common.Name{alice}}); err != nil {
+		t.Fatal(err)
+	}
+	if !a.HasAccess(id, owner, alice) || !a.HasAccess(id, common.Name(assetManagerName), alice) {
+		t.Fatal("owner and allowed account should have access")
+	}
+	if a.HasAccess(id, alice, bob) {
+		t.Fatal("account not allowed should have no access")
+	}
+
+	if err := a.SetAssetAccessList(&AssetAccessList{AssetID: id}); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := a.GetAssetAccessList(id); err != nil || !list.IsEmpty() {
+		t.Fatalf("GetAssetAccessList = %v, %v", list, err)
+	}
+	if !a.HasAccess(id, alice, bob) {
+		t.Fatal("removed access list should not restrict")
+	}
+
+	if err := a.SetAssetAccessList(&AssetAccessList{AssetID: id, Banned: make([]common.Name, MaxAccessListSize+1)}); err != ErrAccessListTooLong {
+		t.Errorf("SetAssetAccessList err = %v, want %v", err, ErrAccessListTooLong)
+	}
+	if err := a.SetAssetAccessList(&AssetAccessList{AssetID: id + 100}); err != ErrAssetNotExist {
+		t.Errorf("SetAssetAccessList err = %v, want %v", err, ErrAssetNotExist)
+	}
+}
+
+func TestAsset_SetAssetBanned(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("a123456789aeee")
+	id, err := a.IssueAsset("sanctioned", 0, 0, "snc", big.NewInt(1), 0, common.Name(""), owner, big.NewInt(10), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob, carol := common.Name("alice"), common.Name("bob"), common.Name("carol")
+	for _, name := range []common.Name{alice, bob, carol} {
+		if err := a.SetAssetBanned(id, name, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.HasAccess(id, owner, alice) || a.HasAccess(id, owner, carol) {
+		t.Fatal("banned account should have no access")
+	}
+
+	// unbanning the first member moves the last member into its place
+	if err := a.SetAssetBanned(id, alice, false); err != nil {
+		t.Fatal(err)
+	}
+	if !a.HasAccess(id, owner, alice) || a.HasAccess(id, owner, carol) {
+		t.Fatal("only the unbanned account should have access")
+	}
+	list, err := a.GetAssetAccessList(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Banned) != 2 || list.Banned[0] != carol || list.Banned[1] != bob {
+		t.Fatalf("banned = %v, want [%v %v]", list.Banned, carol, bob)
+	}
+
+	if err := a.SetAssetBanned(id, carol, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetAssetBanned(id, bob, false); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := a.GetAssetAccessList(id); err != nil || !list.IsEmpty() {
+		t.Fatalf("GetAssetAccessList = %v, %v", list, err)
+	}
+	if err := a.SetAssetBanned(id, bob, false); err != nil {
+		t.Errorf("unban account not banned err = %v", err)
+	}
+}
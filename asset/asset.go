@@ -115,6 +115,15 @@ func (a *Asset) GetAssetObjectByTime(assetID uint64, time uint64) (*AssetObject,
 	return &asset, nil
 }
 
+//AssetObjectKeyID get the id of the asset whose record is stored at the state key of the account
+func AssetObjectKeyID(account string, key string) (uint64, bool) {
+	if account != assetManagerName || !strings.HasPrefix(key, assetObjectPrefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(key, assetObjectPrefix), 10, 64)
+	return id, err == nil
+}
+
 //GetAssetIdByName get assset id by asset name
 func (a *Asset) GetAssetIdByName(assetName string) (uint64, error) {
 	if assetName == "" {
@@ -152,10 +161,14 @@ func (a *Asset) GetAssetObjectById(id uint64) (*AssetObject, error) {
 	if len(b) == 0 {
 		return nil, ErrAssetNotExist
 	}
+	if asset := getCachedAsset(id, b); asset != nil {
+		return asset, nil
+	}
 	var asset AssetObject
 	if err := rlp.DecodeBytes(b, &asset); err != nil {
 		return nil, err
 	}
+	setCachedAsset(id, b, &asset)
 	return &asset, nil
 }
 
@@ -176,6 +189,11 @@ func (a *Asset) getAssetCount() (uint64, error) {
 	return assetCount, nil
 }
 
+//GetAssetCount get asset total count, the asset ids are in [0, count)
+func (a *Asset) GetAssetCount() (uint64, error) {
+	return a.getAssetCount()
+}
+
 //InitAssetCount init asset count
 func (a *Asset) InitAssetCount() {
 	_, err := a.getAssetCount()
@@ -229,6 +247,9 @@ func (a *Asset) addNewAssetObject(ao *AssetObject) (uint64, error) {
 	}
 
 	ao.SetAssetId(assetCount)
+	if err := a.reserveAssetSymbol(ao); err != nil {
+		return 0, err
+	}
 	//store asset object
 	aobject, err := rlp.EncodeToBytes(ao)
 	if err != nil {
@@ -252,6 +273,12 @@ func (a *Asset) addNewAssetObject(ao *AssetObject) (uint64, error) {
 	//store assetCount
 	a.sdb.Put(assetManagerName, assetCountPrefix, aid)
 
+	if err := a.markSupplyChange(assetCount, ao.GetAssetAmount(), big.NewInt(0)); err != nil {
+		return 0, err
+	}
+	if err := a.indexAssetObject(nil, ao); err != nil {
+		return 0, err
+	}
 	return assetCount, nil
 }
 
@@ -262,6 +289,14 @@ func (a *Asset) SetAssetObject(ao *AssetObject) error {
 	}
 	assetId := ao.GetAssetId()
 
+	old, err := a.GetAssetObjectById(assetId)
+	if err != nil && err != ErrAssetNotExist {
+		return err
+	}
+	if err := a.indexAssetObject(old, ao); err != nil {
+		return err
+	}
+
 	b, err := rlp.EncodeToBytes(ao)
 	if err != nil {
 		return err
@@ -351,7 +386,7 @@ func (a *Asset) DestroyAsset(accountName common.Name, assetId uint64, amount *bi
 	if err != nil {
 		return err
 	}
-	return nil
+	return a.markSupplyChange(assetId, big.NewInt(0), amount)
 }
 
 //IncreaseAsset increase asset, upperlimit == 0 means no upper limit
@@ -394,7 +429,7 @@ func (a *Asset) IncreaseAsset(accountName common.Name, assetId uint64, amount *b
 	if err != nil {
 		return err
 	}
-	return nil
+	return a.markSupplyChange(assetId, amount, big.NewInt(0))
 }
 
 //UpdateAsset change asset info
@@ -550,19 +585,25 @@ func (a *Asset) IsValidAssetOwner(fromName common.Name, assetPrex string, assetN
 	return 0, false
 }
 
-// HasAccess contract asset access
+// HasAccess contract asset access and asset access list
 func (a *Asset) HasAccess(assetID uint64, names ...common.Name) bool {
 	ast, _ := a.GetAssetObjectById(assetID)
-	if ast != nil && len(ast.Contract.String()) != 0 {
+	if ast == nil {
+		return true
+	}
+	if len(ast.Contract.String()) != 0 {
+		hasContract := false
 		for _, name := range names {
 			if name == ast.Contract {
-				return true
+				hasContract = true
+				break
 			}
 		}
-	} else {
-		return true
+		if !hasContract {
+			return false
+		}
 	}
-	return false
+	return a.checkAccessList(ast, names...)
 }
 
 func (a *Asset) IncStats(assetID uint64) error {
@@ -99,6 +99,22 @@ func NewAssetObjectNoCheck(assetName string, number uint64, symbol string, amoun
 	return &ao
 }
 
+// Copy returns a deep copy of the asset object.
+func (ao *AssetObject) Copy() *AssetObject {
+	cpy := *ao
+	cpy.Amount = copyBig(ao.Amount)
+	cpy.AddIssue = copyBig(ao.AddIssue)
+	cpy.UpperLimit = copyBig(ao.UpperLimit)
+	return &cpy
+}
+
+func copyBig(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+}
+
 func (ao *AssetObject) GetAssetId() uint64 {
 	return ao.AssetId
 }
@@ -162,6 +178,10 @@ func (ao *AssetObject) GetUpperLimit() *big.Int {
 	return ao.UpperLimit
 }
 
+func (ao *AssetObject) SetUpperLimit(limit *big.Int) {
+	ao.UpperLimit = limit
+}
+
 func (ao *AssetObject) GetContract() common.Name {
 	return ao.Contract
 }
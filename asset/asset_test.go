@@ -22,8 +22,10 @@ import (
 	"testing"
 
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/state"
 	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+	"github.com/fractalplatform/fractal/utils/rlp"
 )
 
 var astdb = getStateDB()
@@ -38,8 +40,19 @@ func getStateDB() *state.StateDB {
 		//t.Fatal("test getStateDB failure ", err)
 		return nil
 	}
+	setForkID(statedb, params.ForkID4)
 	return statedb
 }
+
+//setForkID store the fork information of the fork controller at the fork id
+func setForkID(statedb *state.StateDB, id uint64) {
+	SetChainName(common.Name("systestchain"))
+	b, err := rlp.EncodeToBytes(&forkInfo{CurForkID: id})
+	if err != nil {
+		panic(err)
+	}
+	statedb.Put(chainName, forkInfoKey, b)
+}
 func getAsset() *Asset {
 	return NewAsset(astdb)
 }
@@ -554,3 +567,35 @@ func TestAsset_HasAccesss(t *testing.T) {
 		}
 	}
 }
+
+func TestAsset_SharedCache(t *testing.T) {
+	SetSharedCacheSize(16)
+	defer SetSharedCacheSize(0)
+
+	a := NewAsset(getStateDB())
+	ao, _ := NewAssetObject("cachecoin", 0, "cc", big.NewInt(1000), 10, common.Name(""), common.Name("a123456789aeee"), big.NewInt(0), common.Name(""), "")
+	id, err := a.IssueAssetObject(ao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := a.GetAssetObjectById(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.GetAssetAmount().SetInt64(1)
+	second, err := a.GetAssetObjectById(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.GetAssetAmount().Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("cached asset modified by the caller, amount %v", second.GetAssetAmount())
+	}
+
+	second.SetAssetOwner(common.Name("a123456789bbbb"))
+	if err := a.SetAssetObject(second); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := a.GetAssetObjectById(id); got.GetAssetOwner() != common.Name("a123456789bbbb") {
+		t.Fatalf("stale cached asset, owner %v", got.GetAssetOwner())
+	}
+}
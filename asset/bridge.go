@@ -0,0 +1,140 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// A wrapped asset represents an asset of an external chain. Its supply is only
+// minted and burned by the bridge account, against the proofs of the deposits
+// and withdrawals on the origin chain.
+var (
+	wrappedAssetPrefix = "wrappedAsset"
+	bridgeProofPrefix  = "bridgeProof"
+)
+
+const (
+	// MaxOriginChainLength max length of the origin chain identifier
+	MaxOriginChainLength = 64
+	// MaxOriginContractLength max length of the origin contract address
+	MaxOriginContractLength = 128
+	// MaxBridgeProofLength max length of the bridge proof
+	MaxBridgeProofLength = 1024
+)
+
+// WrappedAsset the origin of the wrapped external asset
+type WrappedAsset struct {
+	AssetID        uint64      `json:"assetId"`
+	OriginChain    string      `json:"originChain"`
+	OriginContract string      `json:"originContract"`
+	Bridge         common.Name `json:"bridge"`
+}
+
+//GetWrappedAsset get the origin of the wrapped asset, nil if the asset is not wrapped
+func (a *Asset) GetWrappedAsset(assetID uint64) (*WrappedAsset, error) {
+	b, err := a.sdb.Get(assetManagerName, wrappedAssetPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	wrapped := &WrappedAsset{}
+	if err := rlp.DecodeBytes(b, wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped, nil
+}
+
+//IsWrappedAsset check the asset is a wrapped external asset
+func (a *Asset) IsWrappedAsset(assetID uint64) (bool, error) {
+	wrapped, err := a.GetWrappedAsset(assetID)
+	if err != nil {
+		return false, err
+	}
+	return wrapped != nil, nil
+}
+
+//SetWrappedAsset flag the asset as wrapped, the origin can not change once set
+func (a *Asset) SetWrappedAsset(wrapped *WrappedAsset) error {
+	if len(wrapped.OriginChain) == 0 || len(wrapped.OriginChain) > MaxOriginChainLength ||
+		len(wrapped.OriginContract) == 0 || len(wrapped.OriginContract) > MaxOriginContractLength ||
+		len(wrapped.Bridge) == 0 {
+		return ErrWrappedAssetInvalid
+	}
+	if _, err := a.GetAssetObjectById(wrapped.AssetID); err != nil {
+		return err
+	}
+	if old, err := a.GetWrappedAsset(wrapped.AssetID); err != nil {
+		return err
+	} else if old != nil {
+		return ErrWrappedAssetIsExist
+	}
+	b, err := rlp.EncodeToBytes(wrapped)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, wrappedAssetPrefix+strconv.FormatUint(wrapped.AssetID, 10), b)
+	return nil
+}
+
+//CheckBridge check the account is the bridge of the wrapped asset
+func (a *Asset) CheckBridge(fromName common.Name, assetID uint64) (*WrappedAsset, error) {
+	wrapped, err := a.GetWrappedAsset(assetID)
+	if err != nil {
+		return nil, err
+	}
+	if wrapped == nil {
+		return nil, ErrWrappedAssetNotExist
+	}
+	if wrapped.Bridge != fromName {
+		return nil, ErrBridgeMismatch
+	}
+	return wrapped, nil
+}
+
+func bridgeProofKey(assetID uint64, proof []byte) string {
+	return bridgeProofPrefix + strconv.FormatUint(assetID, 10) + crypto.Keccak256Hash(proof).String()
+}
+
+//IsBridgeProofUsed check the proof has been consumed by the wrapped asset
+func (a *Asset) IsBridgeProofUsed(assetID uint64, proof []byte) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, bridgeProofKey(assetID, proof))
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+//UseBridgeProof consume the proof of the wrapped asset, each proof is used once
+func (a *Asset) UseBridgeProof(assetID uint64, proof []byte) error {
+	if len(proof) == 0 || len(proof) > MaxBridgeProofLength {
+		return ErrBridgeProofInvalid
+	}
+	if used, err := a.IsBridgeProofUsed(assetID, proof); err != nil {
+		return err
+	} else if used {
+		return ErrBridgeProofUsed
+	}
+	a.sdb.Put(assetManagerName, bridgeProofKey(assetID, proof), []byte{1})
+	return nil
+}
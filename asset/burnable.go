@@ -0,0 +1,44 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import "strconv"
+
+var assetBurnDisabledPrefix = "assetBurnDisabled"
+
+//IsAssetBurnable check the holders can burn their balances of the asset, it is burnable if not set
+func (a *Asset) IsAssetBurnable(assetID uint64) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, assetBurnDisabledPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return false, err
+	}
+	return len(b) == 0, nil
+}
+
+//SetAssetBurnable permit or forbid the holders burning their balances of the asset
+func (a *Asset) SetAssetBurnable(assetID uint64, burnable bool) error {
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return err
+	}
+	key := assetBurnDisabledPrefix + strconv.FormatUint(assetID, 10)
+	if burnable {
+		a.sdb.Delete(assetManagerName, key)
+		return nil
+	}
+	a.sdb.Put(assetManagerName, key, []byte{1})
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/fractalplatform/fractal/metrics"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+var (
+	sharedCacheHitMeter  = metrics.NewRegisteredMeter("asset/cache/hit", nil)
+	sharedCacheMissMeter = metrics.NewRegisteredMeter("asset/cache/miss", nil)
+
+	sharedCache atomic.Value // *lru.Cache of *assetCacheEntry by asset id
+)
+
+// assetCacheEntry decoded asset object with the encoded bytes it decoded from,
+// the entry is never modified once cached.
+type assetCacheEntry struct {
+	raw   []byte
+	asset *AssetObject
+}
+
+// SetSharedCacheSize keeps the last size decoded asset objects for all the
+// asset managers. 0 disables the shared cache.
+func SetSharedCacheSize(size int) {
+	var cache *lru.Cache
+	if size > 0 {
+		cache, _ = lru.New(size)
+	}
+	sharedCache.Store(cache)
+}
+
+// getCachedAsset returns a copy of the cached asset object if the encoded bytes
+// still match the state.
+func getCachedAsset(id uint64, raw []byte) *AssetObject {
+	cache, _ := sharedCache.Load().(*lru.Cache)
+	if cache == nil {
+		return nil
+	}
+	if v, ok := cache.Get(id); ok {
+		if entry := v.(*assetCacheEntry); bytes.Equal(entry.raw, raw) {
+			sharedCacheHitMeter.Mark(1)
+			return entry.asset.Copy()
+		}
+	}
+	sharedCacheMissMeter.Mark(1)
+	return nil
+}
+
+func setCachedAsset(id uint64, raw []byte, asset *AssetObject) {
+	if cache, _ := sharedCache.Load().(*lru.Cache); cache != nil {
+		cache.Add(id, &assetCacheEntry{raw: raw, asset: asset.Copy()})
+	}
+}
@@ -27,3 +27,9 @@ type Config struct {
 }
 
 const MaxDescriptionLength uint64 = 255
+
+// asset metadata limits, see AssetMetadata
+const (
+	MaxMetadataURLLength      uint64 = 255
+	MaxMetadataExtendedLength uint64 = 4096
+)
@@ -0,0 +1,149 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var assetConversionPrefix = "assetConversion"
+
+// AssetConversion fixed ratio between the parent asset and its wrapped
+// sub-asset, one unit of the parent asset converts to Ratio units of the
+// sub-asset.
+type AssetConversion struct {
+	ParentID uint64 `json:"parentId"`
+	SubID    uint64 `json:"subId"`
+	Ratio    uint64 `json:"ratio"`
+}
+
+//WrapAmount get the sub-asset amount converted from the parent amount
+func (c *AssetConversion) WrapAmount(amount *big.Int) *big.Int {
+	return new(big.Int).Mul(amount, new(big.Int).SetUint64(c.Ratio))
+}
+
+//UnwrapAmount get the parent amount converted from the sub-asset amount, the amount must be a multiple of the ratio
+func (c *AssetConversion) UnwrapAmount(amount *big.Int) (*big.Int, error) {
+	parent, rem := new(big.Int).QuoRem(amount, new(big.Int).SetUint64(c.Ratio), new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, ErrConversionAmount
+	}
+	return parent, nil
+}
+
+//isSubAssetName check the sub name is under the parent name
+func isSubAssetName(parent string, sub string) bool {
+	return strings.HasPrefix(sub, parent+".")
+}
+
+//GetAssetConversion get the conversion of the wrapped sub-asset
+func (a *Asset) GetAssetConversion(subID uint64) (*AssetConversion, error) {
+	b, err := a.sdb.Get(assetManagerName, assetConversionPrefix+strconv.FormatUint(subID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrConversionNotExist
+	}
+	var conv AssetConversion
+	if err := rlp.DecodeBytes(b, &conv); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+//SetAssetConversion designate the sub-asset as the wrapped representation of its parent asset
+func (a *Asset) SetAssetConversion(conv *AssetConversion) error {
+	if conv.Ratio == 0 || conv.ParentID == conv.SubID {
+		return ErrConversionInvalid
+	}
+	parent, err := a.GetAssetObjectById(conv.ParentID)
+	if err != nil {
+		return err
+	}
+	sub, err := a.GetAssetObjectById(conv.SubID)
+	if err != nil {
+		return err
+	}
+	if !isSubAssetName(parent.GetAssetName(), sub.GetAssetName()) {
+		return ErrConversionInvalid
+	}
+	if old, err := a.GetAssetConversion(conv.SubID); err == nil && old.Ratio != conv.Ratio && sub.GetAssetAmount().Sign() != 0 {
+		return ErrConversionInUse
+	}
+	b, err := rlp.EncodeToBytes(conv)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, assetConversionPrefix+strconv.FormatUint(conv.SubID, 10), b)
+	return nil
+}
+
+//RemoveAssetConversion remove the conversion of the sub-asset, the wrapped supply must be unwrapped first
+func (a *Asset) RemoveAssetConversion(subID uint64) error {
+	if _, err := a.GetAssetConversion(subID); err != nil {
+		return err
+	}
+	sub, err := a.GetAssetObjectById(subID)
+	if err != nil {
+		return err
+	}
+	if sub.GetAssetAmount().Sign() != 0 {
+		return ErrConversionSupply
+	}
+	a.sdb.Delete(assetManagerName, assetConversionPrefix+strconv.FormatUint(subID, 10))
+	return nil
+}
+
+//ConvertAsset move the supply from one asset to the other, the added issue of the assets is not changed
+func (a *Asset) ConvertAsset(fromID uint64, fromAmount *big.Int, toID uint64, toAmount *big.Int) error {
+	if fromAmount.Sign() < 0 || toAmount.Sign() < 0 {
+		return ErrNegativeAmount
+	}
+	from, err := a.GetAssetObjectById(fromID)
+	if err != nil {
+		return err
+	}
+	to, err := a.GetAssetObjectById(toID)
+	if err != nil {
+		return err
+	}
+	fromTotal := new(big.Int).Sub(from.GetAssetAmount(), fromAmount)
+	if fromTotal.Sign() < 0 {
+		return ErrDestroyLimit
+	}
+	toTotal := new(big.Int).Add(to.GetAssetAmount(), toAmount)
+	if to.GetUpperLimit().Sign() > 0 && toTotal.Cmp(to.GetUpperLimit()) > 0 {
+		return ErrUpperLimit
+	}
+	from.SetAssetAmount(fromTotal)
+	if err := a.SetAssetObject(from); err != nil {
+		return err
+	}
+	to.SetAssetAmount(toTotal)
+	if err := a.SetAssetObject(to); err != nil {
+		return err
+	}
+	if err := a.markSupplyChange(fromID, big.NewInt(0), fromAmount); err != nil {
+		return err
+	}
+	return a.markSupplyChange(toID, toAmount, big.NewInt(0))
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var assetSubDelegatePrefix = "assetSubDelegate"
+
+// SubAssetDelegation the sub-assets the delegate may issue under the namespace of the asset
+type SubAssetDelegation struct {
+	Quota  uint64 `json:"quota"`
+	Issued uint64 `json:"issued"`
+}
+
+func subDelegateKey(assetID uint64, delegate common.Name) string {
+	return assetSubDelegatePrefix + strconv.FormatUint(assetID, 10) + "_" + delegate.String()
+}
+
+//GetSubAssetDelegation get the delegation of the delegate on the asset, empty if not delegated
+func (a *Asset) GetSubAssetDelegation(assetID uint64, delegate common.Name) (*SubAssetDelegation, error) {
+	b, err := a.sdb.Get(assetManagerName, subDelegateKey(assetID, delegate))
+	if err != nil {
+		return nil, err
+	}
+	var delegation SubAssetDelegation
+	if len(b) == 0 {
+		return &delegation, nil
+	}
+	if err := rlp.DecodeBytes(b, &delegation); err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (a *Asset) setSubAssetDelegation(assetID uint64, delegate common.Name, delegation *SubAssetDelegation) error {
+	b, err := rlp.EncodeToBytes(delegation)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, subDelegateKey(assetID, delegate), b)
+	return nil
+}
+
+//SetSubAssetDelegate set the quota of the sub-assets the delegate may issue, the sub-assets issued are kept, zero quota revoke the delegation
+func (a *Asset) SetSubAssetDelegate(assetID uint64, delegate common.Name, quota uint64) error {
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return err
+	}
+	if quota == 0 {
+		a.sdb.Delete(assetManagerName, subDelegateKey(assetID, delegate))
+		return nil
+	}
+	delegation, err := a.GetSubAssetDelegation(assetID, delegate)
+	if err != nil {
+		return err
+	}
+	delegation.Quota = quota
+	return a.setSubAssetDelegation(assetID, delegate, delegation)
+}
+
+//UseSubAssetDelegation count a sub-asset issued by the delegate, fails if the quota is used up
+func (a *Asset) UseSubAssetDelegation(assetID uint64, delegate common.Name) error {
+	delegation, err := a.GetSubAssetDelegation(assetID, delegate)
+	if err != nil {
+		return err
+	}
+	if delegation.Issued >= delegation.Quota {
+		return ErrSubAssetQuota
+	}
+	delegation.Issued++
+	return a.setSubAssetDelegation(assetID, delegate, delegation)
+}
@@ -34,4 +34,46 @@ var (
 	ErrAssetManagerNotExist = errors.New("asset manager name not exist")
 	ErrDetailTooLong        = errors.New("detail info exceed maxmium")
 	ErrNegativeAmount       = errors.New("negative amount")
+	ErrTokenNotListed       = errors.New("asset not in the token list")
+	ErrNotTokenCurator      = errors.New("not the token list curator")
+	ErrConversionNotExist   = errors.New("asset conversion not exist")
+	ErrConversionInvalid    = errors.New("asset conversion invalid")
+	ErrConversionInUse      = errors.New("asset conversion ratio can not change with supply")
+	ErrConversionSupply     = errors.New("asset conversion can not remove with wrapped supply")
+	ErrConversionAmount     = errors.New("amount is not a multiple of the conversion ratio")
+	ErrAccessListTooLong    = errors.New("asset access list too long")
+	ErrNFTCollection        = errors.New("asset is not a non-fungible collection")
+	ErrNFTokenNotExist      = errors.New("non-fungible token not exist")
+	ErrNFTokenIsExist       = errors.New("non-fungible token is exist")
+	ErrNFTokenOwner         = errors.New("non-fungible token owner mismatch")
+	ErrNFTURITooLong        = errors.New("non-fungible token uri too long")
+	ErrTransferFeeRate      = errors.New("asset transfer fee rate exceeds the maximum")
+	ErrMetadataTooLong      = errors.New("asset metadata exceed maxmium")
+	ErrMetadataInvalid      = errors.New("asset metadata extended is not a json object")
+	ErrSubAssetQuota        = errors.New("sub-asset delegation quota used up")
+	ErrPendingOwnerNotExist = errors.New("asset pending owner not exist")
+	ErrNotPendingOwner      = errors.New("not the pending owner of the asset")
+	ErrPendingOwnerExpired  = errors.New("asset pending owner is expired")
+	ErrAssetRoleInvalid     = errors.New("asset role invalid")
+	ErrAssetRoleIsExist     = errors.New("asset role is granted already")
+	ErrAssetRoleNotExist    = errors.New("asset role not exist")
+	ErrAssetRoleMembersFull = errors.New("asset role members exceed maximum")
+	ErrAssetRoleMismatch    = errors.New("not the owner or granted the role of the asset")
+	ErrWrappedAssetInvalid  = errors.New("wrapped asset origin invalid")
+	ErrWrappedAssetIsExist  = errors.New("asset is wrapped already")
+	ErrWrappedAssetNotExist = errors.New("asset is not a wrapped asset")
+	ErrBridgeMismatch       = errors.New("not the bridge of the wrapped asset")
+	ErrBridgeProofInvalid   = errors.New("bridge proof invalid")
+	ErrBridgeProofUsed      = errors.New("bridge proof is used already")
+	ErrSymbolIsExist        = errors.New("asset symbol is exist in the namespace")
+	ErrUpperLimitInvalid    = errors.New("asset upper limit invalid")
+	ErrUpperLimitRaise      = errors.New("asset upper limit can only be raised by the approvers")
+	ErrLimitApproverInvalid = errors.New("asset upper limit approvers invalid")
+	ErrPendingLimitNotExist = errors.New("asset pending upper limit not exist")
+	ErrPendingLimitMismatch = errors.New("asset pending upper limit mismatch")
+	ErrPendingLimitExpired  = errors.New("asset pending upper limit is expired")
+	ErrNotLimitApprover     = errors.New("not the upper limit approver of the asset")
+	ErrLimitApproved        = errors.New("asset upper limit is approved already")
+	ErrNotAssetFounder      = errors.New("not the founder of the asset")
+	ErrAssetProofMismatch   = errors.New("asset proof mismatch")
 )
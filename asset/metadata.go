@@ -0,0 +1,93 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var assetMetadataPrefix = "assetMetadata"
+
+// AssetMetadata the structured metadata of the asset, the extended field is a json
+// object for the fields not covered by the standard ones.
+type AssetMetadata struct {
+	IconURL        string      `json:"iconURL"`
+	Website        string      `json:"website"`
+	WhitepaperHash common.Hash `json:"whitepaperHash"`
+	Extended       string      `json:"extended"`
+}
+
+func (m *AssetMetadata) isEmpty() bool {
+	return m.IconURL == "" && m.Website == "" && m.WhitepaperHash == (common.Hash{}) && m.Extended == ""
+}
+
+func (m *AssetMetadata) validate() error {
+	if uint64(len(m.IconURL)) > MaxMetadataURLLength || uint64(len(m.Website)) > MaxMetadataURLLength {
+		return ErrMetadataTooLong
+	}
+	if uint64(len(m.Extended)) > MaxMetadataExtendedLength {
+		return ErrMetadataTooLong
+	}
+	if m.Extended != "" {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(m.Extended), &obj); err != nil {
+			return ErrMetadataInvalid
+		}
+	}
+	return nil
+}
+
+//GetAssetMetadata get the metadata of the asset, empty if not set
+func (a *Asset) GetAssetMetadata(assetID uint64) (*AssetMetadata, error) {
+	b, err := a.sdb.Get(assetManagerName, assetMetadataPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return nil, err
+	}
+	var metadata AssetMetadata
+	if len(b) == 0 {
+		return &metadata, nil
+	}
+	if err := rlp.DecodeBytes(b, &metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+//SetAssetMetadata replace the metadata of the asset, empty metadata remove it
+func (a *Asset) SetAssetMetadata(assetID uint64, metadata *AssetMetadata) error {
+	if err := metadata.validate(); err != nil {
+		return err
+	}
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return err
+	}
+	key := assetMetadataPrefix + strconv.FormatUint(assetID, 10)
+	if metadata.isEmpty() {
+		a.sdb.Delete(assetManagerName, key)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(metadata)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, key, b)
+	return nil
+}
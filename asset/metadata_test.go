@@ -0,0 +1,68 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAsset_Metadata(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("a123456789aeee")
+	id, err := a.IssueAsset("metacoin", 0, 0, "meta", big.NewInt(1), 0, common.Name(""), owner, big.NewInt(10), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata, err := a.GetAssetMetadata(id); err != nil || *metadata != (AssetMetadata{}) {
+		t.Fatalf("unset metadata = %v, %v", metadata, err)
+	}
+
+	tests := []struct {
+		name     string
+		metadata AssetMetadata
+		err      error
+	}{
+		{"icon too long", AssetMetadata{IconURL: strings.Repeat("a", int(MaxMetadataURLLength)+1)}, ErrMetadataTooLong},
+		{"extended too long", AssetMetadata{Extended: `{"a":"` + strings.Repeat("a", int(MaxMetadataExtendedLength)) + `"}`}, ErrMetadataTooLong},
+		{"extended not json", AssetMetadata{Extended: "{"}, ErrMetadataInvalid},
+		{"extended not object", AssetMetadata{Extended: "[1,2]"}, ErrMetadataInvalid},
+		{"valid", AssetMetadata{IconURL: "https://meta.io/icon.png", Website: "https://meta.io", WhitepaperHash: common.HexToHash("0x01"), Extended: `{"discord":"meta"}`}, nil},
+	}
+	for _, tt := range tests {
+		if err := a.SetAssetMetadata(id, &tt.metadata); err != tt.err {
+			t.Fatalf("%s: err %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	metadata, err := a.GetAssetMetadata(id)
+	if err != nil || *metadata != tests[len(tests)-1].metadata {
+		t.Fatalf("metadata = %v, %v", metadata, err)
+	}
+	if err := a.SetAssetMetadata(id+1, metadata); err == nil {
+		t.Fatal("set metadata of missing asset should fail")
+	}
+
+	if err := a.SetAssetMetadata(id, &AssetMetadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if metadata, err := a.GetAssetMetadata(id); err != nil || *metadata != (AssetMetadata{}) {
+		t.Fatalf("removed metadata = %v, %v", metadata, err)
+	}
+}
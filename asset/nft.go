@@ -0,0 +1,147 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	nftCollectionPrefix = "nftCollection"
+	nftTokenPrefix      = "nftToken"
+)
+
+// MaxNFTURILength max length of the metadata uri of a non-fungible token
+const MaxNFTURILength = 255
+
+// NFToken a unique token of a non-fungible asset. The asset is the collection
+// of the tokens, each token counts as one unit of the asset balance of its owner.
+type NFToken struct {
+	AssetID uint64      `json:"assetId"`
+	TokenID uint64      `json:"tokenId"`
+	Owner   common.Name `json:"owner"`
+	URI     string      `json:"uri"`
+}
+
+func nftTokenKey(assetID uint64, tokenID uint64) string {
+	return nftTokenPrefix + strconv.FormatUint(assetID, 10) + ":" + strconv.FormatUint(tokenID, 10)
+}
+
+//IsNFTCollection check the asset is a collection of non-fungible tokens
+func (a *Asset) IsNFTCollection(assetID uint64) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, nftCollectionPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+//GetNFToken get the non-fungible token of the asset
+func (a *Asset) GetNFToken(assetID uint64, tokenID uint64) (*NFToken, error) {
+	b, err := a.sdb.Get(assetManagerName, nftTokenKey(assetID, tokenID))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrNFTokenNotExist
+	}
+	var token NFToken
+	if err := rlp.DecodeBytes(b, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (a *Asset) setNFToken(token *NFToken) error {
+	b, err := rlp.EncodeToBytes(token)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, nftTokenKey(token.AssetID, token.TokenID), b)
+	return nil
+}
+
+//IssueNFToken mint the token and increase the asset amount by one. An asset without
+//decimals and supply becomes a non-fungible collection with its first token.
+func (a *Asset) IssueNFToken(token *NFToken) error {
+	if token.Owner == "" {
+		return ErrAccountNameNull
+	}
+	if len(token.URI) > MaxNFTURILength {
+		return ErrNFTURITooLong
+	}
+	ast, err := a.GetAssetObjectById(token.AssetID)
+	if err != nil {
+		return err
+	}
+	isCollection, err := a.IsNFTCollection(token.AssetID)
+	if err != nil {
+		return err
+	}
+	if !isCollection {
+		if ast.GetDecimals() != 0 || ast.GetAssetAmount().Sign() != 0 {
+			return ErrNFTCollection
+		}
+		a.sdb.Put(assetManagerName, nftCollectionPrefix+strconv.FormatUint(token.AssetID, 10), []byte{1})
+	}
+	if _, err := a.GetNFToken(token.AssetID, token.TokenID); err != ErrNFTokenNotExist {
+		if err == nil {
+			return ErrNFTokenIsExist
+		}
+		return err
+	}
+	if err := a.IncreaseAsset(ast.GetAssetOwner(), token.AssetID, big.NewInt(1)); err != nil {
+		return err
+	}
+	return a.setNFToken(token)
+}
+
+//TransferNFToken change the owner of the token
+func (a *Asset) TransferNFToken(assetID uint64, tokenID uint64, from common.Name, to common.Name) error {
+	token, err := a.GetNFToken(assetID, tokenID)
+	if err != nil {
+		return err
+	}
+	if token.Owner != from {
+		return ErrNFTokenOwner
+	}
+	if to == "" {
+		return ErrAccountNameNull
+	}
+	token.Owner = to
+	return a.setNFToken(token)
+}
+
+//BurnNFToken delete the token and decrease the asset amount by one
+func (a *Asset) BurnNFToken(assetID uint64, tokenID uint64, owner common.Name) error {
+	token, err := a.GetNFToken(assetID, tokenID)
+	if err != nil {
+		return err
+	}
+	if token.Owner != owner {
+		return ErrNFTokenOwner
+	}
+	if err := a.DestroyAsset(owner, assetID, big.NewInt(1)); err != nil {
+		return err
+	}
+	a.sdb.Delete(assetManagerName, nftTokenKey(assetID, tokenID))
+	return nil
+}
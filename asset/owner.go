@@ -0,0 +1,101 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var assetPendingOwnerPrefix = "assetPendingOwner"
+
+// AssetOwnerAcceptBlocks the blocks the proposed owner has to accept the asset
+const AssetOwnerAcceptBlocks uint64 = 28800
+
+// PendingAssetOwner the owner proposed by the current owner of the asset, the
+// ownership moves only when the proposed owner accepts it before Expiry.
+type PendingAssetOwner struct {
+	Owner  common.Name `json:"owner"`
+	Number uint64      `json:"number"`
+	Expiry uint64      `json:"expiry"`
+}
+
+func pendingOwnerKey(assetID uint64) string {
+	return assetPendingOwnerPrefix + strconv.FormatUint(assetID, 10)
+}
+
+//GetPendingAssetOwner get the proposed owner of the asset
+func (a *Asset) GetPendingAssetOwner(assetID uint64) (*PendingAssetOwner, error) {
+	b, err := a.sdb.Get(assetManagerName, pendingOwnerKey(assetID))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrPendingOwnerNotExist
+	}
+	var pending PendingAssetOwner
+	if err := rlp.DecodeBytes(b, &pending); err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
+
+//ProposeAssetOwner propose the new owner of the asset, replacing the former proposal, proposing the current owner cancel it
+func (a *Asset) ProposeAssetOwner(assetID uint64, newOwner common.Name, number uint64) error {
+	assetObj, err := a.GetAssetObjectById(assetID)
+	if err != nil {
+		return err
+	}
+	if assetObj == nil {
+		return ErrAssetNotExist
+	}
+	if assetObj.GetAssetOwner() == newOwner {
+		a.sdb.Delete(assetManagerName, pendingOwnerKey(assetID))
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(&PendingAssetOwner{
+		Owner:  newOwner,
+		Number: number,
+		Expiry: number + AssetOwnerAcceptBlocks,
+	})
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, pendingOwnerKey(assetID), b)
+	return nil
+}
+
+//AcceptAssetOwner the proposed owner take over the asset before the proposal expired
+func (a *Asset) AcceptAssetOwner(accountName common.Name, assetID uint64, number uint64) error {
+	pending, err := a.GetPendingAssetOwner(assetID)
+	if err != nil {
+		return err
+	}
+	if pending.Owner != accountName {
+		return ErrNotPendingOwner
+	}
+	if number > pending.Expiry {
+		return ErrPendingOwnerExpired
+	}
+	if err := a.SetAssetNewOwner(accountName, assetID, accountName); err != nil {
+		return err
+	}
+	a.sdb.Delete(assetManagerName, pendingOwnerKey(assetID))
+	return nil
+}
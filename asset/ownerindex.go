@@ -0,0 +1,229 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// The assets of an account are listed per role, the owner and the founder. The
+// asset is appended when it is issued or handed to the account and swapped out
+// by the last asset when it leaves. The asset position is the paging cursor, it
+// is stable while the assets of the account do not change.
+//
+// The index is kept from ForkID4, the assets issued before are indexed by
+// IndexAssetOwners when the fork activates.
+var (
+	ownerAssetIndex   = "ownerAsset"
+	founderAssetIndex = "founderAsset"
+)
+
+// MaxAccountAssetsLimit max assets returned by a query
+const MaxAccountAssetsLimit = 1000
+
+// AccountAssets a page of the assets of the account, next is the cursor of the next page, 0 if no more
+type AccountAssets struct {
+	Assets []*AssetObject `json:"assets"`
+	Next   uint64         `json:"next"`
+}
+
+func accountAssetCountKey(index string, name common.Name) string {
+	return index + "Count" + name.String()
+}
+
+func accountAssetKey(index string, name common.Name, pos uint64) string {
+	return index + name.String() + "_" + strconv.FormatUint(pos, 10)
+}
+
+func accountAssetPosKey(index string, name common.Name, assetID uint64) string {
+	return index + "Pos" + name.String() + "_" + strconv.FormatUint(assetID, 10)
+}
+
+func (a *Asset) getUint64(key string) (uint64, bool, error) {
+	b, err := a.sdb.Get(assetManagerName, key)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(b) == 0 {
+		return 0, false, nil
+	}
+	var v uint64
+	if err := rlp.DecodeBytes(b, &v); err != nil {
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
+func (a *Asset) putUint64(key string, v uint64) error {
+	b, err := rlp.EncodeToBytes(&v)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, key, b)
+	return nil
+}
+
+func (a *Asset) getAccountAssetCount(index string, name common.Name) (uint64, error) {
+	count, _, err := a.getUint64(accountAssetCountKey(index, name))
+	return count, err
+}
+
+//addAccountAsset append the asset to the assets of the account
+func (a *Asset) addAccountAsset(index string, name common.Name, assetID uint64) error {
+	if len(name) == 0 {
+		return nil
+	}
+	if _, exist, err := a.getUint64(accountAssetPosKey(index, name, assetID)); err != nil || exist {
+		return err
+	}
+	count, err := a.getAccountAssetCount(index, name)
+	if err != nil {
+		return err
+	}
+	if err := a.putUint64(accountAssetKey(index, name, count), assetID); err != nil {
+		return err
+	}
+	if err := a.putUint64(accountAssetPosKey(index, name, assetID), count); err != nil {
+		return err
+	}
+	return a.putUint64(accountAssetCountKey(index, name), count+1)
+}
+
+//removeAccountAsset remove the asset from the assets of the account, the last asset takes its position
+func (a *Asset) removeAccountAsset(index string, name common.Name, assetID uint64) error {
+	pos, exist, err := a.getUint64(accountAssetPosKey(index, name, assetID))
+	if err != nil || !exist {
+		return err
+	}
+	count, err := a.getAccountAssetCount(index, name)
+	if err != nil {
+		return err
+	}
+	last := count - 1
+	if pos != last {
+		lastID, _, err := a.getUint64(accountAssetKey(index, name, last))
+		if err != nil {
+			return err
+		}
+		if err := a.putUint64(accountAssetKey(index, name, pos), lastID); err != nil {
+			return err
+		}
+		if err := a.putUint64(accountAssetPosKey(index, name, lastID), pos); err != nil {
+			return err
+		}
+	}
+	a.sdb.Delete(assetManagerName, accountAssetKey(index, name, last))
+	a.sdb.Delete(assetManagerName, accountAssetPosKey(index, name, assetID))
+	if last == 0 {
+		a.sdb.Delete(assetManagerName, accountAssetCountKey(index, name))
+		return nil
+	}
+	return a.putUint64(accountAssetCountKey(index, name), last)
+}
+
+//moveAccountAsset move the asset from the account to the other account
+func (a *Asset) moveAccountAsset(index string, from common.Name, to common.Name, assetID uint64) error {
+	if from != to {
+		if err := a.removeAccountAsset(index, from, assetID); err != nil {
+			return err
+		}
+	}
+	return a.addAccountAsset(index, to, assetID)
+}
+
+//indexAssetObject index the asset by its owner and founder, old is nil for a new asset
+func (a *Asset) indexAssetObject(old *AssetObject, ao *AssetObject) error {
+	if forked, err := a.isForked(params.ForkID4); err != nil || !forked {
+		return err
+	}
+	var oldOwner, oldFounder common.Name
+	if old != nil {
+		oldOwner, oldFounder = old.GetAssetOwner(), old.GetAssetFounder()
+	}
+	if err := a.moveAccountAsset(ownerAssetIndex, oldOwner, ao.GetAssetOwner(), ao.GetAssetId()); err != nil {
+		return err
+	}
+	return a.moveAccountAsset(founderAssetIndex, oldFounder, ao.GetAssetFounder(), ao.GetAssetId())
+}
+
+//IndexAssetOwners index every asset by its owner and founder, run once when ForkID4 activates
+func (a *Asset) IndexAssetOwners() error {
+	count, err := a.getAssetCount()
+	if err == ErrAssetCountNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for assetID := uint64(0); assetID < count; assetID++ {
+		ao, err := a.GetAssetObjectById(assetID)
+		if err == ErrAssetNotExist {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := a.addAccountAsset(ownerAssetIndex, ao.GetAssetOwner(), assetID); err != nil {
+			return err
+		}
+		if err := a.addAccountAsset(founderAssetIndex, ao.GetAssetFounder(), assetID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Asset) getAccountAssets(index string, name common.Name, cursor uint64, limit uint64) (*AccountAssets, error) {
+	if limit == 0 || limit > MaxAccountAssetsLimit {
+		limit = MaxAccountAssetsLimit
+	}
+	count, err := a.getAccountAssetCount(index, name)
+	if err != nil {
+		return nil, err
+	}
+	assets := &AccountAssets{Assets: []*AssetObject{}}
+	pos := cursor
+	for ; pos < count && uint64(len(assets.Assets)) < limit; pos++ {
+		assetID, _, err := a.getUint64(accountAssetKey(index, name, pos))
+		if err != nil {
+			return nil, err
+		}
+		ao, err := a.GetAssetObjectById(assetID)
+		if err != nil {
+			return nil, err
+		}
+		assets.Assets = append(assets.Assets, ao)
+	}
+	if pos < count {
+		assets.Next = pos
+	}
+	return assets, nil
+}
+
+//GetAssetsByOwner get the assets owned by the account from the cursor, at most limit
+func (a *Asset) GetAssetsByOwner(owner common.Name, cursor uint64, limit uint64) (*AccountAssets, error) {
+	return a.getAccountAssets(ownerAssetIndex, owner, cursor, limit)
+}
+
+//GetAssetsByFounder get the assets founded by the account from the cursor, at most limit
+func (a *Asset) GetAssetsByFounder(founder common.Name, cursor uint64, limit uint64) (*AccountAssets, error) {
+	return a.getAccountAssets(founderAssetIndex, founder, cursor, limit)
+}
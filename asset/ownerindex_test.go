@@ -0,0 +1,111 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+)
+
+func TestAsset_AssetsByOwner(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner, founder, other := common.Name("a123456789aeee"), common.Name("a123456789afff"), common.Name("a123456789abbb")
+	var ids []uint64
+	for _, name := range []string{"ownercoin0", "ownercoin1", "ownercoin2"} {
+		id, err := a.IssueAsset(name, 0, 0, name, big.NewInt(1), 0, founder, owner, big.NewInt(10), common.Name(""), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	assetIDs := func(assets *AccountAssets) []uint64 {
+		var ids []uint64
+		for _, ao := range assets.Assets {
+			ids = append(ids, ao.GetAssetId())
+		}
+		return ids
+	}
+
+	page, err := a.GetAssetsByOwner(owner, 0, 2)
+	if err != nil || len(page.Assets) != 2 || page.Next != 2 {
+		t.Fatalf("first page %v, %v", page, err)
+	}
+	if page, err = a.GetAssetsByOwner(owner, page.Next, 2); err != nil || len(page.Assets) != 1 || page.Next != 0 {
+		t.Fatalf("last page %v, %v", page, err)
+	}
+	if page, err := a.GetAssetsByFounder(founder, 0, 0); err != nil || len(page.Assets) != 3 {
+		t.Fatalf("founder assets %v, %v", page, err)
+	}
+
+	// the first asset changes hands, the last one takes its position
+	if err := a.SetAssetNewOwner(owner, ids[0], other); err != nil {
+		t.Fatal(err)
+	}
+	if page, err := a.GetAssetsByOwner(owner, 0, 0); err != nil || len(page.Assets) != 2 || assetIDs(page)[0] != ids[2] || assetIDs(page)[1] != ids[1] {
+		t.Fatalf("owner assets %v, %v", assetIDs(page), err)
+	}
+	if page, err := a.GetAssetsByOwner(other, 0, 0); err != nil || len(page.Assets) != 1 || assetIDs(page)[0] != ids[0] {
+		t.Fatalf("new owner assets %v, %v", assetIDs(page), err)
+	}
+
+	if err := a.UpdateAsset(owner, ids[1], other); err != nil {
+		t.Fatal(err)
+	}
+	if page, err := a.GetAssetsByFounder(founder, 0, 0); err != nil || len(page.Assets) != 2 {
+		t.Fatalf("founder assets %v, %v", page, err)
+	}
+	if page, err := a.GetAssetsByFounder(other, 0, 0); err != nil || len(page.Assets) != 1 || assetIDs(page)[0] != ids[1] {
+		t.Fatalf("new founder assets %v, %v", page, err)
+	}
+}
+
+func TestAsset_IndexAssetOwners(t *testing.T) {
+	statedb := getStateDB()
+	setForkID(statedb, params.ForkID3)
+	a := NewAsset(statedb)
+	owner, founder := common.Name("a123456789accc"), common.Name("a123456789addd")
+	for _, name := range []string{"forkcoin0", "forkcoin1"} {
+		if _, err := a.IssueAsset(name, 0, 0, name, big.NewInt(1), 0, founder, owner, big.NewInt(10), common.Name(""), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the assets are not indexed before the fork
+	if page, err := a.GetAssetsByOwner(owner, 0, 0); err != nil || len(page.Assets) != 0 {
+		t.Fatalf("owner assets before the fork %v, %v", page, err)
+	}
+
+	setForkID(statedb, params.ForkID4)
+	if err := a.IndexAssetOwners(); err != nil {
+		t.Fatal(err)
+	}
+	if page, err := a.GetAssetsByOwner(owner, 0, 0); err != nil || len(page.Assets) != 2 {
+		t.Fatalf("owner assets %v, %v", page, err)
+	}
+	if page, err := a.GetAssetsByFounder(founder, 0, 0); err != nil || len(page.Assets) != 2 {
+		t.Fatalf("founder assets %v, %v", page, err)
+	}
+	// the backfill is idempotent
+	if err := a.IndexAssetOwners(); err != nil {
+		t.Fatal(err)
+	}
+	if page, err := a.GetAssetsByOwner(owner, 0, 0); err != nil || len(page.Assets) != 2 {
+		t.Fatalf("owner assets after a second backfill %v, %v", page, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// AssetProof the merkle proofs of the asset against the state root. The name
+// proof proves the asset id of the name, the asset proof proves the asset
+// record holding the supply and the owner. The proofs are checked by
+// VerifyAssetProof.
+type AssetProof struct {
+	Asset      *AssetObject    `json:"asset"`
+	NameProof  []hexutil.Bytes `json:"nameProof"`
+	AssetProof []hexutil.Bytes `json:"assetProof"`
+}
+
+//GetAssetProof get the proofs of the asset record, the state must be committed
+//when called please RLock cachedb
+func (a *Asset) GetAssetProof(assetID uint64) (*AssetProof, error) {
+	asset, err := a.GetAssetObjectById(assetID)
+	if err != nil {
+		return nil, err
+	}
+	proof := &AssetProof{Asset: asset}
+	if proof.NameProof, err = a.sdb.Prove(assetManagerName, assetNameIdPrefix+asset.GetAssetName()); err != nil {
+		return nil, err
+	}
+	if proof.AssetProof, err = a.sdb.Prove(assetManagerName, assetObjectPrefix+strconv.FormatUint(assetID, 10)); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+//VerifyAssetProof verify the proofs of the asset against the state root
+func VerifyAssetProof(root common.Hash, proof *AssetProof) error {
+	if proof.Asset == nil {
+		return ErrAssetProofMismatch
+	}
+	b, err := state.VerifyDataProof(root, assetManagerName, assetNameIdPrefix+proof.Asset.GetAssetName(), proof.NameProof)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return ErrAssetNotExist
+	}
+	var assetID uint64
+	if err := rlp.DecodeBytes(b, &assetID); err != nil {
+		return err
+	}
+	if assetID != proof.Asset.GetAssetId() {
+		return ErrAssetProofMismatch
+	}
+
+	if b, err = state.VerifyDataProof(root, assetManagerName, assetObjectPrefix+strconv.FormatUint(assetID, 10), proof.AssetProof); err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return ErrAssetNotExist
+	}
+	enc, err := rlp.EncodeToBytes(proof.Asset)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(b, enc) {
+		return ErrAssetProofMismatch
+	}
+	return nil
+}
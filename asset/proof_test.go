@@ -0,0 +1,74 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestAsset_AssetProof(t *testing.T) {
+	db := memdb.NewMemDatabase()
+	cachedb := state.NewDatabase(db)
+	statedb, err := state.New(common.Hash{}, cachedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAsset(statedb)
+	id, err := a.IssueAsset("proofasset", 0, 0, "prf", big.NewInt(100), 0, common.Name(""), common.Name("owner"), big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch, common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cachedb.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	batch.Write()
+	if statedb, err = state.New(root, state.NewDatabase(db)); err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := NewAsset(statedb).GetAssetProof(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Asset.GetAssetName() != "proofasset" || proof.Asset.GetAssetAmount().Int64() != 100 {
+		t.Fatalf("proved asset %v", proof.Asset)
+	}
+	if err := VerifyAssetProof(root, proof); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAssetProof(common.Hash{1}, proof); err == nil {
+		t.Fatal("verify proof against wrong root should fail")
+	}
+	proof.Asset.Amount = big.NewInt(1)
+	if err := VerifyAssetProof(root, proof); err != ErrAssetProofMismatch {
+		t.Fatalf("verify forged amount err %v", err)
+	}
+	if _, err := NewAsset(statedb).GetAssetProof(id + 1); err != ErrAssetNotExist {
+		t.Fatalf("proof of missing asset err %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// The asset owner grants the operational roles of the asset to other accounts,
+// so the owner key can stay offline for the daily minting and redeeming. The
+// owner always holds every role.
+var (
+	assetRolesPrefix  = "assetRoles"
+	assetPausedPrefix = "assetPaused"
+)
+
+// AssetRole the operational role of the asset
+type AssetRole uint64
+
+const (
+	// RoleMinter increase the asset
+	RoleMinter AssetRole = iota
+	// RoleBurner destroy the asset
+	RoleBurner
+	// RolePauser pause and unpause the transfers of the asset
+	RolePauser
+	// RoleBlacklister ban and unban the accounts of the asset access list
+	RoleBlacklister
+	roleCount
+)
+
+// MaxRoleMembers max accounts of each role of the asset
+const MaxRoleMembers = 32
+
+// AssetRoles the accounts granted the roles of the asset
+type AssetRoles struct {
+	AssetID      uint64        `json:"assetId"`
+	Minters      []common.Name `json:"minters"`
+	Burners      []common.Name `json:"burners"`
+	Pausers      []common.Name `json:"pausers"`
+	Blacklisters []common.Name `json:"blacklisters"`
+}
+
+func (r *AssetRoles) members(role AssetRole) *[]common.Name {
+	switch role {
+	case RoleMinter:
+		return &r.Minters
+	case RoleBurner:
+		return &r.Burners
+	case RolePauser:
+		return &r.Pausers
+	default:
+		return &r.Blacklisters
+	}
+}
+
+//HasRole check the account is granted the role
+func (r *AssetRoles) HasRole(role AssetRole, name common.Name) bool {
+	for _, member := range *r.members(role) {
+		if member == name {
+			return true
+		}
+	}
+	return false
+}
+
+//GetAssetRoles get the accounts granted the roles of the asset
+func (a *Asset) GetAssetRoles(assetID uint64) (*AssetRoles, error) {
+	b, err := a.sdb.Get(assetManagerName, assetRolesPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return nil, err
+	}
+	roles := &AssetRoles{AssetID: assetID}
+	if len(b) == 0 {
+		return roles, nil
+	}
+	if err := rlp.DecodeBytes(b, roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (a *Asset) setAssetRoles(roles *AssetRoles) error {
+	key := assetRolesPrefix + strconv.FormatUint(roles.AssetID, 10)
+	if len(roles.Minters)+len(roles.Burners)+len(roles.Pausers)+len(roles.Blacklisters) == 0 {
+		a.sdb.Delete(assetManagerName, key)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(roles)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, key, b)
+	return nil
+}
+
+//GrantAssetRole grant the role of the asset to the account
+func (a *Asset) GrantAssetRole(assetID uint64, role AssetRole, name common.Name) error {
+	if role >= roleCount {
+		return ErrAssetRoleInvalid
+	}
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return err
+	}
+	roles, err := a.GetAssetRoles(assetID)
+	if err != nil {
+		return err
+	}
+	if roles.HasRole(role, name) {
+		return ErrAssetRoleIsExist
+	}
+	members := roles.members(role)
+	if len(*members) >= MaxRoleMembers {
+		return ErrAssetRoleMembersFull
+	}
+	*members = append(*members, name)
+	return a.setAssetRoles(roles)
+}
+
+//RevokeAssetRole revoke the role of the asset from the account
+func (a *Asset) RevokeAssetRole(assetID uint64, role AssetRole, name common.Name) error {
+	if role >= roleCount {
+		return ErrAssetRoleInvalid
+	}
+	roles, err := a.GetAssetRoles(assetID)
+	if err != nil {
+		return err
+	}
+	members := roles.members(role)
+	for i, member := range *members {
+		if member == name {
+			*members = append((*members)[:i], (*members)[i+1:]...)
+			return a.setAssetRoles(roles)
+		}
+	}
+	return ErrAssetRoleNotExist
+}
+
+//CheckAssetRole check the account is the owner of the asset or granted the role
+func (a *Asset) CheckAssetRole(fromName common.Name, assetID uint64, role AssetRole) error {
+	if err := a.CheckOwner(fromName, assetID); err == nil {
+		return nil
+	} else if err != ErrOwnerMismatch {
+		return err
+	}
+	roles, err := a.GetAssetRoles(assetID)
+	if err != nil {
+		return err
+	}
+	if !roles.HasRole(role, fromName) {
+		return ErrAssetRoleMismatch
+	}
+	return nil
+}
+
+//IsAssetPaused check the transfers of the asset are paused
+func (a *Asset) IsAssetPaused(assetID uint64) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, assetPausedPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+//SetAssetPaused pause or unpause the transfers of the asset
+func (a *Asset) SetAssetPaused(assetID uint64, paused bool) error {
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return err
+	}
+	key := assetPausedPrefix + strconv.FormatUint(assetID, 10)
+	if !paused {
+		a.sdb.Delete(assetManagerName, key)
+		return nil
+	}
+	a.sdb.Put(assetManagerName, key, []byte{1})
+	return nil
+}
+
+//SetAssetBanned ban or unban the account in the access list of the asset
+func (a *Asset) SetAssetBanned(assetID uint64, name common.Name, banned bool) error {
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return err
+	}
+	entry, err := a.getAccessEntry(assetID, name)
+	if err != nil {
+		return err
+	}
+	if banned {
+		if entry != nil && entry.Banned {
+			return nil
+		}
+		return a.setAccess(assetID, name, true)
+	}
+	if entry == nil || !entry.Banned {
+		return nil
+	}
+	return a.removeAccess(assetID, name, entry)
+}
@@ -0,0 +1,233 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	assetSupplyCountPrefix   = "assetSupplyCount"
+	assetSupplyEntryPrefix   = "assetSupplyEntry"
+	assetSupplyPendingPrefix = "assetSupplyPending"
+	assetSupplyDirtyPrefix   = "assetSupplyDirty"
+)
+
+// MaxSupplyHistoryEntries max entries returned by a supply history query
+const MaxSupplyHistoryEntries = 1000
+
+// SupplyChange the supply change of an asset in a block
+type SupplyChange struct {
+	Number    uint64   `json:"number"`
+	Increased *big.Int `json:"increased"`
+	Decreased *big.Int `json:"decreased"`
+	Supply    *big.Int `json:"supply"`
+}
+
+type pendingSupply struct {
+	Increased *big.Int
+	Decreased *big.Int
+}
+
+func (a *Asset) getSupplyDirty() ([]uint64, error) {
+	b, err := a.sdb.Get(assetManagerName, assetSupplyDirtyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	if len(b) == 0 {
+		return ids, nil
+	}
+	if err := rlp.DecodeBytes(b, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (a *Asset) getPendingSupply(assetID uint64) (*pendingSupply, error) {
+	b, err := a.sdb.Get(assetManagerName, assetSupplyPendingPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var pending pendingSupply
+	if err := rlp.DecodeBytes(b, &pending); err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
+
+//markSupplyChange accumulate the supply change of the asset until the block is recorded, from ForkID4
+func (a *Asset) markSupplyChange(assetID uint64, increased *big.Int, decreased *big.Int) error {
+	if forked, err := a.isForked(params.ForkID4); err != nil || !forked {
+		return err
+	}
+	pending, err := a.getPendingSupply(assetID)
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		ids, err := a.getSupplyDirty()
+		if err != nil {
+			return err
+		}
+		b, err := rlp.EncodeToBytes(append(ids, assetID))
+		if err != nil {
+			return err
+		}
+		a.sdb.Put(assetManagerName, assetSupplyDirtyPrefix, b)
+		pending = &pendingSupply{Increased: big.NewInt(0), Decreased: big.NewInt(0)}
+	}
+	pending.Increased.Add(pending.Increased, increased)
+	pending.Decreased.Add(pending.Decreased, decreased)
+	b, err := rlp.EncodeToBytes(pending)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, assetSupplyPendingPrefix+strconv.FormatUint(assetID, 10), b)
+	return nil
+}
+
+func (a *Asset) getSupplyCount(assetID uint64) (uint64, error) {
+	b, err := a.sdb.Get(assetManagerName, assetSupplyCountPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return 0, err
+	}
+	var count uint64
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if err := rlp.DecodeBytes(b, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (a *Asset) getSupplyEntry(assetID uint64, index uint64) (*SupplyChange, error) {
+	b, err := a.sdb.Get(assetManagerName, assetSupplyEntryPrefix+strconv.FormatUint(assetID, 10)+"_"+strconv.FormatUint(index, 10))
+	if err != nil {
+		return nil, err
+	}
+	var entry SupplyChange
+	if err := rlp.DecodeBytes(b, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+//RecordSupplyHistory append the supply changes of the block to the history of the assets
+func (a *Asset) RecordSupplyHistory(number uint64) error {
+	ids, err := a.getSupplyDirty()
+	if err != nil {
+		return err
+	}
+	for _, assetID := range ids {
+		pending, err := a.getPendingSupply(assetID)
+		if err != nil {
+			return err
+		}
+		asset, err := a.GetAssetObjectById(assetID)
+		if err != nil {
+			return err
+		}
+		count, err := a.getSupplyCount(assetID)
+		if err != nil {
+			return err
+		}
+		entry := &SupplyChange{
+			Number:    number,
+			Increased: pending.Increased,
+			Decreased: pending.Decreased,
+			Supply:    asset.GetAssetAmount(),
+		}
+		b, err := rlp.EncodeToBytes(entry)
+		if err != nil {
+			return err
+		}
+		id := strconv.FormatUint(assetID, 10)
+		a.sdb.Put(assetManagerName, assetSupplyEntryPrefix+id+"_"+strconv.FormatUint(count, 10), b)
+		count++
+		c, err := rlp.EncodeToBytes(&count)
+		if err != nil {
+			return err
+		}
+		a.sdb.Put(assetManagerName, assetSupplyCountPrefix+id, c)
+		a.sdb.Delete(assetManagerName, assetSupplyPendingPrefix+id)
+	}
+	if len(ids) > 0 {
+		a.sdb.Delete(assetManagerName, assetSupplyDirtyPrefix)
+	}
+	return nil
+}
+
+//DiscardSupplyChanges drop the supply changes not recorded yet
+func (a *Asset) DiscardSupplyChanges() error {
+	ids, err := a.getSupplyDirty()
+	if err != nil {
+		return err
+	}
+	for _, assetID := range ids {
+		a.sdb.Delete(assetManagerName, assetSupplyPendingPrefix+strconv.FormatUint(assetID, 10))
+	}
+	if len(ids) > 0 {
+		a.sdb.Delete(assetManagerName, assetSupplyDirtyPrefix)
+	}
+	return nil
+}
+
+//GetSupplyHistory get the supply changes of the asset between the blocks, at most MaxSupplyHistoryEntries
+func (a *Asset) GetSupplyHistory(assetID uint64, fromNumber uint64, toNumber uint64) ([]*SupplyChange, error) {
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return nil, err
+	}
+	count, err := a.getSupplyCount(assetID)
+	if err != nil {
+		return nil, err
+	}
+	// the entries are appended in block order, find the first one not before fromNumber
+	var searchErr error
+	start := sort.Search(int(count), func(i int) bool {
+		entry, err := a.getSupplyEntry(assetID, uint64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return entry.Number >= fromNumber
+	})
+	if searchErr != nil {
+		return nil, searchErr
+	}
+	history := []*SupplyChange{}
+	for i := uint64(start); i < count && len(history) < MaxSupplyHistoryEntries; i++ {
+		entry, err := a.getSupplyEntry(assetID, i)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Number > toNumber {
+			break
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
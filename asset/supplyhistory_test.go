@@ -0,0 +1,118 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+)
+
+func TestAsset_SupplyHistory(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("a123456789aeee")
+	id, err := a.IssueAsset("supplycoin", 0, 0, "supply", big.NewInt(100), 0, common.Name(""), owner, big.NewInt(0), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RecordSupplyHistory(1); err != nil {
+		t.Fatal(err)
+	}
+	// no change in the block, nothing recorded
+	if err := a.RecordSupplyHistory(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.IncreaseAsset(owner, id, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DestroyAsset(owner, id, big.NewInt(30)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RecordSupplyHistory(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DestroyAsset(owner, id, big.NewInt(20)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RecordSupplyHistory(5); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SupplyChange{
+		{Number: 1, Increased: big.NewInt(100), Decreased: big.NewInt(0), Supply: big.NewInt(100)},
+		{Number: 3, Increased: big.NewInt(50), Decreased: big.NewInt(30), Supply: big.NewInt(120)},
+		{Number: 5, Increased: big.NewInt(0), Decreased: big.NewInt(20), Supply: big.NewInt(100)},
+	}
+	tests := []struct {
+		from, to uint64
+		want     []SupplyChange
+	}{
+		{0, 10, want},
+		{2, 4, want[1:2]},
+		{3, 5, want[1:]},
+		{6, 10, nil},
+	}
+	for _, tt := range tests {
+		history, err := a.GetSupplyHistory(id, tt.from, tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != len(tt.want) {
+			t.Fatalf("history [%v, %v] has %v entries, want %v", tt.from, tt.to, len(history), len(tt.want))
+		}
+		for i, entry := range history {
+			w := tt.want[i]
+			if entry.Number != w.Number || entry.Increased.Cmp(w.Increased) != 0 || entry.Decreased.Cmp(w.Decreased) != 0 || entry.Supply.Cmp(w.Supply) != 0 {
+				t.Fatalf("history [%v, %v] entry %v = %v, want %v", tt.from, tt.to, i, entry, w)
+			}
+		}
+	}
+	if _, err := a.GetSupplyHistory(id+1, 0, 10); err == nil {
+		t.Fatal("supply history of missing asset should fail")
+	}
+}
+
+func TestAsset_SupplyHistoryBeforeFork(t *testing.T) {
+	statedb := getStateDB()
+	setForkID(statedb, params.ForkID3)
+	a := NewAsset(statedb)
+	owner := common.Name("a123456789aeee")
+	id, err := a.IssueAsset("prefork", 0, 0, "prefork", big.NewInt(100), 0, common.Name(""), owner, big.NewInt(0), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.IncreaseAsset(owner, id, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	// the supply changes before the fork are not kept for the first fork block
+	setForkID(statedb, params.ForkID4)
+	if err := a.DestroyAsset(owner, id, big.NewInt(30)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RecordSupplyHistory(1); err != nil {
+		t.Fatal(err)
+	}
+	history, err := a.GetSupplyHistory(id, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Increased.Sign() != 0 || history[0].Decreased.Cmp(big.NewInt(30)) != 0 || history[0].Supply.Cmp(big.NewInt(120)) != 0 {
+		t.Fatalf("history %v", history)
+	}
+}
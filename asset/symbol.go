@@ -0,0 +1,123 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strings"
+
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// The symbols are unique in their namespace, ignoring case. The main assets share
+// the global namespace, the sub-assets use the name of their main asset and the
+// assets named after an account ("account:name") use the account, so the owner
+// picks the symbols under its own names freely.
+//
+// The symbols are reserved from ForkID4. The symbols of the assets issued before
+// are reserved by ReserveAssetSymbols when the fork activates, the asset with the
+// lowest id keeps a symbol the earlier assets share.
+var assetSymbolPrefix = "assetSymbol"
+
+// SymbolNamespace returns the namespace of the symbol of the asset, empty for a main asset.
+func SymbolNamespace(assetName string) string {
+	if i := strings.Index(assetName, "."); i >= 0 {
+		return assetName[:i]
+	}
+	if i := strings.Index(assetName, ":"); i >= 0 {
+		return assetName[:i]
+	}
+	return ""
+}
+
+func assetSymbolKey(namespace string, symbol string) string {
+	return assetSymbolPrefix + namespace + ":" + strings.ToLower(symbol)
+}
+
+//GetAssetIdBySymbol get the id of the asset of the symbol in the namespace, empty namespace for the main assets
+func (a *Asset) GetAssetIdBySymbol(namespace string, symbol string) (uint64, error) {
+	if symbol == "" {
+		return 0, ErrAssetNotExist
+	}
+	b, err := a.sdb.Get(assetManagerName, assetSymbolKey(namespace, symbol))
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, ErrAssetNotExist
+	}
+	var assetID uint64
+	if err := rlp.DecodeBytes(b, &assetID); err != nil {
+		return 0, err
+	}
+	return assetID, nil
+}
+
+//putAssetSymbol store the symbol of the asset in its namespace, returns false if the symbol is taken
+func (a *Asset) putAssetSymbol(ao *AssetObject) (bool, error) {
+	key := assetSymbolKey(SymbolNamespace(ao.GetAssetName()), ao.GetSymbol())
+	b, err := a.sdb.Get(assetManagerName, key)
+	if err != nil {
+		return false, err
+	}
+	if len(b) != 0 {
+		return false, nil
+	}
+	assetID := ao.GetAssetId()
+	if b, err = rlp.EncodeToBytes(&assetID); err != nil {
+		return false, err
+	}
+	a.sdb.Put(assetManagerName, key, b)
+	return true, nil
+}
+
+//reserveAssetSymbol reserve the symbol of the new asset in its namespace
+func (a *Asset) reserveAssetSymbol(ao *AssetObject) error {
+	if forked, err := a.isForked(params.ForkID4); err != nil || !forked {
+		return err
+	}
+	if ok, err := a.putAssetSymbol(ao); err != nil {
+		return err
+	} else if !ok {
+		return ErrSymbolIsExist
+	}
+	return nil
+}
+
+//ReserveAssetSymbols reserve the symbols of every asset, run once when ForkID4 activates
+func (a *Asset) ReserveAssetSymbols() error {
+	count, err := a.getAssetCount()
+	if err == ErrAssetCountNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for assetID := uint64(0); assetID < count; assetID++ {
+		ao, err := a.GetAssetObjectById(assetID)
+		if err == ErrAssetNotExist {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := a.putAssetSymbol(ao); err != nil {
+			return err
+		}
+	}
+	return nil
+}
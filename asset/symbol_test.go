@@ -0,0 +1,106 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+)
+
+func TestAsset_SymbolNamespace(t *testing.T) {
+	tests := []struct {
+		assetName string
+		namespace string
+	}{
+		{"ftoken", ""},
+		{"ftoken.sub", "ftoken"},
+		{"ftoken.sub.sub", "ftoken"},
+		{"account:coin", "account"},
+		{"account:coin.sub", "account:coin"},
+	}
+	for _, tt := range tests {
+		if namespace := SymbolNamespace(tt.assetName); namespace != tt.namespace {
+			t.Fatalf("%s: namespace %q, want %q", tt.assetName, namespace, tt.namespace)
+		}
+	}
+}
+
+func TestAsset_AssetIdBySymbol(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("a123456789aeee")
+	issue := func(name string, symbol string) (uint64, error) {
+		return a.IssueAsset(name, 0, params.ForkID1, symbol, big.NewInt(1), 0, owner, owner, big.NewInt(10), common.Name(""), "")
+	}
+	id, err := issue("symbolcoin", "sym")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issue("symbolcoin2", "SYM"); err != ErrSymbolIsExist {
+		t.Fatalf("issue same symbol err %v", err)
+	}
+	subID, err := issue("symbolcoin.sub", "sym")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issue("symbolcoin.sub2", "sym"); err != ErrSymbolIsExist {
+		t.Fatalf("issue same sub symbol err %v", err)
+	}
+	if got, err := a.GetAssetIdBySymbol("", "Sym"); err != nil || got != id {
+		t.Fatalf("symbol id %v, %v, want %v", got, err, id)
+	}
+	if got, err := a.GetAssetIdBySymbol("symbolcoin", "sym"); err != nil || got != subID {
+		t.Fatalf("sub symbol id %v, %v, want %v", got, err, subID)
+	}
+	if _, err := a.GetAssetIdBySymbol("", "none"); err != ErrAssetNotExist {
+		t.Fatalf("missing symbol err %v", err)
+	}
+}
+
+func TestAsset_ReserveAssetSymbols(t *testing.T) {
+	statedb := getStateDB()
+	setForkID(statedb, params.ForkID3)
+	a := NewAsset(statedb)
+	owner := common.Name("a123456789aeee")
+	issue := func(name string, symbol string) (uint64, error) {
+		return a.IssueAsset(name, 0, params.ForkID1, symbol, big.NewInt(1), 0, owner, owner, big.NewInt(10), common.Name(""), "")
+	}
+	// the symbols are not reserved before the fork
+	id, err := issue("legacycoin", "leg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issue("legacycoin2", "leg"); err != nil {
+		t.Fatalf("issue same symbol before the fork err %v", err)
+	}
+	if _, err := a.GetAssetIdBySymbol("", "leg"); err != ErrAssetNotExist {
+		t.Fatalf("symbol reserved before the fork err %v", err)
+	}
+
+	setForkID(statedb, params.ForkID4)
+	if err := a.ReserveAssetSymbols(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := a.GetAssetIdBySymbol("", "leg"); err != nil || got != id {
+		t.Fatalf("symbol id %v, %v, want %v", got, err, id)
+	}
+	if _, err := issue("legacycoin3", "LEG"); err != ErrSymbolIsExist {
+		t.Fatalf("issue existing symbol after the fork err %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	tokenCuratorPrefix = "tokenCurator"
+	tokenInfoPrefix    = "tokenInfo"
+	tokenListPrefix    = "tokenList"
+)
+
+// MaxTokenInfoLength max length of each display field of the token info
+const MaxTokenInfoLength = 255
+
+// TokenInfo vetted display info of the asset in the token list
+type TokenInfo struct {
+	AssetID     uint64      `json:"assetId"`
+	DisplayName string      `json:"displayName"`
+	Symbol      string      `json:"symbol"`
+	LogoURL     string      `json:"logoURL"`
+	Website     string      `json:"website"`
+	Curator     common.Name `json:"curator"`
+	Number      uint64      `json:"number"`
+}
+
+//IsTokenCurator check the account is a curator of the token list
+func (a *Asset) IsTokenCurator(name common.Name) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, tokenCuratorPrefix+name.String())
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+//SetTokenCurator add or remove the curator of the token list
+func (a *Asset) SetTokenCurator(name common.Name, remove bool) {
+	if remove {
+		a.sdb.Delete(assetManagerName, tokenCuratorPrefix+name.String())
+		return
+	}
+	a.sdb.Put(assetManagerName, tokenCuratorPrefix+name.String(), []byte{1})
+}
+
+//GetTokenList get all asset id in the token list
+func (a *Asset) GetTokenList() ([]uint64, error) {
+	b, err := a.sdb.Get(assetManagerName, tokenListPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var list []uint64
+	if len(b) == 0 {
+		return list, nil
+	}
+	if err := rlp.DecodeBytes(b, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (a *Asset) setTokenList(list []uint64) error {
+	b, err := rlp.EncodeToBytes(list)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, tokenListPrefix, b)
+	return nil
+}
+
+//GetTokenInfo get the token info by asset id
+func (a *Asset) GetTokenInfo(assetID uint64) (*TokenInfo, error) {
+	b, err := a.sdb.Get(assetManagerName, tokenInfoPrefix+strconv.FormatUint(assetID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrTokenNotListed
+	}
+	var info TokenInfo
+	if err := rlp.DecodeBytes(b, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+//SetTokenInfo add or update the token info of the listed asset
+func (a *Asset) SetTokenInfo(info *TokenInfo) error {
+	if _, err := a.GetAssetObjectById(info.AssetID); err != nil {
+		return err
+	}
+	if len(info.DisplayName) > MaxTokenInfoLength || len(info.Symbol) > MaxTokenInfoLength ||
+		len(info.LogoURL) > MaxTokenInfoLength || len(info.Website) > MaxTokenInfoLength {
+		return ErrDetailTooLong
+	}
+	if _, err := a.GetTokenInfo(info.AssetID); err == ErrTokenNotListed {
+		list, err := a.GetTokenList()
+		if err != nil {
+			return err
+		}
+		if err := a.setTokenList(append(list, info.AssetID)); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	b, err := rlp.EncodeToBytes(info)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, tokenInfoPrefix+strconv.FormatUint(info.AssetID, 10), b)
+	return nil
+}
+
+//RemoveTokenInfo remove the asset from the token list
+func (a *Asset) RemoveTokenInfo(assetID uint64) error {
+	if _, err := a.GetTokenInfo(assetID); err != nil {
+		return err
+	}
+	list, err := a.GetTokenList()
+	if err != nil {
+		return err
+	}
+	for i, id := range list {
+		if id == assetID {
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	if err := a.setTokenList(list); err != nil {
+		return err
+	}
+	a.sdb.Delete(assetManagerName, tokenInfoPrefix+strconv.FormatUint(assetID, 10))
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAsset_TokenList(t *testing.T) {
+	a := NewAsset(getStateDB())
+	curator := common.Name("curator")
+	if ok, _ := a.IsTokenCurator(curator); ok {
+		t.Fatal("unexpected curator")
+	}
+	a.SetTokenCurator(curator, false)
+	if ok, _ := a.IsTokenCurator(curator); !ok {
+		t.Fatal("curator not set")
+	}
+	a.SetTokenCurator(curator, true)
+	if ok, _ := a.IsTokenCurator(curator); ok {
+		t.Fatal("curator not removed")
+	}
+
+	if err := a.SetTokenInfo(&TokenInfo{AssetID: 0}); err != ErrAssetNotExist {
+		t.Errorf("SetTokenInfo err = %v, want %v", err, ErrAssetNotExist)
+	}
+	id0, err := a.IssueAsset("tokena", 0, 0, "tokena", big.NewInt(1), 0, common.Name(""), common.Name("a123456789aeee"), big.NewInt(10), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id1, err := a.IssueAsset("tokenb", 0, 0, "tokenb", big.NewInt(1), 0, common.Name(""), common.Name("a123456789aeee"), big.NewInt(10), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint64{id0, id1, id0} {
+		if err := a.SetTokenInfo(&TokenInfo{AssetID: id, DisplayName: "token", Curator: curator}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, err := a.GetTokenList()
+	if err != nil || len(list) != 2 || list[0] != id0 || list[1] != id1 {
+		t.Errorf("GetTokenList = %v, %v", list, err)
+	}
+
+	if err := a.RemoveTokenInfo(id0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetTokenInfo(id0); err != ErrTokenNotListed {
+		t.Errorf("GetTokenInfo err = %v, want %v", err, ErrTokenNotListed)
+	}
+	if err := a.RemoveTokenInfo(id0); err != ErrTokenNotListed {
+		t.Errorf("RemoveTokenInfo err = %v, want %v", err, ErrTokenNotListed)
+	}
+	list, _ = a.GetTokenList()
+	if len(list) != 1 || list[0] != id1 {
+		t.Errorf("GetTokenList = %v", list)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var assetTransferFeePrefix = "assetTransferFee"
+
+//GetAssetTransferFee get the fee rate of the asset transfers in basis points, 0 if not set
+func (a *Asset) GetAssetTransferFee(assetID uint64) (uint64, error) {
+	b, err := a.sdb.Get(assetManagerName, assetTransferFeePrefix+strconv.FormatUint(assetID, 10))
+	if err != nil || len(b) == 0 {
+		return 0, err
+	}
+	var rate uint64
+	if err := rlp.DecodeBytes(b, &rate); err != nil {
+		return 0, err
+	}
+	return rate, nil
+}
+
+//SetAssetTransferFee set the fee rate of the asset transfers in basis points, 0 remove the fee
+func (a *Asset) SetAssetTransferFee(assetID uint64, rate uint64) error {
+	if rate > params.MaxAssetTransferFeeRate {
+		return ErrTransferFeeRate
+	}
+	if _, err := a.GetAssetObjectById(assetID); err != nil {
+		return err
+	}
+	key := assetTransferFeePrefix + strconv.FormatUint(assetID, 10)
+	if rate == 0 {
+		a.sdb.Delete(assetManagerName, key)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(&rate)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, key, b)
+	return nil
+}
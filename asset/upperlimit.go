@@ -0,0 +1,235 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	assetLimitApproversPrefix = "assetLimitApprovers"
+	assetPendingLimitPrefix   = "assetPendingLimit"
+)
+
+const (
+	// MaxUpperLimitApprovers the max approvers of raising the upper limit of an asset
+	MaxUpperLimitApprovers = 16
+	// UpperLimitApproveBlocks the blocks the approvers have to approve a raise of the upper limit
+	UpperLimitApproveBlocks uint64 = 28800
+)
+
+// PendingUpperLimit the raise of the upper limit proposed by the asset owner,
+// applied once every approver configured at issuance approved it before Expiry.
+type PendingUpperLimit struct {
+	UpperLimit *big.Int      `json:"upperLimit"`
+	Number     uint64        `json:"number"`
+	Expiry     uint64        `json:"expiry"`
+	Approvals  []common.Name `json:"approvals"`
+}
+
+func limitApproversKey(assetID uint64) string {
+	return assetLimitApproversPrefix + strconv.FormatUint(assetID, 10)
+}
+
+func pendingLimitKey(assetID uint64) string {
+	return assetPendingLimitPrefix + strconv.FormatUint(assetID, 10)
+}
+
+// isRaise reports whether the limit is above the current limit, 0 is no limit.
+func isRaise(current, limit *big.Int) bool {
+	if current.Sign() == 0 {
+		return false
+	}
+	return limit.Sign() == 0 || limit.Cmp(current) > 0
+}
+
+//SetUpperLimitApprovers set the approvers of raising the upper limit, only at the issuance of the asset
+func (a *Asset) SetUpperLimitApprovers(assetID uint64, approvers []common.Name) error {
+	if len(approvers) == 0 {
+		return nil
+	}
+	if len(approvers) > MaxUpperLimitApprovers {
+		return ErrLimitApproverInvalid
+	}
+	seen := make(map[common.Name]bool, len(approvers))
+	for _, approver := range approvers {
+		if approver == "" || seen[approver] {
+			return ErrLimitApproverInvalid
+		}
+		seen[approver] = true
+	}
+	if old, err := a.GetUpperLimitApprovers(assetID); err != nil {
+		return err
+	} else if len(old) != 0 {
+		return ErrLimitApproverInvalid
+	}
+	b, err := rlp.EncodeToBytes(approvers)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, limitApproversKey(assetID), b)
+	return nil
+}
+
+//GetUpperLimitApprovers get the approvers of raising the upper limit of the asset, nil if the limit can't be raised
+func (a *Asset) GetUpperLimitApprovers(assetID uint64) ([]common.Name, error) {
+	b, err := a.sdb.Get(assetManagerName, limitApproversKey(assetID))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var approvers []common.Name
+	if err := rlp.DecodeBytes(b, &approvers); err != nil {
+		return nil, err
+	}
+	return approvers, nil
+}
+
+//GetPendingUpperLimit get the proposed raise of the upper limit of the asset
+func (a *Asset) GetPendingUpperLimit(assetID uint64) (*PendingUpperLimit, error) {
+	b, err := a.sdb.Get(assetManagerName, pendingLimitKey(assetID))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrPendingLimitNotExist
+	}
+	var pending PendingUpperLimit
+	if err := rlp.DecodeBytes(b, &pending); err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
+
+//DropPendingUpperLimit drop the proposed raise of the upper limit of the asset, if any
+func (a *Asset) DropPendingUpperLimit(assetID uint64) error {
+	b, err := a.sdb.Get(assetManagerName, pendingLimitKey(assetID))
+	if err != nil || len(b) == 0 {
+		return err
+	}
+	a.sdb.Delete(assetManagerName, pendingLimitKey(assetID))
+	return nil
+}
+
+//UpdateAssetUpperLimit lower the upper limit of the asset, or propose the raise to the approvers replacing the former proposal
+func (a *Asset) UpdateAssetUpperLimit(assetID uint64, limit *big.Int, number uint64) error {
+	if limit == nil || limit.Sign() < 0 {
+		return ErrUpperLimitInvalid
+	}
+	assetObj, err := a.GetAssetObjectById(assetID)
+	if err != nil {
+		return err
+	}
+	if assetObj == nil {
+		return ErrAssetNotExist
+	}
+	current := assetObj.GetUpperLimit()
+	if limit.Cmp(current) == 0 {
+		a.sdb.Delete(assetManagerName, pendingLimitKey(assetID))
+		return nil
+	}
+	if !isRaise(current, limit) {
+		return a.setUpperLimit(assetObj, limit)
+	}
+
+	approvers, err := a.GetUpperLimitApprovers(assetID)
+	if err != nil {
+		return err
+	}
+	if len(approvers) == 0 {
+		return ErrUpperLimitRaise
+	}
+	b, err := rlp.EncodeToBytes(&PendingUpperLimit{
+		UpperLimit: limit,
+		Number:     number,
+		Expiry:     number + UpperLimitApproveBlocks,
+		Approvals:  []common.Name{},
+	})
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, pendingLimitKey(assetID), b)
+	return nil
+}
+
+//ApproveAssetUpperLimit the approver approve the proposed raise, the limit is raised once all the approvers approved it
+func (a *Asset) ApproveAssetUpperLimit(approver common.Name, assetID uint64, limit *big.Int, number uint64) error {
+	pending, err := a.GetPendingUpperLimit(assetID)
+	if err != nil {
+		return err
+	}
+	if limit == nil || pending.UpperLimit.Cmp(limit) != 0 {
+		return ErrPendingLimitMismatch
+	}
+	if number > pending.Expiry {
+		return ErrPendingLimitExpired
+	}
+	approvers, err := a.GetUpperLimitApprovers(assetID)
+	if err != nil {
+		return err
+	}
+	isApprover := false
+	for _, name := range approvers {
+		if name == approver {
+			isApprover = true
+			break
+		}
+	}
+	if !isApprover {
+		return ErrNotLimitApprover
+	}
+	for _, name := range pending.Approvals {
+		if name == approver {
+			return ErrLimitApproved
+		}
+	}
+	pending.Approvals = append(pending.Approvals, approver)
+
+	if len(pending.Approvals) < len(approvers) {
+		b, err := rlp.EncodeToBytes(pending)
+		if err != nil {
+			return err
+		}
+		a.sdb.Put(assetManagerName, pendingLimitKey(assetID), b)
+		return nil
+	}
+
+	assetObj, err := a.GetAssetObjectById(assetID)
+	if err != nil {
+		return err
+	}
+	if err := a.setUpperLimit(assetObj, pending.UpperLimit); err != nil {
+		return err
+	}
+	a.sdb.Delete(assetManagerName, pendingLimitKey(assetID))
+	return nil
+}
+
+//setUpperLimit set the upper limit of the asset, not below the issued amount
+func (a *Asset) setUpperLimit(assetObj *AssetObject, limit *big.Int) error {
+	if limit.Sign() > 0 && (limit.Cmp(assetObj.GetAssetAddIssue()) < 0 || limit.Cmp(assetObj.GetAssetAmount()) < 0) {
+		return ErrUpperLimitInvalid
+	}
+	assetObj.SetUpperLimit(new(big.Int).Set(limit))
+	return a.SetAssetObject(assetObj)
+}
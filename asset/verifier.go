@@ -0,0 +1,59 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+var assetVerifierPrefix = "assetVerifier"
+
+func assetVerifierKey(assetID uint64) string {
+	return assetVerifierPrefix + strconv.FormatUint(assetID, 10)
+}
+
+//SetAssetVerifier set the contract consulted before the issuance and the ownership changes
+//of the asset, only by the founder of the asset, empty verifier removes it
+func (a *Asset) SetAssetVerifier(fromName common.Name, assetID uint64, verifier common.Name) error {
+	assetObj, err := a.GetAssetObjectById(assetID)
+	if err != nil {
+		return err
+	}
+	if assetObj == nil {
+		return ErrAssetNotExist
+	}
+	if assetObj.GetAssetFounder() != fromName {
+		return ErrNotAssetFounder
+	}
+	if verifier == "" {
+		a.sdb.Delete(assetManagerName, assetVerifierKey(assetID))
+		return nil
+	}
+	a.sdb.Put(assetManagerName, assetVerifierKey(assetID), []byte(verifier))
+	return nil
+}
+
+//GetAssetVerifier get the verification contract of the asset, empty if not set
+func (a *Asset) GetAssetVerifier(assetID uint64) (common.Name, error) {
+	b, err := a.sdb.Get(assetManagerName, assetVerifierKey(assetID))
+	if err != nil {
+		return "", err
+	}
+	return common.Name(b), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// AuditBlock re-executes the canonical block of the number on the state of its
+// parent and checks the gas used, the receipts and the state root against the
+// stored block and receipts. A mismatch is returned as *DivergenceError, the
+// state is not written.
+func (bc *BlockChain) AuditBlock(number uint64) error {
+	if number == 0 {
+		return ErrAuditBlockMissing
+	}
+	block := bc.GetBlockByNumber(number)
+	if block == nil {
+		return ErrAuditBlockMissing
+	}
+	parent := bc.GetBlock(block.ParentHash(), number-1)
+	if parent == nil {
+		return ErrAuditBlockMissing
+	}
+	statedb, err := state.New(parent.Root(), bc.stateCache)
+	if err != nil {
+		return err
+	}
+
+	diverge := func(format string, args ...interface{}) error {
+		return &DivergenceError{Number: number, Hash: block.Hash(), Reason: fmt.Sprintf(format, args...)}
+	}
+	receipts, _, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+	if err != nil {
+		return diverge("process: %v", err)
+	}
+	if err := bc.validator.ValidateState(block, parent, statedb, receipts, usedGas); err != nil {
+		return diverge("%v", err)
+	}
+	if root := types.DeriveReceiptsMerkleRoot(bc.GetReceiptsByHash(block.Hash())); root != block.ReceiptHash() {
+		return diverge("invalid stored receipt root hash (header: %x stored: %x)", block.ReceiptHash(), root)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/consensus/dpos"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAuditBlock(t *testing.T) {
+	genesis := DefaultGenesis()
+	genesis.AllocAccounts = append(genesis.AllocAccounts, getDefaultGenesisAccounts()...)
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	// the system candidate produces empty blocks
+	tmpdb, err := deepCopyDB(chain.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := dpos.New(dpos.NewConfig(genesis.Config), chain)
+	engine.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+		return crypto.Sign(content, systemPrikey)
+	})
+	parentTime := genesis.Timestamp * uint64(time.Millisecond)
+	blocks, _ := generateChain(genesis.Config, chain.CurrentBlock(), engine, chain, tmpdb, 4, func(i int, b *BlockGenerator) {
+		b.SetCoinbase(common.StrToName(genesis.Config.SysName))
+		b.OffsetTime(int64(engine.Slot(parentTime + genesis.Config.DposCfg.BlockInterval*uint64(time.Millisecond)*uint64(i+1))))
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, block := range blocks[:3] {
+		if err := chain.AuditBlock(block.NumberU64()); err != nil {
+			t.Fatalf("audit block %d err %v", block.NumberU64(), err)
+		}
+	}
+	if err := chain.AuditBlock(0); err != ErrAuditBlockMissing {
+		t.Fatalf("audit genesis err %v", err)
+	}
+	if err := chain.AuditBlock(chain.CurrentBlock().NumberU64() + 1); err != ErrAuditBlockMissing {
+		t.Fatalf("audit future block err %v", err)
+	}
+
+	// the corrupted receipts of a block diverge
+	block := blocks[1]
+	receipts := chain.GetReceiptsByHash(block.Hash())
+	rawdb.WriteReceipts(chain.db, block.Hash(), block.NumberU64(), append(receipts, &types.Receipt{CumulativeGasUsed: 1}))
+	err = chain.AuditBlock(block.NumberU64())
+	if divergence, ok := err.(*DivergenceError); !ok || divergence.Number != block.NumberU64() {
+		t.Fatalf("audit corrupted block err %v", err)
+	}
+}
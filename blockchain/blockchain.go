@@ -66,6 +66,7 @@ type BlockChain struct {
 	snapshotInterval uint64
 	triesInMemory    uint64
 	triegc           *prque.Prque
+	stateGC          *stateGC // nil if the state trie nodes are never collected
 
 	vmConfig           vm.Config    // vm configuration
 	genesisBlock       *types.Block // genesis block
@@ -96,6 +97,7 @@ type BlockChain struct {
 	blockCache   *lru.Cache    // Cache for the most recent entire blocks
 	futureBlocks *lru.Cache    // future blocks are blocks added for later processing
 	badBlocks    *lru.Cache    // Bad block cache
+	blockTree    *blockTree    // Recent blocks known by height
 	quit         chan struct{} // blockchain quit channel
 }
 
@@ -136,6 +138,7 @@ func NewBlockChain(db fdb.Database, statePruning bool, vmConfig vm.Config, chain
 		blockCache:       blockCache,
 		futureBlocks:     futureBlocks,
 		badBlocks:        badBlocks,
+		blockTree:        newBlockTree(),
 		senderCacher:     senderCacher,
 		fcontroller: NewForkController(&ForkConfig{
 			ForkBlockNum:   chainConfig.ForkedCfg.ForkBlockNum,
@@ -356,6 +359,7 @@ func (bc *BlockChain) StateAt(hash common.Hash) (*state.StateDB, error) {
 // insert injects a new head block into the current block chain.
 func (bc *BlockChain) insert(batch fdb.Batch, block *types.Block) {
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+	rawdb.WriteBlockTime(batch, block.NumberU64(), block.Time().Uint64())
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	if strings.Compare(block.Coinbase().String(), bc.chainConfig.SysName) == 0 {
@@ -539,6 +543,7 @@ func (bc *BlockChain) WriteBlockWithoutState(block *types.Block, td *big.Int) (e
 		return err
 	}
 	rawdb.WriteBlock(bc.db, block)
+	bc.blockTree.add(block, td)
 	return nil
 }
 
@@ -707,7 +712,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 
 	if isCanon {
 		bc.currentBlock.Store(block)
+		bc.triggerStateGC(block.NumberU64())
 	}
+	bc.blockTree.add(block, externTd)
 
 	bc.futureBlocks.Remove(block.Hash())
 	return isCanon, err
@@ -775,7 +782,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []*types.Log, error)
 	)
 
 	if bc.senderCacher != nil {
-		bc.senderCacher.RecoverFromBlocks(types.MakeSigner(bc.chainConfig.ChainID), chain)
+		bc.senderCacher.RecoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].CurForkID()), chain)
 	}
 
 	// Iterate over the blocks and insert when the verifier permits
@@ -867,6 +874,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []*types.Log, error)
 		if err != nil {
 			return i, coalescedLogs, err
 		}
+		bc.prefetchState(block, parent.Root(), state)
 
 		receipts, logs, usedGas, err := bc.processor.Process(block, state, bc.vmConfig)
 		if err != nil {
@@ -904,6 +912,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []*types.Log, error)
 }
 
 func (bc *BlockChain) reorgChain(oldBlock, newBlock *types.Block, batch fdb.Batch) error {
+	bc.blockTree.reorg()
 	var (
 		newChain    types.Blocks
 		oldChain    types.Blocks
@@ -1163,6 +1172,12 @@ func (bc *BlockChain) GetAncestor(hash common.Hash, number, ancestor uint64, max
 
 }
 
+// GetBlockNumberByTime retrieves the number of the last canonical block at or
+// before the time, returns false if the time is before genesis.
+func (bc *BlockChain) GetBlockNumberByTime(time uint64) (uint64, bool) {
+	return rawdb.FindBlockNumberByTime(bc.db, bc.CurrentBlock().NumberU64(), time)
+}
+
 // GetHeaderByNumber retrieves a block header from the database by number.
 func (bc *BlockChain) GetHeaderByNumber(number uint64) *types.Header {
 	hash := rawdb.ReadCanonicalHash(bc.db, number)
@@ -0,0 +1,63 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/consensus/dpos"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/processor"
+	"github.com/fractalplatform/fractal/state"
+)
+
+func TestBlockSizeLimit(t *testing.T) {
+	genesis := DefaultGenesis()
+	genesis.Config = genesis.Config.Copy() // the limit is changed below, keep the default config intact
+	genesis.AllocAccounts = append(genesis.AllocAccounts, getDefaultGenesisAccounts()...)
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	tmpdb, err := deepCopyDB(chain.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := dpos.New(dpos.NewConfig(genesis.Config), chain)
+	engine.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+		return crypto.Sign(content, systemPrikey)
+	})
+	parentTime := genesis.Timestamp * uint64(time.Millisecond)
+	blocks, _ := generateChain(genesis.Config, chain.CurrentBlock(), engine, chain, tmpdb, 1, func(i int, b *BlockGenerator) {
+		b.SetCoinbase(common.StrToName(genesis.Config.SysName))
+		b.OffsetTime(int64(engine.Slot(parentTime + genesis.Config.DposCfg.BlockInterval*uint64(time.Millisecond)*uint64(i+1))))
+	})
+	size := uint64(blocks[0].Size())
+
+	chain.Config().BlockSizeCfg = &params.BlockSize{MaxSize: size - 1}
+	if _, err := chain.InsertChain(blocks); err == nil || !strings.Contains(err.Error(), processor.ErrBlockTooLarge.Error()) {
+		t.Fatalf("insert oversized block err %v", err)
+	}
+
+	chain.Config().BlockSizeCfg = &params.BlockSize{MaxSize: size}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+}
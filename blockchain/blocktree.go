@@ -0,0 +1,124 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// blockTreeHeights is the number of recent heights the block tree keeps.
+const blockTreeHeights = 1024
+
+// blockTree records the blocks written by the node, on the canonical chain or
+// not, so the fork choice of the recent heights can be inspected.
+type blockTree struct {
+	mu      sync.Mutex
+	heights map[uint64][]*types.BlockTreeNode
+	highest uint64
+	reorgs  uint64
+}
+
+func newBlockTree() *blockTree {
+	return &blockTree{heights: make(map[uint64][]*types.BlockTreeNode)}
+}
+
+// add records the block of the total difficulty, dropping the heights out of range.
+func (t *blockTree) add(block *types.Block, td *big.Int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	number := block.NumberU64()
+	for _, node := range t.heights[number] {
+		if node.Hash == block.Hash() {
+			return
+		}
+	}
+	t.heights[number] = append(t.heights[number], &types.BlockTreeNode{
+		Hash:            block.Hash(),
+		ParentHash:      block.ParentHash(),
+		Number:          number,
+		Coinbase:        block.Coinbase(),
+		Time:            block.Time().Uint64(),
+		Difficulty:      block.Difficulty(),
+		TotalDifficulty: new(big.Int).Set(td),
+	})
+	if number <= t.highest {
+		return
+	}
+	t.highest = number
+	for n := range t.heights {
+		if n+blockTreeHeights <= number {
+			delete(t.heights, n)
+		}
+	}
+}
+
+func (t *blockTree) reorg() {
+	t.mu.Lock()
+	t.reorgs++
+	t.mu.Unlock()
+}
+
+// BlockTree returns the blocks known at the recent heights below the current head, at most count heights.
+func (bc *BlockChain) BlockTree(count uint64) *types.BlockTree {
+	if count == 0 || count > blockTreeHeights {
+		count = blockTreeHeights
+	}
+	head := bc.CurrentBlock()
+	t := bc.blockTree
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tree := &types.BlockTree{Head: head.Hash(), Number: head.NumberU64(), Reorgs: t.reorgs, Tips: []common.Hash{}}
+	highest := t.highest
+	if highest < head.NumberU64() {
+		highest = head.NumberU64()
+	}
+	lowest := uint64(0)
+	if highest+1 > count {
+		lowest = highest + 1 - count
+	}
+
+	parents := make(map[common.Hash]bool)
+	for number := highest; number >= lowest; number-- {
+		for _, node := range t.heights[number+1] {
+			parents[node.ParentHash] = true
+		}
+		if nodes := t.heights[number]; len(nodes) != 0 {
+			height := &types.BlockTreeHeight{Number: number, Canonical: rawdb.ReadCanonicalHash(bc.db, number)}
+			for _, node := range nodes {
+				n := *node
+				n.Canonical = n.Hash == height.Canonical
+				n.Tip = !parents[n.Hash]
+				if n.Tip {
+					tree.Tips = append(tree.Tips, n.Hash)
+				}
+				height.Blocks = append(height.Blocks, &n)
+			}
+			tree.Heights = append(tree.Heights, height)
+		}
+		if number == 0 {
+			break
+		}
+	}
+	return tree
+}
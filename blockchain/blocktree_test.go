@@ -0,0 +1,80 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestBlockTree(t *testing.T) {
+	genesis := DefaultGenesis()
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	newBlock := func(parent *types.Block, coinbase string) *types.Block {
+		return types.NewBlockWithHeader(&types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), big.NewInt(1)),
+			Time:       new(big.Int).Add(parent.Time(), big.NewInt(1)),
+			Difficulty: big.NewInt(1),
+			Coinbase:   common.Name(coinbase),
+		})
+	}
+	// block2 is chosen over the side block of the same height
+	block1 := newBlock(chain.Genesis(), "candidate")
+	block2 := newBlock(block1, "candidate")
+	side := newBlock(block1, "sidecandidate")
+	for i, block := range []*types.Block{block1, block2, side} {
+		chain.blockTree.add(block, big.NewInt(int64(i+1)))
+	}
+	rawdb.WriteCanonicalHash(chain.db, block1.Hash(), 1)
+	rawdb.WriteCanonicalHash(chain.db, block2.Hash(), 2)
+
+	tree := chain.BlockTree(0)
+	if len(tree.Heights) != 2 || tree.Heights[0].Number != 2 || tree.Heights[0].Canonical != block2.Hash() {
+		t.Fatalf("tree heights %+v", tree.Heights)
+	}
+	if len(tree.Tips) != 2 {
+		t.Fatalf("tips %v", tree.Tips)
+	}
+	for _, node := range tree.Heights[0].Blocks {
+		if node.Canonical != (node.Hash == block2.Hash()) || !node.Tip {
+			t.Fatalf("node %+v", node)
+		}
+	}
+	if node := tree.Heights[1].Blocks[0]; node.Hash != block1.Hash() || !node.Canonical || node.Tip {
+		t.Fatalf("parent node %+v", node)
+	}
+	if tree = chain.BlockTree(1); len(tree.Heights) != 1 || tree.Heights[0].Number != 2 {
+		t.Fatalf("tree of one height %+v", tree.Heights)
+	}
+
+	// the heights out of range are dropped
+	far := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(blockTreeHeights + 1), Time: big.NewInt(0), Difficulty: big.NewInt(1)})
+	chain.blockTree.add(far, big.NewInt(1))
+	if _, ok := chain.blockTree.heights[1]; ok {
+		t.Fatal("height out of range not dropped")
+	}
+	if _, ok := chain.blockTree.heights[2]; !ok {
+		t.Fatal("height in range dropped")
+	}
+}
@@ -29,6 +29,7 @@ import (
 	mapset "github.com/deckarep/golang-set"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
 	router "github.com/fractalplatform/fractal/event"
 	adaptor "github.com/fractalplatform/fractal/p2p/protoadaptor"
 	"github.com/fractalplatform/fractal/types"
@@ -39,7 +40,8 @@ var (
 )
 
 const (
-	maxKnownBlocks = 1024 // Maximum block hashes to keep in the known list (prevent DOS)
+	maxKnownBlocks   = 1024 // Maximum block hashes to keep in the known list (prevent DOS)
+	maxNodeDataFetch = 384  // Maximum trie nodes to request from a station at once
 )
 
 type errid int
@@ -344,6 +346,69 @@ func getBlocks(from router.Station, to router.Station, req []common.Hash, errch
 	return bodies, nil
 }
 
+func getNodeData(from router.Station, to router.Station, req []common.Hash, errch chan struct{}) ([][]byte, *Error) {
+	se := &router.Event{
+		From:     from,
+		To:       to,
+		Typecode: router.P2PGetNodeDataMsg,
+		Data:     req,
+	}
+	timeout := time.Second + time.Duration(len(req))*(10*time.Millisecond)
+	e, err := syncReq(se, router.P2PNodeDataMsg, [][]byte{}, timeout, errch)
+	if err != nil {
+		return nil, err
+	}
+	return e.Data.([][]byte), nil
+}
+
+// fetchNodes asks the stations for the trie nodes of the hashes, the nodes
+// not matching their hash are dropped.
+func (dl *Downloader) fetchNodes(hashes []common.Hash) map[common.Hash][]byte {
+	dl.remotesMutex.RLock()
+	remotes := make([]*stationStatus, 0, len(dl.remotes.data))
+	for _, v := range dl.remotes.data {
+		remotes = append(remotes, v.(*stationStatus))
+	}
+	dl.remotesMutex.RUnlock()
+
+	rand.Seed(time.Now().UnixNano())
+	station := router.NewLocalStation(fmt.Sprintf("downloaderNodeData%d", rand.Int()), nil)
+	router.StationRegister(station)
+	defer router.StationUnregister(station)
+
+	requested := make(map[common.Hash]bool, len(hashes))
+	for _, hash := range hashes {
+		requested[hash] = true
+	}
+	nodes := make(map[common.Hash][]byte)
+	for _, status := range remotes {
+		var wanted []common.Hash
+		for _, hash := range hashes {
+			if _, ok := nodes[hash]; !ok {
+				wanted = append(wanted, hash)
+			}
+		}
+		for len(wanted) > 0 {
+			req := wanted
+			if len(req) > maxNodeDataFetch {
+				req = req[:maxNodeDataFetch]
+			}
+			wanted = wanted[len(req):]
+			blobs, err := getNodeData(station, status.station, req, status.errCh)
+			if err != nil {
+				log.Debug("Node data request failed", "station", fmt.Sprintf("%x", status.station.Name()), "err", err)
+				break
+			}
+			for _, blob := range blobs {
+				if hash := crypto.Keccak256Hash(blob); requested[hash] {
+					nodes[hash] = blob
+				}
+			}
+		}
+	}
+	return nodes
+}
+
 func (dl *Downloader) findAncestor(from router.Station, to router.Station, headNumber uint64, preAncestor uint64, errCh chan struct{}) (uint64, *Error) {
 	if headNumber < 1 {
 		return 0, nil
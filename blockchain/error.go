@@ -34,6 +34,12 @@ var (
 
 	// ErrBlacklistedHash is returned if a block to import is on the blacklist.
 	ErrBlacklistedHash = errors.New("blacklisted hash")
+
+	// ErrAuditBlockMissing is returned if the block to audit or its parent is not stored.
+	ErrAuditBlockMissing = errors.New("audit block or parent not found")
+
+	errStateGCUnsupported = errors.New("state garbage collection not supported by the database")
+	errStateGCInterrupted = errors.New("state garbage collection interrupted")
 )
 
 // GenesisMismatchError is raised when trying to overwrite an existing
@@ -45,3 +51,15 @@ type GenesisMismatchError struct {
 func (e *GenesisMismatchError) Error() string {
 	return fmt.Sprintf("database already contains an incompatible genesis block (have %x, new %x)", e.Stored[:], e.New[:])
 }
+
+// DivergenceError is raised when the re-execution of a stored block does not
+// reproduce the stored results.
+type DivergenceError struct {
+	Number uint64
+	Hash   common.Hash
+	Reason string
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("block %d (%x) diverges: %s", e.Number, e.Hash[:], e.Reason)
+}
@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
 	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/types"
@@ -143,13 +145,48 @@ func (fc *ForkController) update(block *types.Block, statedb *state.StateDB, get
 		}
 	}
 
+	oldForkID := info.CurForkID
 	if info.NextForkIDBlockNum*100/fc.cfg.ForkBlockNum >= fc.cfg.Forkpercentage {
 		info.CurForkID = block.NextForkID()
 		info.CurForkIDBlockNum = info.NextForkIDBlockNum
 		info.NextForkIDBlockNum = 0
 	}
 
-	return fc.putForkInfo(info, statedb)
+	if err := fc.putForkInfo(info, statedb); err != nil {
+		return err
+	}
+	for id := oldForkID + 1; id <= info.CurForkID; id++ {
+		if err := activateFork(id, statedb); err != nil {
+			return fmt.Errorf("activate fork %v err %v", id, err)
+		}
+	}
+	return nil
+}
+
+// activateFork applies the one-shot state transitions of the fork at the block activating it.
+func activateFork(id uint64, statedb *state.StateDB) error {
+	switch id {
+	case params.ForkID4:
+		accountDB, err := accountmanager.NewAccountManager(statedb)
+		if err != nil {
+			return err
+		}
+		// move the balances of the accounts written before the fork to the per-asset keys
+		// and index the accounts as the holders of their assets
+		if err := accountDB.MigrateAllAccountBalances(); err != nil {
+			return err
+		}
+		assetDB := asset.NewAsset(statedb)
+		// index the assets issued before the fork by their owners and founders
+		if err := assetDB.IndexAssetOwners(); err != nil {
+			return err
+		}
+		// reserve the symbols of the assets issued before the fork
+		if err := assetDB.ReserveAssetSymbols(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (fc *ForkController) currentForkID(statedb *state.StateDB) (uint64, uint64, error) {
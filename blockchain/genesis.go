@@ -28,7 +28,7 @@ import (
 	am "github.com/fractalplatform/fractal/accountmanager"
 	at "github.com/fractalplatform/fractal/asset"
 	"github.com/fractalplatform/fractal/common"
-	"github.com/fractalplatform/fractal/consensus/dpos"
+	"github.com/fractalplatform/fractal/consensus"
 	fm "github.com/fractalplatform/fractal/feemanager"
 	"github.com/fractalplatform/fractal/p2p/enode"
 	"github.com/fractalplatform/fractal/params"
@@ -79,37 +79,10 @@ type Genesis struct {
 	ForkID          uint64              `json:"forkID,omitempty"`
 }
 
-func dposConfig(cfg *params.ChainConfig) *dpos.Config {
-	dcfg := &dpos.Config{
-		MaxURLLen:                     cfg.DposCfg.MaxURLLen,
-		UnitStake:                     cfg.DposCfg.UnitStake,
-		CandidateAvailableMinQuantity: cfg.DposCfg.CandidateAvailableMinQuantity,
-		CandidateMinQuantity:          cfg.DposCfg.CandidateMinQuantity,
-		VoterMinQuantity:              cfg.DposCfg.VoterMinQuantity,
-		ActivatedMinCandidate:         cfg.DposCfg.ActivatedMinCandidate,
-		ActivatedMinQuantity:          cfg.DposCfg.ActivatedMinQuantity,
-		BlockInterval:                 cfg.DposCfg.BlockInterval,
-		BlockFrequency:                cfg.DposCfg.BlockFrequency,
-		CandidateScheduleSize:         cfg.DposCfg.CandidateScheduleSize,
-		BackupScheduleSize:            cfg.DposCfg.BackupScheduleSize,
-		EpochInterval:                 cfg.DposCfg.EpochInterval,
-		FreezeEpochSize:               cfg.DposCfg.FreezeEpochSize,
-		AccountName:                   cfg.DposName,
-		SystemName:                    cfg.SysName,
-		SystemURL:                     cfg.ChainURL,
-		ExtraBlockReward:              cfg.DposCfg.ExtraBlockReward,
-		BlockReward:                   cfg.DposCfg.BlockReward,
-		Decimals:                      cfg.SysTokenDecimals,
-		AssetID:                       cfg.SysTokenID,
-		ReferenceTime:                 cfg.ReferenceTime,
-	}
-	return dcfg
-}
-
 // SetupGenesisBlock The returned chain configuration is never nil.
-func SetupGenesisBlock(db fdb.Database, genesis *Genesis) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
+func SetupGenesisBlock(db fdb.Database, genesis *Genesis) (*params.ChainConfig, common.Hash, error) {
 	if genesis != nil && genesis.Config == nil {
-		return params.DefaultChainconfig, dposConfig(params.DefaultChainconfig), common.Hash{}, errGenesisNoConfig
+		return params.DefaultChainconfig, common.Hash{}, errGenesisNoConfig
 	}
 
 	// Just commit the new block if there is no stored genesis block.
@@ -120,37 +93,33 @@ func SetupGenesisBlock(db fdb.Database, genesis *Genesis) (*params.ChainConfig,
 		}
 		block, err := genesis.Commit(db)
 		if err != nil {
-			return nil, nil, common.Hash{}, err
-		}
-		dfg := dposConfig(genesis.Config)
-		if err := dfg.IsValid(); err != nil {
-			return nil, nil, common.Hash{}, err
+			return nil, common.Hash{}, err
 		}
 		log.Info("Writing genesis block", "hash", block.Hash().Hex())
-		return genesis.Config, dfg, block.Hash(), err
+		return genesis.Config, block.Hash(), err
 	}
 
 	// Check whether the genesis block is already written.
 	if genesis != nil {
 		blk, _, err := genesis.ToBlock(nil)
 		if err != nil {
-			return nil, nil, common.Hash{}, err
+			return nil, common.Hash{}, err
 
 		}
 		hash := blk.Hash()
 		if hash != stored {
-			return genesis.Config, dposConfig(genesis.Config), hash, &GenesisMismatchError{stored, hash}
+			return genesis.Config, hash, &GenesisMismatchError{stored, hash}
 		}
 	}
 
 	number := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db))
 	if number == nil {
-		return nil, nil, common.Hash{}, errors.New("missing block number for head header hash")
+		return nil, common.Hash{}, errors.New("missing block number for head header hash")
 	}
 
 	storedcfg := rawdb.ReadChainConfig(db, stored)
 	if storedcfg == nil {
-		return nil, nil, common.Hash{}, errors.New("Found genesis block without chain config")
+		return nil, common.Hash{}, errors.New("Found genesis block without chain config")
 	}
 	am.SetAccountNameConfig(&am.Config{
 		AccountNameLevel:         storedcfg.AccountNameCfg.Level,
@@ -159,6 +128,12 @@ func SetupGenesisBlock(db fdb.Database, genesis *Genesis) (*params.ChainConfig,
 		MainAccountNameMaxLength: storedcfg.AccountNameCfg.MainMaxLength,
 		SubAccountNameMinLength:  storedcfg.AccountNameCfg.SubMinLength,
 		SubAccountNameMaxLength:  storedcfg.AccountNameCfg.SubMaxLength,
+		AccountNameReclaimBlocks: storedcfg.AccountNameCfg.ReclaimBlocks,
+		AccountNameReuse:         storedcfg.AccountNameCfg.AllowReuse,
+		MaxSignDepth:             storedcfg.AuthorLimit().MaxSignDepth,
+		MaxSignLength:            storedcfg.AuthorLimit().MaxSignLength,
+		MaxAuthorNum:             storedcfg.AuthorLimit().MaxAuthorNum,
+		AllowBLSAuthor:           storedcfg.AuthorLimit().AllowBLS,
 	})
 	at.SetAssetNameConfig(&at.Config{
 		AssetNameLevel:         storedcfg.AssetNameCfg.Level,
@@ -169,15 +144,12 @@ func SetupGenesisBlock(db fdb.Database, genesis *Genesis) (*params.ChainConfig,
 		SubAssetNameMaxLength:  storedcfg.AssetNameCfg.SubMaxLength,
 	})
 	am.SetAcctMangerName(common.StrToName(storedcfg.AccountName))
+	am.SetTransferFeeExemptNames(common.StrToName(storedcfg.AssetName), common.StrToName(storedcfg.FeeName), common.StrToName(storedcfg.DposName))
+	am.SetChainName(common.StrToName(storedcfg.ChainName))
 	at.SetAssetMangerName(common.StrToName(storedcfg.AssetName))
 	fm.SetFeeManagerName(common.StrToName(storedcfg.FeeName))
 
-	dfg := dposConfig(storedcfg)
-	if err := dfg.IsValid(); err != nil {
-		log.Error("genesis get stored config failed ", "hash", stored, "err", err)
-		return nil, nil, stored, err
-	}
-	return storedcfg, dfg, stored, nil
+	return storedcfg, stored, nil
 }
 
 // ToBlock creates the genesis block and writes state of a genesis specification
@@ -205,6 +177,8 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 		SubAssetNameMaxLength:  8,
 	})
 	am.SetAcctMangerName(common.StrToName(g.Config.AccountName))
+	am.SetTransferFeeExemptNames(common.StrToName(g.Config.AssetName), common.StrToName(g.Config.FeeName), common.StrToName(g.Config.DposName))
+	am.SetChainName(common.StrToName(g.Config.ChainName))
 	at.SetAssetMangerName(common.StrToName(g.Config.AssetName))
 	fm.SetFeeManagerName(common.StrToName(g.Config.FeeName))
 	number := big.NewInt(0)
@@ -229,13 +203,15 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 	actActions := []*types.Action{}
 	timestamp := g.Timestamp * uint64(time.Millisecond)
 	g.Config.ReferenceTime = timestamp
-	if err := dpos.Genesis(dposConfig(g.Config), statedb, timestamp, number.Uint64()); err != nil {
-		return nil, nil, fmt.Errorf("genesis dpos err %v", err)
-	}
 
 	chainName := common.Name(g.Config.ChainName)
 	accoutName := common.Name(g.Config.AccountName)
 	assetName := common.Name(g.Config.AssetName)
+
+	// init  fork controller, the account manager reads the fork id from it
+	if err := initForkController(chainName.String(), statedb, g.ForkID); err != nil {
+		return nil, nil, fmt.Errorf("genesis init fork controller failed %v", err)
+	}
 	// chain name
 	act := &am.CreateAccountAction{
 		AccountName: chainName,
@@ -364,6 +340,12 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 		MainAccountNameMaxLength: g.Config.AccountNameCfg.MainMaxLength,
 		SubAccountNameMinLength:  g.Config.AccountNameCfg.SubMinLength,
 		SubAccountNameMaxLength:  g.Config.AccountNameCfg.SubMaxLength,
+		AccountNameReclaimBlocks: g.Config.AccountNameCfg.ReclaimBlocks,
+		AccountNameReuse:         g.Config.AccountNameCfg.AllowReuse,
+		MaxSignDepth:             g.Config.AuthorLimit().MaxSignDepth,
+		MaxSignLength:            g.Config.AuthorLimit().MaxSignLength,
+		MaxAuthorNum:             g.Config.AuthorLimit().MaxAuthorNum,
+		AllowBLSAuthor:           g.Config.AuthorLimit().AllowBLS,
 	})
 	at.SetAssetNameConfig(&at.Config{
 		AssetNameLevel:         g.Config.AssetNameCfg.Level,
@@ -396,36 +378,22 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 	g.Config.SysTokenID = assetInfo.AssetId
 	g.Config.SysTokenDecimals = assetInfo.Decimals
 
-	sys := dpos.NewSystem(statedb, dposConfig(g.Config))
-	epoch, _ := sys.GetLastestEpoch()
+	// the chain starting from ForkID4 has no legacy balances, mark them migrated
+	if g.ForkID >= params.ForkID4 {
+		if err := accountManager.MigrateAllAccountBalances(); err != nil {
+			return nil, nil, fmt.Errorf("genesis migrate balances err %v", err)
+		}
+	}
+
+	candidates := make([]*consensus.GenesisCandidate, 0, len(g.AllocCandidates))
 	for _, candidate := range g.AllocCandidates {
 		if ok, err := accountManager.AccountIsExist(common.StrToName(candidate.Name)); !ok {
 			return nil, nil, fmt.Errorf("candidate %v is not exist %v", candidate.Name, err)
 		}
-		if err := sys.SetCandidate(&dpos.CandidateInfo{
-			Epoch:         epoch,
-			Name:          candidate.Name,
-			URL:           candidate.URL,
-			Quantity:      big.NewInt(0),
-			TotalQuantity: big.NewInt(0),
-			Number:        number.Uint64(),
-		}); err != nil {
-			return nil, nil, fmt.Errorf("genesis create candidate err %v", err)
-		}
-	}
-	if fid := g.ForkID; fid >= params.ForkID2 {
-		if err := sys.UpdateElectedCandidates1(epoch, epoch, number.Uint64(), ""); err != nil {
-			return nil, nil, fmt.Errorf("genesis create candidate err %v", err)
-		}
-	} else {
-		if err := sys.UpdateElectedCandidates0(epoch, epoch, number.Uint64(), ""); err != nil {
-			return nil, nil, fmt.Errorf("genesis create candidate err %v", err)
-		}
+		candidates = append(candidates, &consensus.GenesisCandidate{Name: candidate.Name, URL: candidate.URL})
 	}
-
-	// init  fork controller
-	if err := initForkController(chainName.String(), statedb, g.ForkID); err != nil {
-		return nil, nil, fmt.Errorf("genesis init fork controller failed %v", err)
+	if err := consensus.Genesis(g.Config, statedb, timestamp, g.ForkID, candidates); err != nil {
+		return nil, nil, fmt.Errorf("genesis %v err %v", g.Config.EngineName(), err)
 	}
 
 	// snapshot
@@ -437,6 +405,11 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 		return nil, nil, fmt.Errorf("genesis snapshot err %v", err)
 	}
 
+	// the genesis supply is given by the allocation, the history starts after it
+	if err := at.NewAsset(statedb).DiscardSupplyChanges(); err != nil {
+		return nil, nil, fmt.Errorf("genesis discard supply changes err %v", err)
+	}
+
 	root := statedb.IntermediateRoot()
 	gjson, err := json.Marshal(g)
 	if err != nil {
@@ -501,6 +474,7 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 
 	detailTx.TxHash = receipt.TxHash
 	detailTx.Actions = internals
+	detailTx.SetInternalActionIDs()
 	receipt.SetInternalTxsLog(detailTx)
 
 	receipts := []*types.Receipt{receipt}
@@ -544,6 +518,7 @@ func (g *Genesis) Commit(db fdb.Database) (*types.Block, error) {
 	rawdb.WriteTxLookupEntries(db, block)
 	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	rawdb.WriteBlockTime(db, block.NumberU64(), block.Time().Uint64())
 	rawdb.WriteHeadBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
 	rawdb.WriteChainConfig(db, block.Hash(), g.Config)
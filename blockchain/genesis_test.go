@@ -25,9 +25,11 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/consensus"
 	"github.com/fractalplatform/fractal/consensus/dpos"
 	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/utils/fdb"
 	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
 )
@@ -64,15 +66,15 @@ func TestSetupGenesis(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		fn         func(fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error)
+		fn         func(fdb.Database) (*params.ChainConfig, common.Hash, error)
 		wantConfig *params.ChainConfig
-		wantDpos   *dpos.Config
+		wantEngine string
 		wantHash   common.Hash
 		wantErr    error
 	}{
 		{
 			name: "genesis without ChainConfig",
-			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
+			fn: func(db fdb.Database) (*params.ChainConfig, common.Hash, error) {
 				return SetupGenesisBlock(db, new(Genesis))
 			},
 			wantErr:    errGenesisNoConfig,
@@ -80,28 +82,30 @@ func TestSetupGenesis(t *testing.T) {
 		},
 		{
 			name: "no block in DB, genesis == nil",
-			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
+			fn: func(db fdb.Database) (*params.ChainConfig, common.Hash, error) {
 				return SetupGenesisBlock(db, nil)
 			},
 			wantHash:   defaultgenesisBlockHash,
 			wantConfig: params.DefaultChainconfig,
+			wantEngine: dpos.EngineName,
 		},
 		{
 			name: "mainnet block in DB, genesis == nil",
-			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
+			fn: func(db fdb.Database) (*params.ChainConfig, common.Hash, error) {
 				if _, err := DefaultGenesis().Commit(db); err != nil {
-					return nil, nil, common.Hash{}, err
+					return nil, common.Hash{}, err
 				}
 				return SetupGenesisBlock(db, nil)
 			},
 			wantHash:   defaultgenesisBlockHash,
 			wantConfig: params.DefaultChainconfig,
+			wantEngine: dpos.EngineName,
 		},
 		{
 			name: "compatible config in DB",
-			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
+			fn: func(db fdb.Database) (*params.ChainConfig, common.Hash, error) {
 				if _, err := oldcustomg.Commit(db); err != nil {
-					return nil, nil, common.Hash{}, err
+					return nil, common.Hash{}, err
 				}
 				return SetupGenesisBlock(db, &customg)
 			},
@@ -111,13 +115,14 @@ func TestSetupGenesis(t *testing.T) {
 			},
 			wantHash:   customghash,
 			wantConfig: customg.Config,
+			wantEngine: dpos.EngineName,
 		},
 	}
 
 	for _, test := range tests {
 		db := memdb.NewMemDatabase()
 
-		config, _, hash, err := test.fn(db)
+		config, hash, err := test.fn(db)
 
 		// Check the return values.
 		if !reflect.DeepEqual(err, test.wantErr) {
@@ -139,6 +144,39 @@ func TestSetupGenesis(t *testing.T) {
 			if stored.Hash() != test.wantHash {
 				t.Errorf("%s: 5 block in DB has hash %s, want %s", test.name, stored.Hash(), test.wantHash)
 			}
+			checkGenesisEngine(t, test.name, db, config, stored.Root(), test.wantEngine)
 		}
 	}
 }
+
+// checkGenesisEngine check the chain config selects the engine and the genesis state
+// holds the engine state written by the engine genesis.
+func checkGenesisEngine(t *testing.T, name string, db fdb.Database, config *params.ChainConfig, root common.Hash, wantEngine string) {
+	if engine := config.EngineName(); engine != wantEngine {
+		t.Errorf("%s: 6 selected engine %s, want %s", name, engine, wantEngine)
+		return
+	}
+	engine, err := consensus.NewEngine(config, nil)
+	if err != nil {
+		t.Errorf("%s: 7 create engine err %v", name, err)
+		return
+	}
+	if _, ok := engine.(*dpos.Dpos); !ok {
+		t.Errorf("%s: 7 created engine %T, want %s", name, engine, wantEngine)
+		return
+	}
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		t.Errorf("%s: 8 genesis state err %v", name, err)
+		return
+	}
+	sys := dpos.NewSystem(statedb, dpos.NewConfig(config))
+	epoch, err := sys.GetLastestEpoch()
+	if err != nil {
+		t.Errorf("%s: 8 engine genesis epoch err %v", name, err)
+		return
+	}
+	if gstate, err := sys.GetState(epoch); err != nil || gstate == nil {
+		t.Errorf("%s: 8 engine genesis state %v err %v", name, gstate, err)
+	}
+}
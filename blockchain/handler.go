@@ -48,7 +48,7 @@ func newBlockchainStation(bc *BlockChain, networkId uint64) *BlockchainStation {
 		networkId:  networkId,
 		quit:       make(chan struct{}),
 		downloader: NewDownloader(bc),
-		subs:       make([]router.Subscription, 6),
+		subs:       make([]router.Subscription, 7),
 	}
 	bs.subs[0] = router.Subscribe(nil, bs.peerCh, router.NewPeerNotify, nil)
 	bs.subs[1] = router.Subscribe(nil, bs.peerCh, router.DelPeerNotify, nil)
@@ -56,6 +56,7 @@ func newBlockchainStation(bc *BlockChain, networkId uint64) *BlockchainStation {
 	bs.subs[3] = router.Subscribe(nil, bs.peerCh, router.P2PGetBlockHashMsg, &getBlcokHashByNumber{})
 	bs.subs[4] = router.Subscribe(nil, bs.peerCh, router.P2PGetBlockHeadersMsg, &getBlockHeadersData{})
 	bs.subs[5] = router.Subscribe(nil, bs.peerCh, router.P2PGetBlockBodiesMsg, []common.Hash{})
+	bs.subs[6] = router.Subscribe(nil, bs.peerCh, router.P2PGetNodeDataMsg, []common.Hash{})
 
 	go bs.loop()
 	return bs
@@ -243,6 +244,21 @@ func (bs *BlockchainStation) handleMsg(e *router.Event) error {
 		}
 		router.ReplyEvent(e, router.P2PBlockBodiesMsg, bodies)
 		return nil
+	case router.P2PGetNodeDataMsg:
+		hashes := e.Data.([]common.Hash)
+		// Gather the trie nodes available, the missing ones are skipped
+		triedb := bs.blockchain.stateCache.TrieDB()
+		nodes := make([][]byte, 0, len(hashes))
+		for _, hash := range hashes {
+			if len(nodes) >= maxNodeDataFetch {
+				break
+			}
+			if blob, err := triedb.Node(hash); err == nil && len(blob) > 0 {
+				nodes = append(nodes, blob)
+			}
+		}
+		router.ReplyEvent(e, router.P2PNodeDataMsg, nodes)
+		return nil
 	}
 	return nil
 }
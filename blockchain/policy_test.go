@@ -0,0 +1,135 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/consensus/dpos"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestTransactionFailurePolicy(t *testing.T) {
+	genesis := DefaultGenesis()
+	genesis.AllocAccounts = append(genesis.AllocAccounts, getDefaultGenesisAccounts()...)
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	tmpdb, err := deepCopyDB(chain.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := dpos.New(dpos.NewConfig(genesis.Config), chain)
+	engine.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+		return crypto.Sign(content, systemPrikey)
+	})
+
+	var (
+		from      = common.StrToName(genesis.Config.SysName)
+		recipient = common.StrToName(genesis.Config.DposName)
+		missing   = common.StrToName("policymissing")
+		signer    = types.MakeSigner(params.DefaultChainconfig, params.ForkID4)
+	)
+	parentTime := genesis.Timestamp * uint64(time.Millisecond)
+	_, receipts := generateChain(genesis.Config, chain.CurrentBlock(), engine, chain, tmpdb, 1, func(i int, b *BlockGenerator) {
+		b.SetCoinbase(from)
+		b.setForkID(params.ForkID4)
+		b.OffsetTime(int64(engine.Slot(parentTime + genesis.Config.DposCfg.BlockInterval*uint64(time.Millisecond)*uint64(i+1))))
+
+		nonce := b.TxNonce(from)
+		for _, c := range policyCases {
+			// the transfer to the missing account fails between two transfers
+			var actions []*types.Action
+			for _, to := range []common.Name{recipient, missing, recipient} {
+				actions = append(actions, types.NewAction(types.Transfer, from, to, nonce, 0, 210000, big.NewInt(1), nil, nil))
+				nonce++
+			}
+			if c.tolerate {
+				actions[1].WithContinueOnError()
+			}
+			tx := types.NewTransaction(0, big.NewInt(2), actions...)
+			tx.WithFailurePolicy(c.policy)
+			for _, action := range actions {
+				if err := types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(systemPrikey, []uint64{0})}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before := balanceOf(t, b.statedb, recipient)
+			b.AddTx(tx)
+			if got := new(big.Int).Sub(balanceOf(t, b.statedb, recipient), before); got.Cmp(c.transferred) != 0 {
+				t.Errorf("policy %d tolerate %v transferred %v, want %v", c.policy, c.tolerate, got, c.transferred)
+			}
+		}
+		if got := b.TxNonce(from); got != nonce {
+			t.Errorf("nonce %d, want %d", got, nonce)
+		}
+	})
+
+	for i, receipt := range receipts[0] {
+		c := policyCases[i]
+		for j, result := range receipt.ActionResults {
+			if result.Status != c.status[j] {
+				t.Errorf("policy %d tolerate %v action %d status %d, want %d", c.policy, c.tolerate, j, result.Status, c.status[j])
+			}
+			if result.GasUsed == 0 {
+				t.Errorf("policy %d tolerate %v action %d not charged", c.policy, c.tolerate, j)
+			}
+		}
+	}
+}
+
+var (
+	succeeded = types.ReceiptStatusSuccessful
+	failed    = types.ReceiptStatusFailed
+
+	// the failure of the action continuing on error affects no other action
+	policyCases = []struct {
+		policy      types.FailurePolicy
+		tolerate    bool
+		transferred *big.Int
+		status      []uint64
+	}{
+		{types.PolicyIndependent, false, big.NewInt(2), []uint64{succeeded, failed, succeeded}},
+		{types.PolicyAtomic, false, big.NewInt(0), []uint64{types.ReceiptStatusReverted, failed, types.ReceiptStatusReverted}},
+		{types.PolicyPrefix, false, big.NewInt(1), []uint64{succeeded, failed, types.ReceiptStatusSkipped}},
+		{types.PolicyIndependent, true, big.NewInt(2), []uint64{succeeded, failed, succeeded}},
+		{types.PolicyAtomic, true, big.NewInt(2), []uint64{succeeded, failed, succeeded}},
+		{types.PolicyPrefix, true, big.NewInt(2), []uint64{succeeded, failed, succeeded}},
+	}
+)
+
+func balanceOf(t *testing.T, statedb *state.StateDB, name common.Name) *big.Int {
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, err := am.GetAccountBalanceByID(name, 0, 0)
+	if err == accountmanager.ErrAccountAssetNotExist {
+		return new(big.Int)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// prefetchBalance the balance of the asset of the account
+type prefetchBalance struct {
+	name    common.Name
+	assetID uint64
+}
+
+// prefetchTasks the accounts, the balances and the assets the transactions of
+// the block touch, the actions executed by the contracts are not known.
+func prefetchTasks(block *types.Block) ([]common.Name, []prefetchBalance, []uint64) {
+	var (
+		names    []common.Name
+		balances []prefetchBalance
+		assetIDs []uint64
+		seen     = make(map[interface{}]bool)
+	)
+	addName := func(name common.Name) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	addBalance := func(name common.Name, assetID uint64) {
+		if balance := (prefetchBalance{name, assetID}); name != "" && !seen[balance] {
+			seen[balance] = true
+			balances = append(balances, balance)
+		}
+		if !seen[assetID] {
+			seen[assetID] = true
+			assetIDs = append(assetIDs, assetID)
+		}
+	}
+	for _, tx := range block.Transactions() {
+		if payer := tx.Payer(); payer != nil {
+			addName(payer.Name)
+			addBalance(payer.Name, tx.GasAssetID())
+		}
+		for _, action := range tx.GetActions() {
+			addName(action.Sender())
+			addName(action.Recipient())
+			addBalance(action.Sender(), tx.GasAssetID())
+			addBalance(action.Sender(), action.AssetID())
+			addBalance(action.Recipient(), action.AssetID())
+		}
+	}
+	return names, balances, assetIDs
+}
+
+// prefetchState loads the accounts, the balances and the assets the
+// transactions of the block touch from the state of the root in parallel, then
+// caches them in the state the block is executed on, so the sequential
+// execution reads less from the database. The execution results are never
+// changed by the prefetch, the failed loads are left to the execution.
+func (bc *BlockChain) prefetchState(block *types.Block, root common.Hash, statedb *state.StateDB) {
+	names, balances, assetIDs := prefetchTasks(block)
+	tasks := len(names) + len(balances) + len(assetIDs)
+	if tasks == 0 {
+		return
+	}
+	threads := runtime.NumCPU()
+	if threads > tasks {
+		threads = tasks
+	}
+	loaded := make([]*state.StateDB, threads)
+	for t := range loaded {
+		loadState, err := state.New(root, bc.stateCache)
+		if err != nil {
+			return
+		}
+		loaded[t] = loadState
+	}
+
+	var (
+		next int32 = -1
+		wg   sync.WaitGroup
+	)
+	for _, loadState := range loaded {
+		am, err := accountmanager.NewAccountManager(loadState)
+		if err != nil {
+			break
+		}
+		ast := asset.NewAsset(loadState)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(atomic.AddInt32(&next, 1)); i < tasks; i = int(atomic.AddInt32(&next, 1)) {
+				if atomic.LoadInt32(&bc.procInterrupt) == 1 {
+					return
+				}
+				switch {
+				case i < len(names):
+					am.GetAccountByName(names[i])
+				case i < len(names)+len(balances):
+					balance := balances[i-len(names)]
+					am.GetAccountBalanceByID(balance.name, balance.assetID, 0)
+				default:
+					ast.GetAssetObjectById(assetIDs[i-len(names)-len(balances)])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, loadState := range loaded {
+		statedb.Preload(loadState)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestPrefetchState(t *testing.T) {
+	genesis := DefaultGenesis()
+	genesis.AllocAccounts = append(genesis.AllocAccounts, getDefaultGenesisAccounts()...)
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	var (
+		from      = common.StrToName(genesis.Config.SysName)
+		recipient = common.StrToName(genesis.Config.DposName)
+		assetID   = genesis.Config.SysTokenID
+		root      = chain.CurrentBlock().Root()
+	)
+	action := types.NewAction(types.Transfer, from, recipient, 0, assetID, 210000, big.NewInt(1), nil, nil)
+	block := types.NewBlockWithHeader(chain.CurrentBlock().Header()).WithBody([]*types.Transaction{types.NewTransaction(assetID, big.NewInt(2), action)})
+
+	statedb, err := chain.StateAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.prefetchState(block, root, statedb)
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := chain.StateAt(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := accountmanager.NewAccountManager(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{from, recipient} {
+		if acct, err := am.GetAccountByName(name); err != nil || acct == nil {
+			t.Fatalf("prefetched account %v err %v", name, err)
+		}
+	}
+	balance, err := am.GetAccountBalanceByID(from, assetID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := want.GetAccountBalanceByID(from, assetID, 0); b.Cmp(balance) != 0 {
+		t.Fatalf("prefetched balance %v, want %v", balance, b)
+	}
+	// the execution reads the prefetched values, only the missing keys are read from the trie
+	for _, name := range []common.Name{from, recipient} {
+		want.GetAccountByName(name)
+	}
+	reads, _, cacheHits := statedb.AccessStats()
+	wantReads, _, wantCacheHits := expected.AccessStats()
+	if reads != wantReads || reads-cacheHits >= wantReads-wantCacheHits {
+		t.Fatalf("%d of %d reads cached, %d of %d without prefetch", cacheHits, reads, wantCacheHits, wantReads)
+	}
+}
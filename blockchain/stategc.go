@@ -0,0 +1,339 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/utils/fdb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
+// stateMarkPrefix the prefix of the keys of the mark set of a collection, the
+// marks are stored next to the trie nodes so they are not held in memory.
+var stateMarkPrefix = []byte("stategc-mark-")
+
+// stateGC the garbage collection of the state trie nodes stored on the database,
+// the nodes not reachable from the retained roots are deleted.
+type stateGC struct {
+	retain   uint64 // the number of the recent block state roots retained
+	interval uint64 // the number of blocks between two collections
+	last     uint64 // the block number of the last collection
+	running  int32
+}
+
+// SetStateGC enables the garbage collection of the state trie nodes, only the
+// states of the last retain blocks and of the snapshots are kept, the states
+// of the older blocks are no longer available. The collection runs in the
+// background every interval blocks, the blocks are held back only while the
+// unreachable nodes are swept.
+func (bc *BlockChain) SetStateGC(retain, interval uint64) {
+	// the blocks may still be reverted until they are out of the memory tries
+	if retain < bc.triesInMemory {
+		retain = bc.triesInMemory
+	}
+	if interval == 0 {
+		interval = retain
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.stateGC = &stateGC{retain: retain, interval: interval, last: bc.CurrentBlock().NumberU64()}
+}
+
+// triggerStateGC starts a collection if the interval is elapsed.
+func (bc *BlockChain) triggerStateGC(number uint64) {
+	gc := bc.stateGC
+	if gc == nil || number < gc.last+gc.interval || !atomic.CompareAndSwapInt32(&gc.running, 0, 1) {
+		return
+	}
+	gc.last = number
+	bc.wg.Add(1)
+	go func() {
+		defer bc.wg.Done()
+		defer atomic.StoreInt32(&gc.running, 0)
+
+		marked, deleted, err := bc.collectState(gc.retain)
+		if err != nil {
+			log.Warn("State garbage collection aborted", "err", err)
+			return
+		}
+		log.Info("State garbage collected", "number", number, "reachable", marked, "deleted", deleted)
+	}()
+}
+
+// stateNodeDB returns the database holding the state trie nodes.
+func (bc *BlockChain) stateNodeDB() fdb.Database {
+	if tdb, ok := bc.db.(*rawdb.TieredDatabase); ok {
+		return tdb.Tier(rawdb.TierState)
+	}
+	return bc.db
+}
+
+// collectState deletes the trie nodes not reachable from the retained roots,
+// nothing is deleted unless every retained trie is walked completely. The
+// tries are marked while the blocks are written, the chain mutex is only held
+// to mark the blocks written meanwhile and to sweep.
+func (bc *BlockChain) collectState(retain uint64) (int, int, error) {
+	db := bc.stateNodeDB()
+	marks := newMarkSet(db)
+	// the marks left by an interrupted collection are dropped first
+	if err := marks.clear(); err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if err := marks.clear(); err != nil {
+			log.Warn("Failed to clear the state collection marks", "err", err)
+		}
+	}()
+
+	if err := bc.markRetained(retain, marks); err != nil {
+		return 0, 0, err
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if atomic.LoadInt32(&bc.procInterrupt) == 1 {
+		return 0, 0, errStateGCInterrupted
+	}
+	// the subtries marked already are skipped, only the new nodes are walked
+	if err := bc.markRetained(retain, marks); err != nil {
+		return 0, 0, err
+	}
+	if err := marks.flush(); err != nil {
+		return 0, 0, err
+	}
+
+	batch := db.NewBatch()
+	deleted := 0
+	err := forEachKey(db, func(key []byte) error {
+		if rawdb.KeyTier(key) != rawdb.TierState || len(key) != common.HashLength {
+			return nil
+		}
+		if ok, err := marks.has(common.BytesToHash(key)); err != nil || ok {
+			return err
+		}
+		deleted++
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= fdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return marks.count, deleted, batch.Write()
+}
+
+// markRetained marks the trie nodes reachable from the retained roots.
+func (bc *BlockChain) markRetained(retain uint64, marks *markSet) error {
+	roots, err := bc.retainedRoots(retain)
+	if err != nil {
+		return err
+	}
+	for _, root := range roots {
+		if atomic.LoadInt32(&bc.procInterrupt) == 1 {
+			return errStateGCInterrupted
+		}
+		if err := markTrie(bc.stateCache, root, marks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retainedRoots returns the state roots of the last retain canonical blocks,
+// of the recent side blocks which may still become canonical and of the
+// snapshots.
+func (bc *BlockChain) retainedRoots(retain uint64) ([]common.Hash, error) {
+	current := bc.CurrentBlock()
+	var roots []common.Hash
+	for i := uint64(0); i < retain && i <= current.NumberU64(); i++ {
+		header := bc.GetHeaderByNumber(current.NumberU64() - i)
+		if header == nil {
+			break
+		}
+		roots = append(roots, header.Root)
+	}
+	for _, height := range bc.BlockTree(retain).Heights {
+		for _, node := range height.Blocks {
+			if header := bc.GetHeader(node.Hash, node.Number); header != nil {
+				roots = append(roots, header.Root)
+			}
+		}
+	}
+
+	statedb, err := state.New(current.Root(), bc.stateCache)
+	if err != nil {
+		return nil, err
+	}
+	sm := snapshot.NewSnapshotManager(statedb)
+	time, err := sm.GetLastSnapshotTime()
+	if err != nil {
+		// no snapshot taken yet
+		return roots, nil
+	}
+	for time != 0 {
+		prevTime, err := sm.GetPrevSnapshotTime(time)
+		if err != nil {
+			// the previous snapshot of the first one is never taken
+			break
+		}
+		root, err := sm.GetSnapshotRoot(time)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, root)
+		time = prevTime
+	}
+	return roots, nil
+}
+
+// markSet the hashes of the marked trie nodes stored on the database, the
+// marks not flushed yet are kept in memory.
+type markSet struct {
+	db      fdb.Database
+	batch   fdb.Batch
+	pending map[common.Hash]struct{}
+	count   int
+}
+
+func newMarkSet(db fdb.Database) *markSet {
+	return &markSet{db: db, batch: db.NewBatch(), pending: make(map[common.Hash]struct{})}
+}
+
+func markKey(hash common.Hash) []byte {
+	return append(append([]byte{}, stateMarkPrefix...), hash[:]...)
+}
+
+func (m *markSet) has(hash common.Hash) (bool, error) {
+	if _, ok := m.pending[hash]; ok {
+		return true, nil
+	}
+	return m.db.Has(markKey(hash))
+}
+
+func (m *markSet) add(hash common.Hash) error {
+	m.pending[hash] = struct{}{}
+	m.count++
+	if err := m.batch.Put(markKey(hash), []byte{1}); err != nil {
+		return err
+	}
+	if m.batch.ValueSize() >= fdb.IdealBatchSize {
+		return m.flush()
+	}
+	return nil
+}
+
+func (m *markSet) flush() error {
+	if err := m.batch.Write(); err != nil {
+		return err
+	}
+	m.batch.Reset()
+	m.pending = make(map[common.Hash]struct{})
+	return nil
+}
+
+// clear deletes the marks stored on the database.
+func (m *markSet) clear() error {
+	m.batch.Reset()
+	m.pending = make(map[common.Hash]struct{})
+	m.count = 0
+	batch := m.db.NewBatch()
+	err := forEachKey(m.db, func(key []byte) error {
+		if !bytes.HasPrefix(key, stateMarkPrefix) {
+			return nil
+		}
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= fdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// markTrie marks the trie nodes reachable from the root, the subtries already
+// marked by another root are skipped.
+func markTrie(db state.Database, root common.Hash, marks *markSet) error {
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return err
+	}
+	it := tr.NodeIterator(nil)
+	for descend := true; it.Next(descend); {
+		descend = true
+		hash := it.Hash()
+		if hash == (common.Hash{}) {
+			continue
+		}
+		ok, err := marks.has(hash)
+		if err != nil {
+			return err
+		}
+		if ok {
+			descend = false
+			continue
+		}
+		if err := marks.add(hash); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// forEachKey calls fn with every key of the database.
+func forEachKey(db fdb.Database, fn func(key []byte) error) error {
+	switch d := db.(type) {
+	case interface{ NewIterator() iterator.Iterator }:
+		it := d.NewIterator()
+		defer it.Release()
+		for it.Next() {
+			if err := fn(it.Key()); err != nil {
+				return err
+			}
+		}
+		return it.Error()
+	case interface{ Keys() [][]byte }:
+		for _, key := range d.Keys() {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errStateGCUnsupported
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestCollectState(t *testing.T) {
+	genesis := DefaultGenesis()
+	genesis.AllocAccounts = append(genesis.AllocAccounts, getDefaultGenesisAccounts()...)
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	nodes := func() int {
+		count := 0
+		for _, key := range chain.db.(*memdb.MemDatabase).Keys() {
+			if len(key) == common.HashLength {
+				count++
+			}
+		}
+		return count
+	}
+
+	// commit a state no block refers to
+	current := chain.CurrentBlock()
+	statedb, err := chain.StateAt(current.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 64; i++ {
+		statedb.SetState(genesis.Config.SysName, common.BigToHash(big.NewInt(int64(i))), common.BigToHash(big.NewInt(int64(i+1))))
+	}
+	batch := chain.db.NewBatch()
+	orphan, err := statedb.Commit(batch, common.Hash{}, current.NumberU64()+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := statedb.Database().TrieDB().Commit(orphan, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	before := nodes()
+	reachable, deleted, err := chain.collectState(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted == 0 || before-deleted != nodes() {
+		t.Fatalf("deleted %d of %d nodes, %d left", deleted, before, nodes())
+	}
+
+	// the retained state is complete, the orphan one is gone
+	marked := newMarkSet(memdb.NewMemDatabase())
+	if err := markTrie(state.NewDatabase(chain.db), current.Root(), marked); err != nil {
+		t.Fatal(err)
+	}
+	if marked.count > reachable {
+		t.Fatalf("current state has %d nodes, %d reachable", marked.count, reachable)
+	}
+	if err := markTrie(state.NewDatabase(chain.db), orphan, newMarkSet(memdb.NewMemDatabase())); err == nil {
+		t.Fatal("orphan state not collected")
+	}
+	for _, key := range chain.db.(*memdb.MemDatabase).Keys() {
+		if bytes.HasPrefix(key, stateMarkPrefix) {
+			t.Fatal("collection marks left on the database")
+		}
+	}
+	if _, deleted, err := chain.collectState(1); err != nil || deleted != 0 {
+		t.Fatalf("second collection deleted %d err %v", deleted, err)
+	}
+}
@@ -88,7 +88,7 @@ func makeSystemCandidatesAndTime(parentTime uint64, genesis *Genesis) ([]string,
 		candidates     []string
 		baseCandidates = getCandidates()
 	)
-	dcfg := dposConfig(genesis.Config)
+	dcfg := dpos.NewConfig(genesis.Config)
 	for i := uint64(0); i < (dcfg.EpochInterval/dcfg.BlockInterval*dcfg.BlockFrequency)+1; i++ {
 		for j := 0; j < len(baseCandidates); j++ {
 			for k := 0; k < int(genesis.Config.DposCfg.BlockFrequency); k++ {
@@ -126,7 +126,7 @@ func makeCandidatesAndTime(parentTime uint64, genesis *Genesis, rounds uint64) (
 func newCanonical(t *testing.T, genesis *Genesis) *BlockChain {
 	// Initialize a fresh chain with only a genesis block
 	chainDb := memdb.NewMemDatabase()
-	chainCfg, dposCfg, _, err := SetupGenesisBlock(chainDb, genesis)
+	chainCfg, _, err := SetupGenesisBlock(chainDb, genesis)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -156,7 +156,7 @@ func newCanonical(t *testing.T, genesis *Genesis) *BlockChain {
 	chainCfg.SysTokenID = assetInfo.AssetId
 	chainCfg.SysTokenDecimals = assetInfo.Decimals
 
-	engine := dpos.New(dposCfg, blockchain)
+	engine := dpos.New(dpos.NewConfig(chainCfg), blockchain)
 	bc := struct {
 		*BlockChain
 		consensus.IEngine
@@ -177,7 +177,7 @@ func makeNewChain(t *testing.T, genesis *Genesis, chain *BlockChain, candidates
 		t.Fatal(err)
 	}
 
-	engine := dpos.New(dposConfig(genesis.Config), chain)
+	engine := dpos.New(dpos.NewConfig(genesis.Config), chain)
 
 	newblocks, _ := generateChain(genesis.Config, chain.CurrentBlock(), engine, chain, tmpdb,
 		len(headerTimes), func(i int, b *BlockGenerator) {
@@ -220,7 +220,7 @@ func generateForkBlocks(t *testing.T, genesis *Genesis, candidates []string, hea
 		t.Fatal(err)
 	}
 
-	engine := dpos.New(dposConfig(genesis.Config), chain)
+	engine := dpos.New(dpos.NewConfig(genesis.Config), chain)
 
 	newblocks, _ := generateChain(genesis.Config, chain.CurrentBlock(), engine, chain, tmpdb,
 		len(headerTimes), func(i int, b *BlockGenerator) {
@@ -337,6 +337,12 @@ func generateChain(config *params.ChainConfig, parent *types.Block, engine conse
 				panic(fmt.Sprintf("engine prepare error: %v", err))
 			}
 
+			if b.processor != nil {
+				if err := b.processor.ApplyBlockTransitions(b.statedb, b.header); err != nil {
+					panic(fmt.Sprintf("apply block transitions error: %v", err))
+				}
+			}
+
 			block, err := b.engine.Finalize(b, b.header, b.txs, b.receipts, b.statedb)
 			if err != nil {
 				panic(fmt.Sprintf("engine finalize error: %v", err))
@@ -428,3 +434,12 @@ func checkCompleteChain(t *testing.T, chain *BlockChain) {
 		block = parentBlock
 	}
 }
+
+// setForkID switches the generated block and its state to the fork id.
+func (b *BlockGenerator) setForkID(id uint64) {
+	fc := NewForkController(&ForkConfig{}, b.config)
+	if err := fc.putForkInfo(ForkInfo{CurForkID: id, NextForkID: id}, b.statedb); err != nil {
+		panic(err)
+	}
+	b.header.WithForkID(id, id)
+}
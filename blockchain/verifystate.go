@@ -0,0 +1,78 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state/mtp"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// VerifyState walks the state trie of the canonical block number, checking the
+// hashes and the references of its nodes. With repair the missing and corrupted
+// nodes are downloaded from the connected stations until the trie is complete
+// or the stations do not have them.
+func (bc *BlockChain) VerifyState(number uint64, repair bool) (*types.StateReport, error) {
+	header := bc.GetHeaderByNumber(number)
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	var fetch func([]common.Hash) map[common.Hash][]byte
+	if repair {
+		fetch = bc.station.downloader.fetchNodes
+	}
+	report, err := bc.verifyState(header.Root, fetch)
+	if report != nil {
+		report.Number = number
+	}
+	return report, err
+}
+
+// verifyState verifies the state trie at root, the bad nodes are replaced by
+// the ones returned by fetch unless it is nil.
+func (bc *BlockChain) verifyState(root common.Hash, fetch func([]common.Hash) map[common.Hash][]byte) (*types.StateReport, error) {
+	report := &types.StateReport{Root: root}
+	for {
+		trieReport := mtp.VerifyTrie(bc.stateCache.TrieDB(), root)
+		report.Nodes, report.Shared = trieReport.Nodes, trieReport.Shared
+		report.Missing, report.Corrupted = trieReport.Missing, trieReport.Corrupted
+
+		bad := append(append([]common.Hash{}, report.Missing...), report.Corrupted...)
+		if fetch == nil || len(bad) == 0 {
+			return report, nil
+		}
+		nodes := fetch(bad)
+		if len(nodes) == 0 {
+			log.Warn("State repair incomplete", "root", root, "missing", len(report.Missing), "corrupted", len(report.Corrupted))
+			return report, nil
+		}
+		batch := bc.db.NewBatch()
+		for hash, blob := range nodes {
+			if err := batch.Put(hash[:], blob); err != nil {
+				return report, err
+			}
+		}
+		if err := batch.Write(); err != nil {
+			return report, err
+		}
+		report.Repaired += len(nodes)
+		log.Info("State nodes repaired", "root", root, "nodes", len(nodes))
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestVerifyState(t *testing.T) {
+	genesis := DefaultGenesis()
+	genesis.AllocAccounts = append(genesis.AllocAccounts, getDefaultGenesisAccounts()...)
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	root := chain.CurrentBlock().Root()
+	report, err := chain.VerifyState(chain.CurrentBlock().NumberU64(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Nodes == 0 || len(report.Missing) != 0 || len(report.Corrupted) != 0 {
+		t.Fatalf("intact state: nodes %d missing %v corrupted %v", report.Nodes, report.Missing, report.Corrupted)
+	}
+	nodes := report.Nodes
+
+	// damage a copy of the database, the original one serves the repair
+	db := chain.db.(*memdb.MemDatabase)
+	backup := db.Copy()
+	damaged := 0
+	for _, key := range db.Keys() {
+		if len(key) != common.HashLength || common.BytesToHash(key) == root {
+			continue
+		}
+		if damaged%2 == 0 {
+			db.Delete(key)
+		} else {
+			db.Put(key, []byte{0xc0})
+		}
+		if damaged++; damaged == 4 {
+			break
+		}
+	}
+	report, err = chain.verifyState(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Missing)+len(report.Corrupted) == 0 || report.Nodes >= nodes {
+		t.Fatalf("damaged state: nodes %d missing %v corrupted %v", report.Nodes, report.Missing, report.Corrupted)
+	}
+
+	fetch := func(hashes []common.Hash) map[common.Hash][]byte {
+		blobs := make(map[common.Hash][]byte)
+		for _, hash := range hashes {
+			if blob, err := backup.Get(hash[:]); err == nil {
+				blobs[hash] = blob
+			}
+		}
+		return blobs
+	}
+	report, err = chain.verifyState(root, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Nodes != nodes || report.Repaired == 0 || len(report.Missing)+len(report.Corrupted) != 0 {
+		t.Fatalf("repaired state: nodes %d/%d repaired %d missing %v corrupted %v", report.Nodes, nodes, report.Repaired, report.Missing, report.Corrupted)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/fractalplatform/fractal/params"
+	"github.com/spf13/cobra"
+)
+
+var auditCommand = &cobra.Command{
+	Use:   "audit",
+	Short: "Re-execute the historical blocks and compare the state roots and receipts",
+	Long:  "Re-execute the historical blocks and compare the state roots and receipts, the node must run with --audit",
+}
+
+var auditStartCmd = &cobra.Command{
+	Use:   "start <from uint64> <to uint64> [interval ms uint64]",
+	Short: "Start the audit of the blocks from to to in the background, to 0 is the current block.",
+	Long:  `Start the audit of the blocks from to to in the background, to 0 is the current block.`,
+	Args:  cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		var interval *uint64
+		if len(args) == 3 {
+			ms := parseUint64(args[2])
+			interval = &ms
+		}
+		var result bool
+		clientCall(ipcEndpoint, &result, "audit_start", parseUint64(args[0]), parseUint64(args[1]), interval)
+		printJSON(result)
+	},
+}
+
+var auditStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running audit.",
+	Long:  `Stop the running audit.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var result bool
+		clientCall(ipcEndpoint, &result, "audit_stop")
+		printJSON(result)
+	},
+}
+
+var auditStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Returns the progress and the divergent blocks of the last audit.",
+	Long:  `Returns the progress and the divergent blocks of the last audit.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var result interface{}
+		clientCall(ipcEndpoint, &result, "audit_status")
+		printJSON(result)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(auditCommand)
+	auditCommand.AddCommand(auditStartCmd, auditStopCmd, auditStatusCmd)
+	auditCommand.PersistentFlags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/fractalplatform/fractal/cmd/utils"
 	"github.com/fractalplatform/fractal/debug"
 	"github.com/fractalplatform/fractal/ftservice"
+	"github.com/fractalplatform/fractal/ftservice/checkpoint"
+	"github.com/fractalplatform/fractal/ftservice/stream"
 	"github.com/fractalplatform/fractal/ftservice/gasprice"
 	"github.com/fractalplatform/fractal/metrics"
 	"github.com/fractalplatform/fractal/node"
@@ -59,7 +61,7 @@ func defaultNodeConfig() *node.Config {
 		IPCPath:          params.ClientIdentifier + ".ipc",
 		HTTPHost:         "localhost",
 		HTTPPort:         8545,
-		HTTPModules:      []string{"ft", "dpos", "fee", "account"},
+		HTTPModules:      []string{"ft", "dpos", "fee", "account", "chain"},
 		HTTPVirtualHosts: []string{"localhost"},
 		HTTPCors:         []string{"*"},
 		WSHost:           "localhost",
@@ -83,12 +85,15 @@ func defaultFtServiceConfig() *ftservice.Config {
 	return &ftservice.Config{
 		DatabaseHandles: makeDatabaseHandles(),
 		DatabaseCache:   768,
+		ObjectCache:     4096,
 		TxPool:          txpool.DefaultTxPoolConfig,
 		Miner:           defaultMinerConfig(),
 		GasPrice: gasprice.Config{
 			Blocks: 20,
 		},
 		MetricsConf:     defaultMetricsConfig(),
+		Checkpoint:      &checkpoint.Config{},
+		Stream:          &stream.Config{Topic: "fractal"},
 		ContractLogFlag: false,
 		StatePruning:    true,
 	}
@@ -0,0 +1,55 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/spf13/cobra"
+)
+
+var verifyStateRepair bool
+
+var dbCommand = &cobra.Command{
+	Use:   "db",
+	Short: "Check the databases of the running node",
+	Long:  "Check the databases of the running node",
+	Args:  cobra.NoArgs,
+}
+
+var verifyStateCmd = &cobra.Command{
+	Use:   "verifystate [number|latest]",
+	Short: "Walk the state trie of a block checking the hashes and references of its nodes.",
+	Long:  `Walk the state trie of a block checking the hashes and references of its nodes, with --repair the missing and corrupted nodes are downloaded from the peers.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		number := "latest"
+		if len(args) == 1 {
+			number = args[0]
+		}
+		result := new(types.StateReport)
+		clientCall(ipcEndpoint, &result, "debug_verifyState", number, verifyStateRepair)
+		printJSON(result)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(dbCommand)
+	dbCommand.AddCommand(verifyStateCmd)
+	dbCommand.PersistentFlags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+	verifyStateCmd.Flags().BoolVar(&verifyStateRepair, "repair", false, "download the missing and corrupted nodes from the peers")
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/ftservice"
+	"github.com/spf13/cobra"
+)
+
+var exportAccountsCommand = &cobra.Command{
+	Use:   "exportaccounts -d <datadir> <accounts file name> [num]",
+	Short: "Export the assets and the accounts at a block to file",
+	Long:  "Export the assets and the accounts with their balances at the state root of the block, the current block if no number is given",
+	Run: func(cmd *cobra.Command, args []string) {
+		ftCfgInstance.LogCfg.Setup()
+		if err := exportAccounts(args); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportAccountsCommand)
+	exportAccountsCommand.Flags().StringVarP(&ftCfgInstance.NodeCfg.DataDir, "datadir", "d", ftCfgInstance.NodeCfg.DataDir, "Data directory for the databases ")
+}
+
+func exportAccounts(args []string) error {
+	if len(args) < 1 {
+		return errors.New("This command requires an argument")
+	}
+
+	stack, err := makeNode()
+	if err != nil {
+		return err
+	}
+	ftsrv, err := ftservice.New(stack.GetNodeConfig(), ftCfgInstance.FtServiceCfg)
+	if err != nil {
+		return err
+	}
+
+	chain := ftsrv.BlockChain()
+	header := chain.CurrentBlock().Header()
+	if len(args) > 1 {
+		number, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return errors.New("Export error in parsing parameters: block number not an integer")
+		}
+		if header = chain.GetHeaderByNumber(number); header == nil {
+			return fmt.Errorf("Export error: block %d not found", number)
+		}
+	}
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		return err
+	}
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return err
+	}
+
+	fn := args[0]
+	log.Info("Exporting accounts", "file", fn, "number", header.Number, "root", header.Root)
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	count, err := am.ExportAccounts(writer, header.Root)
+	if err != nil {
+		return err
+	}
+	log.Info("Exported accounts", "file", fn, "accounts", count)
+	return nil
+}
@@ -240,6 +240,14 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("node.wsexposeall", flags.Lookup("ws_exposeall"))
 
+	flags.Uint64Var(
+		&ftCfgInstance.NodeCfg.RPCSlowQuery,
+		"rpc_slowquery",
+		ftCfgInstance.NodeCfg.RPCSlowQuery,
+		"RPC:calls taking longer than this many milliseconds are logged with their parameters, 0 disables the log",
+	)
+	viper.BindPFlag("node.rpcslowquery", flags.Lookup("rpc_slowquery"))
+
 	// ftservice database options
 	flags.IntVar(
 		&ftCfgInstance.FtServiceCfg.DatabaseCache,
@@ -249,6 +257,30 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("ftservice.databasecache", flags.Lookup("database_cache"))
 
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.StateDir,
+		"database_statedir",
+		ftCfgInstance.FtServiceCfg.StateDir,
+		"Directory of the state trie database, empty keeps it in the chain database",
+	)
+	viper.BindPFlag("ftservice.statedir", flags.Lookup("database_statedir"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.AncientDir,
+		"database_ancientdir",
+		ftCfgInstance.FtServiceCfg.AncientDir,
+		"Directory of the headers, bodies and receipts database, empty keeps it in the chain database",
+	)
+	viper.BindPFlag("ftservice.ancientdir", flags.Lookup("database_ancientdir"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.IndexDir,
+		"database_indexdir",
+		ftCfgInstance.FtServiceCfg.IndexDir,
+		"Directory of the transaction lookup and bloom bits database, empty keeps it in the chain database",
+	)
+	viper.BindPFlag("ftservice.indexdir", flags.Lookup("database_indexdir"))
+
 	flags.BoolVar(
 		&ftCfgInstance.FtServiceCfg.ContractLogFlag,
 		"contractlog",
@@ -266,6 +298,169 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("ftservice.statepruning", flags.Lookup("statepruning_enable"))
 
+	flags.Uint64Var(
+		&ftCfgInstance.FtServiceCfg.StateGC,
+		"state_gc",
+		ftCfgInstance.FtServiceCfg.StateGC,
+		"number of the recent block states kept with the snapshot states by the state garbage collection, 0 keeps every state.",
+	)
+	viper.BindPFlag("ftservice.stategc", flags.Lookup("state_gc"))
+
+	flags.Uint64Var(
+		&ftCfgInstance.FtServiceCfg.StateGCInterval,
+		"state_gcinterval",
+		ftCfgInstance.FtServiceCfg.StateGCInterval,
+		"number of blocks between two state garbage collections, 0 collects every state_gc blocks.",
+	)
+	viper.BindPFlag("ftservice.stategcinterval", flags.Lookup("state_gcinterval"))
+
+	flags.IntVar(
+		&ftCfgInstance.FtServiceCfg.ObjectCache,
+		"state_objectcache",
+		ftCfgInstance.FtServiceCfg.ObjectCache,
+		"number of decoded accounts and assets shared between the blocks, 0 disables the cache.",
+	)
+	viper.BindPFlag("ftservice.objectcache", flags.Lookup("state_objectcache"))
+
+	// load generator
+	flags.BoolVar(
+		&ftCfgInstance.FtServiceCfg.LoadGen,
+		"loadgen",
+		ftCfgInstance.FtServiceCfg.LoadGen,
+		"flag for enable the admin load generator, devnet only.",
+	)
+	viper.BindPFlag("ftservice.loadgen", flags.Lookup("loadgen"))
+
+	flags.BoolVar(
+		&ftCfgInstance.FtServiceCfg.ManagedSender,
+		"managedsender",
+		ftCfgInstance.FtServiceCfg.ManagedSender,
+		"flag for enable the sender assigning the nonces of the local accounts.",
+	)
+	viper.BindPFlag("ftservice.managedsender", flags.Lookup("managedsender"))
+
+	flags.BoolVar(
+		&ftCfgInstance.FtServiceCfg.GasStats,
+		"gasstats",
+		ftCfgInstance.FtServiceCfg.GasStats,
+		"flag for enable the gas usage statistics per action type and contract of the recent blocks.",
+	)
+	viper.BindPFlag("ftservice.gasstats", flags.Lookup("gasstats"))
+
+	flags.BoolVar(
+		&ftCfgInstance.FtServiceCfg.Search,
+		"search",
+		ftCfgInstance.FtServiceCfg.Search,
+		"flag for enable the full-text search index of the account descriptions and the asset names, symbols and descriptions.",
+	)
+	viper.BindPFlag("ftservice.search", flags.Lookup("search"))
+
+	flags.BoolVar(
+		&ftCfgInstance.FtServiceCfg.Audit,
+		"audit",
+		ftCfgInstance.FtServiceCfg.Audit,
+		"flag for enable the audit re-executing the historical blocks and comparing the state roots and receipts.",
+	)
+	viper.BindPFlag("ftservice.audit", flags.Lookup("audit"))
+
+	// epoch checkpoint exporter
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.Target,
+		"checkpoint_target",
+		ftCfgInstance.FtServiceCfg.Checkpoint.Target,
+		"checkpoint storage, s3 or ipfs, empty disable the epoch checkpoint exporter.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.target", flags.Lookup("checkpoint_target"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.Endpoint,
+		"checkpoint_endpoint",
+		ftCfgInstance.FtServiceCfg.Checkpoint.Endpoint,
+		"checkpoint s3 endpoint or ipfs api url.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.endpoint", flags.Lookup("checkpoint_endpoint"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.Bucket,
+		"checkpoint_bucket",
+		ftCfgInstance.FtServiceCfg.Checkpoint.Bucket,
+		"checkpoint s3 bucket.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.bucket", flags.Lookup("checkpoint_bucket"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.Region,
+		"checkpoint_region",
+		ftCfgInstance.FtServiceCfg.Checkpoint.Region,
+		"checkpoint s3 region.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.region", flags.Lookup("checkpoint_region"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.AccessKey,
+		"checkpoint_accesskey",
+		ftCfgInstance.FtServiceCfg.Checkpoint.AccessKey,
+		"checkpoint s3 access key id.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.accesskey", flags.Lookup("checkpoint_accesskey"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.SecretKey,
+		"checkpoint_secretkey",
+		ftCfgInstance.FtServiceCfg.Checkpoint.SecretKey,
+		"checkpoint s3 secret access key.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.secretkey", flags.Lookup("checkpoint_secretkey"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.PrivateKey,
+		"checkpoint_privatekey",
+		ftCfgInstance.FtServiceCfg.Checkpoint.PrivateKey,
+		"hex private key signing the checkpoints.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.privatekey", flags.Lookup("checkpoint_privatekey"))
+
+	flags.BoolVar(
+		&ftCfgInstance.FtServiceCfg.Checkpoint.Archive,
+		"checkpoint_archive",
+		ftCfgInstance.FtServiceCfg.Checkpoint.Archive,
+		"flag for publish the accounts archive with the checkpoint.",
+	)
+	viper.BindPFlag("ftservice.checkpoint.archive", flags.Lookup("checkpoint_archive"))
+
+	// block events streaming
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Stream.Sink,
+		"stream_sink",
+		ftCfgInstance.FtServiceCfg.Stream.Sink,
+		"sink of the block events, kafka or nats, empty disable the streaming.",
+	)
+	viper.BindPFlag("ftservice.stream.sink", flags.Lookup("stream_sink"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Stream.Endpoint,
+		"stream_endpoint",
+		ftCfgInstance.FtServiceCfg.Stream.Endpoint,
+		"kafka rest proxy url or nats server url of the stream sink.",
+	)
+	viper.BindPFlag("ftservice.stream.endpoint", flags.Lookup("stream_endpoint"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Stream.Topic,
+		"stream_topic",
+		ftCfgInstance.FtServiceCfg.Stream.Topic,
+		"topic prefix of the stream, the balance, account and supply events are published to its sub topics.",
+	)
+	viper.BindPFlag("ftservice.stream.topic", flags.Lookup("stream_topic"))
+
+	flags.Uint64Var(
+		&ftCfgInstance.FtServiceCfg.Stream.Confirmations,
+		"stream_confirmations",
+		ftCfgInstance.FtServiceCfg.Stream.Confirmations,
+		"blocks on top of a block before its events are streamed.",
+	)
+	viper.BindPFlag("ftservice.stream.confirmations", flags.Lookup("stream_confirmations"))
+
 	// start number
 	flags.Uint64Var(
 		&ftCfgInstance.FtServiceCfg.StartNumber,
@@ -373,6 +568,14 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("ftservice.txpool.resendtime", flags.Lookup("txpool_resendtime"))
 
+	flags.DurationVar(
+		&ftCfgInstance.FtServiceCfg.TxPool.LocalResend,
+		"txpool_localresend",
+		ftCfgInstance.FtServiceCfg.TxPool.LocalResend,
+		"Time interval to re-broadcast the local executable transactions",
+	)
+	viper.BindPFlag("ftservice.txpool.localresend", flags.Lookup("txpool_localresend"))
+
 	flags.Uint64Var(
 		&ftCfgInstance.FtServiceCfg.TxPool.MinBroadcast,
 		"txpool_minbroadcast",
@@ -431,6 +634,14 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("ftservice.miner.name", flags.Lookup("miner_extra"))
 
+	flags.Uint64Var(
+		&ftCfgInstance.FtServiceCfg.Miner.Margin,
+		"miner_margin",
+		ftCfgInstance.FtServiceCfg.Miner.Margin,
+		"Slot time left for block propagation, packing stops when reached, 0 uses 2/5 of the block interval (ms)",
+	)
+	viper.BindPFlag("ftservice.miner.margin", flags.Lookup("miner_margin"))
+
 	// gas price oracle
 	flags.IntVar(
 		&ftCfgInstance.FtServiceCfg.GasPrice.Blocks,
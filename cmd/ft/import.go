@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/fractalplatform/fractal/blockchain"
 	"github.com/fractalplatform/fractal/ftservice"
+	"github.com/fractalplatform/fractal/rawdb"
 	"github.com/fractalplatform/fractal/types"
 	ldb "github.com/fractalplatform/fractal/utils/fdb/leveldb"
 	"github.com/fractalplatform/fractal/utils/rlp"
@@ -111,18 +112,22 @@ func importChain(args []string) error {
 
 	log.Info("Import done in ", "time", time.Since(start))
 
-	db := ftsrv.ChainDb().(*ldb.LDBDatabase)
-	stats, err := db.LDB().GetProperty("leveldb.stats")
-	if err != nil {
-		return fmt.Errorf("Failed to read database stats: %v", err)
+	var dbs []*ldb.LDBDatabase
+	switch db := ftsrv.ChainDb().(type) {
+	case *ldb.LDBDatabase:
+		dbs = append(dbs, db)
+	case *rawdb.TieredDatabase:
+		for _, tdb := range db.Databases() {
+			if l, ok := tdb.(*ldb.LDBDatabase); ok {
+				dbs = append(dbs, l)
+			}
+		}
 	}
-	fmt.Println(stats)
-
-	ioStats, err := db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		return fmt.Errorf("Failed to read database iostats: %v", err)
+	for _, db := range dbs {
+		if err := printDBStats(db); err != nil {
+			return err
+		}
 	}
-	fmt.Println(ioStats)
 
 	mem := new(runtime.MemStats)
 	runtime.ReadMemStats(mem)
@@ -133,25 +138,34 @@ func importChain(args []string) error {
 	fmt.Printf("GC pause:      %v\n\n", time.Duration(mem.PauseTotalNs))
 
 	// Compact the entire database to more accurately measure disk io and print the stats
-	start = time.Now()
-	fmt.Println("Compacting entire database...")
-	if err = db.LDB().CompactRange(util.Range{}); err != nil {
-		return fmt.Errorf("Compaction failed: %v", err)
+	for _, db := range dbs {
+		start = time.Now()
+		fmt.Printf("Compacting entire database %v...\n", db.Path())
+		if err = db.LDB().CompactRange(util.Range{}); err != nil {
+			return fmt.Errorf("Compaction failed: %v", err)
+		}
+		fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
+
+		if err := printDBStats(db); err != nil {
+			return err
+		}
 	}
-	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.LDB().GetProperty("leveldb.stats")
+	return nil
+}
+
+func printDBStats(db *ldb.LDBDatabase) error {
+	stats, err := db.LDB().GetProperty("leveldb.stats")
 	if err != nil {
 		return fmt.Errorf("Failed to read database stats: %v", err)
 	}
 	fmt.Println(stats)
 
-	ioStats, err = db.LDB().GetProperty("leveldb.iostats")
+	ioStats, err := db.LDB().GetProperty("leveldb.iostats")
 	if err != nil {
 		return fmt.Errorf("Failed to read database iostats: %v", err)
 	}
 	fmt.Println(ioStats)
-
 	return nil
 }
 
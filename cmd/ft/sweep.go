@@ -0,0 +1,59 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/fractalplatform/fractal/params"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sweepTime       uint64
+	sweepGasAssetID uint64
+	sweepGasPrice   string
+	sweepGasLimit   uint64
+)
+
+var sweepCommand = &cobra.Command{
+	Use:   "sweep <to string> <assetID uint64> <accounts string array>",
+	Short: "Generate unsigned transactions sweeping the snapshot balances of the accounts to the destination",
+	Long:  `Generate unsigned transactions sweeping the snapshot balances of the accounts to the destination`,
+	Args:  cobra.MinimumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		sweepArgs := map[string]interface{}{
+			"to":         args[0],
+			"assetId":    parseUint64(args[1]),
+			"accounts":   args[2:],
+			"time":       sweepTime,
+			"gasAssetId": sweepGasAssetID,
+			"gasPrice":   parseBigInt(sweepGasPrice),
+			"gasLimit":   sweepGasLimit,
+		}
+		var result []interface{}
+		clientCall(ipcEndpoint, &result, "account_getSweepTransactions", sweepArgs)
+		printJSONList(result)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(sweepCommand)
+	sweepCommand.Flags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+	sweepCommand.Flags().Uint64VarP(&sweepTime, "time", "t", 0, "snapshot time, 0 use the last snapshot")
+	sweepCommand.Flags().Uint64Var(&sweepGasAssetID, "gasassetid", 0, "gas asset id of the transactions")
+	sweepCommand.Flags().StringVar(&sweepGasPrice, "gasprice", "0", "gas price of the transactions")
+	sweepCommand.Flags().Uint64Var(&sweepGasLimit, "gaslimit", 30000, "gas limit of the transfer action")
+}
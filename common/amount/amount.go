@@ -0,0 +1,161 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package amount implements the asset amount helpers: decimal aware parsing and
+// formatting, overflow checked arithmetic and the string json encoding of big integers.
+package amount
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MaxDecimals is the max decimals of an asset amount.
+const MaxDecimals = 77
+
+var (
+	// MaxAmount is the max amount, an amount is a 256 bits unsigned integer.
+	MaxAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrNegativeAmount  = errors.New("negative amount")
+	ErrAmountOverflow  = errors.New("amount overflow")
+	ErrInvalidDecimals = errors.New("invalid decimals")
+	ErrTooManyDecimals = errors.New("amount has more fraction digits than the asset decimals")
+)
+
+// check returns x if it is an amount.
+func check(x *big.Int) (*big.Int, error) {
+	if x.Sign() < 0 {
+		return nil, ErrNegativeAmount
+	}
+	if x.Cmp(MaxAmount) > 0 {
+		return nil, ErrAmountOverflow
+	}
+	return x, nil
+}
+
+// Parse parses the decimal string of an amount in the asset unit, such as "1.5",
+// to the amount in the smallest unit of an asset of the decimals.
+func Parse(s string, decimals uint64) (*big.Int, error) {
+	if decimals > MaxDecimals {
+		return nil, ErrInvalidDecimals
+	}
+	s = strings.TrimSpace(s)
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if len(intPart) == 0 && len(fracPart) == 0 {
+		return nil, ErrInvalidAmount
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return nil, ErrInvalidAmount
+			}
+		}
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+	if uint64(len(fracPart)) > decimals {
+		return nil, ErrTooManyDecimals
+	}
+	digits := intPart + fracPart + strings.Repeat("0", int(decimals)-len(fracPart))
+	x, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, ErrInvalidAmount
+	}
+	return check(x)
+}
+
+// Format formats the amount in the smallest unit of an asset of the decimals to
+// the decimal string in the asset unit, the trailing fraction zeros are dropped.
+func Format(x *big.Int, decimals uint64) string {
+	if x == nil {
+		return "0"
+	}
+	sign := ""
+	if x.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(x).String()
+	if decimals == 0 {
+		return sign + digits
+	}
+	if uint64(len(digits)) <= decimals {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(decimals)
+	frac := strings.TrimRight(digits[point:], "0")
+	if len(frac) == 0 {
+		return sign + digits[:point]
+	}
+	return sign + digits[:point] + "." + frac
+}
+
+// Add returns x + y, it fails if the sum is not an amount.
+func Add(x, y *big.Int) (*big.Int, error) {
+	return check(new(big.Int).Add(x, y))
+}
+
+// Sub returns x - y, it fails if y is greater than x.
+func Sub(x, y *big.Int) (*big.Int, error) {
+	return check(new(big.Int).Sub(x, y))
+}
+
+// Mul returns x * y, it fails if the product is not an amount.
+func Mul(x, y *big.Int) (*big.Int, error) {
+	return check(new(big.Int).Mul(x, y))
+}
+
+// Amount is a big integer encoded as a decimal json string, it decodes from a
+// decimal or hex string or a json number.
+type Amount big.Int
+
+// NewAmount returns the amount of x.
+func NewAmount(x *big.Int) *Amount {
+	return (*Amount)(new(big.Int).Set(x))
+}
+
+// ToInt returns the big integer of the amount.
+func (a *Amount) ToInt() *big.Int {
+	return (*big.Int)(a)
+}
+
+// String returns the decimal string of the amount.
+func (a *Amount) String() string {
+	return a.ToInt().String()
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a *Amount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + a.String() + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Amount) UnmarshalJSON(input []byte) error {
+	s := string(input)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	if _, ok := a.ToInt().SetString(s, 0); !ok {
+		return fmt.Errorf("%v %s", ErrInvalidAmount, s)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package amount
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseAndFormat(t *testing.T) {
+	tests := []struct {
+		s        string
+		decimals uint64
+		want     string
+		format   string
+		err      error
+	}{
+		{"1.5", 18, "1500000000000000000", "1.5", nil},
+		{"0.000000000000000001", 18, "1", "0.000000000000000001", nil},
+		{"100", 0, "100", "100", nil},
+		{"12.3400", 4, "123400", "12.34", nil},
+		{".5", 2, "50", "0.5", nil},
+		{"1.", 2, "100", "1", nil},
+		{"1.234", 2, "", "", ErrTooManyDecimals},
+		{"-1", 2, "", "", ErrInvalidAmount},
+		{"1e18", 2, "", "", ErrInvalidAmount},
+		{".", 2, "", "", ErrInvalidAmount},
+		{"1", 78, "", "", ErrInvalidDecimals},
+	}
+	for _, test := range tests {
+		x, err := Parse(test.s, test.decimals)
+		if err != test.err {
+			t.Fatalf("parse %s err %v, want %v", test.s, err, test.err)
+		}
+		if err != nil {
+			continue
+		}
+		if x.String() != test.want {
+			t.Fatalf("parse %s got %v, want %s", test.s, x, test.want)
+		}
+		if s := Format(x, test.decimals); s != test.format {
+			t.Fatalf("format %v got %s, want %s", x, s, test.format)
+		}
+	}
+	if _, err := Parse(MaxAmount.String()+"0", 0); err != ErrAmountOverflow {
+		t.Fatalf("parse overflow err %v", err)
+	}
+}
+
+func TestCheckedArithmetic(t *testing.T) {
+	if _, err := Add(MaxAmount, big.NewInt(1)); err != ErrAmountOverflow {
+		t.Fatalf("add overflow err %v", err)
+	}
+	if _, err := Sub(big.NewInt(1), big.NewInt(2)); err != ErrNegativeAmount {
+		t.Fatalf("sub underflow err %v", err)
+	}
+	if _, err := Mul(MaxAmount, big.NewInt(2)); err != ErrAmountOverflow {
+		t.Fatalf("mul overflow err %v", err)
+	}
+	if x, err := Sub(big.NewInt(5), big.NewInt(2)); err != nil || x.Int64() != 3 {
+		t.Fatalf("sub got %v err %v", x, err)
+	}
+}
+
+type testInner struct {
+	Balance *big.Int `json:"balance"`
+}
+
+type testEmbedded struct {
+	Total big.Int
+}
+
+type testResult struct {
+	testEmbedded
+	Name     string                 `json:"name"`
+	Nonce    uint64                 `json:"nonce"`
+	Value    *big.Int               `json:"value,omitempty"`
+	Balances []*testInner           `json:"balances"`
+	ByAsset  map[uint64]*big.Int    `json:"byAsset"`
+	Amount   *Amount                `json:"amount"`
+	Extra    map[string]interface{} `json:"extra"`
+	private  *big.Int
+}
+
+func TestStringifyBigInts(t *testing.T) {
+	big1, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	res := &testResult{
+		testEmbedded: testEmbedded{Total: *big.NewInt(7)},
+		Name:         "test",
+		Nonce:        1,
+		Balances:     []*testInner{{Balance: big1}, {}},
+		ByAsset:      map[uint64]*big.Int{1: big.NewInt(2)},
+		Amount:       NewAmount(big.NewInt(3)),
+		Extra:        map[string]interface{}{"number": big.NewInt(4)},
+		private:      big.NewInt(5),
+	}
+	b, err := json.Marshal(StringifyBigInts(res))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Total":"7","amount":"3","balances":[{"balance":"123456789012345678901234567890"},{"balance":null}],"byAsset":{"1":"2"},"extra":{"number":"4"},"name":"test","nonce":1}`
+	if string(b) != want {
+		t.Fatalf("stringify got %s, want %s", b, want)
+	}
+
+	var dec testResult
+	if err := UnmarshalJSON(b, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Balances[0].Balance.Cmp(big1) != 0 || dec.ByAsset[1].Int64() != 2 || dec.Total.Int64() != 7 || dec.Amount.ToInt().Int64() != 3 {
+		t.Fatalf("unmarshal mismatch %+v", dec)
+	}
+
+	var result interface{} = new(big.Int)
+	if err := UnmarshalJSON([]byte(`"0x10"`), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.(*big.Int).Int64() != 16 {
+		t.Fatalf("unmarshal hex got %v", result)
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package amount
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	bigIntType    = reflect.TypeOf(big.Int{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// bigTypes caches whether a value of the type can hold a big integer.
+	bigTypes   = make(map[reflect.Type]bool)
+	bigTypesMu sync.Mutex
+)
+
+// hasBigInt reports whether a value of the type can hold a big integer encoded by
+// encoding/json, the types with their own json encoding are not walked.
+func hasBigInt(t reflect.Type) bool {
+	bigTypesMu.Lock()
+	defer bigTypesMu.Unlock()
+	return hasBigIntLocked(t)
+}
+
+func hasBigIntLocked(t reflect.Type) bool {
+	if has, ok := bigTypes[t]; ok {
+		return has
+	}
+	// store false first to stop at the recursive types
+	bigTypes[t] = false
+	has := walkType(t)
+	bigTypes[t] = has
+	return has
+}
+
+func walkType(t reflect.Type) bool {
+	if t == bigIntType || (t.Kind() == reflect.Ptr && t.Elem() == bigIntType) {
+		return true
+	}
+	if t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasBigIntLocked(t.Elem())
+	case reflect.Struct:
+		for _, f := range jsonFields(t) {
+			if hasBigIntLocked(f.typ) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// field is a struct field encoded by encoding/json.
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+// jsonFields returns the fields of the struct type in the encoding/json naming,
+// the fields of the embedded structs are promoted unless shadowed.
+func jsonFields(t reflect.Type) []field {
+	var fields []field
+	seen := make(map[string]bool)
+	var collect func(t reflect.Type, index []int)
+	collect = func(t reflect.Type, index []int) {
+		var embedded []reflect.StructField
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts := tag, ""
+			if i := strings.IndexByte(tag, ','); i >= 0 {
+				name, opts = tag[:i], tag[i+1:]
+			}
+			ft := sf.Type
+			if sf.Anonymous && name == "" {
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					embedded = append(embedded, sf)
+					continue
+				}
+			}
+			if sf.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			fields = append(fields, field{
+				name:      name,
+				index:     append(append([]int{}, index...), i),
+				typ:       sf.Type,
+				omitEmpty: strings.Contains(opts, "omitempty"),
+			})
+		}
+		for _, sf := range embedded {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			collect(ft, append(append([]int{}, index...), sf.Index...))
+		}
+	}
+	collect(t, nil)
+	return fields
+}
+
+// fieldByIndex returns the field value, it is invalid if an embedded pointer is nil.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// StringifyBigInts returns a value with the same json encoding as v except that
+// the big integers are encoded as decimal strings.
+func StringifyBigInts(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return stringify(reflect.ValueOf(v))
+}
+
+func stringify(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	if !hasBigInt(t) {
+		return v.Interface()
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return stringify(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if t.Elem() == bigIntType {
+			return v.Interface().(*big.Int).String()
+		}
+		return stringify(v.Elem())
+	case reflect.Struct:
+		if t == bigIntType {
+			x := v.Interface().(big.Int)
+			return x.String()
+		}
+		m := make(map[string]interface{})
+		for _, f := range jsonFields(t) {
+			fv := fieldByIndex(v, f.index)
+			if !fv.IsValid() || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			m[f.name] = stringify(fv)
+		}
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = stringify(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			m[mapKey(k)] = stringify(v.MapIndex(k))
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if k.Type().Implements(textType) {
+		if b, err := k.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+// UnmarshalJSON decodes the json data into v like json.Unmarshal, in addition the
+// big integers of v are decoded from the decimal or hex strings.
+func UnmarshalJSON(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	// unwrap the pointer to the interface holding the result pointer
+	for rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Interface && !rv.Elem().IsNil() && rv.Elem().Elem().Kind() == reflect.Ptr {
+		rv = rv.Elem().Elem()
+	}
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || !hasBigInt(rv.Type().Elem()) || rv.Type().Elem().Kind() == reflect.Interface {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return err
+	}
+	generic, err := unquote(generic, rv.Type().Elem())
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, rv.Interface())
+}
+
+// unquote replaces the strings decoded for the big integers of the type by json numbers.
+func unquote(v interface{}, t reflect.Type) (interface{}, error) {
+	if v == nil || !hasBigInt(t) {
+		return v, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == bigIntType {
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		x, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("%v %s", ErrInvalidAmount, s)
+		}
+		return json.Number(x.String()), nil
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if s, ok := v.([]interface{}); ok {
+			for i := range s {
+				x, err := unquote(s[i], t.Elem())
+				if err != nil {
+					return nil, err
+				}
+				s[i] = x
+			}
+		}
+	case reflect.Map:
+		if m, ok := v.(map[string]interface{}); ok {
+			for k := range m {
+				x, err := unquote(m[k], t.Elem())
+				if err != nil {
+					return nil, err
+				}
+				m[k] = x
+			}
+		}
+	case reflect.Struct:
+		if m, ok := v.(map[string]interface{}); ok {
+			fields := jsonFields(t)
+			for k := range m {
+				for _, f := range fields {
+					if f.name == k || strings.EqualFold(f.name, k) {
+						x, err := unquote(m[k], f.typ)
+						if err != nil {
+							return nil, err
+						}
+						m[k] = x
+						break
+					}
+				}
+			}
+		}
+	}
+	return v, nil
+}
@@ -14,12 +14,14 @@ const (
 	AccountNameType AuthorType = iota
 	PubKeyType
 	AddressType
+	BLSPubKeyType
 )
 
 type (
 	Author struct {
 		Owner  `json:"owner"`
-		Weight uint64 `json:"weight"`
+		Weight uint64   `json:"weight"`
+		Scope  []uint64 `json:"scope,omitempty"` // action types the key may sign, empty allows all
 	}
 	Owner interface {
 		String() string
@@ -33,12 +35,14 @@ type StorageAuthor struct {
 	Type    AuthorType
 	DataRaw rlp.RawValue
 	Weight  uint64
+	Scope   []uint64 `rlp:"tail"`
 }
 
 type AuthorJSON struct {
 	authorType AuthorType
-	OwnerStr   string `json:"owner"`
-	Weight     uint64 `json:"weight"`
+	OwnerStr   string   `json:"owner"`
+	Weight     uint64   `json:"weight"`
+	Scope      []uint64 `json:"scope,omitempty"`
 }
 
 func NewAuthor(owner Owner, weight uint64) *Author {
@@ -68,6 +72,7 @@ func (a *Author) encode() (*StorageAuthor, error) {
 			Type:    AccountNameType,
 			DataRaw: value,
 			Weight:  a.Weight,
+			Scope:   a.Scope,
 		}, nil
 	case PubKey:
 		value, err := rlp.EncodeToBytes(&aTy)
@@ -78,6 +83,7 @@ func (a *Author) encode() (*StorageAuthor, error) {
 			Type:    PubKeyType,
 			DataRaw: value,
 			Weight:  a.Weight,
+			Scope:   a.Scope,
 		}, nil
 	case Address:
 		value, err := rlp.EncodeToBytes(&aTy)
@@ -88,6 +94,18 @@ func (a *Author) encode() (*StorageAuthor, error) {
 			Type:    AddressType,
 			DataRaw: value,
 			Weight:  a.Weight,
+			Scope:   a.Scope,
+		}, nil
+	case BLSPubKey:
+		value, err := rlp.EncodeToBytes(&aTy)
+		if err != nil {
+			return nil, err
+		}
+		return &StorageAuthor{
+			Type:    BLSPubKeyType,
+			DataRaw: value,
+			Weight:  a.Weight,
+			Scope:   a.Scope,
 		}, nil
 	}
 	return nil, errors.New("Author encode failed")
@@ -99,6 +117,9 @@ func (a *Author) DecodeRLP(s *rlp.Stream) error {
 	if err != nil {
 		return err
 	}
+	if len(storageAuthor.Scope) == 0 {
+		storageAuthor.Scope = nil
+	}
 	return a.decode(storageAuthor)
 }
 
@@ -111,6 +132,7 @@ func (a *Author) decode(sa *StorageAuthor) error {
 		}
 		a.Owner = name
 		a.Weight = sa.Weight
+		a.Scope = sa.Scope
 		return nil
 	case PubKeyType:
 		var pubKey PubKey
@@ -119,6 +141,7 @@ func (a *Author) decode(sa *StorageAuthor) error {
 		}
 		a.Owner = pubKey
 		a.Weight = sa.Weight
+		a.Scope = sa.Scope
 		return nil
 	case AddressType:
 		var address Address
@@ -127,6 +150,16 @@ func (a *Author) decode(sa *StorageAuthor) error {
 		}
 		a.Owner = address
 		a.Weight = sa.Weight
+		a.Scope = sa.Scope
+		return nil
+	case BLSPubKeyType:
+		var key BLSPubKey
+		if err := rlp.DecodeBytes(sa.DataRaw, &key); err != nil {
+			return err
+		}
+		a.Owner = key
+		a.Weight = sa.Weight
+		a.Scope = sa.Scope
 		return nil
 	}
 	return errors.New("Author decode failed")
@@ -135,11 +168,13 @@ func (a *Author) decode(sa *StorageAuthor) error {
 func (a *Author) MarshalJSON() ([]byte, error) {
 	switch aTy := a.Owner.(type) {
 	case Name:
-		return json.Marshal(&AuthorJSON{authorType: AccountNameType, OwnerStr: aTy.String(), Weight: a.Weight})
+		return json.Marshal(&AuthorJSON{authorType: AccountNameType, OwnerStr: aTy.String(), Weight: a.Weight, Scope: a.Scope})
 	case PubKey:
-		return json.Marshal(&AuthorJSON{authorType: PubKeyType, OwnerStr: aTy.String(), Weight: a.Weight})
+		return json.Marshal(&AuthorJSON{authorType: PubKeyType, OwnerStr: aTy.String(), Weight: a.Weight, Scope: a.Scope})
 	case Address:
-		return json.Marshal(&AuthorJSON{authorType: AddressType, OwnerStr: aTy.String(), Weight: a.Weight})
+		return json.Marshal(&AuthorJSON{authorType: AddressType, OwnerStr: aTy.String(), Weight: a.Weight, Scope: a.Scope})
+	case BLSPubKey:
+		return json.Marshal(&AuthorJSON{authorType: BLSPubKeyType, OwnerStr: aTy.String(), Weight: a.Weight, Scope: a.Scope})
 	}
 	return nil, errors.New("Author marshal failed")
 }
@@ -153,12 +188,15 @@ func (a *Author) UnmarshalJSON(data []byte) error {
 	case AccountNameType:
 		a.Owner = Name(aj.OwnerStr)
 		a.Weight = aj.Weight
+		a.Scope = aj.Scope
 	case PubKeyType:
 		a.Owner = HexToPubKey(aj.OwnerStr)
 		a.Weight = aj.Weight
+		a.Scope = aj.Scope
 	case AddressType:
 		a.Owner = HexToAddress(aj.OwnerStr)
 		a.Weight = aj.Weight
+		a.Scope = aj.Scope
 	}
 	return nil
 }
@@ -32,6 +32,7 @@ func TestAuthorEncodeAndDecodeRLP(t *testing.T) {
 		{&Author{Owner: Name("test"), Weight: 1}},
 		{&Author{Owner: HexToPubKey("test"), Weight: 1}},
 		{&Author{Owner: HexToAddress("test"), Weight: 1}},
+		{&Author{Owner: NewBLSPubKey([]byte{1, 2, 3}, []byte{4, 5}), Weight: 1}},
 	}
 	for _, test := range tests {
 		authorBytes, err := rlp.EncodeToBytes(test.inputAuthor)
@@ -68,3 +69,26 @@ func TestAuthorMarshalAndUnMarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestAuthorScopeEncodeAndDecodeRLP(t *testing.T) {
+	legacy, err := rlp.EncodeToBytes(&StorageAuthor{Type: AccountNameType, DataRaw: []byte{0x84, 't', 'e', 's', 't'}, Weight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	author := &Author{Owner: Name("test"), Weight: 1}
+	authorBytes, err := rlp.EncodeToBytes(author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, legacy, authorBytes)
+
+	author.Scope = []uint64{0, 1 << 4}
+	if authorBytes, err = rlp.EncodeToBytes(author); err != nil {
+		t.Fatal(err)
+	}
+	outputAuthor := &Author{}
+	if err := rlp.Decode(bytes.NewReader(authorBytes), outputAuthor); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, author, outputAuthor)
+}
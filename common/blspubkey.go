@@ -0,0 +1,61 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package common
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// BLSPubKeyLength of the BLS public key in bytes
+	BLSPubKeyLength = 48
+	// BLSProofLength of the proof of possession of the BLS secret key in bytes
+	BLSProofLength = 96
+)
+
+// BLSPubKey is a BLS public key author with the proof of possession of its
+// secret key, the authors sign with the BLS keys by an aggregated signature.
+type BLSPubKey struct {
+	Key   [BLSPubKeyLength]byte
+	Proof [BLSProofLength]byte
+}
+
+// NewBLSPubKey returns the BLS public key author of the key and its proof.
+func NewBLSPubKey(key, proof []byte) BLSPubKey {
+	var p BLSPubKey
+	copy(p.Key[:], key)
+	copy(p.Proof[:], proof)
+	return p
+}
+
+// Bytes return the bytes of the key
+func (p BLSPubKey) Bytes() []byte { return p.Key[:] }
+
+// Hex converts the key to a hex string.
+func (p BLSPubKey) Hex() string { return hexutil.Encode(p.Key[:]) }
+
+// String implements fmt.Stringer, the key identifies the author.
+func (p BLSPubKey) String() string {
+	return p.Hex()
+}
+
+// Compare returns an integer comparing the keys lexicographically.
+func (p BLSPubKey) Compare(x BLSPubKey) int {
+	return bytes.Compare(p.Key[:], x.Key[:])
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var errBadProofNode = errors.New("invalid proof node")
+
+// VerifyProof checks the merkle proof of the trie key against the state root
+// and returns the proved value, nil if the proof shows the key is absent. The
+// proof is the encoded trie nodes on the path from the root to the key, as
+// produced by state.Prove.
+func VerifyProof(root Hash, key []byte, proof []hexutil.Bytes) ([]byte, error) {
+	nodes := make(map[Hash][]byte, len(proof))
+	for _, node := range proof {
+		nodes[keccak256Hash(node)] = node
+	}
+	path := keyToNibbles(key)
+	want := root
+	for i := 0; ; i++ {
+		buf, ok := nodes[want]
+		if !ok {
+			return nil, fmt.Errorf("proof node %d (hash %064x) missing", i, want)
+		}
+		value, next, rest, err := walkProofNode(buf, path)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %v", i, err)
+		}
+		if next == nil {
+			return value, nil
+		}
+		want, path = BytesToHash(next), rest
+	}
+}
+
+// walkProofNode follows the path through the encoded node and its embedded
+// children. It returns the value if the path ends in the node, the hash of the
+// next node and the rest of the path if the path leaves the node, and neither
+// if the key is absent.
+func walkProofNode(buf []byte, path []byte) (value []byte, next []byte, rest []byte, err error) {
+	for {
+		elems, _, err := rlp.SplitList(buf)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		count, err := rlp.CountValues(elems)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var ref []byte
+		switch count {
+		case 2:
+			compact, val, err := rlp.SplitString(elems)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if len(compact) == 0 {
+				return nil, nil, nil, errBadProofNode
+			}
+			key := compactToNibbles(compact)
+			if len(path) < len(key) || !bytes.Equal(key, path[:len(key)]) {
+				return nil, nil, nil, nil
+			}
+			path = path[len(key):]
+			if len(key) > 0 && key[len(key)-1] == 16 {
+				value, _, err := rlp.SplitString(val)
+				return value, nil, nil, err
+			}
+			ref = val
+		case 17:
+			if len(path) == 0 {
+				return nil, nil, nil, errBadProofNode
+			}
+			for i := byte(0); i < path[0]; i++ {
+				if _, _, elems, err = rlp.Split(elems); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+			if path[0] == 16 {
+				value, _, err := rlp.SplitString(elems)
+				if len(value) == 0 {
+					value = nil
+				}
+				return value, nil, nil, err
+			}
+			_, _, rest, err := rlp.Split(elems)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			ref, path = elems[:len(elems)-len(rest)], path[1:]
+		default:
+			return nil, nil, nil, errBadProofNode
+		}
+
+		kind, content, _, err := rlp.Split(ref)
+		switch {
+		case err != nil:
+			return nil, nil, nil, err
+		case kind == rlp.List:
+			buf = ref
+		case kind == rlp.String && len(content) == 0:
+			return nil, nil, nil, nil
+		case kind == rlp.String && len(content) == HashLength:
+			return nil, content, path, nil
+		default:
+			return nil, nil, nil, errBadProofNode
+		}
+	}
+}
+
+// keyToNibbles splits the key into nibbles followed by the terminator 16.
+func keyToNibbles(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2+1)
+	for i, b := range key {
+		nibbles[i*2] = b / 16
+		nibbles[i*2+1] = b % 16
+	}
+	nibbles[len(nibbles)-1] = 16
+	return nibbles
+}
+
+// compactToNibbles decodes the compact key of a short node, a leaf key keeps
+// the terminator.
+func compactToNibbles(compact []byte) []byte {
+	base := keyToNibbles(compact)
+	if base[0] < 2 {
+		base = base[:len(base)-1]
+	}
+	return base[2-base[0]&1:]
+}
+
+func keccak256Hash(data []byte) (hash Hash) {
+	d := Get256()
+	defer Put256(d)
+	d.Write(data)
+	d.Sum(hash[:0])
+	return hash
+}
@@ -17,7 +17,11 @@
 package consensus
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
+	"sort"
+	"sync"
 
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/params"
@@ -27,6 +31,20 @@ import (
 	"github.com/fractalplatform/fractal/types"
 )
 
+var (
+	// ErrIllegalCandidateName is returned when the coinbase is not a registered block producer.
+	ErrIllegalCandidateName = errors.New("illegal candidate name")
+	// ErrIllegalCandidatePubKey is returned when none of the local keys may sign for the coinbase.
+	ErrIllegalCandidatePubKey = errors.New("illegal candidate pubkey")
+	// ErrTooMuchRreversible is returned when too many blocks are still reversible to produce another one.
+	ErrTooMuchRreversible = errors.New("too much rreversible blocks")
+	// ErrSystemTakeOver is returned when the system account has taken over block production.
+	ErrSystemTakeOver = errors.New("system account take over")
+)
+
+// SignFn signs the content of a sealed header on behalf of the local producer.
+type SignFn func([]byte, *state.StateDB) ([]byte, error)
+
 // IAPI returns the RPC APIs this consensus engine provides.
 type IAPI interface {
 	APIs(chain IChainReader) []rpc.API
@@ -125,10 +143,112 @@ type IEngine interface {
 	IValidator
 }
 
+// ISealer is implemented by engines which are able to produce blocks, it
+// exposes the scheduling hooks the miner needs on top of IEngine.
+type ISealer interface {
+	// BlockInterval returns the block interval in nanoseconds.
+	BlockInterval() uint64
+
+	// Slot returns the start of the production slot containing timestamp.
+	Slot(timestamp uint64) uint64
+
+	// SetSignFn sets the function used to sign sealed headers.
+	SetSignFn(signFn SignFn)
+
+	// CanSeal checks whether coinbase, signing with one of pubkeys, is allowed to seal the
+	// block at timestamp on top of parent. force lets the system account take over.
+	CanSeal(chain IChainReader, parent *types.Header, timestamp uint64, coinbase string, pubkeys [][]byte, state *state.StateDB, force bool) error
+
+	// PrepareSeal fills the engine specific fields of a header built on top of parent
+	// before its transactions are executed.
+	PrepareSeal(chain IChainReader, parent *types.Header, header *types.Header)
+}
+
+// GenesisCandidate is a block producer allocated in the genesis block.
+type GenesisCandidate struct {
+	Name string
+	URL  string
+}
+
+// EngineCreator creates a consensus engine for the chain config.
+type EngineCreator func(config *params.ChainConfig, chain IChainReader) (IEngine, error)
+
+// GenesisFn writes the engine specific state of the genesis block. It runs after the
+// genesis accounts and assets are created.
+type GenesisFn func(config *params.ChainConfig, state *state.StateDB, timestamp uint64, forkID uint64, candidates []*GenesisCandidate) error
+
+type engine struct {
+	creator EngineCreator
+	genesis GenesisFn
+}
+
+var (
+	enginesMu sync.RWMutex
+	engines   = make(map[string]*engine)
+)
+
+// Register makes a consensus engine available by the provided name.
+// It panics if Register is called twice with the same name.
+func Register(name string, creator EngineCreator, genesis GenesisFn) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if creator == nil || genesis == nil {
+		panic("consensus: register engine creator or genesis is nil")
+	}
+	if _, dup := engines[name]; dup {
+		panic("consensus: register called twice for engine " + name)
+	}
+	engines[name] = &engine{creator: creator, genesis: genesis}
+}
+
+func lookup(name string) (*engine, error) {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	e, ok := engines[name]
+	if !ok {
+		return nil, fmt.Errorf("consensus: unknown engine %q", name)
+	}
+	return e, nil
+}
+
+// NewEngine creates the consensus engine the chain config names.
+func NewEngine(config *params.ChainConfig, chain IChainReader) (IEngine, error) {
+	e, err := lookup(config.EngineName())
+	if err != nil {
+		return nil, err
+	}
+	return e.creator(config, chain)
+}
+
+// Genesis writes the genesis state of the consensus engine the chain config names.
+func Genesis(config *params.ChainConfig, state *state.StateDB, timestamp uint64, forkID uint64, candidates []*GenesisCandidate) error {
+	e, err := lookup(config.EngineName())
+	if err != nil {
+		return err
+	}
+	return e.genesis(config, state, timestamp, forkID, candidates)
+}
+
+// Engines returns the names of the registered consensus engines.
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ITxProcessor is an Processor.
 type ITxProcessor interface {
 	// ApplyTransaction attempts to apply a transaction.
 	ApplyTransaction(coinbase *common.Name, gp *common.GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, uint64, error)
+
+	// ApplyBlockTransitions applies the state transitions due at the end of the block,
+	// before the engine finalizes it.
+	ApplyBlockTransitions(statedb *state.StateDB, header *types.Header) error
 }
 
 // ITxPool contains all currently known transactions.
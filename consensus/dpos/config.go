@@ -83,6 +83,33 @@ type Config struct {
 	safeSize    atomic.Value
 }
 
+// NewConfig returns the dpos config of the chain.
+func NewConfig(cfg *params.ChainConfig) *Config {
+	return &Config{
+		MaxURLLen:                     cfg.DposCfg.MaxURLLen,
+		UnitStake:                     cfg.DposCfg.UnitStake,
+		CandidateAvailableMinQuantity: cfg.DposCfg.CandidateAvailableMinQuantity,
+		CandidateMinQuantity:          cfg.DposCfg.CandidateMinQuantity,
+		VoterMinQuantity:              cfg.DposCfg.VoterMinQuantity,
+		ActivatedMinCandidate:         cfg.DposCfg.ActivatedMinCandidate,
+		ActivatedMinQuantity:          cfg.DposCfg.ActivatedMinQuantity,
+		BlockInterval:                 cfg.DposCfg.BlockInterval,
+		BlockFrequency:                cfg.DposCfg.BlockFrequency,
+		CandidateScheduleSize:         cfg.DposCfg.CandidateScheduleSize,
+		BackupScheduleSize:            cfg.DposCfg.BackupScheduleSize,
+		EpochInterval:                 cfg.DposCfg.EpochInterval,
+		FreezeEpochSize:               cfg.DposCfg.FreezeEpochSize,
+		AccountName:                   cfg.DposName,
+		SystemName:                    cfg.SysName,
+		SystemURL:                     cfg.ChainURL,
+		ExtraBlockReward:              cfg.DposCfg.ExtraBlockReward,
+		BlockReward:                   cfg.DposCfg.BlockReward,
+		Decimals:                      cfg.SysTokenDecimals,
+		AssetID:                       cfg.SysTokenID,
+		ReferenceTime:                 cfg.ReferenceTime,
+	}
+}
+
 func (cfg *Config) decimals() *big.Int {
 	if decimal := cfg.decimal.Load(); decimal != nil {
 		return decimal.(*big.Int)
@@ -43,10 +43,10 @@ var (
 	errInvalidMintBlockTime   = errors.New("invalid time to mint the block")
 	errInvalidBlockCandidate  = errors.New("invalid block candidate")
 	errInvalidTimestamp       = errors.New("invalid timestamp")
-	ErrIllegalCandidateName   = errors.New("illegal candidate name")
-	ErrIllegalCandidatePubKey = errors.New("illegal candidate pubkey")
-	ErrTooMuchRreversible     = errors.New("too much rreversible blocks")
-	ErrSystemTakeOver         = errors.New("system account take over")
+	ErrIllegalCandidateName   = consensus.ErrIllegalCandidateName
+	ErrIllegalCandidatePubKey = consensus.ErrIllegalCandidatePubKey
+	ErrTooMuchRreversible     = consensus.ErrTooMuchRreversible
+	ErrSystemTakeOver         = consensus.ErrSystemTakeOver
 	errUnknownBlock           = errors.New("unknown block")
 	extraSeal                 = 65
 	timeOfGenesisBlock        int64
@@ -141,8 +141,50 @@ func Genesis(cfg *Config, state *state.StateDB, timestamp uint64, number uint64)
 	return nil
 }
 
+// EngineName is the name the dpos engine is registered under.
+const EngineName = "dpos"
+
+func init() {
+	consensus.Register(EngineName, func(config *params.ChainConfig, chain consensus.IChainReader) (consensus.IEngine, error) {
+		cfg := NewConfig(config)
+		if err := cfg.IsValid(); err != nil {
+			return nil, err
+		}
+		return New(cfg, chain), nil
+	}, genesis)
+}
+
+// genesis stores the dpos state and the allocated candidates of the genesis block.
+func genesis(config *params.ChainConfig, state *state.StateDB, timestamp uint64, forkID uint64, candidates []*consensus.GenesisCandidate) error {
+	cfg := NewConfig(config)
+	if err := Genesis(cfg, state, timestamp, 0); err != nil {
+		return err
+	}
+	sys := NewSystem(state, cfg)
+	epoch, err := sys.GetLastestEpoch()
+	if err != nil {
+		return err
+	}
+	for _, candidate := range candidates {
+		if err := sys.SetCandidate(&CandidateInfo{
+			Epoch:         epoch,
+			Name:          candidate.Name,
+			URL:           candidate.URL,
+			Quantity:      big.NewInt(0),
+			TotalQuantity: big.NewInt(0),
+			Number:        0,
+		}); err != nil {
+			return err
+		}
+	}
+	if forkID >= params.ForkID2 {
+		return sys.UpdateElectedCandidates1(epoch, epoch, 0, "")
+	}
+	return sys.UpdateElectedCandidates0(epoch, epoch, 0, "")
+}
+
 // SignFn signature function
-type SignFn func([]byte, *state.StateDB) ([]byte, error)
+type SignFn = consensus.SignFn
 
 // Dpos dpos engine
 type Dpos struct {
@@ -186,6 +228,11 @@ func (dpos *Dpos) SetSignFn(signFn SignFn) {
 	dpos.signFn = signFn
 }
 
+var (
+	_ consensus.IEngine = (*Dpos)(nil)
+	_ consensus.ISealer = (*Dpos)(nil)
+)
+
 // Author implements consensus.Engine, returning the header's coinbase
 func (dpos *Dpos) Author(header *types.Header) (common.Name, error) {
 	return header.Coinbase, nil
@@ -540,18 +587,8 @@ func (dpos *Dpos) finalize0(chain consensus.IChainReader, header *types.Header,
 
 	prevHeader := chain.GetHeaderByHash(blk.ParentHash())
 	snapshotInterval := chain.Config().SnapshotInterval * uint64(time.Millisecond)
-	prevTime := prevHeader.Time.Uint64()
-	prevTimeFormat := prevTime / snapshotInterval * snapshotInterval
-
-	currentTime := blk.Time().Uint64()
-	currentTimeFormat := currentTime / snapshotInterval * snapshotInterval
-
-	if prevTimeFormat != currentTimeFormat {
-		snapshotManager := snapshot.NewSnapshotManager(state)
-		err := snapshotManager.SetSnapshot(currentTimeFormat, snapshot.BlockInfo{Number: blk.NumberU64(), BlockHash: blk.ParentHash(), Timestamp: prevTimeFormat})
-		if err != nil {
-			return nil, err
-		}
+	if err := snapshot.NewSnapshotManager(state).TakeSnapshot(header, prevHeader.Time.Uint64(), snapshotInterval); err != nil {
+		return nil, err
 	}
 
 	gstate, err := sys.GetState(dpos.config.epoch(header.Time.Uint64()))
@@ -697,13 +734,8 @@ func (dpos *Dpos) finalize1(chain consensus.IChainReader, header *types.Header,
 
 	//snapshot
 	snapshotInterval := chain.Config().SnapshotInterval * uint64(time.Millisecond)
-	parentTimeFormat := parent.Time.Uint64() / snapshotInterval * snapshotInterval
-	currentTimeFormat := header.Time.Uint64() / snapshotInterval * snapshotInterval
-	if parentTimeFormat != currentTimeFormat {
-		snapshotManager := snapshot.NewSnapshotManager(state)
-		if err := snapshotManager.SetSnapshot(currentTimeFormat, snapshot.BlockInfo{Number: header.Number.Uint64(), BlockHash: blk.ParentHash(), Timestamp: parentTimeFormat}); err != nil {
-			return nil, err
-		}
+	if err := snapshot.NewSnapshotManager(state).TakeSnapshot(header, parent.Time.Uint64(), snapshotInterval); err != nil {
+		return nil, err
 	}
 
 	// bftIrreversibles
@@ -779,6 +811,20 @@ func (dpos *Dpos) CalcDifficulty(chain consensus.IChainReader, time uint64, pare
 	return big.NewInt((int64(time)-timeOfGenesisBlock)/int64(dpos.config.blockInterval()) + 1)
 }
 
+// CanSeal checks whether the candidate is scheduled to produce the block at timestamp on top of parent.
+func (dpos *Dpos) CanSeal(chain consensus.IChainReader, parent *types.Header, timestamp uint64, coinbase string, pubkeys [][]byte, state *state.StateDB, force bool) error {
+	header := &types.Header{}
+	if err := chain.FillForkID(header, state); err != nil {
+		return err
+	}
+	return dpos.IsValidateCandidate(chain, parent, timestamp, coinbase, pubkeys, state, force, header.CurForkID())
+}
+
+// PrepareSeal sets the irreversible number the header proposes.
+func (dpos *Dpos) PrepareSeal(chain consensus.IChainReader, parent *types.Header, header *types.Header) {
+	header.ProposedIrreversible = dpos.CalcProposedIrreversible(chain, parent, false)
+}
+
 //IsValidateCandidate current candidate
 func (dpos *Dpos) IsValidateCandidate(chain consensus.IChainReader, parent *types.Header, timestamp uint64, candidate string, pubkeys [][]byte, state *state.StateDB, force bool, fid uint64) error {
 	if timestamp%dpos.BlockInterval() != 0 {
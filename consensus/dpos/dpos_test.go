@@ -0,0 +1,44 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"testing"
+
+	"github.com/fractalplatform/fractal/consensus"
+	"github.com/fractalplatform/fractal/params"
+)
+
+func TestEngineRegistry(t *testing.T) {
+	cfg := params.DefaultChainconfig.Copy()
+	engine, err := consensus.NewEngine(cfg, nil)
+	if err != nil {
+		t.Fatalf("new engine err %v", err)
+	}
+	if _, ok := engine.(consensus.ISealer); !ok {
+		t.Fatal("dpos engine should be able to seal blocks")
+	}
+	cfg.DposCfg.EpochInterval = 1
+	if _, err := consensus.NewEngine(cfg, nil); err == nil {
+		t.Fatal("new engine with invalid config should fail")
+	}
+	cfg = params.DefaultChainconfig.Copy()
+	cfg.Engine = "unknown"
+	if _, err := consensus.NewEngine(cfg, nil); err == nil {
+		t.Fatal("new unknown engine should fail")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/fractalplatform/fractal/utils/rlp"
 
 	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/snapshot"
 	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/types"
 )
@@ -55,6 +56,11 @@ type RemoveKickedCandidate struct {
 	Candidates []string
 }
 
+// ForceSnapshot the block taking the snapshot
+type ForceSnapshot struct {
+	Number uint64
+}
+
 // ProcessAction exec action
 func (dpos *Dpos) ProcessAction(fid uint64, number uint64, chainCfg *params.ChainConfig, state *state.StateDB, action *types.Action) ([]*types.InternalAction, error) {
 	snap := state.Snapshot()
@@ -165,6 +171,20 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 				return nil, err
 			}
 		}
+	case types.ForceSnapshot:
+		if strings.Compare(action.Sender().String(), dpos.config.SystemName) != 0 {
+			return nil, fmt.Errorf("no permission for forcing snapshot")
+		}
+		arg := &ForceSnapshot{}
+		if err := rlp.DecodeBytes(action.Data(), &arg); err != nil {
+			return nil, err
+		}
+		if arg.Number < number {
+			return nil, fmt.Errorf("snapshot block %d is before the current block %d", arg.Number, number)
+		}
+		if err := snapshot.NewSnapshotManager(state).ScheduleSnapshot(arg.Number); err != nil {
+			return nil, err
+		}
 
 	case types.ExitTakeOver:
 		gstate, _ := sys.GetState(epoch)
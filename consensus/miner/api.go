@@ -51,6 +51,10 @@ func (api *API) SetDelay(delayDuration uint64) error {
 	return api.miner.SetDelayDuration(delayDuration)
 }
 
+func (api *API) SetMargin(margin uint64) error {
+	return api.miner.SetMargin(margin)
+}
+
 func (api *API) SetExtra(extra string) error {
 	return api.miner.SetExtra([]byte(extra))
 }
@@ -137,6 +137,11 @@ func (miner *Miner) SetDelayDuration(delayDuration uint64) error {
 	return miner.worker.setDelayDuration(delayDuration)
 }
 
+// SetMargin slot time (ms) left for the block propagation
+func (miner *Miner) SetMargin(margin uint64) error {
+	return miner.worker.setMargin(margin)
+}
+
 // SetExtra extra data
 func (miner *Miner) SetExtra(extra []byte) error {
 	if uint64(len(extra)) > params.MaximumExtraDataSize-65 {
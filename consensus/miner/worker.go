@@ -32,9 +32,9 @@ import (
 	"github.com/fractalplatform/fractal/blockchain"
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/consensus"
-	"github.com/fractalplatform/fractal/consensus/dpos"
 	"github.com/fractalplatform/fractal/crypto"
 	"github.com/fractalplatform/fractal/event"
+	"github.com/fractalplatform/fractal/metrics"
 	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/processor"
 	"github.com/fractalplatform/fractal/processor/vm"
@@ -47,6 +47,15 @@ const (
 	// txChanSize = 4096
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+	// blockSizeSlack is the bytes reserved for the header fields filled at the
+	// finalization and the growth of the list prefixes.
+	blockSizeSlack = 64
+)
+
+var (
+	packBlockMeter      = metrics.NewRegisteredMeter("miner/pack/blocks", nil)
+	packCutoffMeter     = metrics.NewRegisteredMeter("miner/pack/cutoff", nil)
+	packOverTimeTxMeter = metrics.NewRegisteredMeter("miner/pack/overtime", nil)
 )
 
 // Worker is the main object which takes care of applying messages to the new state
@@ -55,6 +64,7 @@ type Worker struct {
 
 	mu            sync.Mutex
 	delayDuration uint64
+	margin        uint64
 	coinbase      string
 	privKeys      []*ecdsa.PrivateKey
 	pubKeys       [][]byte
@@ -117,11 +127,11 @@ func (worker *Worker) start(force bool) {
 func (worker *Worker) mintLoop() {
 	worker.wg.Add(1)
 	defer worker.wg.Done()
-	dpos, ok := worker.Engine().(*dpos.Dpos)
+	sealer, ok := worker.Engine().(consensus.ISealer)
 	if !ok {
-		panic("only support dpos engine")
+		panic("consensus engine is not able to seal blocks")
 	}
-	dpos.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+	sealer.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
 		accountDB, err := accountmanager.NewAccountManager(state)
 		if err != nil {
 			return nil, err
@@ -133,7 +143,7 @@ func (worker *Worker) mintLoop() {
 		}
 		return nil, fmt.Errorf("not found match private key for sign")
 	})
-	interval := int64(dpos.BlockInterval())
+	interval := int64(sealer.BlockInterval())
 	c := make(chan time.Time)
 	to := time.Now()
 	worker.utimerTo(to.Add(time.Duration(interval-(to.UnixNano()%interval))), c)
@@ -151,7 +161,7 @@ func (worker *Worker) mintLoop() {
 
 			quit := make(chan struct{})
 			worker.wgWork.Add(1)
-			timestamp := int64(dpos.Slot(uint64(now.UnixNano())))
+			timestamp := int64(sealer.Slot(uint64(now.UnixNano())))
 			go worker.mintBlock(timestamp, quit)
 			to := time.Now()
 			worker.utimerTo(to.Add(time.Duration(interval-(to.UnixNano()%interval))), c)
@@ -182,24 +192,22 @@ func (worker *Worker) mintBlock(timestamp int64, quit chan struct{}) {
 		default:
 		}
 
-		cdpos := worker.Engine().(*dpos.Dpos)
+		sealer := worker.Engine().(consensus.ISealer)
 		header := worker.CurrentHeader()
 		state, err := worker.StateAt(header.Root)
 		if err != nil {
 			log.Error("failed to mint block", "timestamp", timestamp, "err", err)
 			return
 		}
-		theader := &types.Header{}
-		worker.FillForkID(theader, state)
-		if err := cdpos.IsValidateCandidate(worker, header, uint64(timestamp), worker.coinbase, worker.pubKeys, state, worker.force, theader.CurForkID()); err != nil {
+		if err := sealer.CanSeal(worker, header, uint64(timestamp), worker.coinbase, worker.pubKeys, state, worker.force); err != nil {
 			switch err {
-			case dpos.ErrSystemTakeOver:
+			case consensus.ErrSystemTakeOver:
 				fallthrough
-			case dpos.ErrTooMuchRreversible:
+			case consensus.ErrTooMuchRreversible:
 				fallthrough
-			case dpos.ErrIllegalCandidateName:
+			case consensus.ErrIllegalCandidateName:
 				fallthrough
-			case dpos.ErrIllegalCandidatePubKey:
+			case consensus.ErrIllegalCandidatePubKey:
 				log.Warn("failed to mint the block", "timestamp", timestamp, "err", err, "candidate", worker.coinbase)
 			default:
 				log.Debug("failed to mint the block", "timestamp", timestamp, "err", err)
@@ -215,8 +223,7 @@ func (worker *Worker) mintBlock(timestamp int64, quit chan struct{}) {
 			log.Error("failed to mint block", "timestamp", timestamp, "err", err)
 			break
 		} else if strings.Contains(err.Error(), "wait") {
-			worker.usleepTo(time.Now().Add(time.Duration(cdpos.BlockInterval() / 10)))
-			//time.Sleep(time.Duration(cdpos.BlockInterval() / 10))
+			worker.usleepTo(time.Now().Add(time.Duration(sealer.BlockInterval() / 10)))
 		}
 
 		log.Warn("failed to mint block", "timestamp", timestamp, "err", err)
@@ -237,6 +244,25 @@ func (worker *Worker) setDelayDuration(delay uint64) error {
 	return nil
 }
 
+func (worker *Worker) setMargin(margin uint64) error {
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+	worker.margin = margin
+	return nil
+}
+
+// packDeadline returns the time the packing of the block stops, the rest of
+// the slot is left for finalizing and propagating the block.
+func (worker *Worker) packDeadline(timestamp uint64, interval uint64) uint64 {
+	worker.mu.Lock()
+	margin := worker.margin * uint64(time.Millisecond)
+	worker.mu.Unlock()
+	if margin == 0 || margin >= interval {
+		margin = 2 * interval / 5
+	}
+	return timestamp + interval - margin
+}
+
 func (worker *Worker) setCoinbase(name string, privKeys []*ecdsa.PrivateKey) {
 	worker.mu.Lock()
 	defer worker.mu.Unlock()
@@ -257,17 +283,16 @@ func (worker *Worker) setExtra(extra []byte) {
 }
 
 func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit chan struct{}) (*types.Block, error) {
-	dpos := worker.Engine().(*dpos.Dpos)
-	if t := time.Now(); t.UnixNano() >= timestamp+int64(dpos.BlockInterval()) {
+	sealer := worker.Engine().(consensus.ISealer)
+	if t := time.Now(); t.UnixNano() >= timestamp+int64(sealer.BlockInterval()) {
 		return nil, fmt.Errorf("mint the ingore block, need %v, now %v, sub %v", timestamp, t.UnixNano(), t.Sub(time.Unix(timestamp/int64(time.Second), timestamp%int64(time.Second))))
 	}
 	if parent.Time.Int64() >= timestamp {
 		return nil, errors.New("mint the old block")
 	}
-	// if dpos.IsFirst(uint64(timestamp)) && parent.Time.Int64() != timestamp-int64(dpos.BlockInterval()) && timestamp-time.Now().UnixNano() >= int64(dpos.BlockInterval())/10 {
 	if parent.Number.Uint64() > 0 &&
-		parent.Time.Int64()+int64(dpos.BlockInterval()) < timestamp &&
-		time.Now().UnixNano()-timestamp <= 2*int64(dpos.BlockInterval())/5 {
+		parent.Time.Int64()+int64(sealer.BlockInterval()) < timestamp &&
+		time.Now().UnixNano()-timestamp <= 2*int64(sealer.BlockInterval())/5 {
 		return nil, errors.New("wait for last block arrived")
 	}
 
@@ -281,7 +306,7 @@ func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit
 		Difficulty: worker.CalcDifficulty(worker.IConsensus, uint64(timestamp), parent),
 	}
 	header.Coinbase = common.StrToName(worker.coinbase)
-	header.ProposedIrreversible = dpos.CalcProposedIrreversible(worker, parent, false)
+	sealer.PrepareSeal(worker, parent, header)
 
 	state, err := worker.StateAt(parent.Root)
 	if err != nil {
@@ -292,6 +317,7 @@ func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit
 	if err := worker.FillForkID(header, state); err != nil {
 		return nil, err
 	}
+	header.WithBaseFee(types.CalcBaseFee(worker.Config(), parent, header.CurForkID()))
 
 	work := &Work{
 		currentHeader:   header,
@@ -306,6 +332,7 @@ func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit
 	if err := worker.Prepare(worker.IConsensus, work.currentHeader, work.currentTxs, work.currentReceipts, work.currentState); err != nil {
 		return nil, fmt.Errorf("prepare header for mining, err: %v", err)
 	}
+	work.currentSize = uint64(types.NewBlockWithHeader(work.currentHeader).Size()) + blockSizeSlack
 
 	start := time.Now()
 	pending, err := worker.Pending()
@@ -319,11 +346,14 @@ func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit
 	log.Debug("worker get pending txs from txpool", "len", txsLen, "since", time.Since(start))
 
 	txs := types.NewTransactionsByPriceAndNonce(pending)
-	if err := worker.commitTransactions(work, txs, dpos.BlockInterval()); err != nil {
+	if err := worker.commitTransactions(work, txs, sealer.BlockInterval()); err != nil {
 		return nil, err
 	}
 
 	if atomic.LoadInt32(&worker.mining) == 1 {
+		if err := worker.ApplyBlockTransitions(work.currentState, work.currentHeader); err != nil {
+			return nil, fmt.Errorf("apply block transitions, err: %v", err)
+		}
 		blk, err := worker.Finalize(worker.IConsensus, work.currentHeader, work.currentTxs, work.currentReceipts, work.currentState)
 		if err != nil {
 			return nil, fmt.Errorf("finalize block, err: %v", err)
@@ -361,8 +391,9 @@ func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit
 
 func (worker *Worker) commitTransactions(work *Work, txs *types.TransactionsByPriceAndNonce, interval uint64) error {
 	var coalescedLogs []*types.Log
-	endTimeStamp := work.currentHeader.Time.Uint64() + interval - 2*interval/5
+	endTimeStamp := worker.packDeadline(work.currentHeader.Time.Uint64(), interval)
 	endTime := time.Unix((int64)(endTimeStamp)/(int64)(time.Second), (int64)(endTimeStamp)%(int64)(time.Second))
+	packBlockMeter.Mark(1)
 	for {
 		select {
 		case <-work.quit:
@@ -375,7 +406,10 @@ func (worker *Worker) commitTransactions(work *Work, txs *types.TransactionsByPr
 		}
 
 		if interval != math.MaxUint64 && uint64(time.Now().UnixNano()) >= endTimeStamp {
-			log.Debug("Not enough time for further transactions", "timestamp", work.currentHeader.Time.Int64())
+			log.Debug("Not enough time for further transactions", "timestamp", work.currentHeader.Time.Int64(), "txs", len(work.currentTxs))
+			if txs.Peek() != nil {
+				packCutoffMeter.Mark(1)
+			}
 			break
 		}
 
@@ -399,6 +433,18 @@ func (worker *Worker) commitTransactions(work *Work, txs *types.TransactionsByPr
 		}
 
 		from := action.Sender()
+		if err := tx.CheckFork(work.currentHeader.CurForkID()); err != nil {
+			// The fork of the transaction is not active yet, skip account
+			log.Trace("Skipping transaction of a fork not active", "sender", from, "hash", tx.Hash())
+			txs.Pop()
+			continue
+		}
+		if max := worker.Config().MaxBlockSize(); max != 0 && work.currentSize+uint64(tx.Size()) > max {
+			// The following transactions of the account can not be packed before it, skip account
+			log.Trace("Block size limit exceeded for current block", "sender", from, "size", tx.Size())
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
 		work.currentState.Prepare(tx.Hash(), common.Hash{}, work.currentCnt)
 
@@ -406,6 +452,7 @@ func (worker *Worker) commitTransactions(work *Work, txs *types.TransactionsByPr
 		switch err {
 		case vm.ErrExecOverTime:
 			log.Trace("Skipping transaction exec over time", "hash", tx.Hash())
+			packOverTimeTxMeter.Mark(1)
 			txs.Pop()
 		case common.ErrGasLimitReached:
 			// Pop the current out-of-gas transaction without shifting in the next from the account
@@ -422,6 +469,16 @@ func (worker *Worker) commitTransactions(work *Work, txs *types.TransactionsByPr
 			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", action.Nonce())
 			txs.Pop()
 
+		case types.ErrTxExpired:
+			// The following transactions of the account can not be executed, skip account
+			log.Trace("Skipping expired transaction", "sender", from, "hash", tx.Hash())
+			txs.Pop()
+
+		case processor.ErrGasPriceBelowBaseFee:
+			// The following transactions of the account can not be executed, skip account
+			log.Trace("Skipping account with low gas price", "sender", from, "price", tx.GasPrice())
+			txs.Pop()
+
 		case nil:
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
@@ -457,6 +514,7 @@ func (worker *Worker) commitTransaction(work *Work, tx *types.Transaction, endTi
 	}
 	work.currentTxs = append(work.currentTxs, tx)
 	work.currentReceipts = append(work.currentReceipts, receipt)
+	work.currentSize += uint64(tx.Size())
 	return receipt.Logs, nil
 }
 
@@ -469,6 +527,7 @@ func (worker *Worker) calcGasLimit(parent *types.Block) uint64 {
 
 type Work struct {
 	currentCnt      int
+	currentSize     uint64 // serialized bytes of the block packed so far
 	currentGasPool  *common.GasPool
 	currentHeader   *types.Header
 	currentTxs      []*types.Transaction
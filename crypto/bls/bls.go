@@ -0,0 +1,213 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bls implements the BLS signatures aggregated by the authors of an
+// account on the BLS12-381 curve. The public keys are on G1 and the signatures
+// on G2, the messages are hashed to G2 by the hash_to_curve suite
+// BLS12381G2_XMD:SHA-256_SSWU_RO_ with the domains of the proof of possession
+// scheme. The signatures of the same message aggregate into one signature
+// verified against the sum of the public keys, the keys must be registered
+// with a proof of possession to rule out the rogue key attacks.
+package bls
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+const (
+	// SecretKeyLength of the secret key in bytes
+	SecretKeyLength = 32
+	// PublicKeyLength of the compressed public key in bytes
+	PublicKeyLength = 48
+	// SignatureLength of the compressed signature in bytes
+	SignatureLength = 96
+)
+
+var (
+	// order of the G1 and G2 groups
+	order = bls12381.NewG1().Q()
+
+	// the domain separation tags of the signatures and the proofs of possession
+	signDomain  = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	proofDomain = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+)
+
+var (
+	errInvalidSecretKey = errors.New("invalid bls secret key")
+	errInvalidPublicKey = errors.New("invalid bls public key")
+	errInvalidSignature = errors.New("invalid bls signature")
+)
+
+// SecretKey is a BLS secret key.
+type SecretKey struct {
+	x *big.Int
+}
+
+// GenerateKey generates a secret key reading the randomness from r.
+func GenerateKey(r io.Reader) (*SecretKey, error) {
+	for {
+		b := make([]byte, SecretKeyLength)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		if sk, err := ToSecretKey(b); err == nil {
+			return sk, nil
+		}
+	}
+}
+
+// ToSecretKey returns the secret key of the big endian scalar b.
+func ToSecretKey(b []byte) (*SecretKey, error) {
+	x := new(big.Int).SetBytes(b)
+	if len(b) != SecretKeyLength || x.Sign() == 0 || x.Cmp(order) >= 0 {
+		return nil, errInvalidSecretKey
+	}
+	return &SecretKey{x: x}, nil
+}
+
+// Bytes returns the big endian scalar of the secret key.
+func (sk *SecretKey) Bytes() []byte {
+	b := make([]byte, SecretKeyLength)
+	return sk.x.FillBytes(b)
+}
+
+// PublicKey returns the compressed public key of the secret key.
+func (sk *SecretKey) PublicKey() []byte {
+	g1 := bls12381.NewG1()
+	return g1.ToCompressed(g1.MulScalarBig(g1.New(), g1.One(), sk.x))
+}
+
+// Sign returns the signature of the message.
+func Sign(msg []byte, sk *SecretKey) []byte {
+	return sign(signDomain, msg, sk)
+}
+
+// Prove returns the proof of possession of the secret key, the signature of
+// its public key on the proof domain.
+func Prove(sk *SecretKey) []byte {
+	return sign(proofDomain, sk.PublicKey(), sk)
+}
+
+func sign(domain, msg []byte, sk *SecretKey) []byte {
+	g2 := bls12381.NewG2()
+	h, err := g2.HashToCurve(msg, domain)
+	if err != nil {
+		// the domains are shorter than 256 bytes, the hash never fails
+		panic(err)
+	}
+	return g2.ToCompressed(g2.MulScalarBig(g2.New(), h, sk.x))
+}
+
+// Verify returns whether sig is the signature of the message by the public key.
+func Verify(pub, msg, sig []byte) bool {
+	return VerifyAggregate([][]byte{pub}, msg, sig)
+}
+
+// VerifyProof returns whether proof is the proof of possession of the secret
+// key of the public key.
+func VerifyProof(pub, proof []byte) bool {
+	key, err := decodePublicKey(bls12381.NewG1(), pub)
+	if err != nil {
+		return false
+	}
+	return verify(key, proofDomain, pub, proof)
+}
+
+// Aggregate returns the aggregated signature of the signatures.
+func Aggregate(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errInvalidSignature
+	}
+	g2 := bls12381.NewG2()
+	agg := g2.Zero()
+	for _, sig := range sigs {
+		s, err := decodeSignature(g2, sig)
+		if err != nil {
+			return nil, err
+		}
+		g2.Add(agg, agg, s)
+	}
+	return g2.ToCompressed(agg), nil
+}
+
+// VerifyAggregate returns whether sig is the aggregated signature of the
+// message by all the public keys, a pairing check whatever the number of keys.
+func VerifyAggregate(pubs [][]byte, msg, sig []byte) bool {
+	if len(pubs) == 0 {
+		return false
+	}
+	g1 := bls12381.NewG1()
+	agg := g1.Zero()
+	for _, pub := range pubs {
+		key, err := decodePublicKey(g1, pub)
+		if err != nil {
+			return false
+		}
+		g1.Add(agg, agg, key)
+	}
+	return verify(agg, signDomain, msg, sig)
+}
+
+// verify checks e(key, H(msg)) == e(g1, sig).
+func verify(key *bls12381.PointG1, domain, msg, sig []byte) bool {
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	if g1.IsZero(key) {
+		return false
+	}
+	s, err := decodeSignature(g2, sig)
+	if err != nil {
+		return false
+	}
+	h, err := g2.HashToCurve(msg, domain)
+	if err != nil {
+		return false
+	}
+	engine := bls12381.NewEngine()
+	engine.AddPair(key, h)
+	engine.AddPairInv(g1.One(), s)
+	return engine.Check()
+}
+
+// decodePublicKey decodes the compressed public key, it must be a point of the
+// G1 group other than the infinity.
+func decodePublicKey(g1 *bls12381.G1, pub []byte) (*bls12381.PointG1, error) {
+	if len(pub) != PublicKeyLength {
+		return nil, errInvalidPublicKey
+	}
+	// the decoding checks the point is on the curve and in the group
+	key, err := g1.FromCompressed(pub)
+	if err != nil || g1.IsZero(key) {
+		return nil, errInvalidPublicKey
+	}
+	return key, nil
+}
+
+// decodeSignature decodes the compressed signature, it must be a point of the
+// G2 group other than the infinity.
+func decodeSignature(g2 *bls12381.G2, sig []byte) (*bls12381.PointG2, error) {
+	if len(sig) != SignatureLength {
+		return nil, errInvalidSignature
+	}
+	s, err := g2.FromCompressed(sig)
+	if err != nil || g2.IsZero(s) {
+		return nil, errInvalidSignature
+	}
+	return s, nil
+}
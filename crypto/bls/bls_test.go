@@ -0,0 +1,145 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package bls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+func generateKeys(t *testing.T, n int) []*SecretKey {
+	keys := make([]*SecretKey, n)
+	for i := range keys {
+		sk, err := GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = sk
+	}
+	return keys
+}
+
+func TestSignVerify(t *testing.T) {
+	keys := generateKeys(t, 2)
+	msg := []byte("fractal")
+	sig := Sign(msg, keys[0])
+	if len(sig) != SignatureLength || len(keys[0].PublicKey()) != PublicKeyLength {
+		t.Fatal("encoding length mismatch")
+	}
+	if !Verify(keys[0].PublicKey(), msg, sig) {
+		t.Fatal("valid signature rejected")
+	}
+	if Verify(keys[1].PublicKey(), msg, sig) {
+		t.Fatal("signature verified by another key")
+	}
+	if Verify(keys[0].PublicKey(), []byte("other"), sig) {
+		t.Fatal("signature verified for another message")
+	}
+
+	for i := 0; i < 16; i++ {
+		if msg := []byte{byte(i)}; !Verify(keys[1].PublicKey(), msg, Sign(msg, keys[1])) {
+			t.Fatalf("valid signature of message %d rejected", i)
+		}
+	}
+
+	restored, err := ToSecretKey(keys[0].Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored.PublicKey(), keys[0].PublicKey()) {
+		t.Fatal("restored key mismatch")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	keys := generateKeys(t, 4)
+	msg := []byte("fractal")
+	var sigs, pubs [][]byte
+	for _, sk := range keys {
+		sigs = append(sigs, Sign(msg, sk))
+		pubs = append(pubs, sk.PublicKey())
+	}
+	agg, err := Aggregate(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyAggregate(pubs, msg, agg) {
+		t.Fatal("valid aggregated signature rejected")
+	}
+	if VerifyAggregate(pubs[:3], msg, agg) {
+		t.Fatal("aggregated signature verified by a subset of the keys")
+	}
+	partial, _ := Aggregate(sigs[:3])
+	if VerifyAggregate(pubs, msg, partial) {
+		t.Fatal("partial aggregated signature verified by all the keys")
+	}
+}
+
+func TestProof(t *testing.T) {
+	keys := generateKeys(t, 2)
+	proof := Prove(keys[0])
+	if !VerifyProof(keys[0].PublicKey(), proof) {
+		t.Fatal("valid proof rejected")
+	}
+	if VerifyProof(keys[1].PublicKey(), proof) {
+		t.Fatal("proof verified for another key")
+	}
+	// a signature of the public key is not a proof
+	if VerifyProof(keys[0].PublicKey(), Sign(keys[0].PublicKey(), keys[0])) {
+		t.Fatal("signature accepted as a proof")
+	}
+}
+
+func TestInvalidEncoding(t *testing.T) {
+	sk := generateKeys(t, 1)[0]
+	msg := []byte("fractal")
+	sig := Sign(msg, sk)
+	if Verify(make([]byte, PublicKeyLength), msg, sig) {
+		t.Fatal("infinity public key accepted")
+	}
+	if Verify(sk.PublicKey(), msg, make([]byte, SignatureLength)) {
+		t.Fatal("infinity signature accepted")
+	}
+	bad := append([]byte{}, sk.PublicKey()...)
+	bad[len(bad)-1] ^= 1
+	if Verify(bad, msg, sig) {
+		t.Fatal("malformed public key accepted")
+	}
+	if _, err := ToSecretKey(make([]byte, SecretKeyLength)); err == nil {
+		t.Fatal("zero secret key accepted")
+	}
+}
+
+func TestHashToCurveVector(t *testing.T) {
+	// RFC 9380 J.10.1, BLS12381G2_XMD:SHA-256_SSWU_RO_ of the empty message
+	g2 := bls12381.NewG2()
+	p, err := g2.HashToCurve([]byte(""), []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "05cb8437535e20ecffaef7752baddf98034139c38452458baeefab379ba13dff5bf5dd71b72418717047f5b0f37da03d" +
+		"0141ebfbdca40eb85b87142e130ab689c673cf60f1a3e98d69335266f30d9b8d4ac44c1038e9dcdd5393faf5c41fb78a" +
+		"12424ac32561493f3fe3c260708a12b7c620e7be00099a974e259ddc7d1f6395c3c811cdd19f1e8dbf3e9ecfdcbab8d6" +
+		"0503921d7f6a12805e72940b963c0cf3471c7b2a524950ca195d11062ee75ec076daf2d4bc358c4b190c0c98064fdd92"
+	if got := hex.EncodeToString(g2.ToUncompressed(p)); got != want {
+		t.Fatalf("hash to curve = %s, want %s", got, want)
+	}
+}
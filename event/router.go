@@ -90,6 +90,8 @@ const (
 	P2PBlockHashMsg                  // 10 BlockHash response
 	P2PNewBlockHashesMsg             // 11 NewBlockHash notify
 	P2PTxMsg                         // 12 TxMsg notify
+	P2PGetNodeDataMsg                // 13 NodeData request
+	P2PNodeDataMsg                   // 14 NodeData response
 	P2PEndSize
 	ChainHeadEv         = 1023 + iota - P2PEndSize // 1024
 	NewPeerNotify                                  // 1025 emit when remote peer incoming but needed to check chainID and genesis block
@@ -111,6 +113,7 @@ var typeLimit = [P2PEndSize]int{
 	P2PGetBlockHeadersMsg: 64,
 	P2PGetBlockBodiesMsg:  64,
 	P2PNewBlockHashesMsg:  3,
+	P2PGetNodeDataMsg:     64,
 }
 
 // ReplyEvent is equivalent to `SendTo(e.To, e.From, typecode, data)`
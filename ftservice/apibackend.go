@@ -18,6 +18,7 @@ package ftservice
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -116,14 +117,19 @@ func (b *APIBackend) GetBlockDetailLog(ctx context.Context, blockNr rpc.BlockNum
 	}
 }
 
-func (b *APIBackend) GetTxsByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookforwardNum uint64) *types.AccountTxs {
+func (b *APIBackend) GetTxsByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookforwardNum uint64, cursor *types.TxPosition, limit uint64) *types.AccountTxs {
 	if lookforwardNum > 128 {
 		lookforwardNum = 128
 	}
 
 	lastnum := int64(blockNr + lookforwardNum)
 	txhhpairs := make([]*types.TxHeightHashPair, 0)
-	for ublocknum := int64(blockNr); ublocknum <= lastnum; ublocknum++ {
+	var next *types.TxPosition
+	ublocknum := int64(blockNr)
+	if cursor != nil && cursor.Height > blockNr {
+		ublocknum = int64(cursor.Height)
+	}
+	for ; ublocknum <= lastnum && next == nil; ublocknum++ {
 		hash := rawdb.ReadCanonicalHash(b.ftservice.chainDb, uint64(ublocknum))
 		if hash == (common.Hash{}) {
 			continue
@@ -135,9 +141,16 @@ func (b *APIBackend) GetTxsByFilter(ctx context.Context, filterFn func(common.Na
 		}
 		batchTxs := blockBody.Transactions
 
-		for _, tx := range batchTxs {
+		for i, tx := range batchTxs {
+			if cursor != nil && cursor.Height == uint64(ublocknum) && uint64(i) < cursor.Index {
+				continue
+			}
 			for _, act := range tx.GetActions() {
 				if filterFn(act.Sender()) || filterFn(act.Recipient()) {
+					if uint64(len(txhhpairs)) >= limit {
+						next = &types.TxPosition{Height: uint64(ublocknum), Index: uint64(i)}
+						break
+					}
 					hhpair := &types.TxHeightHashPair{
 						Hash:   tx.Hash(),
 						Height: uint64(ublocknum),
@@ -146,6 +159,9 @@ func (b *APIBackend) GetTxsByFilter(ctx context.Context, filterFn func(common.Na
 					break
 				}
 			}
+			if next != nil {
+				break
+			}
 		}
 	}
 
@@ -153,6 +169,7 @@ func (b *APIBackend) GetTxsByFilter(ctx context.Context, filterFn func(common.Na
 		Txs:                     txhhpairs,
 		IrreversibleBlockHeight: b.ftservice.engine.CalcBFTIrreversible(),
 		EndHeight:               uint64(lastnum),
+		Next:                    next,
 	}
 
 	return accountTxs
@@ -201,6 +218,22 @@ func (b *APIBackend) GetBadBlocks(ctx context.Context) ([]*types.Block, error) {
 	return b.ftservice.blockchain.BadBlocks(), nil
 }
 
+func (b *APIBackend) GetBlockTree(ctx context.Context, count uint64) *types.BlockTree {
+	return b.ftservice.blockchain.BlockTree(count)
+}
+
+func (b *APIBackend) VerifyState(ctx context.Context, blockNr rpc.BlockNumber, repair bool) (*types.StateReport, error) {
+	number := uint64(blockNr)
+	if blockNr == rpc.LatestBlockNumber {
+		number = b.ftservice.blockchain.CurrentBlock().NumberU64()
+	}
+	return b.ftservice.blockchain.VerifyState(number, repair)
+}
+
+func (b *APIBackend) GetExecStats(ctx context.Context, hash common.Hash) *types.ExecStats {
+	return b.ftservice.blockchain.Processor().GetExecStats(hash)
+}
+
 func (b *APIBackend) GetTd(blockHash common.Hash) *big.Int {
 	return b.ftservice.blockchain.GetTdByHash(blockHash)
 }
@@ -258,6 +291,19 @@ func (b *APIBackend) GetAccountManager() (*accountmanager.AccountManager, error)
 	return accountmanager.NewAccountManager(sdb)
 }
 
+//GetAccountManagerByNumber get a read only account manager on the state of the block
+func (b *APIBackend) GetAccountManagerByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*accountmanager.AccountManager, error) {
+	header := b.HeaderByNumber(ctx, blockNr)
+	if header == nil {
+		return nil, fmt.Errorf("block %d not exist", blockNr)
+	}
+	sdb, err := b.ftservice.blockchain.State()
+	if err != nil {
+		return nil, err
+	}
+	return accountmanager.NewReadOnlyAccountManager(sdb, header.Root)
+}
+
 //GetFeeManager get fee manager
 func (b *APIBackend) GetFeeManager() (*feemanager.FeeManager, error) {
 	sdb, err := b.ftservice.blockchain.State()
@@ -389,6 +435,11 @@ func (b *APIBackend) Engine() consensus.IEngine {
 	return b.ftservice.engine
 }
 
+// StateAt returns the state of the root.
+func (b *APIBackend) StateAt(root common.Hash) (*state.StateDB, error) {
+	return b.ftservice.blockchain.StateAt(root)
+}
+
 //SetStatePruning set state pruning
 func (b *APIBackend) SetStatePruning(enable bool) (bool, uint64) {
 	return b.ftservice.blockchain.StatePruning(enable)
@@ -396,5 +447,21 @@ func (b *APIBackend) SetStatePruning(enable bool) (bool, uint64) {
 
 // APIs returns apis
 func (b *APIBackend) APIs() []rpc.API {
-	return b.ftservice.miner.APIs(b.ftservice.blockchain)
+	apis := b.ftservice.miner.APIs(b.ftservice.blockchain)
+	if b.ftservice.loadGen != nil {
+		apis = append(apis, b.ftservice.loadGen.APIs()...)
+	}
+	if b.ftservice.sender != nil {
+		apis = append(apis, b.ftservice.sender.APIs()...)
+	}
+	if b.ftservice.gasStats != nil {
+		apis = append(apis, b.ftservice.gasStats.APIs()...)
+	}
+	if b.ftservice.search != nil {
+		apis = append(apis, b.ftservice.search.APIs()...)
+	}
+	if b.ftservice.auditor != nil {
+		apis = append(apis, b.ftservice.auditor.APIs()...)
+	}
+	return apis
 }
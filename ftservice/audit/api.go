@@ -0,0 +1,62 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"time"
+
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+// API exposes the auditor for the RPC interface.
+type API struct {
+	a *Auditor
+}
+
+// Start audits the blocks from to to in the background, to 0 audits up to the
+// current block, interval is the pause in milliseconds between the blocks.
+func (api *API) Start(from, to uint64, interval *uint64) (bool, error) {
+	var d time.Duration
+	if interval != nil {
+		d = time.Duration(*interval) * time.Millisecond
+	}
+	if err := api.a.Start(from, to, d); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Stop stops the running audit.
+func (api *API) Stop() bool {
+	api.a.Stop()
+	return true
+}
+
+// Status returns the progress of the last audit.
+func (api *API) Status() *Status {
+	return api.a.Status()
+}
+
+func (a *Auditor) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "audit",
+			Version:   "1.0",
+			Service:   &API{a: a},
+		},
+	}
+}
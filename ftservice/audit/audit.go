@@ -0,0 +1,183 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package audit implements the background re-execution of a range of stored
+// blocks, comparing the results with the stored state roots and receipts to
+// detect the silent corruption of the database of long-running nodes.
+package audit
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/blockchain"
+	"github.com/fractalplatform/fractal/metrics"
+	"github.com/fractalplatform/fractal/types"
+)
+
+const (
+	// DefaultInterval is the pause between the audited blocks, keeping the audit at low priority.
+	DefaultInterval = 50 * time.Millisecond
+	// maxDivergences is the max divergences kept in the status.
+	maxDivergences = 100
+)
+
+var (
+	ErrAuditRunning = errors.New("audit is running")
+	ErrRangeInvalid = errors.New("audit range invalid")
+
+	divergenceCounter = metrics.NewRegisteredCounter("audit/divergence", nil)
+	auditedCounter    = metrics.NewRegisteredCounter("audit/blocks", nil)
+)
+
+// Backend is the node services the audit uses.
+type Backend interface {
+	CurrentBlock() *types.Block
+	AuditBlock(number uint64) error
+}
+
+// Divergence is a block whose re-execution does not match the stored results.
+type Divergence struct {
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
+	Reason string `json:"reason"`
+}
+
+// Status is the progress of the audit.
+type Status struct {
+	Running     bool          `json:"running"`
+	From        uint64        `json:"from"`
+	To          uint64        `json:"to"`
+	Next        uint64        `json:"next"`
+	Audited     uint64        `json:"audited"`
+	Skipped     uint64        `json:"skipped"` // blocks without the state of the parent, pruned
+	Divergences []*Divergence `json:"divergences"`
+}
+
+// Auditor re-executes a range of blocks in the background.
+type Auditor struct {
+	backend Backend
+
+	mu     sync.RWMutex
+	status Status
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New creates an idle auditor.
+func New(backend Backend) *Auditor {
+	return &Auditor{backend: backend, status: Status{Divergences: []*Divergence{}}}
+}
+
+// Start audits the blocks from to to in the background, to 0 audits up to the
+// current block, the interval is the pause between the blocks.
+func (a *Auditor) Start(from, to uint64, interval time.Duration) error {
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 {
+		to = a.backend.CurrentBlock().NumberU64()
+	}
+	if from > to || to > a.backend.CurrentBlock().NumberU64() {
+		return ErrRangeInvalid
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.status.Running {
+		return ErrAuditRunning
+	}
+	a.status = Status{Running: true, From: from, To: to, Next: from, Divergences: []*Divergence{}}
+	a.quit = make(chan struct{})
+	a.wg.Add(1)
+	go a.loop(from, to, interval, a.quit)
+	log.Info("Audit started", "from", from, "to", to, "interval", interval)
+	return nil
+}
+
+// Stop stops the running audit.
+func (a *Auditor) Stop() {
+	a.mu.Lock()
+	if a.quit != nil {
+		close(a.quit)
+		a.quit = nil
+	}
+	a.mu.Unlock()
+	a.wg.Wait()
+}
+
+// Status returns the progress of the last audit.
+func (a *Auditor) Status() *Status {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	status := a.status
+	status.Divergences = append([]*Divergence{}, a.status.Divergences...)
+	return &status
+}
+
+func (a *Auditor) loop(from, to uint64, interval time.Duration, quit chan struct{}) {
+	defer a.wg.Done()
+	defer func() {
+		a.mu.Lock()
+		a.status.Running = false
+		a.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for number := from; number <= to; number++ {
+		select {
+		case <-quit:
+			log.Info("Audit stopped", "next", number)
+			return
+		case <-timer.C:
+		}
+		a.audit(number)
+		timer.Reset(interval)
+	}
+	status := a.Status()
+	log.Info("Audit finished", "from", from, "to", to, "audited", status.Audited, "skipped", status.Skipped, "divergences", len(status.Divergences))
+}
+
+// audit re-executes the block and records the result.
+func (a *Auditor) audit(number uint64) {
+	err := a.backend.AuditBlock(number)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.status.Next = number + 1
+	switch e := err.(type) {
+	case nil:
+		a.status.Audited++
+		auditedCounter.Inc(1)
+	case *blockchain.DivergenceError:
+		a.status.Audited++
+		auditedCounter.Inc(1)
+		divergenceCounter.Inc(1)
+		log.Error("Audit found divergent block, the database may be corrupted", "number", e.Number, "hash", e.Hash, "reason", e.Reason)
+		if len(a.status.Divergences) < maxDivergences {
+			a.status.Divergences = append(a.status.Divergences, &Divergence{Number: e.Number, Hash: e.Hash.Hex(), Reason: e.Reason})
+		}
+	default:
+		a.status.Skipped++
+		log.Debug("Audit skip block", "number", number, "err", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fractalplatform/fractal/blockchain"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+type testBackend struct {
+	head    uint64
+	diverge map[uint64]bool
+	pruned  map[uint64]bool
+}
+
+func (b *testBackend) CurrentBlock() *types.Block {
+	return types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(b.head)})
+}
+
+func (b *testBackend) AuditBlock(number uint64) error {
+	if b.pruned[number] {
+		return errors.New("missing trie node")
+	}
+	if b.diverge[number] {
+		return &blockchain.DivergenceError{Number: number, Hash: common.BytesToHash([]byte{byte(number)}), Reason: "state root mismatch"}
+	}
+	return nil
+}
+
+func waitAudit(t *testing.T, a *Auditor) *Status {
+	for i := 0; i < 200; i++ {
+		if status := a.Status(); !status.Running {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("audit not finished")
+	return nil
+}
+
+func TestAuditor(t *testing.T) {
+	backend := &testBackend{
+		head:    20,
+		diverge: map[uint64]bool{7: true},
+		pruned:  map[uint64]bool{3: true, 4: true},
+	}
+	a := New(backend)
+
+	if err := a.Start(10, 30, time.Millisecond); err != ErrRangeInvalid {
+		t.Fatalf("range beyond the head, got %v", err)
+	}
+	if err := a.Start(0, 0, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	status := waitAudit(t, a)
+	if status.From != 1 || status.To != 20 || status.Next != 21 {
+		t.Fatalf("range mismatch %+v", status)
+	}
+	if status.Audited != 18 || status.Skipped != 2 {
+		t.Fatalf("audited %v skipped %v", status.Audited, status.Skipped)
+	}
+	if len(status.Divergences) != 1 || status.Divergences[0].Number != 7 {
+		t.Fatalf("divergences mismatch %v", status.Divergences)
+	}
+
+	if err := a.Start(1, 20, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Start(1, 20, time.Hour); err != ErrAuditRunning {
+		t.Fatalf("audit running, got %v", err)
+	}
+	a.Stop()
+	if status := a.Status(); status.Running || status.Audited > 1 {
+		t.Fatalf("stopped status mismatch %+v", status)
+	}
+}
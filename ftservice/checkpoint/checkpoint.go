@@ -0,0 +1,115 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package checkpoint implements an exporter which publishes a signed checkpoint of
+// the state at every epoch change to external storage, so that new nodes and
+// auditors can fetch trusted snapshots out of band.
+package checkpoint
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	errCheckpointConfig    = errors.New("checkpoint config invalid")
+	errCheckpointSignature = errors.New("checkpoint signature invalid")
+	errCheckpointSigner    = errors.New("checkpoint signed by unexpected key")
+)
+
+// Config is the external storage checkpoints are published to.
+type Config struct {
+	Target     string `mapstructure:"target"`     // "s3" or "ipfs", empty disables the exporter
+	Endpoint   string `mapstructure:"endpoint"`   // s3 endpoint or ipfs api url
+	Bucket     string `mapstructure:"bucket"`     // s3 bucket
+	Region     string `mapstructure:"region"`     // s3 region
+	AccessKey  string `mapstructure:"accesskey"`  // s3 access key id
+	SecretKey  string `mapstructure:"secretkey"`  // s3 secret access key
+	PrivateKey string `mapstructure:"privatekey"` // hex key signing the checkpoints
+	Archive    bool   `mapstructure:"archive"`    // publish the accounts archive with the checkpoint
+}
+
+// Checkpoint is the state of the chain at the first block of an epoch.
+type Checkpoint struct {
+	ChainID     *big.Int      `json:"chainID"`
+	ChainName   string        `json:"chainName"`
+	Epoch       uint64        `json:"epoch"`
+	Number      uint64        `json:"number"`
+	Hash        common.Hash   `json:"hash"`
+	Root        common.Hash   `json:"root"`
+	Time        uint64        `json:"time"`
+	Accounts    uint64        `json:"accounts"`
+	Archive     string        `json:"archive,omitempty"` // location of the gzip accounts archive
+	ArchiveHash common.Hash   `json:"archiveHash"`       // keccak256 of the archive
+	Signature   hexutil.Bytes `json:"signature"`
+}
+
+// SigHash returns the hash signed by the checkpoint signature.
+func (c *Checkpoint) SigHash() common.Hash {
+	b, _ := rlp.EncodeToBytes([]interface{}{
+		c.ChainID,
+		c.ChainName,
+		c.Epoch,
+		c.Number,
+		c.Hash,
+		c.Root,
+		c.Time,
+		c.Accounts,
+		c.Archive,
+		c.ArchiveHash,
+	})
+	return crypto.Keccak256Hash(b)
+}
+
+// Sign signs the checkpoint with the key.
+func (c *Checkpoint) Sign(prv *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(c.SigHash().Bytes(), prv)
+	if err != nil {
+		return err
+	}
+	c.Signature = sig
+	return nil
+}
+
+// Signer returns the public key which signed the checkpoint.
+func (c *Checkpoint) Signer() (common.PubKey, error) {
+	if len(c.Signature) != 65 {
+		return common.PubKey{}, errCheckpointSignature
+	}
+	pub, err := crypto.SigToPub(c.SigHash().Bytes(), c.Signature)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	return common.BytesToPubKey(crypto.FromECDSAPub(pub)), nil
+}
+
+// Verify checks the checkpoint is signed by the public key.
+func (c *Checkpoint) Verify(pubKey common.PubKey) error {
+	signer, err := c.Signer()
+	if err != nil {
+		return err
+	}
+	if signer != pubKey {
+		return errCheckpointSigner
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+)
+
+func TestCheckpointSignVerify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	pubKey := common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))
+
+	cp := &Checkpoint{ChainID: big.NewInt(1), ChainName: "fractal", Epoch: 3, Number: 300, Root: common.HexToHash("0x01")}
+	if err := cp.Verify(pubKey); err != errCheckpointSignature {
+		t.Fatalf("unsigned checkpoint err %v", err)
+	}
+	if err := cp.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Verify(pubKey); err != nil {
+		t.Fatalf("verify err %v", err)
+	}
+	if err := cp.Verify(common.BytesToPubKey(crypto.FromECDSAPub(&other.PublicKey))); err != errCheckpointSigner {
+		t.Fatalf("other key err %v", err)
+	}
+	cp.Root = common.HexToHash("0x02")
+	if err := cp.Verify(pubKey); err != errCheckpointSigner {
+		t.Fatalf("tampered checkpoint err %v", err)
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	for _, cfg := range []*Config{
+		{Target: "ftp", Endpoint: "http://localhost"},
+		{Target: "ipfs"},
+		{Target: "s3", Endpoint: "http://localhost", Region: "us-east-1"},
+	} {
+		if _, err := NewPublisher(cfg); err != errCheckpointConfig {
+			t.Fatalf("config %v err %v", cfg, err)
+		}
+	}
+}
+
+func TestIPFSPublisher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/add" {
+			http.NotFound(w, r)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil || header.Filename != "cp.json" {
+			http.Error(w, "bad file", http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(file)
+		if string(data) != "checkpoint" {
+			http.Error(w, "bad data", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"Name":"cp.json","Hash":"QmTest","Size":"10"}`))
+	}))
+	defer server.Close()
+
+	p, err := NewPublisher(&Config{Target: "ipfs", Endpoint: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	location, err := p.Publish("cp.json", []byte("checkpoint"))
+	if err != nil || location != "ipfs://QmTest" {
+		t.Fatalf("publish = %v, %v", location, err)
+	}
+}
+
+func TestS3Publisher(t *testing.T) {
+	var auth, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		auth, path, body = r.Header.Get("Authorization"), r.URL.Path, string(data)
+		if r.Method != http.MethodPut || r.Header.Get("x-amz-date") != "20190101T000000Z" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewPublisher(&Config{Target: "s3", Endpoint: server.URL, Bucket: "snapshots", Region: "us-east-1", AccessKey: "AKID", SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.(*s3Publisher).now = func() time.Time { return time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC) }
+	location, err := p.Publish("cp.json", []byte("checkpoint"))
+	if err != nil || location != server.URL+"/snapshots/cp.json" {
+		t.Fatalf("publish = %v, %v", location, err)
+	}
+	if path != "/snapshots/cp.json" || body != "checkpoint" {
+		t.Fatalf("put %v %v", path, body)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20190101/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Fatalf("authorization %v", auth)
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	})
+	if _, err := p.Publish("cp.json", []byte("checkpoint")); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Fatalf("denied publish err %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/consensus"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/event"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// chainHeadChanSize is the size of channel listening to the chain head events.
+const chainHeadChanSize = 10
+
+// Backend is the node services the exporter uses.
+type Backend interface {
+	ChainConfig() *params.ChainConfig
+	Engine() consensus.IEngine
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// Exporter publishes a signed checkpoint at the first block of every epoch.
+type Exporter struct {
+	backend   Backend
+	publisher Publisher
+	key       *ecdsa.PrivateKey
+	archive   bool
+
+	chainHeadCh  chan *event.Event
+	chainHeadSub event.Subscription
+	pending      chan *types.Header
+	wg           sync.WaitGroup
+}
+
+// New creates the exporter of the config, the exporter does nothing until started.
+func New(backend Backend, cfg *Config) (*Exporter, error) {
+	publisher, err := NewPublisher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.HexToECDSA(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint private key invalid: %v", err)
+	}
+	return &Exporter{
+		backend:   backend,
+		publisher: publisher,
+		key:       key,
+		archive:   cfg.Archive,
+	}, nil
+}
+
+// Start publishes the checkpoints of the epochs starting after the head block.
+func (e *Exporter) Start(head *types.Header) {
+	e.chainHeadCh = make(chan *event.Event, chainHeadChanSize)
+	e.pending = make(chan *types.Header, 1)
+	e.chainHeadSub = event.Subscribe(nil, e.chainHeadCh, event.ChainHeadEv, &types.Block{})
+
+	epoch, _ := e.epochAt(head)
+	e.wg.Add(2)
+	go e.loop(epoch)
+	go e.publishLoop()
+}
+
+// Stop stops the exporter, it waits the checkpoint being published.
+func (e *Exporter) Stop() {
+	e.chainHeadSub.Unsubscribe()
+	e.wg.Wait()
+}
+
+func (e *Exporter) epochAt(header *types.Header) (uint64, error) {
+	statedb, err := e.backend.StateAt(header.Root)
+	if err != nil {
+		return 0, err
+	}
+	epoch, _, err := e.backend.Engine().GetEpoch(statedb, 0, 0)
+	return epoch, err
+}
+
+// loop watches the chain head and hands the first block of a new epoch to the publish loop.
+func (e *Exporter) loop(epoch uint64) {
+	defer e.wg.Done()
+	defer close(e.pending)
+	for {
+		select {
+		case ev := <-e.chainHeadCh:
+			block := ev.Data.(*types.Block)
+			if block == nil {
+				continue
+			}
+			current, err := e.epochAt(block.Header())
+			if err != nil {
+				log.Warn("Checkpoint failed to get epoch", "number", block.NumberU64(), "err", err)
+				continue
+			}
+			if current <= epoch {
+				continue
+			}
+			epoch = current
+			select {
+			case e.pending <- block.Header():
+			default:
+				log.Warn("Checkpoint skipped, previous one still publishing", "epoch", epoch, "number", block.NumberU64())
+			}
+			// Be unsubscribed due to system stopped
+		case <-e.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
+func (e *Exporter) publishLoop() {
+	defer e.wg.Done()
+	for header := range e.pending {
+		cp, err := e.publish(header)
+		if err != nil {
+			log.Error("Failed to publish checkpoint", "number", header.Number, "err", err)
+			continue
+		}
+		log.Info("Published checkpoint", "epoch", cp.Epoch, "number", cp.Number, "root", cp.Root, "archive", cp.Archive)
+	}
+}
+
+// publish uploads the archive of the accounts at the header, then the signed checkpoint.
+func (e *Exporter) publish(header *types.Header) (*Checkpoint, error) {
+	statedb, err := e.backend.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	epoch, _, err := e.backend.Engine().GetEpoch(statedb, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	config := e.backend.ChainConfig()
+	cp := &Checkpoint{
+		ChainID:   config.ChainID,
+		ChainName: config.ChainName,
+		Epoch:     epoch,
+		Number:    header.Number.Uint64(),
+		Hash:      header.Hash(),
+		Root:      header.Root,
+		Time:      header.Time.Uint64(),
+	}
+	name := fmt.Sprintf("%s-checkpoint-%d", config.ChainName, epoch)
+
+	if e.archive {
+		data, accounts, err := exportArchive(statedb, header.Root)
+		if err != nil {
+			return nil, err
+		}
+		location, err := e.publisher.Publish(name+".accounts.json.gz", data)
+		if err != nil {
+			return nil, err
+		}
+		cp.Accounts = accounts
+		cp.Archive = location
+		cp.ArchiveHash = crypto.Keccak256Hash(data)
+	}
+
+	if err := cp.Sign(e.key); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := e.publisher.Publish(name+".json", data); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// exportArchive returns the gzip of the accounts exported at the state root and the number of accounts.
+func exportArchive(statedb *state.StateDB, root common.Hash) ([]byte, uint64, error) {
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return nil, 0, err
+	}
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	accounts, err := am.ExportAccounts(zw, root)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), accounts, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// publishTimeout is the timeout of an upload to the external storage.
+const publishTimeout = 5 * time.Minute
+
+// Publisher uploads a named object to the external storage and returns its location.
+type Publisher interface {
+	Publish(name string, data []byte) (string, error)
+}
+
+// NewPublisher creates the publisher of the config target.
+func NewPublisher(cfg *Config) (Publisher, error) {
+	client := &http.Client{Timeout: publishTimeout}
+	switch cfg.Target {
+	case "ipfs":
+		if cfg.Endpoint == "" {
+			return nil, errCheckpointConfig
+		}
+		return &ipfsPublisher{api: strings.TrimRight(cfg.Endpoint, "/"), client: client}, nil
+	case "s3":
+		if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.Region == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+			return nil, errCheckpointConfig
+		}
+		return &s3Publisher{
+			endpoint:  strings.TrimRight(cfg.Endpoint, "/"),
+			bucket:    cfg.Bucket,
+			region:    cfg.Region,
+			accessKey: cfg.AccessKey,
+			secretKey: cfg.SecretKey,
+			client:    client,
+			now:       time.Now,
+		}, nil
+	}
+	return nil, errCheckpointConfig
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("upload failed: %s %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// ipfsPublisher adds the objects by the http api of an ipfs node.
+type ipfsPublisher struct {
+	api    string
+	client *http.Client
+}
+
+func (p *ipfsPublisher) Publish(name string, data []byte) (string, error) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	resp, err := p.client.Post(p.api+"/api/v0/add?pin=true", mw.FormDataContentType(), body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+	var result struct {
+		Hash string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.Hash == "" {
+		return "", fmt.Errorf("upload failed: no ipfs hash for %s", name)
+	}
+	return "ipfs://" + result.Hash, nil
+}
+
+// s3Publisher puts the objects to a s3 compatible bucket, signed by aws signature version 4.
+type s3Publisher struct {
+	endpoint  string
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func (p *s3Publisher) Publish(name string, data []byte) (string, error) {
+	u, err := url.Parse(p.endpoint + "/" + p.bucket + "/" + name)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	p.sign(req, data)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// sign adds the aws signature version 4 authorization to the request.
+func (p *s3Publisher) sign(req *http.Request, data []byte) {
+	now := p.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(data)
+	payload := hex.EncodeToString(payloadHash[:])
+
+	req.Header.Set("x-amz-content-sha256", payload)
+	req.Header.Set("x-amz-date", amzDate)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payload,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payload,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + p.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+p.secretKey), date)
+	key = hmacSHA256(key, p.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.accessKey, scope, signedHeaders, signature))
+}
@@ -18,7 +18,9 @@ package ftservice
 
 import (
 	"github.com/fractalplatform/fractal/blockchain"
+	"github.com/fractalplatform/fractal/ftservice/checkpoint"
 	"github.com/fractalplatform/fractal/ftservice/gasprice"
+	"github.com/fractalplatform/fractal/ftservice/stream"
 	"github.com/fractalplatform/fractal/metrics"
 	"github.com/fractalplatform/fractal/txpool"
 )
@@ -32,6 +34,11 @@ type Config struct {
 	// Database options
 	DatabaseHandles int
 	DatabaseCache   int `mapstructure:"databasecache"`
+	// Database tier paths, relative to the instance directory unless absolute,
+	// empty keeps the tier in the chain database.
+	StateDir   string `mapstructure:"statedir"`
+	AncientDir string `mapstructure:"ancientdir"`
+	IndexDir   string `mapstructure:"indexdir"`
 
 	// Transaction pool options
 	TxPool *txpool.Config `mapstructure:"txpool"`
@@ -44,8 +51,28 @@ type Config struct {
 
 	MetricsConf *metrics.Config `mapstructure:"metrics"`
 
+	// epoch checkpoint exporter
+	Checkpoint *checkpoint.Config `mapstructure:"checkpoint"`
+
+	// block events streaming
+	Stream *stream.Config `mapstructure:"stream"`
+
 	StatePruning    bool `mapstructure:"statepruning"`
 	ContractLogFlag bool `mapstructure:"contractlog"`
+	LoadGen         bool `mapstructure:"loadgen"`       // devnet only, enable the admin load generator
+	ManagedSender   bool `mapstructure:"managedsender"` // enable the sender assigning the nonces of the local accounts
+	GasStats        bool `mapstructure:"gasstats"`      // enable the gas usage statistics of the recent blocks
+	Search          bool `mapstructure:"search"`        // enable the full-text search index of the accounts and assets
+	Audit           bool `mapstructure:"audit"`         // enable the re-execution of the historical blocks on demand
+
+	// state garbage collection, the recent block states kept with the snapshot
+	// states, 0 disables it, and the blocks between two collections
+	StateGC         uint64 `mapstructure:"stategc"`
+	StateGCInterval uint64 `mapstructure:"stategcinterval"`
+
+	// decoded accounts and asset objects shared between the account managers,
+	// 0 disables the cache
+	ObjectCache int `mapstructure:"objectcache"`
 
 	BadHashes   []string `mapstructure:"badhashes"`
 	StartNumber uint64   `mapstructure:"startnumber"`
@@ -58,4 +85,5 @@ type MinerConfig struct {
 	Name        string   `mapstructure:"name"`
 	PrivateKeys []string `mapstructure:"private"`
 	ExtraData   string   `mapstructure:"extra"`
+	Margin      uint64   `mapstructure:"margin"` // ms left for propagation after packing, 0 uses 2/5 of the interval
 }
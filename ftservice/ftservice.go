@@ -17,20 +17,31 @@
 package ftservice
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
 	"github.com/fractalplatform/fractal/blockchain"
 	"github.com/fractalplatform/fractal/consensus"
-	"github.com/fractalplatform/fractal/consensus/dpos"
+	_ "github.com/fractalplatform/fractal/consensus/dpos" // register the dpos engine
 	"github.com/fractalplatform/fractal/consensus/miner"
+	"github.com/fractalplatform/fractal/ftservice/audit"
+	"github.com/fractalplatform/fractal/ftservice/checkpoint"
 	"github.com/fractalplatform/fractal/ftservice/gasprice"
+	"github.com/fractalplatform/fractal/ftservice/gasstats"
+	"github.com/fractalplatform/fractal/ftservice/loadgen"
+	"github.com/fractalplatform/fractal/ftservice/search"
+	"github.com/fractalplatform/fractal/ftservice/sender"
+	"github.com/fractalplatform/fractal/ftservice/stream"
 	"github.com/fractalplatform/fractal/node"
 	"github.com/fractalplatform/fractal/p2p"
 	adaptor "github.com/fractalplatform/fractal/p2p/protoadaptor"
 	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/processor"
 	"github.com/fractalplatform/fractal/processor/vm"
+	"github.com/fractalplatform/fractal/rawdb"
 	"github.com/fractalplatform/fractal/rpc"
 	"github.com/fractalplatform/fractal/rpcapi"
 	"github.com/fractalplatform/fractal/txpool"
@@ -47,6 +58,13 @@ type FtService struct {
 	chainDb      fdb.Database // Block chain database
 	engine       consensus.IEngine
 	miner        *miner.Miner
+	loadGen      *loadgen.LoadGen
+	sender       *sender.Sender
+	gasStats     *gasstats.Stats
+	search       *search.Index
+	auditor      *audit.Auditor
+	checkpoint   *checkpoint.Exporter
+	streamer     *stream.Streamer
 	p2pServer    *adaptor.ProtoAdaptor
 	APIBackend   *APIBackend
 }
@@ -58,7 +76,7 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 		return nil, err
 	}
 
-	chainCfg, dposCfg, _, err := blockchain.SetupGenesisBlock(chainDb, config.Genesis)
+	chainCfg, _, err := blockchain.SetupGenesisBlock(chainDb, config.Genesis)
 	if err != nil {
 		return nil, err
 	}
@@ -78,10 +96,16 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 		ContractLogFlag: config.ContractLogFlag,
 	}
 
+	accountmanager.SetSharedCacheSize(config.ObjectCache)
+	asset.SetSharedCacheSize(config.ObjectCache)
+
 	ftservice.blockchain, err = blockchain.NewBlockChain(chainDb, config.StatePruning, vmconfig, ftservice.chainConfig, config.BadHashes, config.StartNumber, txpool.SenderCacher)
 	if err != nil {
 		return nil, err
 	}
+	if config.StateGC != 0 {
+		ftservice.blockchain.SetStateGC(config.StateGC, config.StateGCInterval)
+	}
 	// used to generate MagicNetID
 	ftservice.p2pServer.GenesisHash = ftservice.blockchain.Genesis().Hash()
 
@@ -92,8 +116,10 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 
 	ftservice.txPool = txpool.New(*config.TxPool, ftservice.chainConfig, ftservice.blockchain)
 
-	engine := dpos.New(dposCfg, ftservice.blockchain)
-	ftservice.engine = engine
+	ftservice.engine, err = consensus.NewEngine(ftservice.chainConfig, ftservice.blockchain)
+	if err != nil {
+		return nil, err
+	}
 
 	type bc struct {
 		*blockchain.BlockChain
@@ -118,6 +144,7 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 	bcc.Processor = txProcessor
 	ftservice.miner = miner.NewMiner(bcc)
 	ftservice.miner.SetDelayDuration(config.Miner.Delay)
+	ftservice.miner.SetMargin(config.Miner.Margin)
 	ftservice.miner.SetCoinbase(config.Miner.Name, config.Miner.PrivateKeys)
 	ftservice.miner.SetExtra([]byte(config.Miner.ExtraData))
 	if config.Miner.Start {
@@ -125,6 +152,39 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 	}
 
 	ftservice.APIBackend = &APIBackend{ftservice: ftservice}
+	if config.LoadGen {
+		log.Warn("Load generator enabled, do not use it out of devnet")
+		ftservice.loadGen = loadgen.New(ftservice.APIBackend)
+	}
+	if config.ManagedSender {
+		ftservice.sender = sender.New(ftservice.APIBackend)
+		ftservice.sender.Start()
+	}
+	if config.GasStats {
+		ftservice.gasStats = gasstats.New(ftservice.APIBackend, gasstats.DefaultBlocks)
+		ftservice.gasStats.Start()
+	}
+	if config.Search {
+		ftservice.search = search.New(ftservice.APIBackend)
+		ftservice.search.Start()
+	}
+	if config.Audit {
+		ftservice.auditor = audit.New(ftservice.blockchain)
+	}
+	if config.Checkpoint != nil && config.Checkpoint.Target != "" {
+		ftservice.checkpoint, err = checkpoint.New(ftservice.APIBackend, config.Checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		ftservice.checkpoint.Start(ftservice.blockchain.CurrentHeader())
+	}
+	if config.Stream != nil && config.Stream.Sink != "" {
+		ftservice.streamer, err = stream.New(ftservice.APIBackend, config.Stream)
+		if err != nil {
+			return nil, err
+		}
+		ftservice.streamer.Start(ftservice.blockchain.CurrentHeader())
+	}
 
 	ftservice.SetGasPrice(ftservice.TxPool().GasPrice())
 	return ftservice, nil
@@ -143,6 +203,27 @@ func (fs *FtService) Start() error {
 
 // Stop implements node.Service, terminating all internal goroutine
 func (fs *FtService) Stop() error {
+	if fs.loadGen != nil {
+		fs.loadGen.Stop()
+	}
+	if fs.sender != nil {
+		fs.sender.Stop()
+	}
+	if fs.gasStats != nil {
+		fs.gasStats.Stop()
+	}
+	if fs.search != nil {
+		fs.search.Stop()
+	}
+	if fs.auditor != nil {
+		fs.auditor.Stop()
+	}
+	if fs.checkpoint != nil {
+		fs.checkpoint.Stop()
+	}
+	if fs.streamer != nil {
+		fs.streamer.Stop()
+	}
 	fs.blockchain.Stop()
 	fs.txPool.Stop()
 	fs.chainDb.Close()
@@ -163,13 +244,44 @@ func (fs *FtService) SetGasPrice(gasPrice *big.Int) bool {
 	return true
 }
 
-// CreateDB creates the chain database.
+// CreateDB creates the chain database, the tiers configured with their own
+// path are stored on separate databases.
 func CreateDB(ctx *node.ServiceContext, config *Config, name string) (fdb.Database, error) {
 	db, err := ctx.OpenDatabase(name, config.DatabaseCache, config.DatabaseHandles)
 	if err != nil {
 		return nil, err
 	}
-	return db, nil
+	dirs := []struct {
+		tier rawdb.Tier
+		dir  string
+	}{
+		{rawdb.TierState, config.StateDir},
+		{rawdb.TierAncient, config.AncientDir},
+		{rawdb.TierIndex, config.IndexDir},
+	}
+	tiers := make(map[rawdb.Tier]fdb.Database)
+	opened := make(map[string]fdb.Database)
+	for _, d := range dirs {
+		if d.dir == "" {
+			continue
+		}
+		tdb, ok := opened[d.dir]
+		if !ok {
+			if tdb, err = ctx.OpenDatabase(d.dir, config.DatabaseCache, config.DatabaseHandles); err != nil {
+				db.Close()
+				for _, o := range opened {
+					o.Close()
+				}
+				return nil, fmt.Errorf("open database of tier %v: %v", d.dir, err)
+			}
+			opened[d.dir] = tdb
+		}
+		tiers[d.tier] = tdb
+	}
+	if len(tiers) == 0 {
+		return db, nil
+	}
+	return rawdb.NewTieredDatabase(db, tiers), nil
 }
 
 func (s *FtService) BlockChain() *blockchain.BlockChain { return s.blockchain }
@@ -0,0 +1,49 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package gasstats
+
+import (
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+// API exposes the gas statistics for the RPC interface.
+type API struct {
+	s *Stats
+}
+
+func (api *API) Actions() *Summary {
+	return api.s.Actions()
+}
+
+func (api *API) Contracts(limit int) []*ContractGasStat {
+	return api.s.Contracts(limit)
+}
+
+func (api *API) Contract(contract common.Name) *ContractGasStat {
+	return api.s.Contract(contract)
+}
+
+func (s *Stats) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "gasstats",
+			Version:   "1.0",
+			Service:   &API{s: s},
+		},
+	}
+}
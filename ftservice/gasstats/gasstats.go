@@ -0,0 +1,271 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package gasstats implements the rolling aggregates of the gas used by the
+// actions of the recent blocks, per action type and per called contract, to
+// measure the costs the gas schedule charges.
+package gasstats
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/event"
+	"github.com/fractalplatform/fractal/types"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to the chain head events.
+	chainHeadChanSize = 10
+	// DefaultBlocks is the number of recent blocks aggregated.
+	DefaultBlocks = 1024
+	// maxContracts is the max contracts returned by a contracts query.
+	maxContracts = 100
+)
+
+// Backend is the node services the gas statistics use.
+type Backend interface {
+	GetReceipts(ctx context.Context, hash common.Hash) ([]*types.Receipt, error)
+}
+
+// GasStat is the gas used by the actions of a kind.
+type GasStat struct {
+	Count   uint64 `json:"count"`
+	Failed  uint64 `json:"failed"`
+	GasUsed uint64 `json:"gasUsed"`
+	MinGas  uint64 `json:"minGas"`
+	MaxGas  uint64 `json:"maxGas"`
+	AvgGas  uint64 `json:"avgGas"`
+}
+
+func (s *GasStat) add(o *GasStat) {
+	if s.Count == 0 || o.MinGas < s.MinGas {
+		s.MinGas = o.MinGas
+	}
+	if o.MaxGas > s.MaxGas {
+		s.MaxGas = o.MaxGas
+	}
+	s.Count += o.Count
+	s.Failed += o.Failed
+	s.GasUsed += o.GasUsed
+	s.AvgGas = s.GasUsed / s.Count
+}
+
+func (s *GasStat) addAction(gasUsed uint64, failed bool) {
+	stat := &GasStat{Count: 1, GasUsed: gasUsed, MinGas: gasUsed, MaxGas: gasUsed}
+	if failed {
+		stat.Failed = 1
+	}
+	s.add(stat)
+}
+
+// ActionGasStat is the gas used by the actions of the type.
+type ActionGasStat struct {
+	ActionType types.ActionType `json:"actionType"`
+	*GasStat
+}
+
+// ContractGasStat is the gas used by the calls of the contract.
+type ContractGasStat struct {
+	Contract common.Name `json:"contract"`
+	*GasStat
+}
+
+// Summary is the gas statistics of the recent blocks.
+type Summary struct {
+	FromNumber uint64           `json:"fromNumber"`
+	ToNumber   uint64           `json:"toNumber"`
+	Actions    []*ActionGasStat `json:"actions"`
+}
+
+// blockStats is the gas used by the actions of a block.
+type blockStats struct {
+	number    uint64
+	actions   map[types.ActionType]*GasStat
+	contracts map[common.Name]*GasStat
+}
+
+// Stats aggregates the gas used by the actions of the recent blocks.
+type Stats struct {
+	backend Backend
+	blocks  int
+
+	mu     sync.RWMutex
+	window []*blockStats // in block order
+
+	chainHeadCh  chan *event.Event
+	chainHeadSub event.Subscription
+	wg           sync.WaitGroup
+}
+
+// New creates the gas statistics of the recent blocks, blocks <= 0 uses DefaultBlocks.
+func New(backend Backend, blocks int) *Stats {
+	if blocks <= 0 {
+		blocks = DefaultBlocks
+	}
+	return &Stats{backend: backend, blocks: blocks}
+}
+
+// Start aggregates the blocks of the new chain heads.
+func (s *Stats) Start() {
+	s.chainHeadCh = make(chan *event.Event, chainHeadChanSize)
+	s.chainHeadSub = event.Subscribe(nil, s.chainHeadCh, event.ChainHeadEv, &types.Block{})
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop stops aggregating the blocks.
+func (s *Stats) Stop() {
+	s.chainHeadSub.Unsubscribe()
+	s.wg.Wait()
+}
+
+func (s *Stats) loop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case ev := <-s.chainHeadCh:
+			block := ev.Data.(*types.Block)
+			receipts, err := s.backend.GetReceipts(context.Background(), block.Hash())
+			if err != nil {
+				log.Debug("Gas statistics skip block", "number", block.NumberU64(), "err", err)
+				continue
+			}
+			s.addBlock(block, receipts)
+			// Be unsubscribed due to system stopped
+		case <-s.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
+// addBlock aggregates the block, the blocks of the same or higher numbers are replaced by it.
+func (s *Stats) addBlock(block *types.Block, receipts []*types.Receipt) {
+	stats := &blockStats{
+		number:    block.NumberU64(),
+		actions:   make(map[types.ActionType]*GasStat),
+		contracts: make(map[common.Name]*GasStat),
+	}
+	for i, tx := range block.Txs {
+		if i >= len(receipts) {
+			break
+		}
+		for j, action := range tx.GetActions() {
+			if j >= len(receipts[i].ActionResults) {
+				break
+			}
+			result := receipts[i].ActionResults[j]
+			failed := result.Status != types.ReceiptStatusSuccessful
+			stat, ok := stats.actions[action.Type()]
+			if !ok {
+				stat = &GasStat{}
+				stats.actions[action.Type()] = stat
+			}
+			stat.addAction(result.GasUsed, failed)
+			if action.Type() != types.CallContract {
+				continue
+			}
+			stat, ok = stats.contracts[action.Recipient()]
+			if !ok {
+				stat = &GasStat{}
+				stats.contracts[action.Recipient()] = stat
+			}
+			stat.addAction(result.GasUsed, failed)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// a reorg replaces the blocks of the old chain
+	n := sort.Search(len(s.window), func(i int) bool { return s.window[i].number >= stats.number })
+	s.window = append(s.window[:n], stats)
+	if len(s.window) > s.blocks {
+		s.window = s.window[len(s.window)-s.blocks:]
+	}
+}
+
+// Actions returns the gas used per action type in the recent blocks, in action type order.
+func (s *Stats) Actions() *Summary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	summary := &Summary{Actions: []*ActionGasStat{}}
+	if len(s.window) == 0 {
+		return summary
+	}
+	summary.FromNumber, summary.ToNumber = s.window[0].number, s.window[len(s.window)-1].number
+	totals := make(map[types.ActionType]*GasStat)
+	for _, block := range s.window {
+		for actionType, stat := range block.actions {
+			total, ok := totals[actionType]
+			if !ok {
+				total = &GasStat{}
+				totals[actionType] = total
+				summary.Actions = append(summary.Actions, &ActionGasStat{ActionType: actionType, GasStat: total})
+			}
+			total.add(stat)
+		}
+	}
+	sort.Slice(summary.Actions, func(i, j int) bool { return summary.Actions[i].ActionType < summary.Actions[j].ActionType })
+	return summary
+}
+
+// Contracts returns the contracts of the most gas used in the recent blocks, at most limit.
+func (s *Stats) Contracts(limit int) []*ContractGasStat {
+	if limit <= 0 || limit > maxContracts {
+		limit = maxContracts
+	}
+	s.mu.RLock()
+	totals := make(map[common.Name]*GasStat)
+	contracts := []*ContractGasStat{}
+	for _, block := range s.window {
+		for contract, stat := range block.contracts {
+			total, ok := totals[contract]
+			if !ok {
+				total = &GasStat{}
+				totals[contract] = total
+				contracts = append(contracts, &ContractGasStat{Contract: contract, GasStat: total})
+			}
+			total.add(stat)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(contracts, func(i, j int) bool {
+		if contracts[i].GasUsed != contracts[j].GasUsed {
+			return contracts[i].GasUsed > contracts[j].GasUsed
+		}
+		return contracts[i].Contract < contracts[j].Contract
+	})
+	if len(contracts) > limit {
+		contracts = contracts[:limit]
+	}
+	return contracts
+}
+
+// Contract returns the gas used by the calls of the contract in the recent blocks.
+func (s *Stats) Contract(contract common.Name) *ContractGasStat {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	total := &GasStat{}
+	for _, block := range s.window {
+		if stat, ok := block.contracts[contract]; ok {
+			total.add(stat)
+		}
+	}
+	return &ContractGasStat{Contract: contract, GasStat: total}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package gasstats
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+type testAction struct {
+	actionType types.ActionType
+	to         common.Name
+	gasUsed    uint64
+	failed     bool
+}
+
+func testBlock(number uint64, actions ...testAction) (*types.Block, []*types.Receipt) {
+	txs := make([]*types.Transaction, 0, len(actions))
+	receipts := make([]*types.Receipt, 0, len(actions))
+	for i, a := range actions {
+		action := types.NewAction(a.actionType, common.Name("sender"), a.to, uint64(i), 0, 100000, big.NewInt(0), nil, nil)
+		txs = append(txs, types.NewTransaction(0, big.NewInt(1), action))
+		status := types.ReceiptStatusSuccessful
+		if a.failed {
+			status = types.ReceiptStatusFailed
+		}
+		receipts = append(receipts, &types.Receipt{ActionResults: []*types.ActionResult{{Status: status, GasUsed: a.gasUsed}}})
+	}
+	header := &types.Header{Number: new(big.Int).SetUint64(number)}
+	return types.NewBlock(header, txs, receipts), receipts
+}
+
+func TestGasStats(t *testing.T) {
+	s := New(nil, 2)
+	s.addBlock(testBlock(1,
+		testAction{types.Transfer, "to", 100, false},
+		testAction{types.CallContract, "c1", 500, false},
+	))
+	s.addBlock(testBlock(2,
+		testAction{types.Transfer, "to", 300, true},
+		testAction{types.CallContract, "c2", 900, false},
+		testAction{types.CallContract, "c1", 700, false},
+	))
+
+	summary := s.Actions()
+	if summary.FromNumber != 1 || summary.ToNumber != 2 || len(summary.Actions) != 2 {
+		t.Fatalf("summary %+v", summary)
+	}
+	call, transfer := summary.Actions[0], summary.Actions[1]
+	if call.ActionType != types.CallContract || call.Count != 3 || call.GasUsed != 2100 || call.MinGas != 500 || call.MaxGas != 900 || call.AvgGas != 700 {
+		t.Fatalf("call contract stat %+v", call.GasStat)
+	}
+	if transfer.ActionType != types.Transfer || transfer.Count != 2 || transfer.Failed != 1 || transfer.AvgGas != 200 {
+		t.Fatalf("transfer stat %+v", transfer.GasStat)
+	}
+	if contracts := s.Contracts(1); len(contracts) != 1 || contracts[0].Contract != "c1" || contracts[0].GasUsed != 1200 {
+		t.Fatalf("contracts %+v", contracts)
+	}
+
+	// the oldest block rolls out of the window
+	s.addBlock(testBlock(3, testAction{types.CallContract, "c1", 100, false}))
+	if c := s.Contract("c1"); c.Count != 2 || c.GasUsed != 800 || c.MinGas != 100 {
+		t.Fatalf("contract c1 %+v", c.GasStat)
+	}
+
+	// a reorg replaces the blocks of the old chain
+	s.addBlock(testBlock(2, testAction{types.Transfer, "to", 50, false}))
+	summary = s.Actions()
+	if summary.FromNumber != 2 || summary.ToNumber != 2 || len(summary.Actions) != 1 || summary.Actions[0].GasUsed != 50 {
+		t.Fatalf("summary after reorg %+v", summary)
+	}
+	if c := s.Contract("c1"); c.Count != 0 {
+		t.Fatalf("contract c1 after reorg %+v", c.GasStat)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package loadgen
+
+import (
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+// API exposes the load generator methods for the RPC interface.
+type API struct {
+	lg *LoadGen
+}
+
+func (api *API) StartLoadGen(cfg *Config) error {
+	return api.lg.Start(cfg)
+}
+
+func (api *API) StopLoadGen() error {
+	return api.lg.Stop()
+}
+
+func (api *API) LoadGenStats() Stats {
+	return api.lg.Stats()
+}
+
+func (lg *LoadGen) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   &API{lg: lg},
+		},
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package loadgen implements a devnet transaction generator which feeds signed
+// transfers, account creates and asset issues into the local transaction pool.
+package loadgen
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/txpool"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+const (
+	// tickInterval is the interval the generator sends a batch of transactions.
+	tickInterval = 100 * time.Millisecond
+	// maxTPS is the max transactions per second the generator sends.
+	maxTPS = 10000
+	// defaultGasLimit is the gas limit of an action when the config does not set one.
+	defaultGasLimit = 200000
+)
+
+var (
+	errLoadGenRunning    = errors.New("load generator is running")
+	errLoadGenNotRunning = errors.New("load generator is not running")
+	errLoadGenConfig     = errors.New("load generator config invalid")
+)
+
+// Backend is the node services the generator uses.
+type Backend interface {
+	ChainConfig() *params.ChainConfig
+	TxPool() *txpool.TxPool
+}
+
+// Config is the workload of the generator.
+type Config struct {
+	From          common.Name `json:"from"`          // account signing all the transactions
+	PrivateKey    string      `json:"privateKey"`    // hex private key of the from account, author index 0
+	To            common.Name `json:"to"`            // recipient of the transfers, empty use from
+	AssetID       uint64      `json:"assetId"`       // asset of the transfers
+	Amount        *big.Int    `json:"amount"`        // amount of a transfer, nil use 1
+	GasPrice      *big.Int    `json:"gasPrice"`      // nil use the tx pool gas price
+	GasLimit      uint64      `json:"gasLimit"`      // gas limit of an action, 0 use the default
+	TPS           uint64      `json:"tps"`           // target transactions per second
+	Duration      uint64      `json:"duration"`      // seconds to run, 0 run until stopped
+	Transfer      uint64      `json:"transfer"`      // weight of the transfers in the action mix
+	CreateAccount uint64      `json:"createAccount"` // weight of the account creates in the action mix
+	IssueAsset    uint64      `json:"issueAsset"`    // weight of the asset issues in the action mix
+	Seed          int64       `json:"seed"`          // seed of the action mix, the same seed replays the same workload
+}
+
+// Stats is the progress of the generator.
+type Stats struct {
+	Running       bool   `json:"running"`
+	StartTime     uint64 `json:"startTime"`
+	Sent          uint64 `json:"sent"`
+	Failed        uint64 `json:"failed"`
+	Transfer      uint64 `json:"transfer"`
+	CreateAccount uint64 `json:"createAccount"`
+	IssueAsset    uint64 `json:"issueAsset"`
+	LastError     string `json:"lastError,omitempty"`
+}
+
+// LoadGen sends the configured workload to the tx pool until stopped.
+type LoadGen struct {
+	backend Backend
+
+	mu    sync.Mutex
+	quit  chan struct{}
+	wg    sync.WaitGroup
+	stats Stats
+}
+
+// New creates a load generator on the backend.
+func New(backend Backend) *LoadGen {
+	return &LoadGen{backend: backend}
+}
+
+// Start starts sending the workload, it fails if the generator is running.
+func (lg *LoadGen) Start(cfg *Config) error {
+	w, err := newWorkload(lg.backend, cfg)
+	if err != nil {
+		return err
+	}
+
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	if lg.quit != nil {
+		return errLoadGenRunning
+	}
+	lg.quit = make(chan struct{})
+	lg.stats = Stats{Running: true, StartTime: uint64(time.Now().Unix())}
+	lg.wg.Add(1)
+	go lg.loop(w, cfg, lg.quit)
+	log.Info("Load generator started", "from", cfg.From, "tps", cfg.TPS)
+	return nil
+}
+
+// Stop stops sending the workload.
+func (lg *LoadGen) Stop() error {
+	lg.mu.Lock()
+	if lg.quit == nil {
+		lg.mu.Unlock()
+		return errLoadGenNotRunning
+	}
+	close(lg.quit)
+	lg.quit = nil
+	lg.mu.Unlock()
+
+	lg.wg.Wait()
+	log.Info("Load generator stopped")
+	return nil
+}
+
+// Stats returns the progress of the current or last run.
+func (lg *LoadGen) Stats() Stats {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	return lg.stats
+}
+
+func (lg *LoadGen) loop(w *workload, cfg *Config, quit chan struct{}) {
+	defer lg.wg.Done()
+	defer func() {
+		lg.mu.Lock()
+		lg.stats.Running = false
+		if lg.quit == quit {
+			lg.quit = nil
+		}
+		lg.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	var deadline <-chan time.Time
+	if cfg.Duration != 0 {
+		timer := time.NewTimer(time.Duration(cfg.Duration) * time.Second)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	// budget carries the fraction of a transaction per tick for low rates.
+	perTick := float64(cfg.TPS) * tickInterval.Seconds()
+	budget := float64(0)
+	for {
+		select {
+		case <-quit:
+			return
+		case <-deadline:
+			log.Info("Load generator finished", "duration", cfg.Duration)
+			return
+		case <-ticker.C:
+			budget += perTick
+			for ; budget >= 1; budget-- {
+				aType, err := w.send()
+				lg.record(aType, err)
+			}
+		}
+	}
+}
+
+func (lg *LoadGen) record(aType types.ActionType, err error) {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	if err != nil {
+		lg.stats.Failed++
+		lg.stats.LastError = err.Error()
+		return
+	}
+	lg.stats.Sent++
+	switch aType {
+	case types.Transfer:
+		lg.stats.Transfer++
+	case types.CreateAccount:
+		lg.stats.CreateAccount++
+	case types.IssueAsset:
+		lg.stats.IssueAsset++
+	}
+}
+
+// workload builds and signs the transactions of a config.
+type workload struct {
+	backend  Backend
+	cfg      *Config
+	priv     *ecdsa.PrivateKey
+	pubKey   common.PubKey
+	gasPrice *big.Int
+	gasLimit uint64
+	amount   *big.Int
+	to       common.Name
+	rand     *rand.Rand
+	nonce    uint64
+}
+
+func newWorkload(backend Backend, cfg *Config) (*workload, error) {
+	if cfg == nil || cfg.From == "" {
+		return nil, errLoadGenConfig
+	}
+	if cfg.TPS == 0 || cfg.TPS > maxTPS || cfg.Transfer+cfg.CreateAccount+cfg.IssueAsset == 0 {
+		return nil, errLoadGenConfig
+	}
+	priv, err := crypto.HexToECDSA(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &workload{
+		backend:  backend,
+		cfg:      cfg,
+		priv:     priv,
+		pubKey:   common.BytesToPubKey(crypto.FromECDSAPub(&priv.PublicKey)),
+		gasPrice: cfg.GasPrice,
+		gasLimit: cfg.GasLimit,
+		amount:   cfg.Amount,
+		to:       cfg.To,
+		rand:     rand.New(rand.NewSource(cfg.Seed)),
+	}
+	if w.gasPrice == nil {
+		w.gasPrice = backend.TxPool().GasPrice()
+	}
+	if w.gasLimit == 0 {
+		w.gasLimit = defaultGasLimit
+	}
+	if w.amount == nil {
+		w.amount = big.NewInt(1)
+	}
+	if w.to == "" {
+		w.to = cfg.From
+	}
+	if err := w.resetNonce(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// resetNonce reads the pending nonce of the from account from the tx pool.
+func (w *workload) resetNonce() error {
+	nonce, err := w.backend.TxPool().State().GetNonce(w.cfg.From)
+	if err != nil {
+		return err
+	}
+	w.nonce = nonce
+	return nil
+}
+
+// pick chooses the next action type by the weights of the config.
+func (w *workload) pick() types.ActionType {
+	n := uint64(w.rand.Int63n(int64(w.cfg.Transfer + w.cfg.CreateAccount + w.cfg.IssueAsset)))
+	switch {
+	case n < w.cfg.Transfer:
+		return types.Transfer
+	case n < w.cfg.Transfer+w.cfg.CreateAccount:
+		return types.CreateAccount
+	default:
+		return types.IssueAsset
+	}
+}
+
+// send builds, signs and adds the next transaction to the tx pool.
+func (w *workload) send() (types.ActionType, error) {
+	aType := w.pick()
+	action, err := w.newAction(aType)
+	if err != nil {
+		return aType, err
+	}
+	tx := types.NewTransaction(w.backend.ChainConfig().SysTokenID, w.gasPrice, action)
+	key := types.MakeKeyPair(w.priv, []uint64{0})
+	if err := types.SignActionWithMultiKey(action, tx, w.backend.TxPool().Signer(), 0, []*types.KeyPair{key}); err != nil {
+		return aType, err
+	}
+	if err := w.backend.TxPool().AddLocal(tx); err != nil {
+		if rerr := w.resetNonce(); rerr != nil {
+			log.Warn("Load generator reset nonce failed", "err", rerr)
+		}
+		return aType, err
+	}
+	w.nonce++
+	return aType, nil
+}
+
+// newAction builds an action of the type, the names of the new accounts and assets
+// are derived from the nonce so they never collide.
+func (w *workload) newAction(aType types.ActionType) (*types.Action, error) {
+	cfg := w.backend.ChainConfig()
+	suffix := "lg" + strconv.FormatUint(w.nonce, 36)
+	switch aType {
+	case types.CreateAccount:
+		payload, err := rlp.EncodeToBytes(&accountmanager.CreateAccountAction{
+			AccountName: common.Name(w.cfg.From.String() + "." + suffix),
+			PublicKey:   w.pubKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return types.NewAction(aType, w.cfg.From, common.Name(cfg.AccountName), w.nonce, cfg.SysTokenID, w.gasLimit, big.NewInt(0), payload, nil), nil
+	case types.IssueAsset:
+		payload, err := rlp.EncodeToBytes(&accountmanager.IssueAsset{
+			AssetName:  w.cfg.From.String() + ":" + suffix,
+			Symbol:     suffix,
+			Amount:     big.NewInt(1000000),
+			Owner:      w.cfg.From,
+			Founder:    w.cfg.From,
+			UpperLimit: big.NewInt(0),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return types.NewAction(aType, w.cfg.From, common.Name(cfg.AssetName), w.nonce, cfg.SysTokenID, w.gasLimit, big.NewInt(0), payload, nil), nil
+	default:
+		return types.NewAction(types.Transfer, w.cfg.From, w.to, w.nonce, w.cfg.AssetID, w.gasLimit, w.amount, nil, nil), nil
+	}
+}
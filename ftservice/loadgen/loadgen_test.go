@@ -0,0 +1,87 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package loadgen
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/txpool"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+type testBackend struct{}
+
+func (testBackend) ChainConfig() *params.ChainConfig { return params.DefaultChainconfig }
+func (testBackend) TxPool() *txpool.TxPool           { return nil }
+
+func newTestWorkload(cfg *Config) *workload {
+	return &workload{backend: testBackend{}, cfg: cfg, to: cfg.From, rand: rand.New(rand.NewSource(cfg.Seed))}
+}
+
+func TestWorkloadPick(t *testing.T) {
+	cfg := &Config{From: common.Name("loadgentester"), Transfer: 2, CreateAccount: 1, Seed: 7}
+	w1, w2 := newTestWorkload(cfg), newTestWorkload(cfg)
+	counts := make(map[types.ActionType]int)
+	for i := 0; i < 300; i++ {
+		t1, t2 := w1.pick(), w2.pick()
+		if t1 != t2 {
+			t.Fatalf("the same seed picks different actions %v %v", t1, t2)
+		}
+		counts[t1]++
+	}
+	if counts[types.IssueAsset] != 0 || counts[types.Transfer] <= counts[types.CreateAccount] {
+		t.Fatalf("action mix mismatch %v", counts)
+	}
+}
+
+func TestWorkloadNewAction(t *testing.T) {
+	cfg := &Config{From: common.Name("loadgentester"), Transfer: 1}
+	w := newTestWorkload(cfg)
+	w.nonce = 12345
+
+	action, err := w.newAction(types.CreateAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var create accountmanager.CreateAccountAction
+	if err := rlp.DecodeBytes(action.Data(), &create); err != nil {
+		t.Fatal(err)
+	}
+	if !create.AccountName.IsValid(accountmanager.GetAcountNameRegExpFork1(), accountmanager.GetAcountNameLength()) {
+		t.Fatalf("account name %v invalid", create.AccountName)
+	}
+	if action.Nonce() != w.nonce || action.Recipient() != common.Name(params.DefaultChainconfig.AccountName) {
+		t.Fatalf("create account action mismatch")
+	}
+
+	action, err = w.newAction(types.IssueAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issue accountmanager.IssueAsset
+	if err := rlp.DecodeBytes(action.Data(), &issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.AssetName != "loadgentester:lg9ix" || issue.Owner != cfg.From {
+		t.Fatalf("issue asset mismatch %v", issue.AssetName)
+	}
+}
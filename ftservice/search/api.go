@@ -0,0 +1,47 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+// API exposes the search index for the RPC interface.
+type API struct {
+	idx *Index
+}
+
+// Search returns the accounts and assets matching all the terms of the query,
+// fuzzy also matches the terms with typos.
+func (api *API) Search(query string, fuzzy bool, limit int) []*Result {
+	return api.idx.Search(query, fuzzy, limit)
+}
+
+// Count returns the count of the indexed accounts and assets.
+func (api *API) Count() int {
+	return api.idx.Count()
+}
+
+func (idx *Index) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "search",
+			Version:   "1.0",
+			Service:   &API{idx: idx},
+		},
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package search implements the local full-text index of the account
+// descriptions and of the asset names, symbols and descriptions, searched by
+// prefix and fuzzy matching without an external search engine.
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/event"
+	"github.com/fractalplatform/fractal/types"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to the chain head events.
+	chainHeadChanSize = 10
+	// maxResults is the max documents returned by a search.
+	maxResults = 100
+	// maxQueryTerms is the max terms of a search query.
+	maxQueryTerms = 8
+
+	// KindAccount is the kind of the account documents.
+	KindAccount = "account"
+	// KindAsset is the kind of the asset documents.
+	KindAsset = "asset"
+)
+
+// match scores of a query term
+const (
+	scoreFuzzy = iota + 1
+	scorePrefix
+	scoreExact
+)
+
+// Backend is the node services the index uses.
+type Backend interface {
+	GetAccountManager() (*accountmanager.AccountManager, error)
+}
+
+// Document is an indexed account or asset.
+type Document struct {
+	Kind        string `json:"kind"`
+	ID          uint64 `json:"id"`
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol,omitempty"`
+	Description string `json:"description"`
+}
+
+func (d *Document) key() string {
+	return d.Kind + ":" + d.Name
+}
+
+// Result is a document matching a search, higher scores match better.
+type Result struct {
+	*Document
+	Score int `json:"score"`
+}
+
+// Index is the inverted index of the accounts and assets of the chain.
+type Index struct {
+	backend Backend
+
+	mu          sync.RWMutex
+	docs        map[string]*Document
+	postings    map[string]map[string]struct{} // token -> document keys
+	tokens      []string                       // sorted tokens of the postings
+	lastAccount uint64                         // id of the last indexed account
+	assetCount  uint64                         // count of the indexed asset ids
+
+	chainHeadCh  chan *event.Event
+	chainHeadSub event.Subscription
+	wg           sync.WaitGroup
+}
+
+// New creates an empty index.
+func New(backend Backend) *Index {
+	return &Index{
+		backend:  backend,
+		docs:     make(map[string]*Document),
+		postings: make(map[string]map[string]struct{}),
+	}
+}
+
+// Start indexes the current state and the accounts and assets of the new chain heads.
+func (idx *Index) Start() {
+	idx.chainHeadCh = make(chan *event.Event, chainHeadChanSize)
+	idx.chainHeadSub = event.Subscribe(nil, idx.chainHeadCh, event.ChainHeadEv, &types.Block{})
+	idx.wg.Add(1)
+	go idx.loop()
+}
+
+// Stop stops indexing.
+func (idx *Index) Stop() {
+	idx.chainHeadSub.Unsubscribe()
+	idx.wg.Wait()
+}
+
+func (idx *Index) loop() {
+	defer idx.wg.Done()
+	idx.sync()
+	for {
+		select {
+		case <-idx.chainHeadCh:
+			idx.sync()
+			// Be unsubscribed due to system stopped
+		case <-idx.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
+// sync indexes the accounts and assets created since the last sync. The
+// descriptions can't be changed, a reorg dropping indexed ids rebuilds the index.
+func (idx *Index) sync() {
+	am, err := idx.backend.GetAccountManager()
+	if err != nil {
+		log.Debug("Search index skip sync", "err", err)
+		return
+	}
+	if err := idx.update(am); err != nil {
+		log.Debug("Search index sync failed", "err", err)
+	}
+}
+
+func (idx *Index) update(am *accountmanager.AccountManager) error {
+	accountCounter, err := am.GetAccountCounter()
+	if err != nil {
+		return err
+	}
+	assetCount, err := am.GetAssetCount()
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if accountCounter < idx.lastAccount || assetCount < idx.assetCount {
+		idx.docs = make(map[string]*Document)
+		idx.postings = make(map[string]map[string]struct{})
+		idx.tokens = nil
+		idx.lastAccount, idx.assetCount = 0, 0
+	}
+	for id := idx.lastAccount + 1; id <= accountCounter; id++ {
+		acct, err := am.GetAccountById(id)
+		if err != nil {
+			return err
+		}
+		idx.lastAccount = id
+		if acct == nil {
+			continue
+		}
+		idx.add(&Document{Kind: KindAccount, ID: id, Name: acct.GetName().String(), Description: acct.Description})
+	}
+	for id := idx.assetCount; id < assetCount; id++ {
+		ao, err := am.GetAssetInfoByID(id)
+		if err != nil {
+			return err
+		}
+		idx.assetCount = id + 1
+		idx.add(&Document{Kind: KindAsset, ID: id, Name: ao.GetAssetName(), Symbol: ao.GetSymbol(), Description: ao.Description})
+	}
+	return nil
+}
+
+// add indexes the document, replacing the document of the same kind and name.
+func (idx *Index) add(doc *Document) {
+	key := doc.key()
+	if old, ok := idx.docs[key]; ok {
+		for _, token := range documentTokens(old) {
+			delete(idx.postings[token], key)
+			if len(idx.postings[token]) == 0 {
+				delete(idx.postings, token)
+				i := sort.SearchStrings(idx.tokens, token)
+				idx.tokens = append(idx.tokens[:i], idx.tokens[i+1:]...)
+			}
+		}
+	}
+	idx.docs[key] = doc
+	for _, token := range documentTokens(doc) {
+		keys, ok := idx.postings[token]
+		if !ok {
+			keys = make(map[string]struct{})
+			idx.postings[token] = keys
+			i := sort.SearchStrings(idx.tokens, token)
+			idx.tokens = append(idx.tokens, "")
+			copy(idx.tokens[i+1:], idx.tokens[i:])
+			idx.tokens[i] = token
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// Search returns the documents matching all the terms of the query, by exact,
+// prefix or, when fuzzy, approximate match of a term, at most limit ordered by score.
+func (idx *Index) Search(query string, fuzzy bool, limit int) []*Result {
+	if limit <= 0 || limit > maxResults {
+		limit = maxResults
+	}
+	terms := tokenize(query)
+	if len(terms) > maxQueryTerms {
+		terms = terms[:maxQueryTerms]
+	}
+	results := []*Result{}
+	if len(terms) == 0 {
+		return results
+	}
+
+	idx.mu.RLock()
+	var scores map[string]int
+	for _, term := range terms {
+		termScores := idx.match(term, fuzzy)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for key, score := range scores {
+			if termScore, ok := termScores[key]; ok {
+				scores[key] = score + termScore
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+	for key, score := range scores {
+		results = append(results, &Result{Document: idx.docs[key], Score: score})
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Kind != results[j].Kind {
+			return results[i].Kind < results[j].Kind
+		}
+		return results[i].Name < results[j].Name
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// match returns the best score of the term per matching document.
+func (idx *Index) match(term string, fuzzy bool) map[string]int {
+	scores := make(map[string]int)
+	addToken := func(token string, score int) {
+		for key := range idx.postings[token] {
+			if score > scores[key] {
+				scores[key] = score
+			}
+		}
+	}
+	for i := sort.SearchStrings(idx.tokens, term); i < len(idx.tokens) && strings.HasPrefix(idx.tokens[i], term); i++ {
+		if idx.tokens[i] == term {
+			addToken(idx.tokens[i], scoreExact)
+		} else {
+			addToken(idx.tokens[i], scorePrefix)
+		}
+	}
+	if maxEdits := maxEditDistance(term); fuzzy && maxEdits > 0 {
+		for _, token := range idx.tokens {
+			if !strings.HasPrefix(token, term) && editDistance(term, token, maxEdits) <= maxEdits {
+				addToken(token, scoreFuzzy)
+			}
+		}
+	}
+	return scores
+}
+
+// Count returns the count of the indexed documents.
+func (idx *Index) Count() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// documentTokens returns the distinct tokens of the document.
+func documentTokens(doc *Document) []string {
+	tokens := tokenize(doc.Name + " " + doc.Symbol + " " + doc.Description)
+	// the whole names are searchable too
+	for _, name := range []string{doc.Name, doc.Symbol} {
+		if name = strings.ToLower(name); name != "" {
+			tokens = append(tokens, name)
+		}
+	}
+	seen := make(map[string]bool, len(tokens))
+	distinct := tokens[:0]
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			distinct = append(distinct, token)
+		}
+	}
+	return distinct
+}
+
+// tokenize splits the text into lower case words of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// maxEditDistance returns the edits allowed by a fuzzy match of the term.
+func maxEditDistance(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the levenshtein distance of a and b, or max+1 when it
+// exceeds max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if cur[j] < rowMin {
+				rowMin = cur[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
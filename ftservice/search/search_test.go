@@ -0,0 +1,114 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"testing"
+)
+
+func testIndex() *Index {
+	idx := New(nil)
+	idx.add(&Document{Kind: KindAccount, ID: 4097, Name: "exchange.hub", Description: "Decentralized exchange of wrapped tokens"})
+	idx.add(&Document{Kind: KindAccount, ID: 4098, Name: "gamestudio", Description: "Games and collectibles"})
+	idx.add(&Document{Kind: KindAsset, ID: 1, Name: "exchange.hub.token", Symbol: "HUB", Description: "Governance token of the exchange"})
+	return idx
+}
+
+func resultNames(results []*Result) []string {
+	names := make([]string, 0, len(results))
+	for _, r := range results {
+		names = append(names, r.Kind+":"+r.Name)
+	}
+	return names
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+	if idx.Count() != 3 {
+		t.Fatalf("count %d", idx.Count())
+	}
+
+	tests := []struct {
+		query string
+		fuzzy bool
+		want  []string
+	}{
+		{"hub", false, []string{"account:exchange.hub", "asset:exchange.hub.token"}},
+		{"exchange token", false, []string{"asset:exchange.hub.token", "account:exchange.hub"}},
+		{"governance", false, []string{"asset:exchange.hub.token"}},
+		{"collect", false, []string{"account:gamestudio"}},
+		{"colectibles", false, []string{}},
+		{"colectibles", true, []string{"account:gamestudio"}},
+		{"exchange.hub.token", false, []string{"asset:exchange.hub.token", "account:exchange.hub"}},
+		{"", false, []string{}},
+	}
+	for _, test := range tests {
+		got := resultNames(idx.Search(test.query, test.fuzzy, 0))
+		if len(got) != len(test.want) {
+			t.Errorf("search %q fuzzy %v got %v, want %v", test.query, test.fuzzy, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("search %q fuzzy %v got %v, want %v", test.query, test.fuzzy, got, test.want)
+				break
+			}
+		}
+	}
+
+	// exact matches rank before prefix matches
+	if results := idx.Search("token", false, 0); len(results) != 2 || results[0].Name != "exchange.hub.token" {
+		t.Errorf("search token got %v", resultNames(results))
+	}
+	if results := idx.Search("exchange", false, 1); len(results) != 1 {
+		t.Errorf("search limit got %v", resultNames(results))
+	}
+}
+
+func TestSearchReplaceDocument(t *testing.T) {
+	idx := testIndex()
+	idx.add(&Document{Kind: KindAccount, ID: 4098, Name: "gamestudio", Description: "Music label"})
+	if results := idx.Search("collectibles", false, 0); len(results) != 0 {
+		t.Errorf("replaced description still matches %v", resultNames(results))
+	}
+	if results := idx.Search("music", false, 0); len(results) != 1 {
+		t.Errorf("new description not matched %v", resultNames(results))
+	}
+	for _, token := range idx.tokens {
+		if token == "games" || token == "collectibles" {
+			t.Errorf("token %s of the replaced description not removed", token)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"token", "token", 2, 0},
+		{"token", "tokne", 2, 2},
+		{"token", "tokens", 2, 1},
+		{"token", "exchange", 2, 3},
+	}
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b, test.max); got != test.want {
+			t.Errorf("edit distance of %s and %s got %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sender
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+// API exposes the managed sender methods for the RPC interface.
+type API struct {
+	s *Sender
+}
+
+func (api *API) AddAccount(name common.Name, privateKey string) error {
+	return api.s.AddAccount(name, privateKey)
+}
+
+func (api *API) RemoveAccount(name common.Name) error {
+	return api.s.RemoveAccount(name)
+}
+
+func (api *API) Accounts() []common.Name {
+	return api.s.Accounts()
+}
+
+func (api *API) SendTransaction(args SendArgs) (common.Hash, error) {
+	return api.s.Send(&args)
+}
+
+func (api *API) RescueTransaction(hash common.Hash, gasPrice *big.Int) (common.Hash, error) {
+	return api.s.Rescue(hash, gasPrice)
+}
+
+func (api *API) Status(name common.Name) (*Status, error) {
+	return api.s.Status(name)
+}
+
+func (s *Sender) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "sender",
+			Version:   "1.0",
+			Service:   &API{s: s},
+		},
+	}
+}
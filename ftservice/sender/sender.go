@@ -0,0 +1,495 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package sender implements the managed sending of the local accounts, the node
+// assigns and tracks the nonces of their transactions, resubmits the ones dropped
+// from the tx pool and fills the nonce gaps left by the ones never included.
+package sender
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/event"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/txpool"
+	"github.com/fractalplatform/fractal/types"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to the chain head events.
+	chainHeadChanSize = 10
+	// defaultGasLimit is the gas limit of an action when the args do not set one.
+	defaultGasLimit = 200000
+)
+
+var (
+	errAccountManaged    = errors.New("account is managed already")
+	errAccountNotManaged = errors.New("account is not managed")
+	errKeyNotAuthor      = errors.New("key is not an author of the account")
+	errTxNotPending      = errors.New("transaction is not pending in the tx pool")
+	errRescuePrice       = errors.New("gas price is not above the price bump of the transaction")
+)
+
+// Backend is the node services the sender uses.
+type Backend interface {
+	ChainConfig() *params.ChainConfig
+	TxPool() *txpool.TxPool
+	GetAccountManager() (*accountmanager.AccountManager, error)
+}
+
+// txBackend is the tx pool and chain access of the sender.
+type txBackend interface {
+	addTx(tx *types.Transaction) error
+	hasTx(hash common.Hash) bool
+	getTx(hash common.Hash) *types.Transaction
+	gasPrice() *big.Int
+	priceBump() uint64
+	signer() types.Signer
+	poolNonce(name common.Name) (uint64, error)
+	chainNonce(name common.Name) (uint64, error)
+	getAccount(name common.Name) (*accountmanager.Account, error)
+}
+
+type nodeBackend struct {
+	Backend
+}
+
+func (b nodeBackend) addTx(tx *types.Transaction) error { return b.TxPool().AddLocal(tx) }
+func (b nodeBackend) hasTx(hash common.Hash) bool       { return b.TxPool().Get(hash) != nil }
+func (b nodeBackend) getTx(hash common.Hash) *types.Transaction {
+	return b.TxPool().Get(hash)
+}
+func (b nodeBackend) gasPrice() *big.Int { return b.TxPool().GasPrice() }
+func (b nodeBackend) priceBump() uint64  { return b.TxPool().PriceBump() }
+func (b nodeBackend) signer() types.Signer {
+	return b.TxPool().Signer()
+}
+func (b nodeBackend) poolNonce(name common.Name) (uint64, error) {
+	return b.TxPool().State().GetNonce(name)
+}
+
+func (b nodeBackend) chainNonce(name common.Name) (uint64, error) {
+	am, err := b.GetAccountManager()
+	if err != nil {
+		return 0, err
+	}
+	return am.GetNonce(name)
+}
+
+func (b nodeBackend) getAccount(name common.Name) (*accountmanager.Account, error) {
+	am, err := b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAccountByName(name)
+}
+
+// SendArgs is the action sent by a managed account, the nonce is assigned by the sender.
+type SendArgs struct {
+	ActionType types.ActionType `json:"actionType"`
+	From       common.Name      `json:"from"`
+	To         common.Name      `json:"to"`
+	AssetID    uint64           `json:"assetId"`
+	Amount     *big.Int         `json:"amount"`
+	GasPrice   *big.Int         `json:"gasPrice"` // nil use the tx pool gas price
+	GasLimit   uint64           `json:"gasLimit"` // 0 use the default
+	Payload    hexutil.Bytes    `json:"payload"`
+	Remark     hexutil.Bytes    `json:"remark"`
+}
+
+// PendingTx is a transaction sent by a managed account and not confirmed yet.
+type PendingTx struct {
+	Nonce  uint64      `json:"nonce"`
+	Hash   common.Hash `json:"hash"`
+	Filler bool        `json:"filler"` // filling the nonce of a transaction never included
+}
+
+// Status is the nonce tracking of a managed account.
+type Status struct {
+	Account common.Name  `json:"account"`
+	Nonce   uint64       `json:"nonce"` // next nonce to assign
+	Pending []*PendingTx `json:"pending"`
+	Sent    uint64       `json:"sent"`
+	Resent  uint64       `json:"resent"`
+	Filled  uint64       `json:"filled"`
+	Rescued uint64       `json:"rescued"`
+}
+
+// account is a managed account, the transactions sent are kept in nonce order
+// until the chain nonce passes them.
+type account struct {
+	name common.Name
+	priv *ecdsa.PrivateKey
+
+	mu      sync.Mutex
+	nonce   uint64
+	pending []*types.Transaction
+	fillers map[common.Hash]bool
+	sent    uint64
+	resent  uint64
+	filled  uint64
+	rescued uint64
+}
+
+// Sender sends the transactions of the managed accounts.
+type Sender struct {
+	backend txBackend
+	config  *params.ChainConfig
+
+	mu       sync.RWMutex
+	accounts map[common.Name]*account
+
+	chainHeadCh  chan *event.Event
+	chainHeadSub event.Subscription
+	wg           sync.WaitGroup
+}
+
+// New creates a sender on the backend, it manages no account until added.
+func New(backend Backend) *Sender {
+	return newSender(nodeBackend{backend}, backend.ChainConfig())
+}
+
+func newSender(backend txBackend, config *params.ChainConfig) *Sender {
+	return &Sender{
+		backend:  backend,
+		config:   config,
+		accounts: make(map[common.Name]*account),
+	}
+}
+
+// Start heals the nonces of the managed accounts at every new chain head.
+func (s *Sender) Start() {
+	s.chainHeadCh = make(chan *event.Event, chainHeadChanSize)
+	s.chainHeadSub = event.Subscribe(nil, s.chainHeadCh, event.ChainHeadEv, &types.Block{})
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop stops healing the nonces.
+func (s *Sender) Stop() {
+	s.chainHeadSub.Unsubscribe()
+	s.wg.Wait()
+}
+
+func (s *Sender) loop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.chainHeadCh:
+			s.mu.RLock()
+			accounts := make([]*account, 0, len(s.accounts))
+			for _, acct := range s.accounts {
+				accounts = append(accounts, acct)
+			}
+			s.mu.RUnlock()
+			for _, acct := range accounts {
+				s.heal(acct)
+			}
+			// Be unsubscribed due to system stopped
+		case <-s.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
+// AddAccount manages the account signing with the hex private key, the key must be an author of the account.
+func (s *Sender) AddAccount(name common.Name, privateKey string) error {
+	priv, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		return err
+	}
+	acct := &account{name: name, priv: priv, fillers: make(map[common.Hash]bool)}
+	if _, err := s.signIndex(acct); err != nil {
+		return err
+	}
+	if acct.nonce, err = s.backend.poolNonce(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[name]; ok {
+		return errAccountManaged
+	}
+	s.accounts[name] = acct
+	log.Info("Managed sender account added", "account", name, "nonce", acct.nonce)
+	return nil
+}
+
+// RemoveAccount stops managing the account, the transactions sent are kept in the tx pool.
+func (s *Sender) RemoveAccount(name common.Name) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[name]; !ok {
+		return errAccountNotManaged
+	}
+	delete(s.accounts, name)
+	return nil
+}
+
+// Accounts returns the names of the managed accounts.
+func (s *Sender) Accounts() []common.Name {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]common.Name, 0, len(s.accounts))
+	for name := range s.accounts {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (s *Sender) account(name common.Name) (*account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	acct, ok := s.accounts[name]
+	if !ok {
+		return nil, errAccountNotManaged
+	}
+	return acct, nil
+}
+
+// Send signs the action with the next nonce of the managed account and adds it to the tx pool.
+func (s *Sender) Send(args *SendArgs) (common.Hash, error) {
+	acct, err := s.account(args.From)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	acct.mu.Lock()
+	defer acct.mu.Unlock()
+
+	index, err := s.signIndex(acct)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	for resync := false; ; resync = true {
+		tx, err := s.newTx(acct, index, args, acct.nonce)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		err = s.backend.addTx(tx)
+		if err == nil {
+			acct.pending = append(acct.pending, tx)
+			acct.nonce++
+			acct.sent++
+			return tx.Hash(), nil
+		}
+		if err != txpool.ErrNonceTooLow || resync {
+			return common.Hash{}, err
+		}
+		// the key is used out of the sender, take the nonce of the tx pool
+		if acct.nonce, err = s.backend.poolNonce(acct.name); err != nil {
+			return common.Hash{}, err
+		}
+	}
+}
+
+// Status returns the nonce tracking of the managed account.
+func (s *Sender) Status(name common.Name) (*Status, error) {
+	acct, err := s.account(name)
+	if err != nil {
+		return nil, err
+	}
+	acct.mu.Lock()
+	defer acct.mu.Unlock()
+	status := &Status{
+		Account: acct.name,
+		Nonce:   acct.nonce,
+		Pending: make([]*PendingTx, 0, len(acct.pending)),
+		Sent:    acct.sent,
+		Resent:  acct.resent,
+		Filled:  acct.filled,
+		Rescued: acct.rescued,
+	}
+	for _, tx := range acct.pending {
+		hash := tx.Hash()
+		status.Pending = append(status.Pending, &PendingTx{Nonce: txNonce(tx), Hash: hash, Filler: acct.fillers[hash]})
+	}
+	return status, nil
+}
+
+// Rescue replaces the pending transaction of a managed account by the same actions
+// at a higher gas price, signed with the key of the account. A nil gas price bumps
+// the price of the transaction by the tx pool price bump.
+func (s *Sender) Rescue(hash common.Hash, gasPrice *big.Int) (common.Hash, error) {
+	tx := s.backend.getTx(hash)
+	if tx == nil {
+		return common.Hash{}, errTxNotPending
+	}
+	acct, err := s.account(tx.GetActions()[0].Sender())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	acct.mu.Lock()
+	defer acct.mu.Unlock()
+
+	minPrice := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(100+s.backend.priceBump()))
+	minPrice.Div(minPrice, big.NewInt(100))
+	if gasPrice == nil {
+		gasPrice = minPrice.Add(minPrice, big.NewInt(1))
+	} else if gasPrice.Cmp(minPrice) <= 0 {
+		return common.Hash{}, errRescuePrice
+	}
+
+	index, err := s.signIndex(acct)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	actions := make([]*types.Action, 0, len(tx.GetActions()))
+	keys := []*types.KeyPair{types.MakeKeyPair(acct.priv, index)}
+	for _, a := range tx.GetActions() {
+		if a.Sender() != acct.name {
+			return common.Hash{}, errAccountNotManaged
+		}
+		actions = append(actions, types.NewAction(a.Type(), a.Sender(), a.Recipient(), a.Nonce(), a.AssetID(), a.Gas(), a.Value(), a.Data(), a.Remark()))
+	}
+	rescue := types.NewTransaction(tx.GasAssetID(), gasPrice, actions...)
+	for _, action := range actions {
+		if err := types.SignActionWithMultiKey(action, rescue, s.backend.signer(), 0, keys); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	if err := s.backend.addTx(rescue); err != nil {
+		return common.Hash{}, err
+	}
+
+	for i, pending := range acct.pending {
+		if pending.Hash() == hash {
+			acct.pending[i] = rescue
+			if acct.fillers[hash] {
+				delete(acct.fillers, hash)
+				acct.fillers[rescue.Hash()] = true
+			}
+		}
+	}
+	acct.rescued++
+	log.Info("Managed sender rescued transaction", "account", acct.name, "nonce", txNonce(tx), "hash", hash, "rescue", rescue.Hash(), "gasPrice", gasPrice)
+	return rescue.Hash(), nil
+}
+
+// heal drops the confirmed transactions of the account, resubmits the ones dropped
+// from the tx pool and fills the nonce of the ones never to be included.
+func (s *Sender) heal(acct *account) {
+	acct.mu.Lock()
+	defer acct.mu.Unlock()
+
+	confirmed, err := s.backend.chainNonce(acct.name)
+	if err != nil {
+		log.Warn("Managed sender failed to get nonce", "account", acct.name, "err", err)
+		return
+	}
+	i := 0
+	for ; i < len(acct.pending) && txNonce(acct.pending[i]) < confirmed; i++ {
+		delete(acct.fillers, acct.pending[i].Hash())
+	}
+	acct.pending = acct.pending[i:]
+	if acct.nonce < confirmed {
+		acct.nonce = confirmed
+	}
+
+	var index []uint64
+	for i, tx := range acct.pending {
+		if s.backend.hasTx(tx.Hash()) {
+			continue
+		}
+		err := s.backend.addTx(tx)
+		if err == nil {
+			acct.resent++
+			continue
+		}
+		log.Warn("Managed sender failed to resubmit", "account", acct.name, "nonce", txNonce(tx), "hash", tx.Hash(), "err", err)
+		if index == nil {
+			if index, err = s.signIndex(acct); err != nil {
+				log.Warn("Managed sender failed to get sign index", "account", acct.name, "err", err)
+				return
+			}
+		}
+		filler, err := s.newFiller(acct, index, txNonce(tx))
+		if err == nil {
+			err = s.backend.addTx(filler)
+		}
+		if err != nil {
+			// the later transactions wait the gap filled at the next head
+			log.Warn("Managed sender failed to fill nonce gap", "account", acct.name, "nonce", txNonce(tx), "err", err)
+			return
+		}
+		acct.pending[i] = filler
+		acct.fillers[filler.Hash()] = true
+		acct.filled++
+	}
+}
+
+// signIndex returns the author index of the key of the account.
+func (s *Sender) signIndex(acct *account) ([]uint64, error) {
+	a, err := s.backend.getAccount(acct.name)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, accountmanager.ErrAccountNotExist
+	}
+	pubKey := common.BytesToPubKey(crypto.FromECDSAPub(&acct.priv.PublicKey))
+	address := crypto.PubkeyToAddress(acct.priv.PublicKey)
+	for i, author := range a.Authors {
+		switch owner := author.Owner.(type) {
+		case common.PubKey:
+			if owner.Compare(pubKey) == 0 {
+				return []uint64{uint64(i)}, nil
+			}
+		case common.Address:
+			if owner.Compare(address) == 0 {
+				return []uint64{uint64(i)}, nil
+			}
+		}
+	}
+	return nil, errKeyNotAuthor
+}
+
+func (s *Sender) newTx(acct *account, index []uint64, args *SendArgs, nonce uint64) (*types.Transaction, error) {
+	amount, gasPrice, gasLimit := args.Amount, args.GasPrice, args.GasLimit
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	if gasPrice == nil {
+		gasPrice = s.backend.gasPrice()
+	}
+	if gasLimit == 0 {
+		gasLimit = defaultGasLimit
+	}
+	action := types.NewAction(args.ActionType, acct.name, args.To, nonce, args.AssetID, gasLimit, amount, args.Payload, args.Remark)
+	tx := types.NewTransaction(s.config.SysTokenID, gasPrice, action)
+	if err := types.SignActionWithMultiKey(action, tx, s.backend.signer(), 0, []*types.KeyPair{types.MakeKeyPair(acct.priv, index)}); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// newFiller returns a zero transfer of the account to itself taking the nonce.
+func (s *Sender) newFiller(acct *account, index []uint64, nonce uint64) (*types.Transaction, error) {
+	args := &SendArgs{ActionType: types.Transfer, From: acct.name, To: acct.name, AssetID: s.config.SysTokenID}
+	return s.newTx(acct, index, args, nonce)
+}
+
+func txNonce(tx *types.Transaction) uint64 {
+	return tx.GetActions()[0].Nonce()
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sender
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/txpool"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// testBackend is a tx pool keeping the transactions by hash and a chain of a single nonce.
+type testBackend struct {
+	acct     *accountmanager.Account
+	pool     map[common.Hash]*types.Transaction
+	chain    uint64
+	rejected map[common.Hash]bool
+}
+
+func (b *testBackend) addTx(tx *types.Transaction) error {
+	if b.rejected[tx.Hash()] {
+		return errors.New("rejected")
+	}
+	if txNonce(tx) < b.chain {
+		return txpool.ErrNonceTooLow
+	}
+	b.pool[tx.Hash()] = tx
+	return nil
+}
+
+func (b *testBackend) hasTx(hash common.Hash) bool               { return b.pool[hash] != nil }
+func (b *testBackend) getTx(hash common.Hash) *types.Transaction { return b.pool[hash] }
+func (b *testBackend) gasPrice() *big.Int                        { return big.NewInt(1) }
+func (b *testBackend) priceBump() uint64                         { return 10 }
+func (b *testBackend) signer() types.Signer {
+	return types.NewSigner(params.DefaultChainconfig.ChainID)
+}
+func (b *testBackend) poolNonce(name common.Name) (uint64, error) {
+	nonce := b.chain
+	for _, tx := range b.pool {
+		if n := txNonce(tx) + 1; n > nonce {
+			nonce = n
+		}
+	}
+	return nonce, nil
+}
+func (b *testBackend) chainNonce(name common.Name) (uint64, error) { return b.chain, nil }
+func (b *testBackend) getAccount(name common.Name) (*accountmanager.Account, error) {
+	return b.acct, nil
+}
+
+func TestSenderNonces(t *testing.T) {
+	priv, _ := crypto.GenerateKey()
+	name := common.Name("managedsender")
+	acct, err := accountmanager.NewAccount(name, common.Name(""), common.BytesToPubKey(crypto.FromECDSAPub(&priv.PublicKey)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &testBackend{acct: acct, pool: make(map[common.Hash]*types.Transaction), rejected: make(map[common.Hash]bool)}
+	s := newSender(backend, params.DefaultChainconfig)
+
+	other, _ := crypto.GenerateKey()
+	if err := s.AddAccount(name, common.Bytes2Hex(crypto.FromECDSA(other))); err != errKeyNotAuthor {
+		t.Fatalf("add account of other key err %v", err)
+	}
+	if err := s.AddAccount(name, common.Bytes2Hex(crypto.FromECDSA(priv))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Send(&SendArgs{From: common.Name("unmanaged")}); err != errAccountNotManaged {
+		t.Fatalf("send of unmanaged account err %v", err)
+	}
+
+	var hashes []common.Hash
+	for i := 0; i < 4; i++ {
+		hash, err := s.Send(&SendArgs{ActionType: types.Transfer, From: name, To: name, Amount: big.NewInt(1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonce := txNonce(backend.pool[hash]); nonce != uint64(i) {
+			t.Fatalf("tx %d nonce %d", i, nonce)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	// nonce 0 confirmed, nonce 2 dropped from the pool, nonce 1 dropped and never to be included
+	backend.chain = 1
+	delete(backend.pool, hashes[0])
+	delete(backend.pool, hashes[1])
+	delete(backend.pool, hashes[2])
+	backend.rejected[hashes[1]] = true
+	s.heal(s.accounts[name])
+
+	status, err := s.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Nonce != 4 || status.Sent != 4 || status.Resent != 1 || status.Filled != 1 || len(status.Pending) != 3 {
+		t.Fatalf("status %+v", status)
+	}
+	filler := status.Pending[0]
+	if !filler.Filler || filler.Nonce != 1 || backend.pool[filler.Hash] == nil {
+		t.Fatalf("filler %+v", filler)
+	}
+	if status.Pending[1].Hash != hashes[2] || !backend.hasTx(hashes[2]) || status.Pending[2].Hash != hashes[3] {
+		t.Fatalf("pending %+v", status.Pending)
+	}
+
+	// the key is used out of the sender, the nonce is taken from the tx pool
+	backend.chain = 7
+	backend.pool = make(map[common.Hash]*types.Transaction)
+	hash, err := s.Send(&SendArgs{ActionType: types.Transfer, From: name, To: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce := txNonce(backend.pool[hash]); nonce != 7 {
+		t.Fatalf("resynced nonce %d", nonce)
+	}
+	s.heal(s.accounts[name])
+	if status, _ := s.Status(name); status.Nonce != 8 || len(status.Pending) != 1 {
+		t.Fatalf("status after resync %+v", status)
+	}
+}
+
+func TestSenderRescue(t *testing.T) {
+	priv, _ := crypto.GenerateKey()
+	name := common.Name("managedsender")
+	acct, err := accountmanager.NewAccount(name, common.Name(""), common.BytesToPubKey(crypto.FromECDSAPub(&priv.PublicKey)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &testBackend{acct: acct, pool: make(map[common.Hash]*types.Transaction), rejected: make(map[common.Hash]bool)}
+	s := newSender(backend, params.DefaultChainconfig)
+	if err := s.AddAccount(name, common.Bytes2Hex(crypto.FromECDSA(priv))); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := s.Send(&SendArgs{ActionType: types.Transfer, From: name, To: name, Amount: big.NewInt(1), GasPrice: big.NewInt(100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Rescue(common.Hash{}, nil); err != errTxNotPending {
+		t.Fatalf("rescue unknown tx err %v", err)
+	}
+	if _, err := s.Rescue(hash, big.NewInt(110)); err != errRescuePrice {
+		t.Fatalf("rescue without price bump err %v", err)
+	}
+	rescue, err := s.Rescue(hash, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, old := backend.pool[rescue], backend.pool[hash]
+	if tx.GasPrice().Cmp(big.NewInt(111)) != 0 || txNonce(tx) != txNonce(old) {
+		t.Fatalf("rescue price %v nonce %d", tx.GasPrice(), txNonce(tx))
+	}
+	pubKeys, err := types.RecoverMultiKey(types.NewSigner(params.DefaultChainconfig.ChainID), tx.GetActions()[0], tx)
+	if err != nil || len(pubKeys) != 1 || pubKeys[0].Compare(acct.Authors[0].Owner.(common.PubKey)) != 0 {
+		t.Fatalf("rescue signer %v, %v", pubKeys, err)
+	}
+	status, err := s.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Rescued != 1 || len(status.Pending) != 1 || status.Pending[0].Hash != rescue {
+		t.Fatalf("status %+v", status)
+	}
+}
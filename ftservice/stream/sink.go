@@ -0,0 +1,239 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// publishTimeout is the timeout of a publish to the sink.
+const publishTimeout = 30 * time.Second
+
+// Sink publishes the events to the topic of an external stream.
+type Sink interface {
+	Publish(topic string, events []*Event) error
+	Close() error
+}
+
+// SinkFactory creates the sink of the config.
+type SinkFactory func(cfg *Config) (Sink, error)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = map[string]SinkFactory{
+		"kafka": newKafkaSink,
+		"nats":  newNATSSink,
+	}
+)
+
+// RegisterSink makes a sink available by the name, the plugins register their
+// sinks in their init function.
+func RegisterSink(name string, factory SinkFactory) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	if _, ok := sinks[name]; ok {
+		panic("stream: sink registered twice " + name)
+	}
+	sinks[name] = factory
+}
+
+// NewSink creates the sink of the config.
+func NewSink(cfg *Config) (Sink, error) {
+	sinksMu.RLock()
+	factory, ok := sinks[cfg.Sink]
+	sinksMu.RUnlock()
+	if !ok {
+		return nil, errStreamConfig
+	}
+	return factory(cfg)
+}
+
+// kafkaSink produces the records by the kafka rest proxy, the events are the
+// json values keyed by the account or the asset id.
+type kafkaSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newKafkaSink(cfg *Config) (Sink, error) {
+	if cfg.Endpoint == "" {
+		return nil, errStreamConfig
+	}
+	return &kafkaSink{endpoint: strings.TrimRight(cfg.Endpoint, "/"), client: &http.Client{Timeout: publishTimeout}}, nil
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value *Event `json:"value"`
+}
+
+func (k *kafkaSink) Publish(topic string, events []*Event) error {
+	records := make([]kafkaRecord, len(events))
+	for i, e := range events {
+		records[i] = kafkaRecord{Key: e.Key(), Value: e}
+	}
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	resp, err := k.client.Post(k.endpoint+"/topics/"+url.PathEscape(topic), "application/vnd.kafka.json.v2+json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("kafka produce failed: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (k *kafkaSink) Close() error { return nil }
+
+// natsSink publishes the json events to the subject by the nats client protocol,
+// the connection is dialed again after a failure.
+type natsSink struct {
+	address string
+	user    *url.Userinfo
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newNATSSink(cfg *Config) (Sink, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Scheme != "nats" || u.Host == "" {
+		return nil, errStreamConfig
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &natsSink{address: address, user: u.User}, nil
+}
+
+// connect dials the server, reads its info and sends the connect options.
+func (n *natsSink) connect() error {
+	conn, err := net.DialTimeout("tcp", n.address, publishTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(publishTimeout))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("nats unexpected greeting: %s", strings.TrimSpace(line))
+	}
+	options := map[string]interface{}{"verbose": false, "pedantic": false, "name": "ft-stream", "lang": "go", "protocol": 0}
+	if n.user != nil {
+		options["user"] = n.user.Username()
+		if password, ok := n.user.Password(); ok {
+			options["pass"] = password
+		}
+	}
+	b, err := json.Marshal(options)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", b); err != nil {
+		conn.Close()
+		return err
+	}
+	n.conn, n.r = conn, r
+	return nil
+}
+
+func (n *natsSink) Publish(topic string, events []*Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		if err := n.connect(); err != nil {
+			return err
+		}
+	}
+	if err := n.publish(topic, events); err != nil {
+		n.conn.Close()
+		n.conn, n.r = nil, nil
+		return err
+	}
+	return nil
+}
+
+// publish writes the events and a ping, the pong acknowledges the server
+// processed the messages before it.
+func (n *natsSink) publish(topic string, events []*Event) error {
+	n.conn.SetDeadline(time.Now().Add(publishTimeout))
+	w := bufio.NewWriter(n.conn)
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "PUB %s %d\r\n", topic, len(b))
+		w.Write(b)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("PING\r\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for {
+		line, err := n.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats publish failed: %s", line)
+		}
+	}
+}
+
+func (n *natsSink) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn, n.r = nil, nil
+	return err
+}
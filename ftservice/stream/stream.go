@@ -0,0 +1,446 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stream implements the streaming of the decoded balance changes, the
+// account lifecycle events and the asset supply events of the blocks to an
+// external sink, so the chain is consumed without an indexer polling the rpc.
+package stream
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/event"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/fdb"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// chainHeadChanSize is the size of channel listening to the chain head events.
+const chainHeadChanSize = 10
+
+// the kinds of the events, the sinks publish each kind to its own topic
+const (
+	KindBalance = "balance"
+	KindAccount = "account"
+	KindSupply  = "supply"
+)
+
+// the types of the events
+const (
+	BalanceCredit = "credit" // value received by the account
+	BalanceDebit  = "debit"  // value sent by the account
+
+	AccountCreated        = "created"
+	AccountUpdated        = "updated"
+	AccountAuthorsUpdated = "authorsupdated"
+
+	SupplyIssued  = "issued"
+	SupplyChanged = "changed"
+)
+
+var errStreamConfig = errors.New("stream config invalid")
+
+// Config is the sink the events are streamed to.
+type Config struct {
+	Sink          string `mapstructure:"sink"`          // "kafka", "nats" or a registered sink, empty disables the streaming
+	Endpoint      string `mapstructure:"endpoint"`      // kafka rest proxy url or nats server url
+	Topic         string `mapstructure:"topic"`         // prefix of the topics, the kind of the events is appended
+	Confirmations uint64 `mapstructure:"confirmations"` // blocks on top of a block before its events are streamed
+}
+
+// Backend is the node services the streamer uses.
+type Backend interface {
+	ChainDb() fdb.Database
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// Event is a decoded event of a block. The balance events are the value
+// transfers, the internal ones are included if the node keeps the contract logs.
+type Event struct {
+	Kind         string      `json:"kind"`
+	Type         string      `json:"type"`
+	BlockNumber  uint64      `json:"blockNumber"`
+	BlockHash    common.Hash `json:"blockHash"`
+	TxHash       common.Hash `json:"txHash,omitempty"`
+	ActionIndex  uint64      `json:"actionIndex"`
+	Internal     bool        `json:"internal,omitempty"`
+	Account      common.Name `json:"account,omitempty"`
+	Counterparty common.Name `json:"counterparty,omitempty"`
+	AssetID      uint64      `json:"assetID"`
+	Amount       *big.Int    `json:"amount,omitempty"`
+	Increased    *big.Int    `json:"increased,omitempty"`
+	Decreased    *big.Int    `json:"decreased,omitempty"`
+	Supply       *big.Int    `json:"supply,omitempty"`
+}
+
+// Key returns the key partitioning the events, the account or the asset id.
+func (e *Event) Key() string {
+	if e.Kind == KindSupply {
+		return strconv.FormatUint(e.AssetID, 10)
+	}
+	return e.Account.String()
+}
+
+// Streamer publishes the events of the canonical blocks once confirmed.
+type Streamer struct {
+	backend       Backend
+	sink          Sink
+	topic         string
+	confirmations uint64
+	next          uint64 // the next block to stream
+
+	chainHeadCh  chan *event.Event
+	chainHeadSub event.Subscription
+	wg           sync.WaitGroup
+}
+
+// New creates the streamer of the config, the streamer does nothing until started.
+func New(backend Backend, cfg *Config) (*Streamer, error) {
+	if cfg.Topic == "" {
+		return nil, errStreamConfig
+	}
+	sink, err := NewSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Streamer{
+		backend:       backend,
+		sink:          sink,
+		topic:         cfg.Topic,
+		confirmations: cfg.Confirmations,
+	}, nil
+}
+
+// Start streams the blocks after the head block.
+func (s *Streamer) Start(head *types.Header) {
+	s.next = head.Number.Uint64() + 1
+	s.chainHeadCh = make(chan *event.Event, chainHeadChanSize)
+	s.chainHeadSub = event.Subscribe(nil, s.chainHeadCh, event.ChainHeadEv, &types.Block{})
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop stops the streamer and closes the sink.
+func (s *Streamer) Stop() {
+	s.chainHeadSub.Unsubscribe()
+	s.wg.Wait()
+	if err := s.sink.Close(); err != nil {
+		log.Warn("Stream sink close failed", "err", err)
+	}
+}
+
+func (s *Streamer) loop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case ev := <-s.chainHeadCh:
+			block := ev.Data.(*types.Block)
+			if block == nil {
+				continue
+			}
+			s.update(block.NumberU64())
+			// Be unsubscribed due to system stopped
+		case <-s.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
+// update streams the canonical blocks confirmed by the head, a failed block is
+// streamed again at the next head.
+func (s *Streamer) update(head uint64) {
+	db := s.backend.ChainDb()
+	for ; s.next+s.confirmations <= head; s.next++ {
+		hash := rawdb.ReadCanonicalHash(db, s.next)
+		block := rawdb.ReadBlock(db, hash, s.next)
+		if block == nil {
+			return
+		}
+		events, err := s.blockEvents(block)
+		if err != nil {
+			log.Warn("Stream failed to decode block", "number", s.next, "err", err)
+			return
+		}
+		if err := s.publish(events); err != nil {
+			log.Warn("Stream failed to publish block", "number", s.next, "err", err)
+			return
+		}
+		log.Debug("Streamed block events", "number", s.next, "events", len(events))
+	}
+}
+
+// publish sends the events of a block grouped by kind to the topics of the kinds.
+func (s *Streamer) publish(events []*Event) error {
+	for _, kind := range []string{KindBalance, KindAccount, KindSupply} {
+		var kindEvents []*Event
+		for _, e := range events {
+			if e.Kind == kind {
+				kindEvents = append(kindEvents, e)
+			}
+		}
+		if len(kindEvents) == 0 {
+			continue
+		}
+		if err := s.sink.Publish(s.topic+"."+kind, kindEvents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blockEvents returns the events of the block, the created accounts and the
+// supply changes are read from the states of the block and its parent.
+func (s *Streamer) blockEvents(block *types.Block) ([]*Event, error) {
+	db := s.backend.ChainDb()
+	parent := rawdb.ReadHeader(db, block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, errors.New("parent block not found")
+	}
+	parentState, err := s.backend.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	parentAm, err := accountmanager.NewAccountManager(parentState)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := s.backend.StateAt(block.Root())
+	if err != nil {
+		return nil, err
+	}
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := blockActions(block, rawdb.ReadReceipts(db, block.Hash(), block.NumberU64()), rawdb.ReadDetailTxs(db, block.Hash(), block.NumberU64()))
+	base := Event{BlockNumber: block.NumberU64(), BlockHash: block.Hash()}
+	events := balanceEvents(base, actions)
+
+	created, err := createdEvents(base, parentAm, am)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, created...)
+	events = append(events, accountEvents(base, actions)...)
+
+	supply, err := supplyEvents(base, actions, parentAm, am)
+	if err != nil {
+		return nil, err
+	}
+	return append(events, supply...), nil
+}
+
+// decodedAction is a successful action or internal action of the block.
+type decodedAction struct {
+	aType       types.ActionType
+	from, to    common.Name
+	assetID     uint64
+	amount      *big.Int
+	payload     []byte
+	txHash      common.Hash
+	actionIndex uint64
+	internal    bool
+}
+
+// blockActions returns the successful actions of the block followed by their internal actions.
+func blockActions(block *types.Block, receipts []*types.Receipt, detailTxs []*types.DetailTx) []*decodedAction {
+	var actions []*decodedAction
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) {
+			break
+		}
+		for j, action := range tx.GetActions() {
+			if j >= len(receipts[i].ActionResults) || receipts[i].ActionResults[j].Status != types.ReceiptStatusSuccessful {
+				continue
+			}
+			actions = append(actions, &decodedAction{
+				aType:       action.Type(),
+				from:        action.Sender(),
+				to:          action.Recipient(),
+				assetID:     action.AssetID(),
+				amount:      action.Value(),
+				payload:     action.Data(),
+				txHash:      tx.Hash(),
+				actionIndex: uint64(j),
+			})
+			if i >= len(detailTxs) || j >= len(detailTxs[i].Actions) {
+				continue
+			}
+			for _, internal := range detailTxs[i].Actions[j].InternalActions {
+				if internal.Error != "" || internal.Action == nil {
+					continue
+				}
+				actions = append(actions, &decodedAction{
+					aType:       types.ActionType(internal.Action.Type),
+					from:        internal.Action.From,
+					to:          internal.Action.To,
+					assetID:     internal.Action.AssetID,
+					amount:      internal.Action.Amount,
+					payload:     internal.Action.Payload,
+					txHash:      tx.Hash(),
+					actionIndex: uint64(j),
+					internal:    true,
+				})
+			}
+		}
+	}
+	return actions
+}
+
+// balanceEvents returns the debit and credit events of the value transfers.
+func balanceEvents(base Event, actions []*decodedAction) []*Event {
+	var events []*Event
+	for _, a := range actions {
+		if a.amount == nil || a.amount.Sign() <= 0 || a.from == a.to {
+			continue
+		}
+		debit, credit := base, base
+		debit.Kind, debit.Type, debit.Account, debit.Counterparty = KindBalance, BalanceDebit, a.from, a.to
+		credit.Kind, credit.Type, credit.Account, credit.Counterparty = KindBalance, BalanceCredit, a.to, a.from
+		for _, e := range []*Event{&debit, &credit} {
+			e.TxHash, e.ActionIndex, e.Internal = a.txHash, a.actionIndex, a.internal
+			e.AssetID, e.Amount = a.assetID, new(big.Int).Set(a.amount)
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// createdEvents returns the accounts created in the block, by any action.
+func createdEvents(base Event, parentAm, am *accountmanager.AccountManager) ([]*Event, error) {
+	from, err := parentAm.GetAccountCounter()
+	if err != nil {
+		return nil, err
+	}
+	to, err := am.GetAccountCounter()
+	if err != nil {
+		return nil, err
+	}
+	var events []*Event
+	for id := from + 1; id <= to; id++ {
+		acct, err := am.GetAccountById(id)
+		if err != nil {
+			return nil, err
+		}
+		if acct == nil {
+			continue
+		}
+		e := base
+		e.Kind, e.Type, e.Account, e.Counterparty = KindAccount, AccountCreated, acct.GetName(), acct.GetFounder()
+		events = append(events, &e)
+	}
+	return events, nil
+}
+
+// accountEvents returns the updates of the accounts and of their authors.
+func accountEvents(base Event, actions []*decodedAction) []*Event {
+	var events []*Event
+	for _, a := range actions {
+		var eType string
+		switch a.aType {
+		case types.UpdateAccount:
+			eType = AccountUpdated
+		case types.UpdateAccountAuthor, types.ApplyAuthorUpdate, types.ExecuteRecovery:
+			eType = AccountAuthorsUpdated
+		default:
+			continue
+		}
+		e := base
+		e.Kind, e.Type, e.Account = KindAccount, eType, a.from
+		e.TxHash, e.ActionIndex, e.Internal = a.txHash, a.actionIndex, a.internal
+		// the pending update and the recovery are applied by any account
+		switch a.aType {
+		case types.ApplyAuthorUpdate:
+			var apply accountmanager.ApplyAuthorUpdateAction
+			if err := rlp.DecodeBytes(a.payload, &apply); err == nil {
+				e.Account, e.Counterparty = apply.Account, a.from
+			}
+		case types.ExecuteRecovery:
+			var recovery accountmanager.RecoveryAction
+			if err := rlp.DecodeBytes(a.payload, &recovery); err == nil {
+				e.Account, e.Counterparty = recovery.Account, a.from
+			}
+		}
+		events = append(events, &e)
+	}
+	return events
+}
+
+// supplyEvents returns the supply changes recorded in the block of the assets
+// issued, increased or destroyed by the actions.
+func supplyEvents(base Event, actions []*decodedAction, parentAm, am *accountmanager.AccountManager) ([]*Event, error) {
+	from, err := parentAm.GetAssetCount()
+	if err != nil {
+		return nil, err
+	}
+	to, err := am.GetAssetCount()
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	seen := make(map[uint64]bool)
+	add := func(id uint64) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for id := from; id < to; id++ {
+		add(id)
+	}
+	for _, a := range actions {
+		switch a.aType {
+		case types.IncreaseAsset:
+			var inc accountmanager.IncAsset
+			if err := rlp.DecodeBytes(a.payload, &inc); err == nil {
+				add(inc.AssetId)
+			}
+		case types.DestroyAsset:
+			add(a.assetID)
+		}
+	}
+
+	number := base.BlockNumber
+	var events []*Event
+	for _, id := range ids {
+		history, err := am.GetAssetSupplyHistory(id, number, number)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range history {
+			if change.Number != number {
+				continue
+			}
+			e := base
+			e.Kind, e.Type, e.AssetID = KindSupply, SupplyChanged, id
+			if id >= from {
+				e.Type = SupplyIssued
+			}
+			e.Increased, e.Decreased, e.Supply = change.Increased, change.Decreased, change.Supply
+			events = append(events, &e)
+		}
+	}
+	return events, nil
+}
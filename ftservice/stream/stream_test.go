@@ -0,0 +1,224 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestNewSink(t *testing.T) {
+	for _, cfg := range []*Config{
+		{Sink: "rabbitmq", Endpoint: "http://localhost", Topic: "fractal"},
+		{Sink: "kafka", Topic: "fractal"},
+		{Sink: "nats", Endpoint: "http://localhost:4222", Topic: "fractal"},
+	} {
+		if _, err := NewSink(cfg); err != errStreamConfig {
+			t.Fatalf("config %v err %v", cfg, err)
+		}
+	}
+	if _, err := New(nil, &Config{Sink: "kafka", Endpoint: "http://localhost"}); err != errStreamConfig {
+		t.Fatalf("empty topic err %v", err)
+	}
+}
+
+type testSink struct {
+	published map[string][]*Event
+}
+
+func (s *testSink) Publish(topic string, events []*Event) error {
+	s.published[topic] = append(s.published[topic], events...)
+	return nil
+}
+
+func (s *testSink) Close() error { return nil }
+
+func TestRegisterSink(t *testing.T) {
+	sink := &testSink{published: make(map[string][]*Event)}
+	RegisterSink("testsink", func(cfg *Config) (Sink, error) { return sink, nil })
+	defer func() {
+		sinksMu.Lock()
+		delete(sinks, "testsink")
+		sinksMu.Unlock()
+	}()
+	s, err := New(nil, &Config{Sink: "testsink", Topic: "fractal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []*Event{
+		{Kind: KindBalance, Type: BalanceDebit, Account: common.Name("alice")},
+		{Kind: KindSupply, Type: SupplyIssued, AssetID: 3},
+		{Kind: KindBalance, Type: BalanceCredit, Account: common.Name("bob")},
+	}
+	if err := s.publish(events); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.published["fractal.balance"]) != 2 || len(sink.published["fractal.supply"]) != 1 || len(sink.published["fractal.account"]) != 0 {
+		t.Fatalf("published %v", sink.published)
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/fractal.balance" || r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Records []struct {
+				Key   string `json:"key"`
+				Value *Event `json:"value"`
+			} `json:"records"`
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil || len(body.Records) != 1 || body.Records[0].Key != "alice" || body.Records[0].Value.Amount.Cmp(big.NewInt(7)) != 0 {
+			http.Error(w, "bad records", http.StatusUnprocessableEntity)
+			return
+		}
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(&Config{Sink: "kafka", Endpoint: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &Event{Kind: KindBalance, Type: BalanceDebit, Account: common.Name("alice"), Amount: big.NewInt(7)}
+	if err := sink.Publish("fractal.balance", []*Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Publish("fractal.account", []*Event{event}); err == nil {
+		t.Fatal("publish to unknown topic succeeded")
+	}
+}
+
+// serveNATS accepts a connection and sends the payloads of the published messages.
+func serveNATS(l net.Listener, messages chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte("INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n"))
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "CONNECT":
+		case fields[0] == "PUB" && len(fields) == 3:
+			size, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			messages <- fields[1] + " " + string(payload[:size])
+		case fields[0] == "PING":
+			conn.Write([]byte("PONG\r\n"))
+		default:
+			conn.Write([]byte("-ERR 'Unknown Protocol Operation'\r\n"))
+		}
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	messages := make(chan string, 10)
+	go serveNATS(l, messages)
+
+	sink, err := NewSink(&Config{Sink: "nats", Endpoint: "nats://" + l.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	events := []*Event{
+		{Kind: KindAccount, Type: AccountCreated, Account: common.Name("alice")},
+		{Kind: KindAccount, Type: AccountUpdated, Account: common.Name("bob")},
+	}
+	if err := sink.Publish("fractal.account", events); err != nil {
+		t.Fatal(err)
+	}
+	// the pong acknowledges the messages, they are received already
+	for _, want := range []string{"alice", "bob"} {
+		select {
+		case msg := <-messages:
+			var e Event
+			parts := strings.SplitN(msg, " ", 2)
+			if parts[0] != "fractal.account" || json.Unmarshal([]byte(parts[1]), &e) != nil || e.Account.String() != want {
+				t.Fatalf("message %v, want account %v", msg, want)
+			}
+		default:
+			t.Fatalf("message of %v not received", want)
+		}
+	}
+}
+
+func TestBlockEvents(t *testing.T) {
+	recovery, _ := rlp.EncodeToBytes(&accountmanager.RecoveryAction{Account: common.Name("alice")})
+	actions := []*decodedAction{
+		{aType: types.Transfer, from: common.Name("alice"), to: common.Name("bob"), assetID: 1, amount: big.NewInt(5)},
+		{aType: types.CallContract, from: common.Name("bob"), to: common.Name("dex"), amount: big.NewInt(0)},
+		{aType: types.Transfer, from: common.Name("dex"), to: common.Name("carol"), assetID: 2, amount: big.NewInt(3), internal: true},
+		{aType: types.UpdateAccount, from: common.Name("bob"), amount: big.NewInt(0)},
+		{aType: types.ExecuteRecovery, from: common.Name("guardian"), amount: big.NewInt(0), payload: recovery},
+	}
+	base := Event{BlockNumber: 10}
+
+	balances := balanceEvents(base, actions)
+	if len(balances) != 4 {
+		t.Fatalf("balance events %d, want 4", len(balances))
+	}
+	if e := balances[0]; e.Type != BalanceDebit || e.Account != "alice" || e.Counterparty != "bob" || e.Amount.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("debit event %+v", e)
+	}
+	if e := balances[3]; e.Type != BalanceCredit || e.Account != "carol" || !e.Internal || e.AssetID != 2 {
+		t.Fatalf("internal credit event %+v", e)
+	}
+
+	accounts := accountEvents(base, actions)
+	if len(accounts) != 2 {
+		t.Fatalf("account events %d, want 2", len(accounts))
+	}
+	if e := accounts[0]; e.Type != AccountUpdated || e.Account != "bob" {
+		t.Fatalf("update event %+v", e)
+	}
+	if e := accounts[1]; e.Type != AccountAuthorsUpdated || e.Account != "alice" || e.Counterparty != "guardian" {
+		t.Fatalf("recovery event %+v", e)
+	}
+}
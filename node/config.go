@@ -58,6 +58,9 @@ type Config struct {
 	WSOrigins   []string `mapstructure:"wsorigins"`
 	WSExposeAll bool     `mapstructure:"wsexposall"`
 
+	// RPC calls taking longer than RPCSlowQuery milliseconds are logged, 0 disables the log
+	RPCSlowQuery uint64 `mapstructure:"rpcslowquery"`
+
 	// p2p
 	P2PBootNodes   string      `mapstructure:"bootnodes"`
 	P2PStaticNodes string      `mapstructure:"staticnodes"`
@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	router "github.com/fractalplatform/fractal/event"
@@ -309,6 +310,11 @@ func (n *Node) startRPCServices(services map[reflect.Type]Service) error {
 	return nil
 }
 
+// slowQueryThreshold returns the duration above which rpc calls are logged.
+func (n *Node) slowQueryThreshold() time.Duration {
+	return time.Duration(n.config.RPCSlowQuery) * time.Millisecond
+}
+
 // TODO apis returns the collection of RPC descriptors this node offers
 func (n *Node) apis() []rpc.API {
 	return []rpc.API{}
@@ -323,6 +329,7 @@ func (n *Node) startIPC(apis []rpc.API) error {
 	if err != nil {
 		return err
 	}
+	handler.SetSlowQueryThreshold(n.slowQueryThreshold())
 	n.ipcListener = listener
 	n.ipcHandler = handler
 	n.log.Info("IPC endpoint opened", "url", n.ipcEndpoint)
@@ -354,6 +361,7 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	}
 	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
 	// All listeners booted successfully
+	handler.SetSlowQueryThreshold(n.slowQueryThreshold())
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
 	n.httpHandler = handler
@@ -385,6 +393,7 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	}
 	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
 	// All listeners booted successfully
+	handler.SetSlowQueryThreshold(n.slowQueryThreshold())
 	n.wsEndpoint = endpoint
 	n.wsListener = listener
 	n.wsHandler = handler
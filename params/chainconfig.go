@@ -32,6 +32,16 @@ type ChainConfig struct {
 	ChargeCfg        *ChargeConfig `json:"chargeParams"`
 	ForkedCfg        *FrokedConfig `json:"upgradeParams"`
 	DposCfg          *DposConfig   `json:"dposParams"`
+	RentCfg          *RentConfig   `json:"rentParams,omitempty"`
+	AuthorCfg        *AuthorConfig `json:"authorParams,omitempty"`
+	NamePriceCfg     *PriceConfig  `json:"namePriceParams,omitempty"`
+	ReservedCfg      *ReservedName `json:"reservedNameParams,omitempty"`
+	FeeCfg           *FeeConfig    `json:"feeMarketParams,omitempty"`
+	PartitionCfg     *Partitioning `json:"partitionParams,omitempty"`
+	ScheduleCfg      *Scheduler    `json:"scheduleParams,omitempty"`
+	RemarkCfg        *RemarkConfig `json:"remarkParams,omitempty"`
+	BlockSizeCfg     *BlockSize    `json:"blockSizeParams,omitempty"`
+	Engine           string        `json:"engine,omitempty"`
 	SysName          string        `json:"systemName"`  // system name
 	AccountName      string        `json:"accountName"` // account name
 	AssetName        string        `json:"assetName"`   // asset name
@@ -56,6 +66,64 @@ type NameConfig struct {
 	MainMaxLength uint64 `json:"mainmaxlength"`
 	SubMinLength  uint64 `json:"subminLength"`
 	SubMaxLength  uint64 `json:"submaxLength"`
+	ReclaimBlocks uint64 `json:"reclaimBlocks,omitempty"` // blocks a destroyed name is retained, 0 disable reclaim
+	AllowReuse    bool   `json:"allowReuse,omitempty"`    // reclaimed name can be created again
+}
+
+type RentConfig struct {
+	StorageQuota uint64   `json:"storageQuota"` // free state bytes per account, 0 disable rent
+	PricePerByte *big.Int `json:"pricePerByte"` // system token charged per byte grown beyond quota, zero restrict the growth
+}
+
+// AuthorConfig the author limits of the accounts, they apply from ForkID4, the
+// earlier blocks keep the default limits.
+type AuthorConfig struct {
+	MaxSignDepth  uint64 `json:"maxSignDepth"`       // max depth of the sign index
+	MaxSignLength uint64 `json:"maxSignLength"`      // max signatures of an action
+	MaxAuthorNum  uint64 `json:"maxAuthorNum"`       // max authors of an account
+	AllowBLS      bool   `json:"allowBLS,omitempty"` // BLS keys can be authors signing by an aggregated signature
+}
+
+type ReservedName struct {
+	Names    []string `json:"names"`    // account names only the system account can create
+	Prefixes []string `json:"prefixes"` // main account names with these prefixes only the system account can create
+}
+
+type PriceConfig struct {
+	CreateFee     *big.Int `json:"createFee"`     // system token charged for creating a main account
+	PremiumLength uint64   `json:"premiumLength"` // main names shorter than it must be won by auction, 0 disable auction
+	MinBid        *big.Int `json:"minBid"`        // min system token of the first bid
+	AuctionBlocks uint64   `json:"auctionBlocks"` // blocks the auction is open since the first bid
+	ClaimBlocks   uint64   `json:"claimBlocks"`   // blocks the winner can claim the name after the auction, 0 no deadline
+}
+
+// FeeConfig the fee market since ForkID4, the blocks carry a base fee from the
+// fork on. A chain without the config starts from the zero base fee and burns it.
+type FeeConfig struct {
+	InitialBaseFee    *big.Int `json:"initialBaseFee"`    // base fee of the first block of the fork
+	MinBaseFee        *big.Int `json:"minBaseFee"`        // lower bound of the base fee
+	TargetPercent     uint64   `json:"targetPercent"`     // percent of the gas limit the base fee targets
+	ChangeDenominator uint64   `json:"changeDenominator"` // bounds the base fee change of a block to 1/denominator
+	Treasury          string   `json:"treasury"`          // account receiving the base fee, empty burn the base fee
+}
+
+type Partitioning struct {
+	Count uint64 `json:"count"` // partitions of the account state, less than 2 disable the partitioning
+}
+
+type Scheduler struct {
+	Fee         *big.Int `json:"fee"`         // system token charged per scheduled action, paid to the producer executing it
+	BlockBudget uint64   `json:"blockBudget"` // max scheduled actions executed by a block, 0 disable the scheduler
+	MaxDelay    uint64   `json:"maxDelay"`    // max blocks an action is scheduled ahead, 0 unlimited
+}
+
+type RemarkConfig struct {
+	MaxSize    uint64 `json:"maxSize"`    // max bytes of the action remark, the memo of the transfers, 0 unlimited
+	GasPerByte uint64 `json:"gasPerByte"` // gas charged per remark byte in place of the data gas
+}
+
+type BlockSize struct {
+	MaxSize uint64 `json:"maxSize"` // max bytes of the serialized block, 0 unlimited
 }
 
 type FrokedConfig struct {
@@ -136,6 +204,88 @@ var DefaultChainconfig = &ChainConfig{
 	SysToken:         "ftoken",
 }
 
+// AuthorLimit returns the author limits of the chain, unset limits use the default value.
+func (cfg *ChainConfig) AuthorLimit() *AuthorConfig {
+	limit := &AuthorConfig{
+		MaxSignDepth:  MaxSignDepth,
+		MaxSignLength: MaxSignLength,
+		MaxAuthorNum:  MaxAuthorNum,
+	}
+	if cfg.AuthorCfg == nil {
+		return limit
+	}
+	if cfg.AuthorCfg.MaxSignDepth != 0 {
+		limit.MaxSignDepth = cfg.AuthorCfg.MaxSignDepth
+	}
+	if cfg.AuthorCfg.MaxSignLength != 0 {
+		limit.MaxSignLength = cfg.AuthorCfg.MaxSignLength
+	}
+	if cfg.AuthorCfg.MaxAuthorNum != 0 {
+		limit.MaxAuthorNum = cfg.AuthorCfg.MaxAuthorNum
+	}
+	limit.AllowBLS = cfg.AuthorCfg.AllowBLS
+	return limit
+}
+
+// Target returns the gas of the block the base fee targets.
+func (c *FeeConfig) Target(gasLimit uint64) uint64 {
+	percent := c.TargetPercent
+	if percent == 0 || percent > 100 {
+		percent = BaseFeeTargetPercent
+	}
+	return gasLimit * percent / 100
+}
+
+// Denominator returns the bound divisor of the base fee change.
+func (c *FeeConfig) Denominator() uint64 {
+	if c.ChangeDenominator == 0 {
+		return BaseFeeChangeDenominator
+	}
+	return c.ChangeDenominator
+}
+
+// IsFeeMarket returns whether the blocks of the fork id carry a base fee.
+func (cfg *ChainConfig) IsFeeMarket(forkID uint64) bool {
+	return forkID >= ForkID4
+}
+
+// FeeMarket returns the fee market config of the chain, the default one if unset.
+func (cfg *ChainConfig) FeeMarket() *FeeConfig {
+	if cfg.FeeCfg == nil {
+		return &FeeConfig{}
+	}
+	return cfg.FeeCfg
+}
+
+// Partitions returns the number of the account partitions, 1 if the partitioning is disabled.
+func (cfg *ChainConfig) Partitions() uint64 {
+	if cfg.PartitionCfg == nil || cfg.PartitionCfg.Count < 2 {
+		return 1
+	}
+	return cfg.PartitionCfg.Count
+}
+
+// IsScheduler returns whether the actions can be scheduled for the future blocks.
+func (cfg *ChainConfig) IsScheduler() bool {
+	return cfg.ScheduleCfg != nil && cfg.ScheduleCfg.BlockBudget != 0
+}
+
+// MaxBlockSize returns the max bytes of the serialized block, 0 if unlimited.
+func (cfg *ChainConfig) MaxBlockSize() uint64 {
+	if cfg.BlockSizeCfg == nil {
+		return 0
+	}
+	return cfg.BlockSizeCfg.MaxSize
+}
+
+// EngineName returns the name of the consensus engine of the chain, the default engine if the config names none.
+func (cfg *ChainConfig) EngineName() string {
+	if cfg.Engine == "" {
+		return DefaultEngine
+	}
+	return cfg.Engine
+}
+
 func (cfg *ChainConfig) Copy() *ChainConfig {
 	bts, _ := json.Marshal(cfg)
 	c := &ChainConfig{}
@@ -143,6 +293,9 @@ func (cfg *ChainConfig) Copy() *ChainConfig {
 	return c
 }
 
+// DefaultEngine is the consensus engine of the chains not naming one.
+const DefaultEngine = "dpos"
+
 const (
 	//ForkID0 init
 	ForkID0 = uint64(0)
@@ -152,7 +305,9 @@ const (
 	ForkID2 = uint64(2)
 	//ForkID3 dpos config candidateAvailableMinQuantity modified
 	ForkID3 = uint64(3)
+	//ForkID4 account balances stored under per-asset keys, storage rent charged to the action sender, block transitions run by the processor
+	ForkID4 = uint64(4)
 
 	// NextForkID is the id of next fork
-	NextForkID uint64 = ForkID3
+	NextForkID uint64 = ForkID4
 )
@@ -35,6 +35,8 @@ const (
 	MaxCodeSize uint64 = 24576     // Maximum bytecode to permit for a contract
 	MaxTxSize   uint64 = 32 * 1024 // Heuristic limit, reject transactions over 32KB to prfeed DOS attacks
 
+	MaxReturnDataSize uint64 = 1024 // Max bytes of the contract return data kept in the action results
+
 	// Precompiled contract gas prices
 
 	EcrecoverGas            uint64 = 3000   // Elliptic curve sender recovery gas price
@@ -49,6 +51,13 @@ const (
 	Bn256ScalarMulGas       uint64 = 40000  // Gas needed for an elliptic curve scalar multiplication
 	Bn256PairingBaseGas     uint64 = 100000 // Base price for an elliptic curve pairing check
 	Bn256PairingPerPointGas uint64 = 80000  // Per-point price for an elliptic curve pairing check
+	NamedRecordGetGas       uint64 = 800    // Base price for reading a named record of the contract account
+	NamedRecordSetGas       uint64 = 20000  // Base price for writing a named record of the contract account
+	NamedRecordPerByteGas   uint64 = 200    // Per-byte price for the key and the value of a written named record
+	AcceptAssetOwnerGas     uint64 = 20000  // Price for a contract accepting the ownership of an asset
+	AssetVerifierGas        uint64 = 100000 // Max gas of the call consulting the verification contract of an asset
+	BLSAggregateGas         uint64 = 120000 // Base price for the pairing check of an aggregated BLS signature
+	BLSAuthorGas            uint64 = 2000   // Per-author price for an aggregated BLS signature
 )
 
 var (
@@ -58,12 +67,25 @@ var (
 	DurationLimit          = big.NewInt(13)     // The decision boundary on the blocktime duration used to determine whether difficulty should go up or not.
 )
 
+// default author limits, they also apply before ForkID4, see ChainConfig.AuthorLimit
 const (
 	MaxSignDepth  = uint64(10)
 	MaxSignLength = uint64(50)
 	MaxAuthorNum  = uint64(10)
 )
 
+// default fee market parameters, see FeeConfig
+const (
+	BaseFeeTargetPercent     = uint64(50)
+	BaseFeeChangeDenominator = uint64(8)
+)
+
+// asset transfer fee rates are in basis points of the transferred amount
+const (
+	TransferFeeRateDenominator = uint64(10000)
+	MaxAssetTransferFeeRate    = uint64(1000)
+)
+
 //type for fee
 const (
 	AssetFeeType    = uint64(0)
@@ -51,6 +51,22 @@ var (
 	// one present in the local chain.
 	ErrNonceTooLow = errors.New("nonce too low")
 
+	// ErrGasPriceBelowBaseFee is returned if the gas price of a transaction is lower
+	// than the base fee of the block.
+	ErrGasPriceBelowBaseFee = errors.New("gas price below the base fee")
+
+	// ErrBlockTooLarge is returned if the serialized size of a block exceeds the
+	// max block size of the chain config.
+	ErrBlockTooLarge = errors.New("block size exceeds the limit")
+
+	// ErrActionReverted is the failure of an action succeeded but reverted by the
+	// atomic failure policy of its transaction.
+	ErrActionReverted = errors.New("action reverted by the transaction failure policy")
+
+	// ErrActionSkipped is the failure of an action not executed by the prefix
+	// failure policy of its transaction.
+	ErrActionSkipped = errors.New("action skipped by the transaction failure policy")
+
 	errZeroBlockTime = errors.New("timestamp equals parent's")
 
 	errParentBlock = errors.New("parent block not exist")
@@ -117,6 +117,7 @@ func NewEVMContext(sender common.Name, to common.Name, assetID uint64, gasPrice
 		Difficulty:              new(big.Int).Set(header.Difficulty),
 		GasLimit:                header.GasLimit,
 		GasPrice:                new(big.Int).Set(gasPrice),
+		BaseFee:                 header.GetBaseFee(),
 	}
 }
 
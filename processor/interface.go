@@ -42,4 +42,6 @@ type Validator interface {
 type Processor interface {
 	Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) ([]*types.Receipt, []*types.Log, uint64, error)
 	ApplyTransaction(author *common.Name, gp *common.GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, uint64, error)
+	ApplyBlockTransitions(statedb *state.StateDB, header *types.Header) error
+	GetExecStats(hash common.Hash) *types.ExecStats
 }
@@ -21,31 +21,48 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/consensus"
+	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/processor/vm"
 	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/types"
 )
 
+// execStatsLimit is the number of recent blocks keep the execution statistics.
+const execStatsLimit = 256
+
 // StateProcessor is a basic Processor, which takes care of transitioning
 // state from one point to another.
 //
 // StateProcessor implements Processor.
 type StateProcessor struct {
-	bc     ChainContext      // Canonical block chain
-	engine consensus.IEngine // Consensus engine used for block rewards
+	bc        ChainContext      // Canonical block chain
+	engine    consensus.IEngine // Consensus engine used for block rewards
+	execStats *lru.Cache        // Execution statistics of the recent processed blocks
 }
 
 // NewStateProcessor initialises a new StateProcessor.
 func NewStateProcessor(bc ChainContext, engine consensus.IEngine) *StateProcessor {
+	execStats, _ := lru.New(execStatsLimit)
 	return &StateProcessor{
-		bc:     bc,
-		engine: engine,
+		bc:        bc,
+		engine:    engine,
+		execStats: execStats,
 	}
 }
 
+// GetExecStats returns the execution statistics of the recent processed block.
+func (p *StateProcessor) GetExecStats(hash common.Hash) *types.ExecStats {
+	if stats, ok := p.execStats.Get(hash); ok {
+		return stats.(*types.ExecStats)
+	}
+	return nil
+}
+
 // Process processes the state changes according to the rules by running
 // the transaction messages using the statedb and applying any rewards to both
 // the processor (coinbase) and any included uncles.
@@ -60,15 +77,23 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		header   = block.Header()
 		allLogs  []*types.Log
 		gp       = new(common.GasPool).AddGas(block.GasLimit())
+		stats    = types.NewExecStats(block.NumberU64(), block.Hash())
+		start    = time.Now()
 	)
 
 	// Prepare the block, applying any consensus engine specific extras (e.g. update last)
 	p.engine.Prepare(p.bc, header, block.Transactions(), receipts, statedb)
 
+	// Share the account manager across the block so its account cache is reused
+	accountDB, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
 		statedb.Prepare(tx.Hash(), block.Hash(), i)
-		receipt, _, err := p.ApplyTransaction(nil, gp, statedb, header, tx, usedGas, cfg)
+		receipt, _, err := p.applyTransaction(nil, gp, accountDB, statedb, header, tx, usedGas, cfg, stats)
 		if err != nil {
 			return nil, nil, 0, err
 		}
@@ -76,9 +101,19 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		allLogs = append(allLogs, receipt.Logs...)
 	}
 
+	// Apply the block transitions of the account and asset managers
+	if err := p.ApplyBlockTransitions(statedb, header); err != nil {
+		return nil, nil, 0, err
+	}
+
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	p.engine.Finalize(p.bc, header, block.Transactions(), receipts, statedb)
 
+	stats.TxCount = uint64(len(block.Transactions()))
+	stats.Duration = time.Since(start)
+	stats.StateReads, stats.StateWrites, stats.StateCacheHits = statedb.AccessStats()
+	p.execStats.Add(block.Hash(), stats)
+
 	return receipts, allLogs, *usedGas, nil
 }
 
@@ -87,42 +122,134 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
 func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, uint64, error) {
-	bc := p.bc
-	config := bc.Config()
 	accountDB, err := accountmanager.NewAccountManager(statedb)
 	if err != nil {
 		return nil, 0, err
 	}
+	return p.applyTransaction(author, gp, accountDB, statedb, header, tx, usedGas, cfg, nil)
+}
+
+// ApplyBlockTransitions applies the state transitions due at the end of the block
+// from ForkID4: the asset distributions, the scheduled actions, the redenominations
+// and the supply history. The supply changed by the engine when it finalizes the
+// block is recorded with the next block.
+func (p *StateProcessor) ApplyBlockTransitions(statedb *state.StateDB, header *types.Header) error {
+	if header.CurForkID() < params.ForkID4 {
+		return nil
+	}
+	config := p.bc.Config()
+	accountDB, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return err
+	}
+	// pay the pending asset distributions in bounded chunks
+	if err := accountDB.ProcessDistributions(config, header.Number.Uint64()); err != nil {
+		return err
+	}
+	// execute the scheduled actions due at the block within the block budget
+	if err := accountDB.ProcessScheduled(config, header); err != nil {
+		return err
+	}
+	// rescale the holders of the redenominating assets
+	if err := accountDB.ProcessRedenominations(accountmanager.MaxRedenominateHolders); err != nil {
+		return err
+	}
+	// record the supply changes of the block
+	return asset.NewAsset(statedb).RecordSupplyHistory(header.Number.Uint64())
+}
 
+func (p *StateProcessor) applyTransaction(author *common.Name, gp *common.GasPool, accountDB *accountmanager.AccountManager, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config, stats *types.ExecStats) (*types.Receipt, uint64, error) {
 	// todo for the moment，only system asset
 	// assetID := tx.GasAssetID()
 	assetID := p.bc.Config().SysTokenID
 	if assetID != tx.GasAssetID() {
 		return nil, 0, fmt.Errorf("only support system asset %d as tx fee", p.bc.Config().SysTokenID)
 	}
-	gasPrice := tx.GasPrice()
-	//timer for vm exec overtime
-	var t *time.Timer
-	//
-	var totalGas uint64
-	var ios []*types.ActionResult
+	// the actions of a type not forked yet fail as actions, the pool and the miner reject them
+	if err := tx.CheckForkFields(header.CurForkID()); err != nil {
+		return nil, 0, err
+	}
+	if tx.Expired(header.Number.Uint64(), header.Time.Uint64()/uint64(time.Second)) {
+		return nil, 0, types.ErrTxExpired
+	}
+	snap := statedb.Snapshot()
+	ios, detailActions, totalGas, err := p.applyActions(author, gp, accountDB, statedb, header, tx, usedGas, cfg, stats, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tx.FailurePolicy() == types.PolicyAtomic && anyFailed(tx, ios) {
+		// Revert the effects of all the actions, the gas they used stays charged
+		statedb.RevertToSnapshot(snap)
+		accountDB.ResetAuthorCache()
+		gp.AddGas(totalGas)
+		*usedGas -= totalGas
+		settled := ios
+		if ios, detailActions, totalGas, err = p.applyActions(author, gp, accountDB, statedb, header, tx, usedGas, cfg, nil, settled); err != nil {
+			return nil, 0, err
+		}
+		for i, result := range ios {
+			if settled[i].Status == types.ReceiptStatusFailed {
+				result.Status, result.Error = settled[i].Status, settled[i].Error
+			} else {
+				result.Status = types.ReceiptStatusReverted
+			}
+		}
+	}
 	detailTx := &types.DetailTx{}
-	var detailActions []*types.DetailAction
+	root := statedb.ReceiptRoot()
+	receipt := types.NewReceipt(root[:], *usedGas, totalGas)
+	receipt.TxHash = tx.Hash()
+	receipt.ActionResults = ios
+	// Set the receipt logs and create a bloom for filtering
+	receipt.Logs = statedb.GetLogs(tx.Hash())
+	receipt.Bloom = types.CreateBloom([]*types.Receipt{receipt})
+
+	detailTx.TxHash = receipt.TxHash
+	detailTx.Actions = detailActions
+	detailTx.SetInternalActionIDs()
+	receipt.SetInternalTxsLog(detailTx)
+	return receipt, totalGas, nil
+}
+
+// applyActions applies the actions of the transaction in order. By the prefix
+// failure policy the actions following a failure are skipped, the results of
+// a reverted execution are settled by charging their gas without executing
+// the actions again.
+func (p *StateProcessor) applyActions(author *common.Name, gp *common.GasPool, accountDB *accountmanager.AccountManager, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config, stats *types.ExecStats, settled []*types.ActionResult) ([]*types.ActionResult, []*types.DetailAction, uint64, error) {
+	var (
+		config   = p.bc.Config()
+		assetID  = config.SysTokenID
+		gasPrice = tx.GasPrice()
+		//timer for vm exec overtime
+		t             *time.Timer
+		totalGas      uint64
+		ios           []*types.ActionResult
+		detailActions []*types.DetailAction
+		failure       bool
+	)
 	for i, action := range tx.GetActions() {
+		actionStart := time.Now()
 		if needCheckSign(accountDB, action) {
-			if err := accountDB.RecoverTx(types.NewSigner(config.ChainID), tx); err != nil {
-				return nil, 0, err
+			if err := accountDB.RecoverTx(types.MakeSigner(config, header.CurForkID()), tx); err != nil {
+				return nil, nil, 0, err
 			}
+			if stats != nil {
+				for _, a := range tx.GetActions() {
+					stats.SignVerifies += uint64(len(a.GetSign()))
+				}
+			}
+		} else if stats != nil {
+			stats.AuthorCacheHits++
 		}
 
 		nonce, err := accountDB.GetNonce(action.Sender())
 		if err != nil {
-			return nil, 0, err
+			return nil, nil, 0, err
 		}
 		if nonce < action.Nonce() {
-			return nil, 0, ErrNonceTooHigh
+			return nil, nil, 0, ErrNonceTooHigh
 		} else if nonce > action.Nonce() {
-			return nil, 0, ErrNonceTooLow
+			return nil, nil, 0, ErrNonceTooLow
 		}
 
 		evmcontext := &EvmContext{
@@ -132,34 +259,61 @@ func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPoo
 		context := NewEVMContext(action.Sender(), action.Recipient(), assetID, tx.GasPrice(), header, evmcontext, author)
 		vmenv := vm.NewEVM(context, accountDB, statedb, config, cfg)
 
-		//will abort the vm if overtime
-		if false == cfg.EndTime.IsZero() {
-			t = time.AfterFunc(cfg.EndTime.Sub(time.Now()), func() {
-				vmenv.OverTimeAbort()
-			})
+		var (
+			skip   error
+			charge uint64
+		)
+		switch {
+		case settled != nil:
+			skip, charge = ErrActionReverted, settled[i].GasUsed
+		case failure && tx.FailurePolicy() == types.PolicyPrefix:
+			skip = ErrActionSkipped
 		}
 
-		_, gas, failed, err, vmerr := ApplyMessage(accountDB, vmenv, action, gp, gasPrice, assetID, config, p.engine)
+		var (
+			gas    uint64
+			failed bool
+			vmerr  error
+		)
+		if skip != nil {
+			gas, err = SettleMessage(accountDB, vmenv, action, tx.GasPayer(action), gp, gasPrice, assetID, config, p.engine, charge, skip)
+			failed, vmerr = true, skip
+		} else {
+			//will abort the vm if overtime
+			if false == cfg.EndTime.IsZero() {
+				t = time.AfterFunc(cfg.EndTime.Sub(time.Now()), func() {
+					vmenv.OverTimeAbort()
+				})
+			}
 
-		if false == cfg.EndTime.IsZero() {
-			//close timer
-			t.Stop()
+			_, gas, failed, err, vmerr = ApplyMessage(accountDB, vmenv, action, tx.GasPayer(action), gp, gasPrice, assetID, config, p.engine)
+
+			if false == cfg.EndTime.IsZero() {
+				//close timer
+				t.Stop()
+			}
 		}
 
 		if err != nil {
-			return nil, 0, err
+			return nil, nil, 0, err
+		}
+		if stats != nil {
+			stats.AddAction(action.Type(), time.Since(actionStart))
 		}
 
 		*usedGas += gas
 		totalGas += gas
 
 		var status uint64
-		if failed {
+		switch {
+		case skip == ErrActionSkipped:
+			status = types.ReceiptStatusSkipped
+		case failed:
 			status = types.ReceiptStatusFailed
-		} else {
+		default:
 			status = types.ReceiptStatusSuccessful
-
 		}
+		failure = failure || (failed && !action.ContinueOnError())
 		vmerrstr := ""
 		if vmerr != nil {
 			vmerrstr = vmerr.Error()
@@ -169,21 +323,22 @@ func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPoo
 		for key, gas := range vmenv.FounderGasMap {
 			gasAllot = append(gasAllot, &types.GasDistribution{Account: key.ObjectName.String(), Gas: uint64(gas.Value), TypeID: gas.TypeID})
 		}
-		ios = append(ios, &types.ActionResult{Status: status, Index: uint64(i), GasUsed: gas, GasAllot: gasAllot, Error: vmerrstr})
+		result := &types.ActionResult{Status: status, Index: uint64(i), GasUsed: gas, GasAllot: gasAllot, Error: vmerrstr}
+		result.SetOutput(vmenv.Output)
+		ios = append(ios, result)
 		detailActions = append(detailActions, &types.DetailAction{InternalActions: vmenv.InternalTxs})
 	}
-	root := statedb.ReceiptRoot()
-	receipt := types.NewReceipt(root[:], *usedGas, totalGas)
-	receipt.TxHash = tx.Hash()
-	receipt.ActionResults = ios
-	// Set the receipt logs and create a bloom for filtering
-	receipt.Logs = statedb.GetLogs(tx.Hash())
-	receipt.Bloom = types.CreateBloom([]*types.Receipt{receipt})
+	return ios, detailActions, totalGas, nil
+}
 
-	detailTx.TxHash = receipt.TxHash
-	detailTx.Actions = detailActions
-	receipt.SetInternalTxsLog(detailTx)
-	return receipt, totalGas, nil
+// anyFailed returns whether an action failed without tolerating its failure.
+func anyFailed(tx *types.Transaction, results []*types.ActionResult) bool {
+	for i, result := range results {
+		if result.Status == types.ReceiptStatusFailed && !tx.GetActions()[i].ContinueOnError() {
+			return true
+		}
+	}
+	return false
 }
 
 func needCheckSign(accountDB *accountmanager.AccountManager, action *types.Action) bool {
@@ -39,11 +39,14 @@ var (
 type StateTransition struct {
 	engine      EngineContext
 	from        common.Name
+	payer       common.Name // account paying the gas, the sender unless sponsored
 	gp          *common.GasPool
 	action      *types.Action
 	gas         uint64
 	initialGas  uint64
 	gasPrice    *big.Int
+	skip        error  // reason the action is charged without execution
+	charge      uint64 // gas charged to the skipped action, at least its intrinsic gas
 	assetID     uint64
 	account     *accountmanager.AccountManager
 	evm         *vm.EVM
@@ -52,11 +55,12 @@ type StateTransition struct {
 
 // NewStateTransition initialises and returns a new state transition object.
 func NewStateTransition(accountDB *accountmanager.AccountManager, evm *vm.EVM,
-	action *types.Action, gp *common.GasPool, gasPrice *big.Int, assetID uint64,
+	action *types.Action, payer common.Name, gp *common.GasPool, gasPrice *big.Int, assetID uint64,
 	config *params.ChainConfig, engine EngineContext) *StateTransition {
 	return &StateTransition{
 		engine:      engine,
 		from:        action.Sender(),
+		payer:       payer,
 		gp:          gp,
 		evm:         evm,
 		action:      action,
@@ -68,13 +72,28 @@ func NewStateTransition(accountDB *accountmanager.AccountManager, evm *vm.EVM,
 }
 
 // ApplyMessage computes the new state by applying the given message against the old state within the environment.
+// The gas is bought from and refunded to the payer.
 func ApplyMessage(accountDB *accountmanager.AccountManager, evm *vm.EVM,
-	action *types.Action, gp *common.GasPool, gasPrice *big.Int,
+	action *types.Action, payer common.Name, gp *common.GasPool, gasPrice *big.Int,
 	assetID uint64, config *params.ChainConfig, engine EngineContext) ([]byte, uint64, bool, error, error) {
-	return NewStateTransition(accountDB, evm, action, gp, gasPrice,
+	return NewStateTransition(accountDB, evm, action, payer, gp, gasPrice,
 		assetID, config, engine).TransitionDb()
 }
 
+// SettleMessage charges the gas of the action without executing it, the action
+// fails with the reason given. At least the intrinsic gas of the action is
+// charged, it is used to settle the actions by the failure policy of the
+// transaction.
+func SettleMessage(accountDB *accountmanager.AccountManager, evm *vm.EVM,
+	action *types.Action, payer common.Name, gp *common.GasPool, gasPrice *big.Int,
+	assetID uint64, config *params.ChainConfig, engine EngineContext, charge uint64, reason error) (uint64, error) {
+	st := NewStateTransition(accountDB, evm, action, payer, gp, gasPrice,
+		assetID, config, engine)
+	st.skip, st.charge = reason, charge
+	_, gas, _, err, _ := st.TransitionDb()
+	return gas, err
+}
+
 func (st *StateTransition) useGas(amount uint64) error {
 	if st.gas < amount {
 		return vm.ErrOutOfGas
@@ -84,12 +103,15 @@ func (st *StateTransition) useGas(amount uint64) error {
 }
 
 func (st *StateTransition) preCheck() error {
+	if baseFee := st.evm.Context.BaseFee; baseFee != nil && st.gasPrice.Cmp(baseFee) < 0 {
+		return ErrGasPriceBelowBaseFee
+	}
 	return st.buyGas()
 }
 
 func (st *StateTransition) buyGas() error {
 	mgval := new(big.Int).Mul(new(big.Int).SetUint64(st.action.Gas()), st.gasPrice)
-	balance, err := st.account.GetAccountBalanceByID(st.from, st.assetID, 0)
+	balance, err := st.account.GetAccountBalanceByID(st.payer, st.assetID, 0)
 	if err != nil {
 		return err
 	}
@@ -101,7 +123,7 @@ func (st *StateTransition) buyGas() error {
 	}
 	st.gas += st.action.Gas()
 	st.initialGas = st.action.Gas()
-	return st.account.TransferAsset(st.from, common.Name(st.chainConfig.FeeName), st.assetID, mgval)
+	return st.account.TransferAsset(st.payer, common.Name(st.chainConfig.FeeName), st.assetID, mgval)
 }
 
 // TransitionDb will transition the state by applying the current message and
@@ -113,7 +135,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		return
 	}
 
-	intrinsicGas, err := txpool.IntrinsicGas(st.account, st.action)
+	intrinsicGas, err := txpool.IntrinsicGas(st.account, st.action, st.chainConfig)
 	if err != nil {
 		return nil, 0, true, err, vmerr
 	}
@@ -129,12 +151,22 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		// not assigned to err, except for insufficient balance
 		// error.
 	)
+	snap, internalLen := evm.StateDB.Snapshot(), len(evm.InternalTxs)
+	st.account.TrackStorageGrowth()
 	actionType := st.action.Type()
 	switch {
+	case st.skip != nil:
+		if st.charge > intrinsicGas && st.useGas(st.charge-intrinsicGas) != nil {
+			st.gas = 0
+		}
+		vmerr = st.skip
+	case !actionType.IsForked(st.evm.Context.ForkID):
+		vmerr = types.ErrActionUndefined
 	case actionType == types.CreateContract:
 		ret, st.gas, vmerr = evm.Create(sender, st.action, st.gas)
 	case actionType == types.CallContract:
 		ret, st.gas, vmerr = evm.Call(sender, st.action, st.gas)
+		evm.Output = types.NewReturnOutput(ret)
 	case actionType == types.RegCandidate:
 		fallthrough
 	case actionType == types.UpdateCandidate:
@@ -149,20 +181,40 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		fallthrough
 	case actionType == types.RemoveKickedCandidate:
 		fallthrough
+	case actionType == types.ForceSnapshot:
+		fallthrough
 	case actionType == types.ExitTakeOver:
 		internalLogs, err := st.engine.ProcessAction(st.evm.Context.ForkID, st.evm.Context.BlockNumber.Uint64(),
 			st.evm.ChainConfig(), st.evm.StateDB, st.action)
 		vmerr = err
 		evm.InternalTxs = append(evm.InternalTxs, internalLogs...)
 	default:
-		internalLogs, err := st.account.Process(&types.AccountManagerContext{
+		ctx := &types.AccountManagerContext{
 			Action:      st.action,
 			Number:      st.evm.Context.BlockNumber.Uint64(),
+			Time:        st.evm.Context.Time.Uint64(),
 			CurForkID:   st.evm.Context.ForkID,
 			ChainConfig: st.chainConfig,
-		})
+			VerifyAsset: evm.AssetVerifier(st.from, &st.gas),
+		}
+		internalLogs, err := st.account.Process(ctx)
 		vmerr = err
 		evm.InternalTxs = append(evm.InternalTxs, internalLogs...)
+		if err == nil {
+			evm.Output = ctx.Output
+		}
+	}
+	if vmerr == nil {
+		// the sender pays the rent of the state the action grew, contract calls included
+		rentActions, err := st.account.ChargeStorageRent(st.from, st.chainConfig)
+		if err != nil {
+			evm.StateDB.RevertToSnapshot(snap)
+			st.account.ResetAuthorCache()
+			evm.InternalTxs, evm.Output = evm.InternalTxs[:internalLen], nil
+			vmerr = err
+		} else {
+			evm.InternalTxs = append(evm.InternalTxs, rentActions...)
+		}
 	}
 	if vmerr != nil {
 		log.Debug("VM returned with error", "err", vmerr)
@@ -181,6 +233,10 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	if err != nil {
 		return nil, st.gasUsed(), true, err, vmerr
 	}
+	err = st.account.TouchInheritance(st.from, st.evm.Context.BlockNumber.Uint64())
+	if err != nil {
+		return nil, st.gasUsed(), true, err, vmerr
+	}
 	st.refundGas()
 
 	st.distributeGas(intrinsicGas)
@@ -224,6 +280,68 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 	case types.DeleteAccount:
 		fallthrough
 	case types.UpdateAccountAuthor:
+		fallthrough
+	case types.TransferEscrow:
+		fallthrough
+	case types.ClaimEscrow:
+		fallthrough
+	case types.RefundEscrow:
+		fallthrough
+	case types.TimeLockTransfer:
+		fallthrough
+	case types.ClaimTimeLock:
+		fallthrough
+	case types.CancelTimeLock:
+		fallthrough
+	case types.SetInheritance:
+		fallthrough
+	case types.ClaimInheritance:
+		fallthrough
+	case types.BidAccountName:
+		fallthrough
+	case types.ClaimAccountName:
+		fallthrough
+	case types.SetAuthorDelay:
+		fallthrough
+	case types.CancelAuthorUpdate:
+		fallthrough
+	case types.ApplyAuthorUpdate:
+		fallthrough
+	case types.SetGuardians:
+		fallthrough
+	case types.ProposeRecovery:
+		fallthrough
+	case types.ApproveRecovery:
+		fallthrough
+	case types.CancelRecovery:
+		fallthrough
+	case types.ExecuteRecovery:
+		fallthrough
+	case types.UpdateReservedNames:
+		fallthrough
+	case types.ApproveAsset:
+		fallthrough
+	case types.TransferFromAsset:
+		fallthrough
+	case types.DistributeAsset:
+		fallthrough
+	case types.CreateInvitations:
+		fallthrough
+	case types.RedeemInvitation:
+		fallthrough
+	case types.RevokeInvitation:
+		fallthrough
+	case types.ReleaseHolderBalance:
+		fallthrough
+	case types.AtomicSwap:
+		fallthrough
+	case types.BatchAction:
+		fallthrough
+	case types.ScheduleAction:
+		fallthrough
+	case types.CancelScheduledAction:
+		fallthrough
+	case types.PermitTransfer:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.AccountName))
 		return
 	case types.IncreaseAsset:
@@ -236,6 +354,60 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 		fallthrough
 	case types.UpdateAssetContract:
 		fallthrough
+	case types.UpdateTokenCurator:
+		fallthrough
+	case types.UpdateTokenList:
+		fallthrough
+	case types.SetAssetConversion:
+		fallthrough
+	case types.WrapAsset:
+		fallthrough
+	case types.UnwrapAsset:
+		fallthrough
+	case types.UpdateAssetAccessList:
+		fallthrough
+	case types.IssueNFT:
+		fallthrough
+	case types.TransferNFT:
+		fallthrough
+	case types.BurnNFT:
+		fallthrough
+	case types.BurnAsset:
+		fallthrough
+	case types.SetAssetBurnable:
+		fallthrough
+	case types.SetAssetTransferFee:
+		fallthrough
+	case types.UpdateAssetMetadata:
+		fallthrough
+	case types.SetSubAssetDelegate:
+		fallthrough
+	case types.AcceptAssetOwner:
+		fallthrough
+	case types.RedenominateAsset:
+		fallthrough
+	case types.LockHolderBalance:
+		fallthrough
+	case types.GrantAssetRole:
+		fallthrough
+	case types.RevokeAssetRole:
+		fallthrough
+	case types.PauseAsset:
+		fallthrough
+	case types.SetAssetBanned:
+		fallthrough
+	case types.RegisterWrappedAsset:
+		fallthrough
+	case types.BridgeMint:
+		fallthrough
+	case types.BridgeBurn:
+		fallthrough
+	case types.UpdateAssetUpperLimit:
+		fallthrough
+	case types.ApproveAssetUpperLimit:
+		fallthrough
+	case types.SetAssetVerifier:
+		fallthrough
 	case types.UpdateAsset:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.AssetName))
 		return
@@ -253,6 +425,8 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 		fallthrough
 	case types.RemoveKickedCandidate:
 		fallthrough
+	case types.ForceSnapshot:
+		fallthrough
 	case types.ExitTakeOver:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.DposName))
 		return
@@ -307,8 +481,36 @@ func (st *StateTransition) distributeToSystemAccount(name common.Name) {
 
 }
 
+// tipPrice returns the gas price paid to the fee receivers, the base fee is collected separately.
+func (st *StateTransition) tipPrice() *big.Int {
+	if st.evm.Context.BaseFee == nil {
+		return st.gasPrice
+	}
+	return new(big.Int).Sub(st.gasPrice, st.evm.Context.BaseFee)
+}
+
+// collectBaseFee burns the base fee of the used gas, or routes it to the treasury.
+func (st *StateTransition) collectBaseFee() error {
+	if st.evm.Context.BaseFee == nil {
+		return nil
+	}
+	value := new(big.Int).Mul(st.evm.Context.BaseFee, new(big.Int).SetUint64(st.gasUsed()))
+	if value.Sign() == 0 {
+		return nil
+	}
+	feeName := common.Name(st.chainConfig.FeeName)
+	if treasury := st.chainConfig.FeeMarket().Treasury; len(treasury) != 0 {
+		return st.account.TransferAsset(feeName, common.Name(treasury), st.assetID, value)
+	}
+	return st.account.BurnAsset(feeName, st.assetID, value)
+}
+
 func (st *StateTransition) distributeFee() error {
+	if err := st.collectBaseFee(); err != nil {
+		return fmt.Errorf("collect base fee err(%v), assetID:%d", err, st.assetID)
+	}
 	fm := feemanager.NewFeeManager(st.evm.StateDB, st.evm.AccountDB)
+	tipPrice := st.tipPrice()
 
 	var keys vm.DistributeKeys
 	for key, _ := range st.evm.FounderGasMap {
@@ -319,7 +521,7 @@ func (st *StateTransition) distributeFee() error {
 	for _, key := range keys {
 		gas := st.evm.FounderGasMap[key]
 		if gas.Value > 0 {
-			value := new(big.Int).Mul(st.gasPrice, big.NewInt(gas.Value))
+			value := new(big.Int).Mul(tipPrice, big.NewInt(gas.Value))
 			err := fm.RecordFeeInSystem(key.ObjectName.String(), gas.TypeID, st.assetID, value)
 			if err != nil {
 				return fmt.Errorf("record fee err(%v), key:%v,assetID:%d", err, key, st.assetID)
@@ -332,7 +534,7 @@ func (st *StateTransition) distributeFee() error {
 
 func (st *StateTransition) refundGas() {
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.account.TransferAsset(common.Name(st.chainConfig.FeeName), st.from, st.assetID, remaining)
+	st.account.TransferAsset(common.Name(st.chainConfig.FeeName), st.payer, st.assetID, remaining)
 	st.gp.AddGas(st.gas)
 }
 
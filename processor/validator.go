@@ -116,6 +116,11 @@ func (v *BlockValidator) ValidateHeader(header *types.Header, seal bool) error {
 		return err
 	}
 
+	// Verify the base fee of the fee market
+	if err := types.VerifyBaseFee(v.bc.Config(), parent, header); err != nil {
+		return err
+	}
+
 	// Verify the engine specific seal securing the block
 	if seal {
 		if err := v.engine.VerifySeal(v.bc, header); err != nil {
@@ -133,6 +138,11 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 		return err
 	}
 
+	// Reject the blocks propagating too slowly regardless of their gas
+	if max := v.bc.Config().MaxBlockSize(); max != 0 && uint64(block.Size()) > max {
+		return fmt.Errorf("%v: have %d, max %d", ErrBlockTooLarge, uint64(block.Size()), max)
+	}
+
 	// Header validity is known at this point, check the uncles and transactions
 	if hash := types.DeriveTxsMerkleRoot(block.Txs); hash != block.TxHash() {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, block.TxHash())
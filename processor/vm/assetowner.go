@@ -0,0 +1,83 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var errAcceptAssetOwnerInput = errors.New("accept asset owner input invalid")
+
+// acceptAssetOwner lets the calling contract accept the ownership of the asset
+// proposed to it. The input is the asset id as a 32 bytes word.
+type acceptAssetOwner struct{}
+
+func (c *acceptAssetOwner) RequiredGas(input []byte) uint64 {
+	return params.AcceptAssetOwnerGas
+}
+
+func (c *acceptAssetOwner) Run(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
+	if len(input) != 32 {
+		return nil, errAcceptAssetOwnerInput
+	}
+	if evm.interpreter.readOnly {
+		return nil, errWriteProtection
+	}
+	assetID := new(big.Int).SetBytes(input)
+	if !assetID.IsUint64() {
+		return nil, errAcceptAssetOwnerInput
+	}
+	return nil, execAcceptAssetOwner(evm, contract, assetID.Uint64())
+}
+
+func execAcceptAssetOwner(evm *EVM, contract *Contract, assetID uint64) error {
+	b, err := rlp.EncodeToBytes(&accountmanager.AcceptAssetOwner{AssetID: assetID})
+	if err != nil {
+		return err
+	}
+
+	action := types.NewAction(types.AcceptAssetOwner, contract.Name(), common.Name(evm.chainConfig.AssetName), 0, evm.chainConfig.SysTokenID, 0, big.NewInt(0), b, nil)
+	internalActions, err := evm.AccountDB.Process(&types.AccountManagerContext{
+		Action:      action,
+		Number:      evm.Context.BlockNumber.Uint64(),
+		CurForkID:   evm.Context.ForkID,
+		ChainConfig: evm.chainConfig,
+		VerifyAsset: evm.AssetVerifier(contract.Name(), &contract.Gas),
+	})
+	if evm.vmConfig.ContractLogFlag {
+		errmsg := ""
+		if err != nil {
+			errmsg = err.Error()
+		}
+		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "acceptassetowner", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
+		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		if len(internalActions) > 0 {
+			for _, iLog := range internalActions {
+				iLog.Depth = uint64(evm.depth)
+			}
+			evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+		}
+	}
+	return err
+}
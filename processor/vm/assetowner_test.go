@@ -0,0 +1,84 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/state"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAcceptAssetOwner(t *testing.T) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(mdb.NewMemDatabase()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountmanager.SetChainName(common.Name("vmtestchain"))
+	info, err := rlp.EncodeToBytes(&struct{ CurForkID uint64 }{params.ForkID4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb.Put("vmtestchain", "forkInfo", info)
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey := common.HexToPubKey("0x047db227d7094ce215c3a0f57e1bcc732551fe351f94249471934567e0f5dc1bf795962b8cccb87a2eb56b29fbe37d614e2f4c3c45b789ae4f1f51f4cb21972ffd")
+	owner, contractName := common.Name("assetowner"), common.Name("ownercontract")
+	for _, name := range []common.Name{owner, contractName} {
+		if err := am.CreateAccount(common.Name(""), name, common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.IssueAsset(owner, accountmanager.IssueAsset{AssetName: "ownercoin", Symbol: "own", Amount: big.NewInt(0), Owner: owner, UpperLimit: big.NewInt(0)}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := asset.NewAsset(statedb).ProposeAssetOwner(assetID, contractName, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	evm := NewEVM(Context{BlockNumber: big.NewInt(2), ForkID: params.ForkID4}, am, statedb, params.DefaultChainconfig, Config{})
+	contract := NewContract(AccountRef(owner), AccountRef(contractName), big.NewInt(0), 1000000, 0)
+	p := evm.statefulPrecompiled(10)
+	if p == nil {
+		t.Fatal("accept asset owner contract not available")
+	}
+	if _, err := RunStatefulPrecompiledContract(p, evm, []byte{1}, contract); err != errAcceptAssetOwnerInput {
+		t.Fatalf("short input err %v", err)
+	}
+	input := common.LeftPadBytes(new(big.Int).SetUint64(assetID).Bytes(), 32)
+	evm.interpreter.readOnly = true
+	if _, err := RunStatefulPrecompiledContract(p, evm, input, contract); err != errWriteProtection {
+		t.Fatalf("accept in read only err %v", err)
+	}
+	evm.interpreter.readOnly = false
+	if _, err := RunStatefulPrecompiledContract(p, evm, input, contract); err != nil {
+		t.Fatal(err)
+	}
+	assetObj, err := am.GetAssetInfoByID(assetID)
+	if err != nil || assetObj.GetAssetOwner() != contractName {
+		t.Fatalf("asset owner %v, %v", assetObj, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// verifyAssetActionID is the selector of the function the verification contract of
+// an asset implements, the accounts are passed by id, 0 if not exist:
+//
+//	function verifyAssetAction(uint256 assetID, uint256 actionType, uint256 sender, uint256 target, uint256 amount) view returns (bool)
+var verifyAssetActionID = crypto.Keccak256([]byte("verifyAssetAction(uint256,uint256,uint256,uint256,uint256)"))[:4]
+
+func (evm *EVM) accountID(name common.Name) uint64 {
+	acct, err := evm.AccountDB.GetAccountByName(name)
+	if err != nil || acct == nil {
+		return 0
+	}
+	return acct.GetAccountID()
+}
+
+// AssetVerifier returns the verifier statically calling the verification contracts
+// of the assets from the caller. A call uses at most AssetVerifierGas of the gas,
+// the gas used is not distributed to the founder of the verification contract.
+func (evm *EVM) AssetVerifier(caller common.Name, gas *uint64) types.AssetVerifier {
+	return func(verifier common.Name, action *types.Action, assetID uint64, target common.Name, amount *big.Int) error {
+		input := make([]byte, 0, 4+5*32)
+		input = append(input, verifyAssetActionID...)
+		for _, word := range []*big.Int{
+			new(big.Int).SetUint64(assetID),
+			new(big.Int).SetUint64(uint64(action.Type())),
+			new(big.Int).SetUint64(evm.accountID(action.Sender())),
+			new(big.Int).SetUint64(evm.accountID(target)),
+			amount,
+		} {
+			input = append(input, common.LeftPadBytes(word.Bytes(), 32)...)
+		}
+
+		limit := params.AssetVerifierGas
+		if *gas < limit {
+			limit = *gas
+		}
+		founderGas := make(map[DistributeKey]DistributeGas, len(evm.FounderGasMap))
+		for key, value := range evm.FounderGasMap {
+			founderGas[key] = value
+		}
+		ret, leftOverGas, err := evm.StaticCall(AccountRef(caller), verifier, input, limit)
+		evm.FounderGasMap = founderGas
+		*gas -= limit - leftOverGas
+		if err != nil {
+			return fmt.Errorf("%v: %v", accountmanager.ErrAssetVerifierRejected, err)
+		}
+		if len(ret) != 32 || new(big.Int).SetBytes(ret).Sign() == 0 {
+			return accountmanager.ErrAssetVerifierRejected
+		}
+		return nil
+	}
+}
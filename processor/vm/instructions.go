@@ -920,6 +920,8 @@ func opCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 	//var acct *accountmanager.Account
 	if p := PrecompiledContracts[userID]; p != nil {
 		ret, err = RunPrecompiledContract(p, args, contract)
+	} else if p := evm.statefulPrecompiled(userID); p != nil {
+		ret, err = RunStatefulPrecompiledContract(p, evm, args, contract)
 	} else {
 		acct, err = evm.AccountDB.GetAccountById(userID)
 		if err != nil || acct == nil {
@@ -938,6 +940,7 @@ func opCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 				errmsg = err.Error()
 			}
 			internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "call", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
+			internalAction.SetOutput(types.NewReturnOutput(ret))
 			evm.InternalTxs = append(evm.InternalTxs, internalAction)
 		}
 	}
@@ -979,6 +982,8 @@ func opCallWithPay(pc *uint64, evm *EVM, contract *Contract, memory *Memory, sta
 	//var acct *accountmanager.Account
 	if p := PrecompiledContracts[userID]; p != nil {
 		ret, err = RunPrecompiledContract(p, args, contract)
+	} else if p := evm.statefulPrecompiled(userID); p != nil {
+		ret, err = RunStatefulPrecompiledContract(p, evm, args, contract)
 	} else {
 		acct, err = evm.AccountDB.GetAccountById(userID)
 		if err != nil || acct == nil {
@@ -997,6 +1002,7 @@ func opCallWithPay(pc *uint64, evm *EVM, contract *Contract, memory *Memory, sta
 				errmsg = err.Error()
 			}
 			internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "callwithpay", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
+			internalAction.SetOutput(types.NewReturnOutput(ret))
 			evm.InternalTxs = append(evm.InternalTxs, internalAction)
 		}
 	}
@@ -1061,6 +1067,7 @@ func opCallCode(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "callcode", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
+		internalAction.SetOutput(types.NewReturnOutput(ret))
 		evm.InternalTxs = append(evm.InternalTxs, internalAction)
 	}
 	return ret, nil
@@ -1235,6 +1242,7 @@ func execAddAsset(evm *EVM, contract *Contract, assetID uint64, toName common.Na
 		Number:      evm.Context.BlockNumber.Uint64(),
 		CurForkID:   evm.Context.ForkID,
 		ChainConfig: evm.chainConfig,
+		VerifyAsset: evm.AssetVerifier(contract.Name(), &contract.Gas),
 	})
 	if evm.vmConfig.ContractLogFlag {
 		errmsg := ""
@@ -1480,6 +1488,7 @@ func executeIssuseAsset(evm *EVM, contract *Contract, desc string) (uint64, erro
 					errmsg = err.Error()
 				}
 				internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "issueasset", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
+				internalAction.SetOutput(&types.ActionOutput{AssetID: assetInfo.GetAssetId()})
 				evm.InternalTxs = append(evm.InternalTxs, internalAction)
 				if len(internalActions) > 0 {
 					for _, iLog := range internalActions {
@@ -1529,6 +1538,7 @@ func execSetAssetOwner(evm *EVM, contract *Contract, assetID uint64, owner commo
 		Number:      evm.Context.BlockNumber.Uint64(),
 		CurForkID:   evm.Context.ForkID,
 		ChainConfig: evm.chainConfig,
+		VerifyAsset: evm.AssetVerifier(contract.Name(), &contract.Gas),
 	})
 	if evm.vmConfig.ContractLogFlag {
 		errmsg := ""
@@ -1624,7 +1634,12 @@ func opCallEx(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 
 	action := types.NewAction(types.CallContract, contract.Name(), toName, 0, assetID, 0, value, nil, nil)
 
-	if !contract.UseGas(evm.CheckReceipt(action)) {
+	gasLimit, receiptGas := contract.Gas, evm.CheckReceipt(action)
+	if !contract.UseGas(receiptGas) {
+		if evm.vmConfig.ContractLogFlag {
+			internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "transferex", GasUsed: 0, GasLimit: gasLimit, Depth: uint64(evm.depth), Error: ErrOutOfGas.Error()}
+			evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		}
 		stack.push(evm.interpreter.intPool.getZero())
 		return nil, nil
 	}
@@ -1650,7 +1665,7 @@ func opCallEx(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 		if err != nil {
 			errmsg = err.Error()
 		}
-		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "transferex", GasUsed: 0, GasLimit: 0, Depth: uint64(evm.depth), Error: errmsg}
+		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "transferex", GasUsed: receiptGas, GasLimit: gasLimit, Depth: uint64(evm.depth), Error: errmsg}
 		evm.InternalTxs = append(evm.InternalTxs, internalAction)
 	}
 	return nil, nil
@@ -1693,6 +1708,7 @@ func opStaticCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stac
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{ActionType: "staticcall", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
+		internalAction.SetOutput(types.NewReturnOutput(ret))
 		evm.InternalTxs = append(evm.InternalTxs, internalAction)
 	}
 	return ret, nil
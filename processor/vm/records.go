@@ -0,0 +1,125 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/fractalplatform/fractal/params"
+)
+
+const (
+	namedRecordGet = byte(0)
+	namedRecordSet = byte(1)
+
+	// MaxNamedRecordKeyLength max length of the key of a named record
+	MaxNamedRecordKeyLength = 64
+	// MaxNamedRecordValueLength max length of the value of a named record
+	MaxNamedRecordValueLength = 1024
+)
+
+var (
+	errNamedRecordInput  = errors.New("named record input invalid")
+	namedRecordKeyRegExp = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+)
+
+// StatefulPrecompiledContract is a native Go contract with access to the state of the
+// calling contract account.
+type StatefulPrecompiledContract interface {
+	RequiredGas(input []byte) uint64                                // RequiredPrice calculates the contract gas use
+	Run(evm *EVM, contract *Contract, input []byte) ([]byte, error) // Run runs the precompiled contract
+}
+
+// StatefulPrecompiledContracts contains the pre-compiled contracts with state access
+var StatefulPrecompiledContracts = map[uint64]StatefulPrecompiledContract{
+	9:  &namedRecord{},
+	10: &acceptAssetOwner{},
+}
+
+// statefulPrecompiled returns the stateful precompiled contract of the user id,
+// the stateful precompiled contracts are available from ForkID4.
+func (evm *EVM) statefulPrecompiled(userID uint64) StatefulPrecompiledContract {
+	if evm.Context.ForkID < params.ForkID4 {
+		return nil
+	}
+	return StatefulPrecompiledContracts[userID]
+}
+
+// RunStatefulPrecompiledContract runs and evaluates the output of a stateful precompiled contract.
+func RunStatefulPrecompiledContract(p StatefulPrecompiledContract, evm *EVM, input []byte, contract *Contract) (ret []byte, err error) {
+	gas := p.RequiredGas(input)
+	if contract.UseGas(gas) {
+		return p.Run(evm, contract, input)
+	}
+	return nil, ErrOutOfGas
+}
+
+// namedRecord reads and writes the small records named by readable keys under the
+// account of the calling contract. The input is the operation byte, the key length
+// byte, the key and for the set operation the value, an empty value deletes the record.
+type namedRecord struct{}
+
+// parseNamedRecordInput split the input into the operation, the key and the value
+func parseNamedRecordInput(input []byte) (byte, string, []byte, error) {
+	if len(input) < 2 {
+		return 0, "", nil, errNamedRecordInput
+	}
+	op, keyLen := input[0], int(input[1])
+	if keyLen == 0 || keyLen > MaxNamedRecordKeyLength || len(input) < 2+keyLen {
+		return 0, "", nil, errNamedRecordInput
+	}
+	key, value := string(input[2:2+keyLen]), input[2+keyLen:]
+	if !namedRecordKeyRegExp.MatchString(key) {
+		return 0, "", nil, errNamedRecordInput
+	}
+	switch op {
+	case namedRecordGet:
+		if len(value) != 0 {
+			return 0, "", nil, errNamedRecordInput
+		}
+	case namedRecordSet:
+		if len(value) > MaxNamedRecordValueLength {
+			return 0, "", nil, errNamedRecordInput
+		}
+	default:
+		return 0, "", nil, errNamedRecordInput
+	}
+	return op, key, value, nil
+}
+
+func (c *namedRecord) RequiredGas(input []byte) uint64 {
+	if len(input) > 0 && input[0] == namedRecordSet {
+		return params.NamedRecordSetGas + uint64(len(input)-1)*params.NamedRecordPerByteGas
+	}
+	return params.NamedRecordGetGas
+}
+
+func (c *namedRecord) Run(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
+	op, key, value, err := parseNamedRecordInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if op == namedRecordGet {
+		return evm.StateDB.GetNamedRecord(contract.Name().String(), key)
+	}
+	if evm.interpreter.readOnly {
+		return nil, errWriteProtection
+	}
+	evm.StateDB.SetNamedRecord(contract.Name().String(), key, value)
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/state"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func namedRecordInput(op byte, key string, value []byte) []byte {
+	return append(append([]byte{op, byte(len(key))}, key...), value...)
+}
+
+func TestNamedRecord(t *testing.T) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(mdb.NewMemDatabase()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evm := &EVM{StateDB: statedb, interpreter: &Interpreter{}}
+	contract := NewContract(AccountRef("caller"), AccountRef("recordcontract"), big.NewInt(0), 1000000, 0)
+	p := StatefulPrecompiledContracts[9]
+
+	set := namedRecordInput(namedRecordSet, "owner.name", []byte("fractal"))
+	if gas := p.RequiredGas(set); gas != params.NamedRecordSetGas+uint64(len(set)-1)*params.NamedRecordPerByteGas {
+		t.Fatalf("set gas %v mismatch", gas)
+	}
+	if _, err := RunStatefulPrecompiledContract(p, evm, set, contract); err != nil {
+		t.Fatal(err)
+	}
+	ret, err := RunStatefulPrecompiledContract(p, evm, namedRecordInput(namedRecordGet, "owner.name", nil), contract)
+	if err != nil || !bytes.Equal(ret, []byte("fractal")) {
+		t.Fatalf("get record = %s, %v", ret, err)
+	}
+	if value, _ := statedb.GetNamedRecord("recordcontract", "owner.name"); !bytes.Equal(value, []byte("fractal")) {
+		t.Fatalf("state record = %s", value)
+	}
+
+	evm.interpreter.readOnly = true
+	if _, err := RunStatefulPrecompiledContract(p, evm, namedRecordInput(namedRecordSet, "owner.name", nil), contract); err != errWriteProtection {
+		t.Fatalf("set record in read only err %v", err)
+	}
+	evm.interpreter.readOnly = false
+	if _, err := RunStatefulPrecompiledContract(p, evm, namedRecordInput(namedRecordSet, "owner.name", nil), contract); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := statedb.GetNamedRecord("recordcontract", "owner.name"); len(value) != 0 {
+		t.Fatalf("deleted record = %s", value)
+	}
+
+	for _, input := range [][]byte{
+		{namedRecordGet},
+		namedRecordInput(namedRecordGet, "", nil),
+		namedRecordInput(namedRecordGet, "bad key", nil),
+		namedRecordInput(namedRecordGet, "key", []byte("value")),
+		namedRecordInput(2, "key", nil),
+		namedRecordInput(namedRecordSet, "key", make([]byte, MaxNamedRecordValueLength+1)),
+	} {
+		if _, _, _, err := parseNamedRecordInput(input); err != errNamedRecordInput {
+			t.Fatalf("input %x err %v", input, err)
+		}
+	}
+}
+
+func TestStatefulPrecompiledFork(t *testing.T) {
+	evm := &EVM{Context: Context{ForkID: params.ForkID3}}
+	if p := evm.statefulPrecompiled(9); p != nil {
+		t.Fatal("stateful precompiled contract available before the fork")
+	}
+	evm.Context.ForkID = params.ForkID4
+	if p := evm.statefulPrecompiled(9); p == nil {
+		t.Fatal("stateful precompiled contract not available from the fork")
+	}
+}
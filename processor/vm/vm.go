@@ -74,6 +74,7 @@ type Context struct {
 	ForkID      uint64      // Provides information for FORKID
 	Time        *big.Int    // Provides information for TIME
 	Difficulty  *big.Int    // Provides information for DIFFICULTY
+	BaseFee     *big.Int    // Provides the base fee of the block, nil before the fee market fork
 }
 
 type FounderGas struct {
@@ -112,6 +113,9 @@ type EVM struct {
 	FounderGasMap map[DistributeKey]DistributeGas
 
 	InternalTxs []*types.InternalAction
+
+	// Output is the outcome of the action kept in its result
+	Output *types.ActionOutput
 }
 
 type DistributeGas struct {
@@ -185,6 +189,30 @@ func (evm *EVM) GetCurrentGasTable() params.GasTable {
 	return evm.interpreter.GetGasTable()
 }
 
+// logTransfer records the asset transfer made by a contract as an internal
+// action with the gas it charged, the failed transfer keeps its error. The
+// transfers of the top level action are in its own result and not recorded.
+func (evm *EVM) logTransfer(action *types.Action, gasUsed, gasLimit uint64, err error) *types.InternalAction {
+	if !evm.vmConfig.ContractLogFlag || evm.depth == 0 || action.Value().Sign() == 0 {
+		return nil
+	}
+	errmsg := ""
+	if err != nil {
+		errmsg = err.Error()
+	}
+	transfer := types.NewAction(types.Transfer, action.Sender(), action.Recipient(), 0, action.AssetID(), 0, action.Value(), nil, nil)
+	internalAction := &types.InternalAction{Action: transfer.NewRPCAction(0), ActionType: "transfer", GasUsed: gasUsed, GasLimit: gasLimit, Depth: uint64(evm.depth), Error: errmsg}
+	evm.InternalTxs = append(evm.InternalTxs, internalAction)
+	return internalAction
+}
+
+// revertTransfer marks the recorded transfer reverted with the call it paid.
+func revertTransfer(internalAction *types.InternalAction, err error) {
+	if internalAction != nil && internalAction.Error == "" {
+		internalAction.Error = err.Error()
+	}
+}
+
 func (evm *EVM) CheckReceipt(action *types.Action) uint64 {
 	gasTable := evm.GetCurrentGasTable()
 	if action.Value().Sign() == 0 {
@@ -293,19 +321,23 @@ func (evm *EVM) Call(caller ContractRef, action *types.Action, gas uint64) (ret
 	// Fail if we're trying to transfer more than the available balance
 
 	if ok, err := evm.AccountDB.CanTransfer(caller.Name(), action.AssetID(), action.Value()); !ok || err != nil {
+		evm.logTransfer(action, 0, gas, ErrInsufficientBalance)
 		return nil, gas, ErrInsufficientBalance
 	}
 
 	toName := action.Recipient()
 
 	var (
-		to       = AccountRef(toName)
-		snapshot = evm.StateDB.Snapshot()
+		to         = AccountRef(toName)
+		snapshot   = evm.StateDB.Snapshot()
+		gasLimit   = gas
+		receiptGas uint64
 	)
 
 	if evm.depth != 0 {
-		receiptGas := evm.CheckReceipt(action)
+		receiptGas = evm.CheckReceipt(action)
 		if gas < receiptGas {
+			evm.logTransfer(action, 0, gasLimit, ErrInsufficientBalance)
 			return nil, gas, ErrInsufficientBalance
 		} else {
 			gas -= receiptGas
@@ -313,8 +345,10 @@ func (evm *EVM) Call(caller ContractRef, action *types.Action, gas uint64) (ret
 	}
 
 	if err := evm.AccountDB.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value()); err != nil {
+		evm.logTransfer(action, receiptGas, gasLimit, err)
 		return nil, gas, err
 	}
+	transfer := evm.logTransfer(action, receiptGas, gasLimit, nil)
 
 	var assetName common.Name
 	assetFounder, _ := evm.AccountDB.GetAssetFounder(action.AssetID()) //get asset founder name
@@ -360,6 +394,7 @@ func (evm *EVM) Call(caller ContractRef, action *types.Action, gas uint64) (ret
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		revertTransfer(transfer, err)
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
@@ -16,11 +16,14 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"sort"
 	"testing"
 
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -128,3 +131,32 @@ func TestDistributeGasByScale(t *testing.T) {
 	evm.distributeGasByScale(0, 0)
 	return
 }
+
+func TestLogTransfer(t *testing.T) {
+	evm := &EVM{vmConfig: Config{ContractLogFlag: true}}
+	action := types.NewAction(types.CallContract, common.Name("contract"), common.Name("receiver"), 0, 1, 0, big.NewInt(10), nil, nil)
+	if evm.logTransfer(action, 0, 100, nil) != nil {
+		t.Fatal("top level transfer recorded")
+	}
+
+	evm.depth = 2
+	transfer := evm.logTransfer(action, 30, 100, nil)
+	failed := evm.logTransfer(action, 0, 100, ErrInsufficientBalance)
+	assert.Equal(t, []*types.InternalAction{transfer, failed}, evm.InternalTxs)
+	assert.Equal(t, "transfer", transfer.ActionType)
+	assert.Equal(t, uint64(2), transfer.Depth)
+	assert.Equal(t, uint64(30), transfer.GasUsed)
+	assert.Equal(t, uint64(100), transfer.GasLimit)
+	assert.Equal(t, common.Name("receiver"), transfer.Action.To)
+	assert.Equal(t, ErrInsufficientBalance.Error(), failed.Error)
+
+	revertTransfer(transfer, errors.New("reverted"))
+	revertTransfer(failed, errors.New("reverted"))
+	assert.Equal(t, "reverted", transfer.Error)
+	assert.Equal(t, ErrInsufficientBalance.Error(), failed.Error)
+
+	zero := types.NewAction(types.CallContract, common.Name("contract"), common.Name("receiver"), 0, 1, 0, big.NewInt(0), nil, nil)
+	if evm.logTransfer(zero, 0, 100, nil) != nil {
+		t.Fatal("zero transfer recorded")
+	}
+}
@@ -350,6 +350,7 @@ func ReadDetailTxs(db DatabaseReader, hash common.Hash, number uint64) []*types.
 	detailtxs := make([]*types.DetailTx, len(storageDetailTxs))
 	for i, detailtx := range storageDetailTxs {
 		detailtxs[i] = (*types.DetailTx)(detailtx)
+		detailtxs[i].SetInternalActionIDs()
 	}
 	return detailtxs
 }
@@ -303,6 +303,45 @@ func TestBlockReceiptStorage(t *testing.T) {
 	}
 }
 
+func TestDetailTxsStorage(t *testing.T) {
+	db := mdb.NewMemDatabase()
+
+	internal := func(to string) *types.InternalAction {
+		return &types.InternalAction{Action: &types.RPCAction{From: common.StrToName("a11111111"), To: common.StrToName(to), Amount: big.NewInt(1)}}
+	}
+	dtx := &types.DetailTx{
+		TxHash: common.BytesToHash([]byte{0x11, 0x11}),
+		Actions: []*types.DetailAction{
+			{InternalActions: []*types.InternalAction{internal("a22222222")}},
+			{InternalActions: []*types.InternalAction{internal("a33333333"), internal("a44444444")}},
+		},
+	}
+
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	WriteDetailTxs(db, hash, 0, []*types.DetailTx{dtx})
+	dtxs := ReadDetailTxs(db, hash, 0)
+	if len(dtxs) != 1 || len(dtxs[0].Actions) != 2 {
+		t.Fatalf("detail txs returned: %v", dtxs)
+	}
+	// the ids are derived from the tx hash and the position, not stored
+	dtx.SetInternalActionIDs()
+	seen := make(map[common.Hash]bool)
+	for i, action := range dtxs[0].Actions {
+		for j, in := range action.InternalActions {
+			if in.ID != types.InternalActionID(dtx.TxHash, uint64(i), uint64(j)) || in.ID != dtx.Actions[i].InternalActions[j].ID {
+				t.Fatalf("internal action %d/%d id %x", i, j, in.ID)
+			}
+			if in.ActionIndex != uint64(i) || in.Index != uint64(j) || seen[in.ID] {
+				t.Fatalf("internal action %d/%d position %d/%d", i, j, in.ActionIndex, in.Index)
+			}
+			seen[in.ID] = true
+		}
+	}
+	if types.InternalActionID(common.BytesToHash([]byte{0x22, 0x22}), 0, 0) == dtxs[0].Actions[0].InternalActions[0].ID {
+		t.Fatal("internal action id of another tx should differ")
+	}
+}
+
 func TestIrreversibleNumberStore(t *testing.T) {
 	db := mdb.NewMemDatabase()
 
@@ -17,6 +17,8 @@
 package rawdb
 
 import (
+	"encoding/binary"
+
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/types"
@@ -105,3 +107,60 @@ func WriteBloomBits(db DatabaseWriter, bit uint, section uint64, head common.Has
 		log.Crit("Failed to store bloom bits", "err", err)
 	}
 }
+
+// ReadBlockTime retrieves the time of the canonical block from the time index.
+func ReadBlockTime(db DatabaseReader, number uint64) (uint64, bool) {
+	data, _ := db.Get(blockTimeKey(number))
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteBlockTime stores the time of the canonical block in the time index.
+func WriteBlockTime(db DatabaseWriter, number uint64, time uint64) {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, time)
+	if err := db.Put(blockTimeKey(number), enc); err != nil {
+		log.Crit("Failed to store block time index", "err", err)
+	}
+}
+
+// readCanonicalTime retrieves the time of the canonical block, blocks written
+// before the time index fall back to the header.
+func readCanonicalTime(db DatabaseReader, number uint64) (uint64, bool) {
+	if time, ok := ReadBlockTime(db, number); ok {
+		return time, true
+	}
+	hash := ReadCanonicalHash(db, number)
+	if hash == (common.Hash{}) {
+		return 0, false
+	}
+	header := ReadHeader(db, hash, number)
+	if header == nil {
+		return 0, false
+	}
+	return header.Time.Uint64(), true
+}
+
+// FindBlockNumberByTime binary searches the canonical chain up to head for the
+// last block at or before the time, returns false if the time is before genesis.
+func FindBlockNumberByTime(db DatabaseReader, head uint64, time uint64) (uint64, bool) {
+	lo, hi := uint64(0), head
+	if t, ok := readCanonicalTime(db, lo); !ok || t > time {
+		return 0, false
+	}
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		t, ok := readCanonicalTime(db, mid)
+		if !ok {
+			return 0, false
+		}
+		if t <= time {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo, true
+}
@@ -74,3 +74,38 @@ func TestLookupStorage(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the block at or before a time can be found from the time index.
+func TestFindBlockNumberByTime(t *testing.T) {
+	db := mdb.NewMemDatabase()
+
+	times := []uint64{100, 110, 110, 130, 160}
+	for number, time := range times {
+		WriteBlockTime(db, uint64(number), time)
+	}
+	// the last block is only reachable by its header
+	header := &types.Header{Number: big.NewInt(5), Time: big.NewInt(200)}
+	WriteHeader(db, header)
+	WriteCanonicalHash(db, header.Hash(), 5)
+
+	tests := []struct {
+		time   uint64
+		number uint64
+		found  bool
+	}{
+		{99, 0, false},
+		{100, 0, true},
+		{110, 2, true},
+		{129, 2, true},
+		{159, 3, true},
+		{160, 4, true},
+		{199, 4, true},
+		{300, 5, true},
+	}
+	for _, tt := range tests {
+		number, found := FindBlockNumberByTime(db, 5, tt.time)
+		if number != tt.number || found != tt.found {
+			t.Errorf("time %d: got (%d, %v), want (%d, %v)", tt.time, number, found, tt.number, tt.found)
+		}
+	}
+}
@@ -57,6 +57,8 @@ var (
 	blockOptHash = []byte("LastOptHash")
 
 	blockSnapshotPrefix = []byte("sn")
+
+	blockTimePrefix = []byte("T") // blockTimePrefix + num (uint64 big endian) -> canonical block time (uint64 big endian)
 )
 
 // TxLookupEntry is a positional metadata to help looking up the data content of
@@ -94,6 +96,11 @@ func headerHashKey(number uint64) []byte {
 	return append(append(headerPrefix, encodeBlockNumber(number)...), headerHashSuffix...)
 }
 
+// blockTimeKey = blockTimePrefix + num (uint64 big endian)
+func blockTimeKey(number uint64) []byte {
+	return append(blockTimePrefix, encodeBlockNumber(number)...)
+}
+
 // headerNumberKey = headerNumberPrefix + hash
 func headerNumberKey(hash common.Hash) []byte {
 	return append(headerNumberPrefix, hash.Bytes()...)
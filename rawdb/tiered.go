@@ -0,0 +1,198 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/fdb"
+)
+
+// Tier the class of the chain data, each tier may be stored on its own database
+// so the hot state and the ancient blocks live on different devices.
+type Tier int
+
+const (
+	// TierDefault the chain metadata, the head markers and anything unclassified
+	TierDefault Tier = iota
+	// TierState the state trie nodes, the contract codes and the preimages
+	TierState
+	// TierAncient the headers, bodies, receipts and detail txs of the blocks
+	TierAncient
+	// TierIndex the transaction lookups and the bloom bits
+	TierIndex
+	tierCount
+)
+
+var (
+	ancientPrefixes = [][]byte{headerPrefix, headerNumberPrefix, blockBodyPrefix, blockReceiptsPrefix, blockDetailTxsPrefix, blockTimePrefix}
+	indexPrefixes   = [][]byte{txLookupPrefix, bloomBitsPrefix, BloomBitsIndexPrefix}
+)
+
+// KeyTier returns the tier of the key. The trie nodes and the contract codes are
+// keyed by their bare hash, no other key of the schema has the hash length.
+func KeyTier(key []byte) Tier {
+	if len(key) == common.HashLength || bytes.HasPrefix(key, preimagePrefix) {
+		return TierState
+	}
+	for _, prefix := range ancientPrefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return TierAncient
+		}
+	}
+	for _, prefix := range indexPrefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return TierIndex
+		}
+	}
+	return TierDefault
+}
+
+// TieredDatabase routes the keys to the database of their tier.
+type TieredDatabase struct {
+	dbs [tierCount]fdb.Database
+}
+
+// NewTieredDatabase creates a database storing the tiers on the given databases,
+// the tiers not given are stored on the default database.
+func NewTieredDatabase(db fdb.Database, tiers map[Tier]fdb.Database) *TieredDatabase {
+	t := &TieredDatabase{}
+	for tier := range t.dbs {
+		t.dbs[tier] = db
+		if tdb, ok := tiers[Tier(tier)]; ok && tdb != nil {
+			t.dbs[tier] = tdb
+		}
+	}
+	return t
+}
+
+// Tier returns the database storing the tier.
+func (t *TieredDatabase) Tier(tier Tier) fdb.Database {
+	return t.dbs[tier]
+}
+
+// Databases returns the distinct databases, the default one first.
+func (t *TieredDatabase) Databases() []fdb.Database {
+	var dbs []fdb.Database
+	for _, db := range t.dbs {
+		exist := false
+		for _, d := range dbs {
+			if d == db {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			dbs = append(dbs, db)
+		}
+	}
+	return dbs
+}
+
+// Put implements fdb.Putter.
+func (t *TieredDatabase) Put(key []byte, value []byte) error {
+	return t.dbs[KeyTier(key)].Put(key, value)
+}
+
+// Delete implements fdb.Deleter.
+func (t *TieredDatabase) Delete(key []byte) error {
+	return t.dbs[KeyTier(key)].Delete(key)
+}
+
+// Get implements fdb.Database.
+func (t *TieredDatabase) Get(key []byte) ([]byte, error) {
+	return t.dbs[KeyTier(key)].Get(key)
+}
+
+// Has implements fdb.Database.
+func (t *TieredDatabase) Has(key []byte) (bool, error) {
+	return t.dbs[KeyTier(key)].Has(key)
+}
+
+// Close closes every database once.
+func (t *TieredDatabase) Close() {
+	for _, db := range t.Databases() {
+		db.Close()
+	}
+}
+
+// NewBatch implements fdb.Database.
+func (t *TieredDatabase) NewBatch() fdb.Batch {
+	return &tieredBatch{db: t}
+}
+
+// tieredBatch keeps a batch per database. The batches are not written atomically,
+// the default database holding the head markers is written last so the markers
+// never point to the data not yet stored.
+type tieredBatch struct {
+	db      *TieredDatabase
+	batches []fdb.Batch
+	owners  []fdb.Database
+}
+
+func (b *tieredBatch) batch(key []byte) fdb.Batch {
+	db := b.db.dbs[KeyTier(key)]
+	for i, owner := range b.owners {
+		if owner == db {
+			return b.batches[i]
+		}
+	}
+	b.owners = append(b.owners, db)
+	b.batches = append(b.batches, db.NewBatch())
+	return b.batches[len(b.batches)-1]
+}
+
+func (b *tieredBatch) Put(key []byte, value []byte) error {
+	return b.batch(key).Put(key, value)
+}
+
+func (b *tieredBatch) Delete(key []byte) error {
+	return b.batch(key).Delete(key)
+}
+
+func (b *tieredBatch) ValueSize() int {
+	size := 0
+	for _, batch := range b.batches {
+		size += batch.ValueSize()
+	}
+	return size
+}
+
+func (b *tieredBatch) Write() error {
+	def := b.db.dbs[TierDefault]
+	var last fdb.Batch
+	for i, batch := range b.batches {
+		if b.owners[i] == def {
+			last = batch
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	}
+	if last != nil {
+		return last.Write()
+	}
+	return nil
+}
+
+func (b *tieredBatch) Reset() {
+	for _, batch := range b.batches {
+		batch.Reset()
+	}
+}
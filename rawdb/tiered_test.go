@@ -0,0 +1,76 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/fdb"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+// Tests that the chain data is stored on the database of its tier.
+func TestTieredDatabase(t *testing.T) {
+	def, state, ancient := mdb.NewMemDatabase(), mdb.NewMemDatabase(), mdb.NewMemDatabase()
+	db := NewTieredDatabase(def, map[Tier]fdb.Database{TierState: state, TierAncient: ancient})
+	if len(db.Databases()) != 3 || db.Tier(TierIndex) != def {
+		t.Fatalf("databases %v, index tier %v", len(db.Databases()), db.Tier(TierIndex))
+	}
+
+	action := types.NewAction(types.Transfer, common.Name("fromtest"), common.Name("tototest"), 0, 0, 0, big.NewInt(0), nil, nil)
+	block := &types.Block{
+		Head: &types.Header{Number: big.NewInt(7), Coinbase: "coinbase"},
+		Txs:  []*types.Transaction{types.NewTransaction(0, big.NewInt(1), action)},
+	}
+	batch := db.NewBatch()
+	WriteBlock(batch, block)
+	WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+	WriteTxLookupEntries(batch, block)
+	WriteHeadBlockHash(batch, block.Hash())
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	node := common.BytesToHash([]byte("node")).Bytes()
+	if err := db.Put(node, []byte("rlp")); err != nil {
+		t.Fatal(err)
+	}
+
+	if ReadBlock(db, block.Hash(), block.NumberU64()) == nil || ReadHeadBlockHash(db) != block.Hash() {
+		t.Fatal("block not found in tiered database")
+	}
+	if ReadHeader(ancient, block.Hash(), block.NumberU64()) == nil || ReadBody(ancient, block.Hash(), block.NumberU64()) == nil {
+		t.Fatal("block not stored on the ancient tier")
+	}
+	if ReadHeader(def, block.Hash(), block.NumberU64()) != nil {
+		t.Fatal("block stored on the default tier")
+	}
+	if ReadHeadBlockHash(def) != block.Hash() {
+		t.Fatal("head marker not stored on the default tier")
+	}
+	if txn, _, _, _ := ReadTransaction(db, block.Txs[0].Hash()); txn == nil {
+		t.Fatal("tx lookup not found in tiered database")
+	}
+	if has, _ := def.Has(txLookupKey(block.Txs[0].Hash())); !has {
+		t.Fatal("tx lookup not stored on the default tier")
+	}
+	if has, _ := state.Has(node); !has {
+		t.Fatal("trie node not stored on the state tier")
+	}
+}
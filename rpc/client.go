@@ -34,6 +34,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common/amount"
 )
 
 var (
@@ -313,7 +314,7 @@ func (c *Client) CallContext(ctx context.Context, result interface{}, method str
 	case len(resp.Result) == 0:
 		return ErrNoResult
 	default:
-		return json.Unmarshal(resp.Result, &result)
+		return amount.UnmarshalJSON(resp.Result, &result)
 	}
 }
 
@@ -385,7 +386,7 @@ func (c *Client) BatchCallContext(ctx context.Context, b []BatchElem) error {
 			elem.Error = ErrNoResult
 			continue
 		}
-		elem.Error = json.Unmarshal(resp.Result, elem.Result)
+		elem.Error = amount.UnmarshalJSON(resp.Result, elem.Result)
 	}
 	return err
 }
@@ -817,7 +818,7 @@ func (sub *ClientSubscription) forward() (unsubscribeServer bool, err error) {
 
 func (sub *ClientSubscription) unmarshal(result json.RawMessage) (interface{}, error) {
 	val := reflect.New(sub.etype)
-	err := json.Unmarshal(result, val.Interface())
+	err := amount.UnmarshalJSON(result, val.Interface())
 	return val.Elem().Interface(), err
 }
 
@@ -27,6 +27,7 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common/amount"
 )
 
 const (
@@ -35,6 +36,9 @@ const (
 	subscribeMethodSuffix    = "_subscribe"
 	unsubscribeMethodSuffix  = "_unsubscribe"
 	notificationMethodSuffix = "_subscription"
+
+	// maxBatchRequests is the max requests of a batch, a larger batch is rejected as a whole
+	maxBatchRequests = 1024
 )
 
 type jsonRequest struct {
@@ -222,6 +226,9 @@ func parseBatchRequest(incomingMsg json.RawMessage) ([]rpcRequest, bool, Error)
 	if err := json.Unmarshal(incomingMsg, &in); err != nil {
 		return nil, false, &invalidMessageError{err.Error()}
 	}
+	if len(in) > maxBatchRequests {
+		return nil, true, &invalidRequestError{fmt.Sprintf("batch too large, max %d requests", maxBatchRequests)}
+	}
 
 	requests := make([]rpcRequest, len(in))
 	for i, r := range in {
@@ -320,7 +327,7 @@ func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type) ([]
 
 // CreateResponse will create a JSON-RPC success response with the given id and reply as result.
 func (c *jsonCodec) CreateResponse(id interface{}, reply interface{}) interface{} {
-	return &jsonSuccessResponse{Version: jsonrpcVersion, Id: id, Result: reply}
+	return &jsonSuccessResponse{Version: jsonrpcVersion, Id: id, Result: amount.StringifyBigInts(reply)}
 }
 
 // CreateErrorResponse will create a JSON-RPC error response with the given id and error.
@@ -338,7 +345,7 @@ func (c *jsonCodec) CreateErrorResponseWithInfo(id interface{}, err Error, info
 // CreateNotification will create a JSON-RPC notification with the given subscription id and event as params.
 func (c *jsonCodec) CreateNotification(subid, namespace string, event interface{}) interface{} {
 	return &jsonNotification{Version: jsonrpcVersion, Method: namespace + notificationMethodSuffix,
-		Params: jsonSubscription{Subscription: subid, Result: event}}
+		Params: jsonSubscription{Subscription: subid, Result: amount.StringifyBigInts(event)}}
 }
 
 // Write message to client
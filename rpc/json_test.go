@@ -176,3 +176,26 @@ func TestJSONRequestParamsParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestJSONBatchLimit(t *testing.T) {
+	read := func(n int) ([]rpcRequest, Error) {
+		msg := bytes.NewBufferString("[")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				msg.WriteString(",")
+			}
+			msg.WriteString(`{"id": ` + strconv.Itoa(i) + `, "jsonrpc": "2.0", "method": "calc_add", "params": [1, 2]}`)
+		}
+		msg.WriteString("]")
+		rw := &RWC{bufio.NewReadWriter(bufio.NewReader(msg), bufio.NewWriter(new(bytes.Buffer)))}
+		requests, _, err := NewJSONCodec(rw).ReadRequestHeaders()
+		return requests, err
+	}
+
+	if requests, err := read(maxBatchRequests); err != nil || len(requests) != maxBatchRequests {
+		t.Fatalf("full batch = %d requests, %v", len(requests), err)
+	}
+	if _, err := read(maxBatchRequests + 1); err == nil {
+		t.Fatal("batch beyond the limit accepted")
+	}
+}
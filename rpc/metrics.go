@@ -0,0 +1,106 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/metrics"
+)
+
+const (
+	// maxLoggedParamLen is the maximum length of a single parameter in the slow query log
+	maxLoggedParamLen = 64
+	// maxLoggedParams is the maximum number of parameters in the slow query log
+	maxLoggedParams = 8
+)
+
+// sensitiveMethodWords are the method name fragments of which the parameters
+// are never written to the slow query log.
+var sensitiveMethodWords = []string{"coinbase", "private", "passphrase", "password", "secret", "sign"}
+
+var methodTimers sync.Map // method name -> metrics.Timer
+
+// methodTimer returns the latency timer of the rpc method.
+func methodTimer(method string) metrics.Timer {
+	if t, ok := methodTimers.Load(method); ok {
+		return t.(metrics.Timer)
+	}
+	t, _ := methodTimers.LoadOrStore(method, metrics.GetOrRegisterTimer("rpc/duration/"+method, nil))
+	return t.(metrics.Timer)
+}
+
+// SetSlowQueryThreshold sets the duration above which a call is written to the
+// slow query log, 0 disables the log.
+func (s *Server) SetSlowQueryThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&s.slowQuery, int64(threshold))
+}
+
+// SlowQueryThreshold returns the duration above which a call is logged.
+func (s *Server) SlowQueryThreshold() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.slowQuery))
+}
+
+// recordCall records the latency of a call and logs it if it exceeds the slow query threshold.
+func (s *Server) recordCall(req *serverRequest, elapsed time.Duration) {
+	method := req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)
+	methodTimer(method).Update(elapsed)
+
+	if threshold := s.SlowQueryThreshold(); threshold > 0 && elapsed >= threshold {
+		log.Warn("Slow RPC query", "method", method, "elapsed", elapsed, "params", sanitizeParams(method, req.args))
+	}
+}
+
+// sanitizeParams formats the parameters of a call for logging, the values are
+// truncated and the parameters of sensitive methods are redacted.
+func sanitizeParams(method string, args []reflect.Value) string {
+	lower := strings.ToLower(method)
+	for _, word := range sensitiveMethodWords {
+		if strings.Contains(lower, word) {
+			return fmt.Sprintf("[%d redacted]", len(args))
+		}
+	}
+	params := make([]string, 0, len(args))
+	for i, arg := range args {
+		if i == maxLoggedParams {
+			params = append(params, fmt.Sprintf("...(%d more)", len(args)-i))
+			break
+		}
+		var p string
+		switch {
+		case !arg.IsValid():
+			p = "nil"
+		case arg.Kind() == reflect.Ptr && arg.IsNil():
+			p = "nil"
+		case arg.Kind() == reflect.Ptr:
+			p = fmt.Sprintf("%v", arg.Elem().Interface())
+		default:
+			p = fmt.Sprintf("%v", arg.Interface())
+		}
+		if len(p) > maxLoggedParamLen {
+			p = p[:maxLoggedParamLen] + "..."
+		}
+		params = append(params, p)
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
@@ -21,8 +21,11 @@ import (
 	"encoding/json"
 	"net"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/fractalplatform/fractal/metrics"
 )
 
 type Service struct{}
@@ -160,3 +163,27 @@ func TestServerMethodExecution(t *testing.T) {
 func TestServerMethodWithCtx(t *testing.T) {
 	testServerMethodExecution(t, "echoWithCtx")
 }
+
+func TestServerMethodLatency(t *testing.T) {
+	testServerMethodExecution(t, "echo")
+
+	timer, ok := metrics.DefaultRegistry.Get("rpc/duration/test_echo").(metrics.Timer)
+	if !ok {
+		t.Fatal("Expected latency timer of test_echo to be registered")
+	}
+	if timer.Count() == 0 {
+		t.Error("Expected latency of test_echo to be recorded")
+	}
+}
+
+func TestSanitizeParams(t *testing.T) {
+	long := strings.Repeat("a", maxLoggedParamLen+10)
+	args := []reflect.Value{reflect.ValueOf(long), reflect.ValueOf(1), reflect.ValueOf((*Args)(nil))}
+	want := "[" + long[:maxLoggedParamLen] + "..., 1, nil]"
+	if got := sanitizeParams("test_echo", args); got != want {
+		t.Errorf("sanitized params mismatch, got %s, want %s", got, want)
+	}
+	if got := sanitizeParams("miner_setCoinbase", args); got != "[3 redacted]" {
+		t.Errorf("params of sensitive method not redacted, got %s", got)
+	}
+}
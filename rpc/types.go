@@ -69,9 +69,10 @@ type subscriptions map[string]*callback  // collection of subscription callbacks
 type Server struct {
 	services serviceRegistry
 
-	run      int32
-	codecsMu sync.Mutex
-	codecs   set.Interface
+	run       int32
+	slowQuery int64 // slow query threshold in nanoseconds, 0 disables the log
+	codecsMu  sync.Mutex
+	codecs    set.Interface
 }
 
 // rpcRequest represents a raw incoming RPC request
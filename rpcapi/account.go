@@ -18,14 +18,21 @@ package rpcapi
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/fractalplatform/fractal/accountmanager"
 	"github.com/fractalplatform/fractal/asset"
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
 )
 
+// maxSnapshotTimes is the max snapshot times returned by a snapshot times request.
+const maxSnapshotTimes = 1024
+
 type AccountAPI struct {
 	b Backend
 }
@@ -112,6 +119,170 @@ func (aapi *AccountAPI) GetAccountByName(accountName common.Name) (*accountmanag
 	return accountObj, nil
 }
 
+//GetAccountByNameAtBlock get the account on the state of the block
+func (aapi *AccountAPI) GetAccountByNameAtBlock(ctx context.Context, accountName common.Name, blockNr rpc.BlockNumber) (*accountmanager.Account, error) {
+	am, err := aapi.b.GetAccountManagerByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAccountByName(accountName)
+}
+
+//CheckAccountAuthor
+func (aapi *AccountAPI) CheckAccountAuthor(accountName common.Name, acctAuth *accountmanager.AccountAuthorAction) (bool, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return false, err
+	}
+	if err := am.CheckAccountAuthor(accountName, acctAuth); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//VerifyTypedSignature
+func (aapi *AccountAPI) VerifyTypedSignature(accountName common.Name, data *types.TypedData, actionType types.ActionType, sigs []*types.TypedSignature) (bool, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return false, err
+	}
+	if err := am.VerifyTypedSignature(accountName, aapi.b.ChainConfig().ChainID, data, actionType, sigs); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//GetPermissionScope returns the author scope of the predefined permission
+func (aapi *AccountAPI) GetPermissionScope(permission types.Permission) ([]uint64, error) {
+	return permission.Scope()
+}
+
+//GetArchivedAccounts
+func (aapi *AccountAPI) GetArchivedAccounts(accountName common.Name) ([]*accountmanager.ArchivedAccount, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetArchivedAccounts(accountName)
+}
+
+//GetEscrowByID
+func (aapi *AccountAPI) GetEscrowByID(escrowID uint64) (*accountmanager.Escrow, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetEscrowByID(escrowID)
+}
+
+//GetAccountStorageSize
+func (aapi *AccountAPI) GetAccountStorageSize(accountName common.Name) (*accountmanager.AccountStorageSize, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAccountStorageSize(accountName)
+}
+
+//GetInheritancePolicy
+func (aapi *AccountAPI) GetInheritancePolicy(accountName common.Name) (*accountmanager.InheritancePolicy, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetInheritancePolicy(accountName)
+}
+
+//GetPendingAuthorUpdate
+func (aapi *AccountAPI) GetPendingAuthorUpdate(accountName common.Name) (*accountmanager.PendingAuthorUpdate, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetPendingAuthorUpdate(accountName)
+}
+
+//GetGuardians
+func (aapi *AccountAPI) GetGuardians(accountName common.Name) (*accountmanager.Guardians, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetGuardians(accountName)
+}
+
+//GetRecoveryProposal
+func (aapi *AccountAPI) GetRecoveryProposal(accountName common.Name) (*accountmanager.RecoveryProposal, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetRecoveryProposal(accountName)
+}
+
+//GetNameAuction
+func (aapi *AccountAPI) GetNameAuction(accountName common.Name) (*accountmanager.NameAuction, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetNameAuction(accountName)
+}
+
+//GetReservedNames
+func (aapi *AccountAPI) GetReservedNames() (*accountmanager.ReservedNames, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetReservedNames(aapi.b.ChainConfig())
+}
+
+//GetAllowance
+func (aapi *AccountAPI) GetAllowance(owner common.Name, spender common.Name, assetID uint64) (*big.Int, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAllowance(owner, spender, assetID)
+}
+
+//GetDistributionByID
+func (aapi *AccountAPI) GetDistributionByID(distributionID uint64) (*accountmanager.Distribution, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetDistributionByID(distributionID)
+}
+
+//GetScheduledByID
+func (aapi *AccountAPI) GetScheduledByID(scheduleID uint64) (*accountmanager.Scheduled, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetScheduledByID(scheduleID)
+}
+
+//GetInvitation
+func (aapi *AccountAPI) GetInvitation(codeHash common.Hash) (*accountmanager.Invitation, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetInvitation(codeHash)
+}
+
+//GetTimeLockByID
+func (aapi *AccountAPI) GetTimeLockByID(lockID uint64) (*accountmanager.TimeLock, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetTimeLockByID(lockID)
+}
+
 //GetAccountBalanceByID
 func (aapi *AccountAPI) GetAccountBalanceByID(accountName common.Name, assetID uint64, typeID uint64) (*big.Int, error) {
 	am, err := aapi.b.GetAccountManager()
@@ -121,6 +292,18 @@ func (aapi *AccountAPI) GetAccountBalanceByID(accountName common.Name, assetID u
 	return am.GetAccountBalanceByID(accountName, assetID, typeID)
 }
 
+//GetBalancesBatch
+func (aapi *AccountAPI) GetBalancesBatch(accounts []common.Name, assetIDs []uint64) ([][]*big.Int, error) {
+	if len(accounts)*len(assetIDs) > 2048 {
+		return nil, fmt.Errorf("balances batch size exceed %d", 2048)
+	}
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetBalancesBatch(accounts, assetIDs)
+}
+
 //GetCode
 func (aapi *AccountAPI) GetCode(accountName common.Name) (hexutil.Bytes, error) {
 	acct, err := aapi.b.GetAccountManager()
@@ -156,6 +339,19 @@ func (aapi *AccountAPI) GetAssetInfoByName(ctx context.Context, assetName string
 	return acct.GetAssetInfoByName(assetName)
 }
 
+//GetAssetIdBySymbol the namespace is the main asset or the account, omitted for the main assets
+func (aapi *AccountAPI) GetAssetIdBySymbol(symbol string, namespace *string) (uint64, error) {
+	acct, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return 0, err
+	}
+	ns := ""
+	if namespace != nil {
+		ns = *namespace
+	}
+	return acct.GetAssetIdBySymbol(ns, symbol)
+}
+
 //GetAssetInfoByID
 func (aapi *AccountAPI) GetAssetInfoByID(assetID uint64) (*asset.AssetObject, error) {
 	acct, err := aapi.b.GetAccountManager()
@@ -165,6 +361,238 @@ func (aapi *AccountAPI) GetAssetInfoByID(assetID uint64) (*asset.AssetObject, er
 	return acct.GetAssetInfoByID(assetID)
 }
 
+//GetTokenList
+func (aapi *AccountAPI) GetTokenList() ([]*asset.TokenInfo, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetTokenList()
+}
+
+//GetTokenInfo
+func (aapi *AccountAPI) GetTokenInfo(assetID uint64) (*asset.TokenInfo, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetTokenInfo(assetID)
+}
+
+//GetAssetAccessList
+func (aapi *AccountAPI) GetAssetAccessList(assetID uint64) (*asset.AssetAccessList, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetAccessList(assetID)
+}
+
+//GetAssetRoles
+func (aapi *AccountAPI) GetAssetRoles(assetID uint64) (*asset.AssetRoles, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetRoles(assetID)
+}
+
+//GetWrappedAsset
+func (aapi *AccountAPI) GetWrappedAsset(assetID uint64) (*asset.WrappedAsset, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetWrappedAsset(assetID)
+}
+
+//IsBridgeProofUsed
+func (aapi *AccountAPI) IsBridgeProofUsed(assetID uint64, proof hexutil.Bytes) (bool, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return false, err
+	}
+	return am.IsBridgeProofUsed(assetID, proof)
+}
+
+//IsAssetPaused
+func (aapi *AccountAPI) IsAssetPaused(assetID uint64) (bool, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return false, err
+	}
+	return am.IsAssetPaused(assetID)
+}
+
+//IsAssetBurnable
+func (aapi *AccountAPI) IsAssetBurnable(assetID uint64) (bool, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return false, err
+	}
+	return am.IsAssetBurnable(assetID)
+}
+
+//GetAssetTransferFee
+func (aapi *AccountAPI) GetAssetTransferFee(assetID uint64) (uint64, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return 0, err
+	}
+	return am.GetAssetTransferFee(assetID)
+}
+
+//GetAssetMetadata
+func (aapi *AccountAPI) GetAssetMetadata(assetID uint64) (*asset.AssetMetadata, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetMetadata(assetID)
+}
+
+//GetAssetSupplyHistory
+func (aapi *AccountAPI) GetAssetSupplyHistory(assetID uint64, fromBlock uint64, toBlock uint64) ([]*asset.SupplyChange, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetSupplyHistory(assetID, fromBlock, toBlock)
+}
+
+//GetSubAssetDelegation
+func (aapi *AccountAPI) GetSubAssetDelegation(assetID uint64, delegate common.Name) (*asset.SubAssetDelegation, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetSubAssetDelegation(assetID, delegate)
+}
+
+//GetPendingAssetOwner
+func (aapi *AccountAPI) GetPendingAssetOwner(assetID uint64) (*asset.PendingAssetOwner, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetPendingAssetOwner(assetID)
+}
+
+//GetUpperLimitApprovers
+func (aapi *AccountAPI) GetUpperLimitApprovers(assetID uint64) ([]common.Name, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetUpperLimitApprovers(assetID)
+}
+
+//GetAssetVerifier
+func (aapi *AccountAPI) GetAssetVerifier(assetID uint64) (common.Name, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return "", err
+	}
+	return am.GetAssetVerifier(assetID)
+}
+
+//GetPendingUpperLimit
+func (aapi *AccountAPI) GetPendingUpperLimit(assetID uint64) (*asset.PendingUpperLimit, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetPendingUpperLimit(assetID)
+}
+
+//GetAssetRedenomination
+func (aapi *AccountAPI) GetAssetRedenomination(assetID uint64) (*accountmanager.Redenomination, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetRedenomination(assetID)
+}
+
+//GetHolderLocks
+func (aapi *AccountAPI) GetHolderLocks(accountName common.Name) ([]*accountmanager.HolderLock, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetHolderLocks(accountName)
+}
+
+//GetAccountPartition
+func (aapi *AccountAPI) GetAccountPartition(accountName common.Name) uint64 {
+	return types.AccountPartition(accountName, aapi.b.ChainConfig().Partitions())
+}
+
+//GetAccounts get the accounts without their balances from the account id cursor,
+//next is the cursor of the next page, 0 if no more
+func (aapi *AccountAPI) GetAccounts(cursor uint64, limit uint64) (*accountmanager.AccountList, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAccounts(cursor, limit)
+}
+
+//GetAssetHolders
+func (aapi *AccountAPI) GetAssetHolders(assetID uint64, cursor uint64, limit uint64) (*accountmanager.AssetHolders, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetHolders(assetID, cursor, limit)
+}
+
+// GetHoldersAt returns the holders of the asset with their balances at the snapshot of the time,
+// from the account id cursor. Next is the cursor of the next page, 0 if no more.
+func (aapi *AccountAPI) GetHoldersAt(ctx context.Context, assetID uint64, time uint64, cursor uint64, limit uint64) (*accountmanager.AssetHolders, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.HoldersAt(assetID, time, cursor, limit)
+}
+
+//GetAssetsByOwner
+func (aapi *AccountAPI) GetAssetsByOwner(owner common.Name, cursor uint64, limit uint64) (*asset.AccountAssets, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetsByOwner(owner, cursor, limit)
+}
+
+//GetAssetsByFounder
+func (aapi *AccountAPI) GetAssetsByFounder(founder common.Name, cursor uint64, limit uint64) (*asset.AccountAssets, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetsByFounder(founder, cursor, limit)
+}
+
+//GetNFToken
+func (aapi *AccountAPI) GetNFToken(assetID uint64, tokenID uint64) (*asset.NFToken, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetNFToken(assetID, tokenID)
+}
+
+//GetAssetConversion
+func (aapi *AccountAPI) GetAssetConversion(subID uint64) (*asset.AssetConversion, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetConversion(subID)
+}
+
 //GetAssetAmountByTime
 func (aapi *AccountAPI) GetAssetAmountByTime(assetID uint64, time uint64) (*big.Int, error) {
 	am, err := aapi.b.GetAccountManager()
@@ -183,6 +611,80 @@ func (aapi *AccountAPI) GetAccountBalanceByTime(accountName common.Name, assetID
 	return am.GetBalanceByTime(accountName, assetID, typeID, time)
 }
 
+//GetAccountBalanceAtBlock get the account balance on the state of the block
+func (aapi *AccountAPI) GetAccountBalanceAtBlock(ctx context.Context, accountName common.Name, assetID uint64, typeID uint64, blockNr rpc.BlockNumber) (*big.Int, error) {
+	am, err := aapi.b.GetAccountManagerByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAccountBalanceByID(accountName, assetID, typeID)
+}
+
+// SweepArgs represents the arguments to generate the sweep transactions.
+type SweepArgs struct {
+	Accounts   []common.Name `json:"accounts"`
+	AssetID    uint64        `json:"assetId"`
+	Time       uint64        `json:"time"` // snapshot time, 0 use the last snapshot
+	To         common.Name   `json:"to"`
+	GasAssetID uint64        `json:"gasAssetId"`
+	GasPrice   *big.Int      `json:"gasPrice"`
+	GasLimit   uint64        `json:"gasLimit"`
+	Remark     hexutil.Bytes `json:"remark"`
+}
+
+// SweepTransaction represents an unsigned sweep transaction of the account.
+type SweepTransaction struct {
+	*accountmanager.SweepBalance
+	RawTx hexutil.Bytes `json:"rawTx"`
+}
+
+//GetSweepTransactions generate the unsigned transactions to sweep the snapshot balances to the destination
+func (aapi *AccountAPI) GetSweepTransactions(args SweepArgs) ([]*SweepTransaction, error) {
+	if len(args.Accounts) > 2048 {
+		return nil, fmt.Errorf("sweep accounts exceed %d", 2048)
+	}
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	if exist, err := am.AccountIsExist(args.To); err != nil {
+		return nil, err
+	} else if !exist {
+		return nil, accountmanager.ErrAccountNotExist
+	}
+	if args.Time == 0 {
+		if args.Time, err = am.GetSnapshotTime(0, 0); err != nil {
+			return nil, err
+		}
+	}
+	gasPrice := args.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(0)
+	}
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(args.GasLimit))
+
+	balances, err := am.GetSweepBalances(args.Accounts, args.AssetID, args.Time)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*SweepTransaction, 0, len(balances))
+	for _, balance := range balances {
+		if args.AssetID == args.GasAssetID {
+			balance.Amount.Sub(balance.Amount, fee)
+		}
+		if balance.Amount.Sign() <= 0 || balance.Account == args.To {
+			continue
+		}
+		action := types.NewAction(types.Transfer, balance.Account, args.To, balance.Nonce, args.AssetID, args.GasLimit, balance.Amount, nil, args.Remark)
+		rawTx, err := rlp.EncodeToBytes(types.NewTransaction(args.GasAssetID, gasPrice, action))
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, &SweepTransaction{SweepBalance: balance, RawTx: rawTx})
+	}
+	return txs, nil
+}
+
 //GetSnapshotLast  get last snapshot time
 func (aapi *AccountAPI) GetSnapshotLast() (uint64, error) {
 	am, err := aapi.b.GetAccountManager()
@@ -201,3 +703,37 @@ func (aapi *AccountAPI) GetSnapshotTime(ctx context.Context, m uint64, time uint
 	}
 	return am.GetSnapshotTime(m, time)
 }
+
+// GetSnapshotTimes returns the times of the last max snapshots the account
+// states and balances are queried by, the latest first.
+func (aapi *AccountAPI) GetSnapshotTimes(ctx context.Context, max int) ([]uint64, error) {
+	if max <= 0 || max > maxSnapshotTimes {
+		return nil, fmt.Errorf("max should be in (0, %d]", maxSnapshotTimes)
+	}
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetSnapshotTimes(max)
+}
+
+// GetBalanceHistory returns the balances of the asset of the account from fromTime to toTime
+// every step, each read from the last snapshot at or before its time. Step 0 returns the
+// balance at every snapshot in the range.
+func (aapi *AccountAPI) GetBalanceHistory(ctx context.Context, accountName common.Name, assetID uint64, fromTime uint64, toTime uint64, step uint64) ([]*accountmanager.BalancePoint, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetBalanceHistory(accountName, assetID, fromTime, toTime, step)
+}
+
+// GetSnapshotDiff returns the state entries changed from the snapshot of time1 to the snapshot
+// of time2, with the accounts, the balances and the assets they belong to.
+func (aapi *AccountAPI) GetSnapshotDiff(ctx context.Context, time1 uint64, time2 uint64) (*accountmanager.SnapshotDiff, error) {
+	am, err := aapi.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetSnapshotDiff(time1, time2)
+}
@@ -57,8 +57,11 @@ type Backend interface {
 	GetTd(blockHash common.Hash) *big.Int
 	GetEVM(ctx context.Context, account *accountmanager.AccountManager, state *state.StateDB, from common.Name, to common.Name, assetID uint64, gasPrice *big.Int, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error)
 	GetDetailTxByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64) []*types.DetailTx
-	GetTxsByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64) *types.AccountTxs
+	GetTxsByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64, cursor *types.TxPosition, limit uint64) *types.AccountTxs
 	GetBadBlocks(ctx context.Context) ([]*types.Block, error)
+	GetExecStats(ctx context.Context, blockHash common.Hash) *types.ExecStats
+	GetBlockTree(ctx context.Context, count uint64) *types.BlockTree
+	VerifyState(ctx context.Context, blockNr rpc.BlockNumber, repair bool) (*types.StateReport, error)
 	SetStatePruning(enable bool) (bool, uint64)
 
 	// TxPool
@@ -69,6 +72,7 @@ type Backend interface {
 
 	//Account API
 	GetAccountManager() (*accountmanager.AccountManager, error)
+	GetAccountManagerByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*accountmanager.AccountManager, error)
 
 	//fee manager
 	GetFeeManager() (*feemanager.FeeManager, error)
@@ -122,6 +126,12 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Service:   filters.NewPublicFilterAPI(apiBackend),
 			Public:    true,
 		},
+		{
+			Namespace: "fractal",
+			Version:   "1.0",
+			Service:   NewPublicProofAPI(apiBackend),
+			Public:    true,
+		},
 		{
 			Namespace: "account",
 			Version:   "1.0",
@@ -132,6 +142,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewFeeAPI(apiBackend),
 			Public:    true,
+		}, {
+			Namespace: "chain",
+			Version:   "1.0",
+			Service:   NewChainAPI(apiBackend),
+			Public:    true,
 		},
 		{
 			Namespace: "p2p",
@@ -143,6 +158,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   debug.Handler,
 		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   NewPrivateDebugAPI(apiBackend),
+		},
 	}
 	return append(apis, apiBackend.APIs()...)
 }
@@ -32,9 +32,13 @@ import (
 	"github.com/fractalplatform/fractal/processor/vm"
 	"github.com/fractalplatform/fractal/rawdb"
 	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/txpool"
 	"github.com/fractalplatform/fractal/types"
 )
 
+// maxTxsLimit is the max txs returned by a txs range request.
+const maxTxsLimit = 1000
+
 // PublicBlockChainAPI provides an API to access the blockchain.
 // It offers only methods that operate on public data that is freely available to anyone.
 type PublicBlockChainAPI struct {
@@ -72,6 +76,24 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, blockNr rpc.
 	return nil
 }
 
+// GetBlockNumberByTime returns the number of the last block at or before the time.
+func (s *PublicBlockChainAPI) GetBlockNumberByTime(ctx context.Context, time uint64) (uint64, error) {
+	number, ok := rawdb.FindBlockNumberByTime(s.b.ChainDb(), s.b.CurrentBlock().NumberU64(), time)
+	if !ok {
+		return 0, fmt.Errorf("no block at or before time %d", time)
+	}
+	return number, nil
+}
+
+// GetBlockByTime returns the last block at or before the time.
+func (s *PublicBlockChainAPI) GetBlockByTime(ctx context.Context, time uint64, fullTx bool) (map[string]interface{}, error) {
+	number, err := s.GetBlockNumberByTime(ctx, time)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetBlockByNumber(ctx, rpc.BlockNumber(number), fullTx), nil
+}
+
 // rpcOutputBlock uses the generalized output filler, then adds the total difficulty field, which requires
 // a `PublicBlockchainAPI`.
 func (s *PublicBlockChainAPI) rpcOutputBlock(chainID *big.Int, b *types.Block, inclTx bool, fullTx bool) map[string]interface{} {
@@ -95,6 +117,11 @@ func (s *PublicBlockChainAPI) GetTransactionByHash(ctx context.Context, hash com
 	return nil
 }
 
+// GetRejectedTransaction returns why the transaction pool recently refused or dropped the transaction for the given hash
+func (s *PublicBlockChainAPI) GetRejectedTransaction(ctx context.Context, hash common.Hash) *txpool.RejectedTx {
+	return s.b.TxPool().GetRejected(hash)
+}
+
 func (s *PublicBlockChainAPI) GetTransBatch(ctx context.Context, hashes []common.Hash) []*types.RPCTransaction {
 	txs := make([]*types.RPCTransaction, 0)
 
@@ -126,7 +153,32 @@ func (s *PublicBlockChainAPI) GetTransactionReceipt(ctx context.Context, hash co
 		return nil, nil
 	}
 	receipt := receipts[index]
-	return receipt.NewRPCReceipt(blockHash, blockNumber, index, tx), nil
+	result := receipt.NewRPCReceipt(blockHash, blockNumber, index, tx)
+	if detailtxs := rawdb.ReadDetailTxs(s.b.ChainDb(), blockHash, blockNumber); int(index) < len(detailtxs) {
+		result.SetInternalActionIDs(detailtxs[index])
+	}
+	return result, nil
+}
+
+// GetPartitionReceipts returns the receipts of the value the transaction moved across the
+// account partitions, empty if the partitioning is disabled.
+func (s *PublicBlockChainAPI) GetPartitionReceipts(ctx context.Context, hash common.Hash) ([]*types.PartitionReceipt, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(s.b.ChainDb(), hash)
+	if tx == nil {
+		return nil, nil
+	}
+	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) <= int(index) {
+		return nil, nil
+	}
+	var dtx *types.DetailTx
+	if detailtxs := rawdb.ReadDetailTxs(s.b.ChainDb(), blockHash, blockNumber); int(index) < len(detailtxs) {
+		dtx = detailtxs[index]
+	}
+	return types.PartitionReceipts(tx, receipts[index], dtx, s.b.ChainConfig().Partitions()), nil
 }
 
 func (s *PublicBlockChainAPI) GetBlockAndResultByNumber(ctx context.Context, blockNr rpc.BlockNumber) *types.BlockAndResult {
@@ -151,8 +203,9 @@ func (s *PublicBlockChainAPI) checkRangeInputArgs(blockNr, lookbackNum uint64) e
 
 // GetTxsByAccount return all txs, sent from or received by a specific account
 // the range is indicate by blockNr and lookforwardNum,
-// from blocks with number from blockNr to blockNr+lookforwardNum
-func (s *PublicBlockChainAPI) GetTxsByAccount(ctx context.Context, acctName common.Name, blockNr rpc.BlockNumber, lookforwardNum uint64) (*types.AccountTxs, error) {
+// from blocks with number from blockNr to blockNr+lookforwardNum.
+// At most limit txs are returned, the next of the result is the cursor to continue the range.
+func (s *PublicBlockChainAPI) GetTxsByAccount(ctx context.Context, acctName common.Name, blockNr rpc.BlockNumber, lookforwardNum uint64, cursor *types.TxPosition, limit *uint64) (*types.AccountTxs, error) {
 	// check input argments
 	ui64BlockNr := uint64(blockNr)
 	if err := s.checkRangeInputArgs(ui64BlockNr, lookforwardNum); err != nil {
@@ -163,14 +216,15 @@ func (s *PublicBlockChainAPI) GetTxsByAccount(ctx context.Context, acctName comm
 		return name == acctName
 	}
 
-	return s.b.GetTxsByFilter(ctx, filterFn, ui64BlockNr, lookforwardNum), nil
+	return s.b.GetTxsByFilter(ctx, filterFn, ui64BlockNr, lookforwardNum, cursor, txsLimit(limit)), nil
 }
 
 // GetTxsByBloom return all txs, filtered by a bloomByte
 // bloomByte is constructed by some quantities of account names
 // the range is indicate by blockNr and lookbackNum,
-// from blocks with number from blockNr to blockNr+lookforwardNum
-func (s *PublicBlockChainAPI) GetTxsByBloom(ctx context.Context, bloomByte hexutil.Bytes, blockNr rpc.BlockNumber, lookforwardNum uint64) (*types.AccountTxs, error) {
+// from blocks with number from blockNr to blockNr+lookforwardNum.
+// At most limit txs are returned, the next of the result is the cursor to continue the range.
+func (s *PublicBlockChainAPI) GetTxsByBloom(ctx context.Context, bloomByte hexutil.Bytes, blockNr rpc.BlockNumber, lookforwardNum uint64, cursor *types.TxPosition, limit *uint64) (*types.AccountTxs, error) {
 	// check input argments
 	ui64BlockNr := uint64(blockNr)
 	if err := s.checkRangeInputArgs(ui64BlockNr, lookforwardNum); err != nil {
@@ -181,7 +235,15 @@ func (s *PublicBlockChainAPI) GetTxsByBloom(ctx context.Context, bloomByte hexut
 	filterFn := func(name common.Name) bool {
 		return bloom.TestBytes([]byte(name))
 	}
-	return s.b.GetTxsByFilter(ctx, filterFn, ui64BlockNr, lookforwardNum), nil
+	return s.b.GetTxsByFilter(ctx, filterFn, ui64BlockNr, lookforwardNum, cursor, txsLimit(limit)), nil
+}
+
+// txsLimit the txs returned by a txs range request, maxTxsLimit if not set or beyond it
+func txsLimit(limit *uint64) uint64 {
+	if limit == nil || *limit == 0 || *limit > maxTxsLimit {
+		return maxTxsLimit
+	}
+	return *limit
 }
 
 // GetInternalTxByAccount return all logs of interal txs, sent from or received by a specific account
@@ -241,6 +303,15 @@ func (s *PublicBlockChainAPI) GetInternalTxByHash(ctx context.Context, hash comm
 	return detailtxs[index], nil
 }
 
+// GetExecStats returns the execution statistics of the recent processed block.
+func (s *PublicBlockChainAPI) GetExecStats(ctx context.Context, blockNr rpc.BlockNumber) *types.ExecStats {
+	block := s.b.BlockByNumber(ctx, blockNr)
+	if block == nil {
+		return nil
+	}
+	return s.b.GetExecStats(ctx, block.Hash())
+}
+
 func (s *PublicBlockChainAPI) GetBadBlocks(ctx context.Context, fullTx bool) ([]map[string]interface{}, error) {
 	blocks, err := s.b.GetBadBlocks(ctx)
 	if len(blocks) != 0 {
@@ -265,7 +336,7 @@ type CallArgs struct {
 	Remark     hexutil.Bytes    `json:"remark"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
@@ -276,11 +347,23 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if err != nil {
 		return nil, 0, false, err
 	}
+	if err := overrides.Apply(account, state); err != nil {
+		return nil, 0, false, err
+	}
 
 	gasPrice := args.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(0)
+	}
 	value := args.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
 	assetID := uint64(args.AssetID)
 	gas := uint64(args.Gas)
+	if gas == 0 {
+		gas = header.GasLimit
+	}
 
 	var cancel context.CancelFunc
 	if timeout > 0 {
@@ -297,6 +380,10 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if err != nil {
 		return nil, 0, false, err
 	}
+	// A call without gas price is free, it is not bound to the base fee
+	if gasPrice.Sign() == 0 {
+		evm.Context.BaseFee = nil
+	}
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -308,7 +395,7 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	// and apply the message.
 	gp := new(common.GasPool).AddGas(math.MaxUint64)
 	action := types.NewAction(args.ActionType, args.From, args.To, 0, assetID, gas, value, args.Data, args.Remark)
-	res, gas, failed, err, _ := processor.ApplyMessage(account, evm, action, gp, gasPrice, assetID, s.b.ChainConfig(), s.b.Engine())
+	res, gas, failed, err, _ := processor.ApplyMessage(account, evm, action, action.Sender(), gp, gasPrice, assetID, s.b.ChainConfig(), s.b.Engine())
 	if err := vmError(); err != nil {
 		return nil, 0, false, err
 	}
@@ -317,8 +404,10 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{}, 5*time.Second)
+// The optional overrides replace the balances, code and storage slots of the accounts before the call,
+// the gas price defaults to zero so the call is free.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride) (hexutil.Bytes, error) {
+	result, _, _, err := s.doCall(ctx, args, blockNr, overrides, vm.Config{}, 5*time.Second)
 	return (hexutil.Bytes)(result), err
 }
 
@@ -343,7 +432,7 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	// Create a helper to check if a gas allowance results in an executable transaction
 	executable := func(gas uint64) bool {
 		args.Gas = gas
-		_, _, failed, err := s.doCall(ctx, args, rpc.LatestBlockNumber, vm.Config{}, 0)
+		_, _, failed, err := s.doCall(ctx, args, rpc.LatestBlockNumber, nil, vm.Config{}, 0)
 		if err != nil || failed {
 			return false
 		}
@@ -0,0 +1,127 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/params"
+)
+
+type ChainAPI struct {
+	b Backend
+}
+
+func NewChainAPI(b Backend) *ChainAPI {
+	return &ChainAPI{b}
+}
+
+// NameRules is the rules a new account or asset name must match.
+type NameRules struct {
+	AccountRegExp      string `json:"accountRegExp"`
+	AccountRegExpFork1 string `json:"accountRegExpFork1"`
+	AccountMaxLength   uint64 `json:"accountMaxLength"`
+	AssetRegExp        string `json:"assetRegExp"`
+	AssetMaxLength     uint64 `json:"assetMaxLength"`
+}
+
+// SystemAccounts is the names of the system accounts of the chain.
+type SystemAccounts struct {
+	SysName     string `json:"systemName"`
+	AccountName string `json:"accountName"`
+	AssetName   string `json:"assetName"`
+	DposName    string `json:"dposName"`
+	FeeName     string `json:"feeName"`
+}
+
+// ChainFeatures is the optional modules enabled by the chain config.
+type ChainFeatures struct {
+	Rent          bool `json:"rent"`
+	NameReclaim   bool `json:"nameReclaim"`
+	NameReuse     bool `json:"nameReuse"`
+	NameCreateFee bool `json:"nameCreateFee"`
+	NameAuction   bool `json:"nameAuction"`
+	AuthorLimit   bool `json:"authorLimit"`
+	FeeMarket     bool `json:"feeMarket"`
+	Scheduler     bool `json:"scheduler"`
+	RemarkLimit   bool `json:"remarkLimit"`
+	BlockSize     bool `json:"blockSizeLimit"`
+	ReplayProtect bool `json:"replayProtection"`
+	BLSAuthor     bool `json:"blsAuthor"`
+}
+
+// ChainConfigInfo is the result of chain_getConfig.
+type ChainConfigInfo struct {
+	CurForkID      uint64               `json:"curForkID"`
+	NextForkID     uint64               `json:"nextForkID"`
+	Features       *ChainFeatures       `json:"features"`
+	NameRules      *NameRules           `json:"nameRules"`
+	SystemAccounts *SystemAccounts      `json:"systemAccounts"`
+	AuthorLimit    *params.AuthorConfig `json:"authorLimit"`
+	Config         *params.ChainConfig  `json:"config"`
+}
+
+func chainFeatures(cfg *params.ChainConfig, forkID uint64) *ChainFeatures {
+	features := &ChainFeatures{
+		Rent:          cfg.RentCfg != nil && cfg.RentCfg.StorageQuota != 0,
+		AuthorLimit:   cfg.AuthorCfg != nil,
+		FeeMarket:     cfg.IsFeeMarket(forkID),
+		Scheduler:     cfg.IsScheduler(),
+		RemarkLimit:   cfg.RemarkCfg != nil,
+		BlockSize:     cfg.MaxBlockSize() != 0,
+		ReplayProtect: forkID >= params.ForkID4,
+		BLSAuthor:     cfg.AuthorLimit().AllowBLS,
+	}
+	if cfg.AccountNameCfg != nil {
+		features.NameReclaim = cfg.AccountNameCfg.ReclaimBlocks != 0
+		features.NameReuse = features.NameReclaim && cfg.AccountNameCfg.AllowReuse
+	}
+	if cfg.NamePriceCfg != nil {
+		features.NameCreateFee = cfg.NamePriceCfg.CreateFee != nil && cfg.NamePriceCfg.CreateFee.Sign() > 0
+		features.NameAuction = cfg.NamePriceCfg.PremiumLength != 0
+	}
+	return features
+}
+
+// GetConfig returns the active chain config with the enabled features, name rules and system accounts
+func (capi *ChainAPI) GetConfig(ctx context.Context) *ChainConfigInfo {
+	cfg := capi.b.ChainConfig()
+	head := capi.b.CurrentBlock().Header()
+	return &ChainConfigInfo{
+		CurForkID:  head.CurForkID(),
+		NextForkID: head.NextForkID(),
+		Features:   chainFeatures(cfg, head.CurForkID()),
+		NameRules: &NameRules{
+			AccountRegExp:      accountmanager.GetAcountNameRegExp().String(),
+			AccountRegExpFork1: accountmanager.GetAcountNameRegExpFork1().String(),
+			AccountMaxLength:   accountmanager.GetAcountNameLength(),
+			AssetRegExp:        asset.GetAssetNameRegExp().String(),
+			AssetMaxLength:     asset.GetAssetNameLength(),
+		},
+		SystemAccounts: &SystemAccounts{
+			SysName:     cfg.SysName,
+			AccountName: cfg.AccountName,
+			AssetName:   cfg.AssetName,
+			DposName:    cfg.DposName,
+			FeeName:     cfg.FeeName,
+		},
+		AuthorLimit: cfg.AuthorLimit(),
+		Config:      cfg,
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// PrivateDebugAPI provides the chain debugging methods.
+type PrivateDebugAPI struct {
+	b Backend
+}
+
+func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
+	return &PrivateDebugAPI{b}
+}
+
+//BlockTree get the blocks known at the recent heights with the chosen head of each height, at most count heights
+func (dapi *PrivateDebugAPI) BlockTree(ctx context.Context, count uint64) *types.BlockTree {
+	return dapi.b.GetBlockTree(ctx, count)
+}
+
+//VerifyState walk the state trie of a block checking its nodes, with repair the bad nodes are downloaded from the peers
+func (dapi *PrivateDebugAPI) VerifyState(ctx context.Context, blockNr rpc.BlockNumber, repair bool) (*types.StateReport, error) {
+	return dapi.b.VerifyState(ctx, blockNr, repair)
+}
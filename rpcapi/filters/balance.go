@@ -0,0 +1,239 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/fdb"
+)
+
+const (
+	// maxBalanceAccounts is the max accounts a balance subscription watches.
+	maxBalanceAccounts = 1024
+	// maxConfirmations is the max confirmations of a balance subscription.
+	maxConfirmations = 1024
+	// balanceReorgWindow is the blocks the confirmed events are kept for the reorg check.
+	balanceReorgWindow = 2048
+)
+
+const (
+	// BalanceCredit is the event type of the value received by the account.
+	BalanceCredit = "credit"
+	// BalanceDebit is the event type of the value sent by the account.
+	BalanceDebit = "debit"
+)
+
+var errBalanceCriteria = errors.New("invalid balance criteria")
+
+// BalanceCriteria represents a request to watch the balance changes of the accounts.
+type BalanceCriteria struct {
+	Accounts      []common.Name `json:"accounts"`
+	Confirmations uint64        `json:"confirmations"` // blocks on top of the block before its events are sent
+}
+
+// BalanceEvent is a value transfer of a watched account. A reverted event compensates
+// the event with the same fields sent before, its block is no longer canonical.
+type BalanceEvent struct {
+	Account      common.Name `json:"account"`
+	Type         string      `json:"type"`
+	AssetID      uint64      `json:"assetID"`
+	Amount       *big.Int    `json:"amount"`
+	Counterparty common.Name `json:"counterparty"`
+	BlockNumber  uint64      `json:"blockNumber"`
+	BlockHash    common.Hash `json:"blockHash"`
+	TxHash       common.Hash `json:"txHash"`
+	ActionIndex  uint64      `json:"actionIndex"`
+	Internal     bool        `json:"internal"`
+	Reverted     bool        `json:"reverted"`
+}
+
+// confirmedBlock is a block whose events were sent.
+type confirmedBlock struct {
+	number uint64
+	hash   common.Hash
+	events []*BalanceEvent
+}
+
+// balanceTracker sends the events of the blocks once they have enough confirmations
+// and reverts them if their blocks leave the canonical chain.
+type balanceTracker struct {
+	db            fdb.Database
+	accounts      map[common.Name]bool
+	confirmations uint64
+	next          uint64 // the next block to confirm
+	confirmed     []*confirmedBlock
+}
+
+func newBalanceTracker(db fdb.Database, crit BalanceCriteria, head uint64) (*balanceTracker, error) {
+	if len(crit.Accounts) == 0 || len(crit.Accounts) > maxBalanceAccounts || crit.Confirmations > maxConfirmations {
+		return nil, errBalanceCriteria
+	}
+	accounts := make(map[common.Name]bool, len(crit.Accounts))
+	for _, name := range crit.Accounts {
+		accounts[name] = true
+	}
+	next := uint64(1)
+	if head+1 > crit.Confirmations+next {
+		next = head + 1 - crit.Confirmations
+	}
+	return &balanceTracker{db: db, accounts: accounts, confirmations: crit.Confirmations, next: next}, nil
+}
+
+// update returns the reverted events of the blocks left the canonical chain and the
+// events of the blocks confirmed by the new head.
+func (bt *balanceTracker) update(head uint64) []*BalanceEvent {
+	var events []*BalanceEvent
+	for i, block := range bt.confirmed {
+		if rawdb.ReadCanonicalHash(bt.db, block.number) == block.hash {
+			continue
+		}
+		for j := len(bt.confirmed) - 1; j >= i; j-- {
+			events = append(events, revertBalanceEvents(bt.confirmed[j].events)...)
+		}
+		bt.next = block.number
+		bt.confirmed = bt.confirmed[:i]
+		break
+	}
+
+	for ; bt.next+bt.confirmations <= head; bt.next++ {
+		hash := rawdb.ReadCanonicalHash(bt.db, bt.next)
+		block := rawdb.ReadBlock(bt.db, hash, bt.next)
+		if block == nil {
+			break
+		}
+		blockEvents := bt.blockEvents(block)
+		bt.confirmed = append(bt.confirmed, &confirmedBlock{number: bt.next, hash: hash, events: blockEvents})
+		events = append(events, blockEvents...)
+	}
+
+	for len(bt.confirmed) > 0 && bt.confirmed[0].number+balanceReorgWindow < head {
+		bt.confirmed = bt.confirmed[1:]
+	}
+	return events
+}
+
+// blockEvents returns the value transfers of the watched accounts in the block, the
+// internal actions are included if the node keeps the contract logs.
+func (bt *balanceTracker) blockEvents(block *types.Block) []*BalanceEvent {
+	var events []*BalanceEvent
+	receipts := rawdb.ReadReceipts(bt.db, block.Hash(), block.NumberU64())
+	detailTxs := rawdb.ReadDetailTxs(bt.db, block.Hash(), block.NumberU64())
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) {
+			break
+		}
+		for j, action := range tx.GetActions() {
+			if j >= len(receipts[i].ActionResults) || receipts[i].ActionResults[j].Status != types.ReceiptStatusSuccessful {
+				continue
+			}
+			base := BalanceEvent{BlockNumber: block.NumberU64(), BlockHash: block.Hash(), TxHash: tx.Hash(), ActionIndex: uint64(j)}
+			events = append(events, bt.transferEvents(base, action.Sender(), action.Recipient(), action.AssetID(), action.Value())...)
+
+			if i >= len(detailTxs) || j >= len(detailTxs[i].Actions) {
+				continue
+			}
+			base.Internal = true
+			for _, internal := range detailTxs[i].Actions[j].InternalActions {
+				if internal.Error != "" || internal.Action == nil || internal.Action.Amount == nil {
+					continue
+				}
+				events = append(events, bt.transferEvents(base, internal.Action.From, internal.Action.To, internal.Action.AssetID, internal.Action.Amount)...)
+			}
+		}
+	}
+	return events
+}
+
+func (bt *balanceTracker) transferEvents(base BalanceEvent, from, to common.Name, assetID uint64, amount *big.Int) []*BalanceEvent {
+	if amount.Sign() <= 0 || from == to {
+		return nil
+	}
+	var events []*BalanceEvent
+	if bt.accounts[from] {
+		event := base
+		event.Account, event.Type, event.Counterparty = from, BalanceDebit, to
+		event.AssetID, event.Amount = assetID, new(big.Int).Set(amount)
+		events = append(events, &event)
+	}
+	if bt.accounts[to] {
+		event := base
+		event.Account, event.Type, event.Counterparty = to, BalanceCredit, from
+		event.AssetID, event.Amount = assetID, new(big.Int).Set(amount)
+		events = append(events, &event)
+	}
+	return events
+}
+
+// revertBalanceEvents returns the compensating events in the reverse order.
+func revertBalanceEvents(events []*BalanceEvent) []*BalanceEvent {
+	reverted := make([]*BalanceEvent, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		event := *events[i]
+		event.Reverted = true
+		reverted = append(reverted, &event)
+	}
+	return reverted
+}
+
+// BalanceChanges creates a subscription that fires the credit and debit events of the
+// watched accounts after the confirmations, and the reverted events if a reorg drops
+// the blocks of the events sent before.
+func (api *PublicFilterAPI) BalanceChanges(ctx context.Context, crit BalanceCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	head := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return nil, errors.New("current block not found")
+	}
+	tracker, err := newBalanceTracker(api.chainDb, crit, head.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := api.events.SubscribeNewHeads(headers)
+
+		for {
+			select {
+			case h := <-headers:
+				for _, event := range tracker.update(h.Number.Uint64()) {
+					notifier.Notify(rpcSub.ID, event)
+				}
+			case <-rpcSub.Err():
+				headersSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				headersSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
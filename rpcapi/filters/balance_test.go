@@ -0,0 +1,85 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/fdb"
+	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+// writeTransferBlock writes a canonical block with a transfer from alice to bob.
+func writeTransferBlock(db fdb.Database, number uint64, amount int64, extra string) *types.Block {
+	action := types.NewAction(types.Transfer, common.Name("alice"), common.Name("bob"), number, 1, 21000, big.NewInt(amount), nil, nil)
+	tx := types.NewTransaction(0, big.NewInt(1), action)
+	receipt := types.NewReceipt(nil, 0, 0)
+	receipt.ActionResults = []*types.ActionResult{{Status: types.ReceiptStatusSuccessful}}
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Time: big.NewInt(0), Difficulty: big.NewInt(0), Extra: []byte(extra)}
+	block := types.NewBlock(header, []*types.Transaction{tx}, []*types.Receipt{receipt})
+	rawdb.WriteBlock(db, block)
+	rawdb.WriteReceipts(db, block.Hash(), number, []*types.Receipt{receipt})
+	rawdb.WriteCanonicalHash(db, block.Hash(), number)
+	return block
+}
+
+func TestBalanceTracker(t *testing.T) {
+	db := memdb.NewMemDatabase()
+	tracker, err := newBalanceTracker(db, BalanceCriteria{Accounts: []common.Name{"bob"}, Confirmations: 2}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTransferBlock(db, 1, 10, "")
+	writeTransferBlock(db, 2, 20, "")
+	if events := tracker.update(2); len(events) != 0 {
+		t.Fatalf("events sent before confirmations %v", events)
+	}
+	writeTransferBlock(db, 3, 30, "")
+	events := tracker.update(3)
+	if len(events) != 1 || events[0].Type != BalanceCredit || events[0].Amount.Int64() != 10 || events[0].Counterparty != "alice" || events[0].BlockNumber != 1 {
+		t.Fatalf("confirmed events mismatch %v", events)
+	}
+	writeTransferBlock(db, 4, 40, "")
+	if events := tracker.update(4); len(events) != 1 || events[0].Amount.Int64() != 20 {
+		t.Fatalf("confirmed events mismatch %v", events)
+	}
+
+	// reorg the blocks from 2, the block 2 events are reverted and the new block 2 is sent
+	writeTransferBlock(db, 2, 21, "fork")
+	writeTransferBlock(db, 3, 31, "fork")
+	writeTransferBlock(db, 4, 41, "fork")
+	writeTransferBlock(db, 5, 51, "fork")
+	events = tracker.update(5)
+	if len(events) != 3 {
+		t.Fatalf("reorg events mismatch %v", events)
+	}
+	if !events[0].Reverted || events[0].Amount.Int64() != 20 || events[0].BlockNumber != 2 {
+		t.Fatalf("reverted event mismatch %v", events[0])
+	}
+	if events[1].Reverted || events[1].Amount.Int64() != 21 || events[2].Amount.Int64() != 31 {
+		t.Fatalf("reorg confirmed events mismatch %v %v", events[1], events[2])
+	}
+
+	if _, err := newBalanceTracker(db, BalanceCriteria{}, 0); err != errBalanceCriteria {
+		t.Fatalf("empty criteria err %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/types"
 	"github.com/fractalplatform/fractal/utils/fdb"
 )
@@ -31,6 +32,7 @@ type Backend interface {
 	HeaderByHash(ctx context.Context, blockHash common.Hash) *types.Header
 	GetReceipts(ctx context.Context, blockHash common.Hash) ([]*types.Receipt, error)
 	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
+	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
 }
 
 // Filter can be used to retrieve and filter logs.
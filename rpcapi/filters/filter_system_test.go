@@ -27,6 +27,7 @@ import (
 
 	"github.com/fractalplatform/fractal/event"
 	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/types"
 	"github.com/fractalplatform/fractal/utils/fdb"
 	memdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
@@ -93,6 +94,10 @@ func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types
 	return logs, nil
 }
 
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return nil, b.HeaderByNumber(ctx, blockNr), nil
+}
+
 // TestBlockSubscription tests if a block subscription returns block hashes for posted chain events.
 // It creates multiple subscriptions:
 // - one at the start and should receive all posted chain events and a second (blockHashes)
@@ -0,0 +1,193 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// maxSupplyAssets is the max assets a supply subscription watches.
+const maxSupplyAssets = 1024
+
+var errSupplyCriteria = errors.New("invalid supply criteria")
+
+// SupplyCriteria represents a request to watch the supply changes of the assets.
+type SupplyCriteria struct {
+	AssetIDs      []uint64 `json:"assetIDs"`
+	Confirmations uint64   `json:"confirmations"` // blocks on top of the block before its supply is read
+}
+
+// SupplyEvent is the supply of a watched asset at the block, changed by the amount since
+// the event sent before. The change is negative if the asset was destroyed or a reorg
+// dropped the blocks which increased it.
+type SupplyEvent struct {
+	AssetID     uint64      `json:"assetID"`
+	Amount      *big.Int    `json:"amount"`
+	AddIssue    *big.Int    `json:"addIssue"`
+	Change      *big.Int    `json:"change"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+}
+
+// supplyReader returns the supply and the total issued of the asset at a block, nil if
+// the asset does not exist at the block.
+type supplyReader func(assetID uint64) (amount *big.Int, addIssue *big.Int, err error)
+
+// supplyTracker compares the supplies of the watched assets at the confirmed blocks
+// with the supplies sent before.
+type supplyTracker struct {
+	assetIDs      []uint64
+	confirmations uint64
+	supply        map[uint64]*big.Int
+}
+
+func newSupplyTracker(crit SupplyCriteria) (*supplyTracker, error) {
+	if len(crit.AssetIDs) == 0 || len(crit.AssetIDs) > maxSupplyAssets || crit.Confirmations > maxConfirmations {
+		return nil, errSupplyCriteria
+	}
+	return &supplyTracker{assetIDs: crit.AssetIDs, confirmations: crit.Confirmations}, nil
+}
+
+// update returns the events of the assets whose supply at the block differs from the
+// supply sent before, the first update records the supplies without events.
+func (st *supplyTracker) update(header *types.Header, read supplyReader) ([]*SupplyEvent, error) {
+	supply := make(map[uint64]*big.Int, len(st.assetIDs))
+	var events []*SupplyEvent
+	for _, assetID := range st.assetIDs {
+		amount, addIssue, err := read(assetID)
+		if err != nil {
+			return nil, err
+		}
+		if amount == nil {
+			amount, addIssue = new(big.Int), new(big.Int)
+		}
+		supply[assetID] = amount
+		if st.supply == nil {
+			continue
+		}
+		prev, ok := st.supply[assetID]
+		if !ok {
+			prev = new(big.Int)
+		}
+		if prev.Cmp(amount) == 0 {
+			continue
+		}
+		events = append(events, &SupplyEvent{
+			AssetID:     assetID,
+			Amount:      new(big.Int).Set(amount),
+			AddIssue:    new(big.Int).Set(addIssue),
+			Change:      new(big.Int).Sub(amount, prev),
+			BlockNumber: header.Number.Uint64(),
+			BlockHash:   header.Hash(),
+		})
+	}
+	st.supply = supply
+	return events, nil
+}
+
+// supplyAt returns the header of the block and the reader of the asset supplies at it.
+func (api *PublicFilterAPI) supplyAt(ctx context.Context, number uint64) (*types.Header, supplyReader, error) {
+	statedb, header, err := api.backend.StateAndHeaderByNumber(ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return nil, nil, err
+	}
+	if statedb == nil || header == nil {
+		return nil, nil, errors.New("block not found")
+	}
+	// the state is opened at the root of the block, not of the current block
+	statedb, err = state.New(header.Root, statedb.Database())
+	if err != nil {
+		return nil, nil, err
+	}
+	assets := asset.NewAsset(statedb)
+	return header, func(assetID uint64) (*big.Int, *big.Int, error) {
+		obj, err := assets.GetAssetObjectById(assetID)
+		if err == asset.ErrAssetNotExist {
+			return nil, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return obj.GetAssetAmount(), obj.GetAssetAddIssue(), nil
+	}, nil
+}
+
+// SupplyChanges creates a subscription that fires the supply of the watched assets
+// when it changed, read from the block the confirmations below the new head.
+func (api *PublicFilterAPI) SupplyChanges(ctx context.Context, crit SupplyCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	head := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return nil, errors.New("current block not found")
+	}
+	tracker, err := newSupplyTracker(crit)
+	if err != nil {
+		return nil, err
+	}
+	update := func(number uint64) []*SupplyEvent {
+		if number < tracker.confirmations {
+			return nil
+		}
+		header, read, err := api.supplyAt(context.Background(), number-tracker.confirmations)
+		if err != nil {
+			log.Debug("Skip supply subscription block", "number", number-tracker.confirmations, "err", err)
+			return nil
+		}
+		events, err := tracker.update(header, read)
+		if err != nil {
+			log.Debug("Skip supply subscription block", "number", header.Number, "err", err)
+		}
+		return events
+	}
+	update(head.Number.Uint64())
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := api.events.SubscribeNewHeads(headers)
+
+		for {
+			select {
+			case h := <-headers:
+				for _, event := range update(h.Number.Uint64()) {
+					notifier.Notify(rpcSub.ID, event)
+				}
+			case <-rpcSub.Err():
+				headersSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				headersSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestSupplyTracker(t *testing.T) {
+	if _, err := newSupplyTracker(SupplyCriteria{}); err != errSupplyCriteria {
+		t.Fatalf("empty criteria err %v", err)
+	}
+	tracker, err := newSupplyTracker(SupplyCriteria{AssetIDs: []uint64{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	supply := map[uint64]int64{1: 100}
+	read := func(assetID uint64) (*big.Int, *big.Int, error) {
+		amount, ok := supply[assetID]
+		if !ok {
+			return nil, nil, nil
+		}
+		return big.NewInt(amount), big.NewInt(amount), nil
+	}
+	header := func(number int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Time: big.NewInt(0), Difficulty: big.NewInt(0)}
+	}
+
+	if events, err := tracker.update(header(1), read); err != nil || len(events) != 0 {
+		t.Fatalf("first update = %v, %v", events, err)
+	}
+	if events, _ := tracker.update(header(2), read); len(events) != 0 {
+		t.Fatalf("events without supply change %v", events)
+	}
+
+	// the asset 1 is destroyed and the asset 2 is issued
+	supply[1], supply[2] = 70, 50
+	events, err := tracker.update(header(3), read)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("supply events = %v, %v", events, err)
+	}
+	if events[0].AssetID != 1 || events[0].Amount.Int64() != 70 || events[0].Change.Int64() != -30 || events[0].BlockNumber != 3 {
+		t.Fatalf("destroy event mismatch %v", events[0])
+	}
+	if events[1].AssetID != 2 || events[1].Amount.Int64() != 50 || events[1].Change.Int64() != 50 || events[1].BlockHash != header(3).Hash() {
+		t.Fatalf("issue event mismatch %v", events[1])
+	}
+}
@@ -36,9 +36,36 @@ func NewPublicFractalAPI(b Backend) *PublicFractalAPI {
 	return &PublicFractalAPI{b}
 }
 
-// GasPrice returns a suggestion for a gas price.
+// GasPrice returns a suggestion for a gas price, it is never lower than the base fee of the next block.
 func (s *PublicFractalAPI) GasPrice(ctx context.Context) (*big.Int, error) {
-	return s.b.SuggestPrice(ctx)
+	price, err := s.b.SuggestPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if baseFee := s.BaseFee(); baseFee != nil && baseFee.Cmp(price) > 0 {
+		return baseFee, nil
+	}
+	return price, nil
+}
+
+// BaseFee returns the base fee of the next block, it is nil if the fee market is not active.
+func (s *PublicFractalAPI) BaseFee() *big.Int {
+	head := s.b.CurrentBlock().Header()
+	return types.CalcBaseFee(s.b.ChainConfig(), head, head.CurForkID())
+}
+
+// SignHash returns the digest the authors of the action at index of the unsigned
+// transaction sign, by the signature rules of the next block.
+func (s *PublicFractalAPI) SignHash(ctx context.Context, encodedTx hexutil.Bytes, index uint64) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	if index >= uint64(len(tx.GetActions())) {
+		return common.Hash{}, types.ErrActionNotInTx
+	}
+	signer := types.MakeSigner(s.b.ChainConfig(), s.b.CurrentBlock().CurForkID())
+	return types.SignHashBy(signer, tx.GetActions()[index], tx)
 }
 
 // SendRawTransaction will add the signed transaction to the transaction pool.
@@ -0,0 +1,89 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/state"
+)
+
+// AccountOverride is the account state replaced before the call, the storage slots
+// not injected keep their values.
+type AccountOverride struct {
+	Balances map[uint64]*big.Int         `json:"balances"`
+	Code     *hexutil.Bytes              `json:"code"`
+	Storage  map[common.Hash]common.Hash `json:"storage"`
+}
+
+// StateOverride is the set of the accounts overridden before the call.
+type StateOverride map[common.Name]AccountOverride
+
+// Apply overrides the state of the accounts, the accounts must exist.
+func (overrides *StateOverride) Apply(am *accountmanager.AccountManager, statedb *state.StateDB) error {
+	if overrides == nil {
+		return nil
+	}
+	for name, override := range *overrides {
+		acct, err := am.GetAccountByName(name)
+		if err != nil {
+			return err
+		}
+		if acct == nil {
+			return fmt.Errorf("override account %s not exist", name)
+		}
+		for assetID, balance := range override.Balances {
+			if err := setBalance(am, name, assetID, balance); err != nil {
+				return fmt.Errorf("override account %s balance of asset %d: %v", name, assetID, err)
+			}
+		}
+		if override.Code != nil {
+			if _, err := am.SetCode(name, *override.Code); err != nil {
+				return fmt.Errorf("override account %s code: %v", name, err)
+			}
+		}
+		for key, value := range override.Storage {
+			statedb.SetState(name.String(), key, value)
+		}
+	}
+	return nil
+}
+
+// setBalance sets the balance of the account by adding or subtracting the difference.
+func setBalance(am *accountmanager.AccountManager, name common.Name, assetID uint64, balance *big.Int) error {
+	if balance == nil || balance.Sign() < 0 {
+		return accountmanager.ErrAmountValueInvalid
+	}
+	cur, err := am.GetAccountBalanceByID(name, assetID, 0)
+	if err == accountmanager.ErrAccountAssetNotExist {
+		cur = big.NewInt(0)
+	} else if err != nil {
+		return err
+	}
+	diff := new(big.Int).Sub(balance, cur)
+	switch diff.Sign() {
+	case 1:
+		return am.AddAccountBalanceByID(name, assetID, diff)
+	case -1:
+		return am.SubAccountBalanceByID(name, assetID, diff.Neg(diff))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+// PublicProofAPI offers the merkle proofs of the account and asset state for the
+// light clients and the bridges.
+type PublicProofAPI struct {
+	b Backend
+}
+
+// NewPublicProofAPI creates a new merkle proof API.
+func NewPublicProofAPI(b Backend) *PublicProofAPI {
+	return &PublicProofAPI{b}
+}
+
+// AccountProofResult is the proofs of the account against the state root.
+type AccountProofResult struct {
+	Root common.Hash `json:"root"`
+	*accountmanager.AccountProof
+}
+
+// AssetProofResult is the proofs of the asset against the state root.
+type AssetProofResult struct {
+	Root common.Hash `json:"root"`
+	*asset.AssetProof
+}
+
+// GetAccountProof returns the merkle proofs of the account record and the balances of the
+// assets of the account against the state root of the block, the code hash is proved by the
+// account record. The result is checked by accountmanager.VerifyAccountProof.
+func (s *PublicProofAPI) GetAccountProof(ctx context.Context, account common.Name, assetIDs []uint64, blockNr rpc.BlockNumber) (*AccountProofResult, error) {
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := am.GetAccountProof(account, assetIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountProofResult{Root: header.Root, AccountProof: proof}, nil
+}
+
+// GetAssetProof returns the merkle proofs of the asset record against the state root of the
+// block, the supply and the owner are proved by the asset record. The result is checked by
+// asset.VerifyAssetProof.
+func (s *PublicProofAPI) GetAssetProof(ctx context.Context, assetID uint64, blockNr rpc.BlockNumber) (*AssetProofResult, error) {
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	proof, err := asset.NewAsset(statedb).GetAssetProof(assetID)
+	if err != nil {
+		return nil, err
+	}
+	return &AssetProofResult{Root: header.Root, AssetProof: proof}, nil
+}
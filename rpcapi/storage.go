@@ -0,0 +1,114 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/state"
+)
+
+const (
+	// maxStorageRangeResults is the max storage slots returned by a storage range request.
+	maxStorageRangeResults = 1024
+	// maxStorageRangeScan is the max state entries visited by a storage range request.
+	maxStorageRangeScan = 100000
+	// maxStorageProofKeys is the max storage slots proved by a storage proof request.
+	maxStorageProofKeys = 256
+	// maxStateRangeResults is the max state entries returned by a state range request.
+	maxStateRangeResults = 1024
+	// maxStateRangeScan is the max state entries visited by a state range request.
+	maxStateRangeScan = 100000
+)
+
+// StorageSlotProof is the merkle proof of a storage slot in the state trie.
+type StorageSlotProof struct {
+	Key   common.Hash     `json:"key"`
+	Value common.Hash     `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// StorageProofResult is the storage proofs of the contract account against the state root.
+type StorageProofResult struct {
+	Account      common.Name         `json:"account"`
+	Root         common.Hash         `json:"root"`
+	StorageProof []*StorageSlotProof `json:"storageProof"`
+}
+
+// GetStorageRangeAt returns the storage slots of the contract account at the block in the trie
+// order, starting at the trie key start. The nextKey of the result continues the iteration.
+func (s *PublicBlockChainAPI) GetStorageRangeAt(ctx context.Context, account common.Name, blockNr rpc.BlockNumber, start common.Hash, maxResults int) (*state.StorageRange, error) {
+	if maxResults <= 0 || maxResults > maxStorageRangeResults {
+		return nil, fmt.Errorf("max results should be in (0, %d]", maxStorageRangeResults)
+	}
+	statedb, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	return statedb.StorageRangeAt(account.String(), start, maxResults, maxStorageRangeScan)
+}
+
+// GetStateRangeAt returns the account data entries of the account at the block whose keys start
+// with the prefix, such as the acctInfo entries of the system account, in the trie order starting
+// at the trie key start. An empty account selects every account. The entries are read from the
+// state root of the result, the nextKey continues the iteration at the same block number.
+func (s *PublicBlockChainAPI) GetStateRangeAt(ctx context.Context, account common.Name, prefix string, blockNr rpc.BlockNumber, start common.Hash, maxResults int) (*state.StateRange, error) {
+	if maxResults <= 0 || maxResults > maxStateRangeResults {
+		return nil, fmt.Errorf("max results should be in (0, %d]", maxStateRangeResults)
+	}
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	filter := state.StateFilter{Account: account.String(), Prefix: prefix}
+	return state.StateRangeAt(statedb.Database(), header.Root, filter, start, maxResults, maxStateRangeScan)
+}
+
+// GetNamedRecord returns the named record the contract account stored by the named record precompile at the block.
+func (s *PublicBlockChainAPI) GetNamedRecord(ctx context.Context, account common.Name, key string, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
+	statedb, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	return statedb.GetNamedRecord(account.String(), key)
+}
+
+// GetStorageProof returns the merkle proofs of the storage slots of the contract account
+// against the state root of the block.
+func (s *PublicBlockChainAPI) GetStorageProof(ctx context.Context, account common.Name, keys []common.Hash, blockNr rpc.BlockNumber) (*StorageProofResult, error) {
+	if len(keys) > maxStorageProofKeys {
+		return nil, fmt.Errorf("too many storage keys, max %d", maxStorageProofKeys)
+	}
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	result := &StorageProofResult{Account: account, Root: header.Root, StorageProof: make([]*StorageSlotProof, 0, len(keys))}
+	for _, key := range keys {
+		proof, err := statedb.GetStorageProof(account.String(), key)
+		if err != nil {
+			return nil, err
+		}
+		slot := &StorageSlotProof{Key: key, Value: statedb.RpcGetState(account.String(), key), Proof: proof}
+		result.StorageProof = append(result.StorageProof, slot)
+	}
+	return result, nil
+}
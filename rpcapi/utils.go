@@ -58,6 +58,9 @@ func RPCMarshalBlock(chainID *big.Int, b *types.Block, inclTx bool, fullTx bool)
 		"receiptsRoot":         head.ReceiptsRoot,
 		"forkID":               head.ForkID,
 	}
+	if baseFee := head.GetBaseFee(); baseFee != nil {
+		fields["baseFee"] = baseFee
+	}
 
 	if inclTx {
 		formatTx := func(tx *types.Transaction, index uint64) interface{} {
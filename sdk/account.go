@@ -893,7 +893,7 @@ func (acc *Account) CreateContract(id uint64, gas uint64, input []byte) (hash co
 	action := types.NewAction(types.CreateContract, acc.name, acc.name, acc.nonce, id, gas, nil, input, nil)
 	gasprice := big.NewInt(1)
 	tx := types.NewTransaction(0, gasprice, action)
-	signer := types.MakeSigner(big.NewInt(1))
+	signer := types.NewSigner(big.NewInt(1))
 	key := types.MakeKeyPair(acc.priv, []uint64{0})
 	err = types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{key})
 	if err != nil {
@@ -927,7 +927,7 @@ func (acc *Account) CallContract(id uint64, gas uint64, input []byte) (hash comm
 	gasprice := big.NewInt(1)
 	tx := types.NewTransaction(0, gasprice, action)
 
-	signer := types.MakeSigner(big.NewInt(1))
+	signer := types.NewSigner(big.NewInt(1))
 	key := types.MakeKeyPair(acc.priv, []uint64{0})
 	err = types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{key})
 	if err != nil {
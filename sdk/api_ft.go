@@ -69,6 +69,13 @@ func (api *API) GetTransactionReceiptByHash(hash common.Hash) (*types.RPCReceipt
 	return receipt, err
 }
 
+// GetRejectedTransaction get the reason the txpool rejected the tx, nil if not rejected recently
+func (api *API) GetRejectedTransaction(hash common.Hash) (map[string]interface{}, error) {
+	var rejected map[string]interface{}
+	err := api.client.Call(&rejected, "ft_getRejectedTransaction", hash)
+	return rejected, err
+}
+
 // GasPrice get gas price
 func (api *API) GasPrice() (*big.Int, error) {
 	gasprice := big.NewInt(0)
@@ -17,6 +17,7 @@
 package sdk
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/fractalplatform/fractal/common"
@@ -51,7 +52,9 @@ func TestGetBlockByNumber(t *testing.T) {
 	Convey("ft_getBlockByNumber", t, func() {
 		block, err := api.GetCurrentBlock(false)
 		So(err, ShouldBeNil)
-		block, err = api.GetBlockByNumber((int64(block["number"].(float64))), false)
+		number, err := strconv.ParseInt(block["number"].(string), 10, 64)
+		So(err, ShouldBeNil)
+		block, err = api.GetBlockByNumber(number, false)
 		So(err, ShouldBeNil)
 		So(block, ShouldNotBeNil)
 	})
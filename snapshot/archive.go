@@ -0,0 +1,164 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/fdb"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// archiveVersion the version of the snapshot archive format
+const archiveVersion = 1
+
+const (
+	entryNode     = 0 // a state trie node, keyed by its hash
+	entryPreimage = 1 // a state key, keyed by its hash
+)
+
+var (
+	errArchiveVersion    = errors.New("snapshot archive version unsupported")
+	errArchiveEntry      = errors.New("snapshot archive entry invalid")
+	errArchiveIncomplete = errors.New("snapshot archive state incomplete")
+)
+
+// ArchiveHeader the snapshot of the archive, the state of the archive is
+// verified against the root so the root must be trusted.
+type ArchiveHeader struct {
+	Version   uint64
+	Time      uint64
+	Number    uint64
+	BlockHash common.Hash // parent hash of the block taking the snapshot
+	Root      common.Hash
+}
+
+type archiveEntry struct {
+	Kind uint8
+	Data []byte
+}
+
+// Export writes the archive of the full state at the snapshot time, the header
+// is followed by the state trie nodes and the preimages of the state keys.
+func (sn *SnapshotManager) Export(time uint64, w io.Writer) error {
+	blockInfo, err := sn.getBlockInfo(time)
+	if err != nil {
+		return err
+	}
+	root, err := sn.GetSnapshotRoot(time)
+	if err != nil {
+		return err
+	}
+	header := &ArchiveHeader{Version: archiveVersion, Time: time, Number: blockInfo.Number, BlockHash: blockInfo.BlockHash, Root: root}
+	if err := rlp.Encode(w, header); err != nil {
+		return err
+	}
+
+	db := sn.stateDB.Database()
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return err
+	}
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			blob, err := db.TrieDB().Node(hash)
+			if err != nil {
+				return err
+			}
+			if err := rlp.Encode(w, &archiveEntry{Kind: entryNode, Data: blob}); err != nil {
+				return err
+			}
+		}
+		if it.Leaf() {
+			if preimage := tr.GetKey(it.LeafKey()); preimage != nil {
+				if err := rlp.Encode(w, &archiveEntry{Kind: entryPreimage, Data: preimage}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return it.Error()
+}
+
+// Import writes the state of the archive to the database and records the
+// snapshot of its block, nothing is recorded unless every node of the state is
+// in the archive. The caller checks the returned root against a trusted one.
+func Import(db state.Database, r io.Reader) (*ArchiveHeader, error) {
+	stream := rlp.NewStream(r, 0)
+	header := new(ArchiveHeader)
+	if err := stream.Decode(header); err != nil {
+		return nil, err
+	}
+	if header.Version != archiveVersion {
+		return nil, errArchiveVersion
+	}
+
+	batch := db.GetDB().NewBatch()
+	for {
+		var entry archiveEntry
+		if err := stream.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		hash := common.BytesToHash(crypto.Keccak256(entry.Data))
+		switch entry.Kind {
+		case entryNode:
+			if err := batch.Put(hash[:], entry.Data); err != nil {
+				return nil, err
+			}
+			if batch.ValueSize() >= fdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return nil, err
+				}
+				batch.Reset()
+			}
+		case entryPreimage:
+			db.TrieDB().InsertPreimage(hash, entry.Data)
+		default:
+			return nil, errArchiveEntry
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+
+	// every node is keyed by its hash, the state is complete if it is walked from the root
+	tr, err := db.OpenTrie(header.Root)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", errArchiveIncomplete, err)
+	}
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("%v: %v", errArchiveIncomplete, err)
+	}
+	if err := db.TrieDB().Commit(header.Root, false); err != nil {
+		return nil, err
+	}
+	rawdb.WriteSnapshot(db.GetDB(), types.SnapshotBlock{Number: header.Number, BlockHash: header.BlockHash}, types.SnapshotInfo{Root: header.Root})
+	return header, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestArchive(t *testing.T) {
+	db := mdb.NewMemDatabase()
+	cachedb := state.NewDatabase(db)
+	statedb, _ := state.New(common.Hash{}, cachedb)
+	for i := 0; i < 100; i++ {
+		statedb.SetState("archive01", common.BigToHash(big.NewInt(int64(i))), common.BigToHash(big.NewInt(int64(i+1))))
+	}
+	statedb.Put("archive02", "key", []byte("value"))
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch, common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cachedb.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	batch.Write()
+
+	statedb, _ = state.New(root, cachedb)
+	snapshotManager := NewSnapshotManager(statedb)
+	blockHash := common.Hash{1}
+	if err := snapshotManager.SetSnapshot(100000000, BlockInfo{Number: 1, BlockHash: blockHash}); err != nil {
+		t.Fatal(err)
+	}
+	rawdb.WriteSnapshot(db, types.SnapshotBlock{Number: 1, BlockHash: blockHash}, types.SnapshotInfo{Root: root})
+
+	var archive bytes.Buffer
+	if err := snapshotManager.Export(100000000, &archive); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotManager.Export(100000001, &bytes.Buffer{}); err == nil {
+		t.Fatal("export between the snapshots should fail")
+	}
+
+	// an archive missing nodes is rejected
+	if _, err := Import(state.NewDatabase(mdb.NewMemDatabase()), bytes.NewReader(archive.Bytes()[:archive.Len()-200])); err == nil {
+		t.Fatal("import truncated archive should fail")
+	}
+
+	newdb := mdb.NewMemDatabase()
+	header, err := Import(state.NewDatabase(newdb), bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Root != root || header.Number != 1 || header.BlockHash != blockHash || header.Time != 100000000 {
+		t.Fatalf("archive header %+v", header)
+	}
+	if info := rawdb.ReadSnapshot(newdb, types.SnapshotBlock{Number: 1, BlockHash: blockHash}); info == nil || info.Root != root {
+		t.Fatal("snapshot not recorded")
+	}
+	imported, err := state.New(root, state.NewDatabase(newdb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := imported.Get("archive02", "key"); string(value) != "value" {
+		t.Fatalf("imported value %s", value)
+	}
+	// the storage is iterated by the preimages of the keys
+	storage, err := imported.StorageRangeAt("archive01", common.Hash{}, 1000, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storage.Storage) != 100 {
+		t.Fatalf("imported storage %d slots", len(storage.Storage))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/fractalplatform/fractal/state"
+	trie "github.com/fractalplatform/fractal/state/mtp"
+)
+
+var errPreimageMissing = errors.New("state key preimage not found")
+
+// StateChange a state entry modified, created or deleted between two snapshots
+type StateChange struct {
+	state.StateKey
+	Deleted bool `json:"deleted"`
+}
+
+// Diff get the state entries changed from the snapshot of time1 to the
+// snapshot of time2, the entries are ordered by the account and the key.
+func (sn *SnapshotManager) Diff(time1, time2 uint64) ([]*StateChange, error) {
+	root1, err := sn.GetSnapshotRoot(time1)
+	if err != nil {
+		return nil, err
+	}
+	root2, err := sn.GetSnapshotRoot(time2)
+	if err != nil {
+		return nil, err
+	}
+	db := sn.stateDB.Database()
+	tr1, err := db.OpenTrie(root1)
+	if err != nil {
+		return nil, err
+	}
+	tr2, err := db.OpenTrie(root2)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := diffKeys(tr1, tr2)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := diffKeys(tr2, tr1)
+	if err != nil {
+		return nil, err
+	}
+	var changes []*StateChange
+	for key := range changed {
+		if stateKey, ok := state.ParseStateKey(key); ok {
+			changes = append(changes, &StateChange{StateKey: stateKey})
+		}
+	}
+	for key := range removed {
+		if _, ok := changed[key]; ok {
+			continue
+		}
+		if stateKey, ok := state.ParseStateKey(key); ok {
+			changes = append(changes, &StateChange{StateKey: stateKey, Deleted: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Account != changes[j].Account {
+			return changes[i].Account < changes[j].Account
+		}
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+// diffKeys returns the keys of the entries of b missing or different in a.
+func diffKeys(a, b state.Trie) (map[string]struct{}, error) {
+	diff, _ := trie.NewDifferenceIterator(a.NodeIterator(nil), b.NodeIterator(nil))
+	keys := make(map[string]struct{})
+	it := trie.NewIterator(diff)
+	for it.Next() {
+		preimage := b.GetKey(it.Key)
+		if preimage == nil {
+			return nil, errPreimageMissing
+		}
+		keys[string(preimage)] = struct{}{}
+	}
+	return keys, it.Err
+}
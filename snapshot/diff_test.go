@@ -0,0 +1,91 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestDiff(t *testing.T) {
+	db := mdb.NewMemDatabase()
+	cachedb := state.NewDatabase(db)
+	commit := func(statedb *state.StateDB) common.Hash {
+		batch := db.NewBatch()
+		root, err := statedb.Commit(batch, common.Hash{}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cachedb.TrieDB().Commit(root, false); err != nil {
+			t.Fatal(err)
+		}
+		batch.Write()
+		return root
+	}
+
+	statedb, _ := state.New(common.Hash{}, cachedb)
+	statedb.Put("diff01", "same", []byte("1"))
+	statedb.Put("diff01", "modified", []byte("1"))
+	statedb.Put("diff02", "deleted", []byte("1"))
+	root1 := commit(statedb)
+
+	statedb, _ = state.New(root1, cachedb)
+	statedb.Put("diff01", "modified", []byte("2"))
+	statedb.Put("diff01", "created", []byte("1"))
+	statedb.Delete("diff02", "deleted")
+	statedb.SetState("diff03", common.Hash{1}, common.Hash{2})
+	root2 := commit(statedb)
+
+	statedb, _ = state.New(root2, cachedb)
+	snapshotManager := NewSnapshotManager(statedb)
+	for i, root := range []common.Hash{root1, root2} {
+		block := types.SnapshotBlock{Number: uint64(i + 1), BlockHash: common.Hash{byte(i)}}
+		rawdb.WriteSnapshot(db, block, types.SnapshotInfo{Root: root})
+		if err := snapshotManager.SetSnapshot(uint64(i+1)*1000, BlockInfo{Number: block.Number, BlockHash: block.BlockHash, Timestamp: uint64(i) * 1000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := snapshotManager.Diff(1000, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*StateChange{
+		{StateKey: state.StateKey{Account: "diff01", Key: "created"}},
+		{StateKey: state.StateKey{Account: "diff01", Key: "modified"}},
+		{StateKey: state.StateKey{Account: "diff02", Key: "deleted"}, Deleted: true},
+		{StateKey: state.StateKey{Account: "diff03", Key: common.Hash{1}.String(), Storage: true}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		for _, change := range changes {
+			t.Logf("%+v", change)
+		}
+		t.Fatal("state changes mismatch")
+	}
+	if changes, err := snapshotManager.Diff(2000, 2000); err != nil || len(changes) != 0 {
+		t.Fatalf("diff of the same snapshot %v err %v", changes, err)
+	}
+	if _, err := snapshotManager.Diff(1000, 1500); err == nil {
+		t.Fatal("diff between the snapshots should fail")
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 var snapshotManagerName = "sysSnapshot"
 var snapshotTime = "time"
+var snapshotScheduled = "scheduled"
 
 // SnapshotManager snapshot manager object
 type SnapshotManager struct {
@@ -67,6 +68,53 @@ func (sn *SnapshotManager) SetSnapshot(time uint64, blockInfo BlockInfo) error {
 	return nil
 }
 
+// ScheduleSnapshot schedules a snapshot taken by the block of the number out of
+// the cadence of the snapshot interval.
+func (sn *SnapshotManager) ScheduleSnapshot(number uint64) error {
+	enc, err := rlp.EncodeToBytes(true)
+	if err != nil {
+		return err
+	}
+	sn.stateDB.Put(snapshotManagerName, snapshotScheduled+strconv.FormatUint(number, 10), enc)
+	return nil
+}
+
+// IsSnapshotScheduled returns whether a snapshot is scheduled for the block of the number.
+func (sn *SnapshotManager) IsSnapshotScheduled(number uint64) bool {
+	enc, _ := sn.stateDB.Get(snapshotManagerName, snapshotScheduled+strconv.FormatUint(number, 10))
+	return len(enc) != 0
+}
+
+// TakeSnapshot takes the snapshot of the block if the block starts a new
+// snapshot interval or a snapshot is scheduled for it, the scheduled snapshot
+// is keyed by the block time. It must be called before the block state is
+// committed.
+func (sn *SnapshotManager) TakeSnapshot(header *types.Header, parentTime uint64, interval uint64) error {
+	number := header.Number.Uint64()
+	parentTimeFormat := parentTime / interval * interval
+	currentTimeFormat := header.Time.Uint64() / interval * interval
+	scheduled := sn.IsSnapshotScheduled(number)
+	if scheduled {
+		sn.stateDB.Delete(snapshotManagerName, snapshotScheduled+strconv.FormatUint(number, 10))
+	}
+
+	// link to the scheduled snapshots taken since the interval of the parent
+	prevTime, err := sn.GetLastSnapshotTime()
+	if err != nil {
+		prevTime = 0
+	}
+	if parentTimeFormat != currentTimeFormat {
+		if prevTime < parentTimeFormat {
+			prevTime = parentTimeFormat
+		}
+		return sn.SetSnapshot(currentTimeFormat, BlockInfo{Number: number, BlockHash: header.ParentHash, Timestamp: prevTime})
+	}
+	if scheduled {
+		return sn.SetSnapshot(header.Time.Uint64(), BlockInfo{Number: number, BlockHash: header.ParentHash, Timestamp: prevTime})
+	}
+	return nil
+}
+
 func (sn *SnapshotManager) GetCurrentSnapshotHash() (uint64, common.Hash, error) {
 	timestampEnc, err := sn.stateDB.Get(snapshotManagerName, snapshotTime)
 	if err != nil {
@@ -122,6 +170,25 @@ func (sn *SnapshotManager) GetPrevSnapshotTime(time uint64) (uint64, error) {
 	return blockInfo.Timestamp, nil
 }
 
+// GetSnapshotTimes get the times of the last max snapshots, the latest first
+func (sn *SnapshotManager) GetSnapshotTimes(max int) ([]uint64, error) {
+	time, err := sn.GetLastSnapshotTime()
+	if err != nil {
+		return nil, err
+	}
+	var times []uint64
+	for time != 0 && len(times) < max {
+		prevTime, err := sn.GetPrevSnapshotTime(time)
+		if err != nil {
+			// the previous snapshot of the first one is never taken
+			break
+		}
+		times = append(times, time)
+		time = prevTime
+	}
+	return times, nil
+}
+
 func (sn *SnapshotManager) GetSnapshotMsg(account string, key string, time uint64) ([]byte, error) {
 	if time == 0 {
 		return nil, fmt.Errorf("Not snapshot info, time = %v", time)
@@ -163,18 +230,25 @@ func (sn *SnapshotManager) GetSnapshotMsg(account string, key string, time uint6
 
 //GetSnapshotState get snapshot state
 func (sn *SnapshotManager) GetSnapshotState(time uint64) (*state.StateDB, error) {
-	if time == 0 {
-		return nil, fmt.Errorf("Not snapshot info, time = %v", time)
+	root, err := sn.GetSnapshotRoot(time)
+	if err != nil {
+		return nil, err
 	}
 
-	key1 := snapshotTime + strconv.FormatUint(time, 10)
-	blockInfoEnc, err := sn.stateDB.Get(snapshotManagerName, key1)
+	dbCache := sn.stateDB.Database()
+	statedb, err := state.New(root, dbCache)
 	if err != nil {
-		return nil, fmt.Errorf("Not snapshot info, error = %v", err)
+		return nil, fmt.Errorf("Not snapshot info, new state failed, error = %v", err)
 	}
-	var blockInfo BlockInfo
-	if err = rlp.DecodeBytes(blockInfoEnc, &blockInfo); err != nil {
-		return nil, fmt.Errorf("Not snapshot info, error = %v", err)
+
+	return statedb, nil
+}
+
+//GetSnapshotRoot get the state root of the snapshot
+func (sn *SnapshotManager) GetSnapshotRoot(time uint64) (common.Hash, error) {
+	blockInfo, err := sn.getBlockInfo(time)
+	if err != nil {
+		return common.Hash{}, err
 	}
 
 	snapshotBlock := types.SnapshotBlock{
@@ -185,14 +259,29 @@ func (sn *SnapshotManager) GetSnapshotState(time uint64) (*state.StateDB, error)
 	db := sn.stateDB.Database().GetDB()
 	snapshotInfo := rawdb.ReadSnapshot(db, snapshotBlock)
 	if snapshotInfo == nil {
-		return nil, errors.New("Not snapshot info, rawdb not exist")
+		return common.Hash{}, errors.New("Not snapshot info, rawdb not exist")
 	}
+	return snapshotInfo.Root, nil
+}
 
-	dbCache := sn.stateDB.Database()
-	statedb, err := state.New(snapshotInfo.Root, dbCache)
-	if err != nil {
-		return nil, fmt.Errorf("Not snapshot info, new state failed, error = %v", err)
+// getBlockInfo get the block taking the snapshot of the time
+func (sn *SnapshotManager) getBlockInfo(time uint64) (*BlockInfo, error) {
+	if time == 0 {
+		return nil, fmt.Errorf("Not snapshot info, time = %v", time)
 	}
 
-	return statedb, nil
+	key := snapshotTime + strconv.FormatUint(time, 10)
+	blockInfoEnc, err := sn.stateDB.Get(snapshotManagerName, key)
+	if err != nil {
+		return nil, fmt.Errorf("Not snapshot info, error = %v", err)
+	}
+	if len(blockInfoEnc) == 0 {
+		// the time is between the snapshots
+		return nil, fmt.Errorf("Not snapshot info, no snapshot at time %v", time)
+	}
+	var blockInfo BlockInfo
+	if err = rlp.DecodeBytes(blockInfoEnc, &blockInfo); err != nil {
+		return nil, fmt.Errorf("Not snapshot info, error = %v", err)
+	}
+	return &blockInfo, nil
 }
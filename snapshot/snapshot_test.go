@@ -17,6 +17,8 @@
 package snapshot
 
 import (
+	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/fractalplatform/fractal/common"
@@ -94,3 +96,45 @@ func TestSnapshot(t *testing.T) {
 		t.Error("set snapshot err", err)
 	}
 }
+
+func TestTakeSnapshot(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(mdb.NewMemDatabase()))
+	snapshotManager := NewSnapshotManager(statedb)
+	interval := uint64(1000)
+	header := func(number, time uint64) *types.Header {
+		return &types.Header{Number: new(big.Int).SetUint64(number), Time: new(big.Int).SetUint64(time)}
+	}
+
+	// regular snapshots at the intervals crossed
+	for number, time := range []uint64{1500, 1700, 2100, 2300, 2500, 2700, 3100} {
+		if err := snapshotManager.TakeSnapshot(header(uint64(number+1), time), time-200, interval); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if times, _ := snapshotManager.GetSnapshotTimes(10); !reflect.DeepEqual(times, []uint64{3000, 2000}) {
+		t.Fatalf("regular snapshot times %v", times)
+	}
+
+	// the scheduled snapshot is taken once in the middle of the interval
+	if err := snapshotManager.ScheduleSnapshot(9); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotManager.ScheduleSnapshot(10); err != nil {
+		t.Fatal(err)
+	}
+	blocks := []struct{ number, time uint64 }{{8, 3300}, {9, 3500}, {10, 4100}, {11, 4300}}
+	for _, b := range blocks {
+		if err := snapshotManager.TakeSnapshot(header(b.number, b.time), b.time-200, interval); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if snapshotManager.IsSnapshotScheduled(9) || snapshotManager.IsSnapshotScheduled(10) {
+		t.Fatal("scheduled snapshot not cleared")
+	}
+	if times, _ := snapshotManager.GetSnapshotTimes(10); !reflect.DeepEqual(times, []uint64{4000, 3500, 3000, 2000}) {
+		t.Fatalf("snapshot times %v", times)
+	}
+	if times, _ := snapshotManager.GetSnapshotTimes(2); !reflect.DeepEqual(times, []uint64{4000, 3500}) {
+		t.Fatalf("last snapshot times %v", times)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// maxStateLayers the depth at which the layers of a state are merged, so
+// forking a state repeatedly does not slow its reads down.
+const maxStateLayers = 16
+
+// stateLayer the values written or read by a state before it was forked, the
+// layer is shared by the state and its forks and never modified.
+type stateLayer struct {
+	values map[string][]byte
+	parent *stateLayer
+	depth  int
+}
+
+func (l *stateLayer) get(key string) ([]byte, bool) {
+	for ; l != nil; l = l.parent {
+		if value, exsit := l.values[key]; exsit {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// flatten merges the layer with its parents.
+func (l *stateLayer) flatten() *stateLayer {
+	values := make(map[string][]byte)
+	for ; l != nil; l = l.parent {
+		for key, value := range l.values {
+			if _, exsit := values[key]; !exsit {
+				values[key] = value
+			}
+		}
+	}
+	return &stateLayer{values: values}
+}
+
+// lookup returns the value of the key written or read by the state.
+func (s *StateDB) lookup(key string) ([]byte, bool) {
+	if value, exsit := s.writeSet[key]; exsit {
+		return value, true
+	}
+	return s.layer.get(key)
+}
+
+// Fork returns a state seeing the values of s, the writes of the fork and the
+// later writes of s are isolated from each other. The values are shared rather
+// than copied, the fork and s may then be used concurrently, for instance to
+// execute transactions speculatively and drop the fork. The fork has its own
+// journal, refund, logs and preimages.
+func (s *StateDB) Fork() *StateDB {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// freeze the values of s, s writes to a new set from now on
+	if len(s.writeSet) > 0 {
+		layer := &stateLayer{values: s.writeSet, parent: s.layer}
+		if s.layer != nil {
+			layer.depth = s.layer.depth + 1
+		}
+		if layer.depth >= maxStateLayers {
+			layer = layer.flatten()
+		}
+		s.layer = layer
+		s.writeSet = make(map[string][]byte)
+	}
+
+	state := &StateDB{
+		db:         s.db,
+		layer:      s.layer,
+		readSet:    make(map[string][]byte),
+		writeSet:   make(map[string][]byte),
+		dirtySet:   make(map[string]struct{}, len(s.dirtySet)+len(s.journal.dirties)),
+		thash:      s.thash,
+		bhash:      s.bhash,
+		txIndex:    s.txIndex,
+		logs:       make(map[common.Hash][]*types.Log),
+		preimages:  make(map[common.Hash][]byte),
+		journal:    newJournal(),
+		stateTrace: s.stateTrace,
+	}
+	if s.trie != nil {
+		state.trie = s.db.CopyTrie(s.trie)
+	}
+	// the values s did not finalise yet are not in the trie
+	for key := range s.dirtySet {
+		state.dirtySet[key] = struct{}{}
+	}
+	for key := range s.journal.dirties {
+		state.dirtySet[key] = struct{}{}
+	}
+	return state
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestFork(t *testing.T) {
+	cachedb := NewDatabase(mdb.NewMemDatabase())
+	state, _ := New(common.Hash{}, cachedb)
+	state.Put("a", "k", []byte{1})
+	state.Put("b", "k", []byte{1})
+	state.IntermediateRoot()
+
+	state.Put("a", "k", []byte{2})
+	fork := state.Fork()
+	if value, _ := fork.Get("a", "k"); !bytes.Equal(value, []byte{2}) {
+		t.Fatalf("fork read %x, want the unfinalised write 02", value)
+	}
+
+	// the writes after the fork are isolated
+	fork.Put("a", "k", []byte{3})
+	state.Put("b", "k", []byte{2})
+	if value, _ := state.Get("a", "k"); !bytes.Equal(value, []byte{2}) {
+		t.Fatalf("state read %x, want 02", value)
+	}
+	if value, _ := fork.Get("b", "k"); !bytes.Equal(value, []byte{1}) {
+		t.Fatalf("fork read %x, want 01", value)
+	}
+
+	// a reverted write of the fork falls back to the shared value
+	snap := fork.Snapshot()
+	fork.Put("b", "k", []byte{3})
+	fork.RevertToSnapshot(snap)
+	if value, _ := fork.Get("b", "k"); !bytes.Equal(value, []byte{1}) {
+		t.Fatalf("fork read %x after revert, want 01", value)
+	}
+
+	expect := func(a, b byte) common.Hash {
+		s, _ := New(common.Hash{}, cachedb)
+		s.Put("a", "k", []byte{a})
+		s.Put("b", "k", []byte{b})
+		return s.IntermediateRoot()
+	}
+	if have, want := fork.IntermediateRoot(), expect(3, 1); have != want {
+		t.Fatalf("fork root %x, want %x", have, want)
+	}
+	if have, want := state.IntermediateRoot(), expect(2, 2); have != want {
+		t.Fatalf("state root %x, want %x", have, want)
+	}
+}
+
+func TestForkConcurrent(t *testing.T) {
+	db := mdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+	for i := 0; i < 100; i++ {
+		state.Put("a", fmt.Sprint(i), []byte{byte(i)})
+	}
+	batch := db.NewBatch()
+	root, err := state.Commit(batch, common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Database().TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	// read the trie from the database rather than from the committed state
+	state, err = New(root, NewDatabase(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for f := 0; f < 4*maxStateLayers; f++ {
+		state.Put("b", fmt.Sprint(f), []byte{byte(f)})
+		fork := state.Fork()
+		wg.Add(1)
+		go func(f int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				fork.Put("a", fmt.Sprint(i), []byte{byte(f)})
+			}
+			for i := 0; i <= f; i++ {
+				if value, _ := fork.Get("b", fmt.Sprint(i)); !bytes.Equal(value, []byte{byte(i)}) {
+					t.Errorf("fork %d read %x for %d", f, value, i)
+				}
+			}
+		}(f)
+		for i := 0; i < 100; i++ {
+			if value, _ := state.Get("a", fmt.Sprint(i)); !bytes.Equal(value, []byte{byte(i)}) {
+				t.Fatalf("state read %x for %d", value, i)
+			}
+		}
+	}
+	wg.Wait()
+	if state.layer.depth >= maxStateLayers {
+		t.Fatalf("layers not merged, depth %d", state.layer.depth)
+	}
+}
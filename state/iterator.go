@@ -0,0 +1,154 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	trie "github.com/fractalplatform/fractal/state/mtp"
+)
+
+var errStateKeyPreimage = errors.New("state key preimage not found")
+
+// StateFilter selects the account data entries of the account whose keys start with the
+// prefix, such as the acctInfo entries of the system account. An empty account selects the
+// entries of every account.
+type StateFilter struct {
+	Account string `json:"account"`
+	Prefix  string `json:"prefix"`
+}
+
+func (f StateFilter) match(key string) (StateKey, bool) {
+	stateKey, ok := ParseStateKey(key)
+	if !ok || stateKey.Storage {
+		return StateKey{}, false
+	}
+	if f.Account != "" && stateKey.Account != f.Account {
+		return StateKey{}, false
+	}
+	return stateKey, strings.HasPrefix(stateKey.Key, f.Prefix)
+}
+
+// StateEntry an account data entry of the state
+type StateEntry struct {
+	StateKey
+	Value hexutil.Bytes `json:"value"`
+}
+
+// StateIterator iterates the account data entries selected by the filter in the trie order.
+// The trie is opened at the root and is never modified, the entries are those of the root
+// while the chain moves on, so the callers need not know the trie layout nor lock the chain.
+type StateIterator struct {
+	filter StateFilter
+	tr     Trie
+	it     *trie.Iterator
+	entry  StateEntry
+	err    error
+}
+
+// NewStateIterator open the state trie at the root and iterate it from the trie key start.
+func NewStateIterator(db Database, root common.Hash, filter StateFilter, start common.Hash) (*StateIterator, error) {
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	return &StateIterator{filter: filter, tr: tr, it: trie.NewIterator(tr.NodeIterator(start[:]))}, nil
+}
+
+// step move to the next entry of the trie, matched reports whether it is selected by the filter.
+func (it *StateIterator) step() (matched bool, ok bool) {
+	if it.err != nil || !it.it.Next() {
+		if it.err == nil {
+			it.err = it.it.Err
+		}
+		return false, false
+	}
+	preimage := it.tr.GetKey(it.it.Key)
+	if preimage == nil {
+		it.err = errStateKeyPreimage
+		return false, false
+	}
+	stateKey, matched := it.filter.match(string(preimage))
+	if matched {
+		it.entry = StateEntry{StateKey: stateKey, Value: common.CopyBytes(it.it.Value)}
+	}
+	return matched, true
+}
+
+// Next move to the next selected entry, false at the end of the trie or on an error.
+func (it *StateIterator) Next() bool {
+	for {
+		matched, ok := it.step()
+		if !ok || matched {
+			return ok
+		}
+	}
+}
+
+// Entry the current entry
+func (it *StateIterator) Entry() StateEntry {
+	return it.entry
+}
+
+// TrieKey the trie key of the current entry, an iteration started at it visits the entry again
+func (it *StateIterator) TrieKey() common.Hash {
+	return common.BytesToHash(it.it.Key)
+}
+
+// Err the error which stopped the iteration
+func (it *StateIterator) Err() error {
+	return it.err
+}
+
+// StateRange the account data entries at the root in the trie order. NextKey is the
+// trie key to continue the iteration, it is nil if the iteration is finished.
+type StateRange struct {
+	Root    common.Hash  `json:"root"`
+	Entries []StateEntry `json:"entries"`
+	NextKey *common.Hash `json:"nextKey"`
+}
+
+// StateRangeAt get the account data entries selected by the filter at the root from the trie
+// key start, at most maxResults entries are returned and maxScan state entries are visited.
+func StateRangeAt(db Database, root common.Hash, filter StateFilter, start common.Hash, maxResults int, maxScan int) (*StateRange, error) {
+	it, err := NewStateIterator(db, root, filter, start)
+	if err != nil {
+		return nil, err
+	}
+	result := &StateRange{Root: root, Entries: []StateEntry{}}
+	for scanned := 0; ; scanned++ {
+		matched, ok := it.step()
+		if !ok {
+			break
+		}
+		if len(result.Entries) >= maxResults || scanned >= maxScan {
+			next := it.TrieKey()
+			result.NextKey = &next
+			break
+		}
+		if matched {
+			result.Entries = append(result.Entries, it.Entry())
+		}
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+	return result, nil
+}
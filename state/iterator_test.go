@@ -0,0 +1,110 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestStateIterator(t *testing.T) {
+	db := mdb.NewMemDatabase()
+	cachedb := NewDatabase(db)
+	commit := func(statedb *StateDB) common.Hash {
+		batch := db.NewBatch()
+		root, err := statedb.Commit(batch, common.Hash{}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cachedb.TrieDB().Commit(root, false); err != nil {
+			t.Fatal(err)
+		}
+		batch.Write()
+		return root
+	}
+
+	statedb, _ := New(common.Hash{}, cachedb)
+	want := make(map[string]string)
+	for i := 1; i <= 10; i++ {
+		key, value := fmt.Sprintf("acctInfo%d", i), fmt.Sprintf("account %d", i)
+		want[key] = value
+		statedb.Put("sysAccount", key, []byte(value))
+	}
+	statedb.Put("sysAccount", "acctName", []byte("not selected"))
+	statedb.Put("other", "acctInfo1", []byte("not selected"))
+	statedb.SetState("sysAccount", common.Hash{1}, common.Hash{1})
+	root := commit(statedb)
+
+	// the entries written after the root are not visited
+	statedb, _ = New(root, cachedb)
+	statedb.Put("sysAccount", "acctInfo1", []byte("changed"))
+	statedb.Put("sysAccount", "acctInfo11", []byte("added"))
+	commit(statedb)
+
+	filter := StateFilter{Account: "sysAccount", Prefix: "acctInfo"}
+	it, err := NewStateIterator(cachedb, root, filter, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]string)
+	for it.Next() {
+		entry := it.Entry()
+		if entry.Account != "sysAccount" || entry.Storage {
+			t.Fatalf("unexpected entry %v", entry)
+		}
+		found[entry.Key] = string(entry.Value)
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if fmt.Sprint(found) != fmt.Sprint(want) {
+		t.Fatalf("iterator entries %v, want %v", found, want)
+	}
+
+	paged := make(map[string]string)
+	start := common.Hash{}
+	for {
+		result, err := StateRangeAt(cachedb, root, filter, start, 3, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Entries) > 3 || result.Root != root {
+			t.Fatalf("state range returns %d entries at %x", len(result.Entries), result.Root)
+		}
+		for _, entry := range result.Entries {
+			paged[entry.Key] = string(entry.Value)
+		}
+		if result.NextKey == nil {
+			break
+		}
+		start = *result.NextKey
+	}
+	if fmt.Sprint(paged) != fmt.Sprint(want) {
+		t.Fatalf("state range entries %v, want %v", paged, want)
+	}
+
+	all, err := StateRangeAt(cachedb, root, StateFilter{Prefix: "acctInfo1"}, common.Hash{}, 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Entries) != 3 || all.NextKey != nil {
+		t.Fatalf("entries of every account %v", all.Entries)
+	}
+}
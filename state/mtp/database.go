@@ -318,6 +318,15 @@ func (db *Database) insert(hash common.Hash, blob []byte, node node) {
 	db.nodesSize += common.StorageSize(common.HashLength + entry.size)
 }
 
+// InsertPreimage writes a new trie key pre-image to the memory database if it's
+// yet unknown, it is persisted by the next commit.
+func (db *Database) InsertPreimage(hash common.Hash, preimage []byte) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.insertPreimage(hash, preimage)
+}
+
 // insertPreimage writes a new trie node pre-image to the memory database if it's
 // yet unknown. The method will make a copy of the slice.
 //
@@ -0,0 +1,100 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package mtp
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+)
+
+// TrieReport is the result of the verification of a trie.
+type TrieReport struct {
+	Nodes     int           // intact nodes reached from the root
+	Shared    int           // nodes referenced by more than one parent
+	Missing   []common.Hash // referenced nodes absent from the database
+	Corrupted []common.Hash // nodes whose content does not match their hash
+}
+
+// VerifyTrie walks every node of the trie at root, checking that each node is
+// present, hashes to its key and decodes. The children of the bad nodes can
+// not be reached, so the walk has to be repeated once they are repaired.
+func VerifyTrie(db *Database, root common.Hash) *TrieReport {
+	report := new(TrieReport)
+	if root == emptyRoot || root == (common.Hash{}) {
+		return report
+	}
+	refs := map[common.Hash]int{root: 1}
+	queue := []common.Hash{root}
+	for len(queue) > 0 {
+		hash := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		blob, err := db.Node(hash)
+		if err != nil || len(blob) == 0 {
+			report.Missing = append(report.Missing, hash)
+			continue
+		}
+		if crypto.Keccak256Hash(blob) != hash {
+			report.Corrupted = append(report.Corrupted, hash)
+			continue
+		}
+		n, err := decodeNode(hash[:], blob, 0)
+		if err != nil {
+			report.Corrupted = append(report.Corrupted, hash)
+			continue
+		}
+		report.Nodes++
+
+		var children []common.Hash
+		gatherHashes(n, &children)
+		for _, child := range children {
+			if refs[child]++; refs[child] == 1 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	for _, count := range refs {
+		if count > 1 {
+			report.Shared++
+		}
+	}
+	sortHashes(report.Missing)
+	sortHashes(report.Corrupted)
+	return report
+}
+
+// gatherHashes retrieves the hashnode children of a decoded node, descending
+// into the children embedded in it.
+func gatherHashes(n node, children *[]common.Hash) {
+	switch n := n.(type) {
+	case *shortNode:
+		gatherHashes(n.Val, children)
+	case *fullNode:
+		for _, child := range n.Children {
+			gatherHashes(child, children)
+		}
+	case hashNode:
+		*children = append(*children, common.BytesToHash(n))
+	}
+}
+
+func sortHashes(hashes []common.Hash) {
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+}
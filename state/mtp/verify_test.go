@@ -0,0 +1,64 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package mtp
+
+import (
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestVerifyTrie(t *testing.T) {
+	triedb, trie, _ := makeTestTrie()
+	root := trie.Hash()
+	if err := triedb.Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	diskdb := triedb.DiskDB().(*mdb.MemDatabase)
+
+	report := VerifyTrie(NewDatabase(diskdb), root)
+	if report.Nodes == 0 || len(report.Missing) != 0 || len(report.Corrupted) != 0 {
+		t.Fatalf("intact trie: nodes %d missing %v corrupted %v", report.Nodes, report.Missing, report.Corrupted)
+	}
+	nodes := report.Nodes
+
+	var missing, corrupted common.Hash
+	for _, key := range diskdb.Keys() {
+		hash := common.BytesToHash(key)
+		if len(key) != common.HashLength || hash == root {
+			continue
+		}
+		if missing == (common.Hash{}) {
+			missing = hash
+			diskdb.Delete(key)
+		} else if corrupted == (common.Hash{}) {
+			corrupted = hash
+			diskdb.Put(key, []byte{0xc0})
+		}
+	}
+	report = VerifyTrie(NewDatabase(diskdb), root)
+	if len(report.Missing) != 1 || report.Missing[0] != missing {
+		t.Fatalf("missing %v, want %x", report.Missing, missing)
+	}
+	if len(report.Corrupted) != 1 || report.Corrupted[0] != corrupted {
+		t.Fatalf("corrupted %v, want %x", report.Corrupted, corrupted)
+	}
+	if report.Nodes >= nodes {
+		t.Fatalf("reached %d nodes of a damaged trie, intact %d", report.Nodes, nodes)
+	}
+}
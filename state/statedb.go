@@ -47,6 +47,7 @@ type StateDB struct {
 	readSet  map[string][]byte   // save old/unmodified data
 	writeSet map[string][]byte   // last modify data
 	dirtySet map[string]struct{} // writeSet which key is modified
+	layer    *stateLayer         // values written before the state was forked
 
 	dbErr  error
 	refund uint64 // unuse gas
@@ -65,6 +66,8 @@ type StateDB struct {
 
 	stateTrace bool // replay transaction, true is replayed , false is not replayed
 
+	reads, writes, cacheHits uint64 // access statistics
+
 	lock sync.Mutex
 }
 
@@ -111,6 +114,7 @@ func (s *StateDB) Reset(root common.Hash) error {
 	s.readSet = make(map[string][]byte)
 	s.writeSet = make(map[string][]byte)
 	s.dirtySet = make(map[string]struct{})
+	s.layer = nil
 	s.thash = common.Hash{}
 	s.bhash = common.Hash{}
 	s.txIndex = 0
@@ -200,6 +204,7 @@ func (s *StateDB) set(key string, value []byte) {
 }
 
 func (s *StateDB) put(key string, value []byte) {
+	s.writes++
 	oldValue, _ := s.get(key)
 	s.journal.append(stateChange{key: &key,
 		prevalue: oldValue})
@@ -208,7 +213,14 @@ func (s *StateDB) put(key string, value []byte) {
 
 //get return nil when key not exsit
 func (s *StateDB) get(key string) ([]byte, error) {
+	s.reads++
 	if value, exsit := s.writeSet[key]; exsit {
+		s.cacheHits++
+		return common.CopyBytes(value), nil
+	}
+	if value, exsit := s.layer.get(key); exsit {
+		s.cacheHits++
+		s.writeSet[key] = value
 		return common.CopyBytes(value), nil
 	}
 
@@ -260,6 +272,12 @@ func (s *StateDB) RpcGet(account string, key string) ([]byte, error) {
 	return common.CopyBytes(value), nil
 }
 
+// AccessStats returns the count of reads, writes and reads hit the write set.
+// Every write reads the old value for the journal first.
+func (s *StateDB) AccessStats() (reads, writes, cacheHits uint64) {
+	return s.reads, s.writes, s.cacheHits
+}
+
 func (s *StateDB) Database() Database {
 	return s.db
 }
@@ -281,7 +299,7 @@ func (s *StateDB) Copy() *StateDB {
 		journal:   newJournal()}
 
 	for key := range s.journal.dirties {
-		value := s.writeSet[key]
+		value, _ := s.lookup(key)
 		state.readSet[key] = common.CopyBytes(value)
 		state.writeSet[key] = common.CopyBytes(value)
 	}
@@ -296,6 +314,22 @@ func (s *StateDB) Copy() *StateDB {
 	return state
 }
 
+// Preload caches the values the other state of the same root read from the
+// trie, the keys read or written by the state already are kept. The cached
+// values are recorded in the read set like the values read by the state.
+func (s *StateDB) Preload(from *StateDB) {
+	if s.trie.Hash() != from.trie.Hash() {
+		return
+	}
+	for key, value := range from.readSet {
+		if _, exsit := s.lookup(key); exsit {
+			continue
+		}
+		s.readSet[key] = common.CopyBytes(value)
+		s.writeSet[key] = common.CopyBytes(value)
+	}
+}
+
 func (s *StateDB) Snapshot() int {
 	id := s.nextRevisionID
 	s.nextRevisionID++
@@ -364,7 +398,7 @@ func (s *StateDB) Finalise() {
 	}
 
 	for key := range s.dirtySet {
-		value, exsit := s.writeSet[key]
+		value, exsit := s.lookup(key)
 		if !exsit {
 			panic("WriteSet is invalid when commit")
 		}
@@ -166,6 +166,23 @@ func TestPutAndGet(t *testing.T) {
 	}
 }
 
+func TestAccessStats(t *testing.T) {
+	db := mdb.NewMemDatabase()
+	stateX, err := New(common.Hash{}, NewDatabase(db))
+	if err != nil {
+		t.Fatal(fmt.Sprintf("new state error, %v", err))
+	}
+
+	stateX.Get("A", "key")
+	stateX.Put("A", "key", []byte("100"))
+	stateX.Get("A", "key")
+
+	reads, writes, cacheHits := stateX.AccessStats()
+	if reads != 3 || writes != 1 || cacheHits != 1 {
+		t.Error(fmt.Sprintf("access stats error, reads %v writes %v cacheHits %v", reads, writes, cacheHits))
+	}
+}
+
 func TestSetAndGetState(t *testing.T) {
 	db := mdb.NewMemDatabase()
 	batch := db.NewBatch()
@@ -0,0 +1,173 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	trie "github.com/fractalplatform/fractal/state/mtp"
+)
+
+const namedRecordPrefix = "namedRecord:"
+
+// StorageEntry a storage slot of the contract account
+type StorageEntry struct {
+	Key   common.Hash `json:"key"`
+	Value common.Hash `json:"value"`
+}
+
+// StorageRange storage slots of the contract account in the trie order. NextKey is
+// the trie key to continue the iteration, it is nil if the iteration is finished.
+type StorageRange struct {
+	Storage []StorageEntry `json:"storage"`
+	NextKey *common.Hash   `json:"nextKey"`
+}
+
+// storageKey the state key of the storage slot
+func storageKey(account string, key common.Hash) string {
+	return statePrefix + linkSymbol + account + linkSymbol + key.String()
+}
+
+//StorageRangeAt iterate the committed storage slots of the account from the trie key start,
+//at most maxResults slots are returned and maxScan state entries are visited.
+//when called please RLock cachedb
+func (s *StateDB) StorageRangeAt(account string, start common.Hash, maxResults int, maxScan int) (*StorageRange, error) {
+	prefix := statePrefix + linkSymbol + account + linkSymbol
+	result := &StorageRange{Storage: []StorageEntry{}}
+	it := trie.NewIterator(s.trie.NodeIterator(start[:]))
+	for scanned := 0; it.Next(); scanned++ {
+		if len(result.Storage) >= maxResults || scanned >= maxScan {
+			next := common.BytesToHash(it.Key)
+			result.NextKey = &next
+			break
+		}
+		preimage := s.trie.GetKey(it.Key)
+		if preimage == nil {
+			return nil, errors.New("storage key preimage not found")
+		}
+		if !strings.HasPrefix(string(preimage), prefix) || len(it.Value) != common.HashLength {
+			continue
+		}
+		key := common.HexToHash(strings.TrimPrefix(string(preimage), prefix))
+		result.Storage = append(result.Storage, StorageEntry{Key: key, Value: common.BytesToHash(it.Value)})
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+	return result, nil
+}
+
+// StateKey the account and the key of a state entry
+type StateKey struct {
+	Account string `json:"account"`
+	Key     string `json:"key"`
+	Storage bool   `json:"storage"` // a storage slot of the contract, the key is the hex of the slot
+}
+
+//ParseStateKey split the state key into the account and the key of the account, ok is false for a key of another form
+func ParseStateKey(key string) (stateKey StateKey, ok bool) {
+	parts := strings.SplitN(key, linkSymbol, 3)
+	if len(parts) != 3 {
+		return StateKey{}, false
+	}
+	switch parts[0] {
+	case acctDataPrefix:
+		return StateKey{Account: parts[1], Key: parts[2]}, true
+	case statePrefix:
+		return StateKey{Account: parts[1], Key: parts[2], Storage: true}, true
+	}
+	return StateKey{}, false
+}
+
+// namedRecordKey the account data key of the named record, it is distinct from the keys of the system modules
+func namedRecordKey(key string) string {
+	return namedRecordPrefix + key
+}
+
+//GetNamedRecord get the named record of the contract account
+func (s *StateDB) GetNamedRecord(account string, key string) ([]byte, error) {
+	return s.Get(account, namedRecordKey(key))
+}
+
+//SetNamedRecord set the named record of the contract account, an empty value deletes the record
+func (s *StateDB) SetNamedRecord(account string, key string, value []byte) {
+	if len(value) == 0 {
+		s.Delete(account, namedRecordKey(key))
+		return
+	}
+	s.Put(account, namedRecordKey(key), value)
+}
+
+// proofList collects the proof nodes in the order they are put
+type proofList []hexutil.Bytes
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, common.CopyBytes(value))
+	return nil
+}
+
+// prove get the merkle proof of the committed state key
+func (s *StateDB) prove(key string) ([]hexutil.Bytes, error) {
+	var proof proofList
+	if err := s.trie.Prove(crypto.Keccak256([]byte(key)), 0, &proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+//GetStorageProof get the merkle proof of the committed storage slot of the account, the proof of
+//an empty slot proves its absence
+//when called please RLock cachedb
+func (s *StateDB) GetStorageProof(account string, key common.Hash) ([]hexutil.Bytes, error) {
+	return s.prove(storageKey(account, key))
+}
+
+//VerifyStorageProof verify the storage proof against the state root and return the slot value
+func VerifyStorageProof(root common.Hash, account string, key common.Hash, proof []hexutil.Bytes) (common.Hash, error) {
+	value, err := common.VerifyProof(root, crypto.Keccak256([]byte(storageKey(account, key))), proof)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(value), nil
+}
+
+// dataKey the state key of the account data
+func dataKey(account string, key string) string {
+	return acctDataPrefix + linkSymbol + account + linkSymbol + key
+}
+
+//Prove get the merkle proof of the committed data of the key of the account, the proof of
+//a missing key proves its absence. The proof is checked by VerifyDataProof or by
+//common.VerifyProof with the trie key DataProofKey.
+//when called please RLock cachedb
+func (s *StateDB) Prove(account string, key string) ([]hexutil.Bytes, error) {
+	return s.prove(dataKey(account, key))
+}
+
+//DataProofKey the trie key of the data of the key of the account
+func DataProofKey(account string, key string) []byte {
+	return crypto.Keccak256([]byte(dataKey(account, key)))
+}
+
+//VerifyDataProof verify the account data proof against the state root and return the data, nil if it is absent
+func VerifyDataProof(root common.Hash, account string, key string, proof []hexutil.Bytes) ([]byte, error) {
+	return common.VerifyProof(root, DataProofKey(account, key), proof)
+}
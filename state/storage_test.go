@@ -0,0 +1,122 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+)
+
+func TestStorageRangeAndProof(t *testing.T) {
+	db := mdb.NewMemDatabase()
+	cachedb := NewDatabase(db)
+	statedb, err := New(common.Hash{}, cachedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots := make(map[common.Hash]common.Hash)
+	for i := 1; i <= 10; i++ {
+		key, value := common.BigToHash(big.NewInt(int64(i))), common.Hash{byte(i)}
+		slots[key] = value
+		statedb.SetState("contract", key, value)
+	}
+	statedb.SetState("other", common.Hash{}, common.Hash{1})
+	statedb.Put("contract", "data", []byte("not storage"))
+
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch, common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cachedb.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	batch.Write()
+	statedb, err = New(root, NewDatabase(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[common.Hash]common.Hash)
+	start := common.Hash{}
+	for {
+		result, err := statedb.StorageRangeAt("contract", start, 3, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Storage) > 3 {
+			t.Fatalf("storage range returns %d slots", len(result.Storage))
+		}
+		for _, entry := range result.Storage {
+			found[entry.Key] = entry.Value
+		}
+		if result.NextKey == nil {
+			break
+		}
+		start = *result.NextKey
+	}
+	if len(found) != len(slots) {
+		t.Fatalf("storage range found %d slots, want %d", len(found), len(slots))
+	}
+	for key, value := range slots {
+		if found[key] != value {
+			t.Fatalf("slot %x value %x, want %x", key, found[key], value)
+		}
+	}
+
+	for key, value := range slots {
+		proof, err := statedb.GetStorageProof("contract", key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := VerifyStorageProof(root, "contract", key, proof)
+		if err != nil || got != value {
+			t.Fatalf("verify proof of %x got %x, %v", key, got, err)
+		}
+		if _, err := VerifyStorageProof(common.Hash{1}, "contract", key, proof); err == nil {
+			t.Fatal("verify proof against wrong root should fail")
+		}
+	}
+	missing := common.Hash{0xff}
+	proof, err := statedb.GetStorageProof("contract", missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := VerifyStorageProof(root, "contract", missing, proof); err != nil || got != (common.Hash{}) {
+		t.Fatalf("verify absence proof got %x, %v", got, err)
+	}
+
+	proof, err = statedb.Prove("contract", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := VerifyDataProof(root, "contract", "data", proof); err != nil || string(got) != "not storage" {
+		t.Fatalf("verify data proof got %q, %v", got, err)
+	}
+	if got, err := VerifyDataProof(root, "contract", "nodata", proof); err == nil && got != nil {
+		t.Fatalf("verify data proof of other key got %q", got)
+	}
+	if got, err := common.VerifyProof(root, DataProofKey("contract", "data"), proof); err != nil || string(got) != "not storage" {
+		t.Fatalf("verify proof of the data key got %q, %v", got, err)
+	}
+	if _, err := common.VerifyProof(root, DataProofKey("contract", "data"), proof[:len(proof)-1]); err == nil {
+		t.Fatal("verify truncated proof should fail")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"math/rand"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
@@ -132,7 +133,11 @@ func getFirstBlockTimestamp(api *sdk.API) uint64 {
 	if err != nil {
 		panic(err)
 	}
-	return uint64(ret["timestamp"].(float64))
+	timestamp, err := strconv.ParseUint(ret["timestamp"].(string), 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	return timestamp
 }
 
 func getCurrentBlockTimestamp(api *sdk.API) uint64 {
@@ -140,7 +145,11 @@ func getCurrentBlockTimestamp(api *sdk.API) uint64 {
 	if err != nil {
 		panic(err)
 	}
-	return uint64(ret["timestamp"].(float64))
+	timestamp, err := strconv.ParseUint(ret["timestamp"].(string), 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	return timestamp
 }
 
 func getTxEpoch(api *sdk.API, hash common.Hash) uint64 {
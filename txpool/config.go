@@ -39,6 +39,8 @@ type Config struct {
 	Lifetime   time.Duration `mapstructure:"lifetime"`   // Maximum amount of time non-executable transaction are queued
 	ResendTime time.Duration `mapstructure:"resendtime"` // Maximum amount of time  executable transaction are resended
 
+	LocalResend time.Duration `mapstructure:"localresend"` // Time interval to re-broadcast the local executable transactions
+
 	MinBroadcast   uint64 `mapstructure:"minbroadcast"`   // Minimum number of nodes for the transaction broadcast
 	RatioBroadcast uint64 `mapstructure:"ratiobroadcast"` // Ratio of nodes for the transaction broadcast
 	GasAssetID     uint64
@@ -56,6 +58,7 @@ var DefaultTxPoolConfig = &Config{
 	GlobalQueue:    4096,
 	Lifetime:       3 * time.Hour,
 	ResendTime:     10 * time.Minute,
+	LocalResend:    time.Minute,
 	MinBroadcast:   3,
 	RatioBroadcast: 3,
 }
@@ -100,6 +103,10 @@ func (config *Config) check() Config {
 		log.Warn("Sanitizing invalid txpool resendtime", "provided", conf.ResendTime, "updated", DefaultTxPoolConfig.ResendTime)
 		conf.ResendTime = DefaultTxPoolConfig.ResendTime
 	}
+	if conf.LocalResend < time.Second {
+		log.Warn("Sanitizing invalid txpool localresend", "provided", conf.LocalResend, "updated", DefaultTxPoolConfig.LocalResend)
+		conf.LocalResend = DefaultTxPoolConfig.LocalResend
+	}
 	if conf.RatioBroadcast < 1 {
 		log.Warn("Sanitizing invalid txpool ratiobroadcast", "provided", conf.RatioBroadcast, "updated", DefaultTxPoolConfig.RatioBroadcast)
 		conf.RatioBroadcast = DefaultTxPoolConfig.RatioBroadcast
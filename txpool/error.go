@@ -36,6 +36,10 @@ var (
 	// with a different one without the required price bump.
 	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
 
+	// ErrBelowBaseFee is returned if a transaction's gas price is below the base
+	// fee of the pending block.
+	ErrBelowBaseFee = errors.New("transaction gas price below base fee")
+
 	// ErrInsufficientFundsForGas is returned if the gas cost of executing a transaction
 	// is higher than the balance of the user's account.
 	ErrInsufficientFundsForGas = errors.New("insufficient funds for gas * price")
@@ -55,4 +59,20 @@ var (
 	// ErrNegativeValue is a sanity error to ensure noone is able to specify a
 	// transaction with a negative value.
 	ErrNegativeValue = errors.New("negative value")
+
+	// ErrReplaced is recorded if a pooled transaction is replaced by another one
+	// with the same nonce and a higher gas price.
+	ErrReplaced = errors.New("replaced by a transaction with higher gas price")
+
+	// ErrUnpayable is recorded if a pooled transaction can no longer be paid or
+	// authorized by its sender.
+	ErrUnpayable = errors.New("insufficient funds or permissions")
+
+	// ErrPoolOverflow is recorded if a pooled transaction is evicted to keep the
+	// pool within its limits.
+	ErrPoolOverflow = errors.New("evicted by transaction pool limits")
+
+	// ErrExpired is recorded if a queued transaction stayed in the pool longer
+	// than its lifetime.
+	ErrExpired = errors.New("queued transaction expired")
 )
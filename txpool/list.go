@@ -120,7 +120,8 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64, signer types.Signer
 		}
 
 		// todo change action
-		return act.Value().Cmp(balance) > 0 || tx.Cost().Cmp(costLimit) > 0 || act.Gas() > gasLimit
+		// the sponsored gas is not paid by the sender funds
+		return act.Value().Cmp(balance) > 0 || (tx.Payer() == nil && tx.Cost().Cmp(costLimit) > 0) || act.Gas() > gasLimit
 	})
 
 	// If the list was strict, filter anything above the lowest nonce
@@ -139,6 +140,25 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64, signer types.Signer
 	return removed, invalids
 }
 
+// Expire removes all the transactions expired at the block of the number and
+// the unix time in seconds given. A strict list returns the transactions
+// following the lowest removed nonce as invalids too.
+func (l *txList) Expire(number, timestamp uint64) ([]*types.Transaction, []*types.Transaction) {
+	removed := l.txs.Filter(func(tx *types.Transaction) bool { return tx.Expired(number, timestamp) })
+
+	var invalids []*types.Transaction
+	if l.strict && len(removed) > 0 {
+		lowest := uint64(math.MaxUint64)
+		for _, tx := range removed {
+			if nonce := tx.GetActions()[0].Nonce(); lowest > nonce {
+				lowest = nonce
+			}
+		}
+		invalids = l.txs.Filter(func(tx *types.Transaction) bool { return tx.GetActions()[0].Nonce() > lowest })
+	}
+	return removed, invalids
+}
+
 // Cap places a hard limit on the number of items, returning all transactions
 // exceeding that limit.
 func (l *txList) Cap(threshold int) []*types.Transaction {
@@ -0,0 +1,74 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"time"
+
+	"github.com/fractalplatform/fractal/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// rejectedLimit is the number of recently rejected transactions remembered by the pool.
+const rejectedLimit = 4096
+
+// RejectedTx is the reason why a transaction was refused or dropped by the pool.
+type RejectedTx struct {
+	Hash   common.Hash `json:"hash"`
+	Reason string      `json:"reason"`
+	Time   uint64      `json:"time"` // unix seconds of the rejection
+}
+
+// txRejected remembers the rejection reasons of the most recent transactions,
+// the oldest ones are evicted once the limit is reached.
+type txRejected struct {
+	cache *lru.Cache
+}
+
+// newTxRejected returns a new txRejected structure.
+func newTxRejected(limit int) *txRejected {
+	cache, _ := lru.New(limit)
+	return &txRejected{cache: cache}
+}
+
+// Add records the reason the transaction was rejected.
+func (r *txRejected) Add(hash common.Hash, reason error) {
+	r.cache.Add(hash, &RejectedTx{
+		Hash:   hash,
+		Reason: reason.Error(),
+		Time:   uint64(time.Now().Unix()),
+	})
+}
+
+// Get returns the rejection of the transaction, nil if it was not rejected recently.
+func (r *txRejected) Get(hash common.Hash) *RejectedTx {
+	if v, ok := r.cache.Get(hash); ok {
+		return v.(*RejectedTx)
+	}
+	return nil
+}
+
+// Remove forgets the rejection of a transaction accepted afterwards.
+func (r *txRejected) Remove(hash common.Hash) {
+	r.cache.Remove(hash)
+}
+
+// GetRejected returns why the transaction was refused or dropped by the pool,
+// nil if it is unknown or was not rejected recently.
+func (tp *TxPool) GetRejected(hash common.Hash) *RejectedTx {
+	return tp.rejected.Get(hash)
+}
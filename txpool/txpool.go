@@ -80,16 +80,19 @@ type TxPool struct {
 	curAccountManager     *am.AccountManager // Current state in the blockchain head
 	pendingAccountManager *am.AccountManager // Pending state tracking virtual nonces
 	currentMaxGas         uint64             // Current gas limit for transaction caps
+	pendingBaseFee        *big.Int           // Base fee of the pending block, nil before the fee market
+	pendingNumber         uint64             // Number of the pending block, the transactions expired at it are dropped
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
-	pending map[common.Name]*txList
-	queue   map[common.Name]*txList
-	beats   map[common.Name]time.Time // Last heartbeat from each known account
-	all     *txLookup                 // All transactions to allow lookups
-	priced  *txPricedList
-	station *TxpoolStation
+	pending  map[common.Name]*txList
+	queue    map[common.Name]*txList
+	beats    map[common.Name]time.Time // Last heartbeat from each known account
+	all      *txLookup                 // All transactions to allow lookups
+	rejected *txRejected               // Reasons of the recently rejected transactions
+	priced   *txPricedList
+	station  *TxpoolStation
 
 	chainHeadCh     chan *event.Event
 	chainHeadSub    event.Subscription
@@ -109,7 +112,7 @@ func New(config Config, chainconfig *params.ChainConfig, bc blockChain) *TxPool
 	//  check the input to ensure no vulnerable gas prices are set
 	config.GasAssetID = chainconfig.SysTokenID
 	config = (&config).check()
-	signer := types.NewSigner(chainconfig.ChainID)
+	signer := types.MakeSigner(chainconfig, bc.CurrentBlock().CurForkID())
 	all := newTxLookup()
 
 	tp := &TxPool{
@@ -122,6 +125,7 @@ func New(config Config, chainconfig *params.ChainConfig, bc blockChain) *TxPool
 		beats:           make(map[common.Name]time.Time),
 		all:             all,
 		priced:          newTxPricedList(all),
+		rejected:        newTxRejected(rejectedLimit),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
 		chainHeadCh:     make(chan *event.Event, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
@@ -248,6 +252,9 @@ func (tp *TxPool) loop() {
 	resend := time.NewTicker(resendTxInterval)
 	defer resend.Stop()
 
+	localResend := time.NewTicker(tp.config.LocalResend)
+	defer localResend.Stop()
+
 	journal := time.NewTicker(tp.config.Rejournal)
 	defer journal.Stop()
 
@@ -290,6 +297,7 @@ func (tp *TxPool) loop() {
 				// Any non-locals old enough should be removed
 				if time.Since(tp.beats[name]) > tp.config.Lifetime {
 					for _, tx := range tp.queue[name].Flatten() {
+						tp.rejected.Add(tx.Hash(), ErrExpired)
 						tp.removeTx(tx.Hash(), true)
 					}
 				}
@@ -312,6 +320,26 @@ func (tp *TxPool) loop() {
 
 			log.Debug("resend account transactions", "txlen", resendTxsCount)
 			tp.mu.Unlock()
+			// Handle local transaction re-broadcast
+		case <-localResend.C:
+			tp.mu.Lock()
+
+			var resendTxsCount int
+
+			for name, list := range tp.pending {
+				if !tp.locals.contains(name) {
+					continue
+				}
+				if txs := list.Flatten(); len(txs) != 0 {
+					resendTxsFunc(txs)
+					resendTxsCount = resendTxsCount + len(txs)
+				}
+			}
+
+			if resendTxsCount != 0 {
+				log.Debug("resend local transactions", "txlen", resendTxsCount)
+			}
+			tp.mu.Unlock()
 			// Handle local transaction journal rotation
 		case <-journal.C:
 			if tp.journal != nil {
@@ -525,12 +553,21 @@ func (tp *TxPool) reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to create current NewAccountManager", "err", err)
 		return
 	}
-	tp.pendingAccountManager, err = am.NewAccountManager(statedb.Copy())
+	tp.pendingAccountManager, err = am.NewAccountManager(statedb.Fork())
 	if err != nil {
 		log.Error("Failed to create pending  NewAccountManager state", "err", err)
 		return
 	}
+	// pick the signer and the base fee of the pending block by its fork
+	forkID, err := tp.curAccountManager.CurForkID()
+	if err != nil {
+		log.Error("Failed to get current fork id", "err", err)
+		return
+	}
+	tp.signer = types.MakeSigner(tp.chain.Config(), forkID)
 	tp.currentMaxGas = newHead.GasLimit
+	tp.pendingBaseFee = types.CalcBaseFee(tp.chain.Config(), newHead, forkID)
+	tp.pendingNumber = newHead.Number.Uint64() + 1
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	SenderCacher.recover(tp.signer, reinject)
@@ -558,6 +595,19 @@ func (tp *TxPool) GasPrice() *big.Int {
 	return new(big.Int).Set(tp.gasPrice)
 }
 
+// Signer returns the signer of the pending block the transactions are validated with.
+func (tp *TxPool) Signer() types.Signer {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	return tp.signer
+}
+
+// PriceBump returns the minimum price bump percentage to replace a transaction of the same nonce.
+func (tp *TxPool) PriceBump() uint64 {
+	return tp.config.PriceBump
+}
+
 // SetGasPrice updates the minimum price required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (tp *TxPool) SetGasPrice(price *big.Int) {
@@ -566,6 +616,7 @@ func (tp *TxPool) SetGasPrice(price *big.Int) {
 
 	tp.gasPrice = price
 	for _, tx := range tp.priced.Cap(price, tp.locals) {
+		tp.rejected.Add(tx.Hash(), ErrUnderpriced)
 		tp.removeTx(tx.Hash(), false)
 	}
 	log.Info("Transaction pool price threshold updated", "price", price)
@@ -656,6 +707,10 @@ func (tp *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		if !local && tp.gasPrice.Cmp(tx.GasPrice()) > 0 {
 			return ErrUnderpriced
 		}
+		// Drop transactions which can not pay the base fee of the pending block
+		if tp.pendingBaseFee != nil && tp.pendingBaseFee.Cmp(tx.GasPrice()) > 0 {
+			return ErrBelowBaseFee
+		}
 		// Ensure the transaction adheres to nonce ordering
 		nonce, err := tp.curAccountManager.GetNonce(from)
 		if err != nil {
@@ -666,8 +721,9 @@ func (tp *TxPool) validateTx(tx *types.Transaction, local bool) error {
 			return ErrNonceTooLow
 		}
 
-		// Transactor should have enough funds to cover the gas costs
-		balance, err := tp.curAccountManager.GetAccountBalanceByID(from, tx.GasAssetID(), 0)
+		// Transactor or the fee payer should have enough funds to cover the gas costs
+		payer := tx.GasPayer(action)
+		balance, err := tp.curAccountManager.GetAccountBalanceByID(payer, tx.GasAssetID(), 0)
 		if err != nil {
 			return err
 		}
@@ -684,7 +740,7 @@ func (tp *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		}
 
 		value := action.Value()
-		if tp.config.GasAssetID == action.AssetID() {
+		if tp.config.GasAssetID == action.AssetID() && payer == from {
 			value.Add(value, gascost)
 		}
 
@@ -692,7 +748,7 @@ func (tp *TxPool) validateTx(tx *types.Transaction, local bool) error {
 			return ErrInsufficientFundsForValue
 		}
 
-		intrGas, err := IntrinsicGas(tp.curAccountManager, action)
+		intrGas, err := IntrinsicGas(tp.curAccountManager, action, tp.chain.Config())
 		if err != nil {
 			return err
 		}
@@ -704,6 +760,20 @@ func (tp *TxPool) validateTx(tx *types.Transaction, local bool) error {
 
 	}
 
+	// Drop transactions using the fields or the action types of a fork not active yet
+	forkID, err := tp.curAccountManager.CurForkID()
+	if err != nil {
+		return err
+	}
+	if err := tx.CheckFork(forkID); err != nil {
+		return err
+	}
+
+	// Drop transactions which can not be included by the pending block
+	if tx.Expired(tp.pendingNumber, uint64(time.Now().Unix())) {
+		return types.ErrTxExpired
+	}
+
 	// Make sure the transaction is signed properly
 	if err := tp.curAccountManager.RecoverTx(tp.signer, tx); err != nil {
 		return ErrInvalidSender
@@ -746,6 +816,7 @@ func (tp *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		drop := tp.priced.Discard(tp.all.Count()-int(tp.config.GlobalSlots+tp.config.GlobalQueue-1), tp.locals)
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
+			tp.rejected.Add(tx.Hash(), ErrUnderpriced)
 			tp.removeTx(tx.Hash(), false)
 		}
 	}
@@ -763,6 +834,7 @@ func (tp *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		if old != nil {
 			tp.all.Remove(old.Hash())
 			tp.priced.Removed(1)
+			tp.rejected.Add(old.Hash(), ErrReplaced)
 		}
 		tp.all.Add(tx)
 		tp.priced.Put(tx)
@@ -804,6 +876,7 @@ func (tp *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, erro
 	if old != nil {
 		tp.all.Remove(old.Hash())
 		tp.priced.Removed(1)
+		tp.rejected.Add(old.Hash(), ErrReplaced)
 	}
 	if tp.all.Get(hash) == nil {
 		tp.all.Add(tx)
@@ -840,12 +913,14 @@ func (tp *TxPool) promoteTx(name common.Name, hash common.Hash, tx *types.Transa
 		// An older transaction was better, discard this
 		tp.all.Remove(hash)
 		tp.priced.Removed(1)
+		tp.rejected.Add(hash, ErrReplaced)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
 	if old != nil {
 		tp.all.Remove(old.Hash())
 		tp.priced.Removed(1)
+		tp.rejected.Add(old.Hash(), ErrReplaced)
 
 	}
 	// Failsafe to work around direct pending inserts (tests)
@@ -910,7 +985,8 @@ func (tp *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	var addedTxs []*types.Transaction
 	var errs = make([]error, len(txs))
 	var indexs []int
-	// Cache senders in transactions before obtaining lock (pool.signer is immutable)
+	// Cache senders in transactions before obtaining lock, the signer changes only at the fork
+	signer := tp.Signer()
 	for index, tx := range txs {
 		// If the transaction is already known, discard it
 		if tp.all.Get(tx.Hash()) != nil {
@@ -922,11 +998,12 @@ func (tp *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 		if err := tx.Check(tp.chain.Config()); err != nil {
 			log.Trace("add txs check ", "err", err, "hash", tx.Hash())
 			errs[index] = fmt.Errorf("transaction check err: %v", err)
+			tp.rejected.Add(tx.Hash(), errs[index])
 			continue
 		}
 
 		for i, action := range tx.GetActions() {
-			if _, err := types.RecoverMultiKey(tp.signer, action, tx); err != nil {
+			if _, err := types.RecoverMultiKey(signer, action, tx); err != nil {
 				log.Trace("RecoverMultiKey reocver faild ", "err", err, "hash", tx.Hash())
 				errs[index] = fmt.Errorf("action %v,recoverMultiKey reocver faild: %v", i, err)
 				continue
@@ -960,7 +1037,12 @@ func (tp *TxPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *
 	for i, tx := range txs {
 		replaced, err := tp.add(tx, local)
 		errs[i] = err
-		if err == nil && !replaced {
+		if err != nil {
+			tp.rejected.Add(tx.Hash(), err)
+			continue
+		}
+		tp.rejected.Remove(tx.Hash())
+		if !replaced {
 			dirty.addTx(tx)
 		}
 	}
@@ -1067,12 +1149,21 @@ func (tp *TxPool) promoteExecutables(accounts []common.Name) []*types.Transactio
 			tp.all.Remove(hash)
 			log.Trace("Removed old queued transaction", "hash", hash)
 		}
+		// Drop all transactions expired at the pending block
+		expired, _ := list.Expire(tp.pendingNumber, uint64(time.Now().Unix()))
+		for _, tx := range expired {
+			hash := tx.Hash()
+			tp.all.Remove(hash)
+			tp.rejected.Add(hash, types.ErrTxExpired)
+			log.Trace("Removed expired queued transaction", "hash", hash)
+		}
 		// Drop all transactions that are too costly (low balance or out of gas)
 		balance, _ := tp.curAccountManager.GetAccountBalanceByID(name, tp.config.GasAssetID, 0)
 		drops, _ := list.Filter(balance, tp.currentMaxGas, tp.signer, tp.curAccountManager.GetAccountBalanceByID, tp.curAccountManager.RecoverTx)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			tp.all.Remove(hash)
+			tp.rejected.Add(hash, ErrUnpayable)
 			log.Trace("Removed unpayable queued transaction", "hash", hash)
 		}
 
@@ -1094,6 +1185,7 @@ func (tp *TxPool) promoteExecutables(accounts []common.Name) []*types.Transactio
 			for _, tx := range caps {
 				hash := tx.Hash()
 				tp.all.Remove(hash)
+				tp.rejected.Add(hash, ErrPoolOverflow)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 		}
@@ -1150,6 +1242,7 @@ func (tp *TxPool) truncatePending() {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						tp.all.Remove(hash)
+						tp.rejected.Add(hash, ErrPoolOverflow)
 
 						// Update the account nonce to the dropped transaction
 						pnonce, _ := tp.pendingAccountManager.GetNonce(offenders[i])
@@ -1176,6 +1269,7 @@ func (tp *TxPool) truncatePending() {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
 					tp.all.Remove(hash)
+					tp.rejected.Add(hash, ErrPoolOverflow)
 
 					// Update the account nonce to the dropped transaction
 					pnonce, _ := tp.pendingAccountManager.GetNonce(name)
@@ -1221,6 +1315,7 @@ func (tp *TxPool) truncateQueue() {
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
+				tp.rejected.Add(tx.Hash(), ErrPoolOverflow)
 				tp.removeTx(tx.Hash(), true)
 			}
 			drop -= size
@@ -1229,6 +1324,7 @@ func (tp *TxPool) truncateQueue() {
 		// Otherwise drop only last few transactions
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+			tp.rejected.Add(txs[i].Hash(), ErrPoolOverflow)
 			tp.removeTx(txs[i].Hash(), true)
 			drop--
 		}
@@ -1260,11 +1356,26 @@ func (tp *TxPool) demoteUnexecutables() {
 			log.Error("promoteExecutables current account manager get balance err ", "name", name, "assetID", tp.config.GasAssetID, "err", err)
 		}
 
+		expired, invalids := list.Expire(tp.pendingNumber, uint64(time.Now().Unix()))
+		for _, tx := range expired {
+			hash := tx.Hash()
+			log.Trace("Removed expired pending transaction", "hash", hash)
+			tp.all.Remove(hash)
+			tp.rejected.Add(hash, types.ErrTxExpired)
+			tp.priced.Removed(1)
+		}
+		for _, tx := range invalids {
+			hash := tx.Hash()
+			log.Trace("Demoting pending transaction", "hash", hash)
+			tp.enqueueTx(hash, tx)
+		}
+
 		drops, invalids := list.Filter(gasBalance, tp.currentMaxGas, tp.signer, tp.curAccountManager.GetAccountBalanceByID, tp.curAccountManager.RecoverTx)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending or no permissions transaction", "hash", hash)
 			tp.all.Remove(hash)
+			tp.rejected.Add(hash, ErrUnpayable)
 			tp.priced.Removed(1)
 		}
 
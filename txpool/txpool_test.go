@@ -35,8 +35,19 @@ import (
 	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/types"
 	mdb "github.com/fractalplatform/fractal/utils/fdb/memdb"
+	"github.com/fractalplatform/fractal/utils/rlp"
 )
 
+// setForkID stores the current fork id of the test chain in the state.
+func setForkID(t *testing.T, statedb *state.StateDB, id uint64) {
+	am.SetChainName(common.Name("txpooltestchain"))
+	b, err := rlp.EncodeToBytes(&struct{ CurForkID uint64 }{id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb.Put("txpooltestchain", "forkInfo", b)
+}
+
 // This test simulates a scenario where a new block is imported during a
 // state reset and tests whether the pending state is in sync with the
 // block head event that initiated the resetState().
@@ -744,6 +755,77 @@ func TestTransactionQueueAccountLimiting(t *testing.T) {
 	}
 }
 
+func TestTransactionExpiry(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(mdb.NewMemDatabase()))
+	setForkID(t, statedb, params.ForkID4)
+	blockchain := &testBlockChain{statedb, 10000000, new(event.Feed)}
+
+	pool := New(testTxPoolConfig, params.DefaultChainconfig, blockchain)
+	defer pool.Stop()
+
+	manager, _ := am.NewAccountManager(statedb)
+	tname := common.Name("totestname")
+	fname := common.Name("fromname")
+	fkey := generateAccount(t, fname, manager, pool.pendingAccountManager)
+	generateAccount(t, tname, manager, pool.pendingAccountManager)
+
+	pool.curAccountManager.AddAccountBalanceByID(fname, uint64(0), big.NewInt(10000000000))
+
+	expiring := func(nonce, expireAt uint64) *types.Transaction {
+		tx := newTx(big.NewInt(1), newAction(nonce, fname, tname, big.NewInt(100), 109000, nil))
+		tx.WithExpireAt(expireAt)
+		keyPair := types.MakeKeyPair(fkey, []uint64{0})
+		if err := types.SignActionWithMultiKey(tx.GetActions()[0], tx, pool.Signer(), 0, []*types.KeyPair{keyPair}); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	if !pool.Signer().Equal(types.MakeSigner(params.DefaultChainconfig, params.ForkID4)) {
+		t.Fatal("pool signer is not the signer of the pending fork")
+	}
+
+	// the expiry is rejected before the fork introducing it
+	setForkID(t, statedb, params.ForkID3)
+	if err := pool.addRemoteSync(expiring(0, pool.pendingNumber)); err != types.ErrNotForked {
+		t.Fatalf("unforked transaction error mismatch: have %v, want %v", err, types.ErrNotForked)
+	}
+	setForkID(t, statedb, params.ForkID4)
+
+	passed := expiring(0, uint64(time.Now().Add(-time.Hour).Unix()))
+	if err := pool.addRemoteSync(passed); err != types.ErrTxExpired {
+		t.Fatalf("expired transaction error mismatch: have %v, want %v", err, types.ErrTxExpired)
+	}
+	if rejected := pool.GetRejected(passed.Hash()); rejected == nil || rejected.Reason != types.ErrTxExpired.Error() {
+		t.Fatalf("expired transaction reason mismatch: have %v", rejected)
+	}
+
+	// the transactions are includable up to their expiry block
+	first := expiring(0, pool.pendingNumber)
+	if err := pool.addRemoteSync(first); err != nil {
+		t.Fatalf("failed to add expiring transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(expiring(1, 0)); err != nil {
+		t.Fatalf("failed to add following transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pending/queued mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+
+	pool.mu.Lock()
+	pool.pendingNumber++
+	pool.demoteUnexecutables()
+	pool.mu.Unlock()
+	if pending, queued := pool.Stats(); pending != 0 || queued != 1 {
+		t.Fatalf("pending/queued mismatch after expiry: have %d/%d, want 0/1", pending, queued)
+	}
+	if rejected := pool.GetRejected(first.Hash()); rejected == nil || rejected.Reason != types.ErrTxExpired.Error() {
+		t.Fatalf("dropped transaction reason mismatch: have %v", rejected)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that if the transaction count belonging to multiple accounts go above
 // some threshold, the higher transactions are dropped to prevent DOS attacks.
 //
@@ -1443,6 +1525,48 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that the refused and replaced transactions are remembered with their
+// reasons, and forgotten once accepted.
+func TestTransactionRejectedReasons(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(mdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 10000000, new(event.Feed)}
+
+	pool := New(testTxPoolConfig, params.DefaultChainconfig, blockchain)
+	defer pool.Stop()
+
+	manager, _ := am.NewAccountManager(statedb)
+	tname := common.Name("totestname")
+	fname := common.Name("fromname")
+	fkey := generateAccount(t, fname, manager, pool.pendingAccountManager)
+	generateAccount(t, tname, manager, pool.pendingAccountManager)
+
+	pool.curAccountManager.AddAccountBalanceByID(fname, uint64(0), big.NewInt(10000000000))
+
+	original := pricedTransaction(0, fname, tname, 1000000, big.NewInt(1), fkey)
+	if err := pool.addRemoteSync(original); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	if rejected := pool.GetRejected(original.Hash()); rejected != nil {
+		t.Fatalf("accepted transaction rejected: %v", rejected.Reason)
+	}
+	cheap := pricedTransaction(0, fname, tname, 1000010, big.NewInt(1), fkey)
+	if err := pool.addRemoteSync(cheap); err != ErrReplaceUnderpriced {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if rejected := pool.GetRejected(cheap.Hash()); rejected == nil || rejected.Reason != ErrReplaceUnderpriced.Error() {
+		t.Fatalf("refused transaction reason mismatch: have %v, want %v", rejected, ErrReplaceUnderpriced)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, fname, tname, 1000000, big.NewInt(2), fkey)); err != nil {
+		t.Fatalf("failed to replace original transaction: %v", err)
+	}
+	if rejected := pool.GetRejected(original.Hash()); rejected == nil || rejected.Reason != ErrReplaced.Error() {
+		t.Fatalf("replaced transaction reason mismatch: have %v, want %v", rejected, ErrReplaced)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that if the transaction count belonging to multiple accounts go above
 // some hard threshold, the higher transactions are dropped to prevent DOS
 // attacks.
@@ -27,7 +27,7 @@ import (
 )
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action) (uint64, error) {
+func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action, config *params.ChainConfig) (uint64, error) {
 	// Bump the required gas by the amount of transactional data
 	gasTable := params.GasTableInstanse
 	dataGasFunc := func(data []byte) (uint64, error) {
@@ -73,16 +73,33 @@ func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action
 		return 0
 	}
 
+	actionGasFunc := func(actionType types.ActionType) uint64 {
+		if actionType == types.CreateContract || actionType == types.CreateAccount {
+			return gasTable.ActionGasCreation
+		} else if actionType == types.IssueAsset {
+			return gasTable.ActionGasIssueAsset
+		} else if actionType == types.CallContract {
+			return gasTable.ActionGasCallContract
+		}
+		return gasTable.ActionGas
+	}
+
 	var gas uint64
 
-	if action.Type() == types.CreateContract || action.Type() == types.CreateAccount {
-		gas += gasTable.ActionGasCreation
-	} else if action.Type() == types.IssueAsset {
-		gas += gasTable.ActionGasIssueAsset
-	} else if action.Type() == types.CallContract {
-		gas += gasTable.ActionGasCallContract
-	} else {
-		gas += gasTable.ActionGas
+	gas += actionGasFunc(action.Type())
+
+	// the sub-actions of a batch pay as the actions they are
+	if action.Type() == types.BatchAction {
+		subs, err := types.DecodeBatch(action.Data())
+		if err != nil {
+			return 0, err
+		}
+		for _, sub := range subs {
+			gas += actionGasFunc(sub.Type)
+			if sub.Amount != nil && sub.Amount.Sign() != 0 {
+				gas += receiptGasFunc(sub.Action(action.Sender()))
+			}
+		}
 	}
 
 	dataGas, err := dataGasFunc(action.Data())
@@ -91,15 +108,30 @@ func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action
 	}
 	gas += dataGas
 
-	remarkGas, err := dataGasFunc(action.Remark())
-	if err != nil {
-		return 0, err
+	// the remark is priced per byte when the chain bounds it
+	if remarkCfg := config.RemarkCfg; remarkCfg != nil {
+		size := uint64(len(action.Remark()))
+		if remarkCfg.MaxSize != 0 && size > remarkCfg.MaxSize {
+			return 0, types.ErrRemarkTooLong
+		}
+		if remarkCfg.GasPerByte != 0 && (math.MaxUint64-gas)/remarkCfg.GasPerByte < size {
+			return 0, ErrOutOfGas
+		}
+		gas += size * remarkCfg.GasPerByte
+	} else {
+		remarkGas, err := dataGasFunc(action.Remark())
+		if err != nil {
+			return 0, err
+		}
+		gas += remarkGas
 	}
-	gas += remarkGas
 
 	if signLen := len(action.GetSign()); signLen > 1 {
 		gas += (uint64(len(action.GetSign()) - 1)) * gasTable.SignGas
 	}
+	if agg := action.GetAggregateSign(); agg != nil {
+		gas += params.BLSAggregateGas + uint64(len(agg.Indexes))*params.BLSAuthorGas
+	}
 
 	if action.Value().Sign() != 0 {
 		gas += receiptGasFunc(action)
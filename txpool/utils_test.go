@@ -0,0 +1,57 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestIntrinsicGasRemark(t *testing.T) {
+	remark := []byte{0, 1, 2, 3}
+	action := types.NewAction(types.Transfer, common.Name("fromname"), common.Name("toname"), 0, 0, 0, big.NewInt(0), nil, remark)
+	gasTable := params.GasTableInstanse
+
+	config := params.DefaultChainconfig.Copy()
+	gas, err := IntrinsicGas(nil, action, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := gasTable.ActionGas + gasTable.TxDataZeroGas + 3*gasTable.TxDataNonZeroGas; gas != want {
+		t.Fatalf("data priced remark gas %v, want %v", gas, want)
+	}
+
+	config.RemarkCfg = &params.RemarkConfig{MaxSize: 4, GasPerByte: 100}
+	if gas, err = IntrinsicGas(nil, action, config); err != nil {
+		t.Fatal(err)
+	}
+	if want := gasTable.ActionGas + 4*100; gas != want {
+		t.Fatalf("byte priced remark gas %v, want %v", gas, want)
+	}
+
+	config.RemarkCfg.MaxSize = 3
+	if _, err := IntrinsicGas(nil, action, config); err != types.ErrRemarkTooLong {
+		t.Fatalf("oversized remark err %v", err)
+	}
+	if err := action.Check(config); err != types.ErrRemarkTooLong {
+		t.Fatalf("oversized remark check err %v", err)
+	}
+}
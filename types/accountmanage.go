@@ -17,14 +17,23 @@
 package types
 
 import (
+	"math/big"
+
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/params"
 )
 
+// AssetVerifier consults the verification contract of the asset before the
+// action issues the amount to the target or changes the owner to the target.
+type AssetVerifier func(verifier common.Name, action *Action, assetID uint64, target common.Name, amount *big.Int) error
+
 type AccountManagerContext struct {
 	Action           *Action
 	ChainConfig      *params.ChainConfig
 	Number           uint64
+	Time             uint64
 	CurForkID        uint64
 	FromAccountExtra []common.Name
+	VerifyAsset      AssetVerifier // nil if no contract can be called, the assets with a verifier are refused
+	Output           *ActionOutput // set by the actions with a structured result, e.g. the id of the issued asset
 }
@@ -32,6 +32,19 @@ import (
 // ErrInvalidSig invalid signature.
 var ErrInvalidSig = errors.New("invalid action v, r, s values")
 
+// ErrRemarkTooLong is returned if the remark of the action exceeds the max size of the chain.
+var ErrRemarkTooLong = errors.New("action remark too long")
+
+// ErrContinueOnErrorInvalid is returned if the continue on error flag of the action is not canonical.
+var ErrContinueOnErrorInvalid = errors.New("action continue on error flag invalid")
+
+// ErrNotForked is returned if a transaction uses a field or an action type of a fork not active yet.
+var ErrNotForked = errors.New("transaction field or action type not supported by the current fork")
+
+// ErrActionUndefined is returned if the action type is not defined, the action
+// of a type of a fork not active yet fails with it in the blocks.
+var ErrActionUndefined = errors.New("Receipt undefined")
+
 // ActionType type of Action.
 type ActionType uint64
 
@@ -51,6 +64,68 @@ const (
 	DeleteAccount
 	// UpdateAccountAuthor represents the update account author.
 	UpdateAccountAuthor
+	// TransferEscrow repesents lock the value in escrow.
+	TransferEscrow
+	// ClaimEscrow repesents release the escrow to the recipient.
+	ClaimEscrow
+	// RefundEscrow repesents refund the expired escrow to the sender.
+	RefundEscrow
+	// TimeLockTransfer repesents lock the value for the recipient until maturity.
+	TimeLockTransfer
+	// ClaimTimeLock repesents release the matured time lock to the recipient.
+	ClaimTimeLock
+	// CancelTimeLock repesents refund the time lock to the sender before maturity.
+	CancelTimeLock
+	// SetInheritance repesents set the heir of the account.
+	SetInheritance
+	// ClaimInheritance repesents the heir claim the inactive account.
+	ClaimInheritance
+	// BidAccountName repesents bid for the premium account name.
+	BidAccountName
+	// ClaimAccountName repesents the winner create the auctioned account.
+	ClaimAccountName
+	// SetAuthorDelay repesents set the delay of the author update.
+	SetAuthorDelay
+	// CancelAuthorUpdate repesents cancel the pending author update.
+	CancelAuthorUpdate
+	// ApplyAuthorUpdate repesents apply the matured pending author update.
+	ApplyAuthorUpdate
+	// SetGuardians repesents set the recovery guardians of the account.
+	SetGuardians
+	// ProposeRecovery repesents the guardian propose new authors of the account.
+	ProposeRecovery
+	// ApproveRecovery repesents the guardian approve the recovery proposal.
+	ApproveRecovery
+	// CancelRecovery repesents the account cancel the recovery proposal.
+	CancelRecovery
+	// ExecuteRecovery repesents execute the approved recovery proposal.
+	ExecuteRecovery
+	// UpdateReservedNames repesents the system account replace the reserved account names.
+	UpdateReservedNames
+	// ApproveAsset repesents set the asset allowance of the spender.
+	ApproveAsset
+	// TransferFromAsset repesents the spender transfer the asset of the owner within the allowance.
+	TransferFromAsset
+	// DistributeAsset repesents the asset owner distribute the value to the holders of the asset.
+	DistributeAsset
+	// CreateInvitations repesents the sponsor pre-fund the account creation invitations.
+	CreateInvitations
+	// RedeemInvitation repesents create the account by the invitation code.
+	RedeemInvitation
+	// RevokeInvitation repesents the sponsor refund the unused invitation.
+	RevokeInvitation
+	// ReleaseHolderBalance repesents release the matured balance locks of the holder.
+	ReleaseHolderBalance
+	// AtomicSwap repesents two accounts exchange their assets, signed by both parties.
+	AtomicSwap
+	// BatchAction repesents the ordered sub-actions of the sender executed atomically under the batch signatures.
+	BatchAction
+	// ScheduleAction repesents the sender register an action executed automatically at a future block.
+	ScheduleAction
+	// CancelScheduledAction repesents the sender cancel its pending scheduled action.
+	CancelScheduledAction
+	// PermitTransfer repesents the relayer execute the transfer signed off-chain by the payer and pay its gas.
+	PermitTransfer
 )
 
 const (
@@ -60,13 +135,67 @@ const (
 	IssueAsset
 	// DestroyAsset destroy asset
 	DestroyAsset
-	// SetAssetOwner repesents set asset new owner action.
+	// SetAssetOwner repesents propose the asset new owner action, the new owner must accept it.
 	SetAssetOwner
 	// UpdateAsset update asset
 	UpdateAsset
 	// Transfer repesents transfer asset action.
 	Transfer
 	UpdateAssetContract
+	// UpdateTokenCurator repesents add or remove the token list curator.
+	UpdateTokenCurator
+	// UpdateTokenList repesents add, update or remove the token list entry.
+	UpdateTokenList
+	// SetAssetConversion repesents set the conversion ratio of the wrapped sub-asset.
+	SetAssetConversion
+	// WrapAsset repesents convert the parent asset to the wrapped sub-asset.
+	WrapAsset
+	// UnwrapAsset repesents convert the wrapped sub-asset back to the parent asset.
+	UnwrapAsset
+	// UpdateAssetAccessList repesents the asset owner replace the access list of the asset.
+	UpdateAssetAccessList
+	// IssueNFT repesents the asset owner issue a non-fungible token of the asset.
+	IssueNFT
+	// TransferNFT repesents transfer a non-fungible token to the recipient.
+	TransferNFT
+	// BurnNFT repesents the token owner burn a non-fungible token.
+	BurnNFT
+	// BurnAsset repesents the holder destroy its own balance of the asset.
+	BurnAsset
+	// SetAssetBurnable repesents the asset owner permit or forbid the holders burning the asset.
+	SetAssetBurnable
+	// SetAssetTransferFee repesents the asset owner set the fee rate taken on the asset transfers.
+	SetAssetTransferFee
+	// UpdateAssetMetadata repesents the asset owner replace the metadata of the asset.
+	UpdateAssetMetadata
+	// SetSubAssetDelegate repesents the asset owner set the sub-assets quota of the delegate.
+	SetSubAssetDelegate
+	// AcceptAssetOwner repesents the pending owner accept the ownership of the asset.
+	AcceptAssetOwner
+	// RedenominateAsset repesents the asset owner change the decimals of the asset and rescale the balances.
+	RedenominateAsset
+	// LockHolderBalance repesents the asset owner lock the balance of the holder until the unlock number.
+	LockHolderBalance
+	// GrantAssetRole repesents the asset owner grant the role of the asset to the account.
+	GrantAssetRole
+	// RevokeAssetRole repesents the asset owner revoke the role of the asset from the account.
+	RevokeAssetRole
+	// PauseAsset repesents the asset owner or pauser pause or unpause the transfers of the asset.
+	PauseAsset
+	// SetAssetBanned repesents the asset owner or blacklister ban or unban the account of the asset.
+	SetAssetBanned
+	// RegisterWrappedAsset repesents the asset owner flag the asset as wrapped external asset minted by the bridge.
+	RegisterWrappedAsset
+	// BridgeMint repesents the bridge mint the wrapped asset against the deposit proof on the origin chain.
+	BridgeMint
+	// BridgeBurn repesents the bridge burn the wrapped asset against the withdrawal on the origin chain.
+	BridgeBurn
+	// UpdateAssetUpperLimit repesents the asset owner lower the upper limit, or propose the raise to the approvers.
+	UpdateAssetUpperLimit
+	// ApproveAssetUpperLimit repesents the approver approve the proposed raise of the upper limit.
+	ApproveAssetUpperLimit
+	// SetAssetVerifier repesents the asset founder set the contract verifying the issuance and ownership changes.
+	SetAssetVerifier
 )
 
 const (
@@ -89,6 +218,8 @@ const (
 	ExitTakeOver
 	// RemoveKickedCandidate kicked
 	RemoveKickedCandidate
+	// ForceSnapshot repesents the system schedules a snapshot taken by a block out of the snapshot interval.
+	ForceSnapshot
 )
 
 const (
@@ -96,9 +227,47 @@ const (
 	WithdrawFee ActionType = 0x500 + iota
 )
 
+// forkID returns the fork id introducing the action type.
+func (t ActionType) forkID() uint64 {
+	switch {
+	case t > UpdateAccountAuthor && t <= PermitTransfer:
+		return params.ForkID4
+	case t > UpdateAssetContract && t <= SetAssetVerifier:
+		return params.ForkID4
+	case t == ForceSnapshot:
+		return params.ForkID4
+	}
+	return params.ForkID0
+}
+
+// IsForked checks the action type is defined by the forks up to the fork id.
+func (t ActionType) IsForked(forkID uint64) bool {
+	return t.forkID() <= forkID
+}
+
 type Signature struct {
 	ParentIndex uint64
 	SignData    []*SignData
+	Aggregate   []*AggregateSign `rlp:"tail"` // at most one, signed by the BLS authors
+}
+
+// DecodeRLP implements rlp.Decoder, the signatures without aggregate decode with nil aggregate.
+func (s *Signature) DecodeRLP(st *rlp.Stream) error {
+	type signature Signature
+	if err := st.Decode((*signature)(s)); err != nil {
+		return err
+	}
+	if len(s.Aggregate) == 0 {
+		s.Aggregate = nil
+	}
+	return nil
+}
+
+// AggregateSign is the BLS signature aggregated from the signatures of the BLS
+// authors at the indexes, they sign the same hash as the ECDSA authors.
+type AggregateSign struct {
+	Sig     []byte
+	Indexes [][]uint64
 }
 
 type SignData struct {
@@ -120,6 +289,8 @@ type actionData struct {
 	Remark   []byte
 
 	Sign *Signature
+
+	ContinueOnError []bool `rlp:"tail"` // at most one true, set if the failure of the action is tolerated
 }
 
 // Action represents an entire action in the transaction.
@@ -146,7 +317,7 @@ func NewAction(actionType ActionType, from, to common.Name, nonce, assetID, gasL
 		Amount:   new(big.Int),
 		Payload:  payload,
 		Remark:   remark,
-		Sign:     &Signature{0, make([]*SignData, 0)},
+		Sign:     &Signature{ParentIndex: 0, SignData: make([]*SignData, 0)},
 	}
 	if amount != nil {
 		data.Amount.Set(amount)
@@ -162,12 +333,41 @@ func (a *Action) GetSign() []*SignData {
 	return a.data.Sign.SignData
 }
 
+// GetAggregateSign returns the aggregated BLS signature of the action, nil if none.
+func (a *Action) GetAggregateSign() *AggregateSign {
+	if len(a.data.Sign.Aggregate) == 0 {
+		return nil
+	}
+	return a.data.Sign.Aggregate[0]
+}
+
 func (a *Action) GetSignParent() uint64 {
 	return a.data.Sign.ParentIndex
 }
 
+// CheckFork checks the action uses only the types and the fields of the forks up to the fork id.
+func (a *Action) CheckFork(forkID uint64) error {
+	if !a.Type().IsForked(forkID) {
+		return ErrNotForked
+	}
+	return a.CheckForkFields(forkID)
+}
+
+// CheckForkFields checks the action uses only the fields of the forks up to the
+// fork id, the blocks reject the fields the action could not be decoded with
+// before their fork.
+func (a *Action) CheckForkFields(forkID uint64) error {
+	if forkID < params.ForkID4 && (a.ContinueOnError() || a.GetAggregateSign() != nil) {
+		return ErrNotForked
+	}
+	return nil
+}
+
 // Check the validity of all fields
 func (a *Action) Check(conf *params.ChainConfig) error {
+	if conf.RemarkCfg != nil && conf.RemarkCfg.MaxSize != 0 && uint64(len(a.data.Remark)) > conf.RemarkCfg.MaxSize {
+		return ErrRemarkTooLong
+	}
 	//check To
 	switch a.Type() {
 	case CreateContract:
@@ -183,6 +383,68 @@ func (a *Action) Check(conf *params.ChainConfig) error {
 	case DeleteAccount:
 		fallthrough
 	case UpdateAccountAuthor:
+		fallthrough
+	case TransferEscrow:
+		fallthrough
+	case ClaimEscrow:
+		fallthrough
+	case RefundEscrow:
+		fallthrough
+	case TimeLockTransfer:
+		fallthrough
+	case ClaimTimeLock:
+		fallthrough
+	case CancelTimeLock:
+		fallthrough
+	case SetInheritance:
+		fallthrough
+	case ClaimInheritance:
+		fallthrough
+	case BidAccountName:
+		fallthrough
+	case ClaimAccountName:
+		fallthrough
+	case SetAuthorDelay:
+		fallthrough
+	case CancelAuthorUpdate:
+		fallthrough
+	case ApplyAuthorUpdate:
+		fallthrough
+	case SetGuardians:
+		fallthrough
+	case ProposeRecovery:
+		fallthrough
+	case ApproveRecovery:
+		fallthrough
+	case CancelRecovery:
+		fallthrough
+	case ExecuteRecovery:
+		fallthrough
+	case UpdateReservedNames:
+		fallthrough
+	case ApproveAsset:
+		fallthrough
+	case TransferFromAsset:
+		fallthrough
+	case DistributeAsset:
+		fallthrough
+	case CreateInvitations:
+		fallthrough
+	case RedeemInvitation:
+		fallthrough
+	case RevokeInvitation:
+		fallthrough
+	case AtomicSwap:
+		fallthrough
+	case BatchAction:
+		fallthrough
+	case ScheduleAction:
+		fallthrough
+	case CancelScheduledAction:
+		fallthrough
+	case PermitTransfer:
+		fallthrough
+	case ReleaseHolderBalance:
 		if a.data.To.String() != conf.AccountName {
 			return fmt.Errorf("Receipt should is %v", conf.AccountName)
 		}
@@ -197,6 +459,60 @@ func (a *Action) Check(conf *params.ChainConfig) error {
 		fallthrough
 	case UpdateAssetContract:
 		fallthrough
+	case UpdateTokenCurator:
+		fallthrough
+	case UpdateTokenList:
+		fallthrough
+	case SetAssetConversion:
+		fallthrough
+	case WrapAsset:
+		fallthrough
+	case UnwrapAsset:
+		fallthrough
+	case UpdateAssetAccessList:
+		fallthrough
+	case IssueNFT:
+		fallthrough
+	case TransferNFT:
+		fallthrough
+	case BurnNFT:
+		fallthrough
+	case BurnAsset:
+		fallthrough
+	case SetAssetBurnable:
+		fallthrough
+	case SetAssetTransferFee:
+		fallthrough
+	case UpdateAssetMetadata:
+		fallthrough
+	case SetSubAssetDelegate:
+		fallthrough
+	case AcceptAssetOwner:
+		fallthrough
+	case RedenominateAsset:
+		fallthrough
+	case LockHolderBalance:
+		fallthrough
+	case GrantAssetRole:
+		fallthrough
+	case RevokeAssetRole:
+		fallthrough
+	case PauseAsset:
+		fallthrough
+	case SetAssetBanned:
+		fallthrough
+	case RegisterWrappedAsset:
+		fallthrough
+	case BridgeMint:
+		fallthrough
+	case BridgeBurn:
+		fallthrough
+	case UpdateAssetUpperLimit:
+		fallthrough
+	case ApproveAssetUpperLimit:
+		fallthrough
+	case SetAssetVerifier:
+		fallthrough
 	case UpdateAsset:
 		if a.data.To.String() != conf.AssetName {
 			return fmt.Errorf("Receipt should is %v", conf.AssetName)
@@ -217,6 +533,8 @@ func (a *Action) Check(conf *params.ChainConfig) error {
 		fallthrough
 	case RemoveKickedCandidate:
 		fallthrough
+	case ForceSnapshot:
+		fallthrough
 	case ExitTakeOver:
 		if a.data.To.String() != conf.DposName {
 			return fmt.Errorf("Receipt should is %v", conf.DposName)
@@ -225,7 +543,7 @@ func (a *Action) Check(conf *params.ChainConfig) error {
 			return fmt.Errorf("Asset id should is %v", conf.SysTokenID)
 		}
 	default:
-		return fmt.Errorf("Receipt undefined")
+		return ErrActionUndefined
 	}
 
 	//check value
@@ -238,8 +556,22 @@ func (a *Action) Check(conf *params.ChainConfig) error {
 		fallthrough
 	case CreateAccount:
 		fallthrough
+	case TransferEscrow:
+		fallthrough
+	case TimeLockTransfer:
+		fallthrough
+	case DistributeAsset:
+		fallthrough
+	case CreateInvitations:
+		fallthrough
+	case BidAccountName:
+		fallthrough
 	case DestroyAsset:
 		fallthrough
+	case WrapAsset:
+		fallthrough
+	case UnwrapAsset:
+		fallthrough
 	case RegCandidate:
 		fallthrough
 	case UpdateCandidate:
@@ -273,6 +605,16 @@ func (a *Action) Data() []byte { return common.CopyBytes(a.data.Payload) }
 // Remark returns action's remark.
 func (a *Action) Remark() []byte { return common.CopyBytes(a.data.Remark) }
 
+// ContinueOnError returns whether the failure of the action is recorded in
+// its result without failing the transaction by its failure policy.
+func (a *Action) ContinueOnError() bool { return len(a.data.ContinueOnError) != 0 }
+
+// WithContinueOnError tolerates the failure of the action, it is signed by the
+// senders so it must be set before signing.
+func (a *Action) WithContinueOnError() {
+	a.data.ContinueOnError = []bool{true}
+}
+
 // Gas returns action's Gas.
 func (a *Action) Gas() uint64 { return a.data.GasLimit }
 
@@ -284,9 +626,19 @@ func (a *Action) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, &a.data)
 }
 
-// DecodeRLP implements rlp.Decoder
+// DecodeRLP implements rlp.Decoder, only the canonical continue on error flag
+// is accepted so the encoding of an action is unique.
 func (a *Action) DecodeRLP(s *rlp.Stream) error {
-	return s.Decode(&a.data)
+	if err := s.Decode(&a.data); err != nil {
+		return err
+	}
+	switch {
+	case len(a.data.ContinueOnError) == 0:
+		a.data.ContinueOnError = nil
+	case len(a.data.ContinueOnError) > 1 || !a.data.ContinueOnError[0]:
+		return ErrContinueOnErrorInvalid
+	}
+	return nil
 }
 
 // ChainID returns which chain id this action was signed for (if at all)
@@ -314,6 +666,11 @@ func (a *Action) WithSignature(signer Signer, sig []byte, index []uint64) error
 	return nil
 }
 
+// WithAggregateSign sets the aggregated BLS signature of the authors at the indexes.
+func (a *Action) WithAggregateSign(sig []byte, indexes [][]uint64) {
+	a.data.Sign.Aggregate = []*AggregateSign{{Sig: common.CopyBytes(sig), Indexes: indexes}}
+}
+
 // WithSignature returns a new transaction with the given signature.
 func (a *Action) WithParentIndex(parentIndex uint64) {
 	a.data.Sign.ParentIndex = parentIndex
@@ -332,6 +689,8 @@ type RPCAction struct {
 	Payload    hexutil.Bytes `json:"payload"`
 	Hash       common.Hash   `json:"actionHash"`
 	ActionIdex uint64        `json:"actionIndex"`
+
+	ContinueOnError bool `json:"continueOnError,omitempty"`
 }
 
 // NewRPCAction returns a action that will serialize to the RPC.
@@ -348,6 +707,8 @@ func (a *Action) NewRPCAction(index uint64) *RPCAction {
 		Payload:    hexutil.Bytes(a.Data()),
 		Hash:       a.Hash(),
 		ActionIdex: index,
+
+		ContinueOnError: a.ContinueOnError(),
 	}
 }
 
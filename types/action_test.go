@@ -103,6 +103,55 @@ func TestActionEncodeAndDecode(t *testing.T) {
 	assert.Equal(t, testAction, actAction)
 }
 
+func TestActionContinueOnError(t *testing.T) {
+	action := NewAction(Transfer, common.Name("fromname"), common.Name("totoname"), 1, 3, 2000, big.NewInt(1000), nil, nil)
+	plain, err := rlp.EncodeToBytes(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(big.NewInt(1))
+	plainHash := signer.Hash(NewTransaction(0, big.NewInt(0), action))
+
+	action.WithContinueOnError()
+	encoded, err := rlp.EncodeToBytes(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(plain, encoded) {
+		t.Fatal("flag not encoded")
+	}
+	decoded := new(Action)
+	if err := rlp.DecodeBytes(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.ContinueOnError() || decoded.Hash() != action.Hash() {
+		t.Fatal("decoded flag mismatch")
+	}
+	if signer.Hash(NewTransaction(0, big.NewInt(0), action)) == plainHash {
+		t.Fatal("flag not signed by the sender")
+	}
+	if err := action.CheckFork(params.ForkID3); err != ErrNotForked {
+		t.Fatalf("flag before fork err %v", err)
+	}
+	if err := action.CheckFork(params.ForkID4); err != nil {
+		t.Fatalf("flag after fork err %v", err)
+	}
+	aggregated := NewAction(Transfer, common.Name("fromname"), common.Name("totoname"), 1, 3, 2000, big.NewInt(1000), nil, nil)
+	aggregated.WithAggregateSign([]byte{1}, [][]uint64{{0}})
+	if err := aggregated.CheckFork(params.ForkID3); err != ErrNotForked {
+		t.Fatalf("aggregate sign before fork err %v", err)
+	}
+
+	// the unset flag is never encoded
+	invalid, err := rlp.EncodeToBytes([]interface{}{Transfer, uint64(1), uint64(3), common.Name("fromname"), common.Name("totoname"), uint64(2000), big.NewInt(1000), []byte{}, []byte{}, &Signature{}, false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rlp.DecodeBytes(invalid, new(Action)); err != ErrContinueOnErrorInvalid {
+		t.Fatalf("decode unset flag err %v", err)
+	}
+}
+
 func TestAction_Check(t *testing.T) {
 	actionBytes, err := rlp.EncodeToBytes(testAction)
 	if err != nil {
@@ -0,0 +1,82 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/params"
+)
+
+// CalcBaseFee returns the base fee of the child block of the parent in the fork
+// id, it is nil before the fee market fork. The base fee rises if the parent used
+// more gas than the target and falls if it used less, by at most 1/denominator
+// of the parent base fee.
+func CalcBaseFee(config *params.ChainConfig, parent *Header, forkID uint64) *big.Int {
+	if !config.IsFeeMarket(forkID) {
+		return nil
+	}
+	cfg := config.FeeMarket()
+	minBaseFee := new(big.Int)
+	if cfg.MinBaseFee != nil {
+		minBaseFee.Set(cfg.MinBaseFee)
+	}
+	parentBaseFee := parent.GetBaseFee()
+	if parentBaseFee == nil {
+		if cfg.InitialBaseFee == nil || cfg.InitialBaseFee.Cmp(minBaseFee) < 0 {
+			return minBaseFee
+		}
+		return new(big.Int).Set(cfg.InitialBaseFee)
+	}
+
+	target := cfg.Target(parent.GasLimit)
+	if target == 0 || parent.GasUsed == target {
+		return new(big.Int).Set(parentBaseFee)
+	}
+	denominator := new(big.Int).SetUint64(cfg.Denominator())
+	baseFee := new(big.Int)
+	if parent.GasUsed > target {
+		delta := new(big.Int).Mul(parentBaseFee, new(big.Int).SetUint64(parent.GasUsed-target))
+		delta.Div(delta, new(big.Int).SetUint64(target))
+		delta.Div(delta, denominator)
+		if delta.Sign() == 0 {
+			delta.SetUint64(1)
+		}
+		baseFee.Add(parentBaseFee, delta)
+	} else {
+		delta := new(big.Int).Mul(parentBaseFee, new(big.Int).SetUint64(target-parent.GasUsed))
+		delta.Div(delta, new(big.Int).SetUint64(target))
+		delta.Div(delta, denominator)
+		baseFee.Sub(parentBaseFee, delta)
+	}
+	if baseFee.Cmp(minBaseFee) < 0 {
+		return minBaseFee
+	}
+	return baseFee
+}
+
+// VerifyBaseFee checks the base fee of the header is computed from the parent
+// by the fork of the header.
+func VerifyBaseFee(config *params.ChainConfig, parent, header *Header) error {
+	expected := CalcBaseFee(config, parent, header.CurForkID())
+	baseFee := header.GetBaseFee()
+	if len(header.BaseFee) > 1 || (expected == nil) != (baseFee == nil) || (expected != nil && expected.Cmp(baseFee) != 0) {
+		return fmt.Errorf("invalid base fee: have %v, want %v", baseFee, expected)
+	}
+	return nil
+}
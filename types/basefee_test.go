@@ -0,0 +1,79 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/params"
+)
+
+func TestCalcBaseFee(t *testing.T) {
+	config := &params.ChainConfig{FeeCfg: &params.FeeConfig{InitialBaseFee: big.NewInt(1000), MinBaseFee: big.NewInt(100)}}
+	header := func(number int64, gasUsed uint64, baseFee int64) *Header {
+		h := &Header{Number: big.NewInt(number), GasLimit: 1000, GasUsed: gasUsed}
+		if baseFee != 0 {
+			h.WithBaseFee(big.NewInt(baseFee))
+		}
+		return h
+	}
+	tests := []struct {
+		parent *Header
+		forkID uint64
+		want   *big.Int
+	}{
+		{header(8, 0, 0), params.ForkID3, nil},
+		{header(9, 0, 0), params.ForkID4, big.NewInt(1000)},
+		{header(10, 500, 1000), params.ForkID4, big.NewInt(1000)},
+		{header(10, 1000, 1000), params.ForkID4, big.NewInt(1125)},
+		{header(10, 501, 100), params.ForkID4, big.NewInt(101)},
+		{header(10, 0, 1000), params.ForkID4, big.NewInt(875)},
+		{header(10, 0, 105), params.ForkID4, big.NewInt(100)},
+	}
+	for i, test := range tests {
+		got := CalcBaseFee(config, test.parent, test.forkID)
+		if (got == nil) != (test.want == nil) || (got != nil && got.Cmp(test.want) != 0) {
+			t.Errorf("test %d: base fee %v, want %v", i, got, test.want)
+		}
+	}
+
+	parent := header(10, 1000, 1000)
+	child := header(11, 0, 1125)
+	child.WithForkID(params.ForkID4, params.ForkID4)
+	if err := VerifyBaseFee(config, parent, child); err != nil {
+		t.Fatal(err)
+	}
+	child.WithBaseFee(nil)
+	if err := VerifyBaseFee(config, parent, child); err == nil {
+		t.Fatal("missing base fee should fail")
+	}
+	child.WithForkID(params.ForkID3, params.ForkID4)
+	if err := VerifyBaseFee(config, parent, child); err != nil {
+		t.Fatalf("block before the fork without base fee err %v", err)
+	}
+
+	// the chain without the fee market config starts from the zero base fee
+	if got := CalcBaseFee(&params.ChainConfig{}, header(9, 0, 0), params.ForkID4); got == nil || got.Sign() != 0 {
+		t.Fatalf("default base fee %v, want 0", got)
+	}
+
+	// the header without base fee keeps the encoding of the header before the fork
+	if h := header(1, 0, 0); h.Hash() != CopyHeader(h).Hash() || len(CopyHeader(h).BaseFee) != 0 {
+		t.Fatal("copy header without base fee mismatch")
+	}
+}
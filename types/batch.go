@@ -0,0 +1,93 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// A batch action carries an ordered list of sub-actions of the sender, signed
+// once by the signature of the batch and executed all or nothing.
+
+// MaxBatchActions the max sub-actions of a batch action.
+const MaxBatchActions = 16
+
+var (
+	ErrBatchEmpty      = errors.New("batch action has no sub-action")
+	ErrBatchTooLong    = errors.New("batch action exceeds the max sub-actions")
+	ErrBatchActionType = errors.New("action type not allowed in a batch action")
+)
+
+// SubAction is an action of the batch, sent by the sender of the batch.
+type SubAction struct {
+	Type    ActionType  `json:"type"`
+	To      common.Name `json:"to"`
+	AssetID uint64      `json:"assetId"`
+	Amount  *big.Int    `json:"amount"`
+	Payload []byte      `json:"payload"`
+}
+
+// Action returns the sub-action as the action of the sender.
+func (s *SubAction) Action(sender common.Name) *Action {
+	amount := s.Amount
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	return NewAction(s.Type, sender, s.To, 0, s.AssetID, 0, amount, s.Payload, nil)
+}
+
+// BatchAllows check the action type can be a sub-action of a batch. The
+// contract, consensus and fee actions, the author updates signed under the
+// update threshold, the scheduled actions and the batches themselves can't.
+func BatchAllows(t ActionType) bool {
+	switch t {
+	case BatchAction, ScheduleAction, UpdateAccountAuthor, SetAuthorDelay, SetGuardians, SetInheritance:
+		return false
+	}
+	group := uint64(t) >> 8
+	return group == 1 || group == 2
+}
+
+// ScheduleAllows check the action type can be scheduled, the same types a
+// batch allows except the cancel of the scheduled actions.
+func ScheduleAllows(t ActionType) bool {
+	return t != CancelScheduledAction && BatchAllows(t)
+}
+
+// DecodeBatch decodes and checks the sub-actions of the batch payload.
+func DecodeBatch(data []byte) ([]*SubAction, error) {
+	var subs []*SubAction
+	if err := rlp.DecodeBytes(data, &subs); err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, ErrBatchEmpty
+	}
+	if len(subs) > MaxBatchActions {
+		return nil, ErrBatchTooLong
+	}
+	for _, sub := range subs {
+		if !BatchAllows(sub.Type) {
+			return nil, ErrBatchActionType
+		}
+	}
+	return subs, nil
+}
@@ -51,6 +51,9 @@ type Header struct {
 	Time                 *big.Int
 	Extra                []byte
 	ForkID               ForkID
+	// BaseFee holds the base fee since the fee market fork, it is empty before the
+	// fork so the encoding of the earlier headers is unchanged.
+	BaseFee []*big.Int `rlp:"tail"`
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
@@ -62,6 +65,22 @@ func (h *Header) WithForkID(cur, next uint64) {
 	h.ForkID = ForkID{Cur: cur, Next: next}
 }
 
+// GetBaseFee returns the header's base fee, nil before the fee market fork.
+func (h *Header) GetBaseFee() *big.Int {
+	if len(h.BaseFee) == 0 {
+		return nil
+	}
+	return h.BaseFee[0]
+}
+
+// WithBaseFee store base fee
+func (h *Header) WithBaseFee(baseFee *big.Int) {
+	h.BaseFee = nil
+	if baseFee != nil {
+		h.BaseFee = []*big.Int{new(big.Int).Set(baseFee)}
+	}
+}
+
 // CurForkID returns the header's current fork ID.
 func (h *Header) CurForkID() uint64 { return h.ForkID.Cur }
 
@@ -253,6 +272,7 @@ func CopyHeader(h *Header) *Header {
 		cpy.Extra = make([]byte, len(h.Extra))
 		copy(cpy.Extra, h.Extra)
 	}
+	cpy.WithBaseFee(h.GetBaseFee())
 	return &cpy
 }
 
@@ -0,0 +1,52 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+// BlockTreeNode a block known to the node, canonical or not.
+type BlockTreeNode struct {
+	Hash            common.Hash `json:"hash"`
+	ParentHash      common.Hash `json:"parentHash"`
+	Number          uint64      `json:"number"`
+	Coinbase        common.Name `json:"coinbase"`
+	Time            uint64      `json:"time"`
+	Difficulty      *big.Int    `json:"difficulty"`
+	TotalDifficulty *big.Int    `json:"totalDifficulty"` // weight compared by the fork choice
+	Canonical       bool        `json:"canonical"`
+	Tip             bool        `json:"tip"` // no child known
+}
+
+// BlockTreeHeight the blocks known at a height and the chosen one.
+type BlockTreeHeight struct {
+	Number    uint64           `json:"number"`
+	Canonical common.Hash      `json:"canonical"`
+	Blocks    []*BlockTreeNode `json:"blocks"`
+}
+
+// BlockTree the recent blocks known to the node by height.
+type BlockTree struct {
+	Head    common.Hash        `json:"head"`
+	Number  uint64             `json:"number"`
+	Reorgs  uint64             `json:"reorgs"` // chain reorganisations since the node started
+	Tips    []common.Hash      `json:"tips"`
+	Heights []*BlockTreeHeight `json:"heights"`
+}
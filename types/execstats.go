@@ -0,0 +1,63 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"time"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+// ActionExecStat execution statistic of one action type.
+type ActionExecStat struct {
+	Count    uint64        `json:"count"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ExecStats execution statistics of a processed block.
+type ExecStats struct {
+	Number          uint64                         `json:"number"`
+	Hash            common.Hash                    `json:"hash"`
+	TxCount         uint64                         `json:"txCount"`
+	Duration        time.Duration                  `json:"duration"`
+	Actions         map[ActionType]*ActionExecStat `json:"actions"`
+	StateReads      uint64                         `json:"stateReads"`
+	StateWrites     uint64                         `json:"stateWrites"`
+	StateCacheHits  uint64                         `json:"stateCacheHits"`
+	SignVerifies    uint64                         `json:"signVerifies"`
+	AuthorCacheHits uint64                         `json:"authorCacheHits"`
+}
+
+// NewExecStats returns empty execution statistics of the block.
+func NewExecStats(number uint64, hash common.Hash) *ExecStats {
+	return &ExecStats{
+		Number:  number,
+		Hash:    hash,
+		Actions: make(map[ActionType]*ActionExecStat),
+	}
+}
+
+// AddAction records one executed action of the type.
+func (s *ExecStats) AddAction(aType ActionType, d time.Duration) {
+	stat, ok := s.Actions[aType]
+	if !ok {
+		stat = &ActionExecStat{}
+		s.Actions[aType] = stat
+	}
+	stat.Count++
+	stat.Duration += d
+}
@@ -16,7 +16,10 @@
 
 package types
 
-import "github.com/fractalplatform/fractal/common"
+import (
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
 
 type DetailTx struct {
 	TxHash  common.Hash     `json:"txhash"`
@@ -27,13 +30,59 @@ type DetailAction struct {
 	InternalActions []*InternalAction `json:"internalActions"`
 }
 
+// InternalAction is an action executed by an action of the transaction. The ID and the
+// position are derived from the transaction, they are not stored.
 type InternalAction struct {
-	Action     *RPCAction `json:"action"`
-	ActionType string     `json:"actionType"`
-	GasUsed    uint64     `json:"gasUsed"`
-	GasLimit   uint64     `json:"gasLimit"`
-	Depth      uint64     `json:"depth"`
-	Error      string     `json:"error"`
+	ID          common.Hash     `json:"id" rlp:"-"`
+	ActionIndex uint64          `json:"actionIndex" rlp:"-"`
+	Index       uint64          `json:"index" rlp:"-"`
+	Action      *RPCAction      `json:"action"`
+	ActionType  string          `json:"actionType"`
+	GasUsed     uint64          `json:"gasUsed"`
+	GasLimit    uint64          `json:"gasLimit"`
+	Depth       uint64          `json:"depth"`
+	Error       string          `json:"error"`
+	Outputs     []*ActionOutput `json:"outputs,omitempty" rlp:"tail"` // at most one, the return data of the call or the issued asset
+}
+
+// DecodeRLP implements rlp.Decoder, the internal actions without output decode with nil outputs.
+func (a *InternalAction) DecodeRLP(s *rlp.Stream) error {
+	type internal InternalAction
+	if err := s.Decode((*internal)(a)); err != nil {
+		return err
+	}
+	if len(a.Outputs) == 0 {
+		a.Outputs = nil
+	}
+	return nil
+}
+
+// SetOutput records the output of the internal action, nil records none.
+func (a *InternalAction) SetOutput(output *ActionOutput) {
+	a.Outputs = nil
+	if output != nil {
+		a.Outputs = []*ActionOutput{output}
+	}
+}
+
+// InternalActionID returns the id of the internal action at index of the internal actions
+// of the action at actionIndex of the transaction. The id is the same in every block
+// including the transaction, so it identifies the internal action across reorgs.
+func InternalActionID(txHash common.Hash, actionIndex uint64, index uint64) common.Hash {
+	return RlpHash([]interface{}{txHash, actionIndex, index})
+}
+
+// SetInternalActionIDs sets the ids and the positions of the internal actions of the transaction.
+func (d *DetailTx) SetInternalActionIDs() {
+	for i, action := range d.Actions {
+		if action == nil {
+			continue
+		}
+		for j, internal := range action.InternalActions {
+			internal.ActionIndex, internal.Index = uint64(i), uint64(j)
+			internal.ID = InternalActionID(d.TxHash, uint64(i), uint64(j))
+		}
+	}
 }
 
 type BlockAndResult struct {
@@ -47,8 +96,17 @@ type TxHeightHashPair struct {
 	Height uint64      `json:"height"`
 }
 
+// TxPosition the position of a transaction in the chain, the cursor of the transaction lists
+type TxPosition struct {
+	Height uint64 `json:"height"`
+	Index  uint64 `json:"index"`
+}
+
+// AccountTxs the txs of a block range. Next is the position to continue from when the
+// limit stopped the range before the end height, it is nil if the range is finished.
 type AccountTxs struct {
 	Txs                     []*TxHeightHashPair `json:"txs"`
 	IrreversibleBlockHeight uint64              `json:"irreversibleBlockHeight"`
 	EndHeight               uint64              `json:"endHeight"`
+	Next                    *TxPosition         `json:"next"`
 }
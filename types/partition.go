@@ -0,0 +1,112 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+)
+
+// AccountPartition returns the partition of the account. The sub accounts share the
+// partition of their main account, so an account tree never spans partitions.
+func AccountPartition(name common.Name, partitions uint64) uint64 {
+	if partitions < 2 {
+		return 0
+	}
+	main := name.String()
+	if i := strings.IndexByte(main, '.'); i >= 0 {
+		main = main[:i]
+	}
+	h := crypto.Keccak256([]byte(main))
+	return binary.BigEndian.Uint64(h[:8]) % partitions
+}
+
+// PartitionReceipt is the value moved by an action from the account of a partition to
+// the account of another one, the partition of the recipient credits the value by it.
+type PartitionReceipt struct {
+	ID            common.Hash `json:"id"` // hash of the action or id of the internal action
+	ActionIndex   uint64      `json:"actionIndex"`
+	Internal      bool        `json:"internal"`
+	Index         uint64      `json:"index"` // position among the internal actions of the action
+	From          common.Name `json:"from"`
+	To            common.Name `json:"to"`
+	FromPartition uint64      `json:"fromPartition"`
+	ToPartition   uint64      `json:"toPartition"`
+	AssetID       uint64      `json:"assetID"`
+	Amount        *big.Int    `json:"amount"`
+}
+
+// PartitionReceipts returns the receipts of the value moved across the partitions by the
+// successful actions of the transaction and their internal actions, dtx may be nil.
+func PartitionReceipts(tx *Transaction, receipt *Receipt, dtx *DetailTx, partitions uint64) []*PartitionReceipt {
+	var receipts []*PartitionReceipt
+	if partitions < 2 {
+		return receipts
+	}
+	cross := func(from, to common.Name, amount *big.Int) (uint64, uint64, bool) {
+		if amount == nil || amount.Sign() <= 0 {
+			return 0, 0, false
+		}
+		fromPartition, toPartition := AccountPartition(from, partitions), AccountPartition(to, partitions)
+		return fromPartition, toPartition, fromPartition != toPartition
+	}
+	for i, action := range tx.GetActions() {
+		if i >= len(receipt.ActionResults) || receipt.ActionResults[i].Status != ReceiptStatusSuccessful {
+			continue
+		}
+		if fromPartition, toPartition, ok := cross(action.Sender(), action.Recipient(), action.Value()); ok {
+			receipts = append(receipts, &PartitionReceipt{
+				ID:            action.Hash(),
+				ActionIndex:   uint64(i),
+				From:          action.Sender(),
+				To:            action.Recipient(),
+				FromPartition: fromPartition,
+				ToPartition:   toPartition,
+				AssetID:       action.AssetID(),
+				Amount:        new(big.Int).Set(action.Value()),
+			})
+		}
+		if dtx == nil || i >= len(dtx.Actions) || dtx.Actions[i] == nil {
+			continue
+		}
+		for j, internal := range dtx.Actions[i].InternalActions {
+			if internal.Error != "" || internal.Action == nil {
+				continue
+			}
+			a := internal.Action
+			if fromPartition, toPartition, ok := cross(a.From, a.To, a.Amount); ok {
+				receipts = append(receipts, &PartitionReceipt{
+					ID:            InternalActionID(tx.Hash(), uint64(i), uint64(j)),
+					ActionIndex:   uint64(i),
+					Internal:      true,
+					Index:         uint64(j),
+					From:          a.From,
+					To:            a.To,
+					FromPartition: fromPartition,
+					ToPartition:   toPartition,
+					AssetID:       a.AssetID,
+					Amount:        new(big.Int).Set(a.Amount),
+				})
+			}
+		}
+	}
+	return receipts
+}
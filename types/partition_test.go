@@ -0,0 +1,91 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAccountPartition(t *testing.T) {
+	const partitions = 4
+	counts := make([]int, partitions)
+	for i := 0; i < 400; i++ {
+		name := common.Name(fmt.Sprintf("partitiontest%d", i))
+		p := AccountPartition(name, partitions)
+		if p >= partitions {
+			t.Fatalf("partition %d out of range", p)
+		}
+		if sub := AccountPartition(common.Name(name.String()+".sub"), partitions); sub != p {
+			t.Fatalf("sub account partition %d, main %d", sub, p)
+		}
+		counts[p]++
+	}
+	for p, count := range counts {
+		if count == 0 {
+			t.Errorf("partition %d has no account", p)
+		}
+	}
+	if p := AccountPartition(common.Name("partitiontest0"), 1); p != 0 {
+		t.Errorf("disabled partitioning partition %d", p)
+	}
+}
+
+func TestPartitionReceipts(t *testing.T) {
+	const partitions = 2
+	// find the names of the other partition
+	a, b := common.Name("partitiona"), common.Name("")
+	for i := 0; b == ""; i++ {
+		if name := common.Name(fmt.Sprintf("partitionb%d", i)); AccountPartition(name, partitions) != AccountPartition(a, partitions) {
+			b = name
+		}
+	}
+	local := common.Name(a.String() + ".local")
+
+	cross := NewAction(Transfer, a, b, 0, 1, 0, big.NewInt(10), nil, nil)
+	same := NewAction(Transfer, a, local, 1, 1, 0, big.NewInt(10), nil, nil)
+	failed := NewAction(Transfer, b, a, 0, 1, 0, big.NewInt(10), nil, nil)
+	tx := NewTransaction(0, big.NewInt(1), cross, same, failed)
+	receipt := &Receipt{ActionResults: []*ActionResult{
+		{Status: ReceiptStatusSuccessful},
+		{Status: ReceiptStatusSuccessful},
+		{Status: ReceiptStatusFailed},
+	}}
+	internal := NewAction(Transfer, local, b, 0, 2, 0, big.NewInt(3), nil, nil)
+	dtx := &DetailTx{TxHash: tx.Hash(), Actions: []*DetailAction{
+		{},
+		{InternalActions: []*InternalAction{{Action: internal.NewRPCAction(0)}, {Action: internal.NewRPCAction(1), Error: "reverted"}}},
+		{InternalActions: []*InternalAction{{Action: internal.NewRPCAction(0)}}},
+	}}
+
+	if receipts := PartitionReceipts(tx, receipt, dtx, 1); len(receipts) != 0 {
+		t.Fatalf("disabled partitioning receipts %v", receipts)
+	}
+	receipts := PartitionReceipts(tx, receipt, dtx, partitions)
+	if len(receipts) != 2 {
+		t.Fatalf("receipts %v, want 2", len(receipts))
+	}
+	if r := receipts[0]; r.ID != cross.Hash() || r.Internal || r.From != a || r.To != b || r.Amount.Int64() != 10 || r.FromPartition == r.ToPartition {
+		t.Errorf("action receipt %+v", r)
+	}
+	if r := receipts[1]; r.ID != InternalActionID(tx.Hash(), 1, 0) || !r.Internal || r.ActionIndex != 1 || r.From != local || r.AssetID != 2 || r.Amount.Int64() != 3 {
+		t.Errorf("internal action receipt %+v", r)
+	}
+}
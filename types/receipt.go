@@ -19,6 +19,7 @@ package types
 import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/utils/rlp"
 )
 
@@ -28,6 +29,14 @@ const (
 
 	// ReceiptStatusSuccessful is the status code of a action if execution succeeded.
 	ReceiptStatusSuccessful = uint64(1)
+
+	// ReceiptStatusReverted is the status code of a action succeeded but reverted
+	// by the atomic failure policy of the transaction.
+	ReceiptStatusReverted = uint64(2)
+
+	// ReceiptStatusSkipped is the status code of a action not executed after a
+	// failure by the prefix failure policy of the transaction.
+	ReceiptStatusSkipped = uint64(3)
 )
 
 // ActionResult represents the results the transaction action.
@@ -37,22 +46,75 @@ type GasDistribution struct {
 	TypeID  uint64 `json:"typeId"`
 }
 
+// ActionOutput is the outcome of an action kept for the clients, the return data
+// of a contract call or the structured result of a native action.
+type ActionOutput struct {
+	ReturnData hexutil.Bytes `json:"returnData,omitempty"` // return data of the contract call, at most MaxReturnDataSize bytes
+	Truncated  bool          `json:"truncated,omitempty"`  // return data was longer than MaxReturnDataSize
+	AssetID    uint64        `json:"assetId,omitempty"`    // id of the asset issued by the action
+}
+
+// NewReturnOutput returns the output of a contract call returning ret, nil if ret is empty.
+func NewReturnOutput(ret []byte) *ActionOutput {
+	if len(ret) == 0 {
+		return nil
+	}
+	output := &ActionOutput{}
+	if uint64(len(ret)) > params.MaxReturnDataSize {
+		ret, output.Truncated = ret[:params.MaxReturnDataSize], true
+	}
+	output.ReturnData = common.CopyBytes(ret)
+	return output
+}
+
 type ActionResult struct {
 	Status   uint64
 	Index    uint64
 	GasUsed  uint64
 	GasAllot []*GasDistribution
 	Error    string
+	Outputs  []*ActionOutput `rlp:"tail"` // at most one, a tail so the receipts stored before still decode
+}
+
+// DecodeRLP implements rlp.Decoder, the results without output decode with nil outputs.
+func (a *ActionResult) DecodeRLP(s *rlp.Stream) error {
+	type result ActionResult
+	if err := s.Decode((*result)(a)); err != nil {
+		return err
+	}
+	if len(a.Outputs) == 0 {
+		a.Outputs = nil
+	}
+	return nil
+}
+
+// SetOutput records the output of the action, nil records none.
+func (a *ActionResult) SetOutput(output *ActionOutput) {
+	a.Outputs = nil
+	if output != nil {
+		a.Outputs = []*ActionOutput{output}
+	}
+}
+
+// Output returns the output of the action, nil if none.
+func (a *ActionResult) Output() *ActionOutput {
+	if len(a.Outputs) == 0 {
+		return nil
+	}
+	return a.Outputs[0]
 }
 
 // RPCActionResult that will serialize to the RPC representation of a ActionResult.
 type RPCActionResult struct {
-	ActionType uint64             `json:"actionType"`
-	Status     uint64             `json:"status"`
-	Index      uint64             `json:"index"`
-	GasUsed    uint64             `json:"gasUsed"`
-	GasAllot   []*GasDistribution `json:"gasAllot"`
-	Error      string             `json:"error"`
+	ActionType        uint64             `json:"actionType"`
+	Status            uint64             `json:"status"`
+	Index             uint64             `json:"index"`
+	GasUsed           uint64             `json:"gasUsed"`
+	GasAllot          []*GasDistribution `json:"gasAllot"`
+	Error             string             `json:"error"`
+	InternalActionIDs []common.Hash      `json:"internalActionIDs,omitempty"`
+	Remark            hexutil.Bytes      `json:"remark,omitempty"` // memo of the action, e.g. the deposit reference of a transfer
+	Output            *ActionOutput      `json:"output,omitempty"`
 }
 
 // NewRPCActionResult returns a ActionResult that will serialize to the RPC.
@@ -64,6 +126,7 @@ func (a *ActionResult) NewRPCActionResult(aType ActionType) *RPCActionResult {
 		GasUsed:    a.GasUsed,
 		GasAllot:   a.GasAllot,
 		Error:      a.Error,
+		Output:     a.Output(),
 	}
 }
 
@@ -124,7 +187,9 @@ func (r *Receipt) NewRPCReceipt(blockHash common.Hash, blockNumber uint64, index
 
 	var rpcActionResults []*RPCActionResult
 	for i, a := range tx.GetActions() {
-		rpcActionResults = append(rpcActionResults, r.ActionResults[i].NewRPCActionResult(a.Type()))
+		rpcActionResult := r.ActionResults[i].NewRPCActionResult(a.Type())
+		rpcActionResult.Remark = hexutil.Bytes(a.Remark())
+		rpcActionResults = append(rpcActionResults, rpcActionResult)
 	}
 	result.ActionResults = rpcActionResults
 
@@ -137,6 +202,23 @@ func (r *Receipt) NewRPCReceipt(blockHash common.Hash, blockNumber uint64, index
 	return result
 }
 
+// SetInternalActionIDs sets the ids of the internal actions of the transaction to the action results.
+func (r *RPCReceipt) SetInternalActionIDs(dtx *DetailTx) {
+	for i, action := range dtx.Actions {
+		if i >= len(r.ActionResults) {
+			break
+		}
+		if action == nil {
+			continue
+		}
+		ids := make([]common.Hash, 0, len(action.InternalActions))
+		for j := range action.InternalActions {
+			ids = append(ids, InternalActionID(dtx.TxHash, uint64(i), uint64(j)))
+		}
+		r.ActionResults[i].InternalActionIDs = ids
+	}
+}
+
 // ConsensusReceipt returns consensus encoding of a receipt.
 func (r *Receipt) ConsensusReceipt() *Receipt {
 	result := &Receipt{
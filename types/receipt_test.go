@@ -19,6 +19,7 @@ package types
 import (
 	"testing"
 
+	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/utils/rlp"
 
 	"github.com/stretchr/testify/assert"
@@ -37,3 +38,48 @@ func TestReceiptEncodeAndDecode(t *testing.T) {
 	rlp.DecodeBytes(bytes, newR)
 	assert.Equal(t, testR, newR)
 }
+
+func TestActionResultOutput(t *testing.T) {
+	// the results stored before the outputs decode
+	legacy := struct {
+		Status   uint64
+		Index    uint64
+		GasUsed  uint64
+		GasAllot []*GasDistribution
+		Error    string
+	}{Status: ReceiptStatusSuccessful, Index: 1, GasUsed: 100, Error: "err"}
+	bytes, err := rlp.EncodeToBytes(&legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &ActionResult{}
+	if err := rlp.DecodeBytes(bytes, result); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &ActionResult{Status: ReceiptStatusSuccessful, Index: 1, GasUsed: 100, GasAllot: []*GasDistribution{}, Error: "err"}, result)
+	encoded, _ := rlp.EncodeToBytes(result)
+	assert.Equal(t, bytes, encoded)
+
+	// the return data is bounded
+	ret := make([]byte, params.MaxReturnDataSize+1)
+	output := NewReturnOutput(ret)
+	assert.True(t, output.Truncated)
+	assert.Equal(t, int(params.MaxReturnDataSize), len(output.ReturnData))
+	assert.Nil(t, NewReturnOutput(nil))
+
+	result.SetOutput(&ActionOutput{ReturnData: []byte{1, 2}, AssetID: 7})
+	bytes, err = rlp.EncodeToBytes(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &ActionResult{}
+	if err := rlp.DecodeBytes(bytes, decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, result.Output(), decoded.Output())
+	assert.Equal(t, uint64(7), decoded.NewRPCActionResult(IssueAsset).Output.AssetID)
+
+	// the outputs are not part of the consensus receipt
+	receipt := &Receipt{ActionResults: []*ActionResult{decoded}}
+	assert.Nil(t, receipt.ConsensusReceipt().ActionResults[0].Output())
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import "errors"
+
+// The scope of an author is a bitmap of the action types its key may sign,
+// one word per action type group (the high byte of the type), bit n of the
+// word allows the n-th type of the group. An empty scope allows all types.
+
+// ActionScope returns the scope allowing the action types.
+func ActionScope(actionTypes ...ActionType) []uint64 {
+	var scope []uint64
+	for _, t := range actionTypes {
+		group, offset := uint64(t)>>8, uint64(t)&0xff
+		if offset >= 64 {
+			continue
+		}
+		for uint64(len(scope)) <= group {
+			scope = append(scope, 0)
+		}
+		scope[group] |= 1 << offset
+	}
+	return scope
+}
+
+// ScopeAllows check the scope allows the action type.
+func ScopeAllows(scope []uint64, t ActionType) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	group, offset := uint64(t)>>8, uint64(t)&0xff
+	if group >= uint64(len(scope)) || offset >= 64 {
+		return false
+	}
+	return scope[group]&(1<<offset) != 0
+}
+
+// ErrUnknownPermission is returned for a permission that is not predefined.
+var ErrUnknownPermission = errors.New("unknown author permission")
+
+// Permission is a predefined author scope, like the permission levels of the
+// EOS accounts.
+type Permission string
+
+const (
+	// TransferPermission allows the key to transfer assets only.
+	TransferPermission Permission = "transfer"
+	// VotePermission allows the key to vote the candidates only.
+	VotePermission Permission = "vote"
+	// CallContractPermission allows the key to call the contracts only.
+	CallContractPermission Permission = "callcontract"
+)
+
+// permissionTypes the action types allowed by the predefined permissions.
+var permissionTypes = map[Permission][]ActionType{
+	TransferPermission:     {Transfer},
+	VotePermission:         {VoteCandidate},
+	CallContractPermission: {CallContract},
+}
+
+// Scope returns the ActionScope of the action types the permission allows. An
+// unknown permission is an error, as its empty scope would allow all types.
+func (p Permission) Scope() ([]uint64, error) {
+	actionTypes, ok := permissionTypes[p]
+	if !ok {
+		return nil, ErrUnknownPermission
+	}
+	return ActionScope(actionTypes...), nil
+}
@@ -24,6 +24,8 @@ import (
 
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/crypto/bls"
+	"github.com/fractalplatform/fractal/params"
 )
 
 var (
@@ -33,6 +35,8 @@ var (
 	ErrSigUnprotected = errors.New("signature is considered unprotected")
 	//ErrSignEmpty signature is considered unprotected
 	ErrSignEmpty = errors.New("signature is nil")
+	//ErrActionNotInTx the action is not one of the transaction
+	ErrActionNotInTx = errors.New("action not in transaction")
 )
 
 // sigCache is used to cache the derived sender and contains the signer used to derive it.
@@ -50,9 +54,36 @@ func MakeKeyPair(priv *ecdsa.PrivateKey, index []uint64) *KeyPair {
 	return &KeyPair{priv, index}
 }
 
-// MakeSigner returns a Signer based on the given chainID .
-func MakeSigner(chainID *big.Int) Signer {
-	return NewSigner(chainID)
+// MakeSigner returns the signer of the blocks of the fork id. A change of the
+// signature hash rules adds a signer activated at its fork on top of the
+// earlier ones, the blocks before the fork keep their signer so the old
+// transactions still validate.
+func MakeSigner(config *params.ChainConfig, forkID uint64) Signer {
+	if forkID >= params.ForkID4 {
+		return NewChainIDSigner(config.ChainID)
+	}
+	return NewSigner(config.ChainID)
+}
+
+// SignHash returns the digest the authors of the action sign with their keys,
+// the raw 32 bytes are signed as is by secp256k1 without any prefix. The digest
+// covers every action of the transaction with the chain id and the gas fields,
+// so the transaction must be complete before signing. It follows the initial
+// signature rules, use SignHashBy with the signer of the block to follow the
+// rules of the later forks.
+func SignHash(a *Action, tx *Transaction, chainID *big.Int) (common.Hash, error) {
+	return SignHashBy(NewSigner(chainID), a, tx)
+}
+
+// SignHashBy returns the digest the authors of the action sign by the rules
+// of the signer.
+func SignHashBy(s Signer, a *Action, tx *Transaction) (common.Hash, error) {
+	for _, action := range tx.GetActions() {
+		if action == a {
+			return s.Hash(tx), nil
+		}
+	}
+	return common.Hash{}, ErrActionNotInTx
 }
 
 func SignActionWithMultiKey(a *Action, tx *Transaction, s Signer, parentIndex uint64, keys []*KeyPair) error {
@@ -72,6 +103,56 @@ func SignActionWithMultiKey(a *Action, tx *Transaction, s Signer, parentIndex ui
 	return nil
 }
 
+// BLSKeyPair is a BLS secret key with the sign index of its author.
+type BLSKeyPair struct {
+	priv  *bls.SecretKey
+	index []uint64
+}
+
+func MakeBLSKeyPair(priv *bls.SecretKey, index []uint64) *BLSKeyPair {
+	return &BLSKeyPair{priv, index}
+}
+
+// SignActionWithBLSKeys signs the action by the BLS keys, their signatures are
+// aggregated into one signature.
+func SignActionWithBLSKeys(a *Action, tx *Transaction, s Signer, parentIndex uint64, keys []*BLSKeyPair) error {
+	h := s.Hash(tx)
+	sigs := make([][]byte, 0, len(keys))
+	indexes := make([][]uint64, 0, len(keys))
+	for _, key := range keys {
+		sigs = append(sigs, bls.Sign(h[:], key.priv))
+		indexes = append(indexes, key.index)
+	}
+	sig, err := bls.Aggregate(sigs)
+	if err != nil {
+		return err
+	}
+	a.WithAggregateSign(sig, indexes)
+	a.WithParentIndex(parentIndex)
+	return nil
+}
+
+// SignPayerWithMultiKey signs the transaction by the keys of its fee payer.
+func SignPayerWithMultiKey(tx *Transaction, s Signer, parentIndex uint64, keys []*KeyPair) error {
+	if tx.payer == nil {
+		return ErrPayerInvalid
+	}
+	h := s.PayerHash(tx)
+	for _, key := range keys {
+		sig, err := crypto.Sign(h[:], key.priv)
+		if err != nil {
+			return err
+		}
+		r, sv, v, err := s.SignatureValues(sig)
+		if err != nil {
+			return err
+		}
+		tx.payer.Sign.SignData = append(tx.payer.Sign.SignData, &SignData{R: r, S: sv, V: v, Index: key.index})
+	}
+	tx.payer.Sign.ParentIndex = parentIndex
+	return nil
+}
+
 func RecoverMultiKey(signer Signer, a *Action, tx *Transaction) ([]common.PubKey, error) {
 	if sc := a.senderPubkeys.Load(); sc != nil {
 		sigCache := sc.(sigCache)
@@ -103,41 +184,88 @@ func GetAuthorCache(a *Action) map[common.Name]common.Hash {
 	return authorVersion
 }
 
-// Signer implements Signer .
-type Signer struct {
+// Signer encapsulates the signature rules of the actions, the rules of a fork
+// are implemented by a new signer.
+type Signer interface {
+	// PubKeys returns the public keys recovered from the signatures of the action.
+	PubKeys(a *Action, tx *Transaction) ([]common.PubKey, error)
+	// SignatureValues returns the R, S, V values of the [R || S || V] signature.
+	SignatureValues(sig []byte) (R, S, V *big.Int, err error)
+	// Hash returns the hash to be signed by the sender.
+	Hash(tx *Transaction) common.Hash
+	// PayerPubKeys returns the public keys recovered from the signatures of the fee payer.
+	PayerPubKeys(tx *Transaction) ([]common.PubKey, error)
+	// PayerHash returns the hash to be signed by the fee payer.
+	PayerHash(tx *Transaction) common.Hash
+	// ChainID returns the chain id the signatures are protected by.
+	ChainID() *big.Int
+	// Equal returns whether the signer applies the same rules.
+	Equal(Signer) bool
+}
+
+// InitialSigner implements the signature rules since the genesis.
+type InitialSigner struct {
 	chainID, chainIDMul *big.Int
 }
 
-// NewSigner initialize signer
+// NewSigner initialize the signer of the initial rules
 func NewSigner(chainID *big.Int) Signer {
 	if chainID == nil {
 		chainID = new(big.Int)
 	}
-	return Signer{
+	return InitialSigner{
 		chainID:    chainID,
 		chainIDMul: new(big.Int).Mul(chainID, big.NewInt(2)),
 	}
 }
 
-// Equal judging the same chainID
-func (s Signer) Equal(s2 Signer) bool {
-	return s2.chainID.Cmp(s.chainID) == 0
+// ChainID returns the chain id of the signer
+func (s InitialSigner) ChainID() *big.Int {
+	return s.chainID
+}
+
+// Equal judging the same rules and chainID
+func (s InitialSigner) Equal(s2 Signer) bool {
+	initial, ok := s2.(InitialSigner)
+	return ok && initial.chainID.Cmp(s.chainID) == 0
 }
 
 var big8 = big.NewInt(8)
 
-func (s Signer) PubKeys(a *Action, tx *Transaction) ([]common.PubKey, error) {
+func (s InitialSigner) PubKeys(a *Action, tx *Transaction) ([]common.PubKey, error) {
 	if len(a.GetSign()) == 0 {
+		// the action signed by the BLS authors only
+		if a.GetAggregateSign() != nil {
+			return nil, nil
+		}
 		return nil, ErrSignEmpty
 	}
 	if a.ChainID().Cmp(s.chainID) != 0 {
 		return nil, ErrInvalidchainID
 	}
+	return s.recoverPubKeys(s.Hash(tx), a.data.Sign.SignData)
+}
+
+// PayerPubKeys returns the public keys recovered from the signatures of the fee payer.
+func (s InitialSigner) PayerPubKeys(tx *Transaction) ([]common.PubKey, error) {
+	if tx.payer == nil {
+		return nil, ErrPayerInvalid
+	}
+	if tx.payer.Sign == nil || len(tx.payer.Sign.SignData) == 0 {
+		return nil, ErrSignEmpty
+	}
+	if deriveChainID(tx.payer.Sign.SignData[0].V).Cmp(s.chainID) != 0 {
+		return nil, ErrInvalidchainID
+	}
+	return s.recoverPubKeys(s.PayerHash(tx), tx.payer.Sign.SignData)
+}
+
+func (s InitialSigner) recoverPubKeys(hash common.Hash, signs []*SignData) ([]common.PubKey, error) {
 	var pubKeys []common.PubKey
-	for _, sign := range a.data.Sign.SignData {
+	for _, sign := range signs {
 		V := new(big.Int).Sub(sign.V, s.chainIDMul)
 		V.Sub(V, big8)
-		data, err := recoverPlain(s.Hash(tx), sign.R, sign.S, V)
+		data, err := recoverPlain(hash, sign.R, sign.S, V)
 		if err != nil {
 			return nil, err
 		}
@@ -149,7 +277,7 @@ func (s Signer) PubKeys(a *Action, tx *Transaction) ([]common.PubKey, error) {
 
 // SignatureValues returns a new transaction with the given signature. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
-func (s Signer) SignatureValues(sig []byte) (R, S, V *big.Int, err error) {
+func (s InitialSigner) SignatureValues(sig []byte) (R, S, V *big.Int, err error) {
 	if len(sig) != 65 {
 		panic(fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig)))
 	}
@@ -165,10 +293,10 @@ func (s Signer) SignatureValues(sig []byte) (R, S, V *big.Int, err error) {
 }
 
 // Hash returns the hash to be signed by the sender.
-func (s Signer) Hash(tx *Transaction) common.Hash {
+func (s InitialSigner) Hash(tx *Transaction) common.Hash {
 	actionHashs := make([]common.Hash, len(tx.GetActions()))
 	for i, a := range tx.GetActions() {
-		hash := RlpHash([]interface{}{
+		fields := []interface{}{
 			a.data.From,
 			a.data.AType,
 			a.data.Nonce,
@@ -179,14 +307,124 @@ func (s Signer) Hash(tx *Transaction) common.Hash {
 			a.data.AssetID,
 			a.data.Remark,
 			s.chainID, uint(0), uint(0),
-		})
-		actionHashs[i] = hash
+		}
+		// the flag is signed only if set, so the hash of the other actions is unchanged
+		if a.ContinueOnError() {
+			fields = append(fields, true)
+		}
+		actionHashs[i] = RlpHash(fields)
 	}
 
-	return RlpHash([]interface{}{
+	fields := []interface{}{
 		common.MerkleRoot(actionHashs),
 		tx.gasAssetID,
 		tx.gasPrice,
+	}
+	// the optional fields are signed up to the last one set
+	if tx.policy != PolicyIndependent || tx.expireAt != 0 {
+		fields = append(fields, tx.policy)
+	}
+	if tx.expireAt != 0 {
+		fields = append(fields, tx.expireAt)
+	}
+	return RlpHash(fields)
+}
+
+// PayerHash returns the hash to be signed by the fee payer, it commits to the
+// actions and the gas price signed by the senders.
+func (s InitialSigner) PayerHash(tx *Transaction) common.Hash {
+	var name common.Name
+	if tx.payer != nil {
+		name = tx.payer.Name
+	}
+	return RlpHash([]interface{}{
+		s.Hash(tx),
+		name,
+		s.chainID,
+	})
+}
+
+// ChainIDSigner implements the signature rules since ForkID4. The initial rules
+// already sign the chain id in every action hash, but they check it only in
+// the V of the first signature of the action and accept a chain without id,
+// whose unprotected signatures verify on every other chain without id. The
+// chain id rules check the V of every signature, the payer included, and
+// refuse a chain without id. The chain id is bound again on top of the initial
+// hash so the signatures of the initial rules do not verify under these rules.
+type ChainIDSigner struct {
+	InitialSigner
+}
+
+// NewChainIDSigner initialize the signer of the replay protection rules
+func NewChainIDSigner(chainID *big.Int) Signer {
+	return ChainIDSigner{NewSigner(chainID).(InitialSigner)}
+}
+
+// Equal judging the same rules and chainID
+func (s ChainIDSigner) Equal(s2 Signer) bool {
+	cs, ok := s2.(ChainIDSigner)
+	return ok && cs.chainID.Cmp(s.chainID) == 0
+}
+
+func (s ChainIDSigner) PubKeys(a *Action, tx *Transaction) ([]common.PubKey, error) {
+	if len(a.GetSign()) == 0 {
+		if a.GetAggregateSign() != nil {
+			return nil, nil
+		}
+		return nil, ErrSignEmpty
+	}
+	if err := s.checkChainID(a.data.Sign.SignData); err != nil {
+		return nil, err
+	}
+	return s.recoverPubKeys(s.Hash(tx), a.data.Sign.SignData)
+}
+
+// PayerPubKeys returns the public keys recovered from the signatures of the fee payer.
+func (s ChainIDSigner) PayerPubKeys(tx *Transaction) ([]common.PubKey, error) {
+	if tx.payer == nil {
+		return nil, ErrPayerInvalid
+	}
+	if tx.payer.Sign == nil || len(tx.payer.Sign.SignData) == 0 {
+		return nil, ErrSignEmpty
+	}
+	if err := s.checkChainID(tx.payer.Sign.SignData); err != nil {
+		return nil, err
+	}
+	return s.recoverPubKeys(s.PayerHash(tx), tx.payer.Sign.SignData)
+}
+
+// checkChainID rejects the unprotected signatures and the ones of other chains.
+func (s ChainIDSigner) checkChainID(signs []*SignData) error {
+	if s.chainID.Sign() == 0 {
+		return ErrSigUnprotected
+	}
+	for _, sign := range signs {
+		if sign.V == nil || deriveChainID(sign.V).Cmp(s.chainID) != 0 {
+			return ErrInvalidchainID
+		}
+	}
+	return nil
+}
+
+// Hash returns the hash to be signed by the sender, it commits to the chain id
+// on top of the hash of the initial rules.
+func (s ChainIDSigner) Hash(tx *Transaction) common.Hash {
+	return RlpHash([]interface{}{
+		s.InitialSigner.Hash(tx),
+		s.chainID,
+	})
+}
+
+// PayerHash returns the hash to be signed by the fee payer.
+func (s ChainIDSigner) PayerHash(tx *Transaction) common.Hash {
+	var name common.Name
+	if tx.payer != nil {
+		name = tx.payer.Name
+	}
+	return RlpHash([]interface{}{
+		s.Hash(tx),
+		name,
+		s.chainID,
 	})
 }
 
@@ -22,6 +22,7 @@ import (
 
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
 )
 
 func TestSigningMultiKey(t *testing.T) {
@@ -71,8 +72,85 @@ func TestChainID(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if testTx.GetActions()[0].ChainID().Cmp(signer.chainID) != 0 {
-		t.Error("expected chainId to be", signer.chainID, "got", testTx.GetActions()[0].ChainID())
+	if testTx.GetActions()[0].ChainID().Cmp(signer.ChainID()) != 0 {
+		t.Error("expected chainId to be", signer.ChainID(), "got", testTx.GetActions()[0].ChainID())
+	}
+}
+
+func TestMakeSigner(t *testing.T) {
+	config := params.DefaultChainconfig
+	signer := MakeSigner(config, params.ForkID0)
+	if !signer.Equal(NewSigner(config.ChainID)) {
+		t.Fatal("genesis signer mismatch")
+	}
+	if signer.Equal(NewSigner(new(big.Int).Add(config.ChainID, big.NewInt(1)))) {
+		t.Fatal("signers of different chains are equal")
+	}
+
+	// the transactions signed by the rules of a fork validate in its blocks
+	key, _ := crypto.GenerateKey()
+	if err := SignActionWithMultiKey(testAction, testTx, MakeSigner(config, params.ForkID3), 0, []*KeyPair{MakeKeyPair(key, []uint64{0})}); err != nil {
+		t.Fatal(err)
+	}
+	pubKeys, err := MakeSigner(config, params.ForkID3).PubKeys(testAction, testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pubKeys) == 0 || pubKeys[len(pubKeys)-1].Compare(common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))) != 0 {
+		t.Fatal("recovered public key mismatch")
+	}
+}
+
+func TestChainIDSigner(t *testing.T) {
+	config := params.DefaultChainconfig
+	if !MakeSigner(config, params.ForkID3).Equal(NewSigner(config.ChainID)) {
+		t.Fatal("signer before the fork mismatch")
+	}
+	signer := MakeSigner(config, params.ForkID4)
+	if !signer.Equal(NewChainIDSigner(config.ChainID)) {
+		t.Fatal("signer since the fork mismatch")
+	}
+
+	newTx := func() *Transaction {
+		action := NewAction(Transfer, common.Name("fromname"), common.Name("totoname"), 1, 3, 2000, big.NewInt(1000), nil, nil)
+		return NewTransaction(1, big.NewInt(1000), action)
+	}
+	key, _ := crypto.GenerateKey()
+	keys := []*KeyPair{MakeKeyPair(key, []uint64{0})}
+
+	tx := newTx()
+	if err := SignActionWithMultiKey(tx.GetActions()[0], tx, signer, 0, keys); err != nil {
+		t.Fatal(err)
+	}
+	pubKeys, err := signer.PubKeys(tx.GetActions()[0], tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubKeys[0].Compare(common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))) != 0 {
+		t.Fatal("recovered public key mismatch")
+	}
+
+	// the signatures of the initial rules do not recover the sender since the fork
+	old := newTx()
+	if err := SignActionWithMultiKey(old.GetActions()[0], old, NewSigner(config.ChainID), 0, keys); err != nil {
+		t.Fatal(err)
+	}
+	pubKeys, err = signer.PubKeys(old.GetActions()[0], old)
+	if err == nil && pubKeys[0].Compare(common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))) == 0 {
+		t.Fatal("initial signature recovered the sender since the fork")
+	}
+
+	// the signatures of another chain are rejected
+	other := newTx()
+	otherSigner := NewChainIDSigner(new(big.Int).Add(config.ChainID, big.NewInt(1)))
+	if err := SignActionWithMultiKey(other.GetActions()[0], other, otherSigner, 0, keys); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.PubKeys(other.GetActions()[0], other); err != ErrInvalidchainID {
+		t.Fatalf("expected %v, got %v", ErrInvalidchainID, err)
+	}
+	if signer.Hash(tx) == otherSigner.Hash(tx) {
+		t.Fatal("signing hashes of different chains are equal")
 	}
 }
 
@@ -89,3 +167,40 @@ func TestAuthorCache(t *testing.T) {
 		}
 	}
 }
+
+func TestSignHash(t *testing.T) {
+	chainID := big.NewInt(1)
+	action := NewAction(Transfer, common.Name("fromname"), common.Name("totoname"), 1, 3, 2000, big.NewInt(1000), nil, nil)
+	tx := NewTransaction(1, big.NewInt(1000), action)
+	hash, err := SignHash(action, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the digest signed outside of the node recovers the signer
+	key, _ := crypto.GenerateKey()
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(chainID)
+	if err := action.WithSignature(signer, sig, []uint64{0}); err != nil {
+		t.Fatal(err)
+	}
+	pubKeys, err := signer.PubKeys(action, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubKeys[0].Compare(common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))) != 0 {
+		t.Fatal("recovered public key mismatch")
+	}
+
+	forked, err := SignHashBy(NewChainIDSigner(chainID), action, tx)
+	if err != nil || forked != NewChainIDSigner(chainID).Hash(tx) {
+		t.Fatalf("fork sign hash %x err %v", forked, err)
+	}
+	other := NewAction(Transfer, common.Name("fromname"), common.Name("totoname"), 2, 3, 2000, big.NewInt(1000), nil, nil)
+	if _, err := SignHash(other, tx, chainID); err != ErrActionNotInTx {
+		t.Fatalf("foreign action err %v", err)
+	}
+}
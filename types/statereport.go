@@ -0,0 +1,32 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"github.com/fractalplatform/fractal/common"
+)
+
+// StateReport the result of the verification of the state trie of a block.
+type StateReport struct {
+	Number    uint64        `json:"number"`
+	Root      common.Hash   `json:"root"`
+	Nodes     int           `json:"nodes"`
+	Shared    int           `json:"shared"` // nodes referenced by more than one parent
+	Missing   []common.Hash `json:"missing"`
+	Corrupted []common.Hash `json:"corrupted"`
+	Repaired  int           `json:"repaired"` // nodes downloaded from the peers
+}
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"bytes"
 	"container/heap"
 	"errors"
 	"fmt"
@@ -37,13 +38,59 @@ var (
 
 	// ErrEmptyActions transaction no actions
 	ErrEmptyActions = errors.New("transaction no actions")
+
+	// ErrPayerInvalid is returned if the fee payer of a transaction is malformed.
+	ErrPayerInvalid = errors.New("transaction fee payer invalid")
+
+	// ErrPolicyInvalid is returned if the failure policy of a transaction is unknown.
+	ErrPolicyInvalid = errors.New("transaction failure policy invalid")
+
+	// ErrExpireInvalid is returned if the expiry of a transaction is malformed.
+	ErrExpireInvalid = errors.New("transaction expiry invalid")
+
+	// ErrTxExpired is returned if a transaction is included after its expiry.
+	ErrTxExpired = errors.New("transaction expired")
 )
 
+// ExpireAtTimeThreshold is the lowest expiry read as a unix time in seconds,
+// the expiries below it are block numbers.
+const ExpireAtTimeThreshold = 500000000
+
+// FailurePolicy is how the failure of an action affects the other actions of
+// the transaction. The gas used by every action is charged whatever the policy,
+// the failure of an action flagged continue on error affects no other action.
+type FailurePolicy uint64
+
+const (
+	// PolicyIndependent executes every action, a failed action reverts only its
+	// own effects. It is the policy of the transactions without one.
+	PolicyIndependent FailurePolicy = iota
+	// PolicyAtomic reverts the effects of all the actions if any action fails.
+	PolicyAtomic
+	// PolicyPrefix commits the actions before the first failure, the following
+	// actions are not executed.
+	PolicyPrefix
+)
+
+// emptyPayer is the encoding of a transaction without fee payer but with the
+// fields following the payer.
+var emptyPayer = []byte{0xC0}
+
+// FeePayer is the account sponsoring the gas of all the actions of a
+// transaction, the senders of the actions remain unchanged.
+type FeePayer struct {
+	Name common.Name
+	Sign *Signature
+}
+
 // Transaction represents an entire transaction in the block.
 type Transaction struct {
 	actions    []*Action
 	gasAssetID uint64
 	gasPrice   *big.Int
+	payer      *FeePayer
+	policy     FailurePolicy
+	expireAt   uint64
 	// caches
 	hash atomic.Value
 	size atomic.Value
@@ -68,6 +115,55 @@ func (tx *Transaction) GasAssetID() uint64 { return tx.gasAssetID }
 // GasPrice returns transaction gas price.
 func (tx *Transaction) GasPrice() *big.Int { return new(big.Int).Set(tx.gasPrice) }
 
+// Payer returns the fee payer of the transaction, nil if the senders pay the gas.
+func (tx *Transaction) Payer() *FeePayer { return tx.payer }
+
+// WithPayer sets the account sponsoring the gas of the transaction, its
+// signatures are added by SignPayerWithMultiKey.
+func (tx *Transaction) WithPayer(name common.Name) {
+	tx.payer = &FeePayer{Name: name, Sign: &Signature{}}
+}
+
+// FailurePolicy returns how the failure of an action affects the other actions.
+func (tx *Transaction) FailurePolicy() FailurePolicy { return tx.policy }
+
+// WithFailurePolicy sets the failure policy of the transaction, it is signed by
+// the senders so it must be set before signing.
+func (tx *Transaction) WithFailurePolicy(policy FailurePolicy) {
+	tx.policy = policy
+}
+
+// ExpireAt returns the last block number, or unix time in seconds from
+// ExpireAtTimeThreshold, the transaction can be included at, 0 never expires.
+func (tx *Transaction) ExpireAt() uint64 { return tx.expireAt }
+
+// WithExpireAt sets the expiry of the transaction, it is signed by the senders
+// so it must be set before signing.
+func (tx *Transaction) WithExpireAt(expireAt uint64) {
+	tx.expireAt = expireAt
+}
+
+// Expired returns whether the transaction can no longer be included by the
+// block of the number and the unix time in seconds given.
+func (tx *Transaction) Expired(number, timestamp uint64) bool {
+	switch {
+	case tx.expireAt == 0:
+		return false
+	case tx.expireAt < ExpireAtTimeThreshold:
+		return number > tx.expireAt
+	default:
+		return timestamp > tx.expireAt
+	}
+}
+
+// GasPayer returns the account paying the gas of the action.
+func (tx *Transaction) GasPayer(a *Action) common.Name {
+	if tx.payer != nil {
+		return tx.payer.Name
+	}
+	return a.Sender()
+}
+
 // Cost returns all actions gasprice * gaslimit.
 func (tx *Transaction) Cost() *big.Int {
 	total := new(big.Int)
@@ -83,8 +179,23 @@ func (tx *Transaction) GetActions() []*Action {
 }
 
 // EncodeRLP implements rlp.Encoder
+// The optional fields are appended up to the last one set, so the encoding of
+// the transactions without them is unchanged.
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{tx.gasAssetID, tx.gasPrice, tx.actions})
+	fields := []interface{}{tx.gasAssetID, tx.gasPrice, tx.actions}
+	optional := []interface{}{rlp.RawValue(emptyPayer), tx.policy, tx.expireAt}
+	if tx.payer != nil {
+		optional[0] = tx.payer
+	}
+	switch {
+	case tx.expireAt != 0:
+		fields = append(fields, optional...)
+	case tx.policy != PolicyIndependent:
+		fields = append(fields, optional[:2]...)
+	case tx.payer != nil:
+		fields = append(fields, optional[:1]...)
+	}
+	return rlp.Encode(w, fields)
 }
 
 // DecodeRLP implements rlp.Decoder
@@ -93,19 +204,67 @@ func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
 		AssetID  uint64
 		GasPrice *big.Int
 		Actions  []*Action
+		Optional []rlp.RawValue `rlp:"tail"`
 	}
 
 	_, size, _ := s.Kind()
 	err := s.Decode(&tmpTx)
+	var opt txOptional
+	if err == nil {
+		err = opt.decode(tmpTx.Optional)
+	}
 	if err == nil {
 		tx.gasAssetID = tmpTx.AssetID
 		tx.gasPrice = tmpTx.GasPrice
 		tx.actions = tmpTx.Actions
+		tx.payer = opt.payer
+		tx.policy = opt.policy
+		tx.expireAt = opt.expireAt
 		tx.size.Store(common.StorageSize(rlp.ListSize(size)))
 	}
 	return err
 }
 
+// txOptional is the optional fields of a transaction.
+type txOptional struct {
+	payer    *FeePayer
+	policy   FailurePolicy
+	expireAt uint64
+}
+
+// decode decodes the optional fields of a transaction, only their canonical
+// encoding is accepted, the last field present is never empty.
+func (opt *txOptional) decode(fields []rlp.RawValue) error {
+	if len(fields) > 3 {
+		return ErrExpireInvalid
+	}
+	if len(fields) > 0 && !bytes.Equal(fields[0], emptyPayer) {
+		opt.payer = new(FeePayer)
+		if err := rlp.DecodeBytes(fields[0], opt.payer); err != nil {
+			return ErrPayerInvalid
+		}
+	}
+	if len(fields) > 1 {
+		if err := rlp.DecodeBytes(fields[1], &opt.policy); err != nil {
+			return ErrPolicyInvalid
+		}
+	}
+	if len(fields) > 2 {
+		if err := rlp.DecodeBytes(fields[2], &opt.expireAt); err != nil {
+			return ErrExpireInvalid
+		}
+	}
+	switch {
+	case len(fields) == 1 && opt.payer == nil:
+		return ErrPayerInvalid
+	case len(fields) == 2 && opt.policy == PolicyIndependent:
+		return ErrPolicyInvalid
+	case len(fields) == 3 && opt.expireAt == 0:
+		return ErrExpireInvalid
+	}
+	return nil
+}
+
 // Hash hashes the RLP encoding of tx.
 func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
@@ -127,6 +286,35 @@ func (tx *Transaction) Size() common.StorageSize {
 	return common.StorageSize(c)
 }
 
+// CheckFork checks the transaction and its actions use only the fields and the
+// action types of the forks up to the fork id.
+func (tx *Transaction) CheckFork(forkID uint64) error {
+	if err := tx.CheckForkFields(forkID); err != nil {
+		return err
+	}
+	for _, a := range tx.actions {
+		if !a.Type().IsForked(forkID) {
+			return ErrNotForked
+		}
+	}
+	return nil
+}
+
+// CheckForkFields checks the transaction and its actions use only the fields of
+// the forks up to the fork id. The action types of a fork not active yet fail
+// as actions in the blocks, the pool and the miner reject them by CheckFork.
+func (tx *Transaction) CheckForkFields(forkID uint64) error {
+	if forkID < params.ForkID4 && (tx.payer != nil || tx.policy != PolicyIndependent || tx.expireAt != 0) {
+		return ErrNotForked
+	}
+	for _, a := range tx.actions {
+		if err := a.CheckForkFields(forkID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Check the validity of all fields
 func (tx *Transaction) Check(conf *params.ChainConfig) error {
 	if len(tx.actions) == 0 {
@@ -138,6 +326,14 @@ func (tx *Transaction) Check(conf *params.ChainConfig) error {
 		return ErrOversizedData
 	}
 
+	if tx.payer != nil && (tx.payer.Sign == nil || len(tx.payer.Sign.SignData) == 0) {
+		return ErrPayerInvalid
+	}
+
+	if tx.policy > PolicyPrefix {
+		return ErrPolicyInvalid
+	}
+
 	if conf.SysTokenID != tx.gasAssetID {
 		return fmt.Errorf("only support system asset %d as tx fee", conf.SysTokenID)
 	}
@@ -160,6 +356,9 @@ type RPCTransaction struct {
 	GasAssetID       uint64       `json:"gasAssetID"`
 	GasPrice         *big.Int     `json:"gasPrice"`
 	GasCost          *big.Int     `json:"gasCost"`
+	Payer            common.Name  `json:"payer,omitempty"`
+	FailurePolicy    uint64       `json:"failurePolicy,omitempty"`
+	ExpireAt         uint64       `json:"expireAt,omitempty"`
 }
 
 // NewRPCTransaction returns a transaction that will serialize to the RPC.
@@ -179,6 +378,11 @@ func (tx *Transaction) NewRPCTransaction(blockHash common.Hash, blockNumber uint
 	result.GasAssetID = tx.gasAssetID
 	result.GasPrice = tx.gasPrice
 	result.GasCost = tx.Cost()
+	if tx.payer != nil {
+		result.Payer = tx.payer.Name
+	}
+	result.FailurePolicy = uint64(tx.policy)
+	result.ExpireAt = tx.expireAt
 	return result
 }
 
@@ -18,10 +18,12 @@ package types
 import (
 	"bytes"
 	"encoding/json"
+	"math"
 	"math/big"
 	"testing"
 
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/utils/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -56,3 +58,143 @@ func TestTransactionEncodeAndDecode(t *testing.T) {
 
 	assert.Equal(t, newrpctxbytes, testrpctxbytes)
 }
+
+func TestTransactionPayerRLP(t *testing.T) {
+	plain, err := rlp.EncodeToBytes(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := NewTransaction(testTx.GasAssetID(), testTx.GasPrice(), testTx.GetActions()...)
+	tx.WithPayer(common.Name("sponsor"))
+	tx.Payer().Sign.SignData = append(tx.Payer().Sign.SignData, &SignData{V: big.NewInt(37), R: big.NewInt(1), S: big.NewInt(2), Index: []uint64{0}})
+	sponsored, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(plain, sponsored) {
+		t.Fatal("payer not encoded")
+	}
+
+	decoded := new(Transaction)
+	if err := rlp.DecodeBytes(sponsored, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != tx.Hash() || decoded.Payer() == nil || decoded.Payer().Name != common.Name("sponsor") {
+		t.Fatalf("decoded payer %v", decoded.Payer())
+	}
+
+	decoded = new(Transaction)
+	if err := rlp.DecodeBytes(plain, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Payer() != nil || decoded.Hash() != testTx.Hash() {
+		t.Fatal("plain transaction decoded with payer")
+	}
+}
+
+func TestTransactionFailurePolicyRLP(t *testing.T) {
+	tx := NewTransaction(testTx.GasAssetID(), testTx.GasPrice(), testTx.GetActions()...)
+	tx.WithFailurePolicy(PolicyAtomic)
+	encoded, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Transaction)
+	if err := rlp.DecodeBytes(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.FailurePolicy() != PolicyAtomic || decoded.Payer() != nil || decoded.Hash() != tx.Hash() {
+		t.Fatalf("decoded policy %d payer %v", decoded.FailurePolicy(), decoded.Payer())
+	}
+
+	signer := NewSigner(big.NewInt(1))
+	if signer.Hash(tx) == signer.Hash(testTx) {
+		t.Fatal("failure policy not signed by the senders")
+	}
+
+	// the default policy is never encoded
+	invalid, err := rlp.EncodeToBytes([]interface{}{tx.GasAssetID(), tx.GasPrice(), tx.GetActions(), rlp.RawValue(emptyPayer), PolicyIndependent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rlp.DecodeBytes(invalid, new(Transaction)); err != ErrPolicyInvalid {
+		t.Fatalf("decode default policy err %v", err)
+	}
+}
+
+func TestTransactionExpireAt(t *testing.T) {
+	tx := NewTransaction(testTx.GasAssetID(), testTx.GasPrice(), testTx.GetActions()...)
+	tx.WithExpireAt(100)
+	if tx.Expired(100, 0) || !tx.Expired(101, 0) {
+		t.Fatal("block number expiry mismatch")
+	}
+	tx.WithExpireAt(ExpireAtTimeThreshold + 100)
+	if tx.Expired(math.MaxUint64, ExpireAtTimeThreshold+100) || !tx.Expired(0, ExpireAtTimeThreshold+101) {
+		t.Fatal("time expiry mismatch")
+	}
+	if testTx.Expired(math.MaxUint64, math.MaxUint64) {
+		t.Fatal("transaction without expiry expired")
+	}
+
+	encoded, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Transaction)
+	if err := rlp.DecodeBytes(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ExpireAt() != tx.ExpireAt() || decoded.FailurePolicy() != PolicyIndependent || decoded.Payer() != nil || decoded.Hash() != tx.Hash() {
+		t.Fatalf("decoded expiry %d policy %d payer %v", decoded.ExpireAt(), decoded.FailurePolicy(), decoded.Payer())
+	}
+
+	signer := NewSigner(big.NewInt(1))
+	if signer.Hash(tx) == signer.Hash(testTx) {
+		t.Fatal("expiry not signed by the senders")
+	}
+}
+
+func TestTransactionCheckFork(t *testing.T) {
+	if err := testTx.CheckFork(params.ForkID0); err != nil {
+		t.Fatalf("plain transaction err %v", err)
+	}
+
+	tx := NewTransaction(testTx.GasAssetID(), testTx.GasPrice(), testTx.GetActions()...)
+	tx.WithPayer(common.Name("sponsor"))
+	if err := tx.CheckFork(params.ForkID3); err != ErrNotForked {
+		t.Fatalf("payer before fork err %v", err)
+	}
+	if err := tx.CheckFork(params.ForkID4); err != nil {
+		t.Fatalf("payer after fork err %v", err)
+	}
+
+	tx = NewTransaction(testTx.GasAssetID(), testTx.GasPrice(), testTx.GetActions()...)
+	tx.WithFailurePolicy(PolicyAtomic)
+	if err := tx.CheckFork(params.ForkID3); err != ErrNotForked {
+		t.Fatalf("policy before fork err %v", err)
+	}
+	if err := tx.CheckFork(params.ForkID4); err != nil {
+		t.Fatalf("policy after fork err %v", err)
+	}
+
+	tx = NewTransaction(testTx.GasAssetID(), testTx.GasPrice(), testTx.GetActions()...)
+	tx.WithExpireAt(100)
+	if err := tx.CheckFork(params.ForkID3); err != ErrNotForked {
+		t.Fatalf("expiry before fork err %v", err)
+	}
+	if err := tx.CheckFork(params.ForkID4); err != nil {
+		t.Fatalf("expiry after fork err %v", err)
+	}
+
+	tx = NewTransaction(testTx.GasAssetID(), testTx.GasPrice(), NewAction(TransferEscrow, common.Name("fromname"), common.Name("totoname"), 1, 3, 2000, big.NewInt(1000), nil, nil))
+	if err := tx.CheckFork(params.ForkID3); err != ErrNotForked {
+		t.Fatalf("action type before fork err %v", err)
+	}
+	if err := tx.CheckForkFields(params.ForkID3); err != nil {
+		t.Fatalf("action type before fork fails as action, err %v", err)
+	}
+	if err := tx.CheckFork(params.ForkID4); err != nil {
+		t.Fatalf("action type after fork err %v", err)
+	}
+}
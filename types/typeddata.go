@@ -0,0 +1,366 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+)
+
+// The typed data is hashed like EIP-712, so the off-chain messages (orders,
+// permits) are signed with the structure shown to the user and the hash can
+// be rebuilt by the contracts with keccak256 and abi.encode:
+//
+//   hash = keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+//
+// the domain separates the signatures of the applications and the chains. The
+// account names are encoded as the strings.
+
+// domainType is the struct type of the domain.
+const domainType = "FractalDomain(string name,string version,uint256 chainId,string verifier)"
+
+// ErrTypedDataInvalid the typed data does not match its types.
+var ErrTypedDataInvalid = errors.New("invalid typed data")
+
+// TypedField is a member of a struct type of the typed data.
+type TypedField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedDomain is the domain separating the signatures of the typed data.
+type TypedDomain struct {
+	Name     string      `json:"name"`
+	Version  string      `json:"version"`
+	ChainID  *big.Int    `json:"chainId"`
+	Verifier common.Name `json:"verifier"` // account or contract verifying the signatures
+}
+
+// Separator returns the domain separator of the typed data hash.
+func (d *TypedDomain) Separator() common.Hash {
+	chainID := d.ChainID
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	return crypto.Keccak256Hash(
+		crypto.Keccak256([]byte(domainType)),
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		math.PaddedBigBytes(math.U256(new(big.Int).Set(chainID)), 32),
+		crypto.Keccak256([]byte(d.Verifier.String())),
+	)
+}
+
+// TypedData is a structured message signed off-chain by the authors of an account.
+type TypedData struct {
+	Types       map[string][]TypedField `json:"types"`
+	PrimaryType string                  `json:"primaryType"`
+	Domain      TypedDomain             `json:"domain"`
+	Message     map[string]interface{}  `json:"message"`
+}
+
+// TypedSignature is the signature of the typed data by an author of the account,
+// the index is the path of the author like the signatures of the actions.
+type TypedSignature struct {
+	Signature hexutil.Bytes `json:"signature"`
+	Index     []uint64      `json:"index"`
+}
+
+// Hash returns the hash of the typed data to be signed.
+func (td *TypedData) Hash() (common.Hash, error) {
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	separator := td.Domain.Separator()
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, separator[:], message[:]), nil
+}
+
+// HashStruct returns the hash of the data of the struct type.
+func (td *TypedData) HashStruct(typ string, data map[string]interface{}) (common.Hash, error) {
+	fields, ok := td.Types[typ]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("%v: unknown type %v", ErrTypedDataInvalid, typ)
+	}
+	encodedType, err := td.EncodeType(typ)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(data) != len(fields) {
+		return common.Hash{}, fmt.Errorf("%v: type %v has %d fields, data has %d", ErrTypedDataInvalid, typ, len(fields), len(data))
+	}
+	buf := bytes.NewBuffer(crypto.Keccak256([]byte(encodedType)))
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return common.Hash{}, fmt.Errorf("%v: missing field %v.%v", ErrTypedDataInvalid, typ, field.Name)
+		}
+		encoded, err := td.encodeValue(field.Type, value)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("%v.%v: %v", typ, field.Name, err)
+		}
+		buf.Write(encoded)
+	}
+	return crypto.Keccak256Hash(buf.Bytes()), nil
+}
+
+// EncodeType returns the encoding of the struct type followed by the sorted
+// struct types it refers to, like "Order(string maker,Asset give)Asset(uint256 id,uint256 amount)".
+func (td *TypedData) EncodeType(typ string) (string, error) {
+	deps := make(map[string]bool)
+	if err := td.dependencies(typ, deps); err != nil {
+		return "", err
+	}
+	delete(deps, typ)
+	sorted := make([]string, 0, len(deps))
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted)
+
+	var buf strings.Builder
+	for _, t := range append([]string{typ}, sorted...) {
+		fields := make([]string, len(td.Types[t]))
+		for i, field := range td.Types[t] {
+			fields[i] = field.Type + " " + field.Name
+		}
+		buf.WriteString(t + "(" + strings.Join(fields, ",") + ")")
+	}
+	return buf.String(), nil
+}
+
+// dependencies collects the struct types referred by the type.
+func (td *TypedData) dependencies(typ string, deps map[string]bool) error {
+	typ = baseType(typ)
+	if deps[typ] {
+		return nil
+	}
+	fields, ok := td.Types[typ]
+	if !ok {
+		return nil
+	}
+	deps[typ] = true
+	for _, field := range fields {
+		if field.Name == "" || field.Type == "" {
+			return fmt.Errorf("%v: empty field of type %v", ErrTypedDataInvalid, typ)
+		}
+		if err := td.dependencies(field.Type, deps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baseType strips the array suffixes of the type.
+func baseType(typ string) string {
+	if i := strings.Index(typ, "["); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// encodeValue returns the 32 bytes encoding of the value of the type.
+func (td *TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	// arrays are the hash of the concatenated encodings of the items
+	if strings.HasSuffix(typ, "]") {
+		i := strings.LastIndex(typ, "[")
+		if i < 0 {
+			return nil, fmt.Errorf("%v: bad type %v", ErrTypedDataInvalid, typ)
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: %v is not an array", ErrTypedDataInvalid, typ)
+		}
+		if size := typ[i+1 : len(typ)-1]; size != "" {
+			if n, err := strconv.Atoi(size); err != nil || n != len(items) {
+				return nil, fmt.Errorf("%v: %v has %d items", ErrTypedDataInvalid, typ, len(items))
+			}
+		}
+		var buf bytes.Buffer
+		for _, item := range items {
+			encoded, err := td.encodeValue(typ[:i], item)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(encoded)
+		}
+		return crypto.Keccak256(buf.Bytes()), nil
+	}
+	if _, ok := td.Types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: %v is not a struct", ErrTypedDataInvalid, typ)
+		}
+		hash, err := td.HashStruct(typ, data)
+		if err != nil {
+			return nil, err
+		}
+		return hash[:], nil
+	}
+
+	switch {
+	case typ == "string" || typ == "name":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v: %v is not a string", ErrTypedDataInvalid, typ)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+	case typ == "bytes":
+		b, err := typedBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v: %v is not a bool", ErrTypedDataInvalid, typ)
+		}
+		encoded := make([]byte, 32)
+		if b {
+			encoded[31] = 1
+		}
+		return encoded, nil
+	case typ == "address":
+		b, err := typedBytes(value)
+		if err != nil || len(b) != common.AddressLength {
+			return nil, fmt.Errorf("%v: %v is not an address", ErrTypedDataInvalid, typ)
+		}
+		return common.LeftPadBytes(b, 32), nil
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(typ[len("bytes"):])
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("%v: unknown type %v", ErrTypedDataInvalid, typ)
+		}
+		b, err := typedBytes(value)
+		if err != nil || len(b) != size {
+			return nil, fmt.Errorf("%v: %v has not %d bytes", ErrTypedDataInvalid, typ, size)
+		}
+		return common.RightPadBytes(b, 32), nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		return typedInteger(typ, value)
+	}
+	return nil, fmt.Errorf("%v: unknown type %v", ErrTypedDataInvalid, typ)
+}
+
+// typedBytes decodes the bytes given as hex string.
+func typedBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case hexutil.Bytes:
+		return v, nil
+	case string:
+		b, err := hexutil.Decode(v)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", ErrTypedDataInvalid, err)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("%v: %v are not bytes", ErrTypedDataInvalid, value)
+}
+
+// typedInteger returns the two's complement encoding of the integer, given as
+// number or decimal or hex string.
+func typedInteger(typ string, value interface{}) ([]byte, error) {
+	signed := strings.HasPrefix(typ, "int")
+	bits := 256
+	if size := strings.TrimLeft(typ, "uint"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 8 || n > 256 || n%8 != 0 {
+			return nil, fmt.Errorf("%v: unknown type %v", ErrTypedDataInvalid, typ)
+		}
+		bits = n
+	}
+
+	var n *big.Int
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, fmt.Errorf("%v: nil integer", ErrTypedDataInvalid)
+		}
+		n = v
+	case string:
+		var ok bool
+		if n, ok = math.ParseBig256(v); !ok {
+			return nil, fmt.Errorf("%v: %v is not an integer", ErrTypedDataInvalid, v)
+		}
+	case float64:
+		if v != float64(int64(v)) || v > 1<<53 || v < -(1<<53) {
+			return nil, fmt.Errorf("%v: %v is not an integer", ErrTypedDataInvalid, v)
+		}
+		n = big.NewInt(int64(v))
+	case int:
+		n = big.NewInt(int64(v))
+	case int64:
+		n = big.NewInt(v)
+	case uint64:
+		n = new(big.Int).SetUint64(v)
+	default:
+		return nil, fmt.Errorf("%v: %v is not an integer", ErrTypedDataInvalid, value)
+	}
+
+	if signed {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("%v: %v overflows %v", ErrTypedDataInvalid, n, typ)
+		}
+	} else if n.Sign() < 0 || n.BitLen() > bits {
+		return nil, fmt.Errorf("%v: %v overflows %v", ErrTypedDataInvalid, n, typ)
+	}
+	return math.PaddedBigBytes(math.U256(new(big.Int).Set(n)), 32), nil
+}
+
+// SignTypedData signs the hash of the typed data by the key, the signature is
+// in the [R || S || V] format where V is 0 or 1.
+func SignTypedData(td *TypedData, priv *ecdsa.PrivateKey) ([]byte, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash[:], priv)
+}
+
+// RecoverTypedData returns the public key signing the hash of the typed data.
+func RecoverTypedData(td *TypedData, sig []byte) (common.PubKey, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	if len(sig) != 65 {
+		return common.PubKey{}, ErrInvalidSig
+	}
+	if !crypto.ValidateSignatureValues(sig[64], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])) {
+		return common.PubKey{}, ErrInvalidSig
+	}
+	pub, err := crypto.Ecrecover(hash[:], sig)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	return common.BytesToPubKey(pub), nil
+}
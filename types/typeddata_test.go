@@ -0,0 +1,106 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+)
+
+// mailTypedData is the example of the EIP-712 specification.
+func mailTypedData() *TypedData {
+	return &TypedData{
+		Types: map[string][]TypedField{
+			"Person": {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+			"Mail":   {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "string"}},
+		},
+		PrimaryType: "Mail",
+		Domain:      TypedDomain{Name: "Ether Mail", Version: "1", ChainID: big.NewInt(1), Verifier: common.Name("fractal.mail")},
+		Message: map[string]interface{}{
+			"from":     map[string]interface{}{"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+			"to":       map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func TestTypedDataHashStruct(t *testing.T) {
+	td := mailTypedData()
+	encoded, err := td.EncodeType("Mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; encoded != want {
+		t.Fatalf("encode type mismatch: have %v, want %v", encoded, want)
+	}
+	hash, err := td.HashStruct("Mail", td.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToHash("0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"); hash != want {
+		t.Fatalf("hash struct mismatch: have %x, want %x", hash, want)
+	}
+}
+
+func TestTypedDataInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(td *TypedData)
+	}{
+		{"unknownprimary", func(td *TypedData) { td.PrimaryType = "Letter" }},
+		{"missingfield", func(td *TypedData) { delete(td.Message, "contents") }},
+		{"extrafield", func(td *TypedData) { td.Message["date"] = "today" }},
+		{"badaddress", func(td *TypedData) { td.Message["to"].(map[string]interface{})["wallet"] = "0x1234" }},
+		{"unknowntype", func(td *TypedData) { td.Types["Mail"][2].Type = "text" }},
+		{"overflow", func(td *TypedData) {
+			td.Types["Mail"][2].Type = "uint8"
+			td.Message["contents"] = "256"
+		}},
+	}
+	for _, test := range tests {
+		td := mailTypedData()
+		test.modify(td)
+		if _, err := td.Hash(); err == nil {
+			t.Errorf("%s: invalid typed data hashed", test.name)
+		}
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	td := mailTypedData()
+	sig, err := SignTypedData(td, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := RecoverTypedData(td, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey)); pub.Compare(want) != 0 {
+		t.Fatalf("recovered key mismatch: have %v, want %v", pub, want)
+	}
+
+	// the signature does not apply to another domain
+	td.Domain.ChainID = big.NewInt(2)
+	if pub, err := RecoverTypedData(td, sig); err == nil && pub.Compare(common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))) == 0 {
+		t.Fatal("signature recovered on another domain")
+	}
+}